@@ -0,0 +1,91 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/database"
+)
+
+// compactOptions are the parsed compact-snapshots flags.
+type compactOptions struct {
+	dbPath  string
+	keepRaw time.Duration
+	bucket  time.Duration
+	dryRun  bool
+}
+
+// RunCompactSnapshots implements "live-actions compact-snapshots", which rolls
+// up and deletes raw metrics snapshots older than --keep-raw without running
+// the server, e.g. on a copy of the database ingested for analytics. It
+// returns the process exit status.
+func RunCompactSnapshots(args []string, stdout, stderr io.Writer) int {
+	opts, err := parseCompactArgs(args, stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	db, closeDB, err := openDatabase(opts.dbPath)
+	if err != nil {
+		return fail(stderr, err)
+	}
+	defer closeDB()
+
+	if err := compactSnapshots(context.Background(), db, time.Now().Add(-opts.keepRaw), opts, stdout); err != nil {
+		return fail(stderr, err)
+	}
+	return 0
+}
+
+func parseCompactArgs(args []string, output io.Writer) (compactOptions, error) {
+	var opts compactOptions
+
+	flags := flag.NewFlagSet("compact-snapshots", flag.ContinueOnError)
+	flags.SetOutput(output)
+	flags.StringVar(&opts.dbPath, "db", "", "database file to compact (default: DATABASE_PATH)")
+	flags.DurationVar(&opts.keepRaw, "keep-raw", 48*time.Hour, "keep raw snapshots taken within this long")
+	flags.DurationVar(&opts.bucket, "bucket", time.Hour, "width of the buckets older snapshots are rolled up into")
+	flags.BoolVar(&opts.dryRun, "dry-run", false, "report what would be compacted without changing anything")
+	flags.Usage = func() {
+		fmt.Fprintln(output, "Usage: live-actions compact-snapshots [--db path] [--keep-raw 48h] [--bucket 1h] [--dry-run]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return opts, err
+	}
+	if flags.NArg() > 0 {
+		return opts, fmt.Errorf("unexpected argument %q", flags.Arg(0))
+	}
+
+	if opts.keepRaw < 0 {
+		return opts, fmt.Errorf("--keep-raw must not be negative, got %s", opts.keepRaw)
+	}
+	if opts.bucket < time.Minute {
+		return opts, fmt.Errorf("--bucket must be at least 1m, got %s", opts.bucket)
+	}
+	return opts, nil
+}
+
+// compactSnapshots rolls up the snapshots taken before the cutoff and reports
+// what was compacted.
+func compactSnapshots(ctx context.Context, db database.DatabaseInterface, before time.Time, opts compactOptions, out io.Writer) error {
+	result, err := db.CompactMetricsSnapshots(ctx, before, opts.bucket, opts.dryRun)
+	if err != nil {
+		return err
+	}
+
+	verb := "Compacted"
+	if opts.dryRun {
+		verb = "Would compact"
+	}
+	fmt.Fprintf(out, "%s %d snapshot(s) taken before %s into %d bucket(s) of %s\n",
+		verb, result.Snapshots, before.UTC().Format(time.RFC3339), result.Buckets, opts.bucket)
+	return nil
+}
@@ -0,0 +1,58 @@
+package maintenance
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCompactArgs(t *testing.T) {
+	opts, err := parseCompactArgs(nil, io.Discard)
+	require.NoError(t, err)
+	assert.Equal(t, compactOptions{keepRaw: 48 * time.Hour, bucket: time.Hour}, opts)
+
+	opts, err = parseCompactArgs([]string{"--db", "/tmp/copy.db", "--keep-raw", "72h", "--bucket", "15m", "--dry-run"}, io.Discard)
+	require.NoError(t, err)
+	assert.Equal(t, compactOptions{dbPath: "/tmp/copy.db", keepRaw: 72 * time.Hour, bucket: 15 * time.Minute, dryRun: true}, opts)
+}
+
+func TestParseCompactArgs_Invalid(t *testing.T) {
+	for _, args := range [][]string{
+		{"--keep-raw", "-1h"},
+		{"--keep-raw", "two days"},
+		{"--bucket", "30s"},
+		{"--bucket", "0"},
+		{"extra"},
+	} {
+		_, err := parseCompactArgs(args, io.Discard)
+		assert.Error(t, err, "args %v", args)
+	}
+
+	_, err := parseCompactArgs([]string{"--help"}, io.Discard)
+	assert.True(t, errors.Is(err, flag.ErrHelp))
+}
+
+func TestCompactSnapshots(t *testing.T) {
+	mockDB := new(database.MockDatabase)
+	before := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	opts := compactOptions{bucket: time.Hour, dryRun: true}
+	mockDB.On("CompactMetricsSnapshots", mock.Anything, before, time.Hour, true).Return(models.SnapshotCompaction{Snapshots: 8640, Buckets: 24}, nil)
+
+	var out bytes.Buffer
+	require.NoError(t, compactSnapshots(context.Background(), mockDB, before, opts, &out))
+	assert.Equal(t, "Would compact 8640 snapshot(s) taken before 2024-03-01T12:00:00Z into 24 bucket(s) of 1h0m0s\n", out.String())
+
+	opts.dryRun = false
+	mockDB.On("CompactMetricsSnapshots", mock.Anything, before, time.Hour, false).Return(models.SnapshotCompaction{}, errors.New("db error"))
+	assert.Error(t, compactSnapshots(context.Background(), mockDB, before, opts, &out))
+}
@@ -0,0 +1,42 @@
+// Package maintenance implements the command line subcommands that operate on
+// the database directly, such as pruning data outside the retention period.
+package maintenance
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gateixeira/live-actions/internal/config"
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/pkg/logger"
+)
+
+// openDatabase opens the database file at path or, when it is empty, the one
+// configured through the environment, the same one the server uses. The
+// returned function closes it.
+func openDatabase(path string) (database.DatabaseInterface, func(), error) {
+	logger.InitLogger("warn")
+
+	cfg, err := config.NewConfig()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	if path == "" {
+		path = cfg.GetDatabasePath()
+	}
+	sqlDB, err := database.InitDB(path, cfg.Vars.DatabaseEncryptionKey, database.MigrationOptions{
+		Wait:    cfg.Vars.WaitForMigrations,
+		Timeout: cfg.GetMigrationTimeout(),
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open database %s: %w", path, err)
+	}
+	return database.NewDBWrapper(sqlDB), func() { _ = sqlDB.Close() }, nil
+}
+
+// fail prints err and returns the exit status for a failed subcommand.
+func fail(stderr io.Writer, err error) int {
+	fmt.Fprintf(stderr, "error: %v\n", err)
+	return 1
+}
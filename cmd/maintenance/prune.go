@@ -0,0 +1,101 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/models"
+)
+
+// RunPrune implements "live-actions prune", which deletes one repository's
+// data and/or everything before a date without waiting for the retention
+// period. It returns the process exit status.
+func RunPrune(args []string, stdout, stderr io.Writer) int {
+	filter, dryRun, err := parsePruneArgs(args, stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	db, closeDB, err := openDatabase("")
+	if err != nil {
+		return fail(stderr, err)
+	}
+	defer closeDB()
+
+	if err := prune(context.Background(), db, filter, dryRun, stdout); err != nil {
+		return fail(stderr, err)
+	}
+	return 0
+}
+
+// parsePruneArgs parses the prune flags into a filter.
+func parsePruneArgs(args []string, output io.Writer) (models.PruneFilter, bool, error) {
+	var filter models.PruneFilter
+
+	flags := flag.NewFlagSet("prune", flag.ContinueOnError)
+	flags.SetOutput(output)
+	repo := flags.String("repo", "", "only prune runs of this repository (owner/name or name)")
+	before := flags.String("before", "", "only prune runs created before this date (YYYY-MM-DD) or time (RFC 3339)")
+	dryRun := flags.Bool("dry-run", false, "report what would be pruned without deleting anything")
+	flags.Usage = func() {
+		fmt.Fprintln(output, "Usage: live-actions prune [--repo owner/name] [--before YYYY-MM-DD] [--dry-run]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return filter, false, err
+	}
+	if flags.NArg() > 0 {
+		return filter, false, fmt.Errorf("unexpected argument %q", flags.Arg(0))
+	}
+
+	if *repo != "" {
+		owner, name, hasOwner := strings.Cut(*repo, "/")
+		if !hasOwner {
+			owner, name = "", *repo
+		}
+		if name == "" || strings.Contains(name, "/") || (hasOwner && owner == "") {
+			return filter, false, fmt.Errorf("--repo must be owner/name or name, got %q", *repo)
+		}
+		filter.Owner, filter.Repository = owner, name
+	}
+
+	if *before != "" {
+		t, err := time.Parse(time.DateOnly, *before)
+		if err != nil {
+			if t, err = time.Parse(time.RFC3339, *before); err != nil {
+				return filter, false, fmt.Errorf("--before must be a date (YYYY-MM-DD) or RFC 3339 time, got %q", *before)
+			}
+		}
+		filter.Before = t
+	}
+
+	if filter.Repository == "" && filter.Before.IsZero() {
+		return filter, false, errors.New("at least one of --repo or --before is required")
+	}
+	return filter, *dryRun, nil
+}
+
+// prune removes the data matched by filter and reports what was removed.
+func prune(ctx context.Context, db database.DatabaseInterface, filter models.PruneFilter, dryRun bool, out io.Writer) error {
+	result, err := db.PruneData(ctx, filter, dryRun)
+	if err != nil {
+		return err
+	}
+
+	verb := "Pruned"
+	if dryRun {
+		verb = "Would prune"
+	}
+	fmt.Fprintf(out, "%s %d run(s), %d job(s), %d webhook event(s) and %d counter row(s)\n",
+		verb, result.Runs, result.Jobs, result.WebhookEvents, result.Counters)
+	return nil
+}
@@ -0,0 +1,69 @@
+package maintenance
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePruneArgs(t *testing.T) {
+	filter, dryRun, err := parsePruneArgs([]string{"--repo", "octo-org/loadtest", "--before", "2024-01-01", "--dry-run"}, io.Discard)
+	require.NoError(t, err)
+	assert.True(t, dryRun)
+	assert.Equal(t, "octo-org", filter.Owner)
+	assert.Equal(t, "loadtest", filter.Repository)
+	assert.True(t, filter.Before.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+
+	filter, dryRun, err = parsePruneArgs([]string{"--before", "2024-01-01T12:00:00+02:00"}, io.Discard)
+	require.NoError(t, err)
+	assert.False(t, dryRun)
+	assert.Empty(t, filter.Repository)
+	assert.True(t, filter.Before.Equal(time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)))
+
+	filter, _, err = parsePruneArgs([]string{"--repo", "loadtest"}, io.Discard)
+	require.NoError(t, err)
+	assert.Empty(t, filter.Owner)
+	assert.Equal(t, "loadtest", filter.Repository)
+	assert.True(t, filter.Before.IsZero())
+}
+
+func TestParsePruneArgs_Invalid(t *testing.T) {
+	for _, args := range [][]string{
+		{},
+		{"--dry-run"},
+		{"--repo", "a/b/c"},
+		{"--repo", "octo-org/"},
+		{"--repo", "/loadtest"},
+		{"--before", "01/01/2024"},
+		{"--repo", "app", "extra"},
+	} {
+		_, _, err := parsePruneArgs(args, io.Discard)
+		assert.Error(t, err, "args %v", args)
+	}
+
+	_, _, err := parsePruneArgs([]string{"--help"}, io.Discard)
+	assert.True(t, errors.Is(err, flag.ErrHelp))
+}
+
+func TestPrune(t *testing.T) {
+	mockDB := new(database.MockDatabase)
+	filter := models.PruneFilter{Repository: "loadtest"}
+	mockDB.On("PruneData", mock.Anything, filter, true).Return(models.PruneResult{Runs: 2, Jobs: 5, WebhookEvents: 14, Counters: 3}, nil)
+
+	var out bytes.Buffer
+	require.NoError(t, prune(context.Background(), mockDB, filter, true, &out))
+	assert.Equal(t, "Would prune 2 run(s), 5 job(s), 14 webhook event(s) and 3 counter row(s)\n", out.String())
+
+	mockDB.On("PruneData", mock.Anything, filter, false).Return(models.PruneResult{}, errors.New("db error"))
+	assert.Error(t, prune(context.Background(), mockDB, filter, false, &out))
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/config"
+	"github.com/gateixeira/live-actions/internal/configcheck"
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gateixeira/live-actions/pkg/logger"
+)
+
+// checkConfigTimeout bounds the whole configuration check, including the
+// requests to configured URLs.
+const checkConfigTimeout = 30 * time.Second
+
+// ServeOptions are the flags of "live-actions serve". Everything else is
+// configured through the environment.
+type ServeOptions struct {
+	CheckConfig bool
+	Format      string
+}
+
+// ParseServeArgs parses the serve flags.
+func ParseServeArgs(args []string, output io.Writer) (ServeOptions, error) {
+	var opts ServeOptions
+
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	flags.SetOutput(output)
+	flags.BoolVar(&opts.CheckConfig, "check-config", false, "validate the configuration, the URLs it calls and that the database is writable, then exit: 0 when valid, 1 otherwise")
+	flags.StringVar(&opts.Format, "format", "text", "check-config report format: text or json")
+	flags.Usage = func() {
+		fmt.Fprintln(output, "Usage: live-actions serve [--check-config [--format text|json]]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return opts, err
+	}
+	if flags.NArg() > 0 {
+		return opts, fmt.Errorf("unexpected argument %q", flags.Arg(0))
+	}
+	if opts.Format != "text" && opts.Format != "json" {
+		return opts, fmt.Errorf("--format must be text or json, got %q", opts.Format)
+	}
+	return opts, nil
+}
+
+// RunCheckConfig implements "live-actions serve --check-config": it loads the
+// configuration from the environment as the server would, runs every check
+// without starting the server or migrating the database, and writes the
+// report to stdout. It returns the process exit status.
+func RunCheckConfig(format string, stdout io.Writer) int {
+	logger.InitLogger("error")
+
+	ctx, cancel := context.WithTimeout(context.Background(), checkConfigTimeout)
+	defer cancel()
+
+	var report models.ConfigValidationReport
+	cfg, err := config.NewConfig()
+	if err != nil {
+		report = configcheck.LoadFailed(err, time.Now())
+	} else {
+		report = configcheck.New(func(ctx context.Context) error {
+			return database.CheckDatabaseWritable(ctx, cfg.GetDatabasePath(), cfg.Vars.DatabaseEncryptionKey)
+		}).Run(ctx, cfg)
+	}
+
+	if format == "json" {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(report)
+	} else {
+		writeReport(stdout, report)
+	}
+
+	if !report.Valid {
+		return 1
+	}
+	return 0
+}
+
+// writeReport writes report as a table followed by a summary line.
+func writeReport(w io.Writer, report models.ConfigValidationReport) {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "STATUS\tCATEGORY\tCHECK\tMESSAGE")
+	for _, check := range report.Checks {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\n", check.Status, check.Category, check.Name, check.Message)
+	}
+	_ = table.Flush()
+
+	verdict := "valid"
+	if !report.Valid {
+		verdict = "invalid"
+	}
+	fmt.Fprintf(w, "\nConfiguration is %s: %d errors, %d warnings\n", verdict, report.Errors, report.Warnings)
+}
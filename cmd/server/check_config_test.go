@@ -0,0 +1,48 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io"
+	"testing"
+
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseServeArgs(t *testing.T) {
+	opts, err := ParseServeArgs(nil, io.Discard)
+	require.NoError(t, err)
+	assert.Equal(t, ServeOptions{Format: "text"}, opts)
+
+	opts, err = ParseServeArgs([]string{"--check-config", "--format", "json"}, io.Discard)
+	require.NoError(t, err)
+	assert.Equal(t, ServeOptions{CheckConfig: true, Format: "json"}, opts)
+
+	_, err = ParseServeArgs([]string{"--format", "yaml"}, io.Discard)
+	assert.Error(t, err)
+	_, err = ParseServeArgs([]string{"extra"}, io.Discard)
+	assert.Error(t, err)
+	_, err = ParseServeArgs([]string{"-h"}, io.Discard)
+	assert.True(t, errors.Is(err, flag.ErrHelp))
+}
+
+func TestWriteReport(t *testing.T) {
+	var out bytes.Buffer
+	writeReport(&out, models.ConfigValidationReport{
+		Errors: 1,
+		Checks: []models.ConfigCheck{
+			{Category: "secrets", Name: "WEBHOOK_SECRET", Status: models.ConfigCheckOK, Message: "set"},
+			{Category: "database", Name: "DATABASE_PATH", Status: models.ConfigCheckError, Message: "database is not writable"},
+		},
+	})
+
+	assert.Equal(t, `STATUS  CATEGORY  CHECK           MESSAGE
+ok      secrets   WEBHOOK_SECRET  set
+error   database  DATABASE_PATH   database is not writable
+
+Configuration is invalid: 1 errors, 0 warnings
+`, out.String())
+}
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"context"
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// newPprofServer builds the internal diagnostics listener exposing the
+// net/http/pprof profiles and expvar runtime stats (memstats, cmdline).
+// It uses its own mux so none of these handlers leak onto the public router.
+func newPprofServer(addr string) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	return &http.Server{
+		Addr:        addr,
+		Handler:     mux,
+		ReadTimeout: 30 * time.Second,
+		// CPU profiles and traces stream for the requested duration (30s by default)
+		WriteTimeout: 2 * time.Minute,
+		IdleTimeout:  60 * time.Second,
+	}
+}
+
+// startPprofServer starts the diagnostics listener in the background and
+// returns a function that shuts it down.
+func startPprofServer(addr string) func() {
+	srv := newPprofServer(addr)
+
+	go func() {
+		logger.Logger.Info("Starting pprof diagnostics listener", zap.String("addr", addr))
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Logger.Error("pprof diagnostics listener failed", zap.Error(err))
+		}
+	}()
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			logger.Logger.Error("Failed to stop pprof diagnostics listener", zap.Error(err))
+		}
+	}
+}
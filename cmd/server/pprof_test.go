@@ -0,0 +1,41 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPprofServer_ServesProfiles(t *testing.T) {
+	srv := newPprofServer("127.0.0.1:0")
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/debug/pprof/", nil)
+	srv.Handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "heap")
+}
+
+func TestNewPprofServer_ServesRuntimeVars(t *testing.T) {
+	srv := newPprofServer("127.0.0.1:0")
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/debug/vars", nil)
+	srv.Handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "memstats")
+}
+
+func TestNewPprofServer_UnknownPath(t *testing.T) {
+	srv := newPprofServer("127.0.0.1:0")
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/workflow-runs", nil)
+	srv.Handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
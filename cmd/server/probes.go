@@ -0,0 +1,53 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gateixeira/live-actions/handlers"
+	"github.com/gateixeira/live-actions/internal/config"
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/internal/middleware"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// startStartupProbes answers /healthz, /readyz and the traffic gate on addr
+// while the database is being migrated, so orchestrators see a live but unready instance rather
+// than a closed port. The returned function stops it to free addr for the
+// server.
+func startStartupProbes(cfg *config.Config, addr string) func() {
+	srv := &http.Server{Addr: addr, Handler: newStartupProbeRouter(cfg), ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Logger.Warn("Failed to serve startup probes", zap.String("addr", addr), zap.Error(err))
+		}
+	}()
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			logger.Logger.Warn("Failed to stop startup probes", zap.Error(err))
+		}
+	}
+}
+
+// newStartupProbeRouter serves the startup probes. The traffic gate is served
+// both unversioned and under /api/v1, as the server serves it once started.
+func newStartupProbeRouter(cfg *config.Config) *gin.Engine {
+	r := gin.New()
+	r.Use(middleware.RequestID())
+	r.GET("/healthz", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+	r.GET("/readyz", handlers.Readiness(nil, database.CurrentMigrationState, nil))
+
+	readyForTraffic := handlers.ReadyForTraffic(cfg, nil, database.CurrentMigrationState, nil)
+	r.GET("/api/system/ready-for-traffic", middleware.Deprecated(), readyForTraffic)
+	r.GET(middleware.APIVersionPrefix+"/system/ready-for-traffic", middleware.Envelope(func() []models.Warning { return nil }), readyForTraffic)
+	return r
+}
@@ -3,18 +3,24 @@ package server
 import (
 	"context"
 	"embed"
+	"fmt"
 	"io/fs"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/gateixeira/live-actions/handlers"
+	"github.com/gateixeira/live-actions/internal/anonymize"
 	"github.com/gateixeira/live-actions/internal/config"
 	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/internal/federation"
 	"github.com/gateixeira/live-actions/internal/middleware"
+	"github.com/gateixeira/live-actions/internal/notify"
 	"github.com/gateixeira/live-actions/internal/services"
 	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/gateixeira/live-actions/pkg/metrics"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
@@ -27,7 +33,14 @@ func SetupAndRun(staticFS embed.FS) {
 		logger.Logger.Fatal("Failed to load configuration", zap.Error(err))
 	}
 
-	logger.InitLogger(cfg.Vars.LogLevel)
+	logger.Init(logger.Options{
+		Level:        cfg.Vars.LogLevel,
+		Format:       cfg.Vars.LogFormat,
+		Sampling:     cfg.Vars.LogSampling,
+		ModuleLevels: cfg.Vars.LogModuleLevels,
+		// Payload prefixes are only ever logged at debug level
+		DisablePayloads: !cfg.Vars.LogPayloads,
+	})
 	defer logger.SyncLogger()
 
 	if cfg.IsProduction() {
@@ -42,7 +55,12 @@ func SetupAndRun(staticFS embed.FS) {
 		}
 	}
 
-	sqlDB, err := database.InitDB(dbPath)
+	stopStartupProbes := startStartupProbes(cfg, ":"+cfg.Vars.Port)
+	sqlDB, err := database.InitDB(dbPath, cfg.Vars.DatabaseEncryptionKey, database.MigrationOptions{
+		Wait:    cfg.Vars.WaitForMigrations,
+		Timeout: cfg.GetMigrationTimeout(),
+	})
+	stopStartupProbes()
 	if err != nil {
 		logger.Logger.Error("Failed to initialize database", zap.Error(err))
 		os.Exit(1)
@@ -54,26 +72,102 @@ func SetupAndRun(staticFS embed.FS) {
 		}
 	}()
 
-	db := database.NewDBWrapper(sqlDB)
+	db := database.NewDBWrapperWithClock(sqlDB, cfg.Clock)
 
 	ctx := context.Background()
 
 	cleanupService := services.NewCleanupService(cfg, db, ctx)
-	metricsService := services.NewMetricsUpdateService(db, 10*time.Second, ctx)
+	metrics.GetRegistry().SetLabelLimits(cfg.Vars.MaxLabelsPerJob, cfg.Vars.MaxTrackedLabels)
+	metricsService := services.NewMetricsUpdateService(cfg, db, 10*time.Second, ctx)
+	notifier := notify.NewNotifier(cfg.Vars.AlertWebhookURL, db, handlers.SendAlert)
+	notifier.SetClock(cfg.Clock)
+	degradation := services.NewDegradation(handlers.SendDegradation)
+	alertService := services.NewAlertService(cfg, db, notifier, degradation, time.Minute, ctx)
+
+	var canaryService *services.CanaryService
+	if cfg.CanaryEnabled() {
+		canaryService = services.NewCanaryService(cfg, db, notifier, ctx)
+	}
+
+	var workflowChangeService *services.WorkflowChangeService
+	if cfg.WorkflowChangesEnabled() {
+		workflowChangeService = services.NewWorkflowChangeService(cfg, db, ctx)
+	}
+
+	var snapshotPublisher *services.SnapshotPublisher
+	if cfg.SnapshotEnabled() {
+		snapshotPublisher, err = services.NewSnapshotPublisher(cfg, db, ctx)
+		if err != nil {
+			logger.Logger.Fatal("Invalid snapshot configuration", zap.Error(err))
+		}
+	}
 
 	handlers.InitSSEHandler()
 	sseHandler := handlers.GetSSEHandler()
-	webhookHandler := handlers.NewWebhookHandler(cfg, db)
+	var anonymizer *anonymize.Anonymizer
+	if cfg.Vars.Anonymize {
+		anonymizer = anonymize.New(cfg.Vars.AnonymizeKey)
+		sseHandler.SetAnonymizer(anonymizer)
+		logger.Logger.Info("Anonymizing repository, user and run names in API and SSE responses, the feed and handoff reports")
+	}
+	sseHandler.SetClientLimit(cfg.Vars.SSEMaxClients, cfg.Vars.SSEOverflowMode, time.Duration(cfg.Vars.SSERetryAfterSeconds)*time.Second)
+	sseHandler.SetDegradation(degradation.Warnings)
+	webhookHandler := handlers.NewWebhookHandler(cfg, db, notifier)
 	apiHandler := handlers.NewAPIHandler(cfg, db)
+	apiHandler.SetAnonymizer(anonymizer)
 	metricsHandler := handlers.NewMetricsHandler()
+	adminHandler := handlers.NewAdminHandler(cfg, db, metricsService, sseHandler)
+	federationHandler := handlers.NewFederationHandler(cfg, db)
 
-	r := gin.New()
+	// Services stop in reverse, so webhook events are processed until the
+	// others have stopped
+	serviceManager := services.NewManager(10 * time.Second)
+	serviceManager.Register("event-ordering", services.Background(webhookHandler.OrderingService()))
+	serviceManager.Register("cleanup", cleanupService)
+	serviceManager.Register("metrics-update", metricsService)
+	serviceManager.Register("alerts", alertService)
+	if canaryService != nil {
+		serviceManager.Register("canary", canaryService)
+	}
+	if workflowChangeService != nil {
+		serviceManager.Register("workflow-changes", workflowChangeService)
+	}
+	if snapshotPublisher != nil {
+		serviceManager.Register("snapshot-publisher", snapshotPublisher)
+	}
 
+	if err := adminHandler.LoadSettings(ctx); err != nil {
+		logger.Logger.Error("Failed to load runtime settings, using defaults", zap.Error(err))
+	}
+	if err := handlers.LoadCSRFKey(ctx, cfg, db); err != nil {
+		logger.Logger.Error("Failed to load CSRF signing key, using one for this instance only", zap.Error(err))
+	}
+
+	r, err := newRouter(cfg)
+	if err != nil {
+		logger.Logger.Fatal("Failed to create router", zap.Error(err))
+	}
+
+	if cfg.Vars.AccessLog != "" {
+		accessLog, err := middleware.NewAccessLogWriter(cfg.Vars.AccessLog)
+		if err != nil {
+			logger.Logger.Fatal("Failed to open access log", zap.Error(err))
+		}
+		defer accessLog.Close()
+		watchAccessLogRotation(accessLog)
+		r.Use(middleware.AccessLogger(accessLog, cfg.Vars.AccessLogFormat))
+	}
+
+	r.Use(middleware.RequestID())
+	if anonymizer != nil {
+		r.Use(middleware.Anonymize(anonymizer))
+	}
 	r.Use(middleware.ErrorHandler())
 	r.Use(middleware.RequestLogger())
 	r.Use(middleware.SecurityLogger())
 	r.Use(middleware.SecurityHeaders(cfg))
 	r.Use(middleware.InputValidator())
+	r.Use(middleware.Warnings(degradation.Warnings))
 
 	// Serve static assets from embedded FS
 	distFS, err := fs.Sub(staticFS, "frontend/dist")
@@ -89,18 +183,21 @@ func SetupAndRun(staticFS embed.FS) {
 
 	// Routes
 	r.POST("/webhook", handlers.ValidateGitHubWebhook(cfg), webhookHandler.Handle())
-	r.GET("/api/csrf", apiHandler.GetCSRFToken())
-	r.GET("/api/workflow-runs", handlers.ValidateOrigin(), apiHandler.GetWorkflowRuns())
-	r.GET("/api/workflow-jobs/:run_id", handlers.ValidateOrigin(), apiHandler.GetWorkflowJobsByRunID())
-	r.GET("/api/metrics/query_range", handlers.ValidateOrigin(), apiHandler.GetCurrentMetrics())
-	r.GET("/api/analytics/failures", handlers.ValidateOrigin(), apiHandler.GetFailureAnalytics())
-	r.GET("/api/analytics/labels", handlers.ValidateOrigin(), apiHandler.GetLabelDemand())
-	r.GET("/api/repositories", handlers.ValidateOrigin(), apiHandler.GetRepositories())
+
+	// API routes are served unversioned for existing dashboards and scripts,
+	// and under /api/v1 with a standard response envelope
+	registerAPIRoutes(r.Group("/api", middleware.Deprecated()), cfg, db, apiHandler, adminHandler, federationHandler, sseHandler, serviceManager.Health)
+	registerAPIRoutes(r.Group(middleware.APIVersionPrefix, middleware.Envelope(degradation.Warnings)), cfg, db, apiHandler, adminHandler, federationHandler, sseHandler, serviceManager.Health)
+
+	r.GET("/badge/:owner/:repo", apiHandler.GetStatusBadge())
+	r.GET("/badge/:owner/:repo/:workflow", apiHandler.GetStatusBadge())
+	r.GET("/feed.atom", apiHandler.GetActivityFeed())
 	r.GET("/events", handlers.ValidateSSEOrigin(), sseHandler.HandleSSE())
 	r.GET("/metrics", metricsHandler.Metrics())
 	r.GET("/healthz", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
 	})
+	r.GET("/readyz", handlers.Readiness(db, database.CurrentMigrationState, serviceManager.Health))
 
 	// Serve the React SPA for all other routes
 	indexHTML, err := fs.ReadFile(staticFS, "frontend/dist/index.html")
@@ -118,11 +215,18 @@ func SetupAndRun(staticFS embed.FS) {
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// Event streams never go idle on their own, so end them when shutdown starts
+	srv.RegisterOnShutdown(func() { sseHandler.Shutdown(cfg.GetRestartDowntime()) })
+
 	// Setup graceful shutdown
 	gracefulShutdown := NewGracefulShutdown(srv, 30*time.Second)
 
-	go cleanupService.Start()
-	go metricsService.Start()
+	if cfg.Vars.PprofEnabled {
+		stopPprof := startPprofServer(cfg.Vars.PprofAddr)
+		defer stopPprof()
+	}
+
+	serviceManager.Start()
 	go gracefulShutdown.Start()
 
 	logger.Logger.Info("Starting server",
@@ -132,6 +236,8 @@ func SetupAndRun(staticFS embed.FS) {
 		zap.Int("data_retention_days", cfg.Vars.DataRetentionDays),
 		zap.Int("cleanup_interval_hours", cfg.Vars.CleanupIntervalHours),
 		zap.String("log_level", cfg.Vars.LogLevel),
+		zap.String("log_format", cfg.Vars.LogFormat),
+		zap.Bool("pprof_enabled", cfg.Vars.PprofEnabled),
 	)
 
 	// Start server
@@ -144,13 +250,22 @@ func SetupAndRun(staticFS embed.FS) {
 	gracefulShutdown.Wait()
 
 	// Stop services
-	webhookHandler.Shutdown()
-	cleanupService.Stop()
-	metricsService.Stop()
+	serviceManager.Stop()
 
 	logger.Logger.Info("Server shutdown complete")
 }
 
+// newRouter creates the HTTP engine. Only requests from TRUSTED_PROXIES may set
+// the client IP, which access, audit and security logs record, through
+// X-Forwarded-For or X-Real-IP; everyone else is known by their own address.
+func newRouter(cfg *config.Config) (*gin.Engine, error) {
+	r := gin.New()
+	if err := r.SetTrustedProxies(cfg.Vars.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid TRUSTED_PROXIES: %w", err)
+	}
+	return r, nil
+}
+
 func spaFallbackHandler(indexHTML []byte) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
@@ -161,3 +276,72 @@ func spaFallbackHandler(indexHTML []byte) gin.HandlerFunc {
 		c.Data(http.StatusOK, "text/html; charset=utf-8", indexHTML)
 	}
 }
+
+// registerAPIRoutes registers the JSON API on api, which is mounted at both
+// the unversioned and the versioned prefix.
+func registerAPIRoutes(api *gin.RouterGroup, cfg *config.Config, db database.DatabaseInterface, apiHandler *handlers.APIHandler,
+	adminHandler *handlers.AdminHandler, federationHandler *handlers.FederationHandler, sseHandler *handlers.SSEHandler,
+	serviceHealth func() []services.ServiceHealth) {
+	api.GET("/csrf", apiHandler.GetCSRFToken())
+	api.GET("/workflow-runs", handlers.ValidateOrigin(), apiHandler.GetWorkflowRuns())
+	api.POST("/workflow-runs/:run_id/tags", handlers.ValidateOrigin(), apiHandler.AddRunTag())
+	api.DELETE("/workflow-runs/:run_id/tags/:tag", handlers.ValidateOrigin(), apiHandler.RemoveRunTag())
+	api.GET("/saved-filters", handlers.ValidateOrigin(), apiHandler.GetSavedFilters())
+	api.POST("/saved-filters", handlers.ValidateOrigin(), apiHandler.SaveFilter())
+	api.DELETE("/saved-filters/:id", handlers.ValidateOrigin(), apiHandler.DeleteSavedFilter())
+	api.GET("/repo-groups", handlers.ValidateOrigin(), apiHandler.GetRepoGroups())
+	api.PUT("/repo-groups/:name", handlers.ValidateOrigin(), apiHandler.SaveRepoGroup())
+	api.DELETE("/repo-groups/:name", handlers.ValidateOrigin(), apiHandler.DeleteRepoGroup())
+	api.GET("/slo", handlers.ValidateOrigin(), apiHandler.GetSLOs())
+	api.PUT("/slo/:name", handlers.ValidateOrigin(), apiHandler.SaveSLO())
+	api.DELETE("/slo/:name", handlers.ValidateOrigin(), apiHandler.DeleteSLO())
+	api.GET("/mutes", handlers.ValidateOrigin(), apiHandler.GetMutes())
+	api.POST("/mutes", handlers.ValidateOrigin(), apiHandler.CreateMute())
+	api.DELETE("/mutes/:id", handlers.ValidateOrigin(), apiHandler.DeleteMute())
+	api.GET("/workflow-jobs/:run_id", handlers.ValidateOrigin(), apiHandler.GetWorkflowJobsByRunID())
+	api.POST("/workflow-jobs/batch", handlers.ValidateOrigin(), apiHandler.GetWorkflowJobsBatch())
+	api.GET("/metrics/query_range", handlers.ValidateOrigin(), apiHandler.GetCurrentMetrics())
+	api.GET("/metrics/sparklines", handlers.ValidateOrigin(), apiHandler.GetSparklines())
+	api.GET("/analytics/failures", handlers.ValidateOrigin(), apiHandler.GetFailureAnalytics())
+	api.GET("/analytics/labels", handlers.ValidateOrigin(), apiHandler.GetLabelDemand())
+	api.GET("/analytics/regressions", handlers.ValidateOrigin(), apiHandler.GetDurationRegressions())
+	api.GET("/analytics/capacity", handlers.ValidateOrigin(), apiHandler.GetCapacitySimulation())
+	api.GET("/analytics/saturation", handlers.ValidateOrigin(), apiHandler.GetSaturationAnalytics())
+	api.GET("/analytics/queue-leaderboard", handlers.ValidateOrigin(), apiHandler.GetQueueLeaderboard())
+	api.GET("/analytics/queue-attribution", handlers.ValidateOrigin(), apiHandler.GetQueueAttribution())
+	api.GET("/analytics/throughput", handlers.ValidateOrigin(), apiHandler.GetThroughput())
+	api.GET("/analytics/unschedulable", handlers.ValidateOrigin(), apiHandler.GetUnschedulableJobs())
+	api.GET("/analytics/runner-hosts", handlers.ValidateOrigin(), apiHandler.GetRunnerHostAnalytics())
+	api.GET("/analytics/actors", handlers.ValidateOrigin(), apiHandler.GetActorAnalytics())
+	api.GET("/analytics/approvals", handlers.ValidateOrigin(), apiHandler.GetApprovalAnalytics())
+	api.GET("/job-names/merges", handlers.ValidateOrigin(), apiHandler.GetJobNameMerges())
+	api.POST("/job-names/merges", handlers.ValidateOrigin(), apiHandler.SaveJobNameMerge())
+	api.DELETE("/job-names/merges", handlers.ValidateOrigin(), apiHandler.DeleteJobNameMerge())
+	api.GET("/runner-groups", handlers.ValidateOrigin(), apiHandler.GetRunnerGroups())
+	api.GET("/runner-hosts", handlers.ValidateOrigin(), apiHandler.GetRunnerHosts())
+	api.PUT("/runner-hosts/:name", handlers.ValidateOrigin(), apiHandler.SaveRunnerHost())
+	api.DELETE("/runner-hosts/:name", handlers.ValidateOrigin(), apiHandler.DeleteRunnerHost())
+	api.POST("/runner-hosts/heartbeat", handlers.RequireRunnerHeartbeatToken(cfg), apiHandler.RunnerHeartbeat())
+	api.GET("/canary", handlers.ValidateOrigin(), apiHandler.GetCanaryResults())
+	api.GET("/hosts/metrics", handlers.ValidateOrigin(), apiHandler.GetHostMetrics())
+	api.GET("/reports/handoff", handlers.ValidateOrigin(), apiHandler.GetHandoffReport())
+	api.GET("/repositories", handlers.ValidateOrigin(), apiHandler.GetRepositories())
+	api.GET("/system/stats", handlers.ValidateOrigin(), apiHandler.GetSystemStats())
+	api.GET("/system/storage", handlers.ValidateOrigin(), apiHandler.GetSystemStorage())
+	api.GET("/system/data-quality", handlers.ValidateOrigin(), apiHandler.GetDataQuality())
+	api.GET("/system/ready-for-traffic", handlers.ReadyForTraffic(cfg, db, database.CurrentMigrationState, serviceHealth))
+	// The topology names the integrations, proxies and authentication in use
+	api.GET("/system/topology", handlers.RequireAdminToken(cfg), apiHandler.GetSystemTopology())
+	api.GET("/workflow-runs/:run_id/live", handlers.ValidateSSEOrigin(), sseHandler.HandleRunSSE(db))
+	api.GET("/admin/config", handlers.RequireAdminToken(cfg), adminHandler.GetConfig())
+	api.GET("/admin/config/validate", handlers.RequireAdminToken(cfg), apiHandler.ValidateConfig())
+	api.PUT("/admin/config", handlers.RequireAdminToken(cfg), adminHandler.UpdateConfig())
+	api.DELETE("/admin/events", handlers.RequireAdminToken(cfg), adminHandler.PurgeWebhookEvents())
+	api.GET("/admin/events/distribution", handlers.RequireAdminToken(cfg), adminHandler.GetEventDistribution())
+	api.POST("/admin/support-bundle", handlers.RequireAdminToken(cfg), apiHandler.CreateSupportBundle())
+	api.POST("/admin/security/rotate-csrf", handlers.RequireAdminToken(cfg), adminHandler.RotateCSRFKey())
+	api.GET("/federation/overview", handlers.ValidateOrigin(), federationHandler.GetOverview())
+	// Peers fetch the unversioned federation.SummaryPath
+	api.GET(strings.TrimPrefix(federation.SummaryPath, "/api"), handlers.RequireFederationToken(cfg), federationHandler.GetSummary())
+	api.POST("/remote-write", handlers.RequireRemoteWriteToken(cfg), apiHandler.ReceiveRemoteWrite())
+}
@@ -1,12 +1,21 @@
 package server
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/gateixeira/live-actions/handlers"
+	"github.com/gateixeira/live-actions/internal/config"
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/internal/federation"
+	"github.com/gateixeira/live-actions/internal/middleware"
+	"github.com/gateixeira/live-actions/pkg/logger"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSPAFallbackHandler_GETServesIndex(t *testing.T) {
@@ -35,3 +44,112 @@ func TestSPAFallbackHandler_NonGETReturnsNotFound(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, w.Code)
 	assert.Contains(t, w.Body.String(), "route not found")
 }
+
+func TestNewRouter_TrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r, err := newRouter(&config.Config{Vars: config.Vars{TrustedProxies: []string{"10.0.0.0/8"}}})
+	require.NoError(t, err)
+	r.GET("/ip", func(c *gin.Context) { c.String(http.StatusOK, c.ClientIP()) })
+
+	clientIP := func(remoteAddr, forwardedFor string) string {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/ip", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		r.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	assert.Equal(t, "203.0.113.7", clientIP("10.1.2.3:4567", "203.0.113.7"), "the load balancer forwards the client IP")
+	assert.Equal(t, "203.0.113.7", clientIP("10.1.2.3:4567", "198.51.100.1, 203.0.113.7"), "entries before the last untrusted hop can be forged")
+	assert.Equal(t, "198.51.100.9", clientIP("198.51.100.9:4567", "203.0.113.7"), "other clients cannot set their IP")
+}
+
+func TestNewRouter_NoTrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r, err := newRouter(&config.Config{})
+	require.NoError(t, err)
+	r.GET("/ip", func(c *gin.Context) { c.String(http.StatusOK, c.ClientIP()) })
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/ip", nil)
+	req.RemoteAddr = "10.1.2.3:4567"
+	req.Header.Set("X-Forwarded-For", "203.0.113.7")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, "10.1.2.3", w.Body.String())
+}
+
+func TestRegisterAPIRoutes_TopologyRequiresAdminToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger.InitLogger("error")
+	cfg := &config.Config{Vars: config.Vars{AdminToken: "admin-token"}}
+	mockDB := new(database.MockDatabase)
+	r := gin.New()
+	registerAPIRoutes(r.Group("/api"), cfg, mockDB, handlers.NewAPIHandler(cfg, mockDB),
+		handlers.NewAdminHandler(cfg, mockDB, nil, nil), handlers.NewFederationHandler(cfg, mockDB), handlers.GetSSEHandler(), nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/system/topology", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/api/system/topology", nil)
+	req.Header.Set("Authorization", "Bearer admin-token")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRegisterAPIRoutes_ServesEveryRouteInBothVersions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	mockDB := new(database.MockDatabase)
+	r := gin.New()
+	for _, prefix := range []string{"/api", middleware.APIVersionPrefix} {
+		registerAPIRoutes(r.Group(prefix), cfg, mockDB, handlers.NewAPIHandler(cfg, mockDB),
+			handlers.NewAdminHandler(cfg, mockDB, nil, nil), handlers.NewFederationHandler(cfg, mockDB), handlers.GetSSEHandler(), nil)
+	}
+
+	routes := make(map[string]bool)
+	for _, route := range r.Routes() {
+		routes[route.Method+" "+route.Path] = true
+	}
+	for _, route := range []string{"POST /remote-write", "GET /federation/summary", "GET /system/ready-for-traffic"} {
+		method, path, _ := strings.Cut(route, " ")
+		assert.True(t, routes[method+" /api"+path], route)
+		assert.True(t, routes[method+" "+middleware.APIVersionPrefix+path], route)
+	}
+	assert.True(t, routes["GET "+federation.SummaryPath], "peers fetch the unversioned summary")
+
+	probeRoutes := make(map[string]bool)
+	for _, route := range newStartupProbeRouter(cfg).Routes() {
+		probeRoutes[route.Method+" "+route.Path] = true
+	}
+	assert.True(t, probeRoutes["GET /api/system/ready-for-traffic"], "startup probes serve the unversioned traffic gate")
+	assert.True(t, probeRoutes["GET "+middleware.APIVersionPrefix+"/system/ready-for-traffic"], "startup probes serve the versioned traffic gate")
+}
+
+func TestStartupProbes_VersionedTrafficGateIsEnveloped(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger.InitLogger("error")
+	r := newStartupProbeRouter(&config.Config{})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, middleware.APIVersionPrefix+"/system/ready-for-traffic", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Contains(t, body, "data")
+	assert.Contains(t, body, "request_id")
+	assert.Equal(t, []interface{}{}, body["warnings"])
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/api/system/ready-for-traffic", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.NotContains(t, w.Body.String(), `"request_id"`)
+}
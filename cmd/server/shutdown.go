@@ -8,6 +8,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/gateixeira/live-actions/internal/middleware"
 	"github.com/gateixeira/live-actions/pkg/logger"
 	"go.uber.org/zap"
 )
@@ -78,3 +79,20 @@ func (gs *GracefulShutdown) IsShuttingDown() bool {
 		return false
 	}
 }
+
+// watchAccessLogRotation reopens the access log file whenever the process
+// receives SIGHUP, which is what logrotate's postrotate hook typically sends.
+func watchAccessLogRotation(w *middleware.AccessLogWriter) {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGHUP)
+
+	go func() {
+		for range sigChan {
+			if err := w.Reopen(); err != nil {
+				logger.Logger.Error("Failed to reopen access log", zap.Error(err))
+				continue
+			}
+			logger.Logger.Info("Access log reopened")
+		}
+	}()
+}
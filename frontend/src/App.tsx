@@ -6,9 +6,10 @@ import { WorkflowTable } from './components/WorkflowTable'
 import { FailureAnalytics } from './components/FailureAnalytics'
 import { LabelDemand } from './components/LabelDemand'
 import { Sidebar } from './components/Sidebar'
+import { DegradedBanner } from './components/DegradedBanner'
 import { useSSE } from './hooks/useSSE'
 import { getMetrics, getRepositories, initCsrf } from './api/client'
-import type { MetricsResponse, Period } from './api/types'
+import type { DegradationWarning, MetricsResponse, Period } from './api/types'
 
 type Page = 'dashboard' | 'failures' | 'labels'
 
@@ -47,12 +48,16 @@ export default function App() {
     initCsrf().then(() => setReady(true))
   }, [])
 
+  // Bumped by config_changed events to refetch the reference data they name
+  const [reposRefresh, setReposRefresh] = useState(0)
+  const [analyticsRefresh, setAnalyticsRefresh] = useState(0)
+
   useEffect(() => {
     if (!ready) return
     getRepositories()
       .then((r) => setRepos(r.repositories))
       .catch((err) => console.error('Failed to load repositories', err))
-  }, [ready])
+  }, [ready, reposRefresh])
 
   const loadMetrics = useCallback(
     (p: Period) => {
@@ -71,15 +76,41 @@ export default function App() {
   }, [period, loadMetrics, ready])
 
   const [workflowRefresh, setWorkflowRefresh] = useState(0)
+  // Sent on connect and whenever a subsystem degrades or recovers
+  const [degraded, setDegraded] = useState<DegradationWarning[]>([])
 
-  const { connected } = useSSE({
+  const { connected, restarting, polling } = useSSE({
     onMetricsUpdate: (data) => {
       setLiveRunning(data.running_jobs)
       setLiveQueued(data.queued_jobs)
     },
-    onWorkflowUpdate: () => {
+    onWorkflowUpdate: (data) => {
+      // Job updates don't change the runs table
+      if (data.type === 'run') setWorkflowRefresh((r) => r + 1)
+    },
+    onDegraded: (data) => setDegraded(data.warnings ?? []),
+    onPoll: () => {
+      // No live updates while turned away, so refresh what they would change
+      setLiveRunning(null)
+      setLiveQueued(null)
+      loadMetrics(period)
       setWorkflowRefresh((r) => r + 1)
     },
+    onConfigChanged: ({ kind }) => {
+      switch (kind) {
+        case 'repo_groups':
+          setReposRefresh((r) => r + 1)
+          setAnalyticsRefresh((r) => r + 1)
+          setWorkflowRefresh((r) => r + 1)
+          break
+        case 'settings':
+          loadMetrics(period)
+          break
+        default:
+          // Mutes, job names, runner hosts and SLOs only feed the analytics
+          setAnalyticsRefresh((r) => r + 1)
+      }
+    },
   })
 
   const running = liveRunning ?? metricsData?.current_metrics?.running_jobs ?? 0
@@ -96,7 +127,7 @@ export default function App() {
 
   return (
     <div className="flex min-h-screen">
-      <Sidebar activePage={activePage} onNavigate={setActivePage} connected={connected} />
+      <Sidebar activePage={activePage} onNavigate={setActivePage} connected={connected} restarting={restarting} polling={polling} />
 
       {/* Main content */}
       <main className="ml-56 flex-1 min-h-screen">
@@ -168,6 +199,8 @@ export default function App() {
           </div>
         </header>
 
+        <DegradedBanner warnings={degraded} />
+
         {/* Page content */}
         <div className="p-6">
           {activePage === 'dashboard' && (
@@ -198,11 +231,11 @@ export default function App() {
           )}
 
           {activePage === 'failures' && (
-            <FailureAnalytics ready={ready} repo={selectedRepo} />
+            <FailureAnalytics ready={ready} refreshSignal={analyticsRefresh} repo={selectedRepo} />
           )}
 
           {activePage === 'labels' && (
-            <LabelDemand ready={ready} repo={selectedRepo} />
+            <LabelDemand ready={ready} refreshSignal={analyticsRefresh} repo={selectedRepo} />
           )}
         </div>
       </main>
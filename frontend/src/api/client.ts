@@ -1,4 +1,5 @@
 import type {
+  Envelope,
   WorkflowRunsResponse,
   WorkflowJobsResponse,
   MetricsResponse,
@@ -8,13 +9,16 @@ import type {
   Period,
 } from './types'
 
+// API_BASE is the versioned API; its responses are enveloped
+const API_BASE = '/api/v1'
+
 let csrfToken: string | null = null
 
 function getCsrfToken(): string | null {
   return csrfToken
 }
 
-// Fetch /api/csrf to get the CSRF token and set the cookie
+// Fetch the CSRF token and set the cookie
 export async function initCsrf(): Promise<void> {
   if (getCsrfToken()) return
   await refreshCsrf()
@@ -23,9 +27,9 @@ export async function initCsrf(): Promise<void> {
 // Force-refresh the CSRF token (called on init and after 403 errors)
 async function refreshCsrf(): Promise<void> {
   try {
-    const res = await fetch('/api/csrf', { credentials: 'same-origin' })
-    const data = await res.json()
-    if (data.token) csrfToken = data.token
+    const res = await fetch(`${API_BASE}/csrf`, { credentials: 'same-origin' })
+    const body: Envelope<{ token?: string }> = await res.json()
+    if (body.data?.token) csrfToken = body.data.token
   } catch {
     // Non-fatal: API calls will fail with 403 if CSRF is missing
   }
@@ -38,7 +42,27 @@ function headers(): Record<string, string> {
   return h
 }
 
-async function fetchJson<T>(url: string): Promise<T> {
+// unwrap returns the data of an envelope, with its pagination put back where
+// the unversioned response had it, or throws its error.
+async function unwrap<T>(res: Response): Promise<T> {
+  let body: Envelope<T> | null = null
+  try {
+    body = await res.json()
+  } catch {
+    // Not an envelope, e.g. a proxy error page
+  }
+  if (!res.ok || !body) {
+    const message = body?.error ?? `${res.status} ${res.statusText}`
+    throw new Error(body?.request_id ? `${message} (request ${body.request_id})` : message)
+  }
+  if (body.pagination && body.data && typeof body.data === 'object') {
+    return { ...body.data, pagination: body.pagination }
+  }
+  return body.data
+}
+
+async function fetchJson<T>(path: string): Promise<T> {
+  const url = `${API_BASE}${path}`
   const res = await fetch(url, {
     headers: headers(),
     credentials: 'same-origin',
@@ -50,11 +74,9 @@ async function fetchJson<T>(url: string): Promise<T> {
       headers: headers(),
       credentials: 'same-origin',
     })
-    if (!retry.ok) throw new Error(`${retry.status} ${retry.statusText}`)
-    return retry.json()
+    return unwrap<T>(retry)
   }
-  if (!res.ok) throw new Error(`${res.status} ${res.statusText}`)
-  return res.json()
+  return unwrap<T>(res)
 }
 
 function repoParam(repo: string): string {
@@ -68,33 +90,33 @@ export async function getWorkflowRuns(
   status = '',
 ): Promise<WorkflowRunsResponse> {
   const statusParam = status ? `&status=${encodeURIComponent(status)}` : ''
-  return fetchJson(`/api/workflow-runs?page=${page}&limit=${limit}${repoParam(repo)}${statusParam}`)
+  return fetchJson(`/workflow-runs?page=${page}&limit=${limit}${repoParam(repo)}${statusParam}`)
 }
 
 export async function getWorkflowJobs(
   runId: number,
 ): Promise<WorkflowJobsResponse> {
-  return fetchJson(`/api/workflow-jobs/${runId}`)
+  return fetchJson(`/workflow-jobs/${runId}`)
 }
 
 export async function getMetrics(period: Period): Promise<MetricsResponse> {
-  return fetchJson(`/api/metrics/query_range?period=${period}`)
+  return fetchJson(`/metrics/query_range?period=${period}`)
 }
 
 export async function getFailureAnalytics(
   period: Period,
   repo = '',
 ): Promise<FailureAnalyticsResponse> {
-  return fetchJson(`/api/analytics/failures?period=${period}${repoParam(repo)}`)
+  return fetchJson(`/analytics/failures?period=${period}${repoParam(repo)}`)
 }
 
 export async function getLabelDemand(
   period: Period,
   repo = '',
 ): Promise<LabelDemandResponse> {
-  return fetchJson(`/api/analytics/labels?period=${period}${repoParam(repo)}`)
+  return fetchJson(`/analytics/labels?period=${period}${repoParam(repo)}`)
 }
 
 export async function getRepositories(): Promise<RepositoriesResponse> {
-  return fetchJson('/api/repositories')
+  return fetchJson('/repositories')
 }
@@ -17,6 +17,18 @@ export interface WorkflowRun {
   run_started_at: string
   updated_at: string
   repository_name: string
+  head_sha: string
+  head_commit?: HeadCommit
+  tags?: string[]
+}
+
+export interface HeadCommit {
+  id: string
+  message: string
+  author: {
+    name: string
+    email: string
+  }
 }
 
 export interface WorkflowJob {
@@ -30,6 +42,15 @@ export interface WorkflowJob {
   started_at: string
   completed_at: string
   run_id: number
+  eta?: JobETA
+}
+
+export interface JobETA {
+  estimated_completion: string
+  earliest: string
+  latest: string
+  progress: number
+  samples: number
 }
 
 export interface Pagination {
@@ -66,6 +87,40 @@ export interface WorkflowUpdateEvent {
   workflow_run?: WorkflowRun
 }
 
+export interface ServerRestartingEvent {
+  expected_downtime_seconds: number
+  timestamp: string
+}
+
+export interface PollEvent {
+  interval_seconds: number
+}
+
+export interface DegradationWarning {
+  code: string
+  message: string
+  since: string
+}
+
+// Every /api/v1 response is wrapped in an envelope. Pagination and error
+// fields of the unversioned response are lifted out of data.
+export interface Envelope<T> {
+  data: T
+  pagination?: Pagination
+  error?: string
+  warnings: DegradationWarning[]
+  request_id: string
+}
+
+export interface DegradedEvent {
+  warnings: DegradationWarning[]
+}
+
+export interface ConfigChangedEvent {
+  kind: 'repo_groups' | 'mutes' | 'runner_hosts' | 'settings' | 'slos' | 'job_names'
+  timestamp: string
+}
+
 export interface TimeSeriesEntry {
   metric: Record<string, string>
   values: [number, string][]
@@ -101,6 +156,7 @@ export interface FailureAnalyticsSummary {
   total_completed: number
   total_failed: number
   total_cancelled: number
+  total_muted: number
   failure_rate: number
   top_failing_jobs: FailingJob[]
 }
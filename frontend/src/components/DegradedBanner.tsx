@@ -0,0 +1,19 @@
+import { AlertTriangle } from 'lucide-react'
+import type { DegradationWarning } from '../api/types'
+
+// DegradedBanner tells the user data may be delayed while the server reports
+// a degraded subsystem, such as a webhook processing backlog.
+export function DegradedBanner({ warnings }: { warnings: DegradationWarning[] }) {
+  if (warnings.length === 0) return null
+
+  return (
+    <div className="flex items-start gap-2 border-b border-amber-400/20 bg-amber-400/10 px-6 py-2 text-xs text-amber-300">
+      <AlertTriangle className="mt-0.5 h-3.5 w-3.5 shrink-0 text-amber-400" />
+      <ul className="space-y-0.5">
+        {warnings.map((w) => (
+          <li key={w.code}>{w.message}</li>
+        ))}
+      </ul>
+    </div>
+  )
+}
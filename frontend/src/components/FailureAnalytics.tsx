@@ -17,10 +17,12 @@ import type { FailureAnalyticsResponse, Period } from '../api/types'
 
 interface Props {
   ready: boolean
+  // Bumped when reference data the analytics depend on changed
+  refreshSignal: number
   repo: string
 }
 
-export function FailureAnalytics({ ready, repo }: Props) {
+export function FailureAnalytics({ ready, refreshSignal, repo }: Props) {
   const [period, setPeriod] = useState<Period>('day')
   const [data, setData] = useState<FailureAnalyticsResponse | null>(null)
 
@@ -38,7 +40,7 @@ export function FailureAnalytics({ ready, repo }: Props) {
     load(period)
     const interval = setInterval(() => load(period), 30_000)
     return () => clearInterval(interval)
-  }, [period, load, ready])
+  }, [period, load, ready, refreshSignal])
 
   const trendData = useMemo(() => {
     if (!data?.trend) return []
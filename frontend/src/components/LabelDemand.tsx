@@ -21,10 +21,12 @@ const CHART_COLORS = [
 
 interface Props {
   ready: boolean
+  // Bumped when reference data the analytics depend on changed
+  refreshSignal: number
   repo: string
 }
 
-export function LabelDemand({ ready, repo }: Props) {
+export function LabelDemand({ ready, refreshSignal, repo }: Props) {
   const [period, setPeriod] = useState<Period>('day')
   const [data, setData] = useState<LabelDemandResponse | null>(null)
 
@@ -39,7 +41,7 @@ export function LabelDemand({ ready, repo }: Props) {
     load(period)
     const interval = setInterval(() => load(period), 30_000)
     return () => clearInterval(interval)
-  }, [period, load, ready])
+  }, [period, load, ready, refreshSignal])
 
   const labels = useMemo(() => {
     if (!data?.summary) return []
@@ -12,6 +12,8 @@ interface SidebarProps {
   activePage: Page
   onNavigate: (page: Page) => void
   connected: boolean
+  restarting?: boolean
+  polling?: boolean
 }
 
 const NAV_ITEMS: { id: Page; label: string; icon: typeof LayoutDashboard }[] = [
@@ -20,7 +22,7 @@ const NAV_ITEMS: { id: Page; label: string; icon: typeof LayoutDashboard }[] = [
   { id: 'labels', label: 'Runner Labels', icon: Tags },
 ]
 
-export function Sidebar({ activePage, onNavigate, connected }: SidebarProps) {
+export function Sidebar({ activePage, onNavigate, connected, restarting, polling }: SidebarProps) {
   return (
     <aside className="fixed inset-y-0 left-0 z-30 flex w-56 flex-col border-r border-gray-800 bg-gray-900">
       {/* Logo */}
@@ -56,11 +58,17 @@ export function Sidebar({ activePage, onNavigate, connected }: SidebarProps) {
           <span
             className={clsx(
               'h-2 w-2 rounded-full',
-              connected ? 'bg-emerald-400' : 'bg-red-400',
+              connected ? 'bg-emerald-400' : restarting ? 'bg-amber-400 animate-pulse' : polling ? 'bg-amber-400' : 'bg-red-400',
             )}
           />
           <span className="text-gray-500">
-            {connected ? 'Connected' : 'Disconnected'}
+            {connected
+              ? 'Connected'
+              : restarting
+                ? 'Server restarting, reconnecting…'
+                : polling
+                  ? 'Server busy, polling for updates'
+                  : 'Disconnected'}
           </span>
         </div>
       </div>
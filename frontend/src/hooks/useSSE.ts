@@ -1,9 +1,20 @@
 import { useEffect, useRef, useState } from 'react'
-import type { MetricsUpdateEvent, WorkflowUpdateEvent } from '../api/types'
+import type {
+  ConfigChangedEvent,
+  DegradedEvent,
+  MetricsUpdateEvent,
+  PollEvent,
+  ServerRestartingEvent,
+  WorkflowUpdateEvent,
+} from '../api/types'
 
 interface SSECallbacks {
   onMetricsUpdate?: (data: MetricsUpdateEvent) => void
   onWorkflowUpdate?: (data: WorkflowUpdateEvent) => void
+  onConfigChanged?: (data: ConfigChangedEvent) => void
+  onDegraded?: (data: DegradedEvent) => void
+  // Called when the server is at its stream limit and asked for polling
+  onPoll?: (data: PollEvent) => void
 }
 
 export function useSSE(callbacks: SSECallbacks) {
@@ -12,10 +23,18 @@ export function useSSE(callbacks: SSECallbacks) {
     cbRef.current = callbacks
   })
   const [connected, setConnected] = useState(false)
+  // Set when the server announced a restart, until the stream reconnects
+  const [restarting, setRestarting] = useState(false)
+  // Set while the server turns the stream away with poll events, until a
+  // stream is established again
+  const [polling, setPolling] = useState(false)
 
   useEffect(() => {
     let es: EventSource | null = null
     let retryDelay = 1000
+    // The interval a poll event asked for, used for the next reconnect
+    // instead of the backoff
+    let pollDelay: number | null = null
     let retryTimer: ReturnType<typeof setTimeout> | null = null
     let cancelled = false
 
@@ -25,6 +44,7 @@ export function useSSE(callbacks: SSECallbacks) {
 
       es.onopen = () => {
         setConnected(true)
+        setRestarting(false)
         retryDelay = 1000 // reset backoff on successful connection
       }
 
@@ -34,8 +54,26 @@ export function useSSE(callbacks: SSECallbacks) {
           // SSE can send either a raw object or a {type, data} wrapper
           if (typeof outer === 'object' && outer.type && outer.data) {
             const { type, data } = outer
+            if (type === 'connected') setPolling(false)
             if (type === 'metrics_update') cbRef.current.onMetricsUpdate?.(data)
             if (type === 'workflow_update') cbRef.current.onWorkflowUpdate?.(data)
+            if (type === 'config_changed') cbRef.current.onConfigChanged?.(data)
+            if (type === 'degraded') cbRef.current.onDegraded?.(data)
+            if (type === 'server_restarting') {
+              // Wait out the announced downtime before the first reconnect
+              const { expected_downtime_seconds } = data as ServerRestartingEvent
+              retryDelay = Math.max(expected_downtime_seconds * 1000, 1000)
+              setRestarting(true)
+            }
+            if (type === 'poll') {
+              // The server closes the stream after this; come back at the
+              // interval it asked for rather than a second later
+              const { interval_seconds } = data as PollEvent
+              pollDelay = Math.max(interval_seconds * 1000, 1000)
+              setConnected(false)
+              setPolling(true)
+              cbRef.current.onPoll?.(data)
+            }
           }
         } catch {
           // ignore unparseable messages (e.g. initial "connected" string)
@@ -46,6 +84,11 @@ export function useSSE(callbacks: SSECallbacks) {
         setConnected(false)
         es?.close()
         es = null
+        if (pollDelay !== null) {
+          retryTimer = setTimeout(connect, pollDelay)
+          pollDelay = null
+          return
+        }
         // Reconnect with exponential backoff (max 30s)
         retryTimer = setTimeout(connect, retryDelay)
         retryDelay = Math.min(retryDelay * 2, 30_000)
@@ -62,5 +105,5 @@ export function useSSE(callbacks: SSECallbacks) {
     }
   }, [])
 
-  return { connected }
+  return { connected, restarting, polling }
 }
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// maxActorStats bounds how many users one actor analytics request returns.
+const maxActorStats = 200
+
+// GetActorAnalytics returns who ran and who re-ran workflows over a period
+// (default: week), distinguishing the user a run is attributed to from the
+// one who started its latest attempt.
+func (h *APIHandler) GetActorAnalytics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		period := c.DefaultQuery("period", "week")
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+		if err != nil || limit < 1 || limit > maxActorStats {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 200"})
+			return
+		}
+		repos, ok := h.repoFilter(c)
+		if !ok {
+			return
+		}
+
+		stats, err := h.db.GetActorStats(c.Request.Context(), periodToDuration(period), repos, limit)
+		if err != nil {
+			logger.Logger.Error("Failed to get actor stats", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve actor analytics"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"period": period,
+			"actors": stats,
+		})
+	}
+}
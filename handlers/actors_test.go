@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetActorAnalytics(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/analytics/actors", handler.GetActorAnalytics())
+
+	mockDB.On("GetActorStats", mock.Anything, periodToDuration("week"), []string{"app"}, 50).Return([]models.ActorStats{
+		{Actor: "hubot", Runs: 1, Triggered: 4, ReRuns: 3, ForOthers: 3, Failed: 1},
+		{Actor: "mona", Runs: 3, Triggered: 1},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/analytics/actors?repo=app", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Period string              `json:"period"`
+		Actors []models.ActorStats `json:"actors"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "week", response.Period)
+	require.Len(t, response.Actors, 2)
+	assert.Equal(t, 3, response.Actors[0].ForOthers)
+	mockDB.AssertExpectations(t)
+}
+
+func TestGetActorAnalytics_InvalidLimit(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/analytics/actors", handler.GetActorAnalytics())
+
+	for _, limit := range []string{"0", "201", "x"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/analytics/actors?limit="+limit, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, limit)
+	}
+	mockDB.AssertNotCalled(t, "GetActorStats", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetActorAnalytics_Error(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/analytics/actors", handler.GetActorAnalytics())
+
+	mockDB.On("GetActorStats", mock.Anything, mock.Anything, mock.Anything, 50).Return([]models.ActorStats{}, errors.New("db error"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/analytics/actors", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
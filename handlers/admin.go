@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/config"
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/internal/services"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	settingMetricsInterval = "metrics_interval_seconds"
+	settingSSECoalesce     = "sse_coalesce_ms"
+
+	minMetricsIntervalSeconds = 2
+	maxMetricsIntervalSeconds = 300
+	maxSSECoalesceMillis      = 10000
+)
+
+// DefaultRuntimeSettings are used until an admin changes them.
+var DefaultRuntimeSettings = models.RuntimeSettings{
+	MetricsIntervalSeconds: 10,
+	SSECoalesceMillis:      0,
+}
+
+// RequireAdminToken middleware only lets through requests carrying the
+// configured ADMIN_TOKEN as a bearer token. The admin API is disabled when no
+// token is configured.
+func RequireAdminToken(config *config.Config) gin.HandlerFunc {
+	return requireBearerToken(config.Vars.AdminToken, "Admin", "ADMIN_TOKEN")
+}
+
+// requireBearerToken rejects requests whose bearer token does not match
+// token, and all requests when token is empty.
+func requireBearerToken(token, api, envVar string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.JSON(http.StatusForbidden, gin.H{"error": api + " API is disabled. Set " + envVar + " to enable it."})
+			c.Abort()
+			return
+		}
+
+		provided, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			logger.Logger.Warn("Rejected "+strings.ToLower(api)+" API request", zap.String("client_ip", c.ClientIP()), zap.String("path", c.Request.URL.Path))
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing " + strings.ToLower(api) + " token"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// AdminHandler serves the admin API and applies runtime settings to the
+// running services.
+type AdminHandler struct {
+	config         *config.Config
+	db             database.DatabaseInterface
+	metricsService *services.MetricsUpdateService
+	sseHandler     *SSEHandler
+	// confirmKey signs the confirmation tokens of destructive operations; it
+	// is random per process, so tokens do not survive a restart.
+	confirmKey []byte
+}
+
+func NewAdminHandler(cfg *config.Config, db database.DatabaseInterface, metricsService *services.MetricsUpdateService, sseHandler *SSEHandler) *AdminHandler {
+	confirmKey := make([]byte, 32)
+	_, _ = rand.Read(confirmKey)
+	return &AdminHandler{
+		config:         cfg,
+		db:             db,
+		metricsService: metricsService,
+		sseHandler:     sseHandler,
+		confirmKey:     confirmKey,
+	}
+}
+
+// LoadSettings applies the persisted runtime settings, falling back to the
+// defaults for any that were never set.
+func (h *AdminHandler) LoadSettings(ctx context.Context) error {
+	settings, err := h.getSettings(ctx)
+	if err != nil {
+		return err
+	}
+	h.apply(settings)
+	return nil
+}
+
+// GetConfig returns the current runtime settings.
+func (h *AdminHandler) GetConfig() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		settings, err := h.getSettings(c.Request.Context())
+		if err != nil {
+			logger.Logger.Error("Failed to get settings", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve settings"})
+			return
+		}
+
+		c.JSON(http.StatusOK, settings)
+	}
+}
+
+// UpdateConfig validates, persists and immediately applies runtime settings.
+// Fields left out of the request keep their current value.
+func (h *AdminHandler) UpdateConfig() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			MetricsIntervalSeconds *int `json:"metrics_interval_seconds"`
+			SSECoalesceMillis      *int `json:"sse_coalesce_ms"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		ctx := c.Request.Context()
+		settings, err := h.getSettings(ctx)
+		if err != nil {
+			logger.Logger.Error("Failed to get settings", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve settings"})
+			return
+		}
+
+		if req.MetricsIntervalSeconds != nil {
+			if *req.MetricsIntervalSeconds < minMetricsIntervalSeconds || *req.MetricsIntervalSeconds > maxMetricsIntervalSeconds {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "metrics_interval_seconds must be between 2 and 300"})
+				return
+			}
+			settings.MetricsIntervalSeconds = *req.MetricsIntervalSeconds
+		}
+		if req.SSECoalesceMillis != nil {
+			if *req.SSECoalesceMillis < 0 || *req.SSECoalesceMillis > maxSSECoalesceMillis {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "sse_coalesce_ms must be between 0 and 10000"})
+				return
+			}
+			settings.SSECoalesceMillis = *req.SSECoalesceMillis
+		}
+
+		err = h.db.SaveSettings(ctx, map[string]string{
+			settingMetricsInterval: strconv.Itoa(settings.MetricsIntervalSeconds),
+			settingSSECoalesce:     strconv.Itoa(settings.SSECoalesceMillis),
+		})
+		if err != nil {
+			logger.Logger.Error("Failed to save settings", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save settings"})
+			return
+		}
+
+		h.apply(settings)
+		SendConfigChanged(ConfigSettings)
+		logger.Logger.Info("Runtime settings updated",
+			zap.Int("metrics_interval_seconds", settings.MetricsIntervalSeconds),
+			zap.Int("sse_coalesce_ms", settings.SSECoalesceMillis))
+
+		c.JSON(http.StatusOK, settings)
+	}
+}
+
+// getSettings reads the persisted runtime settings over the defaults.
+// Unparseable stored values are ignored.
+func (h *AdminHandler) getSettings(ctx context.Context) (models.RuntimeSettings, error) {
+	settings := DefaultRuntimeSettings
+
+	stored, err := h.db.GetSettings(ctx)
+	if err != nil {
+		return settings, err
+	}
+	if v, err := strconv.Atoi(stored[settingMetricsInterval]); err == nil {
+		settings.MetricsIntervalSeconds = v
+	}
+	if v, err := strconv.Atoi(stored[settingSSECoalesce]); err == nil {
+		settings.SSECoalesceMillis = v
+	}
+	return settings, nil
+}
+
+func (h *AdminHandler) apply(settings models.RuntimeSettings) {
+	if h.metricsService != nil {
+		h.metricsService.SetInterval(time.Duration(settings.MetricsIntervalSeconds) * time.Second)
+	}
+	if h.sseHandler != nil {
+		h.sseHandler.SetCoalesceWindow(time.Duration(settings.SSECoalesceMillis) * time.Millisecond)
+	}
+}
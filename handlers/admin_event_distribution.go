@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"hash/fnv"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultEventDistributionTop = 20
+	maxEventDistributionTop     = 100
+	defaultPartitions           = 4
+	maxPartitions               = 64
+	// unknownRepository groups events whose run or job is no longer stored
+	unknownRepository = "(unknown)"
+)
+
+// Partitioning strategies compared by the event distribution report.
+const (
+	partitionByOrderingKey = "ordering_key"
+	partitionByRepository  = "repository"
+)
+
+// eventDistributionBucket returns the bucket size used to chart a period.
+func eventDistributionBucket(period string) time.Duration {
+	switch period {
+	case "hour":
+		return 5 * time.Minute
+	case "week":
+		return 6 * time.Hour
+	case "month":
+		return 24 * time.Hour
+	default:
+		return time.Hour
+	}
+}
+
+// GetEventDistribution reports how the webhook events received over a period
+// (default: day) spread over ordering keys and repositories: the busiest keys
+// and repositories, per-bucket totals, and how evenly they would have spread
+// over a number of partitions when hashed by ordering key or by repository.
+func (h *AdminHandler) GetEventDistribution() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		period := c.DefaultQuery("period", "day")
+		top, err := strconv.Atoi(c.DefaultQuery("top", strconv.Itoa(defaultEventDistributionTop)))
+		if err != nil || top < 1 || top > maxEventDistributionTop {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "top must be between 1 and 100"})
+			return
+		}
+		partitions, err := strconv.Atoi(c.DefaultQuery("partitions", strconv.Itoa(defaultPartitions)))
+		if err != nil || partitions < 2 || partitions > maxPartitions {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "partitions must be between 2 and 64"})
+			return
+		}
+
+		bucket := eventDistributionBucket(period)
+		counts, err := h.db.GetEventKeyCounts(c.Request.Context(), periodToDuration(period), bucket)
+		if err != nil {
+			logger.Logger.Error("Failed to get event key counts", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve event distribution"})
+			return
+		}
+
+		report := eventDistribution(counts, bucket, top, partitions)
+		report.Period = period
+		c.JSON(http.StatusOK, report)
+	}
+}
+
+// eventDistribution summarizes per-bucket event counts, ordered by bucket.
+func eventDistribution(counts []models.EventKeyCount, bucket time.Duration, top, partitions int) models.EventDistribution {
+	report := models.EventDistribution{
+		BucketSeconds:   int(bucket.Seconds()),
+		Buckets:         []models.EventDistributionBucket{},
+		HotKeys:         []models.OrderingKeyLoad{},
+		TopRepositories: []models.RepositoryEventLoad{},
+		Partitioning:    []models.PartitionBalance{},
+	}
+
+	keys := make(map[string]*models.OrderingKeyLoad)
+	repos := make(map[string]*models.RepositoryEventLoad)
+	repoKeys := make(map[string]map[string]struct{})
+	for _, c := range counts {
+		n := len(report.Buckets)
+		if n == 0 || !report.Buckets[n-1].Start.Equal(c.BucketStart) {
+			report.Buckets = append(report.Buckets, models.EventDistributionBucket{Start: c.BucketStart})
+			n++
+		}
+		b := &report.Buckets[n-1]
+		b.Events += c.Events
+		b.Keys++
+		if c.Events > b.HottestKeyEvents {
+			b.HottestKey, b.HottestKeyEvents = c.OrderingKey, c.Events
+		}
+
+		repository := c.Repository
+		if repository == "" {
+			repository = unknownRepository
+		}
+		key, ok := keys[c.OrderingKey]
+		if !ok {
+			key = &models.OrderingKeyLoad{Key: c.OrderingKey, Repository: repository}
+			keys[c.OrderingKey] = key
+		}
+		key.Events += c.Events
+		key.PeakBucketEvents = max(key.PeakBucketEvents, c.Events)
+
+		repo, ok := repos[repository]
+		if !ok {
+			repo = &models.RepositoryEventLoad{Repository: repository}
+			repos[repository] = repo
+			repoKeys[repository] = make(map[string]struct{})
+		}
+		repo.Events += c.Events
+		repoKeys[repository][c.OrderingKey] = struct{}{}
+		for len(repo.Series) < n {
+			repo.Series = append(repo.Series, 0)
+		}
+		repo.Series[n-1] += c.Events
+
+		report.Events += c.Events
+	}
+	report.Keys = len(keys)
+	report.Repositories = len(repos)
+	if report.Events == 0 {
+		return report
+	}
+
+	for _, key := range keys {
+		key.Share = float64(key.Events) / float64(report.Events) * 100
+		report.HotKeys = append(report.HotKeys, *key)
+	}
+	sort.Slice(report.HotKeys, func(i, j int) bool {
+		if report.HotKeys[i].Events != report.HotKeys[j].Events {
+			return report.HotKeys[i].Events > report.HotKeys[j].Events
+		}
+		return report.HotKeys[i].Key < report.HotKeys[j].Key
+	})
+	report.HotKeys = report.HotKeys[:min(top, len(report.HotKeys))]
+
+	for name, repo := range repos {
+		repo.Share = float64(repo.Events) / float64(report.Events) * 100
+		repo.Keys = len(repoKeys[name])
+		for len(repo.Series) < len(report.Buckets) {
+			repo.Series = append(repo.Series, 0)
+		}
+		report.TopRepositories = append(report.TopRepositories, *repo)
+	}
+	sort.Slice(report.TopRepositories, func(i, j int) bool {
+		if report.TopRepositories[i].Events != report.TopRepositories[j].Events {
+			return report.TopRepositories[i].Events > report.TopRepositories[j].Events
+		}
+		return report.TopRepositories[i].Repository < report.TopRepositories[j].Repository
+	})
+	report.TopRepositories = report.TopRepositories[:min(top, len(report.TopRepositories))]
+
+	byKey := make([]int, partitions)
+	byRepo := make([]int, partitions)
+	for _, key := range keys {
+		byKey[partitionOf(key.Key, partitions)] += key.Events
+		byRepo[partitionOf(key.Repository, partitions)] += key.Events
+	}
+	report.Partitioning = append(report.Partitioning,
+		partitionBalance(partitionByOrderingKey, byKey, report.Events),
+		partitionBalance(partitionByRepository, byRepo, report.Events))
+
+	return report
+}
+
+// partitionOf hashes a partitioning key onto one of n partitions.
+func partitionOf(key string, n int) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum32() % uint32(n))
+}
+
+func partitionBalance(strategy string, loads []int, total int) models.PartitionBalance {
+	busiest := 0
+	for _, load := range loads {
+		busiest = max(busiest, load)
+	}
+	return models.PartitionBalance{
+		Strategy:   strategy,
+		Partitions: len(loads),
+		MaxShare:   float64(busiest) / float64(total) * 100,
+		Imbalance:  float64(busiest) / (float64(total) / float64(len(loads))),
+	}
+}
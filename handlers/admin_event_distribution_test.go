@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/config"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventDistribution(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	counts := []models.EventKeyCount{
+		{BucketStart: start, OrderingKey: "job_1", Repository: "app", Events: 5},
+		{BucketStart: start, OrderingKey: "job_2", Repository: "web", Events: 2},
+		{BucketStart: start, OrderingKey: "run_1", Repository: "app", Events: 1},
+		{BucketStart: start.Add(time.Hour), OrderingKey: "job_1", Repository: "app", Events: 3},
+		{BucketStart: start.Add(time.Hour), OrderingKey: "job_9", Events: 1},
+	}
+
+	report := eventDistribution(counts, time.Hour, 2, 4)
+
+	assert.Equal(t, 3600, report.BucketSeconds)
+	assert.Equal(t, 12, report.Events)
+	assert.Equal(t, 4, report.Keys)
+	assert.Equal(t, 3, report.Repositories)
+	assert.Equal(t, []models.EventDistributionBucket{
+		{Start: start, Events: 8, Keys: 3, HottestKey: "job_1", HottestKeyEvents: 5},
+		{Start: start.Add(time.Hour), Events: 4, Keys: 2, HottestKey: "job_1", HottestKeyEvents: 3},
+	}, report.Buckets)
+
+	require.Len(t, report.HotKeys, 2, "limited to top")
+	assert.Equal(t, "job_1", report.HotKeys[0].Key)
+	assert.Equal(t, 8, report.HotKeys[0].Events)
+	assert.Equal(t, 5, report.HotKeys[0].PeakBucketEvents)
+	assert.InDelta(t, 66.67, report.HotKeys[0].Share, 0.01)
+
+	require.Len(t, report.TopRepositories, 2)
+	assert.Equal(t, models.RepositoryEventLoad{Repository: "app", Events: 9, Share: 75, Keys: 2, Series: []int{6, 3}}, report.TopRepositories[0])
+	assert.Equal(t, []int{2, 0}, report.TopRepositories[1].Series, "buckets without events read zero")
+
+	require.Len(t, report.Partitioning, 2)
+	byKey, byRepo := report.Partitioning[0], report.Partitioning[1]
+	assert.Equal(t, "ordering_key", byKey.Strategy)
+	assert.Equal(t, 4, byKey.Partitions)
+	assert.GreaterOrEqual(t, byKey.MaxShare, 66.66, "the hottest key lands on one partition")
+	assert.Equal(t, "repository", byRepo.Strategy)
+	assert.GreaterOrEqual(t, byRepo.MaxShare, 75.0)
+	assert.InDelta(t, byRepo.MaxShare/25, byRepo.Imbalance, 0.001)
+}
+
+func TestAdminHandler_GetEventDistribution(t *testing.T) {
+	router, mockDB, _ := setupAPITest()
+	handler := NewAdminHandler(&config.Config{}, mockDB, nil, nil)
+	router.GET("/api/admin/events/distribution", handler.GetEventDistribution())
+
+	mockDB.On("GetEventKeyCounts", mock.Anything, 7*24*time.Hour, 6*time.Hour).Return([]models.EventKeyCount{}, nil)
+
+	for path, status := range map[string]int{
+		"/api/admin/events/distribution?period=week":               http.StatusOK,
+		"/api/admin/events/distribution?period=week&top=0":         http.StatusBadRequest,
+		"/api/admin/events/distribution?period=week&partitions=1":  http.StatusBadRequest,
+		"/api/admin/events/distribution?period=week&partitions=65": http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, req)
+		require.Equal(t, status, w.Code, path)
+		if status == http.StatusOK {
+			var report models.EventDistribution
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+			assert.Equal(t, "week", report.Period)
+			assert.Empty(t, report.HotKeys)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// purgeConfirmTTL is how long a purge confirmation token stays valid.
+const purgeConfirmTTL = 5 * time.Minute
+
+// PurgeWebhookEvents deletes processed or failed webhook events received
+// before a cutoff, to reclaim space after an event storm without waiting for
+// the nightly cleanup. A request without a confirm token deletes nothing: it
+// reports how many events match and returns a token, valid for five minutes
+// and for the same status and cutoff only, that performs the purge when sent
+// back as confirm.
+func (h *AdminHandler) PurgeWebhookEvents() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		status := c.Query("status")
+		if status != "processed" && status != "failed" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "status must be 'processed' or 'failed'"})
+			return
+		}
+		before, err := time.Parse(time.RFC3339, c.Query("before"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "before must be an RFC3339 timestamp"})
+			return
+		}
+		now := h.config.Now()
+		if before.After(now) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "before must not be in the future"})
+			return
+		}
+
+		ctx := c.Request.Context()
+		token := c.Query("confirm")
+		if token == "" {
+			matched, err := h.db.PurgeWebhookEvents(ctx, status, before, true)
+			if err != nil {
+				logger.Logger.Error("Failed to count webhook events to purge", zap.Error(err))
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count webhook events"})
+				return
+			}
+			expiresAt := now.Add(purgeConfirmTTL)
+			c.JSON(http.StatusOK, gin.H{
+				"status":        status,
+				"before":        before.UTC(),
+				"matched":       matched,
+				"confirm_token": h.purgeToken(status, before, expiresAt),
+				"expires_at":    expiresAt.UTC().Truncate(time.Second),
+			})
+			return
+		}
+
+		if !h.validPurgeToken(token, status, before, now) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired confirm token; repeat the request without confirm to get a new one"})
+			return
+		}
+
+		deleted, err := h.db.PurgeWebhookEvents(ctx, status, before, false)
+		if err != nil {
+			logger.Logger.Error("Failed to purge webhook events", zap.Int64("deleted", deleted), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to purge webhook events", "deleted": deleted})
+			return
+		}
+		logger.Logger.Info("Purged webhook events",
+			zap.String("status", status), zap.Time("before", before), zap.Int64("deleted", deleted))
+
+		c.JSON(http.StatusOK, gin.H{
+			"status":  status,
+			"before":  before.UTC(),
+			"deleted": deleted,
+		})
+	}
+}
+
+// purgeToken signs a purge of status events before the cutoff, valid until
+// expiresAt. Tokens read "<expiry unix time>.<signature>".
+func (h *AdminHandler) purgeToken(status string, before, expiresAt time.Time) string {
+	expiry := strconv.FormatInt(expiresAt.Unix(), 10)
+	mac := hmac.New(sha256.New, h.confirmKey)
+	mac.Write([]byte("purge-events\n" + status + "\n" + before.UTC().Format(time.RFC3339) + "\n" + expiry))
+	return expiry + "." + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (h *AdminHandler) validPurgeToken(token, status string, before, now time.Time) bool {
+	expiry, _, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+	unix, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil || now.After(time.Unix(unix, 0)) {
+		return false
+	}
+	return hmac.Equal([]byte(token), []byte(h.purgeToken(status, before, time.Unix(unix, 0))))
+}
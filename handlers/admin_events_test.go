@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/clock"
+	"github.com/gateixeira/live-actions/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminHandler_PurgeWebhookEvents(t *testing.T) {
+	router, mockDB, _ := setupAPITest()
+	fake := clock.NewFake(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	handler := NewAdminHandler(&config.Config{Clock: fake}, mockDB, nil, nil)
+	router.DELETE("/api/admin/events", handler.PurgeWebhookEvents())
+
+	before := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	purge := func(query url.Values) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/api/admin/events?"+query.Encode(), nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Without confirm it only counts
+	mockDB.On("PurgeWebhookEvents", mock.Anything, "processed", before, true).Return(int64(1200), nil).Once()
+	w := purge(url.Values{"status": {"processed"}, "before": {"2024-01-01T00:00:00Z"}})
+	require.Equal(t, http.StatusOK, w.Code)
+	var dryRun struct {
+		Matched      int64  `json:"matched"`
+		ConfirmToken string `json:"confirm_token"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &dryRun))
+	assert.Equal(t, int64(1200), dryRun.Matched)
+	require.NotEmpty(t, dryRun.ConfirmToken)
+
+	// The token is bound to the status and cutoff
+	w = purge(url.Values{"status": {"failed"}, "before": {"2024-01-01T00:00:00Z"}, "confirm": {dryRun.ConfirmToken}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = purge(url.Values{"status": {"processed"}, "before": {"2024-06-01T00:00:00Z"}, "confirm": {dryRun.ConfirmToken}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockDB.On("PurgeWebhookEvents", mock.Anything, "processed", before, false).Return(int64(1210), nil).Once()
+	w = purge(url.Values{"status": {"processed"}, "before": {"2024-01-01T00:00:00Z"}, "confirm": {dryRun.ConfirmToken}})
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"deleted":1210`)
+
+	// Tokens expire on the configured clock
+	fake.Advance(purgeConfirmTTL + time.Second)
+	w = purge(url.Values{"status": {"processed"}, "before": {"2024-01-01T00:00:00Z"}, "confirm": {dryRun.ConfirmToken}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockDB.AssertExpectations(t)
+}
+
+func TestAdminHandler_PurgeWebhookEvents_Validation(t *testing.T) {
+	router, mockDB, _ := setupAPITest()
+	handler := NewAdminHandler(&config.Config{}, mockDB, nil, nil)
+	router.DELETE("/api/admin/events", handler.PurgeWebhookEvents())
+
+	before := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	expired := handler.purgeToken("processed", before, time.Now().Add(-time.Second))
+	for name, query := range map[string]url.Values{
+		"pending events":  {"status": {"pending"}, "before": {"2024-01-01T00:00:00Z"}},
+		"missing cutoff":  {"status": {"processed"}},
+		"bad cutoff":      {"status": {"processed"}, "before": {"yesterday"}},
+		"future cutoff":   {"status": {"processed"}, "before": {time.Now().Add(time.Hour).Format(time.RFC3339)}},
+		"forged token":    {"status": {"processed"}, "before": {"2024-01-01T00:00:00Z"}, "confirm": {strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10) + ".abc"}},
+		"expired token":   {"status": {"processed"}, "before": {"2024-01-01T00:00:00Z"}, "confirm": {expired}},
+		"malformed token": {"status": {"processed"}, "before": {"2024-01-01T00:00:00Z"}, "confirm": {"token"}},
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/api/admin/events?"+query.Encode(), nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, name)
+	}
+	mockDB.AssertNotCalled(t, "PurgeWebhookEvents", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
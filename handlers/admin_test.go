@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/config"
+	"github.com/gateixeira/live-actions/internal/services"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireAdminToken(t *testing.T) {
+	tests := []struct {
+		name       string
+		adminToken string
+		header     string
+		expected   int
+	}{
+		{"Disabled without token", "", "Bearer anything", http.StatusForbidden},
+		{"Missing header", "secret", "", http.StatusUnauthorized},
+		{"Wrong token", "secret", "Bearer wrong", http.StatusUnauthorized},
+		{"Wrong scheme", "secret", "Basic secret", http.StatusUnauthorized},
+		{"Valid token", "secret", "Bearer secret", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, _, testConfig := setupAPITest()
+			testConfig.Vars.AdminToken = tt.adminToken
+			router.GET("/api/admin/config", RequireAdminToken(testConfig), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/api/admin/config", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expected, w.Code)
+		})
+	}
+}
+
+func TestAdminHandler_GetConfig(t *testing.T) {
+	router, mockDB, _ := setupAPITest()
+	handler := NewAdminHandler(&config.Config{}, mockDB, nil, nil)
+	router.GET("/api/admin/config", handler.GetConfig())
+
+	mockDB.On("GetSettings", mock.Anything).Return(map[string]string{"sse_coalesce_ms": "500"}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/admin/config", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var settings models.RuntimeSettings
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &settings))
+	assert.Equal(t, DefaultRuntimeSettings.MetricsIntervalSeconds, settings.MetricsIntervalSeconds)
+	assert.Equal(t, 500, settings.SSECoalesceMillis)
+}
+
+func TestAdminHandler_UpdateConfig(t *testing.T) {
+	router, mockDB, cfg := setupAPITest()
+	metricsService := services.NewMetricsUpdateService(cfg, mockDB, 10*time.Second, context.Background())
+	sse := &SSEHandler{client: make(chan SSEEvent, 1)}
+	handler := NewAdminHandler(&config.Config{}, mockDB, metricsService, sse)
+	router.PUT("/api/admin/config", handler.UpdateConfig())
+
+	mockDB.On("GetSettings", mock.Anything).Return(map[string]string{"metrics_interval_seconds": "30"}, nil)
+	mockDB.On("SaveSettings", mock.Anything, map[string]string{
+		"metrics_interval_seconds": "30",
+		"sse_coalesce_ms":          "2000",
+	}).Return(nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/api/admin/config", bytes.NewBufferString(`{"sse_coalesce_ms": 2000}`))
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 2*time.Second, sse.CoalesceWindow())
+	assert.Equal(t, 30*time.Second, metricsService.Interval())
+	mockDB.AssertExpectations(t)
+}
+
+func TestAdminHandler_UpdateConfig_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"Interval too short", `{"metrics_interval_seconds": 1}`},
+		{"Interval too long", `{"metrics_interval_seconds": 301}`},
+		{"Negative coalescing", `{"sse_coalesce_ms": -1}`},
+		{"Coalescing too long", `{"sse_coalesce_ms": 10001}`},
+		{"Malformed body", `{"metrics_interval_seconds": "fast"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, mockDB, _ := setupAPITest()
+			handler := NewAdminHandler(&config.Config{}, mockDB, nil, nil)
+			router.PUT("/api/admin/config", handler.UpdateConfig())
+
+			mockDB.On("GetSettings", mock.Anything).Return(map[string]string{}, nil).Maybe()
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("PUT", "/api/admin/config", bytes.NewBufferString(tt.body))
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			mockDB.AssertNotCalled(t, "SaveSettings", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestAdminHandler_UpdateConfig_SaveError(t *testing.T) {
+	router, mockDB, _ := setupAPITest()
+	sse := &SSEHandler{client: make(chan SSEEvent, 1)}
+	handler := NewAdminHandler(&config.Config{}, mockDB, nil, sse)
+	router.PUT("/api/admin/config", handler.UpdateConfig())
+
+	mockDB.On("GetSettings", mock.Anything).Return(map[string]string{}, nil)
+	mockDB.On("SaveSettings", mock.Anything, mock.Anything).Return(errors.New("db error"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/api/admin/config", bytes.NewBufferString(`{"sse_coalesce_ms": 2000}`))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Zero(t, sse.CoalesceWindow(), "settings that failed to persist are not applied")
+}
+
+func TestAdminHandler_LoadSettings(t *testing.T) {
+	_, mockDB, _ := setupAPITest()
+	sse := &SSEHandler{client: make(chan SSEEvent, 1)}
+	handler := NewAdminHandler(&config.Config{}, mockDB, nil, sse)
+
+	mockDB.On("GetSettings", mock.Anything).Return(map[string]string{"sse_coalesce_ms": "250"}, nil)
+
+	require.NoError(t, handler.LoadSettings(context.Background()))
+	assert.Equal(t, 250*time.Millisecond, sse.CoalesceWindow())
+}
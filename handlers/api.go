@@ -1,13 +1,16 @@
 package handlers
 
 import (
+	"encoding/json"
 	"fmt"
 	"net"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strconv"
 	"time"
 
+	"github.com/gateixeira/live-actions/internal/anonymize"
 	"github.com/gateixeira/live-actions/internal/config"
 	"github.com/gateixeira/live-actions/internal/database"
 	"github.com/gateixeira/live-actions/internal/utils"
@@ -17,9 +20,17 @@ import (
 	"go.uber.org/zap"
 )
 
+var shaPattern = regexp.MustCompile(`^[0-9a-fA-F]{4,40}$`)
+
+// maxBatchRunIDs bounds how many runs one batch job request may ask for.
+const maxBatchRunIDs = 50
+
 type APIHandler struct {
 	db     database.DatabaseInterface
 	config *config.Config
+	// anonymizer pseudonymizes names in responses that are not JSON, which
+	// the anonymize middleware cannot rewrite; nil leaves them as they are.
+	anonymizer *anonymize.Anonymizer
 }
 
 func NewAPIHandler(config *config.Config, db database.DatabaseInterface) *APIHandler {
@@ -29,6 +40,26 @@ func NewAPIHandler(config *config.Config, db database.DatabaseInterface) *APIHan
 	}
 }
 
+// SetAnonymizer makes responses rendered as Atom or Markdown replace
+// repository, owner and user names and run titles with the anonymizer's
+// pseudonyms, as JSON responses do. Call it before serving.
+func (h *APIHandler) SetAnonymizer(a *anonymize.Anonymizer) {
+	h.anonymizer = a
+}
+
+// anonymized pseudonymizes v in place by the rules of JSON responses, before
+// it is rendered in another format.
+func (h *APIHandler) anonymized(v interface{}) error {
+	if h.anonymizer == nil {
+		return nil
+	}
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(h.anonymizer.JSON(body), v)
+}
+
 // ValidateOrigin middleware ensures requests come from the UI
 func ValidateOrigin() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -79,8 +110,10 @@ func ValidateOrigin() gin.HandlerFunc {
 			return
 		}
 
+		// The token must also be signed with the current key, which rotating it
+		// through the admin API changes
 		csrfHeader := c.GetHeader(utils.HeaderName)
-		if csrfHeader == "" || csrfHeader != csrfCookie {
+		if csrfHeader == "" || csrfHeader != csrfCookie || !csrf.verify(c.Request.Context(), csrfHeader) {
 			c.JSON(http.StatusForbidden, gin.H{
 				"error": "Invalid CSRF token",
 			})
@@ -177,17 +210,32 @@ func normalizeHost(scheme, host string) string {
 func (h *APIHandler) GetWorkflowRuns() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		page, limit := GetPaginationParams(c)
-		repo := c.Query("repo")
+		repos, ok := h.repoFilter(c)
+		if !ok {
+			return
+		}
 		status := c.Query("status")
+		sha := c.Query("sha")
+		if sha != "" && !shaPattern.MatchString(sha) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "sha must be 4-40 hexadecimal characters"})
+			return
+		}
 
 		// Retrieve workflow runs from the database with pagination
-		runs, totalCount, err := h.db.GetWorkflowRunsPaginated(c.Request.Context(), page, limit, repo, status)
+		runs, totalCount, err := h.db.GetWorkflowRunsPaginated(c.Request.Context(), page, limit, repos, status, sha)
 		if err != nil {
 			logger.Logger.Error("Error retrieving workflow runs", zap.Error(err))
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve workflow runs"})
 			return
 		}
 
+		savedFilters, err := h.db.GetSavedFilters(c.Request.Context())
+		if err != nil {
+			logger.Logger.Error("Error retrieving saved filters", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve workflow runs"})
+			return
+		}
+
 		// Calculate pagination metadata
 		totalPages := (totalCount + limit - 1) / limit
 		hasNext := page < totalPages
@@ -196,6 +244,7 @@ func (h *APIHandler) GetWorkflowRuns() gin.HandlerFunc {
 		// Return the workflow runs with pagination metadata as JSON
 		c.JSON(http.StatusOK, gin.H{
 			"workflow_runs": runs,
+			"saved_filters": savedFilters,
 			"pagination": gin.H{
 				"current_page": page,
 				"total_pages":  totalPages,
@@ -232,6 +281,8 @@ func (h *APIHandler) GetWorkflowJobsByRunID() gin.HandlerFunc {
 			return
 		}
 
+		addJobETAs(c.Request.Context(), h.db, newJobNameCache(h.db, h.config.Vars.JobNameRules), runIDInt64, jobs, h.config.Now())
+
 		// Return the workflow jobs as JSON
 		c.JSON(http.StatusOK, gin.H{
 			"workflow_jobs": jobs,
@@ -239,23 +290,83 @@ func (h *APIHandler) GetWorkflowJobsByRunID() gin.HandlerFunc {
 	}
 }
 
-// GetCurrentMetrics returns current metrics and time-series data from the database.
-func (h *APIHandler) GetCurrentMetrics() gin.HandlerFunc {
+// GetWorkflowJobsBatch returns the jobs of several runs at once, keyed by run
+// ID, so the runs list can expand many rows with one request. Every requested
+// run is present, with an empty list when it has no jobs.
+func (h *APIHandler) GetWorkflowJobsBatch() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		period := c.DefaultQuery("period", "day")
+		var req struct {
+			RunIDs []int64 `json:"run_ids"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+		if len(req.RunIDs) == 0 || len(req.RunIDs) > maxBatchRunIDs {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "run_ids must list between 1 and 50 run IDs"})
+			return
+		}
 
-		since := periodToDuration(period)
+		runIDs := make([]int64, 0, len(req.RunIDs))
+		seen := make(map[int64]bool, len(req.RunIDs))
+		for _, id := range req.RunIDs {
+			if id <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid run ID"})
+				return
+			}
+			if !seen[id] {
+				seen[id] = true
+				runIDs = append(runIDs, id)
+			}
+		}
 
-		summary, err := h.db.GetMetricsSummary(c.Request.Context(), since)
+		byRun, err := h.db.GetWorkflowJobsByRunIDs(c.Request.Context(), runIDs)
 		if err != nil {
-			logger.Logger.Error("Failed to get metrics summary", zap.Error(err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve metrics"})
+			logger.Logger.Error("Error retrieving workflow jobs for runs", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve workflow jobs"})
 			return
 		}
 
-		snapshots, err := h.db.GetMetricsHistory(c.Request.Context(), since)
+		now := h.config.Now()
+		result := make(map[string][]models.WorkflowJob, len(runIDs))
+		for _, runID := range runIDs {
+			jobs := byRun[runID]
+			if jobs == nil {
+				jobs = []models.WorkflowJob{}
+			}
+			addJobETAs(c.Request.Context(), h.db, newJobNameCache(h.db, h.config.Vars.JobNameRules), runID, jobs, now)
+			result[strconv.FormatInt(runID, 10)] = jobs
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"workflow_jobs": result,
+		})
+	}
+}
+
+// GetCurrentMetrics returns current metrics and time-series data from the database.
+func (h *APIHandler) GetCurrentMetrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		period := c.DefaultQuery("period", "day")
+
+		since := periodToDuration(period)
+		ctx := c.Request.Context()
+
+		// Read in one snapshot so peak demand covers the series shown
+		var summary map[string]float64
+		var snapshots []models.MetricsSnapshot
+		err := h.db.ReadSnapshot(ctx, func(db database.DatabaseInterface) error {
+			var err error
+			if summary, err = db.GetMetricsSummary(ctx, since); err != nil {
+				return fmt.Errorf("failed to get metrics summary: %w", err)
+			}
+			if snapshots, err = db.GetMetricsHistory(ctx, since); err != nil {
+				return fmt.Errorf("failed to get metrics history: %w", err)
+			}
+			return nil
+		})
 		if err != nil {
-			logger.Logger.Error("Failed to get metrics history", zap.Error(err))
+			logger.Logger.Error("Failed to get metrics", zap.Error(err))
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve metrics"})
 			return
 		}
@@ -309,25 +420,34 @@ func periodToDuration(period string) time.Duration {
 	}
 }
 
-// GetFailureAnalytics returns failure summary and trend data for completed jobs.
+// GetFailureAnalytics returns failure summary and trend data for completed
+// jobs, with renamed jobs merged in the top failing jobs.
 func (h *APIHandler) GetFailureAnalytics() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		period := c.DefaultQuery("period", "day")
 		since := periodToDuration(period)
 		ctx := c.Request.Context()
-		repo := c.Query("repo")
-
-		summary, err := h.db.GetFailureAnalytics(ctx, since, repo)
-		if err != nil {
-			logger.Logger.Error("Failed to get failure analytics", zap.Error(err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve failure analytics"})
+		repos, ok := h.repoFilter(c)
+		if !ok {
 			return
 		}
 
-		trend, err := h.db.GetFailureTrend(ctx, since, repo)
+		// Read in one snapshot so the trend adds up to the summary
+		var summary *models.FailureAnalytics
+		var trend []models.FailureTrendPoint
+		err := h.db.ReadSnapshot(ctx, func(db database.DatabaseInterface) error {
+			var err error
+			if summary, err = h.failureAnalytics(ctx, db, since, repos); err != nil {
+				return fmt.Errorf("failed to get failure summary: %w", err)
+			}
+			if trend, err = db.GetFailureTrend(ctx, since, repos); err != nil {
+				return fmt.Errorf("failed to get failure trend: %w", err)
+			}
+			return nil
+		})
 		if err != nil {
-			logger.Logger.Error("Failed to get failure trend", zap.Error(err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve failure trend"})
+			logger.Logger.Error("Failed to get failure analytics", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve failure analytics"})
 			return
 		}
 
@@ -344,19 +464,27 @@ func (h *APIHandler) GetLabelDemand() gin.HandlerFunc {
 		period := c.DefaultQuery("period", "day")
 		since := periodToDuration(period)
 		ctx := c.Request.Context()
-		repo := c.Query("repo")
-
-		summary, err := h.db.GetLabelDemandSummary(ctx, since, repo)
-		if err != nil {
-			logger.Logger.Error("Failed to get label demand summary", zap.Error(err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve label demand"})
+		repos, ok := h.repoFilter(c)
+		if !ok {
 			return
 		}
 
-		trend, err := h.db.GetLabelDemandTrend(ctx, since, repo)
+		// Read in one snapshot so the trend adds up to the summary
+		var summary []models.LabelDemandSummary
+		var trend []models.LabelDemandTrendPoint
+		err := h.db.ReadSnapshot(ctx, func(db database.DatabaseInterface) error {
+			var err error
+			if summary, err = db.GetLabelDemandSummary(ctx, since, repos); err != nil {
+				return fmt.Errorf("failed to get label demand summary: %w", err)
+			}
+			if trend, err = db.GetLabelDemandTrend(ctx, since, repos); err != nil {
+				return fmt.Errorf("failed to get label demand trend: %w", err)
+			}
+			return nil
+		})
 		if err != nil {
-			logger.Logger.Error("Failed to get label demand trend", zap.Error(err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve label demand trend"})
+			logger.Logger.Error("Failed to get label demand", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve label demand"})
 			return
 		}
 
@@ -380,10 +508,10 @@ func (h *APIHandler) GetRepositories() gin.HandlerFunc {
 	}
 }
 
-// GetCSRFToken generates a CSRF token, sets it as a cookie, and returns it.
+// GetCSRFToken issues a signed CSRF token, sets it as a cookie, and returns it.
 func (h *APIHandler) GetCSRFToken() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		csrfToken, err := utils.GenerateCSRFToken()
+		csrfToken, err := csrf.issue(c.Request.Context())
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate security token"})
 			return
@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -176,14 +178,15 @@ func TestValidateOrigin_Success(t *testing.T) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
 	})
 
+	token := signedCSRFToken(t)
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", "/test", nil)
 	req.Host = "localhost:8080"
 	req.Header.Set("Referer", "http://localhost:8080/")
-	req.Header.Set(utils.HeaderName, "test-token")
+	req.Header.Set(utils.HeaderName, token)
 	req.AddCookie(&http.Cookie{
 		Name:  utils.CookieName,
-		Value: "test-token",
+		Value: token,
 	})
 	router.ServeHTTP(w, req)
 
@@ -191,6 +194,28 @@ func TestValidateOrigin_Success(t *testing.T) {
 	assert.Contains(t, w.Body.String(), "ok")
 }
 
+func TestValidateOrigin_UnsignedCSRFToken(t *testing.T) {
+	router, _, _ := setupAPITest()
+	router.Use(ValidateOrigin())
+	router.GET("/test", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/test", nil)
+	req.Host = "localhost:8080"
+	req.Header.Set("Referer", "http://localhost:8080/")
+	req.Header.Set(utils.HeaderName, "test-token")
+	req.AddCookie(&http.Cookie{
+		Name:  utils.CookieName,
+		Value: "test-token",
+	})
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "Invalid CSRF token")
+}
+
 func TestGetWorkflowJobsByRunID_Success(t *testing.T) {
 	router, mockDB, testConfig := setupAPITest()
 	handler := NewAPIHandler(testConfig, mockDB)
@@ -244,6 +269,68 @@ func TestGetWorkflowJobsByRunID_DatabaseError(t *testing.T) {
 	mockDB.AssertExpectations(t)
 }
 
+func TestGetWorkflowJobsBatch_Success(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+
+	mockDB.On("GetWorkflowJobsByRunIDs", mock.Anything, []int64{1, 2}).Return(map[int64][]models.WorkflowJob{
+		1: {{ID: 10, Name: "build", Status: models.JobStatusCompleted, RunID: 1}, {ID: 11, Name: "test", Status: models.JobStatusQueued, RunID: 1}},
+	}, nil)
+
+	router.POST("/api/workflow-jobs/batch", handler.GetWorkflowJobsBatch())
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/workflow-jobs/batch", strings.NewReader(`{"run_ids":[1,2,1]}`))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		WorkflowJobs map[string][]models.WorkflowJob `json:"workflow_jobs"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response.WorkflowJobs["1"], 2)
+	assert.NotNil(t, response.WorkflowJobs["2"], "runs without jobs are listed empty")
+	assert.Empty(t, response.WorkflowJobs["2"])
+
+	mockDB.AssertExpectations(t)
+}
+
+func TestGetWorkflowJobsBatch_InvalidRequest(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+
+	router.POST("/api/workflow-jobs/batch", handler.GetWorkflowJobsBatch())
+
+	tooMany := make([]string, maxBatchRunIDs+1)
+	for i := range tooMany {
+		tooMany[i] = strconv.Itoa(i + 1)
+	}
+	for _, body := range []string{`{invalid`, `{"run_ids":[]}`, `{"run_ids":[0]}`, `{"run_ids":[` + strings.Join(tooMany, ",") + `]}`} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/workflow-jobs/batch", strings.NewReader(body))
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+
+	mockDB.AssertNotCalled(t, "GetWorkflowJobsByRunIDs", mock.Anything, mock.Anything)
+}
+
+func TestGetWorkflowJobsBatch_DatabaseError(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+
+	mockDB.On("GetWorkflowJobsByRunIDs", mock.Anything, []int64{1}).Return(map[int64][]models.WorkflowJob{}, errors.New("database error"))
+
+	router.POST("/api/workflow-jobs/batch", handler.GetWorkflowJobsBatch())
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/workflow-jobs/batch", strings.NewReader(`{"run_ids":[1]}`))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
 func TestGetWorkflowRuns_Success(t *testing.T) {
 	router, mockDB, testConfig := setupAPITest()
 	handler := NewAPIHandler(testConfig, mockDB)
@@ -265,7 +352,8 @@ func TestGetWorkflowRuns_Success(t *testing.T) {
 		},
 	}
 
-	mockDB.On("GetWorkflowRunsPaginated", mock.Anything, 1, 25, mock.Anything, mock.Anything).Return(expectedRuns, 1, nil)
+	mockDB.On("GetWorkflowRunsPaginated", mock.Anything, 1, 25, mock.Anything, mock.Anything, mock.Anything).Return(expectedRuns, 1, nil)
+	mockDB.On("GetSavedFilters", mock.Anything).Return([]models.SavedFilter{}, nil)
 
 	router.GET("/api/workflow-runs", handler.GetWorkflowRuns())
 
@@ -298,7 +386,8 @@ func TestGetWorkflowRuns_WithPagination(t *testing.T) {
 	handler := NewAPIHandler(testConfig, mockDB)
 
 	expectedRuns := []models.WorkflowRun{}
-	mockDB.On("GetWorkflowRunsPaginated", mock.Anything, 2, 10, mock.Anything, mock.Anything).Return(expectedRuns, 50, nil)
+	mockDB.On("GetWorkflowRunsPaginated", mock.Anything, 2, 10, mock.Anything, mock.Anything, mock.Anything).Return(expectedRuns, 50, nil)
+	mockDB.On("GetSavedFilters", mock.Anything).Return([]models.SavedFilter{}, nil)
 
 	router.GET("/api/workflow-runs", handler.GetWorkflowRuns())
 
@@ -329,7 +418,8 @@ func TestGetWorkflowRuns_InvalidPagination(t *testing.T) {
 
 	// Should default to page=1, limit=25 for invalid values
 	expectedRuns := []models.WorkflowRun{}
-	mockDB.On("GetWorkflowRunsPaginated", mock.Anything, 1, 25, mock.Anything, mock.Anything).Return(expectedRuns, 0, nil)
+	mockDB.On("GetWorkflowRunsPaginated", mock.Anything, 1, 25, mock.Anything, mock.Anything, mock.Anything).Return(expectedRuns, 0, nil)
+	mockDB.On("GetSavedFilters", mock.Anything).Return([]models.SavedFilter{}, nil)
 
 	router.GET("/api/workflow-runs", handler.GetWorkflowRuns())
 
@@ -354,7 +444,7 @@ func TestGetWorkflowRuns_DatabaseError(t *testing.T) {
 	router, mockDB, testConfig := setupAPITest()
 	handler := NewAPIHandler(testConfig, mockDB)
 
-	mockDB.On("GetWorkflowRunsPaginated", mock.Anything, 1, 25, mock.Anything, mock.Anything).Return([]models.WorkflowRun{}, 0, errors.New("database error"))
+	mockDB.On("GetWorkflowRunsPaginated", mock.Anything, 1, 25, mock.Anything, mock.Anything, mock.Anything).Return([]models.WorkflowRun{}, 0, errors.New("database error"))
 
 	router.GET("/api/workflow-runs", handler.GetWorkflowRuns())
 
@@ -368,6 +458,41 @@ func TestGetWorkflowRuns_DatabaseError(t *testing.T) {
 	mockDB.AssertExpectations(t)
 }
 
+func TestGetWorkflowRuns_FilterBySha(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+
+	mockDB.On("GetWorkflowRunsPaginated", mock.Anything, 1, 25, []string(nil), "", "abc1234").Return([]models.WorkflowRun{
+		{ID: 1, HeadSha: "abc1234def", HeadCommit: &models.HeadCommit{ID: "abc1234def", Message: "Fix deploy", Author: models.CommitAuthor{Name: "Octo"}}},
+	}, 1, nil)
+	mockDB.On("GetSavedFilters", mock.Anything).Return([]models.SavedFilter{}, nil)
+
+	router.GET("/api/workflow-runs", handler.GetWorkflowRuns())
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/workflow-runs?sha=abc1234", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"head_sha":"abc1234def"`)
+	assert.Contains(t, w.Body.String(), `"message":"Fix deploy"`)
+	mockDB.AssertExpectations(t)
+}
+
+func TestGetWorkflowRuns_InvalidSha(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/workflow-runs", handler.GetWorkflowRuns())
+
+	for _, sha := range []string{"abc", "not-a-sha", "%25"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/workflow-runs?sha="+sha, nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, sha)
+	}
+}
+
 func TestGetCurrentMetrics_Success(t *testing.T) {
 	router, mockDB, testConfig := setupAPITest()
 	handler := NewAPIHandler(testConfig, mockDB)
@@ -505,17 +630,19 @@ func TestIntegration_ValidateOriginWithGetWorkflowRuns(t *testing.T) {
 
 	// Mock successful database call
 	expectedRuns := []models.WorkflowRun{}
-	mockDB.On("GetWorkflowRunsPaginated", mock.Anything, 1, 25, mock.Anything, mock.Anything).Return(expectedRuns, 0, nil)
+	mockDB.On("GetWorkflowRunsPaginated", mock.Anything, 1, 25, mock.Anything, mock.Anything, mock.Anything).Return(expectedRuns, 0, nil)
+	mockDB.On("GetSavedFilters", mock.Anything).Return([]models.SavedFilter{}, nil)
 
 	// Test with valid CSRF and referer
+	token := signedCSRFToken(t)
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", "/api/workflow-runs", nil)
 	req.Host = "localhost:8080"
 	req.Header.Set("Referer", "http://localhost:8080/")
-	req.Header.Set(utils.HeaderName, "test-token")
+	req.Header.Set(utils.HeaderName, token)
 	req.AddCookie(&http.Cookie{
 		Name:  utils.CookieName,
-		Value: "test-token",
+		Value: token,
 	})
 	router.ServeHTTP(w, req)
 
@@ -584,7 +711,8 @@ func TestGetWorkflowRuns_PaginationEdgeCases(t *testing.T) {
 			handler := NewAPIHandler(testConfig, mockDB)
 
 			expectedRuns := []models.WorkflowRun{}
-			mockDB.On("GetWorkflowRunsPaginated", mock.Anything, tc.expectedPage, tc.expectedLimit, mock.Anything, mock.Anything).Return(expectedRuns, 0, nil)
+			mockDB.On("GetWorkflowRunsPaginated", mock.Anything, tc.expectedPage, tc.expectedLimit, mock.Anything, mock.Anything, mock.Anything).Return(expectedRuns, 0, nil)
+			mockDB.On("GetSavedFilters", mock.Anything).Return([]models.SavedFilter{}, nil)
 
 			router.GET("/api/workflow-runs", handler.GetWorkflowRuns())
 
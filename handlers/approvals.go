@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetApprovalAnalytics returns how long jobs waited for environment approvals
+// over a period (default: week), per environment.
+func (h *APIHandler) GetApprovalAnalytics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		period := c.DefaultQuery("period", "week")
+		repos, ok := h.repoFilter(c)
+		if !ok {
+			return
+		}
+
+		stats, err := h.db.GetApprovalStats(c.Request.Context(), periodToDuration(period), repos)
+		if err != nil {
+			logger.Logger.Error("Failed to get approval stats", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve approval analytics"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"period":       period,
+			"environments": stats,
+		})
+	}
+}
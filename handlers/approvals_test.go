@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetApprovalAnalytics(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/analytics/approvals", handler.GetApprovalAnalytics())
+
+	mockDB.On("GetApprovalStats", mock.Anything, periodToDuration("month"), []string{"app"}).Return([]models.ApprovalStats{
+		{Environment: "production", Approved: 12, Rejected: 1, Pending: 2, AvgSeconds: 1500, P50Seconds: 900, P90Seconds: 3600, MaxSeconds: 7200},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/analytics/approvals?period=month&repo=app", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Period       string                 `json:"period"`
+		Environments []models.ApprovalStats `json:"environments"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "month", response.Period)
+	require.Len(t, response.Environments, 1)
+	assert.Equal(t, 3600.0, response.Environments[0].P90Seconds)
+	mockDB.AssertExpectations(t)
+}
+
+func TestGetApprovalAnalytics_Error(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/analytics/approvals", handler.GetApprovalAnalytics())
+
+	mockDB.On("GetApprovalStats", mock.Anything, mock.Anything, mock.Anything).Return([]models.ApprovalStats{}, errors.New("db error"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/analytics/approvals", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
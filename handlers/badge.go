@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	badgeColorPassing = "#4c1"
+	badgeColorFailing = "#e05d44"
+	badgeColorRunning = "#dfb317"
+	badgeColorNeutral = "#9f9f9f"
+
+	// badgeCacheControl keeps README renderers from hammering the instance
+	// while still reflecting new runs within a minute.
+	badgeCacheControl = "public, max-age=60"
+)
+
+// GetStatusBadge renders an SVG status badge for the latest stored run of a
+// repository (/badge/:owner/:repo.svg) or of one of its workflows
+// (/badge/:owner/:repo/:workflow.svg).
+func (h *APIHandler) GetStatusBadge() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		repo := c.Param("owner") + "/" + strings.TrimSuffix(c.Param("repo"), ".svg")
+		workflow := strings.TrimSuffix(c.Param("workflow"), ".svg")
+
+		run, err := h.db.GetLatestWorkflowRun(c.Request.Context(), repo, workflow)
+		if err != nil {
+			logger.Logger.Error("Failed to get latest workflow run for badge", zap.Error(err), zap.String("repository", repo))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render badge"})
+			return
+		}
+
+		label := "actions"
+		if workflow != "" {
+			label = workflow
+		}
+		message, color := badgeStatus(run)
+
+		c.Header("Cache-Control", badgeCacheControl)
+		c.Data(http.StatusOK, "image/svg+xml; charset=utf-8", []byte(renderBadge(label, message, color)))
+	}
+}
+
+// badgeStatus maps a run to the badge message and color.
+func badgeStatus(run *models.WorkflowRun) (string, string) {
+	if run == nil {
+		return "no runs", badgeColorNeutral
+	}
+
+	if run.Status != models.JobStatusCompleted {
+		return strings.ReplaceAll(string(run.Status), "_", " "), badgeColorRunning
+	}
+
+	switch run.Conclusion {
+	case "success":
+		return "passing", badgeColorPassing
+	case "failure", "timed_out":
+		return "failing", badgeColorFailing
+	case "":
+		return "completed", badgeColorNeutral
+	default:
+		return strings.ReplaceAll(run.Conclusion, "_", " "), badgeColorNeutral
+	}
+}
+
+// renderBadge produces a flat two-part badge. Widths are approximated from
+// the text length, which is close enough for the Verdana 11px used here.
+func renderBadge(label, message, color string) string {
+	labelWidth := 6*len(label) + 12
+	messageWidth := 6*len(message) + 12
+	width := labelWidth + messageWidth
+	label = html.EscapeString(label)
+	message = html.EscapeString(message)
+
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[4]s: %[5]s">`+
+		`<title>%[4]s: %[5]s</title>`+
+		`<rect width="%[2]d" height="20" fill="#555"/>`+
+		`<rect x="%[2]d" width="%[3]d" height="20" fill="%[6]s"/>`+
+		`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`+
+		`<text x="%[7]d" y="14">%[4]s</text>`+
+		`<text x="%[8]d" y="14">%[5]s</text>`+
+		`</g></svg>`,
+		width, labelWidth, messageWidth, label, message, color, labelWidth/2, labelWidth+messageWidth/2)
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetStatusBadge_Repository(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/badge/:owner/:repo", handler.GetStatusBadge())
+
+	mockDB.On("GetLatestWorkflowRun", mock.Anything, "octo/app", "").Return(&models.WorkflowRun{
+		Status:     models.JobStatusCompleted,
+		Conclusion: "success",
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/badge/octo/app.svg", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "image/svg+xml; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, badgeCacheControl, w.Header().Get("Cache-Control"))
+	assert.Contains(t, w.Body.String(), "passing")
+	assert.Contains(t, w.Body.String(), badgeColorPassing)
+	mockDB.AssertExpectations(t)
+}
+
+func TestGetStatusBadge_Workflow(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/badge/:owner/:repo/:workflow", handler.GetStatusBadge())
+
+	mockDB.On("GetLatestWorkflowRun", mock.Anything, "octo/app", "CI & Deploy").Return(&models.WorkflowRun{
+		Status:     models.JobStatusCompleted,
+		Conclusion: "failure",
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/badge/octo/app/CI%20&%20Deploy.svg", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "CI &amp; Deploy")
+	assert.Contains(t, w.Body.String(), "failing")
+	mockDB.AssertExpectations(t)
+}
+
+func TestGetStatusBadge_DatabaseError(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/badge/:owner/:repo", handler.GetStatusBadge())
+
+	mockDB.On("GetLatestWorkflowRun", mock.Anything, "octo/app", "").Return((*models.WorkflowRun)(nil), errors.New("db error"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/badge/octo/app.svg", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestBadgeStatus(t *testing.T) {
+	tests := []struct {
+		name            string
+		run             *models.WorkflowRun
+		expectedMessage string
+		expectedColor   string
+	}{
+		{"no runs", nil, "no runs", badgeColorNeutral},
+		{"in progress", &models.WorkflowRun{Status: models.JobStatusInProgress}, "in progress", badgeColorRunning},
+		{"success", &models.WorkflowRun{Status: models.JobStatusCompleted, Conclusion: "success"}, "passing", badgeColorPassing},
+		{"timed out", &models.WorkflowRun{Status: models.JobStatusCompleted, Conclusion: "timed_out"}, "failing", badgeColorFailing},
+		{"cancelled", &models.WorkflowRun{Status: models.JobStatusCompleted, Conclusion: "cancelled"}, "cancelled", badgeColorNeutral},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, color := badgeStatus(tt.run)
+			assert.Equal(t, tt.expectedMessage, message)
+			assert.Equal(t, tt.expectedColor, color)
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// maxCanaryResults bounds how many canary results one request returns.
+const maxCanaryResults = 500
+
+// GetCanaryResults returns the most recent synthetic canary results, newest
+// first, and whether the canary is configured.
+func (h *APIHandler) GetCanaryResults() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+		if err != nil || limit < 1 || limit > maxCanaryResults {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+			return
+		}
+
+		results, err := h.db.GetCanaryResults(c.Request.Context(), limit)
+		if err != nil {
+			logger.Logger.Error("Failed to get canary results", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve canary results"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"enabled": h.config.CanaryEnabled(),
+			"results": results,
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCanaryResults(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	testConfig.Vars.CanaryToken = "token"
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/canary", handler.GetCanaryResults())
+
+	dispatchedAt := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+	mockDB.On("GetCanaryResults", mock.Anything, 10).Return([]models.CanaryResult{
+		{ID: 1, DispatchedAt: dispatchedAt, Outcome: models.CanaryOutcomeSuccess, RunID: 7, Conclusion: "success", DurationSeconds: 84},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/canary?limit=10", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Enabled bool                  `json:"enabled"`
+		Results []models.CanaryResult `json:"results"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Enabled)
+	require.Len(t, response.Results, 1)
+	assert.Equal(t, models.CanaryOutcomeSuccess, response.Results[0].Outcome)
+	assert.Equal(t, 84.0, response.Results[0].DurationSeconds)
+}
+
+func TestGetCanaryResults_InvalidLimit(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/canary", handler.GetCanaryResults())
+
+	for _, limit := range []string{"0", "501", "abc"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/canary?limit="+limit, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, "limit=%s", limit)
+	}
+	mockDB.AssertNotCalled(t, "GetCanaryResults", mock.Anything, mock.Anything)
+}
+
+func TestGetCanaryResults_DatabaseError(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/canary", handler.GetCanaryResults())
+
+	mockDB.On("GetCanaryResults", mock.Anything, 50).Return([]models.CanaryResult{}, errors.New("db error"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/canary", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"container/heap"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/utils"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxSimulatedCapacities bounds how many runner counts one request compares.
+	maxSimulatedCapacities = 5
+	// maxSimulatedRunners bounds a single simulated runner count.
+	maxSimulatedRunners = 10000
+)
+
+// GetCapacitySimulation replays the jobs that ran on a runner label over a
+// period against hypothetical runner counts and reports the queue times each
+// would have produced next to the observed ones. The replay assumes identical
+// runners serving jobs first in, first out, and that jobs run as long as they
+// did, so it ignores runner start-up time and jobs sharing runners with other
+// labels.
+func (h *APIHandler) GetCapacitySimulation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		label := c.Query("label")
+		if label == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "label is required"})
+			return
+		}
+		capacities, ok := parseCapacities(c.Query("runners"))
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "runners must list 1 to 5 runner counts between 1 and 10000, e.g. runners=10,20"})
+			return
+		}
+
+		period := c.DefaultQuery("period", "week")
+		timings, err := h.db.GetJobTimings(c.Request.Context(), periodToDuration(period), label)
+		if err != nil {
+			logger.Logger.Error("Failed to get job timings", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to run capacity simulation"})
+			return
+		}
+
+		waits := make([]float64, len(timings))
+		for i, t := range timings {
+			waits[i] = t.StartedAt.Sub(t.QueuedAt).Seconds()
+		}
+		observed := summarizeQueue(waits)
+		observed.Runners = peakConcurrency(timings)
+
+		projections := make([]models.QueueProjection, 0, len(capacities))
+		for _, runners := range capacities {
+			projections = append(projections, simulateQueue(timings, runners))
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"label":       label,
+			"period":      period,
+			"observed":    observed,
+			"projections": projections,
+		})
+	}
+}
+
+// parseCapacities parses a comma-separated list of runner counts.
+func parseCapacities(s string) ([]int, bool) {
+	var capacities []int
+	for _, part := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || n < 1 || n > maxSimulatedRunners {
+			return nil, false
+		}
+		capacities = append(capacities, n)
+	}
+	return capacities, len(capacities) <= maxSimulatedCapacities
+}
+
+// runnerHeap holds the times at which each simulated runner becomes free.
+type runnerHeap []time.Time
+
+func (h runnerHeap) Len() int            { return len(h) }
+func (h runnerHeap) Less(i, j int) bool  { return h[i].Before(h[j]) }
+func (h runnerHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *runnerHeap) Push(x interface{}) { *h = append(*h, x.(time.Time)) }
+func (h *runnerHeap) Pop() interface{} {
+	old := *h
+	t := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return t
+}
+
+// simulateQueue replays jobs, sorted by queue time, on the given number of
+// runners, starting each job on the first runner to become free.
+func simulateQueue(jobs []models.JobTiming, runners int) models.QueueProjection {
+	free := make(runnerHeap, runners)
+	waits := make([]float64, len(jobs))
+	for i, job := range jobs {
+		start := heap.Pop(&free).(time.Time)
+		if start.Before(job.QueuedAt) {
+			start = job.QueuedAt
+		}
+		waits[i] = start.Sub(job.QueuedAt).Seconds()
+		heap.Push(&free, start.Add(time.Duration(job.RunSeconds*float64(time.Second))))
+	}
+
+	projection := summarizeQueue(waits)
+	projection.Runners = runners
+	return projection
+}
+
+// summarizeQueue computes queue time statistics. It sorts waits in place.
+func summarizeQueue(waits []float64) models.QueueProjection {
+	projection := models.QueueProjection{Jobs: len(waits)}
+	if len(waits) == 0 {
+		return projection
+	}
+
+	sort.Float64s(waits)
+	total := 0.0
+	for _, w := range waits {
+		total += w
+		if w > 1 {
+			projection.Waited++
+		}
+	}
+	projection.AvgQueueSeconds = total / float64(len(waits))
+	projection.P50QueueSeconds = utils.Percentile(waits, 50)
+	projection.P95QueueSeconds = utils.Percentile(waits, 95)
+	projection.MaxQueueSeconds = waits[len(waits)-1]
+	return projection
+}
+
+// peakConcurrency returns the largest number of jobs that ran at once.
+func peakConcurrency(jobs []models.JobTiming) int {
+	type edge struct {
+		at    time.Time
+		delta int
+	}
+	edges := make([]edge, 0, 2*len(jobs))
+	for _, job := range jobs {
+		edges = append(edges,
+			edge{job.StartedAt, 1},
+			edge{job.StartedAt.Add(time.Duration(job.RunSeconds * float64(time.Second))), -1})
+	}
+	// Ends sort before starts at the same instant, so back-to-back jobs
+	// count as one runner
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].at.Equal(edges[j].at) {
+			return edges[i].delta < edges[j].delta
+		}
+		return edges[i].at.Before(edges[j].at)
+	})
+
+	running, peak := 0, 0
+	for _, e := range edges {
+		running += e.delta
+		if running > peak {
+			peak = running
+		}
+	}
+	return peak
+}
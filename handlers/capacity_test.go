@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// burstTimings returns three 10 minute jobs queued at once that started one
+// after another on a single runner.
+func burstTimings(start time.Time) []models.JobTiming {
+	return []models.JobTiming{
+		{QueuedAt: start, StartedAt: start, RunSeconds: 600},
+		{QueuedAt: start, StartedAt: start.Add(10 * time.Minute), RunSeconds: 600},
+		{QueuedAt: start, StartedAt: start.Add(20 * time.Minute), RunSeconds: 600},
+	}
+}
+
+func TestSimulateQueue(t *testing.T) {
+	jobs := burstTimings(time.Now())
+
+	one := simulateQueue(jobs, 1)
+	assert.Equal(t, 1, one.Runners)
+	assert.Equal(t, 3, one.Jobs)
+	assert.Equal(t, 2, one.Waited)
+	assert.InDelta(t, 600, one.AvgQueueSeconds, 0.001)
+	assert.InDelta(t, 1200, one.MaxQueueSeconds, 0.001)
+
+	two := simulateQueue(jobs, 2)
+	assert.Equal(t, 1, two.Waited)
+	assert.InDelta(t, 600, two.MaxQueueSeconds, 0.001)
+
+	three := simulateQueue(jobs, 3)
+	assert.Equal(t, 0, three.Waited)
+	assert.Zero(t, three.MaxQueueSeconds)
+}
+
+func TestPeakConcurrency(t *testing.T) {
+	start := time.Now()
+	assert.Equal(t, 1, peakConcurrency(burstTimings(start)), "back-to-back jobs use one runner")
+	assert.Equal(t, 2, peakConcurrency([]models.JobTiming{
+		{StartedAt: start, RunSeconds: 600},
+		{StartedAt: start.Add(time.Minute), RunSeconds: 60},
+	}))
+	assert.Equal(t, 0, peakConcurrency(nil))
+}
+
+func TestParseCapacities(t *testing.T) {
+	capacities, ok := parseCapacities("10, 20")
+	assert.True(t, ok)
+	assert.Equal(t, []int{10, 20}, capacities)
+
+	for _, invalid := range []string{"", "0", "ten", "10,", "20000", "1,2,3,4,5,6"} {
+		_, ok := parseCapacities(invalid)
+		assert.False(t, ok, "runners=%q", invalid)
+	}
+}
+
+func TestGetCapacitySimulation(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/analytics/capacity", handler.GetCapacitySimulation())
+
+	mockDB.On("GetJobTimings", mock.Anything, 7*24*time.Hour, "large").Return(burstTimings(time.Now().Add(-time.Hour)), nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/analytics/capacity?label=large&runners=2,3", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Observed    models.QueueProjection   `json:"observed"`
+		Projections []models.QueueProjection `json:"projections"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Observed.Runners)
+	assert.InDelta(t, 1200, response.Observed.MaxQueueSeconds, 0.001)
+	require.Len(t, response.Projections, 2)
+	assert.Equal(t, 2, response.Projections[0].Runners)
+	assert.InDelta(t, 600, response.Projections[0].MaxQueueSeconds, 0.001)
+	assert.Zero(t, response.Projections[1].MaxQueueSeconds)
+}
+
+func TestGetCapacitySimulation_InvalidParams(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/analytics/capacity", handler.GetCapacitySimulation())
+
+	for _, query := range []string{"runners=10", "label=large", "label=large&runners=0"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/analytics/capacity?"+query, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/clock"
+	"github.com/gateixeira/live-actions/internal/config"
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/internal/utils"
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	// settingCSRFKey persists the key CSRF tokens are signed with, so tokens
+	// survive restarts and are accepted by every instance sharing a database.
+	settingCSRFKey = "csrf_signing_key"
+	// csrfKeyRefresh is how often the signing key is re-read, so a key
+	// rotated by another instance sharing the database takes effect here too.
+	csrfKeyRefresh = 30 * time.Second
+)
+
+// csrfSigner signs and verifies CSRF tokens. Tokens read "<nonce>.<signature>";
+// once the key is rotated, tokens signed with the previous one are rejected
+// and dashboards have to fetch a new one.
+type csrfSigner struct {
+	mutex    sync.Mutex
+	key      []byte
+	db       database.DatabaseInterface
+	loadedAt time.Time
+	// clock times key refreshes; nil uses the system clock
+	clock clock.Clock
+}
+
+// csrf signs the CSRF tokens of this process. Its key is random until
+// LoadCSRFKey replaces it with the persisted one.
+var csrf = &csrfSigner{key: newCSRFKey()}
+
+func newCSRFKey() []byte {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	return key
+}
+
+// LoadCSRFKey signs CSRF tokens with the key persisted in db, creating one if
+// there is none yet, and keeps re-reading it from there on cfg's clock.
+func LoadCSRFKey(ctx context.Context, cfg *config.Config, db database.DatabaseInterface) error {
+	return csrf.load(ctx, db, cfg.Clock)
+}
+
+func (s *csrfSigner) load(ctx context.Context, db database.DatabaseInterface, clk clock.Clock) error {
+	settings, err := db.GetSettings(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load CSRF signing key: %w", err)
+	}
+	key, err := hex.DecodeString(settings[settingCSRFKey])
+	if err != nil || len(key) == 0 {
+		key = newCSRFKey()
+		if err := db.SaveSettings(ctx, map[string]string{settingCSRFKey: hex.EncodeToString(key)}); err != nil {
+			return fmt.Errorf("failed to save CSRF signing key: %w", err)
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.db, s.key, s.clock = db, key, clk
+	s.loadedAt = s.now()
+	return nil
+}
+
+// now must be called with mutex held.
+func (s *csrfSigner) now() time.Time {
+	if s.clock == nil {
+		return time.Now()
+	}
+	return s.clock.Now()
+}
+
+// currentKey returns the signing key, re-reading it when it was last read
+// more than csrfKeyRefresh ago. The cached key is kept if that fails.
+func (s *csrfSigner) currentKey(ctx context.Context) []byte {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := s.now()
+	if s.db == nil || now.Sub(s.loadedAt) < csrfKeyRefresh {
+		return s.key
+	}
+
+	s.loadedAt = now
+	settings, err := s.db.GetSettings(ctx)
+	if err != nil {
+		logger.Logger.Warn("Failed to refresh CSRF signing key", zap.Error(err))
+		return s.key
+	}
+	if key, err := hex.DecodeString(settings[settingCSRFKey]); err == nil && len(key) > 0 {
+		s.key = key
+	}
+	return s.key
+}
+
+// rotate replaces the signing key, invalidating every token issued so far.
+func (s *csrfSigner) rotate(ctx context.Context) error {
+	key := newCSRFKey()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.db != nil {
+		if err := s.db.SaveSettings(ctx, map[string]string{settingCSRFKey: hex.EncodeToString(key)}); err != nil {
+			return fmt.Errorf("failed to save CSRF signing key: %w", err)
+		}
+	}
+	s.key, s.loadedAt = key, s.now()
+	return nil
+}
+
+// issue returns a new token signed with the current key.
+func (s *csrfSigner) issue(ctx context.Context) (string, error) {
+	nonce, err := utils.GenerateCSRFToken()
+	if err != nil {
+		return "", err
+	}
+	return nonce + "." + csrfSignature(s.currentKey(ctx), nonce), nil
+}
+
+// verify reports whether token was signed with the current key.
+func (s *csrfSigner) verify(ctx context.Context, token string) bool {
+	nonce, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(csrfSignature(s.currentKey(ctx), nonce)))
+}
+
+func csrfSignature(key []byte, nonce string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("csrf\n" + nonce))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// RotateCSRFKey replaces the key CSRF tokens are signed with, on every
+// instance sharing the database within csrfKeyRefresh. Every dashboard's
+// token stops being accepted, so dashboards fetch a new one before their next
+// API call; use it after a suspected token leak.
+func (h *AdminHandler) RotateCSRFKey() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := csrf.rotate(c.Request.Context()); err != nil {
+			logger.Logger.Error("Failed to rotate CSRF signing key", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rotate CSRF signing key"})
+			return
+		}
+		logger.Logger.Warn("CSRF signing key rotated, all dashboard sessions invalidated",
+			zap.String("client_ip", c.ClientIP()),
+			zap.String("user_agent", c.Request.UserAgent()))
+
+		c.JSON(http.StatusOK, gin.H{
+			"rotated_at": h.config.Now().UTC().Truncate(time.Second),
+			// Other instances sharing the database pick up the new key within this
+			"propagation_seconds": int(csrfKeyRefresh.Seconds()),
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/clock"
+	"github.com/gateixeira/live-actions/internal/config"
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// signedCSRFToken issues a token ValidateOrigin accepts.
+func signedCSRFToken(t *testing.T) string {
+	token, err := csrf.issue(context.Background())
+	require.NoError(t, err)
+	return token
+}
+
+func TestCSRFSigner(t *testing.T) {
+	ctx := context.Background()
+	signer := &csrfSigner{key: newCSRFKey()}
+
+	token, err := signer.issue(ctx)
+	require.NoError(t, err)
+	assert.True(t, signer.verify(ctx, token))
+	assert.False(t, signer.verify(ctx, token+"0"))
+	assert.False(t, signer.verify(ctx, "unsigned"))
+	assert.False(t, (&csrfSigner{key: newCSRFKey()}).verify(ctx, token), "tokens are bound to the key")
+
+	require.NoError(t, signer.rotate(ctx))
+	assert.False(t, signer.verify(ctx, token), "rotation invalidates issued tokens")
+}
+
+func TestCSRFSigner_Load(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Creates a key when none is stored", func(t *testing.T) {
+		mockDB := new(database.MockDatabase)
+		mockDB.On("GetSettings", mock.Anything).Return(map[string]string{}, nil)
+		var saved string
+		mockDB.On("SaveSettings", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			saved = args.Get(1).(map[string]string)[settingCSRFKey]
+		}).Return(nil)
+
+		signer := &csrfSigner{}
+		require.NoError(t, signer.load(ctx, mockDB, nil))
+		assert.Equal(t, saved, hex.EncodeToString(signer.key))
+		assert.Len(t, signer.key, 32)
+	})
+
+	t.Run("Shares the stored key", func(t *testing.T) {
+		key := newCSRFKey()
+		mockDB := new(database.MockDatabase)
+		mockDB.On("GetSettings", mock.Anything).Return(map[string]string{settingCSRFKey: hex.EncodeToString(key)}, nil)
+
+		first, second := &csrfSigner{}, &csrfSigner{}
+		require.NoError(t, first.load(ctx, mockDB, nil))
+		require.NoError(t, second.load(ctx, mockDB, nil))
+
+		token, err := first.issue(ctx)
+		require.NoError(t, err)
+		assert.True(t, second.verify(ctx, token))
+		mockDB.AssertNotCalled(t, "SaveSettings", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Fails when settings cannot be read", func(t *testing.T) {
+		mockDB := new(database.MockDatabase)
+		mockDB.On("GetSettings", mock.Anything).Return(map[string]string{}, errors.New("db error"))
+
+		assert.Error(t, (&csrfSigner{}).load(ctx, mockDB, nil))
+	})
+}
+
+func TestCSRFSigner_PicksUpRotationElsewhere(t *testing.T) {
+	ctx := context.Background()
+	stored := map[string]string{settingCSRFKey: hex.EncodeToString(newCSRFKey())}
+	mockDB := new(database.MockDatabase)
+	mockDB.On("GetSettings", mock.Anything).Return(stored, nil)
+	mockDB.On("SaveSettings", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored[settingCSRFKey] = args.Get(1).(map[string]string)[settingCSRFKey]
+	}).Return(nil)
+
+	fake := clock.NewFake(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	here, elsewhere := &csrfSigner{}, &csrfSigner{}
+	require.NoError(t, here.load(ctx, mockDB, fake))
+	require.NoError(t, elsewhere.load(ctx, mockDB, fake))
+	token, err := here.issue(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, elsewhere.rotate(ctx))
+	assert.True(t, here.verify(ctx, token), "the key is re-read at most every csrfKeyRefresh")
+
+	fake.Advance(csrfKeyRefresh)
+	assert.False(t, here.verify(ctx, token))
+}
+
+func TestRotateCSRFKey(t *testing.T) {
+	router, mockDB, _ := setupAPITest()
+	handler := NewAdminHandler(&config.Config{}, mockDB, nil, nil)
+	router.POST("/api/admin/security/rotate-csrf", handler.RotateCSRFKey())
+	router.GET("/api/test", ValidateOrigin(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+
+	call := func(token string) int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/test", nil)
+		req.Host = "localhost:8080"
+		req.Header.Set("Referer", "http://localhost:8080/")
+		req.Header.Set(utils.HeaderName, token)
+		req.AddCookie(&http.Cookie{Name: utils.CookieName, Value: token})
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	token := signedCSRFToken(t)
+	require.Equal(t, http.StatusOK, call(token))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/admin/security/rotate-csrf", nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "rotated_at")
+
+	assert.Equal(t, http.StatusForbidden, call(token))
+	assert.Equal(t, http.StatusOK, call(signedCSRFToken(t)))
+}
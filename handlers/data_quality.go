@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// maxReportedGaps caps how many individual gaps the data quality report lists;
+// the counts still cover every entity in the period.
+const maxReportedGaps = 100
+
+// expectedProgressions lists, per event type, the statuses GitHub delivers for
+// every job or run, in order. Waiting and requested jobs only occur with
+// environment protection rules and larger runners, so they are not expected.
+var expectedProgressions = map[string][]models.JobStatus{
+	"workflow_job": {models.JobStatusQueued, models.JobStatusInProgress, models.JobStatusCompleted},
+	"workflow_run": {models.JobStatusRequested, models.JobStatusInProgress, models.JobStatusCompleted},
+}
+
+// statusPriorities maps each event type to its status ordering.
+var statusPriorities = map[string]map[models.JobStatus]int{
+	"workflow_job": jobStatusPriorities,
+	"workflow_run": runStatusPriorities,
+}
+
+// statusNames lists every status in delivery order, so a priority shared by
+// completed and cancelled is named completed.
+var statusNames = []models.JobStatus{
+	models.JobStatusWaiting,
+	models.JobStatusRequested,
+	models.JobStatusQueued,
+	models.JobStatusInProgress,
+	models.JobStatusCompleted,
+	models.JobStatusCancelled,
+}
+
+// GetDataQuality reports jobs and runs whose webhook deliveries skipped an
+// expected status over a period (default: day). Gaps spread across many
+// repositories usually point at delivery problems on the GitHub or
+// organization side rather than in any one workflow.
+func (h *APIHandler) GetDataQuality() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		period := c.DefaultQuery("period", "day")
+		since := periodToDuration(period)
+
+		sequences, err := h.db.GetEventStatusSequences(c.Request.Context(), since)
+		if err != nil {
+			logger.Logger.Error("Failed to get event status sequences", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve data quality report"})
+			return
+		}
+
+		report := dataQualityReport(sequences)
+		report.Period = period
+		c.JSON(http.StatusOK, report)
+	}
+}
+
+// dataQualityReport checks each sequence against its expected progression.
+// Sequences are expected newest first, as returned by the database.
+func dataQualityReport(sequences []models.EventStatusSequence) models.DataQualityReport {
+	report := models.DataQualityReport{
+		MissingByStatus: map[string]int{},
+		Gaps:            []models.SequenceGap{},
+	}
+	for _, seq := range sequences {
+		if _, ok := expectedProgressions[seq.EventType]; !ok || len(seq.Priorities) == 0 {
+			continue
+		}
+		report.EntitiesChecked++
+
+		gap, ok := findSequenceGap(seq)
+		if !ok {
+			continue
+		}
+		report.EntitiesWithGaps++
+		for _, status := range gap.Missing {
+			report.MissingByStatus[seq.EventType+":"+status]++
+		}
+		if len(report.Gaps) < maxReportedGaps {
+			report.Gaps = append(report.Gaps, gap)
+		}
+	}
+	if report.EntitiesChecked > 0 {
+		report.GapRate = float64(report.EntitiesWithGaps) / float64(report.EntitiesChecked) * 100
+	}
+	return report
+}
+
+// findSequenceGap returns the expected statuses missing before the latest one
+// received. Jobs and runs cancelled or skipped before starting legitimately
+// never reach in_progress.
+func findSequenceGap(seq models.EventStatusSequence) (models.SequenceGap, bool) {
+	priorities := statusPriorities[seq.EventType]
+	latest := seq.Priorities[len(seq.Priorities)-1]
+	received := make(map[int]bool, len(seq.Priorities))
+	for _, p := range seq.Priorities {
+		received[p] = true
+	}
+
+	var missing []string
+	for _, status := range expectedProgressions[seq.EventType] {
+		p := priorities[status]
+		if p >= latest {
+			break
+		}
+		if received[p] || (seq.NotStarted && status == models.JobStatusInProgress) {
+			continue
+		}
+		missing = append(missing, string(status))
+	}
+	if len(missing) == 0 {
+		return models.SequenceGap{}, false
+	}
+
+	names := make([]string, 0, len(seq.Priorities))
+	for _, p := range seq.Priorities {
+		names = append(names, statusName(priorities, p))
+	}
+	return models.SequenceGap{
+		EventType:      seq.EventType,
+		OrderingKey:    seq.OrderingKey,
+		Received:       names,
+		Missing:        missing,
+		LastReceivedAt: seq.LastReceivedAt,
+	}, true
+}
+
+// statusName returns the status with the given priority, or "unknown" for
+// actions the handlers did not recognize.
+func statusName(priorities map[models.JobStatus]int, priority int) string {
+	for _, status := range statusNames {
+		if p, ok := priorities[status]; ok && p == priority {
+			return string(status)
+		}
+	}
+	return "unknown"
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetDataQuality(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/system/data-quality", handler.GetDataQuality())
+
+	mockDB.On("GetEventStatusSequences", mock.Anything, 24*time.Hour).Return([]models.EventStatusSequence{
+		// Completed without in_progress
+		{EventType: "workflow_job", OrderingKey: "job_1", Priorities: []int{2, 5}},
+		// Complete progression, with the optional waiting status
+		{EventType: "workflow_job", OrderingKey: "job_2", Priorities: []int{1, 2, 4, 5}},
+		// Cancelled while queued
+		{EventType: "workflow_job", OrderingKey: "job_3", Priorities: []int{2, 5}, NotStarted: true},
+		// Still running, nothing missing so far
+		{EventType: "workflow_job", OrderingKey: "job_4", Priorities: []int{2, 4}},
+		// Only the completion arrived
+		{EventType: "workflow_run", OrderingKey: "run_5", Priorities: []int{3}},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/system/data-quality", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var report models.DataQualityReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+
+	assert.Equal(t, "day", report.Period)
+	assert.Equal(t, 5, report.EntitiesChecked)
+	assert.Equal(t, 2, report.EntitiesWithGaps)
+	assert.InDelta(t, 40, report.GapRate, 0.001)
+	assert.Equal(t, map[string]int{
+		"workflow_job:in_progress": 1,
+		"workflow_run:requested":   1,
+		"workflow_run:in_progress": 1,
+	}, report.MissingByStatus)
+
+	require.Len(t, report.Gaps, 2)
+	assert.Equal(t, "job_1", report.Gaps[0].OrderingKey)
+	assert.Equal(t, []string{"queued", "completed"}, report.Gaps[0].Received)
+	assert.Equal(t, []string{"in_progress"}, report.Gaps[0].Missing)
+	assert.Equal(t, []string{"requested", "in_progress"}, report.Gaps[1].Missing)
+	mockDB.AssertExpectations(t)
+}
+
+func TestGetDataQuality_DatabaseError(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/system/data-quality", handler.GetDataQuality())
+
+	mockDB.On("GetEventStatusSequences", mock.Anything, time.Hour).Return([]models.EventStatusSequence{}, errors.New("db down"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/system/data-quality?period=hour", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"sync"
+	"time"
+)
+
+// statusDeduper suppresses repeated status updates for the same entity that
+// arrive within a window, as emitted by some runner setups seconds apart.
+// A zero window disables suppression.
+type statusDeduper struct {
+	mutex     sync.Mutex
+	window    time.Duration
+	seen      map[string]dedupeEntry
+	lastPrune time.Time
+}
+
+type dedupeEntry struct {
+	status string
+	at     time.Time
+}
+
+func newStatusDeduper(window time.Duration) *statusDeduper {
+	return &statusDeduper{window: window, seen: make(map[string]dedupeEntry)}
+}
+
+// isDuplicate reports whether key was last recorded with the same status
+// within the window.
+func (d *statusDeduper) isDuplicate(key string, status string, now time.Time) bool {
+	if d.window <= 0 {
+		return false
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	entry, ok := d.seen[key]
+	return ok && entry.status == status && now.Sub(entry.at) < d.window
+}
+
+// record remembers the status last applied to key, dropping entries that
+// have left the window.
+func (d *statusDeduper) record(key string, status string, now time.Time) {
+	if d.window <= 0 {
+		return
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if now.Sub(d.lastPrune) >= d.window {
+		for k, entry := range d.seen {
+			if now.Sub(entry.at) >= d.window {
+				delete(d.seen, k)
+			}
+		}
+		d.lastPrune = now
+	}
+	d.seen[key] = dedupeEntry{status: status, at: now}
+}
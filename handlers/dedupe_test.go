@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusDeduper(t *testing.T) {
+	d := newStatusDeduper(10 * time.Second)
+	now := time.Now()
+
+	assert.False(t, d.isDuplicate("job_1", "in_progress", now))
+	d.record("job_1", "in_progress", now)
+
+	assert.True(t, d.isDuplicate("job_1", "in_progress", now.Add(3*time.Second)))
+	assert.False(t, d.isDuplicate("job_1", "completed", now.Add(3*time.Second)), "a status change is never suppressed")
+	assert.False(t, d.isDuplicate("job_2", "in_progress", now.Add(3*time.Second)))
+	assert.False(t, d.isDuplicate("job_1", "in_progress", now.Add(10*time.Second)), "the window has passed")
+
+	d.record("job_2", "queued", now.Add(20*time.Second))
+	assert.NotContains(t, d.seen, "job_1", "expired entries are pruned")
+}
+
+func TestStatusDeduper_Disabled(t *testing.T) {
+	d := newStatusDeduper(0)
+	now := time.Now()
+
+	d.record("job_1", "in_progress", now)
+	assert.False(t, d.isDuplicate("job_1", "in_progress", now))
+}
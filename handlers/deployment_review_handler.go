@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/internal/payload"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// DeploymentReviewHandler records which environment jobs waiting for an
+// approval deploy to, since workflow_job events do not say.
+type DeploymentReviewHandler struct {
+	db database.DatabaseInterface
+}
+
+func NewDeploymentReviewHandler(db database.DatabaseInterface) *DeploymentReviewHandler {
+	return &DeploymentReviewHandler{db: db}
+}
+
+func (h *DeploymentReviewHandler) GetEventType() string {
+	return "deployment_review"
+}
+
+func (h *DeploymentReviewHandler) HandleEvent(eventData []byte, sequence *models.EventSequence) error {
+	event, err := payload.ParseDeploymentReviewEvent(eventData)
+	if err != nil {
+		logger.Logger.Error("Failed to parse deployment_review JSON payload",
+			zap.Error(err),
+			zap.String("delivery_id", sequence.DeliveryID))
+		return fmt.Errorf("invalid JSON payload: %w", err)
+	}
+	if len(event.Jobs) == 0 {
+		return nil
+	}
+
+	logger.Logger.Info("Processing deployment review event",
+		zap.String("action", event.Action),
+		zap.Int64("run_id", event.RunID),
+		zap.Int("jobs", len(event.Jobs)),
+		zap.String("delivery_id", sequence.DeliveryID))
+
+	if err := h.db.SetJobEnvironments(context.TODO(), event.RunID, event.Jobs); err != nil {
+		logger.Logger.Error("Error saving job environments",
+			zap.Error(err),
+			zap.String("delivery_id", sequence.DeliveryID),
+			zap.Int64("run_id", event.RunID))
+		return fmt.Errorf("failed to save job environments: %w", err)
+	}
+	return nil
+}
+
+// ExtractEventTimestamp returns the current time, as deployment reviews carry
+// no timestamp of their own.
+func (h *DeploymentReviewHandler) ExtractEventTimestamp(eventData []byte) (time.Time, error) {
+	if _, err := payload.ParseDeploymentReviewEvent(eventData); err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse deployment_review JSON payload: %w", err)
+	}
+	return time.Now(), nil
+}
+
+func (h *DeploymentReviewHandler) ExtractOrderingKey(eventData []byte) (string, error) {
+	event, err := payload.ParseDeploymentReviewEvent(eventData)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse deployment_review JSON payload: %w", err)
+	}
+
+	return fmt.Sprintf("review_%d", event.RunID), nil
+}
+
+// reviewActionPriorities orders deployment_review actions as GitHub delivers them.
+var reviewActionPriorities = map[string]int{
+	"requested": 1,
+	"approved":  2,
+	"rejected":  2,
+}
+
+func (h *DeploymentReviewHandler) GetStatusPriority(eventData []byte) (int, error) {
+	event, err := payload.ParseDeploymentReviewEvent(eventData)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse deployment_review JSON payload: %w", err)
+	}
+
+	if priority, ok := reviewActionPriorities[event.Action]; ok {
+		return priority, nil
+	}
+	logger.Logger.Warn("Unknown deployment review action", zap.String("action", event.Action))
+	return 999, nil
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const reviewRequested = `{"action":"requested","environment":"production","workflow_run":{"id":7},"workflow_job_run":{"id":11,"environment":"production"}}`
+
+func TestDeploymentReviewHandler_HandleEvent(t *testing.T) {
+	logger.InitLogger("error")
+	mockDB := &database.MockDatabase{}
+	handler := NewDeploymentReviewHandler(mockDB)
+
+	mockDB.On("SetJobEnvironments", mock.Anything, int64(7), []models.EnvironmentJob{{ID: 11, Environment: "production"}}).Return(nil).Once()
+
+	assert.NoError(t, handler.HandleEvent([]byte(reviewRequested), &models.EventSequence{DeliveryID: "d1"}))
+	assert.NoError(t, handler.HandleEvent([]byte(`{"action":"approved","workflow_run":{"id":7}}`), &models.EventSequence{DeliveryID: "d2"}),
+		"reviews naming no jobs are ignored")
+	mockDB.AssertExpectations(t)
+
+	mockDB.On("SetJobEnvironments", mock.Anything, int64(7), mock.Anything).Return(errors.New("db error"))
+	assert.Error(t, handler.HandleEvent([]byte(reviewRequested), &models.EventSequence{DeliveryID: "d3"}))
+	assert.Error(t, handler.HandleEvent([]byte(`{invalid`), &models.EventSequence{DeliveryID: "d4"}))
+}
+
+func TestDeploymentReviewHandler_Ordering(t *testing.T) {
+	logger.InitLogger("error")
+	handler := NewDeploymentReviewHandler(&database.MockDatabase{})
+
+	assert.Equal(t, "deployment_review", handler.GetEventType())
+
+	key, err := handler.ExtractOrderingKey([]byte(reviewRequested))
+	assert.NoError(t, err)
+	assert.Equal(t, "review_7", key)
+
+	requested, _ := handler.GetStatusPriority([]byte(reviewRequested))
+	approved, _ := handler.GetStatusPriority([]byte(`{"action":"approved","workflow_run":{"id":7}}`))
+	assert.Less(t, requested, approved)
+}
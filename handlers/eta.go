@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/internal/jobnames"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// minETASamples is the number of previous successful runs needed before an ETA is shown.
+const minETASamples = 3
+
+// addJobETAs sets an ETA on the in-progress jobs of a run. A job without
+// enough history under its own name uses the history of the name, merged with
+// it by rules or a confirmed merge, that has the most. Failing to load the
+// duration history only drops the estimates.
+func addJobETAs(ctx context.Context, db database.DatabaseInterface, jobNames *jobNameCache, runID int64, jobs []models.WorkflowJob, now time.Time) {
+	hasRunning := false
+	for _, job := range jobs {
+		if job.Status == models.JobStatusInProgress && !job.StartedAt.IsZero() {
+			hasRunning = true
+			break
+		}
+	}
+	if !hasRunning {
+		return
+	}
+
+	stats, err := db.GetJobDurationStats(ctx, runID)
+	if err != nil {
+		logger.Logger.Warn("Failed to get job duration stats", zap.Int64("run_id", runID), zap.Error(err))
+		return
+	}
+
+	var names *jobnames.Resolver
+	for i := range jobs {
+		if jobs[i].Status != models.JobStatusInProgress || jobs[i].StartedAt.IsZero() {
+			continue
+		}
+		jobStats := stats[jobs[i].Name]
+		if jobStats.Samples < minETASamples && len(stats) > 0 {
+			if names == nil {
+				if names, err = jobNames.get(ctx, now); err != nil {
+					logger.Logger.Warn("Failed to get job name merges", zap.Error(err))
+					names = jobnames.NewResolver(jobNames.rules, nil)
+				}
+			}
+			jobStats = renamedJobStats(stats, names, jobs[i].Name)
+		}
+		jobs[i].ETA = estimateJobETA(jobs[i].StartedAt, jobStats, now)
+	}
+}
+
+// renamedJobStats returns the stats with the most samples among the names
+// that resolve like name.
+func renamedJobStats(stats map[string]models.DurationStats, names *jobnames.Resolver, name string) models.DurationStats {
+	key := names.Key(name)
+	best := stats[name]
+	for other, s := range stats {
+		if s.Samples > best.Samples && names.Key(other) == key {
+			best = s
+		}
+	}
+	return best
+}
+
+// estimateJobETA projects the completion time of a job started at startedAt.
+// Jobs already running past the median are expected to finish imminently, so
+// the estimate never lies in the past.
+func estimateJobETA(startedAt time.Time, stats models.DurationStats, now time.Time) *models.JobETA {
+	if stats.Samples < minETASamples {
+		return nil
+	}
+
+	seconds := func(s float64) time.Duration { return time.Duration(s * float64(time.Second)) }
+
+	estimate := startedAt.Add(seconds(stats.P50))
+	if estimate.Before(now) {
+		estimate = now
+	}
+	earliest := startedAt.Add(seconds(stats.P10))
+	if earliest.Before(now) {
+		earliest = now
+	}
+	latest := startedAt.Add(seconds(stats.P90))
+	if latest.Before(estimate) {
+		latest = estimate
+	}
+
+	progress := 99.0
+	if stats.P50 > 0 {
+		progress = math.Min(99, now.Sub(startedAt).Seconds()/stats.P50*100)
+	}
+
+	return &models.JobETA{
+		EstimatedCompletion: estimate.UTC().Truncate(time.Second),
+		Earliest:            earliest.UTC().Truncate(time.Second),
+		Latest:              latest.UTC().Truncate(time.Second),
+		Progress:            math.Round(math.Max(0, progress)*10) / 10,
+		Samples:             stats.Samples,
+	}
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/internal/jobnames"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateJobETA(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	stats := models.DurationStats{Samples: 10, P10: 300, P50: 600, P90: 1200}
+
+	t.Run("Within expected duration", func(t *testing.T) {
+		eta := estimateJobETA(now.Add(-2*time.Minute), stats, now)
+
+		require.NotNil(t, eta)
+		assert.Equal(t, now.Add(8*time.Minute), eta.EstimatedCompletion)
+		assert.Equal(t, now.Add(3*time.Minute), eta.Earliest)
+		assert.Equal(t, now.Add(18*time.Minute), eta.Latest)
+		assert.Equal(t, 20.0, eta.Progress)
+		assert.Equal(t, 10, eta.Samples)
+	})
+
+	t.Run("Overrunning the median", func(t *testing.T) {
+		eta := estimateJobETA(now.Add(-15*time.Minute), stats, now)
+
+		require.NotNil(t, eta)
+		assert.Equal(t, now, eta.EstimatedCompletion)
+		assert.Equal(t, now, eta.Earliest)
+		assert.Equal(t, now.Add(5*time.Minute), eta.Latest)
+		assert.Equal(t, 99.0, eta.Progress)
+	})
+
+	t.Run("Not enough history", func(t *testing.T) {
+		assert.Nil(t, estimateJobETA(now, models.DurationStats{Samples: 2, P50: 600}, now))
+	})
+}
+
+func TestAddJobETAs(t *testing.T) {
+	now := time.Now()
+	mockDB := &database.MockDatabase{}
+	mockDB.On("GetJobDurationStats", mock.Anything, int64(7)).Return(map[string]models.DurationStats{
+		"build": {Samples: 5, P10: 60, P50: 120, P90: 240},
+	}, nil)
+	mockDB.On("GetJobNameMerges", mock.Anything).Return([]models.JobNameMerge{}, nil)
+
+	jobs := []models.WorkflowJob{
+		{ID: 1, Name: "build", Status: models.JobStatusInProgress, StartedAt: now.Add(-time.Minute)},
+		{ID: 2, Name: "lint", Status: models.JobStatusInProgress, StartedAt: now.Add(-time.Minute)},
+		{ID: 3, Name: "build", Status: models.JobStatusCompleted, StartedAt: now.Add(-time.Hour)},
+	}
+	addJobETAs(t.Context(), mockDB, newJobNameCache(mockDB, nil), 7, jobs, now)
+
+	assert.NotNil(t, jobs[0].ETA)
+	assert.Nil(t, jobs[1].ETA, "jobs without history get no estimate")
+	assert.Nil(t, jobs[2].ETA, "completed jobs get no estimate")
+}
+
+func TestAddJobETAs_RenamedJob(t *testing.T) {
+	now := time.Now()
+	mockDB := &database.MockDatabase{}
+	mockDB.On("GetJobDurationStats", mock.Anything, int64(7)).Return(map[string]models.DurationStats{
+		"build (18, ubuntu)":   {Samples: 5, P10: 60, P50: 120, P90: 240},
+		"🚀 build (18, ubuntu)": {Samples: 1, P50: 600},
+		"compile":              {Samples: 8, P10: 60, P50: 300, P90: 600},
+	}, nil)
+	mockDB.On("GetJobNameMerges", mock.Anything).Return([]models.JobNameMerge{
+		{Alias: "make", Canonical: "compile", Status: models.JobNameMergeConfirmed},
+	}, nil)
+
+	jobs := []models.WorkflowJob{
+		{ID: 1, Name: "🚀 build (ubuntu, 18)", Status: models.JobStatusInProgress, StartedAt: now},
+		{ID: 2, Name: "make", Status: models.JobStatusInProgress, StartedAt: now},
+		{ID: 3, Name: "lint", Status: models.JobStatusInProgress, StartedAt: now},
+	}
+	addJobETAs(t.Context(), mockDB, newJobNameCache(mockDB, jobnames.DefaultRules), 7, jobs, now)
+
+	require.NotNil(t, jobs[0].ETA, "renamed by rules")
+	assert.Equal(t, 5, jobs[0].ETA.Samples)
+	require.NotNil(t, jobs[1].ETA, "renamed by a confirmed merge")
+	assert.Equal(t, 8, jobs[1].ETA.Samples)
+	assert.Nil(t, jobs[2].ETA)
+	mockDB.AssertNumberOfCalls(t, "GetJobNameMerges", 1)
+}
+
+func TestAddJobETAs_SkipsLookupWithoutRunningJobs(t *testing.T) {
+	mockDB := &database.MockDatabase{}
+
+	addJobETAs(t.Context(), mockDB, newJobNameCache(mockDB, nil), 7, []models.WorkflowJob{{ID: 1, Status: models.JobStatusQueued}}, time.Now())
+
+	mockDB.AssertNotCalled(t, "GetJobDurationStats", mock.Anything, mock.Anything)
+}
+
+func TestAddJobETAs_DatabaseError(t *testing.T) {
+	now := time.Now()
+	mockDB := &database.MockDatabase{}
+	mockDB.On("GetJobDurationStats", mock.Anything, int64(7)).Return(map[string]models.DurationStats{}, errors.New("db error"))
+
+	jobs := []models.WorkflowJob{{ID: 1, Name: "build", Status: models.JobStatusInProgress, StartedAt: now}}
+	addJobETAs(t.Context(), mockDB, newJobNameCache(mockDB, nil), 7, jobs, now)
+
+	assert.Nil(t, jobs[0].ETA)
+}
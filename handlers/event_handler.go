@@ -5,6 +5,7 @@ import (
 
 	"github.com/gateixeira/live-actions/internal/config"
 	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/internal/notify"
 	"github.com/gateixeira/live-actions/internal/services"
 	"github.com/gateixeira/live-actions/models"
 )
@@ -18,26 +19,34 @@ type EventHandler interface {
 }
 
 type WebhookHandler struct {
+	config          *config.Config
 	db              database.DatabaseInterface
 	handlers        map[string]EventHandler
 	orderingService *services.EventOrderingService
 }
 
-func NewWebhookHandler(config *config.Config, db database.DatabaseInterface) *WebhookHandler {
+func NewWebhookHandler(config *config.Config, db database.DatabaseInterface, notifier *notify.Notifier) *WebhookHandler {
 	wh := &WebhookHandler{
+		config:   config,
 		db:       db,
 		handlers: make(map[string]EventHandler),
 	}
 
 	wh.orderingService = services.NewEventOrderingService(db, wh.processOrderedEvent)
-	wh.orderingService.Start()
 
 	wh.RegisterHandler(NewWorkflowJobHandler(config, db))
-	wh.RegisterHandler(NewWorkflowRunHandler(db))
+	wh.RegisterHandler(NewWorkflowRunHandler(config, db, notifier))
+	wh.RegisterHandler(NewDeploymentReviewHandler(db))
 
 	return wh
 }
 
+// OrderingService returns the service that buffers and orders webhook events
+// before they are processed. It is not started by NewWebhookHandler.
+func (h *WebhookHandler) OrderingService() *services.EventOrderingService {
+	return h.orderingService
+}
+
 func (h *WebhookHandler) RegisterHandler(handler EventHandler) {
 	h.handlers[handler.GetEventType()] = handler
 }
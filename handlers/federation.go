@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/config"
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/internal/federation"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// localInstanceName labels this instance in the federated overview.
+const localInstanceName = "local"
+
+// RequireFederationToken middleware only lets through peers carrying the
+// configured FEDERATION_TOKEN as a bearer token. Serving summaries to peers is
+// disabled when no token is configured.
+func RequireFederationToken(config *config.Config) gin.HandlerFunc {
+	return requireBearerToken(config.Vars.FederationToken, "Federation", "FEDERATION_TOKEN")
+}
+
+// FederationHandler serves this instance's summary to peers and combines the
+// summaries of all configured peers into a single overview.
+type FederationHandler struct {
+	db     database.DatabaseInterface
+	client *federation.Client
+}
+
+func NewFederationHandler(config *config.Config, db database.DatabaseInterface) *FederationHandler {
+	return &FederationHandler{
+		db:     db,
+		client: federation.NewClient(config.Vars.FederationPeers, config.Vars.FederationPeerTokens),
+	}
+}
+
+// localSummary computes the summary of this instance from one snapshot of
+// the database.
+func (h *FederationHandler) localSummary(ctx context.Context) (*models.InstanceSummary, error) {
+	var running, queued int
+	var failures *models.FailureAnalytics
+	err := h.db.ReadSnapshot(ctx, func(db database.DatabaseInterface) error {
+		var err error
+		if running, queued, err = db.GetCurrentJobCounts(ctx); err != nil {
+			return err
+		}
+		failures, err = db.GetFailureSummary(ctx, 24*time.Hour, nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.InstanceSummary{
+		Name:           localInstanceName,
+		RunningJobs:    running,
+		QueuedJobs:     queued,
+		TotalCompleted: failures.TotalCompleted,
+		TotalFailed:    failures.TotalFailed,
+		FailureRate:    failures.FailureRate,
+	}, nil
+}
+
+// GetSummary returns this instance's summary for federated peers.
+func (h *FederationHandler) GetSummary() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		summary, err := h.localSummary(c.Request.Context())
+		if err != nil {
+			logger.Logger.Error("Failed to build federation summary", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve summary"})
+			return
+		}
+
+		c.JSON(http.StatusOK, summary)
+	}
+}
+
+// GetOverview returns the summaries of this instance and every configured
+// peer, plus totals across the instances that responded. Each peer carries its
+// URL so the dashboard can link drill-downs to the owning instance.
+func (h *FederationHandler) GetOverview() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		local, err := h.localSummary(ctx)
+		if err != nil {
+			logger.Logger.Error("Failed to build federation summary", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve overview"})
+			return
+		}
+
+		instances := append([]models.InstanceSummary{*local}, h.client.FetchAll(ctx)...)
+
+		totals := models.InstanceSummary{Name: "total"}
+		for _, instance := range instances {
+			if instance.Error != "" {
+				logger.Logger.Warn("Federation peer unavailable", zap.String("peer", instance.Name), zap.String("error", instance.Error))
+				continue
+			}
+			totals.RunningJobs += instance.RunningJobs
+			totals.QueuedJobs += instance.QueuedJobs
+			totals.TotalCompleted += instance.TotalCompleted
+			totals.TotalFailed += instance.TotalFailed
+		}
+		if totals.TotalCompleted > 0 {
+			totals.FailureRate = float64(totals.TotalFailed) / float64(totals.TotalCompleted) * 100
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"instances": instances,
+			"totals":    totals,
+		})
+	}
+}
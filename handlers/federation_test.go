@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/federation"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFederationHandler_GetSummary(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	testConfig.Vars.FederationToken = "secret"
+	handler := NewFederationHandler(testConfig, mockDB)
+	router.GET(federation.SummaryPath, RequireFederationToken(testConfig), handler.GetSummary())
+
+	mockDB.On("GetCurrentJobCounts", mock.Anything).Return(2, 5, nil)
+	mockDB.On("GetFailureSummary", mock.Anything, 24*time.Hour, []string(nil)).Return(&models.FailureAnalytics{
+		TotalCompleted: 10, TotalFailed: 1, FailureRate: 10,
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", federation.SummaryPath, nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", federation.SummaryPath, nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var summary models.InstanceSummary
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &summary))
+	assert.Equal(t, models.InstanceSummary{
+		Name: "local", RunningJobs: 2, QueuedJobs: 5, TotalCompleted: 10, TotalFailed: 1, FailureRate: 10,
+	}, summary)
+}
+
+func TestFederationHandler_GetOverview(t *testing.T) {
+	peer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, federation.SummaryPath, r.URL.Path)
+		if r.Header.Get("Authorization") != "Bearer eu-secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(models.InstanceSummary{
+			Name: "local", RunningJobs: 3, QueuedJobs: 1, TotalCompleted: 30, TotalFailed: 9,
+		})
+	}))
+	defer peer.Close()
+
+	router, mockDB, testConfig := setupAPITest()
+	testConfig.Vars.FederationPeers = map[string]string{
+		"eu":   peer.URL + "/",
+		"us":   peer.URL,
+		"down": "http://127.0.0.1:1",
+	}
+	testConfig.Vars.FederationPeerTokens = map[string]string{"eu": "eu-secret", "us": "wrong"}
+	handler := NewFederationHandler(testConfig, mockDB)
+	router.GET("/api/federation/overview", handler.GetOverview())
+
+	mockDB.On("GetCurrentJobCounts", mock.Anything).Return(2, 5, nil)
+	mockDB.On("GetFailureSummary", mock.Anything, 24*time.Hour, []string(nil)).Return(&models.FailureAnalytics{
+		TotalCompleted: 10, TotalFailed: 1, FailureRate: 10,
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/federation/overview", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Instances []models.InstanceSummary `json:"instances"`
+		Totals    models.InstanceSummary   `json:"totals"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Instances, 4)
+
+	assert.Equal(t, "local", response.Instances[0].Name)
+	assert.Equal(t, "down", response.Instances[1].Name)
+	assert.NotEmpty(t, response.Instances[1].Error)
+	assert.Equal(t, models.InstanceSummary{
+		Name: "eu", URL: peer.URL, RunningJobs: 3, QueuedJobs: 1, TotalCompleted: 30, TotalFailed: 9,
+	}, response.Instances[2])
+	assert.Equal(t, "us", response.Instances[3].Name)
+	assert.Contains(t, response.Instances[3].Error, "401")
+
+	assert.Equal(t, 5, response.Totals.RunningJobs)
+	assert.Equal(t, 6, response.Totals.QueuedJobs)
+	assert.Equal(t, 40, response.Totals.TotalCompleted)
+	assert.InDelta(t, 25.0, response.Totals.FailureRate, 0.001)
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/utils"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const feedEntryLimit = 50
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID      string    `xml:"id"`
+	Title   string    `xml:"title"`
+	Updated string    `xml:"updated"`
+	Link    *atomLink `xml:"link,omitempty"`
+	Summary string    `xml:"summary"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Link    atomLink    `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+// GetActivityFeed serves an Atom feed of notable workflow activity (failed
+// runs, runs exceeding the long-running threshold and breached SLOs) for feed
+// readers and chat RSS integrations, newest first.
+func (h *APIHandler) GetActivityFeed() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		since := periodToDuration(c.DefaultQuery("period", "week"))
+
+		runs, err := h.db.GetNotableRuns(c.Request.Context(), since, h.config.GetLongRunningThreshold(), h.config.Vars.FeedWorkflowFilter, feedEntryLimit)
+		if err != nil {
+			logger.Logger.Error("Failed to get notable runs for feed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build feed"})
+			return
+		}
+
+		breaches, err := h.db.GetSLOBreaches(c.Request.Context(), since, feedEntryLimit)
+		if err != nil {
+			logger.Logger.Error("Failed to get SLO breaches for feed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build feed"})
+			return
+		}
+		breaches = filterBreaches(breaches, h.config.Vars.FeedWorkflowFilter)
+
+		if err := h.anonymized(&runs); err != nil {
+			logger.Logger.Error("Failed to anonymize feed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build feed"})
+			return
+		}
+		if err := h.anonymized(&breaches); err != nil {
+			logger.Logger.Error("Failed to anonymize feed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build feed"})
+			return
+		}
+
+		now := h.config.Now()
+		feed := atomFeed{
+			ID:      "urn:live-actions:feed",
+			Title:   "Live Actions activity",
+			Updated: now.UTC().Format(time.RFC3339),
+			Link:    atomLink{Href: c.Request.URL.String(), Rel: "self"},
+			Entries: make([]atomEntry, 0, len(runs)+len(breaches)),
+		}
+		for _, n := range runs {
+			feed.Entries = append(feed.Entries, buildFeedEntry(n, now))
+		}
+		for _, b := range breaches {
+			feed.Entries = append(feed.Entries, buildBreachEntry(b))
+		}
+		// RFC 3339 UTC timestamps sort chronologically as strings
+		sort.SliceStable(feed.Entries, func(i, j int) bool { return feed.Entries[i].Updated > feed.Entries[j].Updated })
+		if len(feed.Entries) > feedEntryLimit {
+			feed.Entries = feed.Entries[:feedEntryLimit]
+		}
+
+		out, err := xml.MarshalIndent(feed, "", "  ")
+		if err != nil {
+			logger.Logger.Error("Failed to marshal feed", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build feed"})
+			return
+		}
+
+		c.Header("Cache-Control", "public, max-age=60")
+		c.Data(http.StatusOK, "application/atom+xml; charset=utf-8", append([]byte(xml.Header), out...))
+	}
+}
+
+func buildFeedEntry(n models.NotableRun, now time.Time) atomEntry {
+	run := n.Run
+	repo := utils.RepoFullName(run.HtmlUrl, run.RepositoryName)
+
+	updated := run.UpdatedAt
+	if updated.IsZero() {
+		updated = run.CreatedAt
+	}
+
+	var title, summary string
+	switch n.Kind {
+	case "long_running":
+		title = fmt.Sprintf("[%s] %s running for %s", repo, run.Name, now.Sub(run.RunStartedAt).Round(time.Minute))
+		summary = fmt.Sprintf("%s has been in progress since %s", run.DisplayTitle, run.RunStartedAt.UTC().Format(time.RFC3339))
+	default:
+		title = fmt.Sprintf("[%s] %s failed", repo, run.Name)
+		summary = fmt.Sprintf("%s concluded with %s", run.DisplayTitle, run.Conclusion)
+	}
+
+	return atomEntry{
+		ID:      fmt.Sprintf("urn:live-actions:run:%d:%s", run.ID, n.Kind),
+		Title:   title,
+		Updated: updated.UTC().Format(time.RFC3339),
+		Link:    &atomLink{Href: run.HtmlUrl},
+		Summary: summary,
+	}
+}
+
+// buildBreachEntry describes a breached SLO. It has no link, as the breach
+// spans runs rather than pointing at one.
+func buildBreachEntry(b models.SLOBreach) atomEntry {
+	title := b.Title
+	if b.Repository != "" {
+		title = fmt.Sprintf("[%s] %s", b.Repository, b.Title)
+	}
+	return atomEntry{
+		ID:      fmt.Sprintf("urn:live-actions:slo-breach:%d", b.ID),
+		Title:   title,
+		Updated: b.StartedAt.UTC().Format(time.RFC3339),
+		Summary: b.Message,
+	}
+}
+
+// filterBreaches keeps the workflow SLO breaches whose workflow name contains
+// one of filters (case-insensitive), as FEED_WORKFLOW_FILTER does for runs.
+// Processing lag breaches concern every workflow and are always kept.
+func filterBreaches(breaches []models.SLOBreach, filters []string) []models.SLOBreach {
+	if len(filters) == 0 {
+		return breaches
+	}
+	kept := breaches[:0]
+	for _, b := range breaches {
+		if b.Workflow == "" {
+			kept = append(kept, b)
+			continue
+		}
+		for _, f := range filters {
+			if strings.Contains(strings.ToLower(b.Workflow), strings.ToLower(f)) {
+				kept = append(kept, b)
+				break
+			}
+		}
+	}
+	return kept
+}
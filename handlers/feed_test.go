@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/anonymize"
+	"github.com/gateixeira/live-actions/internal/clock"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetActivityFeed_Success(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	testConfig.Vars.LongRunningThresholdMinutes = 60
+	testConfig.Vars.FeedWorkflowFilter = []string{"deploy"}
+	now := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+	testConfig.Clock = clock.NewFake(now)
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/feed.atom", handler.GetActivityFeed())
+
+	mockDB.On("GetNotableRuns", mock.Anything, 7*24*time.Hour, time.Hour, []string{"deploy"}, feedEntryLimit).Return([]models.NotableRun{
+		{Kind: "failure", Run: models.WorkflowRun{ID: 1, Name: "Deploy", HtmlUrl: "https://github.com/octo/app/actions/runs/1", Conclusion: "failure", UpdatedAt: now}},
+		{Kind: "long_running", Run: models.WorkflowRun{ID: 2, Name: "Deploy", HtmlUrl: "https://github.com/octo/app/actions/runs/2", RunStartedAt: now.Add(-2 * time.Hour)}},
+	}, nil)
+	mockDB.On("GetSLOBreaches", mock.Anything, 7*24*time.Hour, feedEntryLimit).Return([]models.SLOBreach{}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/feed.atom", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/atom+xml; charset=utf-8", w.Header().Get("Content-Type"))
+
+	var feed atomFeed
+	require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &feed))
+	require.Len(t, feed.Entries, 2)
+	assert.Equal(t, "[octo/app] Deploy failed", feed.Entries[0].Title)
+	assert.Equal(t, "urn:live-actions:run:1:failure", feed.Entries[0].ID)
+	assert.Contains(t, feed.Entries[1].Title, "running for 2h0m0s")
+	mockDB.AssertExpectations(t)
+}
+
+func TestGetActivityFeed_Anonymized(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	a := anonymize.New("secret")
+	handler.SetAnonymizer(a)
+	router.GET("/feed.atom", handler.GetActivityFeed())
+
+	mockDB.On("GetNotableRuns", mock.Anything, mock.Anything, mock.Anything, mock.Anything, feedEntryLimit).Return([]models.NotableRun{
+		{Kind: "failure", Run: models.WorkflowRun{ID: 1, Name: "Deploy", DisplayTitle: "Fix billing export", RepositoryName: "app",
+			HtmlUrl: "https://github.com/octo/app/actions/runs/1", Conclusion: "failure"}},
+	}, nil)
+	mockDB.On("GetSLOBreaches", mock.Anything, mock.Anything, feedEntryLimit).Return([]models.SLOBreach{
+		{ID: 4, Kind: "slo_burn_rate", Title: "SLO deploy is burning its error budget", Message: "Deploy in octo/app is spending its error budget.",
+			Repository: "octo/app", Workflow: "Deploy", StartedAt: time.Now()},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/feed.atom", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.NotContains(t, body, "octo")
+	assert.NotContains(t, body, "billing")
+	var feed atomFeed
+	require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &feed))
+	require.Len(t, feed.Entries, 2)
+	assert.Equal(t, "["+a.Repository("octo/app")+"] SLO deploy is burning its error budget", feed.Entries[0].Title)
+	assert.Equal(t, "["+a.Repository("octo/app")+"] Deploy failed", feed.Entries[1].Title)
+	assert.Contains(t, feed.Entries[1].Link.Href, a.Repository("octo/app")+"/actions/runs/1")
+}
+
+func TestGetActivityFeed_SLOBreaches(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	testConfig.Vars.FeedWorkflowFilter = []string{"deploy"}
+	now := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+	testConfig.Clock = clock.NewFake(now)
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/feed.atom", handler.GetActivityFeed())
+
+	mockDB.On("GetNotableRuns", mock.Anything, 24*time.Hour, mock.Anything, mock.Anything, feedEntryLimit).Return([]models.NotableRun{
+		{Kind: "failure", Run: models.WorkflowRun{ID: 1, Name: "Deploy", HtmlUrl: "https://github.com/octo/app/actions/runs/1", Conclusion: "failure", UpdatedAt: now.Add(-2 * time.Hour)}},
+	}, nil)
+	mockDB.On("GetSLOBreaches", mock.Anything, 24*time.Hour, feedEntryLimit).Return([]models.SLOBreach{
+		{ID: 3, Kind: "processing_lag", Title: "Webhook processing is lagging", Message: "Events are processed 5m0s after GitHub sent them.", StartedAt: now.Add(-time.Hour)},
+		{ID: 2, Kind: "slo_burn_rate", Title: "SLO lint is burning its error budget", Repository: "octo/app", Workflow: "Lint", StartedAt: now.Add(-90 * time.Minute)},
+		{ID: 1, Kind: "slo_burn_rate", Title: "SLO deploy is burning its error budget", Message: "Deploy is spending its error budget 14.4x too fast.",
+			Repository: "octo/app", Workflow: "Deploy", StartedAt: now.Add(-3 * time.Hour)},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/feed.atom?period=day", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var feed atomFeed
+	require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &feed))
+	// The lint breach is outside FEED_WORKFLOW_FILTER; entries are newest first
+	require.Len(t, feed.Entries, 3)
+	assert.Equal(t, "urn:live-actions:slo-breach:3", feed.Entries[0].ID)
+	assert.Equal(t, "Webhook processing is lagging", feed.Entries[0].Title)
+	assert.Nil(t, feed.Entries[0].Link)
+	assert.Equal(t, "urn:live-actions:run:1:failure", feed.Entries[1].ID)
+	assert.Equal(t, "[octo/app] SLO deploy is burning its error budget", feed.Entries[2].Title)
+	assert.Equal(t, "Deploy is spending its error budget 14.4x too fast.", feed.Entries[2].Summary)
+	assert.Equal(t, now.Add(-3*time.Hour).Format(time.RFC3339), feed.Entries[2].Updated)
+	mockDB.AssertExpectations(t)
+}
+
+func TestGetActivityFeed_DatabaseError(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/feed.atom", handler.GetActivityFeed())
+
+	mockDB.On("GetNotableRuns", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]models.NotableRun{}, errors.New("db error"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/feed.atom?period=day", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -0,0 +1,345 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/internal/jobnames"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	// topFailingJobs is how many jobs failure analytics report
+	topFailingJobs = 10
+	// maxJobNameCandidates bounds the names compared for fuzzy matches, as
+	// every pair of them is compared
+	maxJobNameCandidates  = 500
+	maxJobNameSuggestions = 50
+	maxJobNameLength      = 255
+	// jobNameCacheTTL bounds how long a cached resolver is used, so merges
+	// reviewed on another instance sharing the database apply here too
+	jobNameCacheTTL = time.Minute
+)
+
+// jobNameResolver groups job names by the configured rules and the confirmed
+// merges stored in db.
+func jobNameResolver(ctx context.Context, db database.DatabaseInterface, rules []string) (*jobnames.Resolver, error) {
+	merges, err := db.GetJobNameMerges(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return jobnames.NewResolver(rules, confirmedMerges(merges)), nil
+}
+
+// jobNamesVersion is bumped whenever job name merges change, invalidating
+// every jobNameCache.
+var jobNamesVersion atomic.Uint64
+
+// invalidateJobNames makes every jobNameCache reload on its next use.
+func invalidateJobNames() {
+	jobNamesVersion.Add(1)
+}
+
+// jobNameCache keeps the resolver built by jobNameResolver, so paths running
+// on every webhook event do not read the merges each time. It reloads after
+// invalidateJobNames or jobNameCacheTTL.
+type jobNameCache struct {
+	db    database.DatabaseInterface
+	rules []string
+
+	mutex    sync.Mutex
+	resolver *jobnames.Resolver
+	version  uint64
+	loadedAt time.Time
+}
+
+func newJobNameCache(db database.DatabaseInterface, rules []string) *jobNameCache {
+	return &jobNameCache{db: db, rules: rules}
+}
+
+// get returns the cached resolver, reloading it when stale at now.
+func (c *jobNameCache) get(ctx context.Context, now time.Time) (*jobnames.Resolver, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	version := jobNamesVersion.Load()
+	if c.resolver != nil && c.version == version && now.Sub(c.loadedAt) < jobNameCacheTTL {
+		return c.resolver, nil
+	}
+	resolver, err := jobNameResolver(ctx, c.db, c.rules)
+	if err != nil {
+		return nil, err
+	}
+	c.resolver, c.version, c.loadedAt = resolver, version, now
+	return resolver, nil
+}
+
+// confirmedMerges maps the aliases of confirmed merges to their canonical name.
+func confirmedMerges(merges []models.JobNameMerge) map[string]string {
+	confirmed := make(map[string]string)
+	for _, m := range merges {
+		if m.Status == models.JobNameMergeConfirmed {
+			confirmed[m.Alias] = m.Canonical
+		}
+	}
+	return confirmed
+}
+
+// mergeFailingJobs adds up jobs whose names resolve alike, reporting them
+// under the name with the most jobs, and returns the limit with the most
+// failures. Jobs without failures only count towards their merged job.
+func mergeFailingJobs(jobs []models.FailingJob, names *jobnames.Resolver, limit int) []models.FailingJob {
+	var order []string
+	groups := make(map[string][]models.FailingJob)
+	for _, j := range jobs {
+		key := names.Key(j.Name)
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], j)
+	}
+
+	merged := []models.FailingJob{}
+	for _, key := range order {
+		members := groups[key]
+		sort.SliceStable(members, func(i, j int) bool { return members[i].Total > members[j].Total })
+		job := members[0]
+		job.Failures, job.Total = 0, 0
+		// Link the name that failed most
+		mostFailures := 0
+		for _, m := range members {
+			job.Failures += m.Failures
+			job.Total += m.Total
+			if m.Name != job.Name {
+				job.MergedNames = append(job.MergedNames, m.Name)
+			}
+			if m.Failures > mostFailures {
+				job.HtmlUrl, mostFailures = m.HtmlUrl, m.Failures
+			}
+		}
+		if job.Failures == 0 {
+			continue
+		}
+		job.FailureRate = 0
+		if job.Total > 0 {
+			job.FailureRate = float64(job.Failures) / float64(job.Total) * 100
+		}
+		merged = append(merged, job)
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].Failures > merged[j].Failures })
+	return merged[:min(limit, len(merged))]
+}
+
+// failureAnalytics returns the failure analytics of db with renamed jobs
+// merged and the top failing ones picked.
+func (h *APIHandler) failureAnalytics(ctx context.Context, db database.DatabaseInterface, since time.Duration, repos []string) (*models.FailureAnalytics, error) {
+	analytics, err := db.GetFailureAnalytics(ctx, since, repos)
+	if err != nil {
+		return nil, err
+	}
+	names, err := jobNameResolver(ctx, db, h.config.Vars.JobNameRules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job name merges: %w", err)
+	}
+	analytics.TopFailingJobs = mergeFailingJobs(analytics.TopFailingJobs, names, topFailingJobs)
+	return analytics, nil
+}
+
+// GetJobNameMerges shows how job names are merged in analytics: the groups
+// of names currently reported as one job, the reviewed decisions, and similar
+// names of jobs completed over the period (default: week) proposed for
+// merging.
+func (h *APIHandler) GetJobNameMerges() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		since := periodToDuration(c.DefaultQuery("period", "week"))
+		ctx := c.Request.Context()
+		repos, ok := h.repoFilter(c)
+		if !ok {
+			return
+		}
+
+		var counts []models.JobNameCount
+		var decisions []models.JobNameMerge
+		err := h.db.ReadSnapshot(ctx, func(db database.DatabaseInterface) error {
+			var err error
+			if counts, err = db.GetJobNameCounts(ctx, since, repos, maxJobNameCandidates); err != nil {
+				return fmt.Errorf("failed to get job name counts: %w", err)
+			}
+			if decisions, err = db.GetJobNameMerges(ctx); err != nil {
+				return fmt.Errorf("failed to get job name merges: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			logger.Logger.Error("Failed to get job name merges", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve job name merges"})
+			return
+		}
+
+		c.JSON(http.StatusOK, jobNameMergeReview(counts, decisions, h.config.Vars.JobNameRules, h.config.Vars.JobNameMatchPercent))
+	}
+}
+
+// jobNameMergeReview groups counts, ordered by jobs, by the rules and
+// confirmed decisions, and proposes fuzzy matches not decided on yet.
+func jobNameMergeReview(counts []models.JobNameCount, decisions []models.JobNameMerge, rules []string, matchPercent int) models.JobNameMergeReview {
+	names := jobnames.NewResolver(rules, confirmedMerges(decisions))
+	review := models.JobNameMergeReview{
+		Rules:        rules,
+		MatchPercent: matchPercent,
+		Groups:       []models.JobNameGroup{},
+		Decisions:    decisions,
+		Suggestions:  []models.JobNameMergeSuggestion{},
+	}
+
+	jobs := make(map[string]int, len(counts))
+	var order []string
+	groups := make(map[string]*models.JobNameGroup)
+	candidates := make([]jobnames.Candidate, 0, len(counts))
+	for _, c := range counts {
+		jobs[c.Name] = c.Jobs
+		candidates = append(candidates, jobnames.Candidate{Name: c.Name, Jobs: c.Jobs})
+
+		key := names.Key(c.Name)
+		group, ok := groups[key]
+		if !ok {
+			// Counts come most jobs first, so the group is named after its busiest name
+			group = &models.JobNameGroup{Name: c.Name}
+			groups[key] = group
+			order = append(order, key)
+		}
+		group.Names = append(group.Names, c.Name)
+		group.Jobs += c.Jobs
+	}
+	for _, key := range order {
+		if len(groups[key].Names) > 1 {
+			review.Groups = append(review.Groups, *groups[key])
+		}
+	}
+
+	decided := make(map[[2]string]bool, len(decisions))
+	for _, d := range decisions {
+		decided[[2]string{d.Alias, d.Canonical}] = true
+		decided[[2]string{d.Canonical, d.Alias}] = true
+	}
+	matches := names.FuzzyMatches(candidates, float64(matchPercent), func(alias, canonical string) bool {
+		return decided[[2]string{alias, canonical}]
+	})
+	for _, m := range matches[:min(maxJobNameSuggestions, len(matches))] {
+		review.Suggestions = append(review.Suggestions, models.JobNameMergeSuggestion{
+			Alias:         m.Alias,
+			Canonical:     m.Canonical,
+			Similarity:    m.Similarity,
+			AliasJobs:     jobs[m.Alias],
+			CanonicalJobs: jobs[m.Canonical],
+		})
+	}
+	return review
+}
+
+type jobNameMergeRequest struct {
+	Alias     string `json:"alias"`
+	Canonical string `json:"canonical"`
+	Status    string `json:"status"`
+}
+
+// SaveJobNameMerge records a reviewed decision: confirming merges alias into
+// canonical in failure analytics and job ETAs, rejecting stops the pair being
+// proposed.
+func (h *APIHandler) SaveJobNameMerge() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req jobNameMergeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		merge := models.JobNameMerge{
+			Alias:     strings.TrimSpace(req.Alias),
+			Canonical: strings.TrimSpace(req.Canonical),
+			Status:    req.Status,
+			DecidedAt: h.config.Now().UTC().Truncate(time.Second),
+		}
+		if merge.Status == "" {
+			merge.Status = models.JobNameMergeConfirmed
+		}
+		if merge.Status != models.JobNameMergeConfirmed && merge.Status != models.JobNameMergeRejected {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "status must be 'confirmed' or 'rejected'"})
+			return
+		}
+		if merge.Alias == "" || merge.Canonical == "" || len(merge.Alias) > maxJobNameLength || len(merge.Canonical) > maxJobNameLength {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "alias and canonical must be between 1 and 255 characters"})
+			return
+		}
+		if merge.Alias == merge.Canonical {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "alias and canonical must differ"})
+			return
+		}
+
+		ctx := c.Request.Context()
+		if merge.Status == models.JobNameMergeConfirmed {
+			decisions, err := h.db.GetJobNameMerges(ctx)
+			if err != nil {
+				logger.Logger.Error("Failed to get job name merges", zap.Error(err))
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save job name merge"})
+				return
+			}
+			if confirmedMerges(decisions)[merge.Canonical] == merge.Alias {
+				c.JSON(http.StatusConflict, gin.H{"error": "canonical is already merged into alias"})
+				return
+			}
+		}
+
+		if err := h.db.SaveJobNameMerge(ctx, merge); err != nil {
+			logger.Logger.Error("Failed to save job name merge", zap.String("alias", merge.Alias), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save job name merge"})
+			return
+		}
+
+		logger.Logger.Info("Job name merge reviewed",
+			zap.String("alias", merge.Alias),
+			zap.String("canonical", merge.Canonical),
+			zap.String("status", merge.Status))
+		invalidateJobNames()
+		SendConfigChanged(ConfigJobNames)
+		c.JSON(http.StatusOK, merge)
+	}
+}
+
+// DeleteJobNameMerge forgets the decision on the alias and canonical query
+// parameters.
+func (h *APIHandler) DeleteJobNameMerge() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		alias, canonical := c.Query("alias"), c.Query("canonical")
+		if alias == "" || canonical == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "alias and canonical are required"})
+			return
+		}
+
+		deleted, err := h.db.DeleteJobNameMerge(c.Request.Context(), alias, canonical)
+		if err != nil {
+			logger.Logger.Error("Failed to delete job name merge", zap.String("alias", alias), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete job name merge"})
+			return
+		}
+		if !deleted {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job name merge not found"})
+			return
+		}
+
+		invalidateJobNames()
+		SendConfigChanged(ConfigJobNames)
+		c.Status(http.StatusNoContent)
+	}
+}
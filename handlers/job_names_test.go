@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/clock"
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/internal/jobnames"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobNameCache(t *testing.T) {
+	mockDB := new(database.MockDatabase)
+	mockDB.On("GetJobNameMerges", mock.Anything).Return([]models.JobNameMerge{
+		{Alias: "make", Canonical: "compile", Status: models.JobNameMergeConfirmed},
+	}, nil)
+	cache := newJobNameCache(mockDB, nil)
+	now := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+
+	names, err := cache.get(t.Context(), now)
+	require.NoError(t, err)
+	assert.Equal(t, names.Key("compile"), names.Key("make"))
+	_, err = cache.get(t.Context(), now.Add(time.Second))
+	require.NoError(t, err)
+	mockDB.AssertNumberOfCalls(t, "GetJobNameMerges", 1)
+
+	invalidateJobNames()
+	_, err = cache.get(t.Context(), now.Add(time.Second))
+	require.NoError(t, err)
+	mockDB.AssertNumberOfCalls(t, "GetJobNameMerges", 2)
+
+	_, err = cache.get(t.Context(), now.Add(time.Second+jobNameCacheTTL))
+	require.NoError(t, err)
+	mockDB.AssertNumberOfCalls(t, "GetJobNameMerges", 3)
+}
+
+func TestMergeFailingJobs(t *testing.T) {
+	names := jobnames.NewResolver(jobnames.DefaultRules, map[string]string{"e2e": "end-to-end"})
+	jobs := []models.FailingJob{
+		{Name: "test (ubuntu, 18)", HtmlUrl: "https://github.com/octo/app/actions/runs/1/job/1", Failures: 4, Total: 10},
+		{Name: "e2e", HtmlUrl: "https://github.com/octo/app/actions/runs/2/job/2", Failures: 3, Total: 5},
+		{Name: "✅ test (18, ubuntu)", HtmlUrl: "https://github.com/octo/app/actions/runs/3/job/3", Failures: 2, Total: 30},
+		{Name: "lint", HtmlUrl: "https://github.com/octo/app/actions/runs/4/job/4", Failures: 1, Total: 2},
+		{Name: "end-to-end", Failures: 0, Total: 15},
+		{Name: "docs", Failures: 0, Total: 9},
+	}
+
+	merged := mergeFailingJobs(jobs, names, 2)
+
+	require.Len(t, merged, 2)
+	assert.Equal(t, models.FailingJob{
+		Name:        "✅ test (18, ubuntu)",
+		HtmlUrl:     "https://github.com/octo/app/actions/runs/1/job/1",
+		Failures:    6,
+		Total:       40,
+		FailureRate: 15,
+		MergedNames: []string{"test (ubuntu, 18)"},
+	}, merged[0], "named after the name with the most jobs")
+	assert.Equal(t, "end-to-end", merged[1].Name, "jobs without failures count towards their merged job")
+	assert.Equal(t, 3, merged[1].Failures)
+	assert.Equal(t, 20, merged[1].Total)
+	assert.Equal(t, []string{"e2e"}, merged[1].MergedNames)
+
+	assert.Len(t, mergeFailingJobs(jobs, nil, 10), 4, "a nil resolver merges nothing and drops jobs without failures")
+}
+
+func TestGetFailureAnalytics_MergesRenamedJobs(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	testConfig.Vars.JobNameRules = jobnames.DefaultRules
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/analytics/failures", handler.GetFailureAnalytics())
+
+	mockDB.On("GetFailureAnalytics", mock.Anything, 24*time.Hour, []string(nil)).Return(&models.FailureAnalytics{
+		TotalCompleted: 20, TotalFailed: 3,
+		TopFailingJobs: []models.FailingJob{
+			{Name: "🚀 deploy", Failures: 2, Total: 4},
+			{Name: "deploy", Failures: 1, Total: 6},
+		},
+	}, nil)
+	mockDB.On("GetFailureTrend", mock.Anything, 24*time.Hour, []string(nil)).Return([]models.FailureTrendPoint{}, nil)
+	mockDB.On("GetJobNameMerges", mock.Anything).Return([]models.JobNameMerge{}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/analytics/failures", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Summary models.FailureAnalytics `json:"summary"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Summary.TopFailingJobs, 1)
+	assert.Equal(t, "deploy", response.Summary.TopFailingJobs[0].Name)
+	assert.Equal(t, 3, response.Summary.TopFailingJobs[0].Failures)
+	assert.Equal(t, 30.0, response.Summary.TopFailingJobs[0].FailureRate)
+}
+
+func TestGetJobNameMerges(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	testConfig.Vars.JobNameRules = jobnames.DefaultRules
+	testConfig.Vars.JobNameMatchPercent = 85
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/job-names/merges", handler.GetJobNameMerges())
+
+	mockDB.On("GetJobNameCounts", mock.Anything, 7*24*time.Hour, []string(nil), maxJobNameCandidates).Return([]models.JobNameCount{
+		{Name: "integration-tests", Jobs: 40},
+		{Name: "deploy", Jobs: 12},
+		{Name: "🚀 deploy", Jobs: 3},
+		{Name: "integration-test", Jobs: 2},
+		{Name: "unit-tests", Jobs: 2},
+		{Name: "unit-test", Jobs: 1},
+	}, nil)
+	mockDB.On("GetJobNameMerges", mock.Anything).Return([]models.JobNameMerge{
+		{Alias: "unit-test", Canonical: "unit-tests", Status: models.JobNameMergeRejected},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/job-names/merges?period=week", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var review models.JobNameMergeReview
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &review))
+	assert.Equal(t, []models.JobNameGroup{{Name: "deploy", Names: []string{"deploy", "🚀 deploy"}, Jobs: 15}}, review.Groups)
+	require.Len(t, review.Suggestions, 1, "rejected pairs are not proposed again")
+	assert.Equal(t, "integration-test", review.Suggestions[0].Alias)
+	assert.Equal(t, "integration-tests", review.Suggestions[0].Canonical)
+	assert.Equal(t, 2, review.Suggestions[0].AliasJobs)
+	assert.Equal(t, 40, review.Suggestions[0].CanonicalJobs)
+	assert.Len(t, review.Decisions, 1)
+}
+
+func TestSaveJobNameMerge(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		existing       []models.JobNameMerge
+		expectSave     bool
+		expectedStatus string
+		expectedCode   int
+	}{
+		{"Confirm by default", `{"alias": "integration-test", "canonical": "integration-tests"}`, nil, true, models.JobNameMergeConfirmed, http.StatusOK},
+		{"Reject", `{"alias": "unit-test", "canonical": "unit-tests", "status": "rejected"}`, nil, true, models.JobNameMergeRejected, http.StatusOK},
+		{"Invalid status", `{"alias": "a", "canonical": "b", "status": "maybe"}`, nil, false, "", http.StatusBadRequest},
+		{"Missing canonical", `{"alias": "a", "canonical": " "}`, nil, false, "", http.StatusBadRequest},
+		{"Same names", `{"alias": "a", "canonical": "a"}`, nil, false, "", http.StatusBadRequest},
+		{"Reverse of a confirmed merge", `{"alias": "b", "canonical": "a"}`,
+			[]models.JobNameMerge{{Alias: "a", Canonical: "b", Status: models.JobNameMergeConfirmed}}, false, "", http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, mockDB, testConfig := setupAPITest()
+			now := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+			testConfig.Clock = clock.NewFake(now)
+			mockDB.On("GetJobNameMerges", mock.Anything).Return(tt.existing, nil).Maybe()
+			if tt.expectSave {
+				mockDB.On("SaveJobNameMerge", mock.Anything, mock.MatchedBy(func(m models.JobNameMerge) bool {
+					return m.Status == tt.expectedStatus && m.DecidedAt.Equal(now)
+				})).Return(nil)
+			}
+			handler := NewAPIHandler(testConfig, mockDB)
+			router.POST("/api/job-names/merges", handler.SaveJobNameMerge())
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/job-names/merges", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeleteJobNameMerge(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.DELETE("/api/job-names/merges", handler.DeleteJobNameMerge())
+
+	mockDB.On("DeleteJobNameMerge", mock.Anything, "e2e (a)", "end-to-end").Return(true, nil)
+	mockDB.On("DeleteJobNameMerge", mock.Anything, "gone", "end-to-end").Return(false, nil)
+	mockDB.On("DeleteJobNameMerge", mock.Anything, "broken", "end-to-end").Return(false, errors.New("db error"))
+
+	for query, code := range map[string]int{
+		"alias=e2e+%28a%29&canonical=end-to-end": http.StatusNoContent,
+		"alias=gone&canonical=end-to-end":        http.StatusNotFound,
+		"alias=broken&canonical=end-to-end":      http.StatusInternalServerError,
+		"alias=e2e":                              http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/api/job-names/merges?"+query, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, code, w.Code, query)
+	}
+}
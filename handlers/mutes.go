@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	maxMuteDuration     = 30 * 24 * time.Hour
+	maxMuteReasonLength = 500
+)
+
+type muteRequest struct {
+	EntityType string `json:"entity_type"`
+	EntityID   int64  `json:"entity_id"`
+	Duration   string `json:"duration"`
+	Reason     string `json:"reason"`
+}
+
+// CreateMute silences a run or job in alerting and failure analytics for a limited time.
+func (h *APIHandler) CreateMute() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req muteRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		if req.EntityType != "run" && req.EntityType != "job" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "entity_type must be 'run' or 'job'"})
+			return
+		}
+		if req.EntityID <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "entity_id is required"})
+			return
+		}
+		duration, err := time.ParseDuration(req.Duration)
+		if err != nil || duration <= 0 || duration > maxMuteDuration {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "duration must be a positive duration such as '4h', up to 720h"})
+			return
+		}
+		reason := strings.TrimSpace(req.Reason)
+		if reason == "" || len(reason) > maxMuteReasonLength {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "reason must be between 1 and 500 characters"})
+			return
+		}
+
+		mute := models.Mute{
+			EntityType: req.EntityType,
+			EntityID:   req.EntityID,
+			Reason:     reason,
+			MutedUntil: h.config.Now().Add(duration).UTC().Truncate(time.Second),
+		}
+		id, err := h.db.CreateMute(c.Request.Context(), mute)
+		if err != nil {
+			logger.Logger.Error("Failed to create mute", zap.String("entity_type", mute.EntityType), zap.Int64("entity_id", mute.EntityID), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create mute"})
+			return
+		}
+		mute.ID = id
+
+		logger.Logger.Info("Muted entity",
+			zap.String("entity_type", mute.EntityType),
+			zap.Int64("entity_id", mute.EntityID),
+			zap.Time("muted_until", mute.MutedUntil),
+			zap.String("reason", mute.Reason))
+
+		SendConfigChanged(ConfigMutes)
+		c.JSON(http.StatusCreated, mute)
+	}
+}
+
+// GetMutes returns the mutes that are currently active.
+func (h *APIHandler) GetMutes() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		mutes, err := h.db.GetActiveMutes(c.Request.Context())
+		if err != nil {
+			logger.Logger.Error("Failed to get mutes", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve mutes"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"mutes": mutes})
+	}
+}
+
+// DeleteMute lifts a mute before it expires.
+func (h *APIHandler) DeleteMute() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mute id"})
+			return
+		}
+
+		deleted, err := h.db.DeleteMute(c.Request.Context(), id)
+		if err != nil {
+			logger.Logger.Error("Failed to delete mute", zap.Int64("id", id), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete mute"})
+			return
+		}
+		if !deleted {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Mute not found"})
+			return
+		}
+
+		SendConfigChanged(ConfigMutes)
+		c.Status(http.StatusNoContent)
+	}
+}
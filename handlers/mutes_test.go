@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/clock"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateMute(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		dbErr          error
+		expectCreate   bool
+		expectedStatus int
+	}{
+		{"Valid run mute", `{"entity_type": "run", "entity_id": 42, "duration": "4h", "reason": "known flaky deploy"}`, nil, true, http.StatusCreated},
+		{"Invalid entity type", `{"entity_type": "repo", "entity_id": 42, "duration": "4h", "reason": "x"}`, nil, false, http.StatusBadRequest},
+		{"Missing entity id", `{"entity_type": "job", "duration": "4h", "reason": "x"}`, nil, false, http.StatusBadRequest},
+		{"Invalid duration", `{"entity_type": "job", "entity_id": 1, "duration": "forever", "reason": "x"}`, nil, false, http.StatusBadRequest},
+		{"Duration too long", `{"entity_type": "job", "entity_id": 1, "duration": "1000h", "reason": "x"}`, nil, false, http.StatusBadRequest},
+		{"Missing reason", `{"entity_type": "job", "entity_id": 1, "duration": "1h", "reason": " "}`, nil, false, http.StatusBadRequest},
+		{"Database error", `{"entity_type": "run", "entity_id": 42, "duration": "4h", "reason": "known flaky deploy"}`, errors.New("db error"), true, http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, mockDB, testConfig := setupAPITest()
+			if tt.expectCreate {
+				mockDB.On("CreateMute", mock.Anything, mock.MatchedBy(func(m models.Mute) bool {
+					return m.EntityType == "run" && m.EntityID == 42 && m.Reason == "known flaky deploy" &&
+						time.Until(m.MutedUntil) > 3*time.Hour && time.Until(m.MutedUntil) <= 4*time.Hour
+				})).Return(int64(3), tt.dbErr)
+			}
+			handler := NewAPIHandler(testConfig, mockDB)
+			router.POST("/api/mutes", handler.CreateMute())
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/mutes", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusCreated {
+				var mute models.Mute
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &mute))
+				assert.Equal(t, int64(3), mute.ID)
+			}
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateMute_UsesConfiguredClock(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	now := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+	testConfig.Clock = clock.NewFake(now)
+	mockDB.On("CreateMute", mock.Anything, mock.MatchedBy(func(m models.Mute) bool {
+		return m.MutedUntil.Equal(now.Add(4 * time.Hour))
+	})).Return(int64(1), nil)
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.POST("/api/mutes", handler.CreateMute())
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/mutes", strings.NewReader(`{"entity_type": "run", "entity_id": 42, "duration": "4h", "reason": "deploy"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockDB.AssertExpectations(t)
+}
+
+func TestGetMutes(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/mutes", handler.GetMutes())
+
+	mockDB.On("GetActiveMutes", mock.Anything).Return([]models.Mute{{ID: 1, EntityType: "job", EntityID: 7, Reason: "flaky"}}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/mutes", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"reason":"flaky"`)
+}
+
+func TestDeleteMute(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.DELETE("/api/mutes/:id", handler.DeleteMute())
+
+	mockDB.On("DeleteMute", mock.Anything, int64(1)).Return(true, nil)
+	mockDB.On("DeleteMute", mock.Anything, int64(2)).Return(false, nil)
+
+	for path, status := range map[string]int{
+		"/api/mutes/1":   http.StatusNoContent,
+		"/api/mutes/2":   http.StatusNotFound,
+		"/api/mutes/abc": http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", path, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code, path)
+	}
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// selfHostedLabel is the label GitHub gives every self-hosted runner.
+const selfHostedLabel = "self-hosted"
+
+// Runner types of a queue attribution.
+const (
+	runnerTypeGitHubHosted = "github-hosted"
+	runnerTypeSelfHosted   = "self-hosted"
+)
+
+// Pool states at a point in a self-hosted job's wait.
+const (
+	poolUnknown = iota
+	poolFree
+	poolSaturated
+)
+
+// GetQueueAttribution splits the queue time of the jobs queued over a period
+// (default: day) between GitHub and self-hosted runner capacity, in total and
+// per pool, so slow pickups are not all blamed on the runner pools.
+func (h *APIHandler) GetQueueAttribution() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		period := c.DefaultQuery("period", "day")
+		since := periodToDuration(period)
+		ctx := c.Request.Context()
+		repos, ok := h.repoFilter(c)
+		if !ok {
+			return
+		}
+
+		now := h.config.Now().UTC()
+		var jobs []models.JobQueueWait
+		var samples []models.LabelCapacitySample
+		err := h.db.ReadSnapshot(ctx, func(db database.DatabaseInterface) error {
+			var err error
+			if jobs, err = db.GetJobQueueWaits(ctx, now.Add(-since), now, repos); err != nil {
+				return fmt.Errorf("failed to get job queue waits: %w", err)
+			}
+			// Reach back far enough to know the pool state when the first job was queued
+			if samples, err = db.GetLabelCapacitySamples(ctx, since+maxSaturationSampleGap, ""); err != nil {
+				return fmt.Errorf("failed to get label capacity samples: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			logger.Logger.Error("Failed to get queue attribution", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve queue attribution"})
+			return
+		}
+
+		total, labels := attributeQueueTime(jobs, samples)
+		c.JSON(http.StatusOK, gin.H{
+			"period": period,
+			"total":  total,
+			"labels": labels,
+		})
+	}
+}
+
+// attributeQueueTime attributes each job's queue time and sums it in total and
+// per pool, the runner type and the first label other than self-hosted,
+// ordered by capacity time and then queue time, most first.
+func attributeQueueTime(jobs []models.JobQueueWait, samples []models.LabelCapacitySample) (models.QueueAttribution, []models.QueueAttribution) {
+	byLabel := make(map[string][]models.LabelCapacitySample)
+	for _, s := range samples {
+		byLabel[s.Label] = append(byLabel[s.Label], s)
+	}
+
+	var total models.QueueAttribution
+	groups := make(map[[2]string]*models.QueueAttribution)
+	for _, job := range jobs {
+		runnerType := runnerTypeGitHubHosted
+		var github, capacity, unattributed time.Duration
+		if slices.Contains(job.Labels, selfHostedLabel) {
+			runnerType = runnerTypeSelfHosted
+			github, capacity, unattributed = splitQueueWait(job, byLabel)
+		} else if wait := job.StartedAt.Sub(job.QueuedAt); wait > 0 {
+			github = wait
+		}
+
+		label := "(none)"
+		for _, l := range job.Labels {
+			if l != selfHostedLabel {
+				label = l
+				break
+			}
+		}
+		key := [2]string{runnerType, label}
+		group, ok := groups[key]
+		if !ok {
+			group = &models.QueueAttribution{Label: label, RunnerType: runnerType}
+			groups[key] = group
+		}
+		for _, a := range []*models.QueueAttribution{&total, group} {
+			a.Jobs++
+			a.GitHubSeconds += github.Seconds()
+			a.CapacitySeconds += capacity.Seconds()
+			a.UnattributedSeconds += unattributed.Seconds()
+			a.QueueSeconds += (github + capacity + unattributed).Seconds()
+		}
+	}
+
+	labels := make([]models.QueueAttribution, 0, len(groups))
+	for _, group := range groups {
+		labels = append(labels, *group)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if labels[i].CapacitySeconds != labels[j].CapacitySeconds {
+			return labels[i].CapacitySeconds > labels[j].CapacitySeconds
+		}
+		if labels[i].QueueSeconds != labels[j].QueueSeconds {
+			return labels[i].QueueSeconds > labels[j].QueueSeconds
+		}
+		if labels[i].Label != labels[j].Label {
+			return labels[i].Label < labels[j].Label
+		}
+		return labels[i].RunnerType < labels[j].RunnerType
+	})
+	return total, labels
+}
+
+// splitQueueWait splits a self-hosted job's wait at every capacity sample of
+// its labels taken while it was queued, and attributes each stretch by the
+// state of the pool at its start.
+func splitQueueWait(job models.JobQueueWait, byLabel map[string][]models.LabelCapacitySample) (github, capacity, unattributed time.Duration) {
+	points := []time.Time{job.QueuedAt}
+	for _, label := range job.Labels {
+		samples := byLabel[label]
+		i := sort.Search(len(samples), func(i int) bool { return samples[i].Timestamp.After(job.QueuedAt) })
+		for ; i < len(samples) && samples[i].Timestamp.Before(job.StartedAt); i++ {
+			points = append(points, samples[i].Timestamp)
+		}
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].Before(points[j]) })
+	points = append(points, job.StartedAt)
+
+	for i := 0; i+1 < len(points); i++ {
+		d := points[i+1].Sub(points[i])
+		if d <= 0 {
+			continue
+		}
+		switch poolState(job.Labels, byLabel, points[i]) {
+		case poolSaturated:
+			capacity += d
+		case poolFree:
+			github += d
+		default:
+			unattributed += d
+		}
+	}
+	return github, capacity, unattributed
+}
+
+// poolState reports whether a job with the given labels could have been given
+// a runner at t. A job needs a runner carrying all of its labels, so it has to
+// wait for capacity while every runner carrying any one of them is busy, in
+// whichever order the labels are listed: samples count a job's demand under
+// each of its labels. Labels without a recent sample of known capacity are not
+// judged.
+func poolState(labels []string, byLabel map[string][]models.LabelCapacitySample, t time.Time) int {
+	state := poolUnknown
+	for _, label := range labels {
+		samples := byLabel[label]
+		i := sort.Search(len(samples), func(i int) bool { return samples[i].Timestamp.After(t) }) - 1
+		if i < 0 || t.Sub(samples[i].Timestamp) > maxSaturationSampleGap || samples[i].Capacity <= 0 {
+			continue
+		}
+		if samples[i].Running >= samples[i].Capacity {
+			return poolSaturated
+		}
+		state = poolFree
+	}
+	return state
+}
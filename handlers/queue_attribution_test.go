@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttributeQueueTime(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	samples := []models.LabelCapacitySample{
+		{Label: "linux", Timestamp: start.Add(-time.Minute), Running: 1, Capacity: 2},
+		{Label: "linux", Timestamp: start.Add(2 * time.Minute), Running: 2, Queued: 1, Capacity: 2},
+		{Label: "linux", Timestamp: start.Add(5 * time.Minute), Running: 1, Capacity: 2},
+	}
+	jobs := []models.JobQueueWait{
+		// Waited two minutes for a free runner, then three while the pool was full
+		{JobID: 1, Labels: []string{"self-hosted", "linux"}, QueuedAt: start, StartedAt: start.Add(6 * time.Minute)},
+		{JobID: 2, Labels: []string{"ubuntu-latest"}, QueuedAt: start, StartedAt: start.Add(time.Minute)},
+		// No capacity is known for gpu runners
+		{JobID: 3, Labels: []string{"self-hosted", "gpu"}, QueuedAt: start, StartedAt: start.Add(4 * time.Minute)},
+		// The last linux sample is too old to judge by
+		{JobID: 4, Labels: []string{"self-hosted", "linux"}, QueuedAt: start.Add(30 * time.Minute), StartedAt: start.Add(31 * time.Minute)},
+	}
+
+	total, labels := attributeQueueTime(jobs, samples)
+
+	assert.Equal(t, models.QueueAttribution{
+		Jobs: 4, QueueSeconds: 720, GitHubSeconds: 240, CapacitySeconds: 180, UnattributedSeconds: 300,
+	}, total)
+	assert.Equal(t, []models.QueueAttribution{
+		{Label: "linux", RunnerType: "self-hosted", Jobs: 2, QueueSeconds: 420, GitHubSeconds: 180, CapacitySeconds: 180, UnattributedSeconds: 60},
+		{Label: "gpu", RunnerType: "self-hosted", Jobs: 1, QueueSeconds: 240, UnattributedSeconds: 240},
+		{Label: "ubuntu-latest", RunnerType: "github-hosted", Jobs: 1, QueueSeconds: 60, GitHubSeconds: 60},
+	}, labels)
+}
+
+func TestAttributeQueueTime_SaturatedLaterLabel(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	// Demand counts a job under each of its labels, so the gpu pool is full
+	// although the jobs list it after linux
+	samples := []models.LabelCapacitySample{
+		{Label: "self-hosted", Timestamp: start.Add(-time.Minute), Running: 3, Capacity: 10},
+		{Label: "linux", Timestamp: start.Add(-time.Minute), Running: 3, Capacity: 8},
+		{Label: "gpu", Timestamp: start.Add(-time.Minute), Running: 2, Queued: 1, Capacity: 2},
+	}
+	jobs := []models.JobQueueWait{
+		{JobID: 1, Labels: []string{"self-hosted", "linux", "gpu"}, QueuedAt: start, StartedAt: start.Add(3 * time.Minute)},
+	}
+
+	total, labels := attributeQueueTime(jobs, samples)
+
+	assert.Equal(t, models.QueueAttribution{Jobs: 1, QueueSeconds: 180, CapacitySeconds: 180}, total)
+	assert.Equal(t, []models.QueueAttribution{
+		{Label: "linux", RunnerType: "self-hosted", Jobs: 1, QueueSeconds: 180, CapacitySeconds: 180},
+	}, labels)
+}
+
+func TestGetQueueAttribution(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/analytics/queue-attribution", handler.GetQueueAttribution())
+
+	now := time.Now().UTC()
+	mockDB.On("GetJobQueueWaits", mock.Anything, mock.Anything, mock.Anything, []string{"app"}).Return([]models.JobQueueWait{
+		{JobID: 1, Labels: []string{"ubuntu-latest"}, QueuedAt: now.Add(-time.Hour), StartedAt: now.Add(-time.Hour + 30*time.Second)},
+	}, nil)
+	mockDB.On("GetLabelCapacitySamples", mock.Anything, periodToDuration("day")+maxSaturationSampleGap, "").Return([]models.LabelCapacitySample{}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/analytics/queue-attribution?repo=app", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Period string                    `json:"period"`
+		Total  models.QueueAttribution   `json:"total"`
+		Labels []models.QueueAttribution `json:"labels"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "day", response.Period)
+	assert.Equal(t, 30.0, response.Total.GitHubSeconds)
+	require.Len(t, response.Labels, 1)
+	assert.Equal(t, "github-hosted", response.Labels[0].RunnerType)
+	mockDB.AssertExpectations(t)
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetQueueLeaderboard ranks runner labels by p90 queue time over a period
+// (default: week) and compares each with the period before it.
+func (h *APIHandler) GetQueueLeaderboard() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		period := c.DefaultQuery("period", "week")
+		since := periodToDuration(period)
+		ctx := c.Request.Context()
+		repos, ok := h.repoFilter(c)
+		if !ok {
+			return
+		}
+
+		now := h.config.Now().UTC()
+		var current, previous []models.LabelQueueStats
+		err := h.db.ReadSnapshot(ctx, func(db database.DatabaseInterface) error {
+			var err error
+			if current, err = db.GetLabelQueueStats(ctx, now.Add(-since), now, repos); err != nil {
+				return fmt.Errorf("failed to get label queue stats: %w", err)
+			}
+			if previous, err = db.GetLabelQueueStats(ctx, now.Add(-2*since), now.Add(-since), repos); err != nil {
+				return fmt.Errorf("failed to get previous label queue stats: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			logger.Logger.Error("Failed to get queue leaderboard", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve queue leaderboard"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"period": period,
+			"labels": queueLeaderboard(current, previous),
+		})
+	}
+}
+
+// sortByP90 orders stats by descending p90 queue time, breaking ties by job
+// count and then label so ranks are stable.
+func sortByP90(stats []models.LabelQueueStats) {
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].P90QueueSeconds != stats[j].P90QueueSeconds {
+			return stats[i].P90QueueSeconds > stats[j].P90QueueSeconds
+		}
+		if stats[i].Jobs != stats[j].Jobs {
+			return stats[i].Jobs > stats[j].Jobs
+		}
+		return stats[i].Label < stats[j].Label
+	})
+}
+
+// queueLeaderboard ranks the current period's labels and attaches each
+// label's rank and p90 from the previous period.
+func queueLeaderboard(current, previous []models.LabelQueueStats) []models.QueueLeaderboardEntry {
+	sortByP90(previous)
+	previousByLabel := make(map[string]int, len(previous))
+	for i, s := range previous {
+		previousByLabel[s.Label] = i
+	}
+
+	sortByP90(current)
+	entries := make([]models.QueueLeaderboardEntry, 0, len(current))
+	for i, s := range current {
+		entry := models.QueueLeaderboardEntry{LabelQueueStats: s, Rank: i + 1}
+		if j, ok := previousByLabel[s.Label]; ok {
+			rank := j + 1
+			p90 := previous[j].P90QueueSeconds
+			delta := s.P90QueueSeconds - p90
+			entry.PreviousRank = &rank
+			entry.PreviousJobs = previous[j].Jobs
+			entry.PreviousP90QueueSeconds = &p90
+			entry.P90DeltaSeconds = &delta
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetQueueLeaderboard(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/analytics/queue-leaderboard", handler.GetQueueLeaderboard())
+
+	// The current window ends now; the previous one ends where it starts
+	isCurrent := mock.MatchedBy(func(to time.Time) bool { return time.Since(to) < time.Minute })
+	isPrevious := mock.MatchedBy(func(to time.Time) bool { return time.Since(to) > 6*24*time.Hour })
+	mockDB.On("GetLabelQueueStats", mock.Anything, mock.Anything, isCurrent, []string{"app"}).Return([]models.LabelQueueStats{
+		{Label: "ubuntu-latest", Jobs: 120, P50QueueSeconds: 4, P90QueueSeconds: 12, MaxQueueSeconds: 40},
+		{Label: "gpu", Jobs: 8, P50QueueSeconds: 60, P90QueueSeconds: 900, MaxQueueSeconds: 1200},
+	}, nil)
+	mockDB.On("GetLabelQueueStats", mock.Anything, mock.Anything, isPrevious, []string{"app"}).Return([]models.LabelQueueStats{
+		{Label: "gpu", Jobs: 6, P90QueueSeconds: 300},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/analytics/queue-leaderboard?repo=app", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Period string                         `json:"period"`
+		Labels []models.QueueLeaderboardEntry `json:"labels"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "week", response.Period)
+	require.Len(t, response.Labels, 2)
+
+	gpu := response.Labels[0]
+	assert.Equal(t, "gpu", gpu.Label)
+	assert.Equal(t, 1, gpu.Rank)
+	require.NotNil(t, gpu.PreviousRank)
+	assert.Equal(t, 1, *gpu.PreviousRank)
+	assert.Equal(t, 6, gpu.PreviousJobs)
+	require.NotNil(t, gpu.P90DeltaSeconds)
+	assert.Equal(t, 600.0, *gpu.P90DeltaSeconds)
+
+	ubuntu := response.Labels[1]
+	assert.Equal(t, "ubuntu-latest", ubuntu.Label)
+	assert.Equal(t, 2, ubuntu.Rank)
+	assert.Nil(t, ubuntu.PreviousRank)
+	assert.Nil(t, ubuntu.P90DeltaSeconds)
+}
+
+func TestGetQueueLeaderboard_DatabaseError(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/analytics/queue-leaderboard", handler.GetQueueLeaderboard())
+
+	mockDB.On("GetLabelQueueStats", mock.Anything, mock.Anything, mock.Anything, []string(nil)).Return([]models.LabelQueueStats{}, errors.New("db error"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/analytics/queue-leaderboard", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestQueueLeaderboard_StableTies(t *testing.T) {
+	entries := queueLeaderboard([]models.LabelQueueStats{
+		{Label: "b", Jobs: 5, P90QueueSeconds: 10},
+		{Label: "a", Jobs: 5, P90QueueSeconds: 10},
+		{Label: "c", Jobs: 9, P90QueueSeconds: 10},
+	}, nil)
+
+	require.Len(t, entries, 3)
+	assert.Equal(t, []string{"c", "a", "b"}, []string{entries[0].Label, entries[1].Label, entries[2].Label})
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gateixeira/live-actions/internal/config"
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/internal/services"
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Readiness reports whether the instance should receive traffic: its
+// migrations are complete, the database answers with a schema at least as
+// new as this build expects and no background service has failed. The
+// migration and service states are included either way, so a rollout can
+// tell a replica waiting for migrations from a failed one. A nil db only
+// reports the migration state, for use before the database is open; a nil
+// serviceHealth reports no services.
+func Readiness(db database.DatabaseInterface, migrations func() database.MigrationState, serviceHealth func() []services.ServiceHealth) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		state := migrations()
+		response := gin.H{"status": "not_ready", "migrations": state}
+		if serviceHealth != nil {
+			response["services"] = serviceHealth()
+		}
+		if state.State != database.MigrationComplete || db == nil {
+			c.JSON(http.StatusServiceUnavailable, response)
+			return
+		}
+
+		version, err := db.GetSchemaVersion(c.Request.Context())
+		if err != nil {
+			logger.Logger.Error("Readiness check failed to query the database", zap.Error(err))
+			response["error"] = "database unavailable"
+			c.JSON(http.StatusServiceUnavailable, response)
+			return
+		}
+		state.Version = version
+		response["migrations"] = state
+		if version < state.Latest {
+			response["error"] = "database schema is older than expected"
+			c.JSON(http.StatusServiceUnavailable, response)
+			return
+		}
+
+		if serviceHealth != nil {
+			for _, service := range response["services"].([]services.ServiceHealth) {
+				if service.State == services.ServiceFailed {
+					response["error"] = "service " + service.Name + " failed"
+					c.JSON(http.StatusServiceUnavailable, response)
+					return
+				}
+			}
+		}
+
+		response["status"] = "ready"
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// Reasons the traffic gate holds.
+const (
+	gateMigrations      = "migrations_incomplete"
+	gateDatabase        = "database_unavailable"
+	gateSchemaMismatch  = "schema_mismatch"
+	gateServiceFailed   = "service_failed"
+	gateEventBacklog    = "event_backlog"
+	gateEventBacklogAge = "event_backlog_age"
+)
+
+// gateReason explains why the traffic gate holds.
+type gateReason struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ReadyForTraffic is a deployment gate for blue/green cutovers: ready is true
+// only when this instance passes its readiness checks, the database schema is
+// exactly the version this build expects, and the webhook event backlog is
+// within READY_MAX_PENDING_EVENTS and READY_MAX_PENDING_AGE_SECONDS. Every
+// failed check is listed in reasons. Unlike Readiness it also holds while a
+// newer build's migrations are applied, as this build would be serving a
+// schema it does not know.
+func ReadyForTraffic(cfg *config.Config, db database.DatabaseInterface, migrations func() database.MigrationState, serviceHealth func() []services.ServiceHealth) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		state := migrations()
+		reasons := []gateReason{}
+		response := gin.H{"migrations": state}
+
+		if state.State != database.MigrationComplete {
+			reasons = append(reasons, gateReason{gateMigrations, "migrations are " + state.State})
+		}
+		if serviceHealth != nil {
+			health := serviceHealth()
+			response["services"] = health
+			for _, service := range health {
+				if service.State == services.ServiceFailed {
+					reasons = append(reasons, gateReason{gateServiceFailed, "service " + service.Name + " failed"})
+				}
+			}
+		}
+
+		if db == nil {
+			reasons = append(reasons, gateReason{gateDatabase, "database is not open"})
+		} else if version, err := db.GetSchemaVersion(c.Request.Context()); err != nil {
+			logger.Logger.Error("Traffic gate failed to query the database", zap.Error(err))
+			reasons = append(reasons, gateReason{gateDatabase, "database unavailable"})
+		} else {
+			response["schema"] = gin.H{"version": version, "expected": state.Latest}
+			if version != state.Latest {
+				reasons = append(reasons, gateReason{gateSchemaMismatch,
+					fmt.Sprintf("database schema version %d does not match the version %d this build expects", version, state.Latest)})
+			}
+
+			stats, err := db.GetProcessingLagStats(c.Request.Context(), services.ProcessingLagWindow)
+			if err != nil {
+				logger.Logger.Error("Traffic gate failed to get the event backlog", zap.Error(err))
+				reasons = append(reasons, gateReason{gateDatabase, "database unavailable"})
+			} else {
+				maxAge := cfg.GetReadyMaxPendingAge()
+				response["pending_events"] = gin.H{
+					"count":           stats.Pending,
+					"oldest_seconds":  stats.OldestPendingSeconds,
+					"max_count":       cfg.Vars.ReadyMaxPendingEvents,
+					"max_age_seconds": int(maxAge.Seconds()),
+				}
+				if stats.Pending > cfg.Vars.ReadyMaxPendingEvents {
+					reasons = append(reasons, gateReason{gateEventBacklog,
+						fmt.Sprintf("%d webhook events are pending, over %d", stats.Pending, cfg.Vars.ReadyMaxPendingEvents)})
+				}
+				if stats.OldestPendingSeconds > maxAge.Seconds() {
+					reasons = append(reasons, gateReason{gateEventBacklogAge,
+						fmt.Sprintf("the oldest pending webhook event has waited %.0fs, over %s", stats.OldestPendingSeconds, maxAge)})
+				}
+			}
+		}
+
+		response["ready"] = len(reasons) == 0
+		response["reasons"] = reasons
+		status := http.StatusOK
+		if len(reasons) > 0 {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, response)
+	}
+}
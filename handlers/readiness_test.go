@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/internal/services"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadiness(t *testing.T) {
+	complete := database.MigrationState{State: database.MigrationComplete, Version: 18, Latest: 18}
+	tests := []struct {
+		name       string
+		state      database.MigrationState
+		nilDB      bool
+		version    int
+		versionErr error
+		wantStatus int
+		wantState  string
+	}{
+		{"migrations complete", complete, false, 18, nil, http.StatusOK, "ready"},
+		{"schema migrated ahead by a newer instance", complete, false, 19, nil, http.StatusOK, "ready"},
+		{"waiting for another instance", database.MigrationState{State: database.MigrationWaiting, Version: 17, Latest: 18}, false, 0, nil, http.StatusServiceUnavailable, "not_ready"},
+		{"migrations failed", database.MigrationState{State: database.MigrationFailed, Error: "boom"}, false, 0, nil, http.StatusServiceUnavailable, "not_ready"},
+		{"database not open yet", complete, true, 0, nil, http.StatusServiceUnavailable, "not_ready"},
+		{"database unavailable", complete, false, 0, errors.New("disk I/O error"), http.StatusServiceUnavailable, "not_ready"},
+		{"schema older than expected", complete, false, 12, nil, http.StatusServiceUnavailable, "not_ready"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, mockDB, _ := setupAPITest()
+			mockDB.On("GetSchemaVersion", mock.Anything).Return(tt.version, tt.versionErr)
+			var db database.DatabaseInterface = mockDB
+			if tt.nilDB {
+				db = nil
+			}
+			router.GET("/readyz", Readiness(db, func() database.MigrationState { return tt.state }, nil))
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/readyz", nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			var response struct {
+				Status     string                  `json:"status"`
+				Migrations database.MigrationState `json:"migrations"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.wantState, response.Status)
+			assert.Equal(t, tt.state.State, response.Migrations.State)
+		})
+	}
+}
+
+func TestReadiness_FailedService(t *testing.T) {
+	router, mockDB, _ := setupAPITest()
+	mockDB.On("GetSchemaVersion", mock.Anything).Return(18, nil)
+	migrations := func() database.MigrationState {
+		return database.MigrationState{State: database.MigrationComplete, Version: 18, Latest: 18}
+	}
+	health := []services.ServiceHealth{{Name: "cleanup", State: services.ServiceRunning}}
+	router.GET("/readyz", Readiness(mockDB, migrations, func() []services.ServiceHealth { return health }))
+
+	get := func() (int, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/readyz", nil)
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w.Code, response
+	}
+
+	code, response := get()
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, response["services"], 1)
+
+	health[0] = services.ServiceHealth{Name: "cleanup", State: services.ServiceFailed, Error: "panic: boom"}
+	code, response = get()
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "service cleanup failed", response["error"])
+}
+
+func TestReadyForTraffic(t *testing.T) {
+	complete := database.MigrationState{State: database.MigrationComplete, Version: 18, Latest: 18}
+	healthy := &models.ProcessingLagStats{Pending: 3, OldestPendingSeconds: 5}
+	tests := []struct {
+		name        string
+		state       database.MigrationState
+		version     int
+		lag         *models.ProcessingLagStats
+		services    []services.ServiceHealth
+		wantReasons []string
+	}{
+		{"ready", complete, 18, healthy, nil, []string{}},
+		{"migrations running", database.MigrationState{State: database.MigrationRunning, Latest: 18}, 17, healthy, nil,
+			[]string{gateMigrations, gateSchemaMismatch}},
+		{"schema migrated ahead by a newer build", complete, 19, healthy, nil, []string{gateSchemaMismatch}},
+		{"backlog over both thresholds", complete, 18, &models.ProcessingLagStats{Pending: 101, OldestPendingSeconds: 121}, nil,
+			[]string{gateEventBacklog, gateEventBacklogAge}},
+		{"failed service", complete, 18, healthy, []services.ServiceHealth{{Name: "cleanup", State: services.ServiceFailed}},
+			[]string{gateServiceFailed}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, mockDB, cfg := setupAPITest()
+			cfg.Vars.ReadyMaxPendingEvents = 100
+			cfg.Vars.ReadyMaxPendingAgeSeconds = 120
+			mockDB.On("GetSchemaVersion", mock.Anything).Return(tt.version, nil)
+			mockDB.On("GetProcessingLagStats", mock.Anything, services.ProcessingLagWindow).Return(tt.lag, nil)
+			router.GET("/ready", ReadyForTraffic(cfg, mockDB,
+				func() database.MigrationState { return tt.state },
+				func() []services.ServiceHealth { return tt.services }))
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/ready", nil)
+			router.ServeHTTP(w, req)
+
+			var response struct {
+				Ready   bool         `json:"ready"`
+				Reasons []gateReason `json:"reasons"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			codes := []string{}
+			for _, reason := range response.Reasons {
+				codes = append(codes, reason.Code)
+			}
+			assert.Equal(t, tt.wantReasons, codes)
+			assert.Equal(t, len(tt.wantReasons) == 0, response.Ready)
+			if response.Ready {
+				assert.Equal(t, http.StatusOK, w.Code)
+			} else {
+				assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+			}
+		})
+	}
+}
+
+func TestReadyForTraffic_DatabaseUnavailable(t *testing.T) {
+	router, mockDB, cfg := setupAPITest()
+	mockDB.On("GetSchemaVersion", mock.Anything).Return(0, errors.New("disk I/O error"))
+	migrations := func() database.MigrationState {
+		return database.MigrationState{State: database.MigrationComplete, Version: 18, Latest: 18}
+	}
+	router.GET("/ready", ReadyForTraffic(cfg, mockDB, migrations, nil))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/ready", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), gateDatabase)
+	mockDB.AssertNotCalled(t, "GetProcessingLagStats", mock.Anything, mock.Anything)
+}
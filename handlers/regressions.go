@@ -0,0 +1,274 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/internal/notify"
+	"github.com/gateixeira/live-actions/internal/services"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	// regressionWindow is how many preceding successful runs form a workflow's baseline.
+	regressionWindow = 20
+	// minRegressionSamples is the smallest baseline a run is compared against.
+	minRegressionSamples = 5
+	// minRegressionIncreaseSeconds ignores relative jumps in very short workflows.
+	minRegressionIncreaseSeconds = 60
+	// regressionBaselinePeriod is how far back baseline runs are loaded.
+	regressionBaselinePeriod = 14 * 24 * time.Hour
+	// workflowChangeWindow is how many runs on either side of a workflow file
+	// change are compared.
+	workflowChangeWindow = 20
+	// minWorkflowChangeSamples is the fewest runs needed on either side of a
+	// workflow file change to compare them.
+	minWorkflowChangeSamples = 3
+)
+
+// GetDurationRegressions returns successful runs that took significantly
+// longer than the trailing median of their workflow, and workflow file
+// changes after which the workflow started failing more often.
+func (h *APIHandler) GetDurationRegressions() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		period := c.DefaultQuery("period", "week")
+		since := periodToDuration(period)
+		ctx := c.Request.Context()
+		repos, ok := h.repoFilter(c)
+		if !ok {
+			return
+		}
+
+		var durations []models.RunDuration
+		var outcomes []models.RunOutcome
+		var changes []models.WorkflowFileChange
+		err := h.db.ReadSnapshot(ctx, func(db database.DatabaseInterface) error {
+			var err error
+			if durations, err = db.GetRunDurations(ctx, since+regressionBaselinePeriod, repos); err != nil {
+				return fmt.Errorf("failed to get run durations: %w", err)
+			}
+			// Changes before the period bound the runs compared with the first change in it
+			if changes, err = db.GetWorkflowFileChanges(ctx, since+regressionBaselinePeriod, repos); err != nil {
+				return fmt.Errorf("failed to get workflow file changes: %w", err)
+			}
+			if len(changes) == 0 {
+				return nil
+			}
+			if outcomes, err = db.GetRunOutcomes(ctx, since+regressionBaselinePeriod, repos); err != nil {
+				return fmt.Errorf("failed to get run outcomes: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			logger.Logger.Error("Failed to get regressions", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve duration regressions"})
+			return
+		}
+
+		cutoff := h.config.Now().Add(-since)
+		threshold := h.config.Vars.RegressionThresholdPercent
+		shift := h.config.Vars.WorkflowChangeShiftPercent
+		c.JSON(http.StatusOK, gin.H{
+			"regressions":              findRegressions(durations, cutoff, threshold),
+			"threshold_percent":        threshold,
+			"workflow_changes":         findWorkflowChangeCorrelations(outcomes, changes, cutoff, shift),
+			"workflow_changes_enabled": h.config.WorkflowChangesEnabled(),
+			"change_shift_percent":     shift,
+		})
+	}
+}
+
+// findRegressions walks each workflow's runs in completion order and flags
+// those completed at or after cutoff whose duration exceeds the median of the
+// preceding runs by more than thresholdPercent. Results are newest first.
+func findRegressions(durations []models.RunDuration, cutoff time.Time, thresholdPercent int) []models.DurationRegression {
+	history := make(map[string][]float64)
+	regressions := []models.DurationRegression{}
+
+	for _, d := range durations {
+		key := d.Repository + "/" + d.Name
+		baseline := history[key]
+
+		if !d.CompletedAt.Before(cutoff) {
+			if r := detectRegression(d, baseline, thresholdPercent); r != nil {
+				regressions = append(regressions, *r)
+			}
+		}
+
+		baseline = append(baseline, d.Seconds)
+		if len(baseline) > regressionWindow {
+			baseline = baseline[1:]
+		}
+		history[key] = baseline
+	}
+
+	sort.SliceStable(regressions, func(i, j int) bool {
+		return regressions[i].CompletedAt.After(regressions[j].CompletedAt)
+	})
+	return regressions
+}
+
+func detectRegression(d models.RunDuration, baseline []float64, thresholdPercent int) *models.DurationRegression {
+	if len(baseline) < minRegressionSamples {
+		return nil
+	}
+
+	median := medianOf(baseline)
+	increase := d.Seconds - median
+	if median <= 0 || increase < minRegressionIncreaseSeconds {
+		return nil
+	}
+
+	increasePercent := increase / median * 100
+	if increasePercent < float64(thresholdPercent) {
+		return nil
+	}
+
+	return &models.DurationRegression{
+		RunID:           d.RunID,
+		Name:            d.Name,
+		Repository:      d.Repository,
+		HtmlUrl:         d.HtmlUrl,
+		DisplayTitle:    d.DisplayTitle,
+		CompletedAt:     d.CompletedAt,
+		DurationSeconds: d.Seconds,
+		BaselineSeconds: median,
+		IncreasePercent: increasePercent,
+		Samples:         len(baseline),
+	}
+}
+
+// findWorkflowChangeCorrelations compares the runs of each workflow file on
+// either side of every change to it committed at or after cutoff: those since
+// the previous change and those until the next one, up to
+// workflowChangeWindow each way. Changes after which the failure rate rose by
+// at least shiftPercent points are returned, newest first. Cancelled and
+// skipped runs are not counted.
+func findWorkflowChangeCorrelations(outcomes []models.RunOutcome, changes []models.WorkflowFileChange, cutoff time.Time, shiftPercent int) []models.WorkflowChangeCorrelation {
+	runs := make(map[string][]models.RunOutcome)
+	for _, run := range outcomes {
+		if run.Conclusion == "success" || services.FailedConclusion(run.Conclusion) {
+			key := run.Repository + "/" + run.WorkflowPath
+			runs[key] = append(runs[key], run)
+		}
+	}
+	fileChanges := make(map[string][]models.WorkflowFileChange)
+	for _, change := range changes {
+		key := change.Repository + "/" + change.Path
+		fileChanges[key] = append(fileChanges[key], change)
+	}
+
+	correlations := []models.WorkflowChangeCorrelation{}
+	for key, list := range fileChanges {
+		for i, change := range list {
+			if change.CommittedAt.Before(cutoff) {
+				continue
+			}
+
+			var before, after []models.RunOutcome
+			for _, run := range runs[key] {
+				if run.CreatedAt.Before(change.CommittedAt) {
+					if i == 0 || !run.CreatedAt.Before(list[i-1].CommittedAt) {
+						before = append(before, run)
+					}
+				} else if i+1 == len(list) || run.CreatedAt.Before(list[i+1].CommittedAt) {
+					after = append(after, run)
+				}
+			}
+			before = before[max(len(before)-workflowChangeWindow, 0):]
+			after = after[:min(len(after), workflowChangeWindow)]
+			if len(before) < minWorkflowChangeSamples || len(after) < minWorkflowChangeSamples {
+				continue
+			}
+
+			rateBefore, _ := failureRate(before)
+			rateAfter, firstFailed := failureRate(after)
+			if rateAfter-rateBefore < float64(shiftPercent) {
+				continue
+			}
+			correlations = append(correlations, models.WorkflowChangeCorrelation{
+				Repository:        change.Repository,
+				Workflow:          after[len(after)-1].Name,
+				Path:              change.Path,
+				Change:            change,
+				RunsBefore:        len(before),
+				RunsAfter:         len(after),
+				FailureRateBefore: rateBefore,
+				FailureRateAfter:  rateAfter,
+				FirstFailedRunID:  firstFailed.RunID,
+				FirstFailedRunUrl: firstFailed.HtmlUrl,
+			})
+		}
+	}
+
+	sort.Slice(correlations, func(i, j int) bool {
+		a, b := correlations[i].Change, correlations[j].Change
+		if !a.CommittedAt.Equal(b.CommittedAt) {
+			return a.CommittedAt.After(b.CommittedAt)
+		}
+		if a.Repository != b.Repository {
+			return a.Repository < b.Repository
+		}
+		return a.Path < b.Path
+	})
+	return correlations
+}
+
+// failureRate returns the percentage of runs that failed and the first of them.
+func failureRate(runs []models.RunOutcome) (float64, models.RunOutcome) {
+	var first models.RunOutcome
+	failed := 0
+	for _, run := range runs {
+		if services.FailedConclusion(run.Conclusion) {
+			if failed == 0 {
+				first = run
+			}
+			failed++
+		}
+	}
+	return float64(failed) / float64(len(runs)) * 100, first
+}
+
+func medianOf(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// checkDurationRegression alerts when a just-completed run is a duration
+// regression for its workflow.
+func checkDurationRegression(ctx context.Context, db database.DatabaseInterface, notifier *notify.Notifier, run models.WorkflowRun, thresholdPercent int) {
+	durations, err := db.GetRunDurations(ctx, regressionBaselinePeriod, []string{run.RepositoryName})
+	if err != nil {
+		logger.Logger.Warn("Failed to check run for duration regression", zap.Int64("run_id", run.ID), zap.Error(err))
+		return
+	}
+
+	for _, r := range findRegressions(durations, run.UpdatedAt, thresholdPercent) {
+		if r.RunID != run.ID {
+			continue
+		}
+		notifier.Notify(ctx, models.Alert{
+			Type:  "duration_regression",
+			Title: fmt.Sprintf("%s is %.0f%% slower than usual", r.Name, r.IncreasePercent),
+			Message: fmt.Sprintf("Run %d of %s in %s took %s against a median of %s over the previous %d runs",
+				r.RunID, r.Name, r.Repository, formatSeconds(r.DurationSeconds), formatSeconds(r.BaselineSeconds), r.Samples),
+			Repository: r.Repository,
+			RunID:      r.RunID,
+			HtmlUrl:    r.HtmlUrl,
+			Data:       r,
+		})
+		return
+	}
+}
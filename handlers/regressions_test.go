@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/config"
+	"github.com/gateixeira/live-actions/internal/notify"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// runDurations builds a workflow's run history, one run per hour ending at end.
+func runDurations(name string, end time.Time, seconds ...float64) []models.RunDuration {
+	durations := make([]models.RunDuration, len(seconds))
+	for i, s := range seconds {
+		durations[i] = models.RunDuration{
+			RunID:       int64(i + 1),
+			Name:        name,
+			Repository:  "app",
+			HtmlUrl:     "https://github.com/octo/app/actions/runs/1",
+			CompletedAt: end.Add(-time.Duration(len(seconds)-1-i) * time.Hour),
+			Seconds:     s,
+		}
+	}
+	return durations
+}
+
+// workflowChange builds a change to the CI workflow file of octo/app.
+func workflowChange(committedAt time.Time) models.WorkflowFileChange {
+	return models.WorkflowFileChange{
+		Repository:  "octo/app",
+		Path:        ".github/workflows/ci.yml",
+		SHA:         "abc123",
+		Message:     "Bump node",
+		CommittedAt: committedAt,
+	}
+}
+
+// runOutcomes builds runs of the CI workflow file of octo/app, one per hour
+// ending an hour before the change and the rest starting an hour after it.
+func runOutcomes(changedAt time.Time, before int, conclusions ...string) []models.RunOutcome {
+	runs := make([]models.RunOutcome, len(conclusions))
+	for i, conclusion := range conclusions {
+		offset := time.Duration(i-before) * time.Hour
+		if i >= before {
+			offset += time.Hour
+		}
+		runs[i] = models.RunOutcome{
+			RunID:        int64(i + 1),
+			Repository:   "octo/app",
+			Name:         "CI",
+			WorkflowPath: ".github/workflows/ci.yml",
+			HtmlUrl:      fmt.Sprintf("https://github.com/octo/app/actions/runs/%d", i+1),
+			Conclusion:   conclusion,
+			CreatedAt:    changedAt.Add(offset),
+		}
+	}
+	return runs
+}
+
+func TestFindWorkflowChangeCorrelations(t *testing.T) {
+	now := time.Now()
+	changedAt := now.Add(-5 * time.Hour)
+
+	t.Run("Flags a rise in failures after a change", func(t *testing.T) {
+		runs := runOutcomes(changedAt, 4, "success", "success", "failure", "success", "success", "failure", "failure", "success")
+
+		correlations := findWorkflowChangeCorrelations(runs, []models.WorkflowFileChange{workflowChange(changedAt)}, now.Add(-24*time.Hour), 20)
+
+		require.Len(t, correlations, 1)
+		assert.Equal(t, "CI", correlations[0].Workflow)
+		assert.Equal(t, "abc123", correlations[0].Change.SHA)
+		assert.Equal(t, 4, correlations[0].RunsBefore)
+		assert.Equal(t, 4, correlations[0].RunsAfter)
+		assert.Equal(t, 25.0, correlations[0].FailureRateBefore)
+		assert.Equal(t, 50.0, correlations[0].FailureRateAfter)
+		assert.Equal(t, int64(6), correlations[0].FirstFailedRunID)
+		assert.Equal(t, "https://github.com/octo/app/actions/runs/6", correlations[0].FirstFailedRunUrl)
+	})
+
+	t.Run("Ignores shifts below the threshold", func(t *testing.T) {
+		runs := runOutcomes(changedAt, 4, "success", "success", "failure", "success", "success", "failure", "failure", "success")
+
+		assert.Empty(t, findWorkflowChangeCorrelations(runs, []models.WorkflowFileChange{workflowChange(changedAt)}, now.Add(-24*time.Hour), 30))
+	})
+
+	t.Run("Does not count cancelled runs", func(t *testing.T) {
+		runs := runOutcomes(changedAt, 3, "success", "success", "success", "cancelled", "cancelled", "failure", "success")
+
+		assert.Empty(t, findWorkflowChangeCorrelations(runs, []models.WorkflowFileChange{workflowChange(changedAt)}, now.Add(-24*time.Hour), 20))
+	})
+
+	t.Run("Needs enough runs on either side", func(t *testing.T) {
+		runs := runOutcomes(changedAt, 2, "success", "success", "failure", "failure", "failure")
+
+		assert.Empty(t, findWorkflowChangeCorrelations(runs, []models.WorkflowFileChange{workflowChange(changedAt)}, now.Add(-24*time.Hour), 20))
+	})
+
+	t.Run("Only reports changes after the cutoff", func(t *testing.T) {
+		runs := runOutcomes(changedAt, 3, "success", "success", "success", "failure", "failure", "failure")
+
+		assert.Empty(t, findWorkflowChangeCorrelations(runs, []models.WorkflowFileChange{workflowChange(changedAt)}, now.Add(-time.Hour), 20))
+	})
+
+	t.Run("Compares runs between neighbouring changes", func(t *testing.T) {
+		runs := runOutcomes(changedAt, 3, "success", "success", "success", "failure", "failure", "failure")
+		revert := workflowChange(changedAt.Add(150 * time.Minute))
+		revert.SHA = "def456"
+
+		correlations := findWorkflowChangeCorrelations(runs, []models.WorkflowFileChange{workflowChange(changedAt), revert}, now.Add(-24*time.Hour), 20)
+
+		// Only two runs precede the revert, the first of which follows the change
+		assert.Empty(t, correlations)
+
+		revert.CommittedAt = changedAt.Add(210 * time.Minute)
+		correlations = findWorkflowChangeCorrelations(runs, []models.WorkflowFileChange{workflowChange(changedAt), revert}, now.Add(-24*time.Hour), 20)
+
+		require.Len(t, correlations, 1)
+		assert.Equal(t, "abc123", correlations[0].Change.SHA)
+		assert.Equal(t, 3, correlations[0].RunsAfter)
+	})
+}
+
+func TestFindRegressions(t *testing.T) {
+	now := time.Now()
+
+	t.Run("Flags runs well above the trailing median", func(t *testing.T) {
+		durations := runDurations("CI", now, 600, 620, 580, 610, 590, 1200)
+
+		regressions := findRegressions(durations, now.Add(-time.Hour), 50)
+
+		require.Len(t, regressions, 1)
+		assert.Equal(t, int64(6), regressions[0].RunID)
+		assert.Equal(t, 600.0, regressions[0].BaselineSeconds)
+		assert.Equal(t, 100.0, regressions[0].IncreasePercent)
+		assert.Equal(t, 5, regressions[0].Samples)
+	})
+
+	t.Run("Ignores increases below the threshold", func(t *testing.T) {
+		durations := runDurations("CI", now, 600, 620, 580, 610, 590, 800)
+
+		assert.Empty(t, findRegressions(durations, now.Add(-time.Hour), 50))
+	})
+
+	t.Run("Ignores small absolute increases", func(t *testing.T) {
+		durations := runDurations("Lint", now, 20, 20, 20, 20, 20, 70)
+
+		assert.Empty(t, findRegressions(durations, now.Add(-time.Hour), 50))
+	})
+
+	t.Run("Needs enough history", func(t *testing.T) {
+		durations := runDurations("CI", now, 600, 600, 600, 1200)
+
+		assert.Empty(t, findRegressions(durations, now.Add(-time.Hour), 50))
+	})
+
+	t.Run("Only reports runs after the cutoff", func(t *testing.T) {
+		durations := runDurations("CI", now, 600, 600, 600, 600, 600, 1200, 600)
+
+		assert.Empty(t, findRegressions(durations, now.Add(-30*time.Minute), 50))
+		assert.Len(t, findRegressions(durations, now.Add(-2*time.Hour), 50), 1)
+	})
+
+	t.Run("Keeps workflows separate", func(t *testing.T) {
+		durations := append(runDurations("Fast", now, 60, 60, 60, 60, 60), runDurations("Slow", now, 1200)...)
+
+		assert.Empty(t, findRegressions(durations, now.Add(-time.Hour), 50))
+	})
+}
+
+func TestGetDurationRegressions(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	testConfig.Vars.RegressionThresholdPercent = 50
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/analytics/regressions", handler.GetDurationRegressions())
+
+	now := time.Now()
+	changedAt := now.Add(-3 * time.Hour)
+	mockDB.On("GetRunDurations", mock.Anything, 24*time.Hour+regressionBaselinePeriod, []string{"app"}).
+		Return(runDurations("CI", now, 600, 620, 580, 610, 590, 1200), nil)
+	mockDB.On("GetWorkflowFileChanges", mock.Anything, 24*time.Hour+regressionBaselinePeriod, []string{"app"}).
+		Return([]models.WorkflowFileChange{workflowChange(changedAt)}, nil)
+	mockDB.On("GetRunOutcomes", mock.Anything, 24*time.Hour+regressionBaselinePeriod, []string{"app"}).
+		Return(runOutcomes(changedAt, 3, "success", "success", "success", "failure", "failure", "success"), nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/analytics/regressions?period=day&repo=app", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Regressions      []models.DurationRegression        `json:"regressions"`
+		ThresholdPercent int                                `json:"threshold_percent"`
+		WorkflowChanges  []models.WorkflowChangeCorrelation `json:"workflow_changes"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Regressions, 1)
+	assert.Equal(t, "CI", response.Regressions[0].Name)
+	assert.Equal(t, 50, response.ThresholdPercent)
+	require.Len(t, response.WorkflowChanges, 1)
+	assert.Equal(t, "abc123", response.WorkflowChanges[0].Change.SHA)
+	mockDB.AssertExpectations(t)
+}
+
+func TestGetDurationRegressions_NoWorkflowChanges(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/analytics/regressions", handler.GetDurationRegressions())
+
+	mockDB.On("GetRunDurations", mock.Anything, mock.Anything, []string(nil)).Return([]models.RunDuration{}, nil)
+	mockDB.On("GetWorkflowFileChanges", mock.Anything, mock.Anything, []string(nil)).Return([]models.WorkflowFileChange{}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/analytics/regressions", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"workflow_changes":[]`)
+	mockDB.AssertNotCalled(t, "GetRunOutcomes", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetDurationRegressions_DatabaseError(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/analytics/regressions", handler.GetDurationRegressions())
+
+	mockDB.On("GetRunDurations", mock.Anything, mock.Anything, []string(nil)).Return([]models.RunDuration{}, nil)
+	mockDB.On("GetWorkflowFileChanges", mock.Anything, mock.Anything, []string(nil)).Return([]models.WorkflowFileChange{}, errors.New("db error"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/analytics/regressions", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestWorkflowRunHandler_RegressionAlert(t *testing.T) {
+	mockDB := setupWorkflowRunTest()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	durations := runDurations("CI", now, 600, 620, 580, 610, 590, 1200)
+	mockDB.On("AddOrUpdateRun", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+	mockDB.On("GetRunDurations", mock.Anything, regressionBaselinePeriod, []string{"app"}).Return(durations, nil)
+	mockDB.On("IsMuted", mock.Anything, int64(6), int64(0)).Return(false, nil)
+
+	var alerts []models.Alert
+	notifier := notify.NewNotifier("", mockDB, func(a models.Alert) { alerts = append(alerts, a) })
+	cfg := &config.Config{Vars: config.Vars{RegressionAlerts: true, RegressionThresholdPercent: 50}}
+	handler := NewWorkflowRunHandler(cfg, mockDB, notifier)
+
+	payload, _ := json.Marshal(models.WorkflowRunEvent{
+		Action:      "completed",
+		Repository:  models.Repository{Name: "app"},
+		WorkflowRun: models.WorkflowRun{ID: 6, Name: "CI", Conclusion: "success", UpdatedAt: now},
+	})
+	require.NoError(t, handler.HandleEvent(payload, &models.EventSequence{Timestamp: now}))
+
+	require.Len(t, alerts, 1)
+	assert.Equal(t, "duration_regression", alerts[0].Type)
+	assert.Equal(t, int64(6), alerts[0].RunID)
+	assert.Contains(t, alerts[0].Message, "took 20m0s against a median of 10m0s")
+	mockDB.AssertExpectations(t)
+}
+
+func TestWorkflowRunHandler_RegressionAlertsDisabled(t *testing.T) {
+	mockDB := setupWorkflowRunTest()
+	mockDB.On("AddOrUpdateRun", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+
+	handler := NewWorkflowRunHandler(&config.Config{}, mockDB, nil)
+
+	payload, _ := json.Marshal(models.WorkflowRunEvent{
+		Action:      "completed",
+		Repository:  models.Repository{Name: "app"},
+		WorkflowRun: models.WorkflowRun{ID: 6, Name: "CI", Conclusion: "success"},
+	})
+	require.NoError(t, handler.HandleEvent(payload, &models.EventSequence{Timestamp: time.Now()}))
+
+	mockDB.AssertNotCalled(t, "GetRunDurations", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/config"
+	"github.com/gateixeira/live-actions/internal/remotewrite"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// maxRemoteWriteBodySize bounds a compressed remote-write request; clients
+// send batches well below this by default.
+const maxRemoteWriteBodySize = 10 << 20
+
+// RequireRemoteWriteToken middleware only lets through exporters carrying
+// REMOTE_WRITE_TOKEN, and rejects all requests when it is unset.
+func RequireRemoteWriteToken(config *config.Config) gin.HandlerFunc {
+	return requireBearerToken(config.Vars.RemoteWriteToken, "Remote write", "REMOTE_WRITE_TOKEN")
+}
+
+// ReceiveRemoteWrite accepts Prometheus remote-write requests from runner
+// exporters and stores the series listed in REMOTE_WRITE_METRICS, so host
+// metrics can be shown without running a Prometheus server.
+func (h *APIHandler) ReceiveRemoteWrite() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxRemoteWriteBodySize))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body too large"})
+				return
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+			return
+		}
+
+		series, err := remotewrite.Decode(body)
+		if err != nil {
+			logger.Logger.Warn("Rejected remote write request", zap.String("client_ip", c.ClientIP()), zap.Error(err))
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid remote write request"})
+			return
+		}
+
+		samples := hostMetricSamples(series, h.config.Vars.RemoteWriteMetrics, h.config.Vars.RemoteWriteHostLabel)
+		if len(samples) > 0 {
+			if err := h.db.SaveHostMetricSamples(c.Request.Context(), samples); err != nil {
+				logger.Logger.Error("Failed to save host metric samples", zap.Error(err))
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store samples"})
+				return
+			}
+		}
+
+		logger.Module("remote_write").Debug("Stored remote write samples",
+			zap.Int("series", len(series)),
+			zap.Int("samples", len(samples)))
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// hostMetricSamples keeps the samples of series named in metrics that carry
+// hostLabel. NaN values, which Prometheus uses as staleness markers, and
+// infinities are dropped.
+func hostMetricSamples(series []remotewrite.Series, metrics []string, hostLabel string) []models.HostMetricSample {
+	allowed := make(map[string]bool, len(metrics))
+	for _, m := range metrics {
+		allowed[m] = true
+	}
+
+	var samples []models.HostMetricSample
+	for _, s := range series {
+		metric := s.Labels[remotewrite.NameLabel]
+		host := s.Labels[hostLabel]
+		if !allowed[metric] || host == "" {
+			continue
+		}
+
+		var names []string
+		for name := range s.Labels {
+			if name != remotewrite.NameLabel && name != hostLabel {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		pairs := make([]string, 0, len(names))
+		for _, name := range names {
+			pairs = append(pairs, fmt.Sprintf("%s=%q", name, s.Labels[name]))
+		}
+		labels := strings.Join(pairs, ",")
+
+		for _, sample := range s.Samples {
+			if math.IsNaN(sample.Value) || math.IsInf(sample.Value, 0) {
+				continue
+			}
+			samples = append(samples, models.HostMetricSample{
+				Host:      host,
+				Metric:    metric,
+				Labels:    labels,
+				Timestamp: time.UnixMilli(sample.Timestamp),
+				Value:     sample.Value,
+			})
+		}
+	}
+	return samples
+}
+
+// GetHostMetrics returns one host metric pushed via remote write over a period
+// (default: hour), as a series per host.
+func (h *APIHandler) GetHostMetrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		metric := c.Query("metric")
+		if metric == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "metric is required", "metrics": h.config.Vars.RemoteWriteMetrics})
+			return
+		}
+		period := c.DefaultQuery("period", "hour")
+
+		series, err := h.db.GetHostMetricSeries(c.Request.Context(), metric, periodToDuration(period))
+		if err != nil {
+			logger.Logger.Error("Failed to get host metric series", zap.String("metric", metric), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve host metrics"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"metric": metric,
+			"period": period,
+			"series": series,
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// remoteWriteBody encodes one series per label set as a snappy-compressed
+// WriteRequest, each with a single sample.
+func remoteWriteBody(series []map[string]string, value float64, timestampMs int64) []byte {
+	var req []byte
+	for _, labels := range series {
+		var ts []byte
+		for name, v := range labels {
+			var label []byte
+			label = protowire.AppendTag(label, 1, protowire.BytesType)
+			label = protowire.AppendString(label, name)
+			label = protowire.AppendTag(label, 2, protowire.BytesType)
+			label = protowire.AppendString(label, v)
+			ts = protowire.AppendTag(ts, 1, protowire.BytesType)
+			ts = protowire.AppendBytes(ts, label)
+		}
+		var sample []byte
+		sample = protowire.AppendTag(sample, 1, protowire.Fixed64Type)
+		sample = protowire.AppendFixed64(sample, math.Float64bits(value))
+		sample = protowire.AppendTag(sample, 2, protowire.VarintType)
+		sample = protowire.AppendVarint(sample, uint64(timestampMs))
+		ts = protowire.AppendTag(ts, 2, protowire.BytesType)
+		ts = protowire.AppendBytes(ts, sample)
+		req = protowire.AppendTag(req, 1, protowire.BytesType)
+		req = protowire.AppendBytes(req, ts)
+	}
+
+	// A single snappy literal of up to 64KiB
+	out := binary.AppendUvarint(nil, uint64(len(req)))
+	out = append(out, 61<<2, byte(len(req)-1), byte((len(req)-1)>>8))
+	return append(out, req...)
+}
+
+func TestReceiveRemoteWrite(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	testConfig.Vars.RemoteWriteToken = "secret"
+	testConfig.Vars.RemoteWriteMetrics = []string{"node_load1", "node_filesystem_avail_bytes"}
+	testConfig.Vars.RemoteWriteHostLabel = "instance"
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.POST("/api/remote-write", RequireRemoteWriteToken(testConfig), handler.ReceiveRemoteWrite())
+
+	ts := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+	body := remoteWriteBody([]map[string]string{
+		{"__name__": "node_filesystem_avail_bytes", "instance": "runner-1:9100", "mountpoint": "/", "device": "sda1"},
+		{"__name__": "node_cpu_seconds_total", "instance": "runner-1:9100"}, // not allowed
+		{"__name__": "node_load1"}, // no host
+	}, 1024, ts.UnixMilli())
+
+	mockDB.On("SaveHostMetricSamples", mock.Anything, []models.HostMetricSample{{
+		Host:      "runner-1:9100",
+		Metric:    "node_filesystem_avail_bytes",
+		Labels:    `device="sda1",mountpoint="/"`,
+		Timestamp: time.UnixMilli(ts.UnixMilli()),
+		Value:     1024,
+	}}).Return(nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/remote-write", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("Content-Encoding", "snappy")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	mockDB.AssertExpectations(t)
+}
+
+func TestReceiveRemoteWrite_Rejected(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	testConfig.Vars.RemoteWriteToken = "secret"
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.POST("/api/remote-write", RequireRemoteWriteToken(testConfig), handler.ReceiveRemoteWrite())
+
+	tests := []struct {
+		name  string
+		token string
+		body  []byte
+		want  int
+	}{
+		{"missing token", "", remoteWriteBody(nil, 0, 0), http.StatusUnauthorized},
+		{"not snappy", "secret", []byte("not a write request"), http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/remote-write", bytes.NewReader(tt.body))
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+	mockDB.AssertNotCalled(t, "SaveHostMetricSamples", mock.Anything, mock.Anything)
+}
+
+func TestReceiveRemoteWrite_DropsStaleMarkers(t *testing.T) {
+	body := remoteWriteBody([]map[string]string{{"__name__": "node_load1", "instance": "runner-1"}}, math.NaN(), 1)
+	router, mockDB, testConfig := setupAPITest()
+	testConfig.Vars.RemoteWriteToken = "secret"
+	testConfig.Vars.RemoteWriteMetrics = []string{"node_load1"}
+	testConfig.Vars.RemoteWriteHostLabel = "instance"
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.POST("/api/remote-write", RequireRemoteWriteToken(testConfig), handler.ReceiveRemoteWrite())
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/remote-write", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer secret")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	mockDB.AssertNotCalled(t, "SaveHostMetricSamples", mock.Anything, mock.Anything)
+}
+
+func TestGetHostMetrics(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/hosts/metrics", handler.GetHostMetrics())
+
+	mockDB.On("GetHostMetricSeries", mock.Anything, "node_load1", time.Hour).Return([]models.HostMetricSeries{
+		{Host: "runner-1", Points: []models.HostMetricPoint{{Timestamp: 1714644000, Value: 0.5}}},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/hosts/metrics?metric=node_load1", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Metric string                    `json:"metric"`
+		Series []models.HostMetricSeries `json:"series"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "node_load1", response.Metric)
+	require.Len(t, response.Series, 1)
+	assert.Equal(t, "runner-1", response.Series[0].Host)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/hosts/metrics", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
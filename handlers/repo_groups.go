@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const maxRepoGroupSize = 100
+
+type repoGroupRequest struct {
+	Repositories []string `json:"repositories"`
+}
+
+// repoGroups returns the groups defined in REPO_GROUPS followed by those
+// managed through the API. A configured group hides an API group of the same name.
+func (h *APIHandler) repoGroups(ctx context.Context) ([]models.RepoGroup, error) {
+	groups := []models.RepoGroup{}
+	for name, repos := range h.config.Vars.RepoGroups {
+		groups = append(groups, models.RepoGroup{Name: name, Repositories: repos, Source: "config"})
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+
+	stored, err := h.db.GetRepoGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range stored {
+		if _, ok := h.config.Vars.RepoGroups[g.Name]; !ok {
+			groups = append(groups, g)
+		}
+	}
+	return groups, nil
+}
+
+// repoFilter resolves the ?repo= or ?group= query parameter into the
+// repositories to filter on; nil means all repositories. When the group is
+// unknown it writes an error response and returns false.
+func (h *APIHandler) repoFilter(c *gin.Context) ([]string, bool) {
+	repo := c.Query("repo")
+	name := c.Query("group")
+	if name == "" {
+		if repo == "" {
+			return nil, true
+		}
+		return []string{repo}, true
+	}
+	if repo != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "repo and group cannot be combined"})
+		return nil, false
+	}
+
+	if repos, ok := h.config.Vars.RepoGroups[name]; ok {
+		return repos, true
+	}
+	groups, err := h.db.GetRepoGroups(c.Request.Context())
+	if err != nil {
+		logger.Logger.Error("Failed to get repo groups", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve repository group"})
+		return nil, false
+	}
+	for _, g := range groups {
+		if g.Name == name {
+			return g.Repositories, true
+		}
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": "Repository group not found"})
+	return nil, false
+}
+
+// GetRepoGroups lists all repository groups.
+func (h *APIHandler) GetRepoGroups() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		groups, err := h.repoGroups(c.Request.Context())
+		if err != nil {
+			logger.Logger.Error("Failed to get repo groups", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve repository groups"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"groups": groups})
+	}
+}
+
+// SaveRepoGroup creates or replaces a repository group.
+func (h *APIHandler) SaveRepoGroup() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Param("name")
+		if !tagPattern.MatchString(name) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Group name must be 1-32 lowercase letters, digits, '-' or '_'"})
+			return
+		}
+		if _, ok := h.config.Vars.RepoGroups[name]; ok {
+			c.JSON(http.StatusConflict, gin.H{"error": "Group is defined in configuration"})
+			return
+		}
+
+		var req repoGroupRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		group := models.RepoGroup{Name: name, Repositories: []string{}, Source: "api"}
+		seen := make(map[string]bool)
+		for _, repo := range req.Repositories {
+			if repo = strings.TrimSpace(repo); repo != "" && !seen[repo] {
+				seen[repo] = true
+				group.Repositories = append(group.Repositories, repo)
+			}
+		}
+		if len(group.Repositories) == 0 || len(group.Repositories) > maxRepoGroupSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Group must contain between 1 and 100 repositories"})
+			return
+		}
+
+		if err := h.db.SaveRepoGroup(c.Request.Context(), group); err != nil {
+			logger.Logger.Error("Failed to save repo group", zap.String("name", name), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save repository group"})
+			return
+		}
+
+		SendConfigChanged(ConfigRepoGroups)
+		c.JSON(http.StatusOK, group)
+	}
+}
+
+// DeleteRepoGroup removes a repository group managed through the API.
+func (h *APIHandler) DeleteRepoGroup() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Param("name")
+		if _, ok := h.config.Vars.RepoGroups[name]; ok {
+			c.JSON(http.StatusConflict, gin.H{"error": "Group is defined in configuration"})
+			return
+		}
+
+		deleted, err := h.db.DeleteRepoGroup(c.Request.Context(), name)
+		if err != nil {
+			logger.Logger.Error("Failed to delete repo group", zap.String("name", name), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete repository group"})
+			return
+		}
+		if !deleted {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Repository group not found"})
+			return
+		}
+
+		SendConfigChanged(ConfigRepoGroups)
+		c.Status(http.StatusNoContent)
+	}
+}
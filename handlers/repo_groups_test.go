@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gateixeira/live-actions/internal/services"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetRepoGroups(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	testConfig.Vars.RepoGroups = map[string][]string{"payments": {"api", "billing"}}
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/repo-groups", handler.GetRepoGroups())
+
+	mockDB.On("GetRepoGroups", mock.Anything).Return([]models.RepoGroup{
+		{Name: "payments", Repositories: []string{"shadowed"}, Source: "api"},
+		{Name: "platform", Repositories: []string{"infra"}, Source: "api"},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/repo-groups", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Groups []models.RepoGroup `json:"groups"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []models.RepoGroup{
+		{Name: "payments", Repositories: []string{"api", "billing"}, Source: "config"},
+		{Name: "platform", Repositories: []string{"infra"}, Source: "api"},
+	}, response.Groups)
+}
+
+func TestSaveRepoGroup(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		body           string
+		expectSave     bool
+		expectedStatus int
+	}{
+		{"Valid group", "/api/repo-groups/platform", `{"repositories": [" infra ", "infra", "deploy"]}`, true, http.StatusOK},
+		{"Invalid name", "/api/repo-groups/Platform!", `{"repositories": ["infra"]}`, false, http.StatusBadRequest},
+		{"No repositories", "/api/repo-groups/platform", `{"repositories": [" "]}`, false, http.StatusBadRequest},
+		{"Configured group", "/api/repo-groups/payments", `{"repositories": ["infra"]}`, false, http.StatusConflict},
+		{"Invalid body", "/api/repo-groups/platform", `not json`, false, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, mockDB, testConfig := setupAPITest()
+			testConfig.Vars.RepoGroups = map[string][]string{"payments": {"api"}}
+			if tt.expectSave {
+				mockDB.On("SaveRepoGroup", mock.Anything, models.RepoGroup{
+					Name: "platform", Repositories: []string{"infra", "deploy"}, Source: "api",
+				}).Return(nil)
+			}
+			handler := NewAPIHandler(testConfig, mockDB)
+			router.PUT("/api/repo-groups/:name", handler.SaveRepoGroup())
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("PUT", tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeleteRepoGroup(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	testConfig.Vars.RepoGroups = map[string][]string{"payments": {"api"}}
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.DELETE("/api/repo-groups/:name", handler.DeleteRepoGroup())
+
+	mockDB.On("DeleteRepoGroup", mock.Anything, "platform").Return(true, nil)
+	mockDB.On("DeleteRepoGroup", mock.Anything, "missing").Return(false, nil)
+
+	for path, status := range map[string]int{
+		"/api/repo-groups/platform": http.StatusNoContent,
+		"/api/repo-groups/missing":  http.StatusNotFound,
+		"/api/repo-groups/payments": http.StatusConflict,
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", path, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code, path)
+	}
+}
+
+func TestRepoFilter_Group(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	testConfig.Vars.RepoGroups = map[string][]string{"payments": {"api", "billing"}}
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/analytics/unschedulable", handler.GetUnschedulableJobs())
+
+	mockDB.On("GetRepoGroups", mock.Anything).Return([]models.RepoGroup{
+		{Name: "platform", Repositories: []string{"infra"}, Source: "api"},
+	}, nil)
+	mockDB.On("GetUnschedulableJobs", mock.Anything, services.UnschedulableQueuedFor, []string{"api", "billing"}).Return([]models.UnschedulableJob{}, nil)
+	mockDB.On("GetUnschedulableJobs", mock.Anything, services.UnschedulableQueuedFor, []string{"infra"}).Return([]models.UnschedulableJob{}, nil)
+
+	for path, status := range map[string]int{
+		"/api/analytics/unschedulable?group=payments":          http.StatusOK,
+		"/api/analytics/unschedulable?group=platform":          http.StatusOK,
+		"/api/analytics/unschedulable?group=missing":           http.StatusNotFound,
+		"/api/analytics/unschedulable?group=payments&repo=api": http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code, path)
+	}
+	mockDB.AssertExpectations(t)
+}
@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/internal/utils"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultHandoffHours = 12
+	maxHandoffHours     = 7 * 24
+	handoffRunLimit     = 100
+
+	// A label's queue time is unusual when it is at least queueAnomalyFactor
+	// times its weekly baseline and above queueAnomalyMinSeconds.
+	queueAnomalyBaseline   = 7 * 24 * time.Hour
+	queueAnomalyFactor     = 2.0
+	queueAnomalyMinSeconds = 60.0
+)
+
+// GetHandoffReport returns a summary of failures, long-running runs, top failing jobs
+// and unusual queue activity over the last ?hours= (default 12) for shift handoffs.
+// With ?format=markdown the report is rendered as text ready to paste into notes.
+func (h *APIHandler) GetHandoffReport() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		hours := defaultHandoffHours
+		if v := c.Query("hours"); v != "" {
+			parsed, err := strconv.Atoi(v)
+			if err != nil || parsed < 1 || parsed > maxHandoffHours {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "hours must be between 1 and 168"})
+				return
+			}
+			hours = parsed
+		}
+
+		report, err := h.buildHandoffReport(c.Request.Context(), time.Duration(hours)*time.Hour)
+		if err != nil {
+			logger.Logger.Error("Failed to build handoff report", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build handoff report"})
+			return
+		}
+
+		if c.Query("format") == "markdown" {
+			if err := h.anonymized(report); err != nil {
+				logger.Logger.Error("Failed to anonymize handoff report", zap.Error(err))
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build handoff report"})
+				return
+			}
+			c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(renderHandoffMarkdown(report)))
+			return
+		}
+		c.JSON(http.StatusOK, report)
+	}
+}
+
+// buildHandoffReport builds the report from one snapshot of the database, so
+// its sections agree with each other.
+func (h *APIHandler) buildHandoffReport(ctx context.Context, window time.Duration) (*models.HandoffReport, error) {
+	var report *models.HandoffReport
+	err := h.db.ReadSnapshot(ctx, func(db database.DatabaseInterface) error {
+		var err error
+		report, err = h.handoffReport(ctx, db, window)
+		return err
+	})
+	return report, err
+}
+
+func (h *APIHandler) handoffReport(ctx context.Context, db database.DatabaseInterface, window time.Duration) (*models.HandoffReport, error) {
+	now := h.config.Now().UTC()
+	report := &models.HandoffReport{
+		WindowStart:     now.Add(-window),
+		WindowEnd:       now,
+		FailedRuns:      []models.WorkflowRun{},
+		LongRunningRuns: []models.WorkflowRun{},
+		QueueAnomalies:  []models.QueueAnomaly{},
+	}
+
+	notable, err := db.GetNotableRuns(ctx, window, h.config.GetLongRunningThreshold(), nil, handoffRunLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notable runs: %w", err)
+	}
+	for _, n := range notable {
+		if n.Kind == "long_running" {
+			report.LongRunningRuns = append(report.LongRunningRuns, n.Run)
+		} else {
+			report.FailedRuns = append(report.FailedRuns, n.Run)
+		}
+	}
+
+	if report.Failures, err = h.failureAnalytics(ctx, db, window, nil); err != nil {
+		return nil, fmt.Errorf("failed to get failure analytics: %w", err)
+	}
+
+	summary, err := db.GetMetricsSummary(ctx, window)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metrics summary: %w", err)
+	}
+	report.PeakDemand = summary["peak_demand"]
+	report.AvgQueueSeconds = summary["avg_queue_time"]
+
+	current, err := db.GetLabelDemandSummary(ctx, window, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get label demand: %w", err)
+	}
+	baseline, err := db.GetLabelDemandSummary(ctx, queueAnomalyBaseline, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get baseline label demand: %w", err)
+	}
+	report.QueueAnomalies = findQueueAnomalies(current, baseline)
+
+	if report.ActiveMutes, err = db.GetActiveMutes(ctx); err != nil {
+		return nil, fmt.Errorf("failed to get active mutes: %w", err)
+	}
+
+	return report, nil
+}
+
+// findQueueAnomalies returns the labels whose average queue time is unusually high
+// compared to the baseline window.
+func findQueueAnomalies(current, baseline []models.LabelDemandSummary) []models.QueueAnomaly {
+	baselineByLabel := make(map[string]float64, len(baseline))
+	for _, b := range baseline {
+		baselineByLabel[b.Label] = b.AvgQueueSeconds
+	}
+
+	anomalies := []models.QueueAnomaly{}
+	for _, l := range current {
+		base := baselineByLabel[l.Label]
+		if l.AvgQueueSeconds < queueAnomalyMinSeconds || l.AvgQueueSeconds < base*queueAnomalyFactor {
+			continue
+		}
+		anomalies = append(anomalies, models.QueueAnomaly{
+			Label:                   l.Label,
+			TotalJobs:               l.TotalJobs,
+			AvgQueueSeconds:         l.AvgQueueSeconds,
+			BaselineAvgQueueSeconds: base,
+		})
+	}
+	return anomalies
+}
+
+func renderHandoffMarkdown(r *models.HandoffReport) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "## CI handoff: %s to %s (UTC)\n\n",
+		r.WindowStart.Format("2006-01-02 15:04"), r.WindowEnd.Format("2006-01-02 15:04"))
+
+	if r.Failures != nil {
+		fmt.Fprintf(&b, "**Jobs:** %d completed, %d failed (%.1f%%), %d cancelled, %d muted failures\n",
+			r.Failures.TotalCompleted, r.Failures.TotalFailed, r.Failures.FailureRate, r.Failures.TotalCancelled, r.Failures.TotalMuted)
+	}
+	fmt.Fprintf(&b, "**Queue:** peak demand %.0f, average wait %s\n\n", r.PeakDemand, formatSeconds(r.AvgQueueSeconds))
+
+	b.WriteString("### Failed runs\n")
+	if len(r.FailedRuns) == 0 {
+		b.WriteString("- None\n")
+	}
+	for _, run := range r.FailedRuns {
+		fmt.Fprintf(&b, "- [%s] %s: %s (%s) %s\n",
+			utils.RepoFullName(run.HtmlUrl, run.RepositoryName), run.Name, run.DisplayTitle, run.Conclusion, run.HtmlUrl)
+	}
+
+	b.WriteString("\n### Long-running runs\n")
+	if len(r.LongRunningRuns) == 0 {
+		b.WriteString("- None\n")
+	}
+	for _, run := range r.LongRunningRuns {
+		fmt.Fprintf(&b, "- [%s] %s: running for %s %s\n",
+			utils.RepoFullName(run.HtmlUrl, run.RepositoryName), run.Name, r.WindowEnd.Sub(run.RunStartedAt).Round(time.Minute), run.HtmlUrl)
+	}
+
+	b.WriteString("\n### Top failing jobs\n")
+	if r.Failures == nil || len(r.Failures.TopFailingJobs) == 0 {
+		b.WriteString("- None\n")
+	} else {
+		for _, j := range r.Failures.TopFailingJobs {
+			fmt.Fprintf(&b, "- %s: %d/%d failed (%.1f%%)\n", j.Name, j.Failures, j.Total, j.FailureRate)
+		}
+	}
+
+	b.WriteString("\n### Unusual queue activity\n")
+	if len(r.QueueAnomalies) == 0 {
+		b.WriteString("- None\n")
+	}
+	for _, a := range r.QueueAnomalies {
+		fmt.Fprintf(&b, "- %s: average wait %s over %d jobs (weekly baseline %s)\n",
+			a.Label, formatSeconds(a.AvgQueueSeconds), a.TotalJobs, formatSeconds(a.BaselineAvgQueueSeconds))
+	}
+
+	if len(r.ActiveMutes) > 0 {
+		b.WriteString("\n### Active mutes\n")
+		for _, m := range r.ActiveMutes {
+			fmt.Fprintf(&b, "- %s %d until %s: %s\n", m.EntityType, m.EntityID, m.MutedUntil.UTC().Format("2006-01-02 15:04"), m.Reason)
+		}
+	}
+
+	return b.String()
+}
+
+func formatSeconds(seconds float64) string {
+	return (time.Duration(seconds) * time.Second).Round(time.Second).String()
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/anonymize"
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupHandoffMocks(mockDB *database.MockDatabase, window time.Duration) {
+	now := time.Now()
+	mockDB.On("GetNotableRuns", mock.Anything, window, mock.Anything, []string(nil), handoffRunLimit).Return([]models.NotableRun{
+		{Kind: "failure", Run: models.WorkflowRun{ID: 1, Name: "Deploy", DisplayTitle: "Release 1.2", Conclusion: "failure", HtmlUrl: "https://github.com/octo/app/actions/runs/1"}},
+		{Kind: "long_running", Run: models.WorkflowRun{ID: 2, Name: "Nightly", HtmlUrl: "https://github.com/octo/app/actions/runs/2", RunStartedAt: now.Add(-3 * time.Hour)}},
+	}, nil)
+	mockDB.On("GetFailureAnalytics", mock.Anything, window, []string(nil)).Return(&models.FailureAnalytics{
+		TotalCompleted: 10, TotalFailed: 2, FailureRate: 20,
+		TopFailingJobs: []models.FailingJob{{Name: "integration", Failures: 2, Total: 4, FailureRate: 50}},
+	}, nil)
+	mockDB.On("GetJobNameMerges", mock.Anything).Return([]models.JobNameMerge{}, nil)
+	mockDB.On("GetMetricsSummary", mock.Anything, window).Return(map[string]float64{"peak_demand": 12, "avg_queue_time": 90}, nil)
+	mockDB.On("GetLabelDemandSummary", mock.Anything, window, []string(nil)).Return([]models.LabelDemandSummary{
+		{Label: "ubuntu-latest", TotalJobs: 20, AvgQueueSeconds: 300},
+		{Label: "self-hosted", TotalJobs: 5, AvgQueueSeconds: 30},
+	}, nil)
+	mockDB.On("GetLabelDemandSummary", mock.Anything, queueAnomalyBaseline, []string(nil)).Return([]models.LabelDemandSummary{
+		{Label: "ubuntu-latest", TotalJobs: 200, AvgQueueSeconds: 60},
+		{Label: "self-hosted", TotalJobs: 50, AvgQueueSeconds: 5},
+	}, nil)
+	mockDB.On("GetActiveMutes", mock.Anything).Return([]models.Mute{{ID: 1, EntityType: "run", EntityID: 9, Reason: "known outage"}}, nil)
+}
+
+func TestGetHandoffReport_JSON(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/reports/handoff", handler.GetHandoffReport())
+	setupHandoffMocks(mockDB, 6*time.Hour)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/reports/handoff?hours=6", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var report models.HandoffReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Len(t, report.FailedRuns, 1)
+	assert.Len(t, report.LongRunningRuns, 1)
+	assert.Equal(t, 2, report.Failures.TotalFailed)
+	assert.Equal(t, float64(12), report.PeakDemand)
+	require.Len(t, report.QueueAnomalies, 1)
+	assert.Equal(t, "ubuntu-latest", report.QueueAnomalies[0].Label)
+	assert.Len(t, report.ActiveMutes, 1)
+	assert.Equal(t, 6*time.Hour, report.WindowEnd.Sub(report.WindowStart))
+	mockDB.AssertExpectations(t)
+}
+
+func TestGetHandoffReport_Markdown(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/reports/handoff", handler.GetHandoffReport())
+	setupHandoffMocks(mockDB, defaultHandoffHours*time.Hour)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/reports/handoff?format=markdown", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/markdown; charset=utf-8", w.Header().Get("Content-Type"))
+	body := w.Body.String()
+	assert.Contains(t, body, "- [octo/app] Deploy: Release 1.2 (failure)")
+	assert.Contains(t, body, "- [octo/app] Nightly: running for 3h0m0s")
+	assert.Contains(t, body, "- integration: 2/4 failed (50.0%)")
+	assert.Contains(t, body, "- ubuntu-latest: average wait 5m0s over 20 jobs (weekly baseline 1m0s)")
+	assert.Contains(t, body, "- run 9 until")
+}
+
+func TestGetHandoffReport_MarkdownAnonymized(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	a := anonymize.New("secret")
+	handler.SetAnonymizer(a)
+	router.GET("/api/reports/handoff", handler.GetHandoffReport())
+	setupHandoffMocks(mockDB, defaultHandoffHours*time.Hour)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/reports/handoff?format=markdown", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.NotContains(t, body, "octo")
+	assert.NotContains(t, body, "Release 1.2")
+	assert.Contains(t, body, "- ["+a.Repository("octo/app")+"] Deploy: title-")
+	assert.Contains(t, body, "- integration: 2/4 failed (50.0%)", "counts are unchanged")
+}
+
+func TestGetHandoffReport_InvalidHours(t *testing.T) {
+	for _, hours := range []string{"0", "169", "abc"} {
+		router, mockDB, testConfig := setupAPITest()
+		handler := NewAPIHandler(testConfig, mockDB)
+		router.GET("/api/reports/handoff", handler.GetHandoffReport())
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/reports/handoff?hours="+hours, nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, hours)
+	}
+}
+
+func TestGetHandoffReport_DatabaseError(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/reports/handoff", handler.GetHandoffReport())
+
+	mockDB.On("GetNotableRuns", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]models.NotableRun{}, errors.New("db error"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/reports/handoff", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestFindQueueAnomalies(t *testing.T) {
+	current := []models.LabelDemandSummary{
+		{Label: "new-label", AvgQueueSeconds: 120},
+		{Label: "steady", AvgQueueSeconds: 100},
+		{Label: "fast", AvgQueueSeconds: 20},
+	}
+	baseline := []models.LabelDemandSummary{
+		{Label: "steady", AvgQueueSeconds: 80},
+		{Label: "fast", AvgQueueSeconds: 1},
+	}
+
+	anomalies := findQueueAnomalies(current, baseline)
+
+	require.Len(t, anomalies, 1)
+	assert.Equal(t, "new-label", anomalies[0].Label)
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetRunnerGroups returns utilization and queue statistics per runner group.
+// Queued jobs have no group until a runner picks them up, so they are
+// reported as a single unassigned count.
+func (h *APIHandler) GetRunnerGroups() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		period := c.DefaultQuery("period", "day")
+		since := periodToDuration(period)
+		ctx := c.Request.Context()
+
+		var groups []models.RunnerGroupStats
+		var queued int
+		err := h.db.ReadSnapshot(ctx, func(db database.DatabaseInterface) error {
+			var err error
+			if groups, err = db.GetRunnerGroupStats(ctx, since); err != nil {
+				return fmt.Errorf("failed to get runner group stats: %w", err)
+			}
+			if _, queued, err = db.GetCurrentJobCounts(ctx); err != nil {
+				return fmt.Errorf("failed to get current job counts: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			logger.Logger.Error("Failed to get runner groups", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve runner groups"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"groups":            groups,
+			"queued_unassigned": queued,
+		})
+	}
+}
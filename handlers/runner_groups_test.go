@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetRunnerGroups(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/runner-groups", handler.GetRunnerGroups())
+
+	mockDB.On("GetRunnerGroupStats", mock.Anything, 7*24*time.Hour).Return([]models.RunnerGroupStats{
+		{ID: 2, Name: "production", Running: 3, Started: 40, BusyMinutes: 5040, AvgBusyRunners: 0.5, AvgQueueSeconds: 12, MaxQueueSeconds: 95},
+	}, nil)
+	mockDB.On("GetCurrentJobCounts", mock.Anything).Return(3, 4, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/runner-groups?period=week", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Groups           []models.RunnerGroupStats `json:"groups"`
+		QueuedUnassigned int                       `json:"queued_unassigned"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Groups, 1)
+	assert.Equal(t, "production", response.Groups[0].Name)
+	assert.Equal(t, 0.5, response.Groups[0].AvgBusyRunners)
+	assert.Equal(t, 4, response.QueuedUnassigned)
+}
+
+func TestGetRunnerGroups_DatabaseError(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/runner-groups", handler.GetRunnerGroups())
+
+	mockDB.On("GetRunnerGroupStats", mock.Anything, 24*time.Hour).Return([]models.RunnerGroupStats(nil), errors.New("db down"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/runner-groups", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gateixeira/live-actions/internal/config"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	maxRunnerHostLabels    = 50
+	maxRunnerHostAttribute = 64
+)
+
+// runnerNamePattern matches the runner names GitHub accepts.
+var runnerNamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+type runnerHostRequest struct {
+	Name         string   `json:"name"`
+	Labels       []string `json:"labels"`
+	Zone         string   `json:"zone"`
+	InstanceType string   `json:"instance_type"`
+}
+
+// RequireRunnerHeartbeatToken middleware only lets through runner hosts
+// carrying RUNNER_HEARTBEAT_TOKEN, and rejects all requests when it is unset.
+func RequireRunnerHeartbeatToken(config *config.Config) gin.HandlerFunc {
+	return requireBearerToken(config.Vars.RunnerHeartbeatToken, "Runner heartbeat", "RUNNER_HEARTBEAT_TOKEN")
+}
+
+// runnerHost validates a registration or heartbeat request. It writes an
+// error response and returns false when the request is invalid.
+func runnerHost(c *gin.Context, req runnerHostRequest) (models.RunnerHost, bool) {
+	if !runnerNamePattern.MatchString(req.Name) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Runner name must be 1-64 letters, digits, '.', '-' or '_'"})
+		return models.RunnerHost{}, false
+	}
+
+	host := models.RunnerHost{
+		Name:         req.Name,
+		Labels:       []string{},
+		Zone:         strings.TrimSpace(req.Zone),
+		InstanceType: strings.TrimSpace(req.InstanceType),
+	}
+	if len(host.Zone) > maxRunnerHostAttribute || len(host.InstanceType) > maxRunnerHostAttribute {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Zone and instance type must be at most 64 characters"})
+		return models.RunnerHost{}, false
+	}
+	seen := make(map[string]bool)
+	for _, label := range req.Labels {
+		if label = strings.TrimSpace(label); label != "" && !seen[label] {
+			seen[label] = true
+			host.Labels = append(host.Labels, label)
+		}
+	}
+	if len(host.Labels) > maxRunnerHostLabels {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Runner hosts can have at most 50 labels"})
+		return models.RunnerHost{}, false
+	}
+	return host, true
+}
+
+// GetRunnerHosts lists the registered runner hosts.
+func (h *APIHandler) GetRunnerHosts() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		hosts, err := h.db.GetRunnerHosts(c.Request.Context())
+		if err != nil {
+			logger.Logger.Error("Failed to get runner hosts", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve runner hosts"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"hosts": hosts})
+	}
+}
+
+// SaveRunnerHost registers a runner host or replaces its labels, zone and
+// instance type.
+func (h *APIHandler) SaveRunnerHost() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req runnerHostRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+		req.Name = c.Param("name")
+		host, ok := runnerHost(c, req)
+		if !ok {
+			return
+		}
+
+		if err := h.db.SaveRunnerHost(c.Request.Context(), host); err != nil {
+			logger.Logger.Error("Failed to save runner host", zap.String("name", host.Name), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save runner host"})
+			return
+		}
+
+		SendConfigChanged(ConfigRunnerHosts)
+		c.JSON(http.StatusOK, host)
+	}
+}
+
+// DeleteRunnerHost removes a runner host from the registry.
+func (h *APIHandler) DeleteRunnerHost() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Param("name")
+		deleted, err := h.db.DeleteRunnerHost(c.Request.Context(), name)
+		if err != nil {
+			logger.Logger.Error("Failed to delete runner host", zap.String("name", name), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete runner host"})
+			return
+		}
+		if !deleted {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Runner host not found"})
+			return
+		}
+
+		SendConfigChanged(ConfigRunnerHosts)
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// RunnerHeartbeat records that a runner host is alive, registering it on its
+// first heartbeat. Hosts can send their attributes with every heartbeat or
+// only the name once registered.
+func (h *APIHandler) RunnerHeartbeat() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req runnerHostRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+		host, ok := runnerHost(c, req)
+		if !ok {
+			return
+		}
+
+		if err := h.db.RecordRunnerHeartbeat(c.Request.Context(), host); err != nil {
+			logger.Logger.Error("Failed to record runner heartbeat", zap.String("name", host.Name), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record heartbeat"})
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// GetRunnerHostAnalytics returns runner utilization over a period (default:
+// day) sliced by the registered hosts' zone or instance type.
+func (h *APIHandler) GetRunnerHostAnalytics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		period := c.DefaultQuery("period", "day")
+		by := c.DefaultQuery("by", "zone")
+		if by != "zone" && by != "instance_type" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "by must be 'zone' or 'instance_type'"})
+			return
+		}
+
+		stats, err := h.db.GetRunnerHostStats(c.Request.Context(), periodToDuration(period), by)
+		if err != nil {
+			logger.Logger.Error("Failed to get runner host stats", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve runner host analytics"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"period": period,
+			"by":     by,
+			"slices": stats,
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveRunnerHost(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.PUT("/api/runner-hosts/:name", handler.SaveRunnerHost())
+
+	mockDB.On("SaveRunnerHost", mock.Anything, models.RunnerHost{
+		Name:         "runner-1",
+		Labels:       []string{"linux", "gpu"},
+		Zone:         "eu-west-1a",
+		InstanceType: "g5.xlarge",
+	}).Return(nil)
+
+	w := httptest.NewRecorder()
+	body := `{"labels": ["linux", " gpu", "linux", ""], "zone": " eu-west-1a ", "instance_type": "g5.xlarge"}`
+	req, _ := http.NewRequest("PUT", "/api/runner-hosts/runner-1", strings.NewReader(body))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockDB.AssertExpectations(t)
+}
+
+func TestSaveRunnerHost_Invalid(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.PUT("/api/runner-hosts/:name", handler.SaveRunnerHost())
+
+	tests := []struct {
+		name string
+		host string
+		body string
+	}{
+		{"invalid name", "runner%201", `{}`},
+		{"zone too long", "runner-1", `{"zone": "` + strings.Repeat("a", 65) + `"}`},
+		{"malformed body", "runner-1", `{`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("PUT", "/api/runner-hosts/"+tt.host, strings.NewReader(tt.body))
+			router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+	mockDB.AssertNotCalled(t, "SaveRunnerHost", mock.Anything, mock.Anything)
+}
+
+func TestDeleteRunnerHost_NotFound(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.DELETE("/api/runner-hosts/:name", handler.DeleteRunnerHost())
+
+	mockDB.On("DeleteRunnerHost", mock.Anything, "runner-1").Return(false, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/api/runner-hosts/runner-1", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestRunnerHeartbeat(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	testConfig.Vars.RunnerHeartbeatToken = "secret"
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.POST("/api/runner-hosts/heartbeat", RequireRunnerHeartbeatToken(testConfig), handler.RunnerHeartbeat())
+
+	mockDB.On("RecordRunnerHeartbeat", mock.Anything, models.RunnerHost{Name: "runner-1", Labels: []string{}, Zone: "eu-west-1a"}).Return(nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/runner-hosts/heartbeat", strings.NewReader(`{"name": "runner-1", "zone": "eu-west-1a"}`))
+	req.Header.Set("Authorization", "Bearer secret")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/runner-hosts/heartbeat", strings.NewReader(`{"name": "runner-1"}`))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	mockDB.AssertNumberOfCalls(t, "RecordRunnerHeartbeat", 1)
+}
+
+func TestGetRunnerHostAnalytics(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/analytics/runner-hosts", handler.GetRunnerHostAnalytics())
+
+	mockDB.On("GetRunnerHostStats", mock.Anything, 7*24*time.Hour, "instance_type").Return([]models.RunnerHostStats{
+		{Key: "g5.xlarge", RegisteredHosts: 4, ActiveHosts: 3, Started: 120, BusyMinutes: 20160, AvgBusyRunners: 2, Utilization: 50},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/analytics/runner-hosts?period=week&by=instance_type", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		By     string                   `json:"by"`
+		Slices []models.RunnerHostStats `json:"slices"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "instance_type", response.By)
+	require.Len(t, response.Slices, 1)
+	assert.Equal(t, 50.0, response.Slices[0].Utilization)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/analytics/runner-hosts?by=region", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
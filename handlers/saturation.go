@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxSaturationPoints bounds the points returned per label; longer series
+	// keep the most saturated sample of each time slice.
+	maxSaturationPoints = 500
+	// maxSaturationSampleGap caps how long one sample counts towards the time
+	// above the threshold, so gaps while the server was down are not counted.
+	// It is twice the longest metrics update interval.
+	maxSaturationSampleGap = 2 * maxMetricsIntervalSeconds * time.Second
+)
+
+// GetSaturationAnalytics returns, per runner label, the demand on the label
+// next to the runners available to it over a period (default: day), with the
+// resulting saturation series and how long saturation stayed at or above the
+// threshold percentage (default: 90).
+func (h *APIHandler) GetSaturationAnalytics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		period := c.DefaultQuery("period", "day")
+		threshold, err := strconv.Atoi(c.DefaultQuery("threshold", "90"))
+		if err != nil || threshold < 1 || threshold > 1000 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "threshold must be between 1 and 1000"})
+			return
+		}
+
+		since := periodToDuration(period)
+		samples, err := h.db.GetLabelCapacitySamples(c.Request.Context(), since, c.Query("label"))
+		if err != nil {
+			logger.Logger.Error("Failed to get label capacity samples", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve saturation analytics"})
+			return
+		}
+
+		labels := []models.LabelSaturation{}
+		for start := 0; start < len(samples); {
+			end := start
+			for end < len(samples) && samples[end].Label == samples[start].Label {
+				end++
+			}
+			labels = append(labels, summarizeSaturation(samples[start:end], float64(threshold), since))
+			start = end
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"period":    period,
+			"threshold": threshold,
+			"labels":    labels,
+		})
+	}
+}
+
+// summarizeSaturation summarizes one label's samples, oldest first, and
+// downsamples them to at most maxSaturationPoints points over the window.
+func summarizeSaturation(samples []models.LabelCapacitySample, threshold float64, window time.Duration) models.LabelSaturation {
+	summary := models.LabelSaturation{
+		Label:    samples[0].Label,
+		Capacity: samples[len(samples)-1].Capacity,
+	}
+
+	points := make([]models.SaturationPoint, len(samples))
+	total, known := 0.0, 0
+	for i, s := range samples {
+		points[i] = models.SaturationPoint{
+			Timestamp: s.Timestamp.Unix(),
+			Running:   s.Running,
+			Queued:    s.Queued,
+			Capacity:  s.Capacity,
+		}
+		if s.Capacity <= 0 {
+			continue
+		}
+		saturation := float64(s.Running+s.Queued) / float64(s.Capacity) * 100
+		points[i].Saturation = &saturation
+
+		total += saturation
+		known++
+		if summary.PeakSaturation == nil || saturation > *summary.PeakSaturation {
+			summary.PeakSaturation = &saturation
+		}
+		if saturation >= threshold && i+1 < len(samples) {
+			gap := min(samples[i+1].Timestamp.Sub(s.Timestamp), maxSaturationSampleGap)
+			summary.MinutesAboveThreshold += gap.Minutes()
+		}
+	}
+	if known > 0 {
+		avg := total / float64(known)
+		summary.AvgSaturation = &avg
+	}
+
+	summary.Points = downsampleSaturation(points, window)
+	return summary
+}
+
+// downsampleSaturation keeps the most saturated point of each of
+// maxSaturationPoints equal slices of the window, or the one with the most
+// demand when the capacity is unknown.
+func downsampleSaturation(points []models.SaturationPoint, window time.Duration) []models.SaturationPoint {
+	slice := int64(window.Seconds()) / maxSaturationPoints
+	if len(points) <= maxSaturationPoints || slice <= 0 {
+		return points
+	}
+
+	kept := []models.SaturationPoint{}
+	for _, p := range points {
+		n := len(kept)
+		if n == 0 || p.Timestamp/slice != kept[n-1].Timestamp/slice {
+			kept = append(kept, p)
+			continue
+		}
+		if moreSaturated(p, kept[n-1]) {
+			kept[n-1] = p
+		}
+	}
+	return kept
+}
+
+func moreSaturated(a, b models.SaturationPoint) bool {
+	if a.Saturation != nil && b.Saturation != nil {
+		return *a.Saturation > *b.Saturation
+	}
+	if a.Saturation != nil || b.Saturation != nil {
+		return a.Saturation != nil
+	}
+	return a.Running+a.Queued > b.Running+b.Queued
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSaturationAnalytics(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/analytics/saturation", handler.GetSaturationAnalytics())
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	mockDB.On("GetLabelCapacitySamples", mock.Anything, periodToDuration("day"), "").Return([]models.LabelCapacitySample{
+		{Label: "gpu", Timestamp: start, Running: 1},
+		{Label: "linux", Timestamp: start, Running: 5, Queued: 0, Capacity: 10},
+		{Label: "linux", Timestamp: start.Add(time.Minute), Running: 10, Queued: 2, Capacity: 10},
+		{Label: "linux", Timestamp: start.Add(2 * time.Minute), Running: 9, Queued: 0, Capacity: 10},
+		// The server was down for an hour
+		{Label: "linux", Timestamp: start.Add(62 * time.Minute), Running: 2, Queued: 0, Capacity: 10},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/analytics/saturation", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Threshold int                      `json:"threshold"`
+		Labels    []models.LabelSaturation `json:"labels"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 90, response.Threshold)
+	require.Len(t, response.Labels, 2)
+
+	gpu := response.Labels[0]
+	assert.Equal(t, "gpu", gpu.Label)
+	assert.Nil(t, gpu.PeakSaturation, "capacity unknown")
+	assert.Nil(t, gpu.Points[0].Saturation)
+
+	linux := response.Labels[1]
+	assert.Equal(t, 10, linux.Capacity)
+	require.NotNil(t, linux.PeakSaturation)
+	assert.InDelta(t, 120, *linux.PeakSaturation, 0.001)
+	assert.InDelta(t, 70, *linux.AvgSaturation, 0.001)
+	assert.InDelta(t, 11, linux.MinutesAboveThreshold, 0.001, "one minute at 120% plus the gap after 90%, capped")
+	assert.Len(t, linux.Points, 4)
+}
+
+func TestGetSaturationAnalytics_Errors(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/analytics/saturation", handler.GetSaturationAnalytics())
+
+	for _, threshold := range []string{"0", "1001", "high"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/analytics/saturation?threshold="+threshold, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, threshold)
+	}
+
+	mockDB.On("GetLabelCapacitySamples", mock.Anything, periodToDuration("week"), "gpu").Return([]models.LabelCapacitySample(nil), errors.New("db error"))
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/analytics/saturation?period=week&label=gpu", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestDownsampleSaturation(t *testing.T) {
+	saturation := func(v float64) *float64 { return &v }
+	var points []models.SaturationPoint
+	for i := 0; i < 3*maxSaturationPoints; i++ {
+		points = append(points, models.SaturationPoint{Timestamp: int64(i * 60), Saturation: saturation(float64(i % 3))})
+	}
+
+	kept := downsampleSaturation(points, time.Duration(len(points))*time.Minute)
+
+	require.Len(t, kept, maxSaturationPoints)
+	for _, p := range kept {
+		assert.Equal(t, 2.0, *p.Saturation, "each slice keeps its peak")
+	}
+}
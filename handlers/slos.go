@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gateixeira/live-actions/internal/config"
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/internal/services"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type sloRequest struct {
+	Repository            string  `json:"repository"`
+	Workflow              string  `json:"workflow"`
+	SuccessTarget         float64 `json:"success_target"`
+	DurationPercentile    int     `json:"duration_percentile"`
+	DurationTargetSeconds int     `json:"duration_target_seconds"`
+	WindowDays            int     `json:"window_days"`
+}
+
+// GetSLOs lists every workflow SLO with its compliance, remaining error
+// budget and burn rates.
+func (h *APIHandler) GetSLOs() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		var statuses []models.SLOStatus
+		err := h.db.ReadSnapshot(ctx, func(db database.DatabaseInterface) error {
+			var err error
+			statuses, err = services.SLOStatuses(ctx, h.config, db)
+			return err
+		})
+		if err != nil {
+			logger.Logger.Error("Failed to evaluate workflow SLOs", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve SLOs"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"slos": statuses})
+	}
+}
+
+// SaveSLO creates or replaces a workflow SLO.
+func (h *APIHandler) SaveSLO() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Param("name")
+		if !tagPattern.MatchString(name) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "SLO name must be 1-32 lowercase letters, digits, '-' or '_'"})
+			return
+		}
+		if _, ok := h.config.Vars.WorkflowSLOs[name]; ok {
+			c.JSON(http.StatusConflict, gin.H{"error": "SLO is defined in configuration"})
+			return
+		}
+
+		var req sloRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		slo := models.WorkflowSLO{
+			Name:                  name,
+			Repository:            strings.TrimSpace(req.Repository),
+			Workflow:              strings.TrimSpace(req.Workflow),
+			SuccessTarget:         req.SuccessTarget,
+			DurationPercentile:    req.DurationPercentile,
+			DurationTargetSeconds: req.DurationTargetSeconds,
+			WindowDays:            req.WindowDays,
+			Source:                "api",
+		}
+		if slo.WindowDays == 0 {
+			slo.WindowDays = config.DefaultSLOWindowDays
+		}
+		if err := config.ValidateWorkflowSLO(slo); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid SLO: " + err.Error()})
+			return
+		}
+
+		if err := h.db.SaveWorkflowSLO(c.Request.Context(), slo); err != nil {
+			logger.Logger.Error("Failed to save workflow SLO", zap.String("name", name), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save SLO"})
+			return
+		}
+
+		SendConfigChanged(ConfigSLOs)
+		c.JSON(http.StatusOK, slo)
+	}
+}
+
+// DeleteSLO removes a workflow SLO managed through the API.
+func (h *APIHandler) DeleteSLO() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Param("name")
+		if _, ok := h.config.Vars.WorkflowSLOs[name]; ok {
+			c.JSON(http.StatusConflict, gin.H{"error": "SLO is defined in configuration"})
+			return
+		}
+
+		deleted, err := h.db.DeleteWorkflowSLO(c.Request.Context(), name)
+		if err != nil {
+			logger.Logger.Error("Failed to delete workflow SLO", zap.String("name", name), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete SLO"})
+			return
+		}
+		if !deleted {
+			c.JSON(http.StatusNotFound, gin.H{"error": "SLO not found"})
+			return
+		}
+
+		SendConfigChanged(ConfigSLOs)
+		c.Status(http.StatusNoContent)
+	}
+}
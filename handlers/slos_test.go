@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSLOs(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	testConfig.Vars.WorkflowSLOs = map[string]models.WorkflowSLO{
+		"deploy": {Name: "deploy", Repository: "app", Workflow: "Deploy", SuccessTarget: 90, WindowDays: 7, Source: "config"},
+	}
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/slo", handler.GetSLOs())
+
+	mockDB.On("GetWorkflowSLOs", mock.Anything).Return([]models.WorkflowSLO{}, nil)
+	mockDB.On("GetSLORuns", mock.Anything, "app", "Deploy", 7*24*time.Hour).Return([]models.SLORun{
+		{Conclusion: "success", CompletedAt: time.Now().Add(-time.Hour), Seconds: 60},
+		{Conclusion: "failure", CompletedAt: time.Now().Add(-time.Hour), Seconds: 60},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/slo", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		SLOs []models.SLOStatus `json:"slos"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.SLOs, 1)
+	assert.Equal(t, "deploy", response.SLOs[0].SLO.Name)
+	require.Len(t, response.SLOs[0].Objectives, 1)
+	objective := response.SLOs[0].Objectives[0]
+	assert.Equal(t, 2, objective.Total)
+	assert.False(t, objective.Met)
+	assert.InDelta(t, -4.0, objective.ErrorBudgetRemaining, 0.001)
+}
+
+func TestSaveSLO(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		body           string
+		expectSave     bool
+		expectedStatus int
+	}{
+		{"Valid SLO", "/api/slo/ci", `{"repository": " app ", "workflow": "CI", "success_target": 95, "duration_percentile": 90, "duration_target_seconds": 900}`, true, http.StatusOK},
+		{"Invalid name", "/api/slo/CI!", `{"repository": "app", "workflow": "CI", "success_target": 95}`, false, http.StatusBadRequest},
+		{"No objective", "/api/slo/ci", `{"repository": "app", "workflow": "CI"}`, false, http.StatusBadRequest},
+		{"Target out of range", "/api/slo/ci", `{"repository": "app", "workflow": "CI", "success_target": 100}`, false, http.StatusBadRequest},
+		{"Configured SLO", "/api/slo/deploy", `{"repository": "app", "workflow": "CI", "success_target": 95}`, false, http.StatusConflict},
+		{"Invalid body", "/api/slo/ci", `not json`, false, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, mockDB, testConfig := setupAPITest()
+			testConfig.Vars.WorkflowSLOs = map[string]models.WorkflowSLO{"deploy": {Name: "deploy"}}
+			if tt.expectSave {
+				mockDB.On("SaveWorkflowSLO", mock.Anything, models.WorkflowSLO{
+					Name: "ci", Repository: "app", Workflow: "CI", SuccessTarget: 95,
+					DurationPercentile: 90, DurationTargetSeconds: 900, WindowDays: 28, Source: "api",
+				}).Return(nil)
+			}
+			handler := NewAPIHandler(testConfig, mockDB)
+			router.PUT("/api/slo/:name", handler.SaveSLO())
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("PUT", tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeleteSLO(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	testConfig.Vars.WorkflowSLOs = map[string]models.WorkflowSLO{"deploy": {Name: "deploy"}}
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.DELETE("/api/slo/:name", handler.DeleteSLO())
+
+	mockDB.On("DeleteWorkflowSLO", mock.Anything, "ci").Return(true, nil)
+	mockDB.On("DeleteWorkflowSLO", mock.Anything, "missing").Return(false, nil)
+
+	for path, status := range map[string]int{
+		"/api/slo/ci":      http.StatusNoContent,
+		"/api/slo/missing": http.StatusNotFound,
+		"/api/slo/deploy":  http.StatusConflict,
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", path, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code, path)
+	}
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultSparklinePoints = 30
+	minSparklinePoints     = 5
+	maxSparklinePoints     = 120
+)
+
+// GetSparklines returns small fixed-size running, queued and failure rate
+// series for the period, cheap enough to render on every list row.
+func (h *APIHandler) GetSparklines() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		points := defaultSparklinePoints
+		if p := c.Query("points"); p != "" {
+			parsed, err := strconv.Atoi(p)
+			if err != nil || parsed < minSparklinePoints || parsed > maxSparklinePoints {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "points must be between 5 and 120"})
+				return
+			}
+			points = parsed
+		}
+
+		since := periodToDuration(c.DefaultQuery("period", "day"))
+		bucket := (since / time.Duration(points)).Truncate(time.Second)
+
+		// Align buckets so the last one contains the current time
+		end := h.config.Now().Truncate(bucket).Add(bucket)
+		start := end.Add(-bucket * time.Duration(points))
+
+		sparklines, err := h.db.GetSparklines(c.Request.Context(), start, bucket, points)
+		if err != nil {
+			logger.Logger.Error("Failed to get sparklines", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve sparklines"})
+			return
+		}
+
+		c.JSON(http.StatusOK, sparklines)
+	}
+}
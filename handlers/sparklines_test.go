@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSparklines(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/metrics/sparklines", handler.GetSparklines())
+
+	var start time.Time
+	mockDB.On("GetSparklines", mock.Anything, mock.Anything, 2*time.Minute, 30).
+		Run(func(args mock.Arguments) { start = args.Get(1).(time.Time) }).
+		Return(&models.Sparklines{BucketSeconds: 120, Running: make([]float64, 30)}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/metrics/sparklines?period=hour", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var sparklines models.Sparklines
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sparklines))
+	assert.Len(t, sparklines.Running, 30)
+
+	end := start.Add(time.Hour)
+	assert.True(t, end.After(time.Now()), "the last bucket should contain the current time")
+	assert.True(t, end.Add(-2*time.Minute).Before(time.Now()))
+}
+
+func TestGetSparklines_Points(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/metrics/sparklines", handler.GetSparklines())
+
+	mockDB.On("GetSparklines", mock.Anything, mock.Anything, 2*time.Hour, 12).Return(&models.Sparklines{}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/metrics/sparklines?points=12", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockDB.AssertExpectations(t)
+}
+
+func TestGetSparklines_InvalidPoints(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/metrics/sparklines", handler.GetSparklines())
+
+	for _, points := range []string{"abc", "4", "121"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/metrics/sparklines?points="+points, nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, "points=%s", points)
+	}
+	mockDB.AssertNotCalled(t, "GetSparklines", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetSparklines_DatabaseError(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/metrics/sparklines", handler.GetSparklines())
+
+	mockDB.On("GetSparklines", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("db error"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/metrics/sparklines", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -2,11 +2,19 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/gateixeira/live-actions/internal/anonymize"
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/internal/utils"
 	"github.com/gateixeira/live-actions/models"
 	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/gateixeira/live-actions/pkg/metrics"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
@@ -15,13 +23,73 @@ import (
 type SSEEvent struct {
 	Type string      `json:"type"`
 	Data interface{} `json:"data"`
+	// Repo is the owner/name of the repository the event belongs to. It is
+	// used for routing to per-repository subscribers and is empty for global
+	// events such as metrics updates.
+	Repo string `json:"-"`
+	// RunID is the workflow run the event belongs to, used to route run and
+	// job updates to live tail subscribers.
+	RunID int64 `json:"-"`
+	// Broadcast events reach per-repository subscribers too, though not live
+	// tails of a single run.
+	Broadcast bool `json:"-"`
+}
+
+// sseSubscriber is a single connected SSE client. A non-empty repo restricts
+// the client to events for that repository; a non-zero runID restricts it to
+// updates for that run and its jobs.
+type sseSubscriber struct {
+	repo   string
+	runID  int64
+	events chan SSEEvent
+}
+
+// wants reports whether the subscriber should receive the event.
+func (s *sseSubscriber) wants(event SSEEvent) bool {
+	if s.runID != 0 {
+		return event.RunID == s.runID
+	}
+	if s.repo == "" || event.Broadcast {
+		return true
+	}
+	return event.Repo != "" && event.Repo == s.repo
 }
 
 // SSEHandler handles server-sent events
 type SSEHandler struct {
-	client chan SSEEvent
+	client       chan SSEEvent
+	mutex        sync.RWMutex
+	subscribers  map[*sseSubscriber]struct{}
+	dispatchOnce sync.Once
+	// coalesce is the window in nanoseconds over which metrics updates are
+	// merged per client, sending only the latest. Zero sends every update.
+	coalesce atomic.Int64
+	// maxClients caps concurrent streams; zero is unlimited. Clients beyond
+	// it are turned away according to overflowMode.
+	maxClients   int
+	overflowMode string
+	retryAfter   time.Duration
+	// anonymizer pseudonymizes names in events; nil sends them as they are.
+	anonymizer *anonymize.Anonymizer
+	// degradation returns the current degradation warnings, sent to clients
+	// as they connect; nil sends none.
+	degradation func() []models.Warning
+	// closing is closed by Shutdown to end every stream, after telling the
+	// client the server is restarting and how long it expects to be down.
+	closing          chan struct{}
+	closeOnce        sync.Once
+	expectedDowntime time.Duration
 }
 
+// Ways of turning away clients beyond the connection limit.
+const (
+	// SSEOverflowReject answers 503 with a Retry-After header.
+	SSEOverflowReject = "reject"
+	// SSEOverflowPoll answers with a short stream holding a poll event and a
+	// retry interval, so EventSource reconnects at a low frequency.
+	SSEOverflowPoll = "poll"
+)
+
 // Global SSE handler instance
 var (
 	sseHandler *SSEHandler
@@ -42,48 +110,98 @@ func GetSSEHandler() *SSEHandler {
 }
 
 func (h *SSEHandler) SendEvent(eventType string, data interface{}) {
+	h.publish(SSEEvent{Type: eventType, Data: data})
+}
+
+// sendRepoEvent sends an event scoped to a repository so that per-repository
+// subscribers receive it as well.
+func (h *SSEHandler) sendRepoEvent(eventType string, data interface{}, repo string) {
+	h.publish(SSEEvent{Type: eventType, Data: data, Repo: repo})
+}
+
+func (h *SSEHandler) publish(event SSEEvent) {
 	if h == nil || h.client == nil {
 		return
 	}
-
-	event := SSEEvent{
-		Type: eventType,
-		Data: data,
-	}
+	eventType := event.Type
 
 	select {
 	case h.client <- event:
-		logger.Logger.Debug("SSE event sent", zap.String("type", eventType))
+		logger.Module("sse").Debug("SSE event sent", zap.String("type", eventType))
 	default:
-		logger.Logger.Debug("SSE channel full, dropping event", zap.String("type", eventType))
+		logger.Module("sse").Debug("SSE channel full, dropping event", zap.String("type", eventType))
 	}
 }
 
+// subscribe registers a new client and returns its subscription.
+func (h *SSEHandler) subscribe(repo string) *sseSubscriber {
+	return h.addSubscriber(&sseSubscriber{repo: repo})
+}
+
+// subscribeRun registers a client interested in a single workflow run.
+func (h *SSEHandler) subscribeRun(runID int64) *sseSubscriber {
+	return h.addSubscriber(&sseSubscriber{runID: runID})
+}
+
+// addSubscriber registers the client, or returns nil when the connection
+// limit is reached.
+func (h *SSEHandler) addSubscriber(sub *sseSubscriber) *sseSubscriber {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.maxClients > 0 && len(h.subscribers) >= h.maxClients {
+		return nil
+	}
+	if h.subscribers == nil {
+		h.subscribers = make(map[*sseSubscriber]struct{})
+	}
+	sub.events = make(chan SSEEvent, 100)
+	h.subscribers[sub] = struct{}{}
+	return sub
+}
+
+// unsubscribe removes a client. Once it returns no more events are delivered to it.
+func (h *SSEHandler) unsubscribe(sub *sseSubscriber) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	delete(h.subscribers, sub)
+}
+
+// dispatch fans every event from the shared channel out to the connected
+// clients that want it.
+func (h *SSEHandler) dispatch() {
+	for event := range h.client {
+		h.mutex.RLock()
+		for sub := range h.subscribers {
+			if !sub.wants(event) {
+				continue
+			}
+			select {
+			case sub.events <- event:
+			default:
+				// Client channel full, skip this event
+			}
+		}
+		h.mutex.RUnlock()
+	}
+}
+
+// HandleSSE streams events to the client. The optional repo query parameter
+// (owner/name) limits the stream to that repository's workflow updates.
 func (h *SSEHandler) HandleSSE() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		h.dispatchOnce.Do(func() {
+			go h.dispatch()
+		})
 
-		c.Header("Content-Type", "text/event-stream")
-		c.Header("Cache-Control", "no-cache")
-		c.Header("Connection", "keep-alive")
-
-		clientChan := make(chan SSEEvent, 100)
-
-		go func() {
-			for {
-				select {
-				case event := <-h.client:
-					select {
-					case clientChan <- event:
-					default:
-						// Client channel full, skip this event
-					}
-				case <-c.Request.Context().Done():
-					// Client disconnected
-					close(clientChan)
-					return
-				}
-			}
-		}()
+		sub := h.subscribe(c.Query("repo"))
+		if sub == nil {
+			h.overflow(c)
+			return
+		}
+		defer h.unsubscribe(sub)
+
+		setSSEHeaders(c)
 
 		// Send initial connection event
 		c.SSEvent("message", map[string]interface{}{
@@ -93,41 +211,264 @@ func (h *SSEHandler) HandleSSE() gin.HandlerFunc {
 				"timestamp": time.Now().Format(time.RFC3339),
 			},
 		})
+		c.Writer.Flush()
 
-		// Keep connection alive and send events
-		for {
-			select {
-			case event, ok := <-clientChan:
-				if !ok {
-					// Channel closed, client disconnected
-					return
-				}
+		// Sent even when empty, so a reconnecting dashboard drops a banner
+		// for a degradation that cleared while it was away
+		if h.degradation != nil {
+			warnings := h.degradation()
+			if warnings == nil {
+				warnings = []models.Warning{}
+			}
+			h.writeEvent(c, SSEEvent{Type: "degraded", Data: gin.H{"warnings": warnings}})
+		}
 
-				jsonData, err := json.Marshal(event)
-				if err != nil {
-					logger.Logger.Error("Failed to marshal SSE event", zap.Error(err))
-					continue
-				}
+		h.stream(c, sub, nil)
+	}
+}
 
-				c.SSEvent("message", string(jsonData))
-				c.Writer.Flush()
+// HandleRunSSE streams updates for a single workflow run and its jobs. The
+// stream starts with a run_snapshot event holding the current run and jobs and
+// finishes with an end event once the run reaches a terminal state, so clients
+// should close their EventSource on end instead of reconnecting.
+func (h *SSEHandler) HandleRunSSE(db database.DatabaseInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		runID, err := strconv.ParseInt(c.Param("run_id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid run_id format"})
+			return
+		}
 
-			case <-c.Request.Context().Done():
-				// Client disconnected
-				logger.Logger.Debug("SSE client disconnected")
-				return
+		h.dispatchOnce.Do(func() {
+			go h.dispatch()
+		})
+
+		// Subscribe before loading the snapshot so no update falls in between
+		sub := h.subscribeRun(runID)
+		if sub == nil {
+			h.overflow(c)
+			return
+		}
+		defer h.unsubscribe(sub)
+
+		ctx := c.Request.Context()
+		run, err := db.GetWorkflowRunByID(ctx, runID)
+		if err != nil {
+			logger.Logger.Error("Failed to get workflow run for live tail", zap.Int64("run_id", runID), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve workflow run"})
+			return
+		}
+		if run == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Workflow run not found"})
+			return
+		}
+		jobs, err := db.GetWorkflowJobsByRunID(ctx, runID)
+		if err != nil {
+			logger.Logger.Error("Failed to get workflow jobs for live tail", zap.Int64("run_id", runID), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve workflow jobs"})
+			return
+		}
+
+		setSSEHeaders(c)
+		h.writeEvent(c, SSEEvent{
+			Type: "run_snapshot",
+			Data: gin.H{"workflow_run": run, "workflow_jobs": jobs},
+		})
+
+		if !isTerminalRunStatus(run.Status) {
+			h.stream(c, sub, func(event SSEEvent) bool {
+				update, ok := event.Data.(models.WorkflowUpdateEvent)
+				return ok && update.Type == "run" && isTerminalRunStatus(update.WorkflowRun.Status)
+			})
+		}
+		// A stream ended by a restart is not the end of the run
+		if ctx.Err() == nil && !h.shuttingDown() {
+			h.writeEvent(c, SSEEvent{Type: "end", Data: gin.H{"run_id": runID}})
+		}
+	}
+}
+
+func setSSEHeaders(c *gin.Context) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+}
+
+func (h *SSEHandler) writeEvent(c *gin.Context, event SSEEvent) {
+	jsonData, err := json.Marshal(event)
+	if err != nil {
+		logger.Logger.Error("Failed to marshal SSE event", zap.Error(err))
+		return
+	}
+	jsonData = h.anonymizer.JSON(jsonData)
+
+	c.SSEvent("message", string(jsonData))
+	c.Writer.Flush()
+}
+
+// SetCoalesceWindow sets how long metrics updates are held per client so
+// bursts collapse into the latest one. Zero disables coalescing.
+func (h *SSEHandler) SetCoalesceWindow(window time.Duration) {
+	h.coalesce.Store(int64(window))
+}
+
+// CoalesceWindow returns the current metrics update coalescing window.
+func (h *SSEHandler) CoalesceWindow() time.Duration {
+	return time.Duration(h.coalesce.Load())
+}
+
+// SetClientLimit caps the number of concurrent event streams. Clients beyond
+// limit are turned away with mode, asked to come back after retryAfter. Zero
+// limit removes it.
+func (h *SSEHandler) SetClientLimit(limit int, mode string, retryAfter time.Duration) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.maxClients = limit
+	h.overflowMode = mode
+	h.retryAfter = retryAfter
+}
+
+// SetAnonymizer makes every stream replace repository, owner and user names
+// and run titles with the anonymizer's pseudonyms. Call it before serving.
+func (h *SSEHandler) SetAnonymizer(a *anonymize.Anonymizer) {
+	h.anonymizer = a
+}
+
+// SetDegradation makes every new stream start with a degraded event holding
+// the warnings returned by warnings, so dashboards opened while a subsystem is
+// degraded show the banner without waiting for the next change, and those
+// reconnecting after it cleared hide it. Call it
+// before serving.
+func (h *SSEHandler) SetDegradation(warnings func() []models.Warning) {
+	h.degradation = warnings
+}
+
+// closingChan returns the channel Shutdown closes.
+func (h *SSEHandler) closingChan() chan struct{} {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if h.closing == nil {
+		h.closing = make(chan struct{})
+	}
+	return h.closing
+}
+
+// shuttingDown reports whether Shutdown has been called.
+func (h *SSEHandler) shuttingDown() bool {
+	select {
+	case <-h.closingChan():
+		return true
+	default:
+		return false
+	}
+}
+
+// Shutdown sends every connected client a server_restarting event announcing
+// the expected downtime and ends its stream, so dashboards can show that they
+// are reconnecting instead of freezing while the server restarts. Clients are
+// asked to wait out the downtime before reconnecting.
+func (h *SSEHandler) Shutdown(expectedDowntime time.Duration) {
+	closing := h.closingChan()
+	h.closeOnce.Do(func() {
+		h.mutex.Lock()
+		h.expectedDowntime = expectedDowntime
+		clients := len(h.subscribers)
+		h.mutex.Unlock()
 
-			case <-time.After(30 * time.Second):
-				// Send keepalive ping
-				c.SSEvent("ping", map[string]string{
-					"timestamp": time.Now().Format(time.RFC3339),
-				})
-				c.Writer.Flush()
+		logger.Module("sse").Info("Closing event streams for shutdown", zap.Int("clients", clients))
+		close(closing)
+	})
+}
+
+// writeRestarting tells the client the server is going away and when to
+// reconnect.
+func (h *SSEHandler) writeRestarting(c *gin.Context) {
+	h.mutex.RLock()
+	downtime := h.expectedDowntime
+	h.mutex.RUnlock()
+
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", downtime.Milliseconds())
+	h.writeEvent(c, SSEEvent{Type: "server_restarting", Data: models.ServerRestartingEvent{
+		ExpectedDowntimeSeconds: int(downtime.Seconds()),
+		Timestamp:               time.Now().Format(time.RFC3339),
+	}})
+}
+
+// overflow turns away a client that arrived while the connection limit was
+// reached.
+func (h *SSEHandler) overflow(c *gin.Context) {
+	h.mutex.RLock()
+	mode, retryAfter := h.overflowMode, h.retryAfter
+	h.mutex.RUnlock()
+
+	if mode == "" {
+		mode = SSEOverflowReject
+	}
+	seconds := max(int(retryAfter/time.Second), 1)
+	metrics.GetRegistry().RecordSSEOverflow(mode)
+	logger.Module("sse").Debug("SSE client limit reached", zap.String("mode", mode))
+
+	if mode == SSEOverflowPoll {
+		setSSEHeaders(c)
+		fmt.Fprintf(c.Writer, "retry: %d\n\n", seconds*1000)
+		h.writeEvent(c, SSEEvent{Type: "poll", Data: gin.H{"interval_seconds": seconds}})
+		return
+	}
+
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Too many event stream clients, retry later"})
+}
+
+// stream forwards the subscriber's events to the client until it disconnects,
+// the server shuts down or, when last is non-nil, until an event for which
+// last returns true has been sent.
+func (h *SSEHandler) stream(c *gin.Context, sub *sseSubscriber, last func(SSEEvent) bool) {
+	var pending *SSEEvent
+	var flush <-chan time.Time
+	closing := h.closingChan()
+
+	for {
+		select {
+		case event := <-sub.events:
+			if window := h.CoalesceWindow(); window > 0 && event.Type == "metrics_update" {
+				if pending == nil {
+					flush = time.After(window)
+				}
+				pending = &event
+				continue
 			}
+			h.writeEvent(c, event)
+			if last != nil && last(event) {
+				return
+			}
+
+		case <-flush:
+			h.writeEvent(c, *pending)
+			pending, flush = nil, nil
+
+		case <-closing:
+			h.writeRestarting(c)
+			return
+
+		case <-c.Request.Context().Done():
+			// Client disconnected
+			logger.Module("sse").Debug("SSE client disconnected")
+			return
+
+		case <-time.After(30 * time.Second):
+			// Send keepalive ping
+			c.SSEvent("ping", map[string]string{
+				"timestamp": time.Now().Format(time.RFC3339),
+			})
+			c.Writer.Flush()
 		}
 	}
 }
 
+func isTerminalRunStatus(status models.JobStatus) bool {
+	return status == models.JobStatusCompleted || status == models.JobStatusCancelled
+}
+
 // SendMetricsUpdate sends a metrics update event
 func SendMetricsUpdate(update models.MetricsUpdateEvent) {
 	if sseHandler != nil {
@@ -137,7 +478,80 @@ func SendMetricsUpdate(update models.MetricsUpdateEvent) {
 
 // SendWorkflowUpdate sends a workflow update event
 func SendWorkflowUpdate(update models.WorkflowUpdateEvent) {
-	if sseHandler != nil {
-		sseHandler.SendEvent("workflow_update", update)
+	if sseHandler == nil {
+		return
+	}
+
+	runID := update.WorkflowRun.ID
+	repo := utils.RepoFullName(update.WorkflowRun.HtmlUrl, update.WorkflowRun.RepositoryName)
+	if update.Type == "job" {
+		// Job updates carry no run, but the job's URL names its repository
+		runID = update.WorkflowJob.RunID
+		repo = utils.RepoFullName(update.WorkflowJob.HtmlUrl, "")
+	}
+	sseHandler.publish(SSEEvent{
+		Type:  "workflow_update",
+		Data:  update,
+		Repo:  repo,
+		RunID: runID,
+	})
+}
+
+// Kinds of reference data announced by config_changed events.
+const (
+	ConfigRepoGroups  = "repo_groups"
+	ConfigMutes       = "mutes"
+	ConfigRunnerHosts = "runner_hosts"
+	ConfigSettings    = "settings"
+	ConfigSLOs        = "slos"
+	ConfigJobNames    = "job_names"
+)
+
+// SendConfigChanged tells every open dashboard that reference data of the
+// given kind changed, so it refetches it instead of showing stale groupings
+// until reloaded.
+func SendConfigChanged(kind string) {
+	if sseHandler == nil {
+		return
+	}
+
+	sseHandler.publish(SSEEvent{
+		Type: "config_changed",
+		Data: models.ConfigChangedEvent{
+			Kind:      kind,
+			Timestamp: time.Now().Format(time.RFC3339),
+		},
+		Broadcast: true,
+	})
+}
+
+// SendDegradation tells every open dashboard which subsystems are degraded,
+// so it can show a "data may be delayed" banner. An empty list clears it.
+func SendDegradation(warnings []models.Warning) {
+	if sseHandler == nil {
+		return
+	}
+	if warnings == nil {
+		warnings = []models.Warning{}
 	}
+
+	sseHandler.publish(SSEEvent{
+		Type:      "degraded",
+		Data:      gin.H{"warnings": warnings},
+		Broadcast: true,
+	})
+}
+
+// SendAlert sends an alert to dashboard clients
+func SendAlert(alert models.Alert) {
+	if sseHandler == nil {
+		return
+	}
+
+	sseHandler.publish(SSEEvent{
+		Type:  "alert",
+		Data:  alert,
+		Repo:  utils.RepoFullName(alert.HtmlUrl, alert.Repository),
+		RunID: alert.RunID,
+	})
 }
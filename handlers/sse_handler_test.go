@@ -6,14 +6,18 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/gateixeira/live-actions/internal/anonymize"
+	"github.com/gateixeira/live-actions/internal/database"
 	"github.com/gateixeira/live-actions/models"
 	"github.com/gateixeira/live-actions/pkg/logger"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -187,6 +191,34 @@ func TestSSEHandler_HandleSSE_InitialConnectionEvent(t *testing.T) {
 	assert.Contains(t, body, "SSE connection established", "Response should contain connection message")
 }
 
+func TestSSEHandler_HandleSSE_InitialDegradation(t *testing.T) {
+	setupSSETest()
+
+	handler := &SSEHandler{
+		client: make(chan SSEEvent, 10),
+	}
+	handler.SetDegradation(func() []models.Warning {
+		return []models.Warning{{Code: "event_backlog", Message: "40 webhook events are waiting to be processed; data may be delayed."}}
+	})
+
+	router := gin.New()
+	router.GET("/events", handler.HandleSSE())
+
+	req, _ := http.NewRequest("GET", "/events", nil)
+	w := httptest.NewRecorder()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	req = req.WithContext(ctx)
+
+	router.ServeHTTP(w, req)
+
+	body := w.Body.String()
+	assert.Contains(t, body, `"type":"degraded"`, "new clients receive the current degradation")
+	assert.Contains(t, body, "40 webhook events")
+	assert.Less(t, strings.Index(body, "connected"), strings.Index(body, "degraded"))
+}
+
 func TestSSEHandler_HandleSSE_EventForwarding(t *testing.T) {
 	setupSSETest()
 
@@ -305,6 +337,22 @@ func TestSendMetricsUpdate_NilHandler(t *testing.T) {
 	})
 }
 
+func TestSendConfigChanged(t *testing.T) {
+	setupSSETest()
+	InitSSEHandler()
+
+	SendConfigChanged(ConfigRepoGroups)
+
+	select {
+	case event := <-sseHandler.client:
+		assert.Equal(t, "config_changed", event.Type)
+		assert.True(t, event.Broadcast)
+		assert.Equal(t, ConfigRepoGroups, event.Data.(models.ConfigChangedEvent).Kind)
+	case <-time.After(1 * time.Second):
+		t.Fatal("Config changed event was not received")
+	}
+}
+
 func TestSendWorkflowUpdate(t *testing.T) {
 	setupSSETest()
 
@@ -332,6 +380,25 @@ func TestSendWorkflowUpdate(t *testing.T) {
 	}
 }
 
+func TestSendWorkflowUpdate_JobRepo(t *testing.T) {
+	setupSSETest()
+	InitSSEHandler()
+
+	SendWorkflowUpdate(models.WorkflowUpdateEvent{
+		Type:        "job",
+		ID:          7,
+		WorkflowJob: models.WorkflowJob{ID: 7, RunID: 42, HtmlUrl: "https://github.com/octo/app/actions/runs/42/job/7"},
+	})
+
+	select {
+	case event := <-sseHandler.client:
+		assert.Equal(t, "octo/app", event.Repo, "job updates reach the repository's room")
+		assert.Equal(t, int64(42), event.RunID)
+	case <-time.After(1 * time.Second):
+		t.Fatal("Workflow update event was not received")
+	}
+}
+
 func TestSendWorkflowUpdate_NilHandler(t *testing.T) {
 	setupSSETest()
 
@@ -516,3 +583,379 @@ func TestSSEHandler_HandleSSE_JSONMarshalError(t *testing.T) {
 	assert.Contains(t, body, "connected", "Handler should still send initial connection event")
 	assert.NotContains(t, body, "bad_event", "Bad event should not appear in output")
 }
+
+func TestSSESubscriber_Wants(t *testing.T) {
+	tests := []struct {
+		name     string
+		repo     string
+		event    SSEEvent
+		expected bool
+	}{
+		{"global subscriber receives repo event", "", SSEEvent{Repo: "octo/app"}, true},
+		{"global subscriber receives global event", "", SSEEvent{}, true},
+		{"room receives matching repo", "octo/app", SSEEvent{Repo: "octo/app"}, true},
+		{"room skips other repo", "octo/app", SSEEvent{Repo: "octo/other"}, false},
+		{"room skips global event", "octo/app", SSEEvent{}, false},
+		{"room receives broadcast event", "octo/app", SSEEvent{Broadcast: true}, true},
+		{"bare name does not match any owner", "app", SSEEvent{Repo: "octo/app"}, false},
+		{"owner/name does not match other owner", "octo/app", SSEEvent{Repo: "evil/app"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := &sseSubscriber{repo: tt.repo}
+			assert.Equal(t, tt.expected, sub.wants(tt.event))
+		})
+	}
+}
+
+func TestSSEHandler_HandleSSE_RepoRoom(t *testing.T) {
+	setupSSETest()
+
+	handler := &SSEHandler{
+		client: make(chan SSEEvent, 10),
+	}
+
+	router := gin.New()
+	router.GET("/events", handler.HandleSSE())
+
+	req, _ := http.NewRequest("GET", "/events?repo=octo/app", nil)
+	w := httptest.NewRecorder()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	req = req.WithContext(ctx)
+
+	done := make(chan bool)
+	go func() {
+		router.ServeHTTP(w, req)
+		done <- true
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+
+	handler.sendRepoEvent("workflow_update", map[string]string{"name": "other-repo-run"}, "octo/other")
+	handler.sendRepoEvent("workflow_update", map[string]string{"name": "own-repo-run"}, "octo/app")
+	handler.SendEvent("metrics_update", map[string]string{"name": "global-metrics"})
+
+	select {
+	case <-done:
+	case <-time.After(1 * time.Second):
+		t.Fatal("Handler did not complete within timeout")
+	}
+
+	body := w.Body.String()
+	assert.Contains(t, body, "own-repo-run")
+	assert.NotContains(t, body, "other-repo-run")
+	assert.NotContains(t, body, "global-metrics")
+}
+
+func TestSSEHandler_HandleSSE_BroadcastsToAllClients(t *testing.T) {
+	setupSSETest()
+
+	handler := &SSEHandler{
+		client: make(chan SSEEvent, 10),
+	}
+
+	router := gin.New()
+	router.GET("/events", handler.HandleSSE())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	recorders := []*httptest.ResponseRecorder{httptest.NewRecorder(), httptest.NewRecorder()}
+	var wg sync.WaitGroup
+	for _, w := range recorders {
+		wg.Add(1)
+		go func(w *httptest.ResponseRecorder) {
+			defer wg.Done()
+			req, _ := http.NewRequest("GET", "/events", nil)
+			router.ServeHTTP(w, req.WithContext(ctx))
+		}(w)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	handler.SendEvent("broadcast_event", map[string]string{"message": "to everyone"})
+	wg.Wait()
+
+	for _, w := range recorders {
+		assert.Contains(t, w.Body.String(), "broadcast_event")
+	}
+}
+
+func TestSSESubscriber_WantsRun(t *testing.T) {
+	sub := &sseSubscriber{runID: 42}
+
+	assert.True(t, sub.wants(SSEEvent{Type: "workflow_update", RunID: 42, Repo: "octo/app"}))
+	assert.False(t, sub.wants(SSEEvent{Type: "workflow_update", RunID: 7, Repo: "octo/app"}))
+	assert.False(t, sub.wants(SSEEvent{Type: "metrics_update"}))
+	assert.False(t, sub.wants(SSEEvent{Type: "config_changed", Broadcast: true}))
+}
+
+func TestSSEHandler_HandleRunSSE_StreamsUntilTerminal(t *testing.T) {
+	setupSSETest()
+	InitSSEHandler()
+
+	mockDB := &database.MockDatabase{}
+	mockDB.On("GetWorkflowRunByID", mock.Anything, int64(42)).Return(&models.WorkflowRun{ID: 42, Status: models.JobStatusInProgress}, nil)
+	mockDB.On("GetWorkflowJobsByRunID", mock.Anything, int64(42)).Return([]models.WorkflowJob{{ID: 1, RunID: 42, Name: "build"}}, nil)
+
+	router := gin.New()
+	router.GET("/api/workflow-runs/:run_id/live", sseHandler.HandleRunSSE(mockDB))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	req, _ := http.NewRequest("GET", "/api/workflow-runs/42/live", nil)
+	w := httptest.NewRecorder()
+
+	done := make(chan bool)
+	go func() {
+		router.ServeHTTP(w, req.WithContext(ctx))
+		done <- true
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	SendWorkflowUpdate(models.WorkflowUpdateEvent{Type: "job", ID: 99, Status: "completed", WorkflowJob: models.WorkflowJob{ID: 99, RunID: 7, Name: "other-run-job"}})
+	SendWorkflowUpdate(models.WorkflowUpdateEvent{Type: "job", ID: 1, Status: "completed", WorkflowJob: models.WorkflowJob{ID: 1, RunID: 42, Name: "own-job"}})
+	SendWorkflowUpdate(models.WorkflowUpdateEvent{Type: "run", ID: 42, Status: "completed", WorkflowRun: models.WorkflowRun{ID: 42, Status: models.JobStatusCompleted}})
+
+	select {
+	case <-done:
+	case <-time.After(1 * time.Second):
+		t.Fatal("Live tail should end once the run reaches a terminal state")
+	}
+
+	body := w.Body.String()
+	assert.Contains(t, body, "run_snapshot")
+	assert.Contains(t, body, "own-job")
+	assert.NotContains(t, body, "other-run-job")
+	assert.Contains(t, body, `{"type":"end"`)
+}
+
+func TestSSEHandler_HandleRunSSE_AlreadyFinished(t *testing.T) {
+	setupSSETest()
+	InitSSEHandler()
+
+	mockDB := &database.MockDatabase{}
+	mockDB.On("GetWorkflowRunByID", mock.Anything, int64(42)).Return(&models.WorkflowRun{ID: 42, Status: models.JobStatusCompleted}, nil)
+	mockDB.On("GetWorkflowJobsByRunID", mock.Anything, int64(42)).Return([]models.WorkflowJob{}, nil)
+
+	router := gin.New()
+	router.GET("/api/workflow-runs/:run_id/live", sseHandler.HandleRunSSE(mockDB))
+
+	req, _ := http.NewRequest("GET", "/api/workflow-runs/42/live", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	body := w.Body.String()
+	assert.Contains(t, body, "run_snapshot")
+	assert.Contains(t, body, `{"type":"end"`)
+}
+
+func TestSSEHandler_HandleRunSSE_Errors(t *testing.T) {
+	setupSSETest()
+	InitSSEHandler()
+
+	mockDB := &database.MockDatabase{}
+	mockDB.On("GetWorkflowRunByID", mock.Anything, int64(404)).Return((*models.WorkflowRun)(nil), nil)
+
+	router := gin.New()
+	router.GET("/api/workflow-runs/:run_id/live", sseHandler.HandleRunSSE(mockDB))
+
+	for path, status := range map[string]int{
+		"/api/workflow-runs/abc/live": http.StatusBadRequest,
+		"/api/workflow-runs/404/live": http.StatusNotFound,
+	} {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code, path)
+	}
+}
+
+func TestSSEHandler_HandleSSE_CoalescesMetricsUpdates(t *testing.T) {
+	setupSSETest()
+
+	handler := &SSEHandler{
+		client: make(chan SSEEvent, 10),
+	}
+	handler.SetCoalesceWindow(100 * time.Millisecond)
+
+	router := gin.New()
+	router.GET("/events", handler.HandleSSE())
+
+	req, _ := http.NewRequest("GET", "/events", nil)
+	w := httptest.NewRecorder()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	req = req.WithContext(ctx)
+
+	done := make(chan bool)
+	go func() {
+		router.ServeHTTP(w, req)
+		done <- true
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+
+	handler.SendEvent("metrics_update", map[string]int{"running_jobs": 1})
+	handler.SendEvent("metrics_update", map[string]int{"running_jobs": 2})
+	handler.SendEvent("workflow_update", map[string]string{"name": "passes-through"})
+	handler.SendEvent("metrics_update", map[string]int{"running_jobs": 3})
+
+	select {
+	case <-done:
+	case <-time.After(1 * time.Second):
+		t.Fatal("Handler did not complete within timeout")
+	}
+
+	body := w.Body.String()
+	assert.Contains(t, body, "passes-through")
+	assert.Equal(t, 1, strings.Count(body, "metrics_update"), "bursts should collapse into one update")
+	assert.Contains(t, body, `"running_jobs":3`)
+}
+
+func TestSSEHandler_ClientLimit(t *testing.T) {
+	setupSSETest()
+
+	handler := &SSEHandler{
+		client: make(chan SSEEvent, 10),
+	}
+	handler.SetClientLimit(1, SSEOverflowReject, 30*time.Second)
+
+	mockDB := &database.MockDatabase{}
+	router := gin.New()
+	router.GET("/events", handler.HandleSSE())
+	router.GET("/api/workflow-runs/:run_id/live", handler.HandleRunSSE(mockDB))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequest("GET", "/events", nil)
+	done := make(chan bool)
+	go func() {
+		router.ServeHTTP(httptest.NewRecorder(), req.WithContext(ctx))
+		done <- true
+	}()
+	require.Eventually(t, func() bool {
+		handler.mutex.RLock()
+		defer handler.mutex.RUnlock()
+		return len(handler.subscribers) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	for _, path := range []string{"/events", "/api/workflow-runs/1/live"} {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code, path)
+		assert.Equal(t, "30", w.Header().Get("Retry-After"), path)
+	}
+	mockDB.AssertNotCalled(t, "GetWorkflowRunByID", mock.Anything, mock.Anything)
+
+	handler.SetClientLimit(1, SSEOverflowPoll, 30*time.Second)
+	req, _ = http.NewRequest("GET", "/events", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "retry: 30000\n")
+	assert.Contains(t, w.Body.String(), `{"type":"poll","data":{"interval_seconds":30}}`)
+
+	// A slot frees up once the first client leaves
+	cancel()
+	<-done
+	handler.SetClientLimit(1, SSEOverflowReject, 30*time.Second)
+	req, _ = http.NewRequest("GET", "/events", nil)
+	shortCtx, shortCancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer shortCancel()
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req.WithContext(shortCtx))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "connected")
+}
+
+func TestSSEHandler_Anonymizer(t *testing.T) {
+	setupSSETest()
+
+	handler := &SSEHandler{
+		client: make(chan SSEEvent, 10),
+	}
+	a := anonymize.New("secret")
+	handler.SetAnonymizer(a)
+
+	router := gin.New()
+	router.GET("/events", handler.HandleSSE())
+
+	req, _ := http.NewRequest("GET", "/events", nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	w := httptest.NewRecorder()
+
+	done := make(chan bool)
+	go func() {
+		router.ServeHTTP(w, req.WithContext(ctx))
+		done <- true
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	handler.SendEvent("workflow_update", models.WorkflowUpdateEvent{
+		Type:        "run",
+		WorkflowRun: models.WorkflowRun{ID: 1, RepositoryName: "payments", Actor: "mona"},
+	})
+	<-done
+
+	body := w.Body.String()
+	assert.Contains(t, body, a.Repository("payments"))
+	assert.NotContains(t, body, "payments")
+	assert.NotContains(t, body, "mona")
+}
+
+func TestSSEHandler_Shutdown(t *testing.T) {
+	setupSSETest()
+
+	handler := &SSEHandler{
+		client: make(chan SSEEvent, 10),
+	}
+	mockDB := &database.MockDatabase{}
+	mockDB.On("GetWorkflowRunByID", mock.Anything, int64(42)).Return(&models.WorkflowRun{ID: 42, Status: models.JobStatusInProgress}, nil)
+	mockDB.On("GetWorkflowJobsByRunID", mock.Anything, int64(42)).Return([]models.WorkflowJob{}, nil)
+
+	router := gin.New()
+	router.GET("/events", handler.HandleSSE())
+	router.GET("/api/workflow-runs/:run_id/live", handler.HandleRunSSE(mockDB))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	recorders := make(map[string]*httptest.ResponseRecorder)
+	done := make(chan bool, 2)
+	for _, path := range []string{"/events", "/api/workflow-runs/42/live"} {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		recorders[path] = w
+		go func() {
+			router.ServeHTTP(w, req.WithContext(ctx))
+			done <- true
+		}()
+	}
+	require.Eventually(t, func() bool {
+		handler.mutex.RLock()
+		defer handler.mutex.RUnlock()
+		return len(handler.subscribers) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	handler.Shutdown(15 * time.Second)
+	handler.Shutdown(time.Second)
+
+	for range recorders {
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("Streams should end on shutdown")
+		}
+	}
+	for path, w := range recorders {
+		body := w.Body.String()
+		assert.Contains(t, body, "retry: 15000\n", path)
+		assert.Contains(t, body, `{"type":"server_restarting","data":{"expected_downtime_seconds":15,`, path)
+	}
+	assert.NotContains(t, recorders["/api/workflow-runs/42/live"].Body.String(), `{"type":"end"`, "a restart does not end the run")
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/support"
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// CreateSupportBundle returns a zip archive with the redacted configuration,
+// schema version, recent logs, system stats and anonymized webhook event
+// samples, for attaching to bug reports.
+func (h *APIHandler) CreateSupportBundle() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var buf bytes.Buffer
+		if err := support.Write(c.Request.Context(), &buf, h.config, h.db); err != nil {
+			logger.Logger.Error("Failed to create support bundle", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create support bundle"})
+			return
+		}
+
+		logger.Logger.Info("Support bundle created", zap.String("client_ip", c.ClientIP()), zap.Int("bytes", buf.Len()))
+		filename := "live-actions-support-" + time.Now().UTC().Format("20060102-150405") + ".zip"
+		c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+		c.Data(http.StatusOK, "application/zip", buf.Bytes())
+	}
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateSupportBundle(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	testConfig.Vars.AdminToken = "admin"
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.POST("/api/admin/support-bundle", RequireAdminToken(testConfig), handler.CreateSupportBundle())
+
+	mockDB.On("GetSchemaVersion", mock.Anything).Return(12, nil)
+	mockDB.On("GetProcessingLagStats", mock.Anything, time.Hour).Return(&models.ProcessingLagStats{}, nil)
+	mockDB.On("GetStorageReport", mock.Anything).Return(&models.StorageReport{}, nil)
+	mockDB.On("GetEventSamples", mock.Anything, mock.Anything).Return([]models.EventSample{}, errors.New("db error"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/admin/support-bundle", nil)
+	req.Header.Set("Authorization", "Bearer admin")
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment")
+
+	archive, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	require.NoError(t, err)
+	var names []string
+	for _, f := range archive.File {
+		names = append(names, f.Name)
+	}
+	assert.ElementsMatch(t, []string{"manifest.json", "config.json", "topology.json", "logs.jsonl", "system.json", "events.json"}, names)
+}
+
+func TestCreateSupportBundle_RequiresAdminToken(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	testConfig.Vars.AdminToken = "admin"
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.POST("/api/admin/support-bundle", RequireAdminToken(testConfig), handler.CreateSupportBundle())
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/admin/support-bundle", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockDB.AssertNotCalled(t, "GetSchemaVersion", mock.Anything)
+}
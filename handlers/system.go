@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gateixeira/live-actions/internal/configcheck"
+	"github.com/gateixeira/live-actions/internal/services"
+	"github.com/gateixeira/live-actions/internal/support"
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetSystemStats reports how this instance itself is keeping up: webhook
+// processing lag percentiles over the period (default hour) and whether the
+// recent lag is within the configured SLO.
+func (h *APIHandler) GetSystemStats() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		since := periodToDuration(c.DefaultQuery("period", "hour"))
+		ctx := c.Request.Context()
+
+		lag, err := h.db.GetProcessingLagStats(ctx, since)
+		if err != nil {
+			logger.Logger.Error("Failed to get processing lag", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve system stats"})
+			return
+		}
+
+		recent := lag
+		if since != services.ProcessingLagWindow {
+			if recent, err = h.db.GetProcessingLagStats(ctx, services.ProcessingLagWindow); err != nil {
+				logger.Logger.Error("Failed to get recent processing lag", zap.Error(err))
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve system stats"})
+				return
+			}
+		}
+
+		slo := h.config.GetProcessingLagSLO()
+		c.JSON(http.StatusOK, gin.H{
+			"processing_lag": lag,
+			"slo_seconds":    int(slo.Seconds()),
+			"within_slo":     !services.LagExceedsSLO(recent, slo),
+		})
+	}
+}
+
+// GetSystemStorage reports the database size with row counts, table and
+// index sizes and daily growth per table, for capacity planning.
+func (h *APIHandler) GetSystemStorage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report, err := h.db.GetStorageReport(c.Request.Context())
+		if err != nil {
+			logger.Logger.Error("Failed to get storage report", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve storage report"})
+			return
+		}
+
+		c.JSON(http.StatusOK, report)
+	}
+}
+
+// GetSystemTopology describes how this instance is set up: event backend,
+// database backend, authentication, enabled integrations and retention. It
+// holds no secrets, so it can be shared when reporting an issue.
+func (h *APIHandler) GetSystemTopology() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, support.Topology(h.config))
+	}
+}
+
+// ValidateConfig runs the configuration checks against the running instance:
+// secrets are set, configured URLs answer, runner label settings agree and
+// the database accepts writes. Checks that fail are listed in the report
+// rather than failing the request.
+func (h *APIHandler) ValidateConfig() gin.HandlerFunc {
+	checker := configcheck.New(h.db.CheckWritable)
+	return func(c *gin.Context) {
+		report := checker.Run(c.Request.Context(), h.config)
+		if !report.Valid {
+			logger.Logger.Warn("Configuration validation failed",
+				zap.Int("errors", report.Errors),
+				zap.Int("warnings", report.Warnings))
+		}
+		c.JSON(http.StatusOK, report)
+	}
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/services"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSystemStats(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	testConfig.Vars.ProcessingLagSLOSeconds = 30
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/system/stats", handler.GetSystemStats())
+
+	mockDB.On("GetProcessingLagStats", mock.Anything, 24*time.Hour).Return(&models.ProcessingLagStats{
+		Samples: 1000, P50: 12, P95: 18, P99: 45, Max: 120,
+	}, nil)
+	mockDB.On("GetProcessingLagStats", mock.Anything, services.ProcessingLagWindow).Return(&models.ProcessingLagStats{
+		Samples: 20, P95: 14, Pending: 1, OldestPendingSeconds: 40,
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/system/stats?period=day", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		ProcessingLag models.ProcessingLagStats `json:"processing_lag"`
+		SLOSeconds    int                       `json:"slo_seconds"`
+		WithinSLO     bool                      `json:"within_slo"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1000, response.ProcessingLag.Samples)
+	assert.Equal(t, float64(45), response.ProcessingLag.P99)
+	assert.Equal(t, 30, response.SLOSeconds)
+	assert.False(t, response.WithinSLO, "an event pending longer than the SLO breaches it")
+}
+
+func TestGetSystemStats_DatabaseError(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/system/stats", handler.GetSystemStats())
+
+	mockDB.On("GetProcessingLagStats", mock.Anything, mock.Anything).Return(nil, errors.New("db error"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/system/stats", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestGetSystemStorage(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/system/storage", handler.GetSystemStorage())
+
+	growth := 2048.0
+	mockDB.On("GetStorageReport", mock.Anything).Return(&models.StorageReport{
+		DatabaseBytes: 1 << 20,
+		Tables: []models.TableStorage{
+			{Name: "workflow_jobs", Rows: 500, TableBytes: 65536, IndexBytes: 32768, GrowthBytesPerDay: &growth},
+			{Name: "settings", Rows: 2, TableBytes: 4096},
+		},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/system/storage", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"growth_bytes_per_day":2048`)
+	assert.Contains(t, w.Body.String(), `"growth_rows_per_day":null`)
+
+	var report models.StorageReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, int64(1<<20), report.DatabaseBytes)
+	assert.Len(t, report.Tables, 2)
+}
+
+func TestGetSystemStorage_DatabaseError(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/system/storage", handler.GetSystemStorage())
+
+	mockDB.On("GetStorageReport", mock.Anything).Return(nil, errors.New("db error"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/system/storage", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestGetSystemTopology(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	testConfig.Vars.AdminToken = "admin-token"
+	testConfig.Vars.DataRetentionDays = 14
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/system/topology", handler.GetSystemTopology())
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/system/topology", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var topology models.SystemTopology
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &topology))
+	assert.True(t, topology.Auth.AdminAPI)
+	assert.Equal(t, "sqlite", topology.Database.Driver)
+	assert.Equal(t, 14, topology.Retention.DataRetentionDays)
+	assert.NotContains(t, w.Body.String(), "admin-token")
+	mockDB.AssertNotCalled(t, "GetSettings", mock.Anything)
+}
+
+func TestValidateConfig(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	testConfig.Vars.WebhookSecret = "0123456789abcdef0123"
+	testConfig.Vars.AdminToken = "admin-token-0123456789"
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/admin/config/validate", handler.ValidateConfig())
+
+	mockDB.On("CheckWritable", mock.Anything).Return(errors.New("database is not writable: attempt to write a readonly database")).Once()
+	mockDB.On("CheckWritable", mock.Anything).Return(nil)
+
+	for _, valid := range []bool{false, true} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/admin/config/validate", nil)
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var report models.ConfigValidationReport
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+		assert.Equal(t, valid, report.Valid)
+		assert.NotContains(t, w.Body.String(), "admin-token")
+	}
+	mockDB.AssertExpectations(t)
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const maxSavedFilterNameLength = 64
+
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// runFilterStatuses are the status values accepted by the workflow runs filter.
+var runFilterStatuses = map[string]bool{
+	"": true, "requested": true, "in_progress": true, "completed": true,
+	"success": true, "failure": true, "cancelled": true, "action_required": true,
+	"queued": true, "stale": true,
+}
+
+type runTagRequest struct {
+	Tag string `json:"tag"`
+}
+
+type savedFilterRequest struct {
+	Name   string `json:"name"`
+	Repo   string `json:"repo"`
+	Status string `json:"status"`
+}
+
+// AddRunTag attaches a user-defined tag such as "investigating" to a workflow run.
+func (h *APIHandler) AddRunTag() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		runID, err := strconv.ParseInt(c.Param("run_id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid run_id format"})
+			return
+		}
+
+		var req runTagRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+		tag := strings.ToLower(strings.TrimSpace(req.Tag))
+		if !tagPattern.MatchString(tag) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Tag must be 1-32 lowercase letters, digits, '-' or '_'"})
+			return
+		}
+
+		ctx := c.Request.Context()
+		found, err := h.db.AddRunTag(ctx, runID, tag)
+		if err != nil {
+			logger.Logger.Error("Failed to add run tag", zap.Int64("run_id", runID), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add tag"})
+			return
+		}
+		if !found {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Workflow run not found"})
+			return
+		}
+
+		h.respondWithRunTags(c, runID)
+	}
+}
+
+// RemoveRunTag detaches a tag from a workflow run.
+func (h *APIHandler) RemoveRunTag() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		runID, err := strconv.ParseInt(c.Param("run_id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid run_id format"})
+			return
+		}
+
+		removed, err := h.db.RemoveRunTag(c.Request.Context(), runID, c.Param("tag"))
+		if err != nil {
+			logger.Logger.Error("Failed to remove run tag", zap.Int64("run_id", runID), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove tag"})
+			return
+		}
+		if !removed {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found on this workflow run"})
+			return
+		}
+
+		h.respondWithRunTags(c, runID)
+	}
+}
+
+func (h *APIHandler) respondWithRunTags(c *gin.Context, runID int64) {
+	tags, err := h.db.GetRunTags(c.Request.Context(), runID)
+	if err != nil {
+		logger.Logger.Error("Failed to get run tags", zap.Int64("run_id", runID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve tags"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"run_id": runID, "tags": tags})
+}
+
+// GetSavedFilters returns the saved workflow run filters shared by the team.
+func (h *APIHandler) GetSavedFilters() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filters, err := h.db.GetSavedFilters(c.Request.Context())
+		if err != nil {
+			logger.Logger.Error("Failed to get saved filters", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve saved filters"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"saved_filters": filters})
+	}
+}
+
+// SaveFilter creates a saved filter, replacing any existing filter with the same name.
+func (h *APIHandler) SaveFilter() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req savedFilterRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		filter := models.SavedFilter{
+			Name:   strings.TrimSpace(req.Name),
+			Repo:   strings.TrimSpace(req.Repo),
+			Status: strings.TrimSpace(req.Status),
+		}
+		if filter.Name == "" || len(filter.Name) > maxSavedFilterNameLength {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Filter name must be between 1 and 64 characters"})
+			return
+		}
+		if !runFilterStatuses[filter.Status] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+			return
+		}
+
+		id, err := h.db.SaveFilter(c.Request.Context(), filter)
+		if err != nil {
+			logger.Logger.Error("Failed to save filter", zap.String("name", filter.Name), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save filter"})
+			return
+		}
+		filter.ID = id
+
+		c.JSON(http.StatusCreated, filter)
+	}
+}
+
+// DeleteSavedFilter removes a saved filter by ID.
+func (h *APIHandler) DeleteSavedFilter() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filter id"})
+			return
+		}
+
+		deleted, err := h.db.DeleteSavedFilter(c.Request.Context(), id)
+		if err != nil {
+			logger.Logger.Error("Failed to delete saved filter", zap.Int64("id", id), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete saved filter"})
+			return
+		}
+		if !deleted {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Saved filter not found"})
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
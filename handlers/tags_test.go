@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAddRunTag(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		body           string
+		setupMock      func(*testing.T, *database.MockDatabase)
+		expectedStatus int
+	}{
+		{
+			name: "Adds normalized tag",
+			path: "/api/workflow-runs/42/tags",
+			body: `{"tag": " Known-Issue "}`,
+			setupMock: func(t *testing.T, m *database.MockDatabase) {
+				m.On("AddRunTag", mock.Anything, int64(42), "known-issue").Return(true, nil)
+				m.On("GetRunTags", mock.Anything, int64(42)).Return([]string{"known-issue"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid run id",
+			path:           "/api/workflow-runs/abc/tags",
+			body:           `{"tag": "investigating"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid tag",
+			path:           "/api/workflow-runs/42/tags",
+			body:           `{"tag": "not a tag!"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Unknown run",
+			path: "/api/workflow-runs/42/tags",
+			body: `{"tag": "investigating"}`,
+			setupMock: func(t *testing.T, m *database.MockDatabase) {
+				m.On("AddRunTag", mock.Anything, int64(42), "investigating").Return(false, nil)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "Database error",
+			path: "/api/workflow-runs/42/tags",
+			body: `{"tag": "investigating"}`,
+			setupMock: func(t *testing.T, m *database.MockDatabase) {
+				m.On("AddRunTag", mock.Anything, int64(42), "investigating").Return(false, errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, mockDB, testConfig := setupAPITest()
+			if tt.setupMock != nil {
+				tt.setupMock(t, mockDB)
+			}
+			handler := NewAPIHandler(testConfig, mockDB)
+			router.POST("/api/workflow-runs/:run_id/tags", handler.AddRunTag())
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRemoveRunTag(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.DELETE("/api/workflow-runs/:run_id/tags/:tag", handler.RemoveRunTag())
+
+	mockDB.On("RemoveRunTag", mock.Anything, int64(42), "investigating").Return(true, nil)
+	mockDB.On("RemoveRunTag", mock.Anything, int64(42), "missing").Return(false, nil)
+	mockDB.On("GetRunTags", mock.Anything, int64(42)).Return([]string{}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/api/workflow-runs/42/tags/investigating", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"run_id": 42, "tags": []}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", "/api/workflow-runs/42/tags/missing", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestSaveFilter(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectSave     bool
+		expectedStatus int
+	}{
+		{"Valid filter", `{"name": "My failures", "repo": "app", "status": "failure"}`, true, http.StatusCreated},
+		{"Missing name", `{"name": "  ", "status": "failure"}`, false, http.StatusBadRequest},
+		{"Invalid status", `{"name": "Broken", "status": "exploded"}`, false, http.StatusBadRequest},
+		{"Invalid body", `not json`, false, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, mockDB, testConfig := setupAPITest()
+			if tt.expectSave {
+				mockDB.On("SaveFilter", mock.Anything, models.SavedFilter{Name: "My failures", Repo: "app", Status: "failure"}).Return(int64(7), nil)
+			}
+			handler := NewAPIHandler(testConfig, mockDB)
+			router.POST("/api/saved-filters", handler.SaveFilter())
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/saved-filters", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectSave {
+				var filter models.SavedFilter
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &filter))
+				assert.Equal(t, int64(7), filter.ID)
+			}
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetSavedFilters(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/saved-filters", handler.GetSavedFilters())
+
+	mockDB.On("GetSavedFilters", mock.Anything).Return([]models.SavedFilter{{ID: 1, Name: "Deploys", Status: "failure"}}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/saved-filters", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"Deploys"`)
+}
+
+func TestDeleteSavedFilter(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.DELETE("/api/saved-filters/:id", handler.DeleteSavedFilter())
+
+	mockDB.On("DeleteSavedFilter", mock.Anything, int64(1)).Return(true, nil)
+	mockDB.On("DeleteSavedFilter", mock.Anything, int64(2)).Return(false, nil)
+
+	for path, status := range map[string]int{
+		"/api/saved-filters/1":   http.StatusNoContent,
+		"/api/saved-filters/2":   http.StatusNotFound,
+		"/api/saved-filters/abc": http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", path, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code, path)
+	}
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// maxThroughputWindowMinutes bounds the window the throughput endpoint averages over.
+const maxThroughputWindowMinutes = 24 * 60
+
+// GetThroughput returns the jobs started and completed per minute over a
+// sliding window ending now (default: THROUGHPUT_WINDOW_MINUTES), in total
+// and per runner label, busiest first. A job counts under each of its labels. These are the rates exported as the
+// github_runners_jobs_started_per_minute and
+// github_runners_jobs_completed_per_minute gauges.
+func (h *APIHandler) GetThroughput() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		window, err := strconv.Atoi(c.DefaultQuery("window", strconv.Itoa(h.config.Vars.ThroughputWindowMinutes)))
+		if err != nil || window < 1 || window > maxThroughputWindowMinutes {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "window must be between 1 and 1440 minutes"})
+			return
+		}
+		repos, ok := h.repoFilter(c)
+		if !ok {
+			return
+		}
+
+		now := h.config.Now()
+		from := now.Add(-time.Duration(window) * time.Minute)
+		labels, err := h.db.GetLabelThroughput(c.Request.Context(), from, now, repos)
+		if err != nil {
+			logger.Logger.Error("Failed to get label throughput", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve throughput"})
+			return
+		}
+		// Jobs count under each of their labels, so the total is not their sum
+		total, err := h.db.GetJobThroughput(c.Request.Context(), from, now, repos)
+		if err != nil {
+			logger.Logger.Error("Failed to get job throughput", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve throughput"})
+			return
+		}
+
+		sort.Slice(labels, func(i, j int) bool {
+			if labels[i].Started != labels[j].Started {
+				return labels[i].Started > labels[j].Started
+			}
+			if labels[i].Completed != labels[j].Completed {
+				return labels[i].Completed > labels[j].Completed
+			}
+			return labels[i].Label < labels[j].Label
+		})
+		c.JSON(http.StatusOK, gin.H{
+			"window_minutes": window,
+			"total":          total,
+			"labels":         labels,
+		})
+	}
+}
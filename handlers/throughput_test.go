@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/clock"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetThroughput(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	testConfig.Clock = clock.NewFake(now)
+	testConfig.Vars.ThroughputWindowMinutes = 5
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/analytics/throughput", handler.GetThroughput())
+
+	mockDB.On("GetLabelThroughput", mock.Anything, now.Add(-10*time.Minute), now, []string{"app"}).Return([]models.LabelThroughput{
+		{Label: "gpu", Started: 2, Completed: 4, StartedPerMinute: 0.2, CompletedPerMinute: 0.4},
+		{Label: "ubuntu-latest", Started: 18, Completed: 16, StartedPerMinute: 1.8, CompletedPerMinute: 1.6},
+	}, nil)
+	mockDB.On("GetJobThroughput", mock.Anything, now.Add(-10*time.Minute), now, []string{"app"}).Return(models.LabelThroughput{
+		Started: 18, Completed: 16, StartedPerMinute: 1.8, CompletedPerMinute: 1.6,
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/analytics/throughput?window=10&repo=app", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		WindowMinutes int                      `json:"window_minutes"`
+		Total         models.LabelThroughput   `json:"total"`
+		Labels        []models.LabelThroughput `json:"labels"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 10, response.WindowMinutes)
+	assert.Equal(t, 18, response.Total.Started, "jobs with both labels are counted once")
+	assert.Equal(t, 1.8, response.Total.StartedPerMinute)
+	assert.Equal(t, 1.6, response.Total.CompletedPerMinute)
+	require.Len(t, response.Labels, 2)
+	assert.Equal(t, "ubuntu-latest", response.Labels[0].Label)
+	mockDB.AssertExpectations(t)
+}
+
+func TestGetThroughput_DefaultWindow(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	testConfig.Clock = clock.NewFake(now)
+	testConfig.Vars.ThroughputWindowMinutes = 5
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/analytics/throughput", handler.GetThroughput())
+
+	mockDB.On("GetLabelThroughput", mock.Anything, now.Add(-5*time.Minute), now, []string(nil)).Return([]models.LabelThroughput{}, nil)
+	mockDB.On("GetJobThroughput", mock.Anything, now.Add(-5*time.Minute), now, []string(nil)).Return(models.LabelThroughput{}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/analytics/throughput", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"labels":[]`)
+	mockDB.AssertExpectations(t)
+}
+
+func TestGetThroughput_InvalidWindow(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/analytics/throughput", handler.GetThroughput())
+
+	for _, window := range []string{"0", "1441", "five"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/analytics/throughput?window="+window, nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, window)
+	}
+	mockDB.AssertNotCalled(t, "GetLabelThroughput", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetThroughput_DatabaseError(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/analytics/throughput", handler.GetThroughput())
+
+	mockDB.On("GetLabelThroughput", mock.Anything, mock.Anything, mock.Anything, []string(nil)).Return([]models.LabelThroughput{}, errors.New("db error"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/analytics/throughput?window=5", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
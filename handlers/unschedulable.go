@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gateixeira/live-actions/internal/services"
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GetUnschedulableJobs returns queued jobs whose runner labels no job has
+// ever run on, most likely because of a misconfigured runs-on.
+func (h *APIHandler) GetUnschedulableJobs() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		repos, ok := h.repoFilter(c)
+		if !ok {
+			return
+		}
+
+		jobs, err := h.db.GetUnschedulableJobs(c.Request.Context(), services.UnschedulableQueuedFor, repos)
+		if err != nil {
+			logger.Logger.Error("Failed to get unschedulable jobs", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve unschedulable jobs"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"jobs":               jobs,
+			"queued_for_minutes": int(services.UnschedulableQueuedFor.Minutes()),
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gateixeira/live-actions/internal/services"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetUnschedulableJobs(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/analytics/unschedulable", handler.GetUnschedulableJobs())
+
+	mockDB.On("GetUnschedulableJobs", mock.Anything, services.UnschedulableQueuedFor, []string{"app"}).Return([]models.UnschedulableJob{
+		{ID: 3, Name: "build", RunID: 1, Labels: []string{"self-hosted", "ubunut"}, UnknownLabels: []string{"ubunut"}},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/analytics/unschedulable?repo=app", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Jobs             []models.UnschedulableJob `json:"jobs"`
+		QueuedForMinutes int                       `json:"queued_for_minutes"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Jobs, 1)
+	assert.Equal(t, []string{"ubunut"}, response.Jobs[0].UnknownLabels)
+	assert.Equal(t, 10, response.QueuedForMinutes)
+	mockDB.AssertExpectations(t)
+}
+
+func TestGetUnschedulableJobs_DatabaseError(t *testing.T) {
+	router, mockDB, testConfig := setupAPITest()
+	handler := NewAPIHandler(testConfig, mockDB)
+	router.GET("/api/analytics/unschedulable", handler.GetUnschedulableJobs())
+
+	mockDB.On("GetUnschedulableJobs", mock.Anything, mock.Anything, []string(nil)).Return([]models.UnschedulableJob{}, errors.New("db error"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/analytics/unschedulable", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
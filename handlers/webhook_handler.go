@@ -13,11 +13,11 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
-	"time"
 
 	"github.com/gateixeira/live-actions/internal/config"
 	"github.com/gateixeira/live-actions/models"
 	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/gateixeira/live-actions/pkg/metrics"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
@@ -162,7 +162,10 @@ func (h *WebhookHandler) Handle() gin.HandlerFunc {
 			if !strings.HasPrefix(decodedBody, prefix) {
 				logger.Logger.Error("URL-encoded payload does not start with expected prefix",
 					zap.String("expected_prefix", prefix),
-					zap.String("payload_start", decodedBody[:min(len(decodedBody), 50)]))
+					zap.String("delivery_id", deliveryID))
+				logger.Module("webhook").Debug("Rejected URL-encoded payload",
+					zap.String("delivery_id", deliveryID),
+					logger.Payload("payload_start", []byte(decodedBody)))
 				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL-encoded payload format"})
 				return
 			}
@@ -177,7 +180,10 @@ func (h *WebhookHandler) Handle() gin.HandlerFunc {
 		if err := json.Unmarshal(jsonData, &payload); err != nil {
 			logger.Logger.Error("Failed to parse JSON payload",
 				zap.Error(err),
-				zap.String("payload_start", string(jsonData[:min(len(jsonData), 100)])))
+				zap.String("delivery_id", deliveryID))
+			logger.Module("webhook").Debug("Rejected JSON payload",
+				zap.String("delivery_id", deliveryID),
+				logger.Payload("payload_start", jsonData))
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON payload"})
 			return
 		}
@@ -224,12 +230,13 @@ func (h *WebhookHandler) Handle() gin.HandlerFunc {
 				EventID:    deliveryID,
 				Timestamp:  extractedTime,
 				DeliveryID: deliveryID,
-				ReceivedAt: time.Now(),
+				ReceivedAt: h.config.Now(),
 			},
 			EventType:      eventTypeStr,
 			RawPayload:     jsonData,
 			OrderingKey:    orderingKey,
 			StatusPriority: statusPriority,
+			Terminal:       isTerminalRunStatus(models.JobStatus(fmt.Sprint(payload["action"]))),
 		}
 
 		if err := h.orderingService.AddEvent(orderedEvent); err != nil {
@@ -238,7 +245,7 @@ func (h *WebhookHandler) Handle() gin.HandlerFunc {
 			return
 		}
 
-		logger.Logger.Debug("Event queued for ordered processing",
+		logger.Module("webhook").Debug("Event queued for ordered processing",
 			zap.String("event_type", orderedEvent.EventType),
 			zap.String("delivery_id", orderedEvent.Sequence.DeliveryID),
 			zap.String("ordering_key", orderedEvent.OrderingKey),
@@ -274,7 +281,12 @@ func (h *WebhookHandler) processOrderedEvent(event *models.OrderedEvent) error {
 		return fmt.Errorf("failed to handle event: %w", err)
 	}
 
-	return h.db.MarkEventProcessed(context.TODO(), event.Sequence.DeliveryID)
+	if err := h.db.MarkEventProcessed(context.TODO(), event.Sequence.DeliveryID); err != nil {
+		return err
+	}
+
+	metrics.GetRegistry().RecordProcessingLag(event.EventType, h.config.Now().Sub(event.Sequence.ReceivedAt).Seconds())
+	return nil
 }
 
 func (h *WebhookHandler) Shutdown() {
@@ -161,7 +161,7 @@ func TestWebhookHandler_UnregisteredEventType(t *testing.T) {
 	mockDB.On("GetPendingEventsGrouped", mock.Anything, mock.Anything).Return([]*models.OrderedEvent{}, nil)
 	mockDB.On("GetPendingEventsByAge", mock.Anything, mock.Anything, mock.Anything).Return([]*models.OrderedEvent{}, nil)
 
-	webhookHandler := NewWebhookHandler(testConfig, mockDB)
+	webhookHandler := NewWebhookHandler(testConfig, mockDB, nil)
 	defer webhookHandler.Shutdown()
 
 	router.POST("/webhook", ValidateGitHubWebhook(testConfig), webhookHandler.Handle())
@@ -2,13 +2,13 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"sync"
 	"time"
 
 	"github.com/gateixeira/live-actions/internal/config"
 	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/internal/payload"
 	"github.com/gateixeira/live-actions/models"
 	"github.com/gateixeira/live-actions/pkg/logger"
 	"github.com/gateixeira/live-actions/pkg/metrics"
@@ -16,15 +16,21 @@ import (
 )
 
 type WorkflowJobHandler struct {
-	mutex  sync.RWMutex
-	db     database.DatabaseInterface
-	config *config.Config
+	mutex   sync.RWMutex
+	db      database.DatabaseInterface
+	config  *config.Config
+	deduper *statusDeduper
+	// jobNames groups renamed jobs for ETAs without reading the merges on
+	// every event
+	jobNames *jobNameCache
 }
 
 func NewWorkflowJobHandler(config *config.Config, db database.DatabaseInterface) *WorkflowJobHandler {
 	return &WorkflowJobHandler{
-		db:     db,
-		config: config,
+		db:       db,
+		config:   config,
+		deduper:  newStatusDeduper(config.GetDedupeWindow()),
+		jobNames: newJobNameCache(db, config.Vars.JobNameRules),
 	}
 }
 
@@ -33,8 +39,8 @@ func (h *WorkflowJobHandler) GetEventType() string {
 }
 
 func (h *WorkflowJobHandler) HandleEvent(eventData []byte, sequence *models.EventSequence) error {
-	var event models.WorkflowJobEvent
-	if err := json.Unmarshal(eventData, &event); err != nil {
+	event, version, err := payload.ParseWorkflowJobEvent(eventData)
+	if err != nil {
 		logger.Logger.Error("Failed to parse workflow_job JSON payload",
 			zap.Error(err),
 			zap.String("delivery_id", sequence.DeliveryID),
@@ -42,7 +48,15 @@ func (h *WorkflowJobHandler) HandleEvent(eventData []byte, sequence *models.Even
 		return fmt.Errorf("invalid JSON payload: %w", err)
 	}
 
-	event.WorkflowJob.Status = models.JobStatus(event.Action)
+	dedupeKey := fmt.Sprintf("job_%d", event.WorkflowJob.ID)
+	if h.deduper.isDuplicate(dedupeKey, event.Action, h.config.Now()) {
+		metrics.GetRegistry().RecordSuppressedEvent(h.GetEventType())
+		logger.Logger.Debug("Suppressing repeated job status update",
+			zap.Int64("job_id", event.WorkflowJob.ID),
+			zap.String("status", event.Action),
+			zap.String("delivery_id", sequence.DeliveryID))
+		return nil
+	}
 
 	// Get the previous state of this job from database to handle transitions correctly
 	previousJob, err := h.db.GetWorkflowJobByID(context.TODO(), event.WorkflowJob.ID)
@@ -72,12 +86,15 @@ func (h *WorkflowJobHandler) HandleEvent(eventData []byte, sequence *models.Even
 			zap.String("delivery_id", sequence.DeliveryID))
 		return nil
 	}
+	h.deduper.record(dedupeKey, event.Action, h.config.Now())
+	h.recordApproval(previousJob.Status, event.WorkflowJob, sequence.ReceivedAt)
 
 	h.mutex.Lock()
 	defer h.mutex.Unlock()
 
 	logger.Logger.Info("Processing workflow job event",
 		zap.String("action", event.Action),
+		zap.Stringer("payload_version", version),
 		zap.Int64("job_id", event.WorkflowJob.ID),
 		zap.String("current_status", string(event.WorkflowJob.Status)),
 		zap.String("previous_status", string(previousJob.Status)),
@@ -88,12 +105,48 @@ func (h *WorkflowJobHandler) HandleEvent(eventData []byte, sequence *models.Even
 	// Handle state transitions correctly
 	h.handleJobStatusTransition(previousJob.Status, event.WorkflowJob.Status, event.WorkflowJob)
 
+	h.sendJobUpdate(event.Action, event.WorkflowJob)
 	h.sendMetricsUpdate()
 
 	logger.Logger.Debug("Event handled successfully", zap.String("event_type", h.GetEventType()))
 	return nil
 }
 
+// recordApproval tracks jobs waiting for an environment approval, timing the
+// wait from when the waiting and following queued or in_progress events were
+// received.
+func (h *WorkflowJobHandler) recordApproval(previousStatus models.JobStatus, job models.WorkflowJob, receivedAt time.Time) {
+	var err error
+	switch {
+	case job.Status == models.JobStatusWaiting:
+		err = h.db.RecordJobWaiting(context.TODO(), job.ID, job.RunID, receivedAt)
+	case previousStatus == models.JobStatusWaiting &&
+		(job.Status == models.JobStatusQueued || job.Status == models.JobStatusInProgress):
+		err = h.db.RecordJobApproved(context.TODO(), job.ID, receivedAt)
+	}
+	if err != nil {
+		logger.Logger.Error("Error recording job approval",
+			zap.Error(err),
+			zap.Int64("job_id", job.ID))
+	}
+}
+
+// sendJobUpdate notifies SSE clients of a job change, including an ETA for in-progress jobs.
+func (h *WorkflowJobHandler) sendJobUpdate(action string, job models.WorkflowJob) {
+	jobs := []models.WorkflowJob{job}
+	now := h.config.Now()
+	addJobETAs(context.TODO(), h.db, h.jobNames, job.RunID, jobs, now)
+
+	SendWorkflowUpdate(models.WorkflowUpdateEvent{
+		Type:        "job",
+		Action:      action,
+		ID:          job.ID,
+		Status:      string(job.Status),
+		Timestamp:   now.Format(time.RFC3339),
+		WorkflowJob: jobs[0],
+	})
+}
+
 func (h *WorkflowJobHandler) sendMetricsUpdate() {
 	// Query database for current job counts
 	running, queued, err := h.db.GetCurrentJobCounts(context.TODO())
@@ -106,7 +159,7 @@ func (h *WorkflowJobHandler) sendMetricsUpdate() {
 	metricsUpdate := models.MetricsUpdateEvent{
 		RunningJobs: running,
 		QueuedJobs:  queued,
-		Timestamp:   time.Now().Format(time.RFC3339),
+		Timestamp:   h.config.Now().Format(time.RFC3339),
 	}
 
 	logger.Logger.Debug("Sending metrics update",
@@ -133,10 +186,7 @@ func (h *WorkflowJobHandler) handleJobStatusTransition(previousStatus, currentSt
 		zap.String("from", string(previousStatus)),
 		zap.String("to", string(currentStatus)))
 
-	label := "(unlabeled)"
-	if len(job.Labels) > 0 {
-		label = job.Labels[0]
-	}
+	label := metricsRegistry.JobLabel(job.Labels)
 
 	// Record queue duration if transitioning from queued
 	if previousStatus == models.JobStatusQueued && !job.StartedAt.IsZero() {
@@ -154,8 +204,8 @@ func (h *WorkflowJobHandler) handleJobStatusTransition(previousStatus, currentSt
 }
 
 func (h *WorkflowJobHandler) ExtractEventTimestamp(eventData []byte) (time.Time, error) {
-	var event models.WorkflowJobEvent
-	if err := json.Unmarshal(eventData, &event); err != nil {
+	event, _, err := payload.ParseWorkflowJobEvent(eventData)
+	if err != nil {
 		return time.Time{}, fmt.Errorf("failed to parse workflow_job JSON payload: %w", err)
 	}
 
@@ -163,33 +213,33 @@ func (h *WorkflowJobHandler) ExtractEventTimestamp(eventData []byte) (time.Time,
 }
 
 func (h *WorkflowJobHandler) ExtractOrderingKey(eventData []byte) (string, error) {
-	var event models.WorkflowJobEvent
-	if err := json.Unmarshal(eventData, &event); err != nil {
+	event, _, err := payload.ParseWorkflowJobEvent(eventData)
+	if err != nil {
 		return "", fmt.Errorf("failed to parse workflow_job JSON payload: %w", err)
 	}
 
 	return fmt.Sprintf("job_%d", event.WorkflowJob.ID), nil
 }
 
+// jobStatusPriorities orders workflow_job actions as GitHub delivers them.
+var jobStatusPriorities = map[models.JobStatus]int{
+	models.JobStatusWaiting:    1,
+	models.JobStatusQueued:     2,
+	models.JobStatusRequested:  3,
+	models.JobStatusInProgress: 4,
+	models.JobStatusCompleted:  5,
+	models.JobStatusCancelled:  5,
+}
+
 func (h *WorkflowJobHandler) GetStatusPriority(eventData []byte) (int, error) {
-	var event models.WorkflowJobEvent
-	if err := json.Unmarshal(eventData, &event); err != nil {
+	event, _, err := payload.ParseWorkflowJobEvent(eventData)
+	if err != nil {
 		return 0, fmt.Errorf("failed to parse workflow_job JSON payload: %w", err)
 	}
 
-	switch models.JobStatus(event.Action) {
-	case models.JobStatusWaiting:
-		return 1, nil
-	case models.JobStatusQueued:
-		return 2, nil
-	case models.JobStatusRequested:
-		return 3, nil
-	case models.JobStatusInProgress:
-		return 4, nil
-	case models.JobStatusCompleted, models.JobStatusCancelled:
-		return 5, nil
-	default:
-		logger.Logger.Warn("Unknown job status", zap.String("status", event.Action))
-		return 999, nil
+	if priority, ok := jobStatusPriorities[models.JobStatus(event.Action)]; ok {
+		return priority, nil
 	}
+	logger.Logger.Warn("Unknown job status", zap.String("status", event.Action))
+	return 999, nil
 }
@@ -26,7 +26,10 @@ func setupWorkflowJobTest() (*database.MockDatabase, *config.Config) {
 		Vars: config.Vars{},
 	}
 
-	return &database.MockDatabase{}, testConfig
+	mockDB := &database.MockDatabase{}
+	mockDB.On("GetJobDurationStats", mock.Anything, mock.Anything).Return(map[string]models.DurationStats{}, nil).Maybe()
+
+	return mockDB, testConfig
 }
 
 func TestNewWorkflowJobHandler(t *testing.T) {
@@ -100,6 +103,61 @@ func TestWorkflowJobHandler_HandleEvent_Success(t *testing.T) {
 	mockDB.AssertExpectations(t)
 }
 
+func TestWorkflowJobHandler_HandleEvent_SuppressesRepeatedStatus(t *testing.T) {
+	mockDB, testConfig := setupWorkflowJobTest()
+	testConfig.Vars.DedupeWindowSeconds = 10
+	handler := NewWorkflowJobHandler(testConfig, mockDB)
+
+	now := time.Now()
+	sequence := &models.EventSequence{DeliveryID: "delivery123", Timestamp: now, ReceivedAt: now}
+	eventData, err := json.Marshal(models.WorkflowJobEvent{
+		Action: "in_progress",
+		WorkflowJob: models.WorkflowJob{
+			ID:        12345,
+			Name:      "Test Job",
+			Labels:    []string{"ubuntu-latest"},
+			CreatedAt: now,
+			StartedAt: now,
+			RunID:     67890,
+		},
+	})
+	assert.NoError(t, err)
+
+	mockDB.On("GetWorkflowJobByID", mock.Anything, int64(12345)).Return(models.WorkflowJob{Status: models.JobStatusQueued}, nil).Once()
+	mockDB.On("AddOrUpdateJob", mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Once()
+	mockDB.On("GetCurrentJobCounts", mock.Anything).Return(1, 0, nil).Once()
+
+	assert.NoError(t, handler.HandleEvent(eventData, sequence))
+	assert.NoError(t, handler.HandleEvent(eventData, sequence), "repeated status update should be dropped")
+	mockDB.AssertExpectations(t)
+}
+
+func TestWorkflowJobHandler_HandleEvent_RecordsApproval(t *testing.T) {
+	mockDB, testConfig := setupWorkflowJobTest()
+	handler := NewWorkflowJobHandler(testConfig, mockDB)
+
+	waitingAt := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+	queuedAt := waitingAt.Add(20 * time.Minute)
+	jobEvent := func(action string) []byte {
+		data, _ := json.Marshal(map[string]any{
+			"action":       action,
+			"workflow_job": map[string]any{"id": 12345, "run_id": 67890, "name": "deploy", "created_at": waitingAt},
+		})
+		return data
+	}
+
+	mockDB.On("GetWorkflowJobByID", mock.Anything, int64(12345)).Return(models.WorkflowJob{}, nil).Once()
+	mockDB.On("GetWorkflowJobByID", mock.Anything, int64(12345)).Return(models.WorkflowJob{Status: models.JobStatusWaiting}, nil).Once()
+	mockDB.On("AddOrUpdateJob", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+	mockDB.On("GetCurrentJobCounts", mock.Anything).Return(0, 1, nil)
+	mockDB.On("RecordJobWaiting", mock.Anything, int64(12345), int64(67890), waitingAt).Return(nil).Once()
+	mockDB.On("RecordJobApproved", mock.Anything, int64(12345), queuedAt).Return(nil).Once()
+
+	assert.NoError(t, handler.HandleEvent(jobEvent("waiting"), &models.EventSequence{DeliveryID: "d1", ReceivedAt: waitingAt}))
+	assert.NoError(t, handler.HandleEvent(jobEvent("queued"), &models.EventSequence{DeliveryID: "d2", ReceivedAt: queuedAt}))
+	mockDB.AssertExpectations(t)
+}
+
 func TestWorkflowJobHandler_HandleEvent_InvalidJSON(t *testing.T) {
 	mockDB, testConfig := setupWorkflowJobTest()
 	handler := NewWorkflowJobHandler(testConfig, mockDB)
@@ -2,22 +2,28 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"time"
 
+	"github.com/gateixeira/live-actions/internal/config"
 	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/internal/notify"
+	"github.com/gateixeira/live-actions/internal/payload"
 	"github.com/gateixeira/live-actions/models"
 	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/gateixeira/live-actions/pkg/metrics"
 	"go.uber.org/zap"
 )
 
 type WorkflowRunHandler struct {
-	db database.DatabaseInterface
+	db       database.DatabaseInterface
+	config   *config.Config
+	notifier *notify.Notifier
+	deduper  *statusDeduper
 }
 
-func NewWorkflowRunHandler(db database.DatabaseInterface) *WorkflowRunHandler {
-	return &WorkflowRunHandler{db: db}
+func NewWorkflowRunHandler(config *config.Config, db database.DatabaseInterface, notifier *notify.Notifier) *WorkflowRunHandler {
+	return &WorkflowRunHandler{db: db, config: config, notifier: notifier, deduper: newStatusDeduper(config.GetDedupeWindow())}
 }
 
 func (h *WorkflowRunHandler) GetEventType() string {
@@ -25,23 +31,31 @@ func (h *WorkflowRunHandler) GetEventType() string {
 }
 
 func (h *WorkflowRunHandler) HandleEvent(eventData []byte, sequence *models.EventSequence) error {
-	var event models.WorkflowRunEvent
-	if err := json.Unmarshal(eventData, &event); err != nil {
+	event, version, err := payload.ParseWorkflowRunEvent(eventData)
+	if err != nil {
 		logger.Logger.Error("Failed to parse workflow_run JSON payload",
 			zap.Error(err),
 			zap.String("delivery_id", sequence.DeliveryID),
 			zap.String("event_id", sequence.EventID))
-		logger.Logger.Debug("Raw event data",
-			zap.ByteString("data", eventData),
+		logger.Module("webhook").Debug("Raw event data",
+			logger.Payload("payload_start", eventData),
 			zap.String("delivery_id", sequence.DeliveryID))
 		return fmt.Errorf("invalid JSON payload: %w", err)
 	}
 
-	event.WorkflowRun.Status = models.JobStatus(event.Action)
-	event.WorkflowRun.RepositoryName = event.Repository.Name
+	dedupeKey := fmt.Sprintf("run_%d", event.WorkflowRun.ID)
+	if h.deduper.isDuplicate(dedupeKey, event.Action, h.config.Now()) {
+		metrics.GetRegistry().RecordSuppressedEvent(h.GetEventType())
+		logger.Logger.Debug("Suppressing repeated run status update",
+			zap.Int64("run_id", event.WorkflowRun.ID),
+			zap.String("status", event.Action),
+			zap.String("delivery_id", sequence.DeliveryID))
+		return nil
+	}
 
 	logger.Logger.Info("Processing workflow run event",
 		zap.String("action", event.Action),
+		zap.Stringer("payload_version", version),
 		zap.Int64("run_id", event.WorkflowRun.ID),
 		zap.String("repository", event.WorkflowRun.RepositoryName),
 		zap.String("delivery_id", sequence.DeliveryID),
@@ -67,6 +81,7 @@ func (h *WorkflowRunHandler) HandleEvent(eventData []byte, sequence *models.Even
 			zap.String("delivery_id", sequence.DeliveryID))
 		return nil
 	}
+	h.deduper.record(dedupeKey, event.Action, h.config.Now())
 
 	// Send SSE event for workflow run update
 	SendWorkflowUpdate(models.WorkflowUpdateEvent{
@@ -74,17 +89,21 @@ func (h *WorkflowRunHandler) HandleEvent(eventData []byte, sequence *models.Even
 		Action:      event.Action,
 		ID:          event.WorkflowRun.ID,
 		Status:      string(event.WorkflowRun.Status),
-		Timestamp:   time.Now().Format(time.RFC3339),
+		Timestamp:   h.config.Now().Format(time.RFC3339),
 		WorkflowRun: event.WorkflowRun,
 	})
 
+	if h.config.Vars.RegressionAlerts && event.WorkflowRun.Status == models.JobStatusCompleted && event.WorkflowRun.Conclusion == "success" {
+		checkDurationRegression(context.TODO(), h.db, h.notifier, event.WorkflowRun, h.config.Vars.RegressionThresholdPercent)
+	}
+
 	logger.Logger.Debug("Event handled successfully", zap.String("event_type", h.GetEventType()))
 	return nil
 }
 
 func (h *WorkflowRunHandler) ExtractEventTimestamp(eventData []byte) (time.Time, error) {
-	var event models.WorkflowRunEvent
-	if err := json.Unmarshal(eventData, &event); err != nil {
+	event, _, err := payload.ParseWorkflowRunEvent(eventData)
+	if err != nil {
 		return time.Time{}, fmt.Errorf("failed to parse workflow_run JSON payload: %w", err)
 	}
 
@@ -92,29 +111,31 @@ func (h *WorkflowRunHandler) ExtractEventTimestamp(eventData []byte) (time.Time,
 }
 
 func (h *WorkflowRunHandler) ExtractOrderingKey(eventData []byte) (string, error) {
-	var event models.WorkflowRunEvent
-	if err := json.Unmarshal(eventData, &event); err != nil {
+	event, _, err := payload.ParseWorkflowRunEvent(eventData)
+	if err != nil {
 		return "", fmt.Errorf("failed to parse workflow_run JSON payload: %w", err)
 	}
 
 	return fmt.Sprintf("run_%d", event.WorkflowRun.ID), nil
 }
 
+// runStatusPriorities orders workflow_run actions as GitHub delivers them.
+var runStatusPriorities = map[models.JobStatus]int{
+	models.JobStatusRequested:  1,
+	models.JobStatusInProgress: 2,
+	models.JobStatusCompleted:  3,
+	models.JobStatusCancelled:  3,
+}
+
 func (h *WorkflowRunHandler) GetStatusPriority(eventData []byte) (int, error) {
-	var event models.WorkflowRunEvent
-	if err := json.Unmarshal(eventData, &event); err != nil {
+	event, _, err := payload.ParseWorkflowRunEvent(eventData)
+	if err != nil {
 		return 0, fmt.Errorf("failed to parse workflow_run JSON payload: %w", err)
 	}
 
-	switch models.JobStatus(event.Action) {
-	case models.JobStatusRequested:
-		return 1, nil
-	case models.JobStatusInProgress:
-		return 2, nil
-	case models.JobStatusCompleted, models.JobStatusCancelled:
-		return 3, nil
-	default:
-		logger.Logger.Warn("Unknown run status", zap.String("status", event.Action))
-		return 999, nil
+	if priority, ok := runStatusPriorities[models.JobStatus(event.Action)]; ok {
+		return priority, nil
 	}
+	logger.Logger.Warn("Unknown run status", zap.String("status", event.Action))
+	return 999, nil
 }
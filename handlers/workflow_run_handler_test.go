@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/gateixeira/live-actions/internal/config"
 	"github.com/gateixeira/live-actions/internal/database"
 	"github.com/gateixeira/live-actions/models"
 	"github.com/gateixeira/live-actions/pkg/logger"
@@ -25,7 +26,7 @@ func setupWorkflowRunTest() *database.MockDatabase {
 
 func TestNewWorkflowRunHandler(t *testing.T) {
 	mockDB := setupWorkflowRunTest()
-	handler := NewWorkflowRunHandler(mockDB)
+	handler := NewWorkflowRunHandler(&config.Config{}, mockDB, nil)
 
 	assert.NotNil(t, handler, "NewWorkflowRunHandler should return a non-nil handler")
 	assert.Equal(t, mockDB, handler.db, "Handler should store the database interface")
@@ -33,7 +34,7 @@ func TestNewWorkflowRunHandler(t *testing.T) {
 
 func TestWorkflowRunHandler_GetEventType(t *testing.T) {
 	mockDB := setupWorkflowRunTest()
-	handler := NewWorkflowRunHandler(mockDB)
+	handler := NewWorkflowRunHandler(&config.Config{}, mockDB, nil)
 
 	eventType := handler.GetEventType()
 	assert.Equal(t, "workflow_run", eventType, "GetEventType should return 'workflow_run'")
@@ -41,7 +42,7 @@ func TestWorkflowRunHandler_GetEventType(t *testing.T) {
 
 func TestWorkflowRunHandler_HandleEvent_Success(t *testing.T) {
 	mockDB := setupWorkflowRunTest()
-	handler := NewWorkflowRunHandler(mockDB)
+	handler := NewWorkflowRunHandler(&config.Config{}, mockDB, nil)
 
 	// Create test data
 	now := time.Now()
@@ -93,9 +94,47 @@ func TestWorkflowRunHandler_HandleEvent_Success(t *testing.T) {
 	mockDB.AssertExpectations(t)
 }
 
+func TestWorkflowRunHandler_HandleEvent_CommitMetadata(t *testing.T) {
+	mockDB := setupWorkflowRunTest()
+	handler := NewWorkflowRunHandler(&config.Config{}, mockDB, nil)
+
+	sequence := &models.EventSequence{EventID: "event123", DeliveryID: "delivery123", Timestamp: time.Now()}
+	payload := []byte(`{
+		"action": "completed",
+		"repository": {"name": "app", "url": "https://github.com/octo/app"},
+		"workflow_run": {
+			"id": 1,
+			"name": "Deploy",
+			"status": "completed",
+			"html_url": "https://github.com/octo/app/actions/runs/1",
+			"display_title": "Fix deploy",
+			"created_at": "2024-01-01T12:00:00Z",
+			"head_sha": "abc1234def5678",
+			"head_commit": {
+				"id": "abc1234def5678",
+				"message": "Fix deploy",
+				"author": {"name": "Octo Cat", "email": "octo@example.com"}
+			}
+		}
+	}`)
+
+	mockDB.On("AddOrUpdateRun", mock.Anything, mock.MatchedBy(func(run models.WorkflowRun) bool {
+		return run.HeadSha == "abc1234def5678" &&
+			run.HeadCommit != nil &&
+			run.HeadCommit.Message == "Fix deploy" &&
+			run.HeadCommit.Author.Name == "Octo Cat" &&
+			run.HeadCommit.Author.Email == "octo@example.com"
+	}), mock.AnythingOfType("time.Time")).Return(true, nil)
+
+	err := handler.HandleEvent(payload, sequence)
+
+	assert.NoError(t, err)
+	mockDB.AssertExpectations(t)
+}
+
 func TestWorkflowRunHandler_HandleEvent_InvalidJSON(t *testing.T) {
 	mockDB := setupWorkflowRunTest()
-	handler := NewWorkflowRunHandler(mockDB)
+	handler := NewWorkflowRunHandler(&config.Config{}, mockDB, nil)
 
 	sequence := &models.EventSequence{
 		EventID:    "event123",
@@ -113,7 +152,7 @@ func TestWorkflowRunHandler_HandleEvent_InvalidJSON(t *testing.T) {
 
 func TestWorkflowRunHandler_HandleEvent_DatabaseError(t *testing.T) {
 	mockDB := setupWorkflowRunTest()
-	handler := NewWorkflowRunHandler(mockDB)
+	handler := NewWorkflowRunHandler(&config.Config{}, mockDB, nil)
 
 	// Create test data
 	now := time.Now()
@@ -195,7 +234,7 @@ func TestWorkflowRunHandler_HandleEvent_DifferentActions(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			mockDB := setupWorkflowRunTest()
-			handler := NewWorkflowRunHandler(mockDB)
+			handler := NewWorkflowRunHandler(&config.Config{}, mockDB, nil)
 
 			now := time.Now()
 			sequence := &models.EventSequence{
@@ -248,7 +287,7 @@ func TestWorkflowRunHandler_HandleEvent_DifferentActions(t *testing.T) {
 
 func TestWorkflowRunHandler_HandleEvent_StatusAndRepositoryMapping(t *testing.T) {
 	mockDB := setupWorkflowRunTest()
-	handler := NewWorkflowRunHandler(mockDB)
+	handler := NewWorkflowRunHandler(&config.Config{}, mockDB, nil)
 
 	now := time.Now()
 	sequence := &models.EventSequence{
@@ -305,7 +344,7 @@ func TestWorkflowRunHandler_HandleEvent_StatusAndRepositoryMapping(t *testing.T)
 
 func TestWorkflowRunHandler_HandleEvent_EmptyEventData(t *testing.T) {
 	mockDB := setupWorkflowRunTest()
-	handler := NewWorkflowRunHandler(mockDB)
+	handler := NewWorkflowRunHandler(&config.Config{}, mockDB, nil)
 
 	sequence := &models.EventSequence{
 		EventID:    "event123",
@@ -321,7 +360,7 @@ func TestWorkflowRunHandler_HandleEvent_EmptyEventData(t *testing.T) {
 
 func TestWorkflowRunHandler_HandleEvent_MalformedJSON(t *testing.T) {
 	mockDB := setupWorkflowRunTest()
-	handler := NewWorkflowRunHandler(mockDB)
+	handler := NewWorkflowRunHandler(&config.Config{}, mockDB, nil)
 
 	sequence := &models.EventSequence{
 		EventID:    "event123",
@@ -347,7 +386,7 @@ func TestWorkflowRunHandler_HandleEvent_MalformedJSON(t *testing.T) {
 // Test with minimal required fields
 func TestWorkflowRunHandler_HandleEvent_MinimalRequiredFields(t *testing.T) {
 	mockDB := setupWorkflowRunTest()
-	handler := NewWorkflowRunHandler(mockDB)
+	handler := NewWorkflowRunHandler(&config.Config{}, mockDB, nil)
 
 	now := time.Now()
 	sequence := &models.EventSequence{
@@ -398,7 +437,7 @@ func TestWorkflowRunHandler_HandleEvent_MinimalRequiredFields(t *testing.T) {
 
 func TestWorkflowRunHandler_ExtractEventTimestamp(t *testing.T) {
 	mockDB := setupWorkflowRunTest()
-	handler := NewWorkflowRunHandler(mockDB)
+	handler := NewWorkflowRunHandler(&config.Config{}, mockDB, nil)
 
 	testCases := []struct {
 		name           string
@@ -461,7 +500,7 @@ func TestWorkflowRunHandler_ExtractEventTimestamp(t *testing.T) {
 
 func TestWorkflowRunHandler_ExtractOrderingKey(t *testing.T) {
 	mockDB := setupWorkflowRunTest()
-	handler := NewWorkflowRunHandler(mockDB)
+	handler := NewWorkflowRunHandler(&config.Config{}, mockDB, nil)
 
 	testCases := []struct {
 		name           string
@@ -523,7 +562,7 @@ func TestWorkflowRunHandler_ExtractOrderingKey(t *testing.T) {
 
 func TestWorkflowRunHandler_GetStatusPriority(t *testing.T) {
 	mockDB := setupWorkflowRunTest()
-	handler := NewWorkflowRunHandler(mockDB)
+	handler := NewWorkflowRunHandler(&config.Config{}, mockDB, nil)
 
 	testCases := []struct {
 		name             string
@@ -0,0 +1,227 @@
+// Package anonymize replaces repository, owner and user names and run titles
+// in API payloads with stable pseudonyms, so a live dashboard can be shown
+// outside the organization without revealing what it is building.
+package anonymize
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Kinds of values that get a pseudonym; each is its prefix.
+const (
+	kindOwner = "org"
+	kindRepo  = "repo"
+	kindUser  = "user"
+	kindTitle = "title"
+)
+
+// Anonymizer maps names to pseudonyms derived from a secret key, so the same
+// name always reads the same while the key is unchanged. A nil Anonymizer
+// leaves everything as is.
+type Anonymizer struct {
+	key []byte
+
+	mutex sync.RWMutex
+	// originals maps repository and owner pseudonyms back to their names.
+	originals map[string]string
+	// known holds the names pseudonymized so far, which are also replaced
+	// inside free text such as alert messages.
+	known    map[string]string
+	replacer *strings.Replacer
+}
+
+// New returns an Anonymizer keyed with key, or with a random key when it is
+// empty, in which case pseudonyms change on every start.
+func New(key string) *Anonymizer {
+	k := []byte(key)
+	if len(k) == 0 {
+		k = make([]byte, 32)
+		_, _ = rand.Read(k)
+	}
+	return &Anonymizer{
+		key:       k,
+		originals: make(map[string]string),
+		known:     make(map[string]string),
+	}
+}
+
+// pseudonym returns the pseudonym of value as a kind, remembering it.
+func (a *Anonymizer) pseudonym(kind, value string) string {
+	if value == "" {
+		return value
+	}
+	mac := hmac.New(sha256.New, a.key)
+	mac.Write([]byte(kind + ":" + value))
+	alias := kind + "-" + hex.EncodeToString(mac.Sum(nil)[:4])
+
+	a.mutex.RLock()
+	_, seen := a.known[value]
+	a.mutex.RUnlock()
+	if !seen && kind != kindTitle {
+		a.mutex.Lock()
+		a.known[value] = alias
+		if kind == kindOwner || kind == kindRepo {
+			a.originals[alias] = value
+		}
+		a.replacer = nil
+		a.mutex.Unlock()
+	}
+	return alias
+}
+
+// Repository pseudonymizes a repository given as owner/name or a bare name.
+func (a *Anonymizer) Repository(repo string) string {
+	if a == nil {
+		return repo
+	}
+	if owner, name, ok := strings.Cut(repo, "/"); ok {
+		return a.pseudonym(kindOwner, owner) + "/" + a.pseudonym(kindRepo, name)
+	}
+	return a.pseudonym(kindRepo, repo)
+}
+
+// Reveal maps a repository pseudonym, owner/name or bare, back to the name it
+// stands for. Values that are not known pseudonyms are returned unchanged.
+func (a *Anonymizer) Reveal(repo string) string {
+	if a == nil {
+		return repo
+	}
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	parts := strings.Split(repo, "/")
+	for i, part := range parts {
+		if original, ok := a.originals[part]; ok {
+			parts[i] = original
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// url pseudonymizes the owner and repository in a GitHub web or REST API URL.
+func (a *Anonymizer) url(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw
+	}
+	segments := strings.Split(strings.TrimPrefix(u.Path, "/"), "/")
+	start := 0
+	if segments[0] == "repos" {
+		start = 1
+	}
+	if len(segments) < start+2 || segments[start] == "" || segments[start+1] == "" {
+		return raw
+	}
+	segments[start] = a.pseudonym(kindOwner, segments[start])
+	segments[start+1] = a.pseudonym(kindRepo, segments[start+1])
+	u.Path = "/" + strings.Join(segments, "/")
+	u.RawPath = ""
+	return u.String()
+}
+
+// text replaces the names pseudonymized so far inside free text. Names are
+// matched anywhere, so a short name may also replace part of a longer word.
+func (a *Anonymizer) text(s string) string {
+	a.mutex.RLock()
+	replacer := a.replacer
+	a.mutex.RUnlock()
+
+	if replacer == nil {
+		a.mutex.Lock()
+		names := make([]string, 0, len(a.known))
+		for name := range a.known {
+			names = append(names, name)
+		}
+		// Longest first, so owner/name wins over its parts
+		sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+		pairs := make([]string, 0, 2*len(names))
+		for _, name := range names {
+			pairs = append(pairs, name, a.known[name])
+		}
+		a.replacer = strings.NewReplacer(pairs...)
+		replacer = a.replacer
+		a.mutex.Unlock()
+	}
+	return replacer.Replace(s)
+}
+
+// JSON pseudonymizes a JSON document. Fields are recognized by name:
+// repository names, actors and logins, run titles and commit messages and
+// authors are replaced, as are the owner and repository in URLs; alert titles
+// and messages get the names seen so far replaced. Counts, timings and other
+// metrics are left intact. Bodies that are not valid JSON are returned as is.
+func (a *Anonymizer) JSON(body []byte) []byte {
+	if a == nil {
+		return body
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return body
+	}
+	out, err := json.Marshal(a.walk(doc, "", ""))
+	if err != nil {
+		return body
+	}
+	return out
+}
+
+// walk pseudonymizes value, found under key in an object held under parent.
+func (a *Anonymizer) walk(value interface{}, key, parent string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		// Collect names before free text, so a message can be matched
+		// against the repository of the same object
+		var text []string
+		for k, child := range v {
+			if k == "title" || k == "message" || k == "summary" {
+				text = append(text, k)
+				continue
+			}
+			v[k] = a.walk(child, k, key)
+		}
+		for _, k := range text {
+			v[k] = a.walk(v[k], k, key)
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = a.walk(child, key, parent)
+		}
+		return v
+	case string:
+		return a.field(v, key, parent)
+	}
+	return value
+}
+
+// field pseudonymizes a string field by its name and its parent's.
+func (a *Anonymizer) field(s, key, parent string) string {
+	switch {
+	case key == "repository" || key == "repository_name" || key == "repo" || key == "repositories" || key == "full_name":
+		return a.Repository(s)
+	case key == "name" && parent == "repository":
+		return a.pseudonym(kindRepo, s)
+	case key == "actor" || key == "triggering_actor" || key == "login":
+		return a.pseudonym(kindUser, s)
+	case key == "author" || ((key == "name" || key == "email") && parent == "author"):
+		return a.pseudonym(kindUser, s)
+	case key == "display_title" || (key == "message" && (parent == "head_commit" || parent == "change")):
+		return a.pseudonym(kindTitle, s)
+	case key == "url" || strings.HasSuffix(key, "_url"):
+		return a.url(s)
+	case key == "title" || key == "message" || key == "summary":
+		return a.text(s)
+	}
+	return s
+}
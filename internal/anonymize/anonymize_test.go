@@ -0,0 +1,115 @@
+package anonymize
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnonymizer_JSON(t *testing.T) {
+	a := New("secret")
+	body := []byte(`{
+		"workflow_runs": [{
+			"id": 42,
+			"name": "CI",
+			"repository_name": "payments",
+			"html_url": "https://github.com/octo/payments/actions/runs/42",
+			"display_title": "Add Visa tokenization",
+			"actor": "mona",
+			"triggering_actor": "hubot",
+			"head_commit": {"id": "abc", "message": "Add Visa tokenization", "author": {"name": "Mona", "email": "mona@octo.com"}},
+			"duration_seconds": 12.5
+		}],
+		"alerts": [{"title": "CI is 50% slower than usual", "message": "Run 42 of CI in octo/payments took 2m", "repository": "octo/payments"}],
+		"pagination": {"total": 1}
+	}`)
+
+	var out struct {
+		WorkflowRuns []map[string]interface{} `json:"workflow_runs"`
+		Alerts       []map[string]interface{} `json:"alerts"`
+		Pagination   map[string]interface{}   `json:"pagination"`
+	}
+	require.NoError(t, json.Unmarshal(a.JSON(body), &out))
+
+	run := out.WorkflowRuns[0]
+	repo := a.Repository("payments")
+	owner := strings.Split(a.Repository("octo/payments"), "/")[0]
+	assert.Regexp(t, `^repo-[0-9a-f]{8}$`, repo)
+	assert.Equal(t, repo, run["repository_name"])
+	assert.Equal(t, "https://github.com/"+owner+"/"+repo+"/actions/runs/42", run["html_url"])
+	assert.Regexp(t, `^title-`, run["display_title"])
+	assert.Regexp(t, `^user-`, run["actor"])
+	assert.NotEqual(t, run["actor"], run["triggering_actor"])
+	assert.Equal(t, "CI", run["name"])
+	assert.Equal(t, 12.5, run["duration_seconds"])
+	assert.Equal(t, float64(42), run["id"])
+
+	commit := run["head_commit"].(map[string]interface{})
+	assert.Equal(t, run["display_title"], commit["message"])
+	author := commit["author"].(map[string]interface{})
+	assert.Regexp(t, `^user-`, author["name"])
+	assert.Regexp(t, `^user-`, author["email"])
+
+	alert := out.Alerts[0]
+	assert.Equal(t, owner+"/"+repo, alert["repository"])
+	assert.Equal(t, "Run 42 of CI in "+owner+"/"+repo+" took 2m", alert["message"])
+	assert.Equal(t, "CI is 50% slower than usual", alert["title"])
+	assert.Equal(t, float64(1), out.Pagination["total"])
+
+	assert.NotContains(t, string(a.JSON(body)), "payments")
+	assert.NotContains(t, string(a.JSON(body)), "mona")
+}
+
+func TestAnonymizer_StablePseudonyms(t *testing.T) {
+	a, b := New("secret"), New("secret")
+	assert.Equal(t, a.Repository("octo/app"), b.Repository("octo/app"))
+	assert.NotEqual(t, a.Repository("octo/app"), New("other").Repository("octo/app"))
+	assert.NotEqual(t, New("").Repository("octo/app"), New("").Repository("octo/app"), "an empty key is random")
+}
+
+func TestAnonymizer_Reveal(t *testing.T) {
+	a := New("secret")
+	full := a.Repository("octo/app")
+	bare := a.Repository("api")
+
+	assert.Equal(t, "octo/app", a.Reveal(full))
+	assert.Equal(t, "api", a.Reveal(bare))
+	assert.Equal(t, "octo/unknown", a.Reveal("octo/unknown"))
+}
+
+func TestAnonymizer_Passthrough(t *testing.T) {
+	var a *Anonymizer
+	assert.Equal(t, "octo/app", a.Repository("octo/app"))
+	assert.Equal(t, `{"repository":"octo/app"}`, string(a.JSON([]byte(`{"repository":"octo/app"}`))))
+
+	assert.Equal(t, "not json", string(New("secret").JSON([]byte("not json"))))
+	assert.Equal(t, "https://example.com/", New("secret").url("https://example.com/"))
+}
+
+func TestAnonymizer_APIURL(t *testing.T) {
+	a := New("secret")
+	assert.Equal(t, "https://api.github.com/repos/"+a.Repository("octo/app")+"/actions/runs/1",
+		a.url("https://api.github.com/repos/octo/app/actions/runs/1"))
+}
+
+func TestAnonymizer_WorkflowChanges(t *testing.T) {
+	a := New("secret")
+	body := []byte(`{"workflow_changes": [{
+		"repository": "octo/payments",
+		"change": {
+			"repository": "octo/payments",
+			"message": "Pin the Visa SDK",
+			"author": "mona",
+			"html_url": "https://github.com/octo/payments/commit/abc123"
+		}
+	}]}`)
+
+	out := string(a.JSON(body))
+	assert.NotContains(t, out, "Visa")
+	assert.NotContains(t, out, "mona")
+	assert.Contains(t, out, `"author":"`+a.pseudonym(kindUser, "mona")+`"`)
+	assert.Contains(t, out, `"message":"`+a.pseudonym(kindTitle, "Pin the Visa SDK")+`"`)
+}
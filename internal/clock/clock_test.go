@@ -0,0 +1,31 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFake(t *testing.T) {
+	start := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+	f := NewFake(start)
+	assert.Equal(t, start, f.Now())
+
+	f.Advance(90 * time.Minute)
+	assert.Equal(t, start.Add(90*time.Minute), f.Now())
+
+	f.Set(start)
+	assert.Equal(t, start, f.Now())
+}
+
+func TestReal(t *testing.T) {
+	assert.WithinDuration(t, time.Now(), Real().Now(), time.Second)
+}
+
+func TestStartingAt(t *testing.T) {
+	start := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+	c := StartingAt(start)
+	assert.WithinDuration(t, start, c.Now(), time.Second)
+	assert.False(t, c.Now().Before(start))
+}
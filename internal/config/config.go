@@ -2,42 +2,393 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"time"
+
+	"github.com/gateixeira/live-actions/internal/clock"
+	"github.com/gateixeira/live-actions/internal/jobnames"
+	"github.com/gateixeira/live-actions/models"
 )
 
+// defaultRemoteWriteMetrics are the node_exporter series kept from remote
+// write unless REMOTE_WRITE_METRICS says otherwise.
+const defaultRemoteWriteMetrics = "node_load1,node_memory_MemAvailable_bytes,node_memory_MemTotal_bytes,node_filesystem_avail_bytes"
+
+// DefaultSLOWindowDays is the rolling window of a workflow SLO that does not
+// set one.
+const DefaultSLOWindowDays = 28
+
+// maxSLOWindowDays bounds the window of a workflow SLO.
+const maxSLOWindowDays = 90
+
 type Vars struct {
-	WebhookSecret          string
-	Port                   string
-	DatabasePath           string
-	LogLevel               string
-	TLSEnabled             bool
-	Environment            string
-	DataRetentionDays      int
-	CleanupIntervalHours   int
-	StaleJobThresholdHours int
+	WebhookSecret               string
+	AdminToken                  string
+	FederationToken             string
+	FederationPeers             map[string]string
+	FederationPeerTokens        map[string]string
+	Port                        string
+	DatabasePath                string
+	DatabaseEncryptionKey       string
+	DatabaseEncryptionKeyFile   string
+	LogLevel                    string
+	LogFormat                   string
+	LogSampling                 bool
+	LogModuleLevels             map[string]string
+	LogPayloads                 bool
+	TLSEnabled                  bool
+	Environment                 string
+	DataRetentionDays           int
+	CleanupIntervalHours        int
+	StaleJobThresholdHours      int
+	JobSuccessSamplePercent     int
+	LongRunningThresholdMinutes int
+	FeedWorkflowFilter          []string
+	RepoGroups                  map[string][]string
+	RunnerCapacity              map[string]int
+	WorkflowSLOs                map[string]models.WorkflowSLO
+	SLOBurnRateAlert            int
+	AlertWebhookURL             string
+	RegressionThresholdPercent  int
+	RegressionAlerts            bool
+	RunnerOfflineMinutes        int
+	ProcessingLagSLOSeconds     int
+	EventBacklogWarning         int
+	ReadyMaxPendingEvents       int
+	ReadyMaxPendingAgeSeconds   int
+	DedupeWindowSeconds         int
+	ThroughputWindowMinutes     int
+	JobNameRules                []string
+	JobNameMatchPercent         int
+	GitHubAPIURL                string
+	CanaryToken                 string
+	GitHubAPIToken              string
+	WorkflowChangeShiftPercent  int
+	CanaryRepository            string
+	CanaryWorkflow              string
+	CanaryRef                   string
+	CanaryIntervalMinutes       int
+	CanaryTimeoutMinutes        int
+	CanarySlowSeconds           int
+	RemoteWriteToken            string
+	RemoteWriteMetrics          []string
+	RemoteWriteHostLabel        string
+	RunnerHeartbeatToken        string
+	SnapshotDir                 string
+	SnapshotIntervalSeconds     int
+	SnapshotFields              []string
+	SnapshotS3Bucket            string
+	SnapshotS3Prefix            string
+	SnapshotS3Region            string
+	SnapshotS3Endpoint          string
+	SnapshotS3AccessKey         string
+	SnapshotS3SecretKey         string
+	SnapshotS3SessionToken      string
+	AccessLog                   string
+	AccessLogFormat             string
+	TrustedProxies              []string
+	PprofEnabled                bool
+	PprofAddr                   string
+	SSEMaxClients               int
+	SSEOverflowMode             string
+	SSERetryAfterSeconds        int
+	RestartDowntimeSeconds      int
+	MaxLabelsPerJob             int
+	MaxTrackedLabels            int
+	WaitForMigrations           bool
+	MigrationTimeoutSeconds     int
+	Anonymize                   bool
+	AnonymizeKey                string
+	ClockStart                  string
 }
 
 type Config struct {
 	Vars Vars
+	// Clock tells the time analytics windows, retention and alert checks are
+	// measured from; nil means the wall clock
+	Clock clock.Clock
 }
 
 // NewConfig creates and initializes a new application config.
 func NewConfig() (*Config, error) {
 	vars := Vars{
-		WebhookSecret:        os.Getenv("WEBHOOK_SECRET"),
-		Port:                 getEnvOrDefault("PORT", "8080"),
-		DatabasePath:         getEnvOrDefault("DATABASE_PATH", "./data/live-actions.db"),
-		LogLevel:             getEnvOrDefault("LOG_LEVEL", "info"),
-		TLSEnabled:           getEnvOrDefault("TLS_ENABLED", "false") == "true",
-		Environment:          getEnvOrDefault("ENVIRONMENT", "development"),
-		DataRetentionDays:      getEnvOrDefaultInt("DATA_RETENTION_DAYS", 30),      // Default 1 month
-		CleanupIntervalHours:   getEnvOrDefaultInt("CLEANUP_INTERVAL_HOURS", 24),   // Daily cleanup
-		StaleJobThresholdHours: getEnvOrDefaultInt("STALE_JOB_THRESHOLD_HOURS", 24), // Jobs queued/in_progress longer than this are considered stale
+		WebhookSecret:               os.Getenv("WEBHOOK_SECRET"),
+		AdminToken:                  os.Getenv("ADMIN_TOKEN"),                               // Empty disables the admin API
+		FederationToken:             os.Getenv("FEDERATION_TOKEN"),                          // Token peers present to read this instance's summary; empty disables
+		FederationPeers:             parseKeyValueList(os.Getenv("FEDERATION_PEERS")),       // e.g. "eu=https://actions-eu.example.com"
+		FederationPeerTokens:        parseKeyValueList(os.Getenv("FEDERATION_PEER_TOKENS")), // e.g. "eu=secret"
+		Port:                        getEnvOrDefault("PORT", "8080"),
+		DatabasePath:                getEnvOrDefault("DATABASE_PATH", "./data/live-actions.db"),
+		DatabaseEncryptionKey:       os.Getenv("DATABASE_ENCRYPTION_KEY"),      // Encrypts the database with SQLCipher; empty leaves it unencrypted
+		DatabaseEncryptionKeyFile:   os.Getenv("DATABASE_ENCRYPTION_KEY_FILE"), // Reads the key from a file, e.g. one a KMS or secrets agent renders
+		LogLevel:                    getEnvOrDefault("LOG_LEVEL", "info"),
+		LogFormat:                   getEnvOrDefault("LOG_FORMAT", "console"),
+		LogSampling:                 getEnvOrDefault("LOG_SAMPLING", "true") == "true",
+		LogModuleLevels:             parseKeyValueList(os.Getenv("LOG_MODULE_LEVELS")), // e.g. "sse=debug,http=warn"
+		LogPayloads:                 getEnvOrDefault("LOG_PAYLOADS", "true") == "true",
+		TLSEnabled:                  getEnvOrDefault("TLS_ENABLED", "false") == "true",
+		Environment:                 getEnvOrDefault("ENVIRONMENT", "development"),
+		DataRetentionDays:           getEnvOrDefaultInt("DATA_RETENTION_DAYS", 30),         // Default 1 month
+		CleanupIntervalHours:        getEnvOrDefaultInt("CLEANUP_INTERVAL_HOURS", 24),      // Daily cleanup
+		StaleJobThresholdHours:      getEnvOrDefaultInt("STALE_JOB_THRESHOLD_HOURS", 24),   // Jobs queued/in_progress longer than this are considered stale
+		JobSuccessSamplePercent:     getEnvOrDefaultInt("JOB_SUCCESS_SAMPLE_PERCENT", 100), // Share of successful jobs kept in full; failures are always kept
+		LongRunningThresholdMinutes: getEnvOrDefaultInt("LONG_RUNNING_THRESHOLD_MINUTES", 60),
+		FeedWorkflowFilter:          parseList(os.Getenv("FEED_WORKFLOW_FILTER")), // e.g. "deploy,release"
+		AlertWebhookURL:             os.Getenv("ALERT_WEBHOOK_URL"),
+		RegressionThresholdPercent:  getEnvOrDefaultInt("REGRESSION_THRESHOLD_PERCENT", 50), // Runs this much slower than the trailing median are regressions
+		RegressionAlerts:            getEnvOrDefault("REGRESSION_ALERTS", "false") == "true",
+		RunnerOfflineMinutes:        getEnvOrDefaultInt("RUNNER_OFFLINE_MINUTES", 15),            // Self-hosted pools with a growing queue and no job started for this long are reported offline
+		ProcessingLagSLOSeconds:     getEnvOrDefaultInt("PROCESSING_LAG_SLO_SECONDS", 60),        // Webhook events should be processed within this long of being received
+		SLOBurnRateAlert:            getEnvOrDefaultInt("SLO_BURN_RATE_ALERT", 10),               // Workflow SLOs burning their error budget this many times too fast over 1h and 6h raise an alert; 0 disables
+		EventBacklogWarning:         getEnvOrDefaultInt("EVENT_BACKLOG_WARNING", 500),            // API responses warn that data may be delayed while this many webhook events are pending
+		ReadyMaxPendingEvents:       getEnvOrDefaultInt("READY_MAX_PENDING_EVENTS", 100),         // The traffic gate holds while more webhook events than this are pending
+		ReadyMaxPendingAgeSeconds:   getEnvOrDefaultInt("READY_MAX_PENDING_AGE_SECONDS", 120),    // The traffic gate holds while a webhook event has been pending for longer than this
+		DedupeWindowSeconds:         getEnvOrDefaultInt("DEDUPE_WINDOW_SECONDS", 10),             // Repeated status updates for the same job or run within this window are dropped; 0 disables
+		ThroughputWindowMinutes:     getEnvOrDefaultInt("THROUGHPUT_WINDOW_MINUTES", 5),          // Jobs started and completed per minute are averaged over this sliding window
+		JobNameRules:                parseJobNameRules(os.Getenv("JOB_NAME_RULES")),              // Normalizations merging renamed jobs in analytics; "none" disables
+		JobNameMatchPercent:         getEnvOrDefaultInt("JOB_NAME_MATCH_PERCENT", 85),            // Similarity at which differently named jobs are proposed for merging
+		GitHubAPIURL:                getEnvOrDefault("GITHUB_API_URL", "https://api.github.com"), // e.g. "https://ghes.example.com/api/v3"
+		CanaryToken:                 os.Getenv("CANARY_GITHUB_TOKEN"),                            // Token allowed to dispatch the canary workflow; empty disables the canary
+		GitHubAPIToken:              os.Getenv("GITHUB_API_TOKEN"),                               // Read-only token to look up workflow file changes; empty disables workflow change correlation
+		WorkflowChangeShiftPercent:  getEnvOrDefaultInt("WORKFLOW_CHANGE_SHIFT_PERCENT", 20),     // A workflow file change is flagged when the failure rate rises by this many points after it
+		CanaryRepository:            os.Getenv("CANARY_REPOSITORY"),                              // e.g. "octo-org/actions-canary"
+		CanaryWorkflow:              os.Getenv("CANARY_WORKFLOW"),                                // Workflow file name or ID, e.g. "canary.yml"
+		CanaryRef:                   getEnvOrDefault("CANARY_REF", "main"),
+		CanaryIntervalMinutes:       getEnvOrDefaultInt("CANARY_INTERVAL_MINUTES", 30),
+		CanaryTimeoutMinutes:        getEnvOrDefaultInt("CANARY_TIMEOUT_MINUTES", 15),
+		CanarySlowSeconds:           getEnvOrDefaultInt("CANARY_SLOW_SECONDS", 300), // Canary runs taking longer than this from dispatch to completion raise an alert
+		RemoteWriteToken:            os.Getenv("REMOTE_WRITE_TOKEN"),                // Token exporters present to push metrics; empty disables remote write
+		RemoteWriteMetrics:          parseList(getEnvOrDefault("REMOTE_WRITE_METRICS", defaultRemoteWriteMetrics)),
+		RemoteWriteHostLabel:        getEnvOrDefault("REMOTE_WRITE_HOST_LABEL", "instance"),
+		RunnerHeartbeatToken:        os.Getenv("RUNNER_HEARTBEAT_TOKEN"), // Token runner hosts present to send heartbeats; empty disables heartbeats
+		SnapshotDir:                 os.Getenv("SNAPSHOT_DIR"),           // Directory for the public JSON snapshot; empty disables
+		SnapshotIntervalSeconds:     getEnvOrDefaultInt("SNAPSHOT_INTERVAL_SECONDS", 60),
+		SnapshotFields:              parseList(os.Getenv("SNAPSHOT_FIELDS")), // Empty publishes all fields
+		SnapshotS3Bucket:            os.Getenv("SNAPSHOT_S3_BUCKET"),         // Bucket to upload the public JSON snapshot to; empty disables
+		SnapshotS3Prefix:            os.Getenv("SNAPSHOT_S3_PREFIX"),
+		SnapshotS3Region:            getEnvOrDefault("SNAPSHOT_S3_REGION", "us-east-1"),
+		SnapshotS3Endpoint:          os.Getenv("SNAPSHOT_S3_ENDPOINT"), // S3-compatible store, addressed path-style; empty uses AWS
+		SnapshotS3AccessKey:         os.Getenv("SNAPSHOT_S3_ACCESS_KEY_ID"),
+		SnapshotS3SecretKey:         os.Getenv("SNAPSHOT_S3_SECRET_ACCESS_KEY"),
+		SnapshotS3SessionToken:      os.Getenv("SNAPSHOT_S3_SESSION_TOKEN"),
+		AccessLog:                   os.Getenv("ACCESS_LOG"), // "stdout" or a file path; empty disables
+		AccessLogFormat:             getEnvOrDefault("ACCESS_LOG_FORMAT", "combined"),
+		TrustedProxies:              parseList(os.Getenv("TRUSTED_PROXIES")), // IPs or CIDRs allowed to set the client IP through X-Forwarded-For; empty trusts none
+		PprofEnabled:                getEnvOrDefault("PPROF_ENABLED", "false") == "true",
+		PprofAddr:                   getEnvOrDefault("PPROF_ADDR", "127.0.0.1:6060"), // Internal listener, keep off public interfaces
+		SSEMaxClients:               getEnvOrDefaultInt("SSE_MAX_CLIENTS", 0),        // Concurrent event stream connections; 0 is unlimited
+		SSEOverflowMode:             getEnvOrDefault("SSE_OVERFLOW_MODE", "reject"),  // "reject" answers 503, "poll" tells clients to reconnect later
+		SSERetryAfterSeconds:        getEnvOrDefaultInt("SSE_RETRY_AFTER_SECONDS", 30),
+		RestartDowntimeSeconds:      getEnvOrDefaultInt("RESTART_DOWNTIME_SECONDS", 15),        // Downtime announced to dashboards when the server shuts down
+		MaxLabelsPerJob:             getEnvOrDefaultInt("MAX_LABELS_PER_JOB", 20),              // Jobs with more runner labels are recorded under "(other)" in label metrics; 0 is unlimited
+		MaxTrackedLabels:            getEnvOrDefaultInt("MAX_TRACKED_LABELS", 100),             // Distinct runner labels given their own metrics series; 0 is unlimited
+		WaitForMigrations:           getEnvOrDefault("WAIT_FOR_MIGRATIONS", "false") == "true", // Leave migrating to another instance and wait for the schema
+		MigrationTimeoutSeconds:     getEnvOrDefaultInt("MIGRATION_TIMEOUT_SECONDS", 600),
+		Anonymize:                   getEnvOrDefault("ANONYMIZE", "false") == "true", // Pseudonymize names in API and SSE responses for demos
+		AnonymizeKey:                os.Getenv("ANONYMIZE_KEY"),                      // Keeps pseudonyms stable across restarts; empty picks a random key
+		ClockStart:                  os.Getenv("CLOCK_START"),                        // Demo mode: the clock starts at this RFC 3339 time; empty uses the wall clock
+	}
+
+	repoGroups, err := parseRepoGroups(os.Getenv("REPO_GROUPS")) // e.g. "payments=api,billing;platform=infra"
+	if err != nil {
+		return nil, err
 	}
+	vars.RepoGroups = repoGroups
 
-	config := &Config{Vars: vars}
+	runnerCapacity, err := parseRunnerCapacity(os.Getenv("RUNNER_CAPACITY")) // e.g. "linux-large=20,gpu=4"
+	if err != nil {
+		return nil, err
+	}
+	vars.RunnerCapacity = runnerCapacity
+
+	workflowSLOs, err := parseWorkflowSLOs(os.Getenv("WORKFLOW_SLOS")) // e.g. "deploy=app,Deploy,success=99,p90=15m,window=28d"
+	if err != nil {
+		return nil, err
+	}
+	vars.WorkflowSLOs = workflowSLOs
+
+	if vars.DatabaseEncryptionKeyFile != "" {
+		if vars.DatabaseEncryptionKey != "" {
+			return nil, fmt.Errorf("DATABASE_ENCRYPTION_KEY and DATABASE_ENCRYPTION_KEY_FILE are mutually exclusive")
+		}
+		key, err := os.ReadFile(vars.DatabaseEncryptionKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read DATABASE_ENCRYPTION_KEY_FILE: %w", err)
+		}
+		vars.DatabaseEncryptionKey = strings.TrimSpace(string(key))
+		if vars.DatabaseEncryptionKey == "" {
+			return nil, fmt.Errorf("DATABASE_ENCRYPTION_KEY_FILE %s is empty", vars.DatabaseEncryptionKeyFile)
+		}
+	}
+
+	config := &Config{Vars: vars, Clock: clock.Real()}
+
+	if vars.ClockStart != "" {
+		start, err := time.Parse(time.RFC3339, vars.ClockStart)
+		if err != nil {
+			return nil, fmt.Errorf("CLOCK_START must be an RFC 3339 time such as 2024-05-02T10:00:00Z, got %q", vars.ClockStart)
+		}
+		if config.IsProduction() {
+			return nil, fmt.Errorf("CLOCK_START is for demos and cannot be set in production")
+		}
+		config.Clock = clock.StartingAt(start)
+	}
+
+	if vars.LogFormat != "console" && vars.LogFormat != "json" {
+		return nil, fmt.Errorf("LOG_FORMAT must be 'console' or 'json', got %q", vars.LogFormat)
+	}
+
+	if vars.AccessLogFormat != "combined" && vars.AccessLogFormat != "json" {
+		return nil, fmt.Errorf("ACCESS_LOG_FORMAT must be 'combined' or 'json', got %q", vars.AccessLogFormat)
+	}
+
+	for _, proxy := range vars.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			return nil, fmt.Errorf("TRUSTED_PROXIES entries must be IP addresses or CIDRs, got %q", proxy)
+		}
+	}
+
+	if vars.AlertWebhookURL != "" {
+		if u, err := url.Parse(vars.AlertWebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("ALERT_WEBHOOK_URL must be an http(s) URL, got %q", vars.AlertWebhookURL)
+		}
+	}
+
+	for name, peerURL := range vars.FederationPeers {
+		if u, err := url.Parse(peerURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("FEDERATION_PEERS entry %q must be an http(s) URL, got %q", name, peerURL)
+		}
+	}
+	for name := range vars.FederationPeerTokens {
+		if _, ok := vars.FederationPeers[name]; !ok {
+			return nil, fmt.Errorf("FEDERATION_PEER_TOKENS names unknown peer %q", name)
+		}
+	}
+
+	if vars.JobSuccessSamplePercent < 1 || vars.JobSuccessSamplePercent > 100 {
+		return nil, fmt.Errorf("JOB_SUCCESS_SAMPLE_PERCENT must be between 1 and 100, got %d", vars.JobSuccessSamplePercent)
+	}
+
+	if vars.RegressionThresholdPercent <= 0 {
+		return nil, fmt.Errorf("REGRESSION_THRESHOLD_PERCENT must be positive, got %d", vars.RegressionThresholdPercent)
+	}
+
+	if vars.RunnerOfflineMinutes <= 0 {
+		return nil, fmt.Errorf("RUNNER_OFFLINE_MINUTES must be positive, got %d", vars.RunnerOfflineMinutes)
+	}
+
+	if vars.ProcessingLagSLOSeconds <= 0 {
+		return nil, fmt.Errorf("PROCESSING_LAG_SLO_SECONDS must be positive, got %d", vars.ProcessingLagSLOSeconds)
+	}
+
+	if vars.SLOBurnRateAlert < 0 {
+		return nil, fmt.Errorf("SLO_BURN_RATE_ALERT must not be negative, got %d", vars.SLOBurnRateAlert)
+	}
+
+	if vars.EventBacklogWarning <= 0 {
+		return nil, fmt.Errorf("EVENT_BACKLOG_WARNING must be positive, got %d", vars.EventBacklogWarning)
+	}
+
+	if vars.ReadyMaxPendingEvents < 0 {
+		return nil, fmt.Errorf("READY_MAX_PENDING_EVENTS must not be negative, got %d", vars.ReadyMaxPendingEvents)
+	}
+
+	if vars.ReadyMaxPendingAgeSeconds <= 0 {
+		return nil, fmt.Errorf("READY_MAX_PENDING_AGE_SECONDS must be positive, got %d", vars.ReadyMaxPendingAgeSeconds)
+	}
+
+	if vars.DedupeWindowSeconds < 0 {
+		return nil, fmt.Errorf("DEDUPE_WINDOW_SECONDS must not be negative, got %d", vars.DedupeWindowSeconds)
+	}
+
+	if vars.ThroughputWindowMinutes < 1 || vars.ThroughputWindowMinutes > 60 {
+		return nil, fmt.Errorf("THROUGHPUT_WINDOW_MINUTES must be between 1 and 60, got %d", vars.ThroughputWindowMinutes)
+	}
+
+	if err := jobnames.ValidateRules(vars.JobNameRules); err != nil {
+		return nil, fmt.Errorf("JOB_NAME_RULES: %w", err)
+	}
+
+	if vars.JobNameMatchPercent < 50 || vars.JobNameMatchPercent > 100 {
+		return nil, fmt.Errorf("JOB_NAME_MATCH_PERCENT must be between 50 and 100, got %d", vars.JobNameMatchPercent)
+	}
+
+	if vars.WorkflowChangeShiftPercent < 1 || vars.WorkflowChangeShiftPercent > 100 {
+		return nil, fmt.Errorf("WORKFLOW_CHANGE_SHIFT_PERCENT must be between 1 and 100, got %d", vars.WorkflowChangeShiftPercent)
+	}
+
+	if vars.CanaryToken != "" || vars.GitHubAPIToken != "" {
+		if u, err := url.Parse(vars.GitHubAPIURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("GITHUB_API_URL must be an http(s) URL, got %q", vars.GitHubAPIURL)
+		}
+	}
+
+	if vars.CanaryToken != "" {
+		if owner, name, ok := strings.Cut(vars.CanaryRepository, "/"); !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+			return nil, fmt.Errorf("CANARY_REPOSITORY must be in owner/name form, got %q", vars.CanaryRepository)
+		}
+		if vars.CanaryWorkflow == "" {
+			return nil, fmt.Errorf("CANARY_WORKFLOW is required when CANARY_GITHUB_TOKEN is set")
+		}
+		if vars.CanaryIntervalMinutes < 5 {
+			return nil, fmt.Errorf("CANARY_INTERVAL_MINUTES must be at least 5, got %d", vars.CanaryIntervalMinutes)
+		}
+		if vars.CanaryTimeoutMinutes <= 0 || vars.CanaryTimeoutMinutes >= vars.CanaryIntervalMinutes {
+			return nil, fmt.Errorf("CANARY_TIMEOUT_MINUTES must be positive and below CANARY_INTERVAL_MINUTES, got %d", vars.CanaryTimeoutMinutes)
+		}
+		if vars.CanarySlowSeconds <= 0 {
+			return nil, fmt.Errorf("CANARY_SLOW_SECONDS must be positive, got %d", vars.CanarySlowSeconds)
+		}
+	}
+
+	if vars.RemoteWriteToken != "" {
+		if len(vars.RemoteWriteMetrics) == 0 {
+			return nil, fmt.Errorf("REMOTE_WRITE_METRICS must list at least one metric when REMOTE_WRITE_TOKEN is set")
+		}
+		if vars.RemoteWriteHostLabel == "" {
+			return nil, fmt.Errorf("REMOTE_WRITE_HOST_LABEL must not be empty when REMOTE_WRITE_TOKEN is set")
+		}
+	}
+
+	if vars.SnapshotS3Bucket != "" && (vars.SnapshotS3AccessKey == "" || vars.SnapshotS3SecretKey == "") {
+		return nil, fmt.Errorf("SNAPSHOT_S3_ACCESS_KEY_ID and SNAPSHOT_S3_SECRET_ACCESS_KEY are required when SNAPSHOT_S3_BUCKET is set")
+	}
+
+	if (vars.SnapshotDir != "" || vars.SnapshotS3Bucket != "") && vars.SnapshotIntervalSeconds < 10 {
+		return nil, fmt.Errorf("SNAPSHOT_INTERVAL_SECONDS must be at least 10, got %d", vars.SnapshotIntervalSeconds)
+	}
+
+	if vars.MaxLabelsPerJob < 0 {
+		return nil, fmt.Errorf("MAX_LABELS_PER_JOB must not be negative, got %d", vars.MaxLabelsPerJob)
+	}
+
+	if vars.MaxTrackedLabels < 0 {
+		return nil, fmt.Errorf("MAX_TRACKED_LABELS must not be negative, got %d", vars.MaxTrackedLabels)
+	}
+
+	if vars.MigrationTimeoutSeconds < 1 {
+		return nil, fmt.Errorf("MIGRATION_TIMEOUT_SECONDS must be at least 1, got %d", vars.MigrationTimeoutSeconds)
+	}
+
+	if vars.SSEMaxClients < 0 {
+		return nil, fmt.Errorf("SSE_MAX_CLIENTS must not be negative, got %d", vars.SSEMaxClients)
+	}
+
+	if vars.SSEOverflowMode != "reject" && vars.SSEOverflowMode != "poll" {
+		return nil, fmt.Errorf("SSE_OVERFLOW_MODE must be 'reject' or 'poll', got %q", vars.SSEOverflowMode)
+	}
+
+	if vars.SSERetryAfterSeconds <= 0 {
+		return nil, fmt.Errorf("SSE_RETRY_AFTER_SECONDS must be positive, got %d", vars.SSERetryAfterSeconds)
+	}
+
+	if vars.RestartDowntimeSeconds <= 0 {
+		return nil, fmt.Errorf("RESTART_DOWNTIME_SECONDS must be positive, got %d", vars.RestartDowntimeSeconds)
+	}
 
 	// Validate critical configuration in production
 	if config.IsProduction() {
@@ -66,6 +417,148 @@ func getEnvOrDefaultInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// parseList parses a comma-separated list, dropping empty entries.
+func parseList(value string) []string {
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// parseJobNameRules parses the comma-separated job name rules, defaulting to
+// jobnames.DefaultRules. "none" disables normalization.
+func parseJobNameRules(value string) []string {
+	switch strings.TrimSpace(value) {
+	case "":
+		return jobnames.DefaultRules
+	case "none":
+		return []string{}
+	}
+	return parseList(value)
+}
+
+// parseKeyValueList parses a comma-separated list of key=value pairs.
+// Malformed entries are ignored.
+func parseKeyValueList(value string) map[string]string {
+	result := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		key, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || key == "" {
+			continue
+		}
+		result[strings.TrimSpace(key)] = strings.TrimSpace(val)
+	}
+	return result
+}
+
+// parseRepoGroups parses semicolon-separated name=repo,repo group definitions.
+func parseRepoGroups(value string) (map[string][]string, error) {
+	groups := make(map[string][]string)
+	for _, def := range strings.Split(value, ";") {
+		if def = strings.TrimSpace(def); def == "" {
+			continue
+		}
+		name, repos, ok := strings.Cut(def, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("REPO_GROUPS entries must be name=repo,repo, got %q", def)
+		}
+		list := parseList(repos)
+		if len(list) == 0 {
+			return nil, fmt.Errorf("REPO_GROUPS group %q has no repositories", name)
+		}
+		groups[name] = list
+	}
+	return groups, nil
+}
+
+// parseWorkflowSLOs parses semicolon-separated SLO definitions of the form
+// name=repository,workflow followed by success=<percent>, p<N>=<duration>
+// and window=<days>d options.
+func parseWorkflowSLOs(value string) (map[string]models.WorkflowSLO, error) {
+	slos := make(map[string]models.WorkflowSLO)
+	for _, def := range strings.Split(value, ";") {
+		if def = strings.TrimSpace(def); def == "" {
+			continue
+		}
+		name, rest, ok := strings.Cut(def, "=")
+		name = strings.TrimSpace(name)
+		fields := parseList(rest)
+		if !ok || name == "" || len(fields) < 2 {
+			return nil, fmt.Errorf("WORKFLOW_SLOS entries must be name=repository,workflow,option=value, got %q", def)
+		}
+
+		slo := models.WorkflowSLO{Name: name, Repository: fields[0], Workflow: fields[1], WindowDays: DefaultSLOWindowDays, Source: "config"}
+		for _, option := range fields[2:] {
+			key, val, _ := strings.Cut(option, "=")
+			key, val = strings.TrimSpace(key), strings.TrimSpace(val)
+			var err error
+			switch {
+			case key == "success":
+				slo.SuccessTarget, err = strconv.ParseFloat(val, 64)
+			case key == "window":
+				slo.WindowDays, err = strconv.Atoi(strings.TrimSuffix(val, "d"))
+			case strings.HasPrefix(key, "p"):
+				var target time.Duration
+				if slo.DurationPercentile, err = strconv.Atoi(key[1:]); err == nil {
+					target, err = time.ParseDuration(val)
+					slo.DurationTargetSeconds = int(target.Seconds())
+				}
+			default:
+				err = fmt.Errorf("unknown option")
+			}
+			if err != nil {
+				return nil, fmt.Errorf("WORKFLOW_SLOS SLO %q has an invalid option %q", name, option)
+			}
+		}
+		if err := ValidateWorkflowSLO(slo); err != nil {
+			return nil, fmt.Errorf("WORKFLOW_SLOS SLO %q: %w", name, err)
+		}
+		slos[name] = slo
+	}
+	return slos, nil
+}
+
+// ValidateWorkflowSLO checks that an SLO names a workflow, has at least one
+// objective and that its targets and window are in range.
+func ValidateWorkflowSLO(slo models.WorkflowSLO) error {
+	if slo.Repository == "" || slo.Workflow == "" {
+		return fmt.Errorf("repository and workflow are required")
+	}
+	if slo.SuccessTarget == 0 && slo.DurationTargetSeconds == 0 {
+		return fmt.Errorf("a success or duration target is required")
+	}
+	if slo.SuccessTarget < 0 || slo.SuccessTarget >= 100 {
+		return fmt.Errorf("success target must be above 0 and below 100, got %g", slo.SuccessTarget)
+	}
+	if slo.DurationTargetSeconds < 0 || (slo.DurationTargetSeconds > 0 && (slo.DurationPercentile < 1 || slo.DurationPercentile > 99)) {
+		return fmt.Errorf("duration target must be positive with a percentile between 1 and 99")
+	}
+	if slo.WindowDays < 1 || slo.WindowDays > maxSLOWindowDays {
+		return fmt.Errorf("window must be between 1 and %d days, got %d", maxSLOWindowDays, slo.WindowDays)
+	}
+	return nil
+}
+
+// parseRunnerCapacity parses label=runners pairs, the number of runners each
+// label can use at once.
+func parseRunnerCapacity(value string) (map[string]int, error) {
+	capacity := make(map[string]int)
+	for _, pair := range parseList(value) {
+		label, runners, ok := strings.Cut(pair, "=")
+		label = strings.TrimSpace(label)
+		n, err := strconv.Atoi(strings.TrimSpace(runners))
+		if !ok || label == "" || err != nil || n <= 0 {
+			return nil, fmt.Errorf("RUNNER_CAPACITY entries must be label=runners with a positive runner count, got %q", pair)
+		}
+		capacity[label] = n
+	}
+	return capacity, nil
+}
+
 func (c *Config) GetDatabasePath() string {
 	return c.Vars.DatabasePath
 }
@@ -94,3 +587,91 @@ func (c *Config) GetCleanupInterval() time.Duration {
 func (c *Config) GetStaleJobThreshold() time.Duration {
 	return time.Duration(c.Vars.StaleJobThresholdHours) * time.Hour
 }
+
+// GetRestartDowntime returns the downtime announced to event stream clients on shutdown
+func (c *Config) GetRestartDowntime() time.Duration {
+	return time.Duration(c.Vars.RestartDowntimeSeconds) * time.Second
+}
+
+// GetRunnerOfflineThreshold returns how long a self-hosted pool may go without starting a queued job before it is reported offline
+func (c *Config) GetRunnerOfflineThreshold() time.Duration {
+	return time.Duration(c.Vars.RunnerOfflineMinutes) * time.Minute
+}
+
+// GetProcessingLagSLO returns how long webhook events may wait between being received and processed
+func (c *Config) GetProcessingLagSLO() time.Duration {
+	return time.Duration(c.Vars.ProcessingLagSLOSeconds) * time.Second
+}
+
+// GetReadyMaxPendingAge returns how long a webhook event may have been pending
+// before the traffic gate holds
+func (c *Config) GetReadyMaxPendingAge() time.Duration {
+	return time.Duration(c.Vars.ReadyMaxPendingAgeSeconds) * time.Second
+}
+
+// GetThroughputWindow returns the sliding window job throughput is averaged over
+func (c *Config) GetThroughputWindow() time.Duration {
+	return time.Duration(c.Vars.ThroughputWindowMinutes) * time.Minute
+}
+
+// GetDedupeWindow returns how long repeated status updates for the same job or run are suppressed
+func (c *Config) GetDedupeWindow() time.Duration {
+	return time.Duration(c.Vars.DedupeWindowSeconds) * time.Second
+}
+
+// RemoteWriteEnabled reports whether runner exporters may push metrics via
+// Prometheus remote write
+func (c *Config) RemoteWriteEnabled() bool {
+	return c.Vars.RemoteWriteToken != ""
+}
+
+// CanaryEnabled reports whether the synthetic canary workflow monitor is configured
+func (c *Config) CanaryEnabled() bool {
+	return c.Vars.CanaryToken != ""
+}
+
+// WorkflowChangesEnabled reports whether workflow file changes are looked up
+// on GitHub to correlate them with failure rate shifts
+func (c *Config) WorkflowChangesEnabled() bool {
+	return c.Vars.GitHubAPIToken != ""
+}
+
+// GetCanaryInterval returns how often the canary workflow is dispatched
+func (c *Config) GetCanaryInterval() time.Duration {
+	return time.Duration(c.Vars.CanaryIntervalMinutes) * time.Minute
+}
+
+// GetCanaryTimeout returns how long a canary run may take before it is recorded as timed out
+func (c *Config) GetCanaryTimeout() time.Duration {
+	return time.Duration(c.Vars.CanaryTimeoutMinutes) * time.Minute
+}
+
+// GetMigrationTimeout returns how long startup waits for the migration lock, or
+// for another instance to migrate the schema
+func (c *Config) GetMigrationTimeout() time.Duration {
+	return time.Duration(c.Vars.MigrationTimeoutSeconds) * time.Second
+}
+
+// SnapshotEnabled reports whether the public JSON snapshot is written to a
+// directory or uploaded to S3
+func (c *Config) SnapshotEnabled() bool {
+	return c.Vars.SnapshotDir != "" || c.Vars.SnapshotS3Bucket != ""
+}
+
+// GetSnapshotInterval returns how often the public JSON snapshot is written
+func (c *Config) GetSnapshotInterval() time.Duration {
+	return time.Duration(c.Vars.SnapshotIntervalSeconds) * time.Second
+}
+
+// Now returns the current time according to the configured clock
+func (c *Config) Now() time.Time {
+	if c.Clock == nil {
+		return time.Now()
+	}
+	return c.Clock.Now()
+}
+
+// GetLongRunningThreshold returns how long a run may be in progress before it is reported as long-running
+func (c *Config) GetLongRunningThreshold() time.Duration {
+	return time.Duration(c.Vars.LongRunningThresholdMinutes) * time.Minute
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// redacted replaces the value of a secret setting that is set.
+const redacted = "[REDACTED]"
+
+// isSecret reports whether a Vars field holds credentials or keys. Webhook
+// URLs are included since chat webhooks embed their token in the URL.
+func isSecret(field string) bool {
+	return strings.Contains(field, "Secret") || strings.Contains(field, "Token") ||
+		strings.HasSuffix(field, "WebhookURL") || strings.HasSuffix(field, "Key")
+}
+
+// Redacted returns the configuration keyed by Vars field name with secrets
+// replaced, safe to share in bug reports. Unset secrets stay empty so it
+// still shows which are configured.
+func (c *Config) Redacted() map[string]interface{} {
+	out := make(map[string]interface{})
+	v := reflect.ValueOf(c.Vars)
+	for i := 0; i < v.NumField(); i++ {
+		name := v.Type().Field(i).Name
+		value := v.Field(i)
+		if !isSecret(name) || value.IsZero() {
+			out[name] = value.Interface()
+			continue
+		}
+		if value.Kind() == reflect.Map {
+			masked := make(map[string]string, value.Len())
+			for _, key := range value.MapKeys() {
+				masked[key.String()] = redacted
+			}
+			out[name] = masked
+			continue
+		}
+		out[name] = redacted
+	}
+	return out
+}
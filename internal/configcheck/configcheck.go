@@ -0,0 +1,256 @@
+// Package configcheck validates a configuration against the world it runs
+// in: secrets are set, the URLs it calls answer, runner labels are tracked
+// and the database accepts writes. Problems that only show once an alert
+// fires or a peer is queried are reported before they do.
+package configcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/config"
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/internal/federation"
+	"github.com/gateixeira/live-actions/models"
+)
+
+const (
+	// requestTimeout bounds a single reachability request.
+	requestTimeout = 5 * time.Second
+	// minSecretLength is the length below which secrets are reported as weak.
+	minSecretLength = 16
+)
+
+// Check categories.
+const (
+	CategoryConfig   = "config"
+	CategorySecrets  = "secrets"
+	CategoryURLs     = "urls"
+	CategoryLabels   = "labels"
+	CategoryDatabase = "database"
+)
+
+// Checker validates configurations.
+type Checker struct {
+	// database reports whether the database accepts writes; nil skips the check
+	database func(ctx context.Context) error
+	client   *http.Client
+}
+
+// New creates a Checker checking the database with database, which may be nil.
+func New(database func(ctx context.Context) error) *Checker {
+	return &Checker{database: database, client: &http.Client{Timeout: requestTimeout}}
+}
+
+// Run validates cfg. URLs are requested concurrently but never sent data:
+// the alert webhook only receives a HEAD request.
+func (c *Checker) Run(ctx context.Context, cfg *config.Config) models.ConfigValidationReport {
+	checks := []models.ConfigCheck{{
+		Category: CategoryConfig,
+		Name:     "settings",
+		Status:   models.ConfigCheckOK,
+		Message:  fmt.Sprintf("parsed for the %s environment", cfg.Vars.Environment),
+	}}
+	checks = append(checks, secretChecks(cfg)...)
+	checks = append(checks, c.urlChecks(ctx, cfg)...)
+	checks = append(checks, labelChecks(cfg)...)
+	checks = append(checks, c.databaseCheck(ctx))
+	return newReport(checks, cfg.Now())
+}
+
+// LoadFailed reports a configuration that could not be loaded at all, so
+// nothing else could be checked.
+func LoadFailed(err error, now time.Time) models.ConfigValidationReport {
+	return newReport([]models.ConfigCheck{{
+		Category: CategoryConfig,
+		Name:     "settings",
+		Status:   models.ConfigCheckError,
+		Message:  err.Error(),
+	}}, now)
+}
+
+func newReport(checks []models.ConfigCheck, now time.Time) models.ConfigValidationReport {
+	report := models.ConfigValidationReport{CheckedAt: now.UTC(), Checks: checks}
+	for _, check := range checks {
+		switch check.Status {
+		case models.ConfigCheckError:
+			report.Errors++
+		case models.ConfigCheckWarning:
+			report.Warnings++
+		}
+	}
+	report.Valid = report.Errors == 0
+	return report
+}
+
+func secretChecks(cfg *config.Config) []models.ConfigCheck {
+	v := cfg.Vars
+	secrets := []struct {
+		name, value string
+		// unsetStatus and unset describe an empty secret, which usually
+		// disables what it authenticates
+		unsetStatus, unset string
+	}{
+		{"WEBHOOK_SECRET", v.WebhookSecret, models.ConfigCheckWarning, "not set, webhook deliveries are accepted without a signature"},
+		{"ADMIN_TOKEN", v.AdminToken, models.ConfigCheckSkipped, "not set, the admin API is disabled"},
+		{"FEDERATION_TOKEN", v.FederationToken, models.ConfigCheckSkipped, "not set, peers cannot read this instance's summary"},
+		{"REMOTE_WRITE_TOKEN", v.RemoteWriteToken, models.ConfigCheckSkipped, "not set, remote write is disabled"},
+		{"RUNNER_HEARTBEAT_TOKEN", v.RunnerHeartbeatToken, models.ConfigCheckSkipped, "not set, runner heartbeats are disabled"},
+		{"CANARY_GITHUB_TOKEN", v.CanaryToken, models.ConfigCheckSkipped, "not set, the canary is disabled"},
+		{"GITHUB_API_TOKEN", v.GitHubAPIToken, models.ConfigCheckSkipped, "not set, workflow change correlation is disabled"},
+		{"SNAPSHOT_S3_SECRET_ACCESS_KEY", v.SnapshotS3SecretKey, models.ConfigCheckSkipped, "not set, snapshots are not uploaded to S3"},
+	}
+
+	checks := make([]models.ConfigCheck, 0, len(secrets)+2)
+	for _, s := range secrets {
+		check := models.ConfigCheck{Category: CategorySecrets, Name: s.name, Status: models.ConfigCheckOK, Message: "set"}
+		switch {
+		case s.value == "":
+			check.Status, check.Message = s.unsetStatus, s.unset
+		case len(s.value) < minSecretLength:
+			check.Status = models.ConfigCheckWarning
+			check.Message = fmt.Sprintf("shorter than %d characters, use a random value such as the output of openssl rand -hex 32", minSecretLength)
+		}
+		checks = append(checks, check)
+	}
+
+	encryption := models.ConfigCheck{Category: CategorySecrets, Name: "DATABASE_ENCRYPTION_KEY", Status: models.ConfigCheckOK, Message: "set"}
+	switch {
+	case v.DatabaseEncryptionKey == "":
+		encryption.Status, encryption.Message = models.ConfigCheckSkipped, "not set, the database file is not encrypted"
+	case !database.EncryptionSupported():
+		encryption.Status, encryption.Message = models.ConfigCheckError, "set, but this build cannot open encrypted databases; build with -tags \"sqlcipher sqlite_json\""
+	}
+	checks = append(checks, encryption)
+
+	if v.Anonymize {
+		anonymize := models.ConfigCheck{Category: CategorySecrets, Name: "ANONYMIZE_KEY", Status: models.ConfigCheckOK, Message: "set"}
+		if v.AnonymizeKey == "" {
+			anonymize.Status, anonymize.Message = models.ConfigCheckWarning, "not set, pseudonyms change on every restart"
+		}
+		checks = append(checks, anonymize)
+	}
+	return checks
+}
+
+// urlChecks requests every configured URL concurrently, reporting them in a
+// stable order.
+func (c *Checker) urlChecks(ctx context.Context, cfg *config.Config) []models.ConfigCheck {
+	v := cfg.Vars
+	var probes []func() models.ConfigCheck
+
+	if v.AlertWebhookURL == "" {
+		probes = append(probes, func() models.ConfigCheck {
+			return models.ConfigCheck{Category: CategoryURLs, Name: "ALERT_WEBHOOK_URL", Status: models.ConfigCheckSkipped, Message: "not set, alerts are only shown in the dashboard"}
+		})
+	} else {
+		probes = append(probes, func() models.ConfigCheck {
+			// The URL is left out of messages, chat webhooks embed their token in it
+			return c.probe(ctx, "ALERT_WEBHOOK_URL", http.MethodHead, v.AlertWebhookURL, false)
+		})
+	}
+
+	if cfg.CanaryEnabled() || cfg.WorkflowChangesEnabled() {
+		probes = append(probes, func() models.ConfigCheck {
+			return c.probe(ctx, "GITHUB_API_URL", http.MethodGet, v.GitHubAPIURL, true)
+		})
+	}
+
+	peers := federation.NewClient(v.FederationPeers, v.FederationPeerTokens)
+	for _, peer := range peers.Peers() {
+		probes = append(probes, func() models.ConfigCheck {
+			check := models.ConfigCheck{Category: CategoryURLs, Name: "FEDERATION_PEERS[" + peer.Name + "]", Status: models.ConfigCheckOK}
+			peerCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+			defer cancel()
+			if _, err := peers.FetchSummary(peerCtx, peer); err != nil {
+				check.Status, check.Message = models.ConfigCheckError, err.Error()
+			} else {
+				check.Message = "summary fetched from " + peer.URL
+			}
+			return check
+		})
+	}
+
+	checks := make([]models.ConfigCheck, len(probes))
+	var wg sync.WaitGroup
+	for i, probe := range probes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checks[i] = probe()
+		}()
+	}
+	wg.Wait()
+	return checks
+}
+
+// probe sends a request to target and reports whether it answered. Any
+// response proves the URL reachable; with wantOK anything but 200 is a
+// warning, as the URL may point at the wrong service.
+func (c *Checker) probe(ctx context.Context, name, method, target string, wantOK bool) models.ConfigCheck {
+	check := models.ConfigCheck{Category: CategoryURLs, Name: name}
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		check.Status, check.Message = models.ConfigCheckError, "invalid URL"
+		return check
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		// Drop the URL the error is wrapped in
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		check.Status, check.Message = models.ConfigCheckError, "unreachable: "+err.Error()
+		return check
+	}
+	resp.Body.Close()
+
+	check.Status, check.Message = models.ConfigCheckOK, fmt.Sprintf("reachable, responded with HTTP %d", resp.StatusCode)
+	if wantOK && resp.StatusCode != http.StatusOK {
+		check.Status = models.ConfigCheckWarning
+	}
+	return check
+}
+
+func labelChecks(cfg *config.Config) []models.ConfigCheck {
+	v := cfg.Vars
+	capacity := models.ConfigCheck{Category: CategoryLabels, Name: "RUNNER_CAPACITY", Status: models.ConfigCheckOK}
+	switch {
+	case len(v.RunnerCapacity) == 0:
+		capacity.Status, capacity.Message = models.ConfigCheckSkipped, "not set, saturation uses the runner hosts sending heartbeats"
+	case v.MaxTrackedLabels > 0 && len(v.RunnerCapacity) > v.MaxTrackedLabels:
+		capacity.Status = models.ConfigCheckWarning
+		capacity.Message = fmt.Sprintf("%d labels, over MAX_TRACKED_LABELS (%d); labels seen after the limit share the (other) series", len(v.RunnerCapacity), v.MaxTrackedLabels)
+	default:
+		capacity.Message = fmt.Sprintf("%d labels", len(v.RunnerCapacity))
+	}
+
+	limits := models.ConfigCheck{Category: CategoryLabels, Name: "MAX_LABELS_PER_JOB", Status: models.ConfigCheckOK,
+		Message: fmt.Sprintf("%d per job, %d tracked", v.MaxLabelsPerJob, v.MaxTrackedLabels)}
+	if v.MaxTrackedLabels > 0 && v.MaxLabelsPerJob > v.MaxTrackedLabels {
+		limits.Status = models.ConfigCheckWarning
+		limits.Message = fmt.Sprintf("%d per job, over MAX_TRACKED_LABELS (%d), so a single job can exhaust the tracked labels", v.MaxLabelsPerJob, v.MaxTrackedLabels)
+	}
+	return []models.ConfigCheck{capacity, limits}
+}
+
+func (c *Checker) databaseCheck(ctx context.Context) models.ConfigCheck {
+	check := models.ConfigCheck{Category: CategoryDatabase, Name: "DATABASE_PATH"}
+	if c.database == nil {
+		check.Status, check.Message = models.ConfigCheckSkipped, "not checked"
+		return check
+	}
+	if err := c.database(ctx); err != nil {
+		check.Status, check.Message = models.ConfigCheckError, err.Error()
+		return check
+	}
+	check.Status, check.Message = models.ConfigCheckOK, "writable"
+	return check
+}
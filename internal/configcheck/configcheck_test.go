@@ -0,0 +1,106 @@
+package configcheck
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/clock"
+	"github.com/gateixeira/live-actions/internal/config"
+	"github.com/gateixeira/live-actions/internal/federation"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func checksByName(report models.ConfigValidationReport) map[string]models.ConfigCheck {
+	checks := make(map[string]models.ConfigCheck, len(report.Checks))
+	for _, check := range report.Checks {
+		checks[check.Name] = check
+	}
+	return checks
+}
+
+func TestRun(t *testing.T) {
+	var webhookMethod string
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		webhookMethod = r.Method
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+	defer webhook.Close()
+	peer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != federation.SummaryPath || r.Header.Get("Authorization") != "Bearer peer-token-0123456789" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer peer.Close()
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	now := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+	cfg := &config.Config{Clock: clock.NewFake(now), Vars: config.Vars{
+		Environment:          "production",
+		WebhookSecret:        "0123456789abcdef0123",
+		AdminToken:           "short",
+		AlertWebhookURL:      webhook.URL + "/services/T000/B000/XXXX",
+		FederationPeers:      map[string]string{"eu": peer.URL, "us": peer.URL, "gone": closed.URL},
+		FederationPeerTokens: map[string]string{"eu": "peer-token-0123456789", "us": "wrong"},
+		RunnerCapacity:       map[string]int{"linux": 4, "gpu": 1, "arm": 2},
+		MaxLabelsPerJob:      20,
+		MaxTrackedLabels:     2,
+	}}
+	database := errors.New("database is not writable: attempt to write a readonly database")
+
+	report := New(func(ctx context.Context) error { return database }).Run(context.Background(), cfg)
+	checks := checksByName(report)
+
+	assert.False(t, report.Valid)
+	assert.Equal(t, now, report.CheckedAt)
+	assert.Equal(t, models.ConfigCheckOK, checks["WEBHOOK_SECRET"].Status)
+	assert.Equal(t, models.ConfigCheckWarning, checks["ADMIN_TOKEN"].Status, "short secrets are weak")
+	assert.Equal(t, models.ConfigCheckSkipped, checks["REMOTE_WRITE_TOKEN"].Status)
+
+	assert.Equal(t, http.MethodHead, webhookMethod, "alerts are not sent to the webhook")
+	assert.Equal(t, models.ConfigCheckOK, checks["ALERT_WEBHOOK_URL"].Status)
+	assert.NotContains(t, checks["ALERT_WEBHOOK_URL"].Message, "XXXX")
+	assert.Equal(t, models.ConfigCheckOK, checks["FEDERATION_PEERS[eu]"].Status)
+	assert.Equal(t, models.ConfigCheckError, checks["FEDERATION_PEERS[us]"].Status, "peers rejecting the token fail")
+	assert.Equal(t, models.ConfigCheckError, checks["FEDERATION_PEERS[gone]"].Status)
+	assert.NotContains(t, checks, "GITHUB_API_URL", "the GitHub API is only checked when used")
+
+	assert.Equal(t, models.ConfigCheckWarning, checks["RUNNER_CAPACITY"].Status)
+	assert.Equal(t, models.ConfigCheckWarning, checks["MAX_LABELS_PER_JOB"].Status)
+	assert.Equal(t, models.ConfigCheckError, checks["DATABASE_PATH"].Status)
+	assert.Equal(t, database.Error(), checks["DATABASE_PATH"].Message)
+
+	assert.Equal(t, 3, report.Errors)
+	assert.Equal(t, 3, report.Warnings)
+}
+
+func TestRun_Defaults(t *testing.T) {
+	cfg := &config.Config{Vars: config.Vars{Environment: "development", MaxLabelsPerJob: 20, MaxTrackedLabels: 100}}
+
+	report := New(nil).Run(context.Background(), cfg)
+	checks := checksByName(report)
+
+	assert.True(t, report.Valid)
+	assert.Equal(t, 1, report.Warnings)
+	assert.Equal(t, models.ConfigCheckWarning, checks["WEBHOOK_SECRET"].Status, "unsigned webhooks are accepted")
+	assert.Equal(t, models.ConfigCheckSkipped, checks["ALERT_WEBHOOK_URL"].Status)
+	assert.Equal(t, models.ConfigCheckSkipped, checks["DATABASE_ENCRYPTION_KEY"].Status)
+	assert.Equal(t, models.ConfigCheckSkipped, checks["DATABASE_PATH"].Status)
+}
+
+func TestLoadFailed(t *testing.T) {
+	report := LoadFailed(errors.New("LOG_FORMAT must be 'console' or 'json', got \"xml\""), time.Now())
+
+	assert.False(t, report.Valid)
+	assert.Equal(t, 1, report.Errors)
+	require.Len(t, report.Checks, 1)
+	assert.Equal(t, CategoryConfig, report.Checks[0].Category)
+}
@@ -0,0 +1,53 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+)
+
+// GetActorStats returns per-user run counts for runs started within the given
+// window, most triggered first, at most limit users. A re-run counts from when
+// its latest attempt started.
+func (db *DBWrapper) GetActorStats(ctx context.Context, since time.Duration, repos []string, limit int) ([]models.ActorStats, error) {
+	cutoff := db.clock.Now().UTC().Add(-since).Format(time.RFC3339)
+	args := append([]interface{}{cutoff}, repoArgs(repos)...)
+	args = append(args, limit)
+
+	rows, err := db.db.QueryContext(ctx, `
+		WITH runs AS (
+			SELECT actor, triggering_actor, run_attempt, conclusion
+			FROM workflow_runs
+			WHERE julianday(COALESCE(run_started_at, created_at)) >= julianday(?)`+repoIn("repository", repos)+`
+		)
+		SELECT login, SUM(attributed), SUM(triggered), SUM(re_run), SUM(for_other), SUM(failed)
+		FROM (
+			SELECT actor AS login, 1 AS attributed, 0 AS triggered, 0 AS re_run, 0 AS for_other, 0 AS failed
+			FROM runs WHERE actor != ''
+			UNION ALL
+			SELECT triggering_actor, 0, 1,
+				CASE WHEN run_attempt > 1 THEN 1 ELSE 0 END,
+				CASE WHEN actor != triggering_actor THEN 1 ELSE 0 END,
+				CASE WHEN conclusion = 'failure' THEN 1 ELSE 0 END
+			FROM runs WHERE triggering_actor != ''
+		)
+		GROUP BY login
+		ORDER BY 3 DESC, 2 DESC, 1 ASC
+		LIMIT ?`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get actor stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []models.ActorStats{}
+	for rows.Next() {
+		var s models.ActorStats
+		if err := rows.Scan(&s.Actor, &s.Runs, &s.Triggered, &s.ReRuns, &s.ForOthers, &s.Failed); err != nil {
+			return nil, fmt.Errorf("failed to scan actor stats: %w", err)
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
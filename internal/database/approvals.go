@@ -0,0 +1,144 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/utils"
+	"github.com/gateixeira/live-actions/models"
+)
+
+// RecordJobWaiting records that a job started waiting for an environment
+// approval at the given time. Repeated waiting events keep the first time.
+func (db *DBWrapper) RecordJobWaiting(ctx context.Context, jobID, runID int64, at time.Time) error {
+	_, err := db.db.ExecContext(ctx, `
+		INSERT INTO job_approvals (job_id, run_id, waiting_at, updated_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (job_id) DO UPDATE SET
+			waiting_at = COALESCE(waiting_at, excluded.waiting_at),
+			updated_at = excluded.updated_at`,
+		jobID, runID, at.UTC().Format(time.RFC3339), db.clock.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("failed to record waiting job: %w", err)
+	}
+	return nil
+}
+
+// RecordJobApproved records that a waiting job was queued at the given time.
+// Jobs that never waited are ignored.
+func (db *DBWrapper) RecordJobApproved(ctx context.Context, jobID int64, at time.Time) error {
+	_, err := db.db.ExecContext(ctx, `
+		UPDATE job_approvals SET queued_at = ?, updated_at = ?
+		WHERE job_id = ? AND waiting_at IS NOT NULL AND queued_at IS NULL`,
+		at.UTC().Format(time.RFC3339), db.clock.Now().UTC().Format(time.RFC3339), jobID)
+	if err != nil {
+		return fmt.Errorf("failed to record approved job: %w", err)
+	}
+	return nil
+}
+
+// SetJobEnvironments records the environment each job deploys to. Jobs may
+// be named before their waiting event is processed.
+func (db *DBWrapper) SetJobEnvironments(ctx context.Context, runID int64, jobs []models.EnvironmentJob) error {
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback()
+		}
+	}()
+
+	now := db.clock.Now().UTC().Format(time.RFC3339)
+	for _, job := range jobs {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO job_approvals (job_id, run_id, environment, updated_at) VALUES (?, ?, ?, ?)
+			ON CONFLICT (job_id) DO UPDATE SET
+				environment = excluded.environment,
+				updated_at = excluded.updated_at`,
+			job.ID, runID, job.Environment, now); err != nil {
+			return fmt.Errorf("failed to set job environment: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit job environments: %w", err)
+	}
+	committed = true
+	return nil
+}
+
+// GetApprovalStats returns approval latency per environment for the jobs that
+// started waiting within the given window. If repos is non-empty, filters to
+// those repositories.
+func (db *DBWrapper) GetApprovalStats(ctx context.Context, since time.Duration, repos []string) ([]models.ApprovalStats, error) {
+	cutoff := db.clock.Now().UTC().Add(-since).Format(time.RFC3339)
+	args := append([]interface{}{cutoff}, repoArgs(repos)...)
+
+	repoJoin := ""
+	if len(repos) > 0 {
+		repoJoin = " JOIN workflow_runs r ON r.id = a.run_id"
+	}
+	rows, err := db.db.QueryContext(ctx, `
+		SELECT a.environment,
+			MAX(0, (julianday(a.queued_at) - julianday(a.waiting_at)) * 86400) AS latency,
+			COALESCE(j.status IN ('completed', 'cancelled', 'stale'), 0)
+		FROM job_approvals a
+		LEFT JOIN workflow_jobs j ON j.id = a.job_id`+repoJoin+`
+		WHERE a.waiting_at >= ?`+repoWhere(repos)+`
+		ORDER BY a.environment, latency`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get approval latencies: %w", err)
+	}
+	defer rows.Close()
+
+	results := []models.ApprovalStats{}
+	var current *models.ApprovalStats
+	var latencies []float64
+	flush := func() {
+		if current == nil {
+			return
+		}
+		if n := len(latencies); n > 0 {
+			var total float64
+			for _, l := range latencies {
+				total += l
+			}
+			current.Approved = n
+			current.AvgSeconds = total / float64(n)
+			current.P50Seconds = utils.Percentile(latencies, 50)
+			current.P90Seconds = utils.Percentile(latencies, 90)
+			current.MaxSeconds = latencies[n-1]
+		}
+		results = append(results, *current)
+	}
+	for rows.Next() {
+		var environment string
+		var latency sql.NullFloat64
+		var finished bool
+		if err := rows.Scan(&environment, &latency, &finished); err != nil {
+			return nil, fmt.Errorf("failed to scan approval latency: %w", err)
+		}
+		if current == nil || current.Environment != environment {
+			flush()
+			current, latencies = &models.ApprovalStats{Environment: environment}, nil
+		}
+		switch {
+		case latency.Valid:
+			latencies = append(latencies, latency.Float64)
+		case finished:
+			current.Rejected++
+		default:
+			current.Pending++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+
+	return results, nil
+}
@@ -0,0 +1,40 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path"
+
+	"github.com/gateixeira/live-actions/models"
+)
+
+// GetLatestWorkflowRun returns the most recently created run for a repository
+// given as owner/name. If workflow is non-empty, only runs of that workflow are
+// considered. Returns nil when no run matches.
+func (db *DBWrapper) GetLatestWorkflowRun(ctx context.Context, repo string, workflow string) (*models.WorkflowRun, error) {
+	// repository only stores the short name; the owner is matched via html_url
+	where := `WHERE repository = ? AND html_url LIKE ? ESCAPE '\'`
+	args := []interface{}{path.Base(repo), "%/" + escapeLike(repo) + "/actions/%"}
+	if workflow != "" {
+		where += " AND name = ?"
+		args = append(args, workflow)
+	}
+
+	var run models.WorkflowRun
+	var createdAt, startedAt, updatedAt sql.NullString
+	err := db.db.QueryRowContext(ctx,
+		"SELECT id, name, status, repository, html_url, display_title, conclusion, created_at, run_started_at, updated_at FROM workflow_runs "+where+" ORDER BY created_at DESC LIMIT 1",
+		args...).Scan(&run.ID, &run.Name, &run.Status, &run.RepositoryName, &run.HtmlUrl, &run.DisplayTitle, &run.Conclusion, &createdAt, &startedAt, &updatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get latest workflow run: %w", err)
+	}
+
+	run.CreatedAt = parseTime(createdAt.String)
+	run.RunStartedAt = parseTime(startedAt.String)
+	run.UpdatedAt = parseTime(updatedAt.String)
+	return &run, nil
+}
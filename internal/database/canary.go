@@ -0,0 +1,47 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+)
+
+// SaveCanaryResult stores the result of a canary probe.
+func (db *DBWrapper) SaveCanaryResult(ctx context.Context, result models.CanaryResult) error {
+	_, err := db.db.ExecContext(ctx, `
+		INSERT INTO canary_results (dispatched_at, outcome, run_id, html_url, conclusion, duration_seconds, error)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		result.DispatchedAt.UTC().Format(time.RFC3339), result.Outcome, result.RunID, result.HtmlUrl,
+		result.Conclusion, result.DurationSeconds, result.Error)
+	if err != nil {
+		return fmt.Errorf("failed to save canary result: %w", err)
+	}
+	return nil
+}
+
+// GetCanaryResults returns the most recent canary results, newest first.
+func (db *DBWrapper) GetCanaryResults(ctx context.Context, limit int) ([]models.CanaryResult, error) {
+	rows, err := db.db.QueryContext(ctx, `
+		SELECT id, dispatched_at, outcome, run_id, html_url, conclusion, duration_seconds, error
+		FROM canary_results
+		ORDER BY dispatched_at DESC, id DESC
+		LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get canary results: %w", err)
+	}
+	defer rows.Close()
+
+	results := []models.CanaryResult{}
+	for rows.Next() {
+		var r models.CanaryResult
+		var dispatchedAt string
+		if err := rows.Scan(&r.ID, &dispatchedAt, &r.Outcome, &r.RunID, &r.HtmlUrl, &r.Conclusion, &r.DurationSeconds, &r.Error); err != nil {
+			return nil, fmt.Errorf("failed to scan canary result: %w", err)
+		}
+		r.DispatchedAt = parseTime(dispatchedAt)
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
@@ -0,0 +1,42 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+)
+
+// GetJobTimings returns the queue and run times of jobs carrying label that
+// were queued within the given window and have started, oldest first. Jobs
+// still running are counted as running until now.
+func (db *DBWrapper) GetJobTimings(ctx context.Context, since time.Duration, label string) ([]models.JobTiming, error) {
+	now := db.clock.Now().UTC()
+	rows, err := db.db.QueryContext(ctx, `
+		SELECT j.created_at, j.started_at,
+			MAX(0, (julianday(COALESCE(j.completed_at, ?)) - julianday(j.started_at)) * 86400)
+		FROM workflow_jobs j
+		WHERE j.created_at >= ? AND j.started_at IS NOT NULL
+			AND j.status IN ('in_progress', 'completed')
+			AND EXISTS (SELECT 1 FROM json_each(j.labels) WHERE value = ?)
+		ORDER BY j.created_at ASC`,
+		now.Format(time.RFC3339), now.Add(-since).Format(time.RFC3339), label)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job timings: %w", err)
+	}
+	defer rows.Close()
+
+	var timings []models.JobTiming
+	for rows.Next() {
+		var queuedAt, startedAt string
+		var t models.JobTiming
+		if err := rows.Scan(&queuedAt, &startedAt, &t.RunSeconds); err != nil {
+			return nil, fmt.Errorf("failed to scan job timing: %w", err)
+		}
+		t.QueuedAt = parseTime(queuedAt)
+		t.StartedAt = parseTime(startedAt)
+		timings = append(timings, t)
+	}
+	return timings, rows.Err()
+}
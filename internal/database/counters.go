@@ -0,0 +1,219 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+)
+
+// jobCounterIncrement is the change a job event makes to the job_counters
+// row of one day.
+type jobCounterIncrement struct {
+	day       string
+	queued    int
+	started   int
+	completed int
+	succeeded int
+	failed    int
+	cancelled int
+	minutes   float64
+}
+
+// jobCounterIncrements returns the counter changes for a job moving from its
+// stored state to job. seen and wasStarted describe the stored row. Each
+// transition is counted on the day it happened, so replays of the same
+// state add nothing. Completions without a completion time are counted at
+// now.
+func jobCounterIncrements(seen bool, wasStarted bool, job models.WorkflowJob, now time.Time) []jobCounterIncrement {
+	var increments []jobCounterIncrement
+	if !seen && !job.CreatedAt.IsZero() {
+		increments = append(increments, jobCounterIncrement{day: counterDay(job.CreatedAt), queued: 1})
+	}
+	if !wasStarted && !job.StartedAt.IsZero() &&
+		(job.Status == models.JobStatusInProgress || job.Status == models.JobStatusCompleted) {
+		increments = append(increments, jobCounterIncrement{day: counterDay(job.StartedAt), started: 1})
+	}
+	if job.Status == models.JobStatusCompleted {
+		completedAt := job.CompletedAt
+		if completedAt.IsZero() {
+			completedAt = now
+		}
+		inc := jobCounterIncrement{day: counterDay(completedAt), completed: 1}
+		switch job.Conclusion {
+		case "success":
+			inc.succeeded = 1
+		case "failure", "timed_out":
+			inc.failed = 1
+		case "cancelled":
+			inc.cancelled = 1
+		}
+		if !job.StartedAt.IsZero() && job.CompletedAt.After(job.StartedAt) {
+			inc.minutes = job.CompletedAt.Sub(job.StartedAt).Minutes()
+		}
+		increments = append(increments, inc)
+	}
+	return increments
+}
+
+func counterDay(t time.Time) string {
+	return t.UTC().Format(time.DateOnly)
+}
+
+// recordJobCounters applies the counter changes of a job event within the
+// transaction storing it. Jobs are counted under their run's repository and
+// their first label. Jobs seen before their run are counted without a
+// repository and kept in pending_job_counters, from which
+// attributePendingJobCounters moves them once the run arrives.
+func recordJobCounters(ctx context.Context, tx *sql.Tx, seen bool, wasStarted bool, job models.WorkflowJob, now time.Time) error {
+	increments := jobCounterIncrements(seen, wasStarted, job, now)
+	if len(increments) == 0 {
+		return nil
+	}
+
+	var repository string
+	err := tx.QueryRowContext(ctx, "SELECT repository FROM workflow_runs WHERE id = ?", job.RunID).Scan(&repository)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to get job repository: %w", err)
+	}
+	pending := err == sql.ErrNoRows
+	var label string
+	if len(job.Labels) > 0 {
+		label = job.Labels[0]
+	}
+
+	for _, inc := range increments {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO job_counters (day, repository, label, queued, started, completed, succeeded, failed, cancelled, minutes)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (day, repository, label) DO UPDATE SET
+				queued = queued + excluded.queued,
+				started = started + excluded.started,
+				completed = completed + excluded.completed,
+				succeeded = succeeded + excluded.succeeded,
+				failed = failed + excluded.failed,
+				cancelled = cancelled + excluded.cancelled,
+				minutes = minutes + excluded.minutes`,
+			inc.day, repository, label, inc.queued, inc.started, inc.completed, inc.succeeded, inc.failed, inc.cancelled, inc.minutes)
+		if err != nil {
+			return fmt.Errorf("failed to update job counters: %w", err)
+		}
+		if !pending {
+			continue
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO pending_job_counters (run_id, day, label, queued, started, completed, succeeded, failed, cancelled, minutes)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (run_id, day, label) DO UPDATE SET
+				queued = queued + excluded.queued,
+				started = started + excluded.started,
+				completed = completed + excluded.completed,
+				succeeded = succeeded + excluded.succeeded,
+				failed = failed + excluded.failed,
+				cancelled = cancelled + excluded.cancelled,
+				minutes = minutes + excluded.minutes`,
+			job.RunID, inc.day, label, inc.queued, inc.started, inc.completed, inc.succeeded, inc.failed, inc.cancelled, inc.minutes)
+		if err != nil {
+			return fmt.Errorf("failed to update pending job counters: %w", err)
+		}
+	}
+	return nil
+}
+
+// attributePendingJobCounters moves the counters of the jobs of run seen
+// before it from no repository to repository, within the transaction storing
+// the run.
+func attributePendingJobCounters(ctx context.Context, tx *sql.Tx, runID int64, repository string) error {
+	if repository == "" {
+		return nil
+	}
+	// Added under the repository, then subtracted from the unattributed counts
+	for _, move := range []struct {
+		repository string
+		sign       int
+	}{{repository, 1}, {"", -1}} {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO job_counters (day, repository, label, queued, started, completed, succeeded, failed, cancelled, minutes)
+			SELECT day, ?, label, ? * queued, ? * started, ? * completed, ? * succeeded, ? * failed, ? * cancelled, ? * minutes
+			FROM pending_job_counters
+			WHERE run_id = ?
+			ON CONFLICT (day, repository, label) DO UPDATE SET
+				queued = queued + excluded.queued,
+				started = started + excluded.started,
+				completed = completed + excluded.completed,
+				succeeded = succeeded + excluded.succeeded,
+				failed = failed + excluded.failed,
+				cancelled = cancelled + excluded.cancelled,
+				minutes = minutes + excluded.minutes`,
+			move.repository, move.sign, move.sign, move.sign, move.sign, move.sign, move.sign, move.sign, runID)
+		if err != nil {
+			return fmt.Errorf("failed to attribute pending job counters: %w", err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM pending_job_counters WHERE run_id = ?", runID); err != nil {
+		return fmt.Errorf("failed to delete pending job counters: %w", err)
+	}
+	return nil
+}
+
+// counterFailureTrend returns daily failure trend points from job_counters.
+// The day containing cutoff is included in full.
+func (db *DBWrapper) counterFailureTrend(ctx context.Context, cutoff time.Time, repos []string) ([]models.FailureTrendPoint, error) {
+	args := append([]interface{}{counterDay(cutoff)}, repoArgs(repos)...)
+	rows, err := db.db.QueryContext(ctx, `
+		SELECT day, SUM(failed), SUM(succeeded), SUM(cancelled)
+		FROM job_counters
+		WHERE day >= ?`+repoIn("repository", repos)+`
+		GROUP BY day
+		HAVING SUM(completed) > 0
+		ORDER BY day ASC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get failure trend: %w", err)
+	}
+	defer rows.Close()
+
+	points := []models.FailureTrendPoint{}
+	for rows.Next() {
+		var day string
+		var p models.FailureTrendPoint
+		if err := rows.Scan(&day, &p.Failures, &p.Successes, &p.Cancelled); err != nil {
+			return nil, fmt.Errorf("failed to scan trend point: %w", err)
+		}
+		t, _ := time.Parse(time.DateOnly, day)
+		p.Timestamp = t.Unix()
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
+
+// counterLabelDemandTrend returns daily per-label job counts from
+// job_counters. The day containing cutoff is included in full.
+func (db *DBWrapper) counterLabelDemandTrend(ctx context.Context, cutoff time.Time, repos []string) ([]models.LabelDemandTrendPoint, error) {
+	args := append([]interface{}{counterDay(cutoff)}, repoArgs(repos)...)
+	rows, err := db.db.QueryContext(ctx, `
+		SELECT day, label, SUM(queued)
+		FROM job_counters
+		WHERE day >= ? AND label != ''`+repoIn("repository", repos)+`
+		GROUP BY day, label
+		HAVING SUM(queued) > 0
+		ORDER BY day ASC, label ASC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get label demand trend: %w", err)
+	}
+	defer rows.Close()
+
+	points := []models.LabelDemandTrendPoint{}
+	for rows.Next() {
+		var day string
+		var p models.LabelDemandTrendPoint
+		if err := rows.Scan(&day, &p.Label, &p.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan label demand trend: %w", err)
+		}
+		t, _ := time.Parse(time.DateOnly, day)
+		p.Timestamp = t.Unix()
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
@@ -0,0 +1,92 @@
+package database
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobCounterIncrements(t *testing.T) {
+	created := time.Date(2025, 3, 1, 23, 50, 0, 0, time.UTC)
+	queued := models.WorkflowJob{Status: models.JobStatusQueued, CreatedAt: created}
+	running := queued
+	running.Status = models.JobStatusInProgress
+	running.StartedAt = created.Add(20 * time.Minute)
+	failed := running
+	failed.Status = models.JobStatusCompleted
+	failed.Conclusion = "failure"
+	failed.CompletedAt = running.StartedAt.Add(90 * time.Second)
+
+	tests := []struct {
+		name       string
+		seen       bool
+		wasStarted bool
+		job        models.WorkflowJob
+		want       []jobCounterIncrement
+	}{
+		{"new queued job", false, false, queued, []jobCounterIncrement{{day: "2025-03-01", queued: 1}}},
+		{"repeated queued event", true, false, queued, nil},
+		{"job starts on the next day", true, false, running, []jobCounterIncrement{{day: "2025-03-02", started: 1}}},
+		{"repeated in progress event", true, true, running, nil},
+		{"job fails", true, true, failed, []jobCounterIncrement{{day: "2025-03-02", completed: 1, failed: 1, minutes: 1.5}}},
+		{"first event is the completion", false, false, failed, []jobCounterIncrement{
+			{day: "2025-03-01", queued: 1},
+			{day: "2025-03-02", started: 1},
+			{day: "2025-03-02", completed: 1, failed: 1, minutes: 1.5},
+		}},
+	}
+
+	for _, tt := range tests {
+		if got := jobCounterIncrements(tt.seen, tt.wasStarted, tt.job, created); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: jobCounterIncrements() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestJobCounters_JobBeforeRun(t *testing.T) {
+	db := newTestDB(t, "")
+	ctx := context.Background()
+	created := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	job := models.WorkflowJob{
+		ID: 1, RunID: 7, Name: "build", Status: models.JobStatusCompleted, Conclusion: "failure",
+		Labels: []string{"linux"}, CreatedAt: created, StartedAt: created.Add(time.Minute), CompletedAt: created.Add(3 * time.Minute),
+	}
+	_, err := db.AddOrUpdateJob(ctx, job, created)
+	require.NoError(t, err)
+
+	counters := func() map[string][2]int {
+		rows, err := db.db.QueryContext(ctx, "SELECT repository, queued, failed FROM job_counters")
+		require.NoError(t, err)
+		defer rows.Close()
+		byRepo := make(map[string][2]int)
+		for rows.Next() {
+			var repo string
+			var queued, failed int
+			require.NoError(t, rows.Scan(&repo, &queued, &failed))
+			byRepo[repo] = [2]int{queued, failed}
+		}
+		require.NoError(t, rows.Err())
+		return byRepo
+	}
+	assert.Equal(t, map[string][2]int{"": {1, 1}}, counters(), "the repository is unknown until the run arrives")
+
+	_, err = db.AddOrUpdateRun(ctx, models.WorkflowRun{
+		ID: 7, Name: "CI", Status: models.JobStatusCompleted, RepositoryName: "api",
+		HtmlUrl: "https://github.com/acme/api/actions/runs/7", CreatedAt: created,
+	}, created)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][2]int{"": {0, 0}, "api": {1, 1}}, counters())
+
+	// Later events of the run do not move the counters again
+	_, err = db.AddOrUpdateRun(ctx, models.WorkflowRun{
+		ID: 7, Name: "CI", Status: models.JobStatusCompleted, RepositoryName: "api",
+		HtmlUrl: "https://github.com/acme/api/actions/runs/7", CreatedAt: created,
+	}, created.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, map[string][2]int{"": {0, 0}, "api": {1, 1}}, counters())
+}
@@ -0,0 +1,57 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+)
+
+// GetEventStatusSequences returns the status priorities delivered for each
+// job and run whose first webhook event arrived within the given duration.
+// Entities that started before the window are left out so their earlier
+// deliveries, which may already be cleaned up, are not mistaken for gaps.
+// Pending events are skipped as they may still be in flight.
+func (db *DBWrapper) GetEventStatusSequences(ctx context.Context, since time.Duration) ([]models.EventStatusSequence, error) {
+	cutoff := db.clock.Now().Add(-since).Format(time.RFC3339)
+	rows, err := db.db.QueryContext(ctx, `
+		SELECT e.event_type, e.ordering_key, group_concat(DISTINCT e.status_priority), MAX(e.received_at),
+			COALESCE(MAX(j.conclusion IN ('cancelled', 'skipped') AND (j.started_at IS NULL OR j.started_at = '')),
+				MAX(r.conclusion IN ('cancelled', 'skipped')), 0)
+		FROM webhook_events e
+		LEFT JOIN workflow_jobs j ON e.event_type = 'workflow_job' AND j.id = CAST(substr(e.ordering_key, 5) AS INTEGER)
+		LEFT JOIN workflow_runs r ON e.event_type = 'workflow_run' AND r.id = CAST(substr(e.ordering_key, 5) AS INTEGER)
+		WHERE e.status IN ('processed', 'failed') AND e.ordering_key IN (
+			SELECT ordering_key FROM webhook_events WHERE status IN ('processed', 'failed') AND received_at >= ?)
+		GROUP BY e.event_type, e.ordering_key
+		HAVING MIN(e.received_at) >= ?
+		ORDER BY MAX(e.received_at) DESC`, cutoff, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event status sequences: %w", err)
+	}
+	defer rows.Close()
+
+	sequences := []models.EventStatusSequence{}
+	for rows.Next() {
+		var s models.EventStatusSequence
+		var priorities, lastReceived string
+		if err := rows.Scan(&s.EventType, &s.OrderingKey, &priorities, &lastReceived, &s.NotStarted); err != nil {
+			return nil, fmt.Errorf("failed to scan event status sequence: %w", err)
+		}
+		for _, p := range strings.Split(priorities, ",") {
+			n, err := strconv.Atoi(p)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse status priority %q: %w", p, err)
+			}
+			s.Priorities = append(s.Priorities, n)
+		}
+		sort.Ints(s.Priorities)
+		s.LastReceivedAt = parseTime(lastReceived)
+		sequences = append(sequences, s)
+	}
+	return sequences, rows.Err()
+}
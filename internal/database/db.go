@@ -3,10 +3,13 @@ package database
 import (
 	"database/sql"
 	"embed"
+	"errors"
 	"fmt"
+	"net/url"
 	"path"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/gateixeira/live-actions/pkg/logger"
 	_ "modernc.org/sqlite"
@@ -16,14 +19,20 @@ import (
 //go:embed migrations/*.up.sql
 var migrationsFS embed.FS
 
-// InitDB initializes the SQLite database connection and runs migrations
-func InitDB(dsn string) (*sql.DB, error) {
-	db, err := sql.Open("sqlite", dsn)
-	if err != nil {
-		return nil, err
-	}
+// encryptedDriver is the SQLCipher driver encrypted databases are opened
+// with; only builds with the sqlcipher tag register one.
+var encryptedDriver string
+
+// EncryptionSupported reports whether this build can open encrypted databases.
+func EncryptionSupported() bool {
+	return encryptedDriver != ""
+}
 
-	if err = db.Ping(); err != nil {
+// InitDB initializes the SQLite database connection and runs migrations. A
+// non-empty key opens the database encrypted with SQLCipher.
+func InitDB(dsn, key string, opts MigrationOptions) (*sql.DB, error) {
+	db, err := open(dsn, key)
+	if err != nil {
 		return nil, err
 	}
 
@@ -43,7 +52,7 @@ func InitDB(dsn string) (*sql.DB, error) {
 	// SQLite handles concurrency at the file level; keep pool small
 	db.SetMaxOpenConns(1)
 
-	if err = RunMigrations(db); err != nil {
+	if err = RunMigrations(db, opts); err != nil {
 		logger.Logger.Error("Failed to run database migrations", zap.Error(err))
 		return nil, err
 	}
@@ -52,22 +61,75 @@ func InitDB(dsn string) (*sql.DB, error) {
 	return db, nil
 }
 
-// RunMigrations applies pending SQL migration files from the embedded migrations/ directory.
-func RunMigrations(db *sql.DB) error {
+// open connects to the database, waiting on locks from the first statement, as
+// another instance starting against the same file may be migrating it.
+func open(dsn, key string) (*sql.DB, error) {
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+	if key == "" {
+		db, err := sql.Open("sqlite", dsn+separator+"_pragma=busy_timeout(5000)")
+		if err != nil {
+			return nil, err
+		}
+		if err = db.Ping(); err != nil {
+			return nil, err
+		}
+		return db, nil
+	}
+
+	if !EncryptionSupported() {
+		return nil, errors.New("database encryption requires a build with -tags \"sqlcipher sqlite_json\" and cgo")
+	}
+	db, err := sql.Open(encryptedDriver, dsn+separator+"_busy_timeout=5000&_pragma_key="+url.QueryEscape(key))
+	if err != nil {
+		return nil, err
+	}
+	// The key is only checked once the first page is read
+	if _, err := db.Exec("SELECT count(*) FROM sqlite_master"); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to decrypt database, check the key and that the file is encrypted: %w", err)
+	}
+	if _, err := db.Exec("SELECT json('[]')"); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("encrypted database driver lacks JSON support, build with -tags \"sqlcipher sqlite_json\": %w", err)
+	}
+	return db, nil
+}
+
+// RunMigrations applies pending SQL migration files from the embedded
+// migrations/ directory while holding the migration lock, so instances
+// starting together do not apply them twice. With opts.Wait it applies none
+// and waits for another instance to bring the schema up to date instead.
+func RunMigrations(db *sql.DB, opts MigrationOptions) (err error) {
 	logger.Logger.Info("Running database migrations...")
 
-	// Create migrations tracking table
-	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+	currentVersion, latestVersion := 0, 0
+	defer func() {
+		if err != nil {
+			setMigrationState(MigrationFailed, currentVersion, latestVersion, err)
+		}
+	}()
+
+	// Create migrations tracking and lock tables
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
 		version INTEGER PRIMARY KEY,
 		applied_at TEXT NOT NULL DEFAULT (datetime('now'))
 	)`)
 	if err != nil {
 		return fmt.Errorf("failed to create migrations table: %w", err)
 	}
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS schema_migration_lock (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		owner TEXT NOT NULL,
+		acquired_at TEXT NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("failed to create migration lock table: %w", err)
+	}
 
-	// Check current version
-	var currentVersion int
-	err = db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&currentVersion)
+	currentVersion, err = schemaVersion(db)
 	if err != nil {
 		return fmt.Errorf("failed to check migration version: %w", err)
 	}
@@ -94,6 +156,29 @@ func RunMigrations(db *sql.DB) error {
 		migrations = append(migrations, migration{version: ver, file: e.Name()})
 	}
 	sort.Slice(migrations, func(i, j int) bool { return migrations[i].version < migrations[j].version })
+	if len(migrations) > 0 {
+		latestVersion = migrations[len(migrations)-1].version
+	}
+
+	deadline := time.Now().Add(opts.timeout())
+	if opts.Wait {
+		return waitForSchema(db, latestVersion, deadline)
+	}
+
+	setMigrationState(MigrationWaitingForLock, currentVersion, latestVersion, nil)
+	owner := newMigrationLockOwner()
+	if err := acquireMigrationLock(db, owner, deadline); err != nil {
+		return err
+	}
+	defer releaseMigrationLock(db, owner)
+
+	// Check current version, which another instance may have advanced
+	// while this one waited for the lock
+	currentVersion, err = schemaVersion(db)
+	if err != nil {
+		return fmt.Errorf("failed to check migration version: %w", err)
+	}
+	setMigrationState(MigrationRunning, currentVersion, latestVersion, nil)
 
 	// Apply pending migrations
 	applied := 0
@@ -112,6 +197,11 @@ func RunMigrations(db *sql.DB) error {
 			return fmt.Errorf("failed to start migration transaction: %w", err)
 		}
 
+		if err := refreshMigrationLock(tx, owner); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+
 		if _, err := tx.Exec(string(data)); err != nil {
 			_ = tx.Rollback()
 			return fmt.Errorf("failed to apply migration %s: %w", m.file, err)
@@ -128,6 +218,8 @@ func RunMigrations(db *sql.DB) error {
 
 		logger.Logger.Info("Applied migration", zap.String("file", m.file), zap.Int("version", m.version))
 		applied++
+		currentVersion = m.version
+		setMigrationState(MigrationRunning, currentVersion, latestVersion, nil)
 	}
 
 	if applied == 0 {
@@ -135,6 +227,7 @@ func RunMigrations(db *sql.DB) error {
 	} else {
 		logger.Logger.Info("Database migrations completed", zap.Int("applied", applied))
 	}
+	setMigrationState(MigrationComplete, currentVersion, latestVersion, nil)
 
 	return nil
 }
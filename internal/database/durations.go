@@ -0,0 +1,61 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/gateixeira/live-actions/internal/utils"
+	"github.com/gateixeira/live-actions/models"
+)
+
+// maxDurationSamples caps how many recent successful runs of a job feed its percentiles.
+const maxDurationSamples = 200
+
+// GetJobDurationStats returns duration percentiles keyed by job name for the
+// workflow of the given run, using recent successful jobs of the same workflow
+// in the same repository.
+func (db *DBWrapper) GetJobDurationStats(ctx context.Context, runID int64) (map[string]models.DurationStats, error) {
+	rows, err := db.db.QueryContext(ctx, `
+		SELECT name, duration FROM (
+			SELECT
+				j.name,
+				(julianday(j.completed_at) - julianday(j.started_at)) * 86400 AS duration,
+				ROW_NUMBER() OVER (PARTITION BY j.name ORDER BY j.completed_at DESC) AS rn
+			FROM workflow_jobs j
+			JOIN workflow_runs r ON j.run_id = r.id
+			JOIN workflow_runs target ON target.id = ? AND r.repository = target.repository AND r.name = target.name
+			WHERE j.status = 'completed' AND j.conclusion = 'success'
+				AND j.started_at IS NOT NULL AND j.completed_at IS NOT NULL
+		)
+		WHERE rn <= ? AND duration >= 0`, runID, maxDurationSamples)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job durations: %w", err)
+	}
+	defer rows.Close()
+
+	durations := make(map[string][]float64)
+	for rows.Next() {
+		var name string
+		var duration float64
+		if err := rows.Scan(&name, &duration); err != nil {
+			return nil, fmt.Errorf("failed to scan job duration: %w", err)
+		}
+		durations[name] = append(durations[name], duration)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	stats := make(map[string]models.DurationStats, len(durations))
+	for name, values := range durations {
+		sort.Float64s(values)
+		stats[name] = models.DurationStats{
+			Samples: len(values),
+			P10:     utils.Percentile(values, 10),
+			P50:     utils.Percentile(values, 50),
+			P90:     utils.Percentile(values, 90),
+		}
+	}
+	return stats, nil
+}
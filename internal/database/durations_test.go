@@ -0,0 +1,31 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/gateixeira/live-actions/internal/utils"
+)
+
+func TestPercentile(t *testing.T) {
+	values := []float64{10, 20, 30, 40, 50}
+
+	tests := []struct {
+		p    float64
+		want float64
+	}{
+		{0, 10},
+		{50, 30},
+		{90, 46},
+		{100, 50},
+	}
+
+	for _, tt := range tests {
+		if got := utils.Percentile(values, tt.p); got != tt.want {
+			t.Errorf("Percentile(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+
+	if got := utils.Percentile(nil, 50); got != 0 {
+		t.Errorf("percentile of empty slice = %v, want 0", got)
+	}
+}
@@ -121,7 +121,7 @@ func (db *DBWrapper) GetPendingEventsGrouped(ctx context.Context, limit int) ([]
 }
 
 func (db *DBWrapper) GetPendingEventsByAge(ctx context.Context, maxAge time.Duration, limit int) ([]*models.OrderedEvent, error) {
-	cutoff := time.Now().Add(-maxAge).Format(time.RFC3339)
+	cutoff := db.clock.Now().Add(-maxAge).Format(time.RFC3339)
 
 	query := `
         SELECT delivery_id, event_type, sequence_id, github_timestamp, received_at, 
@@ -178,8 +178,49 @@ func (db *DBWrapper) GetPendingEventsByAge(ctx context.Context, maxAge time.Dura
 	return events, nil
 }
 
+// HasOtherPendingEvents reports whether events other than deliveryID are
+// pending for orderingKey.
+func (db *DBWrapper) HasOtherPendingEvents(ctx context.Context, orderingKey string, deliveryID string) (bool, error) {
+	var pending bool
+	err := db.db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM webhook_events
+			WHERE status = 'pending' AND ordering_key = ? AND delivery_id != ?)`,
+		orderingKey, deliveryID).Scan(&pending)
+	if err != nil {
+		return false, fmt.Errorf("failed to check pending events: %w", err)
+	}
+	return pending, nil
+}
+
+// ClaimPendingEvent moves the event deliveryID from pending to processing and
+// reports whether it did, so an event is processed by whichever path claims
+// it first. Processing ends by marking it processed or failed.
+func (db *DBWrapper) ClaimPendingEvent(ctx context.Context, deliveryID string) (bool, error) {
+	result, err := db.db.ExecContext(ctx,
+		"UPDATE webhook_events SET status = 'processing' WHERE delivery_id = ? AND status = 'pending'",
+		deliveryID)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim event: %w", err)
+	}
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim event: %w", err)
+	}
+	return claimed == 1, nil
+}
+
+// ReleaseClaimedEvents returns the events left processing, by a process that
+// stopped before finishing them, to pending.
+func (db *DBWrapper) ReleaseClaimedEvents(ctx context.Context) error {
+	if _, err := db.db.ExecContext(ctx, "UPDATE webhook_events SET status = 'pending' WHERE status = 'processing'"); err != nil {
+		return fmt.Errorf("failed to release claimed events: %w", err)
+	}
+	return nil
+}
+
 func (db *DBWrapper) MarkEventProcessed(ctx context.Context, deliveryID string) error {
-	now := time.Now().Format(time.RFC3339)
+	now := db.clock.Now().Format(time.RFC3339)
 	_, err := db.db.ExecContext(ctx,
 		"UPDATE webhook_events SET status = 'processed', processed_at = ?, raw_payload = NULL WHERE delivery_id = ?",
 		now, deliveryID)
@@ -198,3 +239,45 @@ func (db *DBWrapper) MarkEventFailed(ctx context.Context, deliveryID string) err
 	}
 	return nil
 }
+
+// purgeBatchSize bounds the rows one purge statement deletes, so webhook
+// intake is not blocked for the whole purge.
+const purgeBatchSize = 5000
+
+// PurgeWebhookEvents deletes the webhook events with the given status that
+// were received before the cutoff, ahead of the retention period. It deletes
+// in batches and returns how many went; on a dry run it only counts them.
+func (db *DBWrapper) PurgeWebhookEvents(ctx context.Context, status string, before time.Time, dryRun bool) (int64, error) {
+	cutoff := before.UTC().Format(time.RFC3339)
+
+	if dryRun {
+		var count int64
+		err := db.db.QueryRowContext(ctx,
+			"SELECT COUNT(*) FROM webhook_events WHERE status = ? AND julianday(received_at) < julianday(?)",
+			status, cutoff).Scan(&count)
+		if err != nil {
+			return 0, fmt.Errorf("failed to count webhook events to purge: %w", err)
+		}
+		return count, nil
+	}
+
+	var total int64
+	for {
+		result, err := db.db.ExecContext(ctx, `
+			DELETE FROM webhook_events WHERE rowid IN (
+				SELECT rowid FROM webhook_events
+				WHERE status = ? AND julianday(received_at) < julianday(?)
+				LIMIT ?)`, status, cutoff, purgeBatchSize)
+		if err != nil {
+			return total, fmt.Errorf("failed to purge webhook events: %w", err)
+		}
+		deleted, err := result.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+		total += deleted
+		if deleted < purgeBatchSize {
+			return total, nil
+		}
+	}
+}
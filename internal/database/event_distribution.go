@@ -0,0 +1,52 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+)
+
+// GetEventKeyCounts returns how many webhook events each ordering key received
+// per bucket of the given size, for events received within the window. The
+// repository is looked up through the run or job the key names, since
+// payloads are dropped once processed, and is empty when it is unknown.
+func (db *DBWrapper) GetEventKeyCounts(ctx context.Context, since, bucket time.Duration) ([]models.EventKeyCount, error) {
+	cutoff := db.clock.Now().UTC().Add(-since).Format(time.RFC3339)
+	bucketSeconds := int64(bucket.Seconds())
+
+	rows, err := db.db.QueryContext(ctx, `
+		SELECT e.bucket_start, e.ordering_key, COALESCE(r.repository, ''), e.events
+		FROM (
+			SELECT CAST(strftime('%s', received_at) AS INTEGER) / ? * ? AS bucket_start,
+				ordering_key, COUNT(*) AS events
+			FROM webhook_events
+			WHERE julianday(received_at) >= julianday(?)
+			GROUP BY bucket_start, ordering_key
+		) e
+		LEFT JOIN workflow_runs r ON r.id = CASE
+			WHEN e.ordering_key LIKE 'run\_%' ESCAPE '\' THEN CAST(substr(e.ordering_key, 5) AS INTEGER)
+			WHEN e.ordering_key LIKE 'review\_%' ESCAPE '\' THEN CAST(substr(e.ordering_key, 8) AS INTEGER)
+			WHEN e.ordering_key LIKE 'job\_%' ESCAPE '\' THEN
+				(SELECT j.run_id FROM workflow_jobs j WHERE j.id = CAST(substr(e.ordering_key, 5) AS INTEGER))
+		END
+		ORDER BY e.bucket_start, e.ordering_key`, bucketSeconds, bucketSeconds, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event key counts: %w", err)
+	}
+	defer rows.Close()
+
+	counts := []models.EventKeyCount{}
+	for rows.Next() {
+		var c models.EventKeyCount
+		var bucketStart sql.NullInt64
+		if err := rows.Scan(&bucketStart, &c.OrderingKey, &c.Repository, &c.Events); err != nil {
+			return nil, fmt.Errorf("failed to scan event key count: %w", err)
+		}
+		c.BucketStart = time.Unix(bucketStart.Int64, 0).UTC()
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
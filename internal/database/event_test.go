@@ -0,0 +1,45 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaimPendingEvent(t *testing.T) {
+	db := newTestDB(t, "")
+	ctx := context.Background()
+	now := time.Now()
+	event := &models.OrderedEvent{
+		Sequence:    models.EventSequence{DeliveryID: "delivery-1", Timestamp: now, ReceivedAt: now},
+		EventType:   "workflow_job",
+		RawPayload:  []byte(`{}`),
+		OrderingKey: "job_1",
+	}
+	require.NoError(t, db.StoreWebhookEvent(ctx, event))
+
+	claimed, err := db.ClaimPendingEvent(ctx, "delivery-1")
+	require.NoError(t, err)
+	assert.True(t, claimed)
+	claimed, err = db.ClaimPendingEvent(ctx, "delivery-1")
+	require.NoError(t, err)
+	assert.False(t, claimed, "an event is claimed once")
+
+	pending, err := db.GetPendingEventsGrouped(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, pending, "claimed events are not flushed")
+
+	require.NoError(t, db.ReleaseClaimedEvents(ctx))
+	pending, err = db.GetPendingEventsGrouped(ctx, 10)
+	require.NoError(t, err)
+	assert.Len(t, pending, 1)
+
+	require.NoError(t, db.MarkEventProcessed(ctx, "delivery-1"))
+	claimed, err = db.ClaimPendingEvent(ctx, "delivery-1")
+	require.NoError(t, err)
+	assert.False(t, claimed, "processed events cannot be claimed")
+}
@@ -3,59 +3,115 @@ package database
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/gateixeira/live-actions/models"
 )
 
+// maxFailingJobNames bounds the job names failure analytics return, leaving
+// room for callers to merge renamed jobs before picking the top failing ones.
+const maxFailingJobNames = 500
+
 // GetFailureAnalytics returns failure summary statistics for completed jobs
-// within the given time window. If repo is non-empty, filters to that repository.
-func (db *DBWrapper) GetFailureAnalytics(ctx context.Context, since time.Duration, repo string) (*models.FailureAnalytics, error) {
-	cutoff := time.Now().Add(-since).Format(time.RFC3339)
+// within the given time window. If repos is non-empty, filters to those repositories.
+// TopFailingJobs holds the counts of every job name, most failures first,
+// including names without failures so renamed jobs can be merged.
+func (db *DBWrapper) GetFailureAnalytics(ctx context.Context, since time.Duration, repos []string) (*models.FailureAnalytics, error) {
+	cutoffTime := db.clock.Now().Add(-since)
+
+	// Successful jobs removed by sampling still count towards the totals
+	sampledTotal, sampledByName, err := db.sampledSuccesses(ctx, cutoffTime, repos)
+	if err != nil {
+		return nil, err
+	}
+
+	analytics, err := db.failureSummary(ctx, cutoffTime, repos, sampledTotal)
+	if err != nil {
+		return nil, err
+	}
+	if analytics.TopFailingJobs, err = db.topFailingJobs(ctx, cutoffTime, repos, sampledByName); err != nil {
+		return nil, err
+	}
+	return analytics, nil
+}
 
-	repoJoin, repoArgs := jobRepoFilter(repo)
+// GetFailureSummary returns the totals of GetFailureAnalytics without
+// TopFailingJobs, for callers that do not report jobs.
+func (db *DBWrapper) GetFailureSummary(ctx context.Context, since time.Duration, repos []string) (*models.FailureAnalytics, error) {
+	cutoffTime := db.clock.Now().Add(-since)
+	sampledTotal, _, err := db.sampledSuccesses(ctx, cutoffTime, repos)
+	if err != nil {
+		return nil, err
+	}
+	return db.failureSummary(ctx, cutoffTime, repos, sampledTotal)
+}
 
-	var totalCompleted, totalFailed, totalCancelled int
-	args := append([]interface{}{cutoff}, repoArgs...)
+// failureSummary counts the jobs completed since cutoff, adding sampled
+// successful jobs to the total.
+func (db *DBWrapper) failureSummary(ctx context.Context, cutoffTime time.Time, repos []string, sampled int) (*models.FailureAnalytics, error) {
+	repoJoin, repoArgs := jobRepoFilter(repos)
+
+	// Failures of muted jobs are reported separately so acknowledged breakages
+	// stay recorded without inflating the failure rate.
+	var totalCompleted, totalFailed, totalCancelled, totalMuted int
+	mutedAt := db.mutedAt()
+	args := append([]interface{}{mutedAt, mutedAt, cutoffTime.Format(time.RFC3339)}, repoArgs...)
 	err := db.db.QueryRowContext(ctx, `
 		SELECT
 			COUNT(*),
-			COALESCE(SUM(CASE WHEN j.conclusion IN ('failure','timed_out') THEN 1 ELSE 0 END), 0),
-			COALESCE(SUM(CASE WHEN j.conclusion = 'cancelled' THEN 1 ELSE 0 END), 0)
+			COALESCE(SUM(CASE WHEN j.conclusion IN ('failure','timed_out') AND NOT `+mutedJobCondition+` THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN j.conclusion = 'cancelled' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN j.conclusion IN ('failure','timed_out') AND `+mutedJobCondition+` THEN 1 ELSE 0 END), 0)
 		FROM workflow_jobs j`+repoJoin+`
-		WHERE j.status = 'completed' AND j.completed_at >= ?`+repoWhere(repo), args...).Scan(&totalCompleted, &totalFailed, &totalCancelled)
+		WHERE j.status = 'completed' AND j.completed_at >= ?`+repoWhere(repos), args...).Scan(&totalCompleted, &totalFailed, &totalCancelled, &totalMuted)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get failure summary: %w", err)
 	}
+	totalCompleted += sampled
 
 	var failureRate float64
 	if totalCompleted > 0 {
 		failureRate = float64(totalFailed) / float64(totalCompleted) * 100
 	}
 
+	return &models.FailureAnalytics{
+		TotalCompleted: totalCompleted,
+		TotalFailed:    totalFailed,
+		TotalCancelled: totalCancelled,
+		TotalMuted:     totalMuted,
+		FailureRate:    failureRate,
+	}, nil
+}
+
+// topFailingJobs returns the counts of every job name completed since
+// cutoff, most failures first, adding sampled successful jobs by name.
+func (db *DBWrapper) topFailingJobs(ctx context.Context, cutoffTime time.Time, repos []string, sampledByName map[string]int) ([]models.FailingJob, error) {
+	repoJoin, repoArgs := jobRepoFilter(repos)
+	args := append([]interface{}{db.mutedAt(), cutoffTime.Format(time.RFC3339)}, repoArgs...)
 	rows, err := db.db.QueryContext(ctx, `
 		SELECT
 			j.name,
 			MAX(j.html_url) AS html_url,
-			SUM(CASE WHEN j.conclusion IN ('failure','timed_out') THEN 1 ELSE 0 END) AS failures,
+			SUM(CASE WHEN j.conclusion IN ('failure','timed_out') AND NOT `+mutedJobCondition+` THEN 1 ELSE 0 END) AS failures,
 			COUNT(*) AS total
 		FROM workflow_jobs j`+repoJoin+`
-		WHERE j.status = 'completed' AND j.completed_at >= ?`+repoWhere(repo)+`
+		WHERE j.status = 'completed' AND j.completed_at >= ?`+repoWhere(repos)+`
 		GROUP BY j.name
-		HAVING failures > 0
-		ORDER BY failures DESC
-		LIMIT 10`, args...)
+		ORDER BY failures DESC, total DESC, j.name ASC
+		LIMIT ?`, append(args, maxFailingJobNames)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get top failing jobs: %w", err)
 	}
 	defer rows.Close()
 
-	var topFailing []models.FailingJob
+	topFailing := []models.FailingJob{}
 	for rows.Next() {
 		var j models.FailingJob
 		if err := rows.Scan(&j.Name, &j.HtmlUrl, &j.Failures, &j.Total); err != nil {
 			return nil, fmt.Errorf("failed to scan failing job: %w", err)
 		}
+		j.Total += sampledByName[j.Name]
 		if j.Total > 0 {
 			j.FailureRate = float64(j.Failures) / float64(j.Total) * 100
 		}
@@ -64,31 +120,22 @@ func (db *DBWrapper) GetFailureAnalytics(ctx context.Context, since time.Duratio
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
-
-	if topFailing == nil {
-		topFailing = []models.FailingJob{}
-	}
-
-	return &models.FailureAnalytics{
-		TotalCompleted: totalCompleted,
-		TotalFailed:    totalFailed,
-		TotalCancelled: totalCancelled,
-		FailureRate:    failureRate,
-		TopFailingJobs: topFailing,
-	}, nil
+	return topFailing, nil
 }
 
 // GetFailureTrend returns time-bucketed failure/success/cancelled counts.
-// Uses hourly buckets for periods <= 1 day, daily buckets otherwise.
-func (db *DBWrapper) GetFailureTrend(ctx context.Context, since time.Duration, repo string) ([]models.FailureTrendPoint, error) {
-	cutoff := time.Now().Add(-since).Format(time.RFC3339)
+// Uses hourly buckets for periods <= 1 day, daily buckets read from the job
+// counters otherwise.
+func (db *DBWrapper) GetFailureTrend(ctx context.Context, since time.Duration, repos []string) ([]models.FailureTrendPoint, error) {
+	cutoffTime := db.clock.Now().Add(-since)
+	cutoff := cutoffTime.Format(time.RFC3339)
 
-	bucketFormat := "%Y-%m-%dT%H:00:00Z"
 	if since > 24*time.Hour {
-		bucketFormat = "%Y-%m-%dT00:00:00Z"
+		return db.counterFailureTrend(ctx, cutoffTime, repos)
 	}
+	bucketFormat := "%Y-%m-%dT%H:00:00Z"
 
-	repoJoin, repoArgs := jobRepoFilter(repo)
+	repoJoin, repoArgs := jobRepoFilter(repos)
 	args := append([]interface{}{cutoff}, repoArgs...)
 
 	rows, err := db.db.QueryContext(ctx, fmt.Sprintf(`
@@ -98,7 +145,7 @@ func (db *DBWrapper) GetFailureTrend(ctx context.Context, since time.Duration, r
 			COALESCE(SUM(CASE WHEN j.conclusion = 'success' THEN 1 ELSE 0 END), 0),
 			COALESCE(SUM(CASE WHEN j.conclusion = 'cancelled' THEN 1 ELSE 0 END), 0)
 		FROM workflow_jobs j`+repoJoin+`
-		WHERE j.status = 'completed' AND j.completed_at >= ?`+repoWhere(repo)+`
+		WHERE j.status = 'completed' AND j.completed_at >= ?`+repoWhere(repos)+`
 		GROUP BY bucket
 		ORDER BY bucket ASC`, bucketFormat), args...)
 	if err != nil {
@@ -121,9 +168,58 @@ func (db *DBWrapper) GetFailureTrend(ctx context.Context, since time.Duration, r
 		return nil, err
 	}
 
+	points, err = db.addSampledSuccesses(ctx, points, bucketFormat, cutoffTime, repos)
+	if err != nil {
+		return nil, err
+	}
+
 	if points == nil {
 		points = []models.FailureTrendPoint{}
 	}
 
 	return points, nil
 }
+
+// addSampledSuccesses adds the successful jobs removed by sampling to the
+// trend buckets, creating buckets that only contain sampled jobs.
+func (db *DBWrapper) addSampledSuccesses(ctx context.Context, points []models.FailureTrendPoint, bucketFormat string, cutoff time.Time, repos []string) ([]models.FailureTrendPoint, error) {
+	args := append([]interface{}{cutoff.UTC().Truncate(time.Hour).Format(time.RFC3339)}, repoArgs(repos)...)
+	rows, err := db.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT strftime('%s', hour) AS bucket, SUM(jobs)
+		FROM sampled_job_counters
+		WHERE hour >= ?`+repoIn("repository", repos)+`
+		GROUP BY bucket`, bucketFormat), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sampled job trend: %w", err)
+	}
+	defer rows.Close()
+
+	index := make(map[int64]int, len(points))
+	for i, p := range points {
+		index[p.Timestamp] = i
+	}
+	added := false
+	for rows.Next() {
+		var bucketStr string
+		var jobs int
+		if err := rows.Scan(&bucketStr, &jobs); err != nil {
+			return nil, fmt.Errorf("failed to scan sampled job trend: %w", err)
+		}
+		t, _ := time.Parse("2006-01-02T15:04:05Z", bucketStr)
+		if i, ok := index[t.Unix()]; ok {
+			points[i].Successes += jobs
+			continue
+		}
+		index[t.Unix()] = len(points)
+		points = append(points, models.FailureTrendPoint{Timestamp: t.Unix(), Successes: jobs})
+		added = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if added {
+		sort.Slice(points, func(i, j int) bool { return points[i].Timestamp < points[j].Timestamp })
+	}
+	return points, nil
+}
@@ -0,0 +1,85 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+)
+
+// GetNotableRuns returns runs worth surfacing in the activity feed: failed
+// runs completed within the window and runs that have been in progress for
+// longer than longRunning. If workflowFilters is non-empty, only workflows
+// whose name contains one of the filters (case-insensitive) are included.
+func (db *DBWrapper) GetNotableRuns(ctx context.Context, since time.Duration, longRunning time.Duration, workflowFilters []string, limit int) ([]models.NotableRun, error) {
+	cutoff := db.clock.Now().Add(-since).Format(time.RFC3339)
+	longRunningCutoff := db.clock.Now().Add(-longRunning).Format(time.RFC3339)
+
+	filterWhere, filterArgs := workflowNameFilter(workflowFilters)
+
+	args := []interface{}{cutoff, longRunningCutoff}
+	args = append(args, filterArgs...)
+	args = append(args, limit)
+
+	rows, err := db.db.QueryContext(ctx, `
+		SELECT kind, id, name, status, repository, html_url, display_title, conclusion, created_at, run_started_at, updated_at
+		FROM (
+			SELECT 'failure' AS kind, * FROM workflow_runs
+			WHERE status = 'completed' AND conclusion IN ('failure', 'timed_out') AND updated_at >= ?
+			UNION ALL
+			SELECT 'long_running' AS kind, * FROM workflow_runs
+			WHERE status = 'in_progress' AND run_started_at IS NOT NULL AND run_started_at < ?
+		)
+		WHERE 1=1`+filterWhere+`
+		ORDER BY COALESCE(updated_at, created_at) DESC
+		LIMIT ?`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notable runs: %w", err)
+	}
+	defer rows.Close()
+
+	var results []models.NotableRun
+	for rows.Next() {
+		var n models.NotableRun
+		var createdAt, startedAt, updatedAt sql.NullString
+		var repository, htmlURL, displayTitle, conclusion sql.NullString
+		if err := rows.Scan(&n.Kind, &n.Run.ID, &n.Run.Name, &n.Run.Status, &repository, &htmlURL, &displayTitle, &conclusion, &createdAt, &startedAt, &updatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan notable run: %w", err)
+		}
+		n.Run.RepositoryName = repository.String
+		n.Run.HtmlUrl = htmlURL.String
+		n.Run.DisplayTitle = displayTitle.String
+		n.Run.Conclusion = conclusion.String
+		n.Run.CreatedAt = parseTime(createdAt.String)
+		n.Run.RunStartedAt = parseTime(startedAt.String)
+		n.Run.UpdatedAt = parseTime(updatedAt.String)
+		results = append(results, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if results == nil {
+		results = []models.NotableRun{}
+	}
+
+	return results, nil
+}
+
+// workflowNameFilter returns an AND clause matching any of the given
+// substrings against the workflow name.
+func workflowNameFilter(filters []string) (string, []interface{}) {
+	if len(filters) == 0 {
+		return "", nil
+	}
+	clauses := make([]string, len(filters))
+	args := make([]interface{}, len(filters))
+	for i, f := range filters {
+		clauses[i] = `LOWER(name) LIKE ? ESCAPE '\'`
+		args[i] = "%" + escapeLike(strings.ToLower(f)) + "%"
+	}
+	return " AND (" + strings.Join(clauses, " OR ") + ")", args
+}
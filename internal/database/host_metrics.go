@@ -0,0 +1,73 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+)
+
+// SaveHostMetricSamples stores samples pushed by runner exporters in one
+// transaction, so a remote-write request is kept or retried as a whole.
+func (db *DBWrapper) SaveHostMetricSamples(ctx context.Context, samples []models.HostMetricSample) error {
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback()
+		}
+	}()
+
+	stmt, err := tx.PrepareContext(ctx,
+		"INSERT INTO host_metrics (host, metric, labels, timestamp, value) VALUES (?, ?, ?, ?, ?)")
+	if err != nil {
+		return fmt.Errorf("failed to prepare host metric insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, s := range samples {
+		if _, err := stmt.ExecContext(ctx, s.Host, s.Metric, s.Labels, s.Timestamp.UTC().Format(time.RFC3339), s.Value); err != nil {
+			return fmt.Errorf("failed to save host metric sample: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit host metric samples: %w", err)
+	}
+	committed = true
+	return nil
+}
+
+// GetHostMetricSeries returns the values of metric within the given duration,
+// one series per host and label set, oldest value first.
+func (db *DBWrapper) GetHostMetricSeries(ctx context.Context, metric string, since time.Duration) ([]models.HostMetricSeries, error) {
+	cutoff := db.clock.Now().UTC().Add(-since).Format(time.RFC3339)
+	rows, err := db.db.QueryContext(ctx, `
+		SELECT host, labels, timestamp, value
+		FROM host_metrics
+		WHERE metric = ? AND timestamp >= ?
+		ORDER BY host, labels, timestamp`, metric, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get host metric series: %w", err)
+	}
+	defer rows.Close()
+
+	series := []models.HostMetricSeries{}
+	for rows.Next() {
+		var host, labels, timestamp string
+		var value float64
+		if err := rows.Scan(&host, &labels, &timestamp, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan host metric sample: %w", err)
+		}
+		if n := len(series); n == 0 || series[n-1].Host != host || series[n-1].Labels != labels {
+			series = append(series, models.HostMetricSeries{Host: host, Labels: labels})
+		}
+		last := &series[len(series)-1]
+		last.Points = append(last.Points, models.HostMetricPoint{Timestamp: parseTime(timestamp).Unix(), Value: value})
+	}
+	return series, rows.Err()
+}
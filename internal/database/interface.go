@@ -5,56 +5,166 @@ import (
 	"database/sql"
 	"time"
 
+	"github.com/gateixeira/live-actions/internal/clock"
 	"github.com/gateixeira/live-actions/models"
 )
 
 // DatabaseInterface defines the contract for database operations
 type DatabaseInterface interface {
+	// ReadSnapshot runs fn against a view of the database that does not
+	// change while it runs, for responses built from several queries
+	ReadSnapshot(ctx context.Context, fn func(DatabaseInterface) error) error
+
 	// Workflow Jobs
 	AddOrUpdateJob(ctx context.Context, workflowJob models.WorkflowJob, eventTimestamp time.Time) (bool, error)
 	GetWorkflowJobByID(ctx context.Context, jobID int64) (models.WorkflowJob, error)
 	GetWorkflowJobsByRunID(ctx context.Context, runID int64) ([]models.WorkflowJob, error)
+	GetWorkflowJobsByRunIDs(ctx context.Context, runIDs []int64) (map[int64][]models.WorkflowJob, error)
 	GetCurrentJobCounts(ctx context.Context) (int, int, error)
+	GetJobDurationStats(ctx context.Context, runID int64) (map[string]models.DurationStats, error)
+	GetRunDurations(ctx context.Context, since time.Duration, repos []string) ([]models.RunDuration, error)
+	GetUnschedulableJobs(ctx context.Context, queuedFor time.Duration, repos []string) ([]models.UnschedulableJob, error)
+	GetRunnerPoolStatus(ctx context.Context) ([]models.RunnerPoolStatus, error)
+	GetRunnerGroupStats(ctx context.Context, since time.Duration) ([]models.RunnerGroupStats, error)
+	GetRunnerHosts(ctx context.Context) ([]models.RunnerHost, error)
+	SaveRunnerHost(ctx context.Context, host models.RunnerHost) error
+	RecordRunnerHeartbeat(ctx context.Context, host models.RunnerHost) error
+	DeleteRunnerHost(ctx context.Context, name string) (bool, error)
+	GetRunnerHostStats(ctx context.Context, since time.Duration, by string) ([]models.RunnerHostStats, error)
+	GetJobTimings(ctx context.Context, since time.Duration, label string) ([]models.JobTiming, error)
+	GetActorStats(ctx context.Context, since time.Duration, repos []string, limit int) ([]models.ActorStats, error)
+	RecordJobWaiting(ctx context.Context, jobID, runID int64, at time.Time) error
+	RecordJobApproved(ctx context.Context, jobID int64, at time.Time) error
+	SetJobEnvironments(ctx context.Context, runID int64, jobs []models.EnvironmentJob) error
+	GetApprovalStats(ctx context.Context, since time.Duration, repos []string) ([]models.ApprovalStats, error)
+	HasOtherPendingEvents(ctx context.Context, orderingKey string, deliveryID string) (bool, error)
+	ClaimPendingEvent(ctx context.Context, deliveryID string) (bool, error)
+	ReleaseClaimedEvents(ctx context.Context) error
+	GetSchemaVersion(ctx context.Context) (int, error)
+	CheckWritable(ctx context.Context) error
+	GetEventSamples(ctx context.Context, limit int) ([]models.EventSample, error)
+	SaveCanaryResult(ctx context.Context, result models.CanaryResult) error
+	GetCanaryResults(ctx context.Context, limit int) ([]models.CanaryResult, error)
+	RecordSLOBreach(ctx context.Context, breach models.SLOBreach) error
+	GetSLOBreaches(ctx context.Context, since time.Duration, limit int) ([]models.SLOBreach, error)
+	GetEventStatusSequences(ctx context.Context, since time.Duration) ([]models.EventStatusSequence, error)
+	SaveHostMetricSamples(ctx context.Context, samples []models.HostMetricSample) error
+	GetHostMetricSeries(ctx context.Context, metric string, since time.Duration) ([]models.HostMetricSeries, error)
+	GetSparklines(ctx context.Context, start time.Time, bucket time.Duration, points int) (*models.Sparklines, error)
+	GetSettings(ctx context.Context) (map[string]string, error)
+	SaveSettings(ctx context.Context, settings map[string]string) error
 
 	// Workflow Runs
 	AddOrUpdateRun(ctx context.Context, workflowRun models.WorkflowRun, eventTimestamp time.Time) (bool, error)
-	GetWorkflowRunsPaginated(ctx context.Context, page int, limit int, repo string, status string) ([]models.WorkflowRun, int, error)
+	GetWorkflowRunsPaginated(ctx context.Context, page int, limit int, repos []string, status string, sha string) ([]models.WorkflowRun, int, error)
+	GetWorkflowRunByID(ctx context.Context, runID int64) (*models.WorkflowRun, error)
+	GetLatestWorkflowRun(ctx context.Context, repo string, workflow string) (*models.WorkflowRun, error)
+	GetNotableRuns(ctx context.Context, since time.Duration, longRunning time.Duration, workflowFilters []string, limit int) ([]models.NotableRun, error)
+
+	// Run Tags and Saved Filters
+	AddRunTag(ctx context.Context, runID int64, tag string) (bool, error)
+	RemoveRunTag(ctx context.Context, runID int64, tag string) (bool, error)
+	GetRunTags(ctx context.Context, runID int64) ([]string, error)
+	GetSavedFilters(ctx context.Context) ([]models.SavedFilter, error)
+	SaveFilter(ctx context.Context, filter models.SavedFilter) (int64, error)
+	DeleteSavedFilter(ctx context.Context, id int64) (bool, error)
+	GetRepoGroups(ctx context.Context) ([]models.RepoGroup, error)
+	SaveRepoGroup(ctx context.Context, group models.RepoGroup) error
+	DeleteRepoGroup(ctx context.Context, name string) (bool, error)
+
+	// Workflow SLOs
+	GetWorkflowSLOs(ctx context.Context) ([]models.WorkflowSLO, error)
+	SaveWorkflowSLO(ctx context.Context, slo models.WorkflowSLO) error
+	DeleteWorkflowSLO(ctx context.Context, name string) (bool, error)
+	GetSLORuns(ctx context.Context, repository, workflow string, since time.Duration) ([]models.SLORun, error)
+	GetWorkflowFiles(ctx context.Context, since time.Duration) ([]models.WorkflowFile, error)
+	SaveWorkflowFileChanges(ctx context.Context, changes []models.WorkflowFileChange) error
+	GetWorkflowFileChanges(ctx context.Context, since time.Duration, repos []string) ([]models.WorkflowFileChange, error)
+	GetRunOutcomes(ctx context.Context, since time.Duration, repos []string) ([]models.RunOutcome, error)
+
+	// Job name merges
+	GetJobNameCounts(ctx context.Context, since time.Duration, repos []string, limit int) ([]models.JobNameCount, error)
+	GetJobNameMerges(ctx context.Context) ([]models.JobNameMerge, error)
+	SaveJobNameMerge(ctx context.Context, merge models.JobNameMerge) error
+	DeleteJobNameMerge(ctx context.Context, alias, canonical string) (bool, error)
+
+	// Mutes
+	CreateMute(ctx context.Context, mute models.Mute) (int64, error)
+	GetActiveMutes(ctx context.Context) ([]models.Mute, error)
+	DeleteMute(ctx context.Context, id int64) (bool, error)
+	IsMuted(ctx context.Context, runID int64, jobID int64) (bool, error)
 
 	// Metrics Snapshots
 	InsertMetricsSnapshot(ctx context.Context, running, queued int) error
 	GetMetricsHistory(ctx context.Context, since time.Duration) ([]models.MetricsSnapshot, error)
 	GetMetricsSummary(ctx context.Context, since time.Duration) (map[string]float64, error)
+	GetRunnerCapacityByLabel(ctx context.Context, seenWithin time.Duration) (map[string]int, error)
+	GetCurrentLabelDemand(ctx context.Context) ([]LabelJobCount, error)
+	SaveLabelCapacitySamples(ctx context.Context, samples []models.LabelCapacitySample) error
+	GetLabelCapacitySamples(ctx context.Context, since time.Duration, label string) ([]models.LabelCapacitySample, error)
 
 	// Webhook Events
 	StoreWebhookEvent(ctx context.Context, event *models.OrderedEvent) error
 	GetPendingEventsGrouped(ctx context.Context, limit int) ([]*models.OrderedEvent, error)
 	GetPendingEventsByAge(ctx context.Context, maxAge time.Duration, limit int) ([]*models.OrderedEvent, error)
 	MarkEventProcessed(ctx context.Context, deliveryID string) error
+	GetProcessingLagStats(ctx context.Context, since time.Duration) (*models.ProcessingLagStats, error)
+	PruneSampledJobs(ctx context.Context, keepPercent int, olderThan time.Duration) (int64, error)
+	RecordStorageSample(ctx context.Context) error
+	GetStorageReport(ctx context.Context) (*models.StorageReport, error)
 	MarkEventFailed(ctx context.Context, deliveryID string) error
+	PurgeWebhookEvents(ctx context.Context, status string, before time.Time, dryRun bool) (int64, error)
+	GetEventKeyCounts(ctx context.Context, since, bucket time.Duration) ([]models.EventKeyCount, error)
 
 	// Cleanup
 	CleanupOldData(ctx context.Context, retentionPeriod time.Duration) (int64, int64, int64, error)
 	CleanupStaleJobs(ctx context.Context, threshold time.Duration) (int64, error)
+	PruneData(ctx context.Context, filter models.PruneFilter, dryRun bool) (models.PruneResult, error)
+	CompactMetricsSnapshots(ctx context.Context, before time.Time, bucket time.Duration, dryRun bool) (models.SnapshotCompaction, error)
 
 	// Repositories
 	GetRepositories(ctx context.Context) ([]string, error)
 
 	// Failure Analytics
-	GetFailureAnalytics(ctx context.Context, since time.Duration, repo string) (*models.FailureAnalytics, error)
-	GetFailureTrend(ctx context.Context, since time.Duration, repo string) ([]models.FailureTrendPoint, error)
+	GetFailureAnalytics(ctx context.Context, since time.Duration, repos []string) (*models.FailureAnalytics, error)
+	GetFailureSummary(ctx context.Context, since time.Duration, repos []string) (*models.FailureAnalytics, error)
+	GetFailureTrend(ctx context.Context, since time.Duration, repos []string) ([]models.FailureTrendPoint, error)
 
 	// Label Demand
-	GetLabelDemandSummary(ctx context.Context, since time.Duration, repo string) ([]models.LabelDemandSummary, error)
-	GetLabelDemandTrend(ctx context.Context, since time.Duration, repo string) ([]models.LabelDemandTrendPoint, error)
+	GetLabelDemandSummary(ctx context.Context, since time.Duration, repos []string) ([]models.LabelDemandSummary, error)
+	GetLabelDemandTrend(ctx context.Context, since time.Duration, repos []string) ([]models.LabelDemandTrendPoint, error)
 	GetCurrentJobCountsByLabel(ctx context.Context) ([]LabelJobCount, error)
+	GetLabelQueueStats(ctx context.Context, from, to time.Time, repos []string) ([]models.LabelQueueStats, error)
+	GetJobQueueWaits(ctx context.Context, from, to time.Time, repos []string) ([]models.JobQueueWait, error)
+	GetLabelThroughput(ctx context.Context, from, to time.Time, repos []string) ([]models.LabelThroughput, error)
+	GetJobThroughput(ctx context.Context, from, to time.Time, repos []string) (models.LabelThroughput, error)
 }
 
 // DBWrapper wraps the actual DB instance and implements DatabaseInterface
 type DBWrapper struct {
-	db *sql.DB
+	db    sqlDB
+	clock clock.Clock
+}
+
+// sqlDB is the part of *sql.DB that DBWrapper uses, so the same queries can
+// run inside a read snapshot's transaction.
+type sqlDB interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
 }
 
 // NewDBWrapper creates a new DBWrapper instance
 func NewDBWrapper(db *sql.DB) DatabaseInterface {
-	return &DBWrapper{db: db}
+	return NewDBWrapperWithClock(db, clock.Real())
+}
+
+// NewDBWrapperWithClock creates a DBWrapper whose retention, stale job and
+// analytics windows are measured from clk
+func NewDBWrapperWithClock(db *sql.DB, clk clock.Clock) DatabaseInterface {
+	return &DBWrapper{db: db, clock: clk}
 }
@@ -0,0 +1,108 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+)
+
+// GetJobNameCounts returns the names of jobs completed within the window with
+// their number of jobs, most jobs first, at most limit of them.
+func (db *DBWrapper) GetJobNameCounts(ctx context.Context, since time.Duration, repos []string, limit int) ([]models.JobNameCount, error) {
+	repoJoin, repoArgs := jobRepoFilter(repos)
+	args := append([]interface{}{db.clock.Now().Add(-since).Format(time.RFC3339)}, repoArgs...)
+	args = append(args, limit)
+	rows, err := db.db.QueryContext(ctx, `
+		SELECT j.name, COUNT(*) AS jobs
+		FROM workflow_jobs j`+repoJoin+`
+		WHERE j.status = 'completed' AND j.completed_at >= ?`+repoWhere(repos)+`
+		GROUP BY j.name
+		ORDER BY jobs DESC, j.name ASC
+		LIMIT ?`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job name counts: %w", err)
+	}
+	defer rows.Close()
+
+	counts := []models.JobNameCount{}
+	for rows.Next() {
+		var c models.JobNameCount
+		if err := rows.Scan(&c.Name, &c.Jobs); err != nil {
+			return nil, fmt.Errorf("failed to scan job name count: %w", err)
+		}
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
+
+// GetJobNameMerges returns the reviewed job name merge decisions, most
+// recent first.
+func (db *DBWrapper) GetJobNameMerges(ctx context.Context) ([]models.JobNameMerge, error) {
+	rows, err := db.db.QueryContext(ctx, `
+		SELECT alias, canonical, status, decided_at
+		FROM job_name_merges
+		ORDER BY decided_at DESC, alias ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job name merges: %w", err)
+	}
+	defer rows.Close()
+
+	merges := []models.JobNameMerge{}
+	for rows.Next() {
+		var m models.JobNameMerge
+		var decidedAt string
+		if err := rows.Scan(&m.Alias, &m.Canonical, &m.Status, &decidedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan job name merge: %w", err)
+		}
+		m.DecidedAt = parseTime(decidedAt)
+		merges = append(merges, m)
+	}
+	return merges, rows.Err()
+}
+
+// SaveJobNameMerge records a decision on merging merge.Alias into
+// merge.Canonical. Confirming a merge replaces any other confirmed merge of
+// the alias, as a name is grouped under one canonical name only.
+func (db *DBWrapper) SaveJobNameMerge(ctx context.Context, merge models.JobNameMerge) error {
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if merge.Status == models.JobNameMergeConfirmed {
+		if _, err := tx.ExecContext(ctx,
+			"DELETE FROM job_name_merges WHERE alias = ? AND status = ?", merge.Alias, models.JobNameMergeConfirmed); err != nil {
+			return fmt.Errorf("failed to replace job name merge: %w", err)
+		}
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO job_name_merges (alias, canonical, status, decided_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (alias, canonical) DO UPDATE SET
+			status = excluded.status,
+			decided_at = excluded.decided_at`,
+		merge.Alias, merge.Canonical, merge.Status, merge.DecidedAt.UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("failed to save job name merge: %w", err)
+	}
+	return tx.Commit()
+}
+
+// DeleteJobNameMerge forgets a decision, so a rejected pair may be proposed
+// again and a confirmed one is reported apart. Returns false when there was
+// no decision on the pair.
+func (db *DBWrapper) DeleteJobNameMerge(ctx context.Context, alias, canonical string) (bool, error) {
+	result, err := db.db.ExecContext(ctx,
+		"DELETE FROM job_name_merges WHERE alias = ? AND canonical = ?", alias, canonical)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete job name merge: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
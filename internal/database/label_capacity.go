@@ -0,0 +1,125 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+)
+
+// GetRunnerCapacityByLabel returns how many registered runner hosts carry each
+// label and sent a heartbeat within the given window.
+func (db *DBWrapper) GetRunnerCapacityByLabel(ctx context.Context, seenWithin time.Duration) (map[string]int, error) {
+	cutoff := db.clock.Now().UTC().Add(-seenWithin).Format(time.RFC3339)
+	rows, err := db.db.QueryContext(ctx, `
+		SELECT l.value, COUNT(*)
+		FROM runner_hosts h, json_each(h.labels) l
+		WHERE h.last_seen_at >= ?
+		GROUP BY l.value`, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get runner capacity by label: %w", err)
+	}
+	defer rows.Close()
+
+	capacity := make(map[string]int)
+	for rows.Next() {
+		var label string
+		var runners int
+		if err := rows.Scan(&label, &runners); err != nil {
+			return nil, fmt.Errorf("failed to scan runner capacity: %w", err)
+		}
+		capacity[label] = runners
+	}
+	return capacity, rows.Err()
+}
+
+// GetCurrentLabelDemand returns the running and queued jobs needing each
+// label. A job counts under every one of its labels, as a runner host counts
+// towards the capacity of each of its labels.
+func (db *DBWrapper) GetCurrentLabelDemand(ctx context.Context) ([]LabelJobCount, error) {
+	rows, err := db.db.QueryContext(ctx, `
+		SELECT l.value,
+			SUM(CASE WHEN j.status = 'in_progress' THEN 1 ELSE 0 END),
+			SUM(CASE WHEN j.status = 'queued' THEN 1 ELSE 0 END)
+		FROM workflow_jobs j, json_each(j.labels) l
+		WHERE j.status IN ('in_progress', 'queued')
+		GROUP BY l.value`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get label demand: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []LabelJobCount
+	for rows.Next() {
+		var c LabelJobCount
+		if err := rows.Scan(&c.Label, &c.Running, &c.Queued); err != nil {
+			return nil, fmt.Errorf("failed to scan label demand: %w", err)
+		}
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
+
+// SaveLabelCapacitySamples stores one round of per-label demand and capacity
+// samples in a single transaction.
+func (db *DBWrapper) SaveLabelCapacitySamples(ctx context.Context, samples []models.LabelCapacitySample) error {
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback()
+		}
+	}()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT OR REPLACE INTO label_capacity_samples (label, timestamp, running, queued, capacity)
+		VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare label capacity insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, s := range samples {
+		if _, err := stmt.ExecContext(ctx, s.Label, s.Timestamp.UTC().Format(time.RFC3339), s.Running, s.Queued, s.Capacity); err != nil {
+			return fmt.Errorf("failed to save label capacity sample: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit label capacity samples: %w", err)
+	}
+	committed = true
+	return nil
+}
+
+// GetLabelCapacitySamples returns the demand and capacity samples within the
+// given duration, ordered by label then time. An empty label returns every
+// label's samples.
+func (db *DBWrapper) GetLabelCapacitySamples(ctx context.Context, since time.Duration, label string) ([]models.LabelCapacitySample, error) {
+	cutoff := db.clock.Now().UTC().Add(-since).Format(time.RFC3339)
+	rows, err := db.db.QueryContext(ctx, `
+		SELECT label, timestamp, running, queued, capacity
+		FROM label_capacity_samples
+		WHERE timestamp >= ? AND (? = '' OR label = ?)
+		ORDER BY label, timestamp`, cutoff, label, label)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get label capacity samples: %w", err)
+	}
+	defer rows.Close()
+
+	var samples []models.LabelCapacitySample
+	for rows.Next() {
+		var s models.LabelCapacitySample
+		var timestamp string
+		if err := rows.Scan(&s.Label, &timestamp, &s.Running, &s.Queued, &s.Capacity); err != nil {
+			return nil, fmt.Errorf("failed to scan label capacity sample: %w", err)
+		}
+		s.Timestamp = parseTime(timestamp)
+		samples = append(samples, s)
+	}
+	return samples, rows.Err()
+}
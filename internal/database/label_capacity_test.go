@@ -0,0 +1,39 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCurrentLabelDemand(t *testing.T) {
+	db := newTestDB(t, "")
+	ctx := context.Background()
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	jobs := []models.WorkflowJob{
+		{ID: 1, Status: models.JobStatusInProgress, Labels: []string{"self-hosted", "gpu"}},
+		{ID: 2, Status: models.JobStatusInProgress, Labels: []string{"self-hosted", "linux", "gpu"}},
+		{ID: 3, Status: models.JobStatusQueued, Labels: []string{"self-hosted", "linux"}},
+		{ID: 4, Status: models.JobStatusCompleted, Labels: []string{"self-hosted", "gpu"}},
+	}
+	for _, job := range jobs {
+		job.RunID, job.Name, job.CreatedAt = 1, "build", created
+		_, err := db.AddOrUpdateJob(ctx, job, created)
+		require.NoError(t, err)
+	}
+
+	counts, err := db.GetCurrentLabelDemand(ctx)
+	require.NoError(t, err)
+	byLabel := make(map[string]LabelJobCount)
+	for _, c := range counts {
+		byLabel[c.Label] = c
+	}
+	assert.Len(t, byLabel, 3)
+	assert.Equal(t, LabelJobCount{Label: "gpu", Running: 2}, byLabel["gpu"], "labels count wherever they are listed")
+	assert.Equal(t, LabelJobCount{Label: "linux", Running: 1, Queued: 1}, byLabel["linux"])
+	assert.Equal(t, LabelJobCount{Label: "self-hosted", Running: 2, Queued: 1}, byLabel["self-hosted"])
+}
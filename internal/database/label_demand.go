@@ -9,11 +9,11 @@ import (
 )
 
 // GetLabelDemandSummary returns per-label demand statistics for the given time window.
-// If repo is non-empty, filters to that repository.
-func (db *DBWrapper) GetLabelDemandSummary(ctx context.Context, since time.Duration, repo string) ([]models.LabelDemandSummary, error) {
-	cutoff := time.Now().Add(-since).Format(time.RFC3339)
+// If repos is non-empty, filters to those repositories.
+func (db *DBWrapper) GetLabelDemandSummary(ctx context.Context, since time.Duration, repos []string) ([]models.LabelDemandSummary, error) {
+	cutoff := db.clock.Now().Add(-since).Format(time.RFC3339)
 
-	repoJoin, repoArgs := jobRepoFilter(repo)
+	repoJoin, repoArgs := jobRepoFilter(repos)
 	args := append([]interface{}{cutoff}, repoArgs...)
 
 	rows, err := db.db.QueryContext(ctx, `
@@ -28,7 +28,7 @@ func (db *DBWrapper) GetLabelDemandSummary(ctx context.Context, since time.Durat
 				END
 			), 0) AS avg_queue_seconds
 		FROM workflow_jobs j`+repoJoin+`
-		WHERE j.created_at >= ? AND json_extract(j.labels, '$[0]') IS NOT NULL`+repoWhere(repo)+`
+		WHERE j.created_at >= ? AND json_extract(j.labels, '$[0]') IS NOT NULL`+repoWhere(repos)+`
 		GROUP BY label
 		ORDER BY total_jobs DESC`, args...)
 	if err != nil {
@@ -56,16 +56,18 @@ func (db *DBWrapper) GetLabelDemandSummary(ctx context.Context, since time.Durat
 }
 
 // GetLabelDemandTrend returns time-bucketed per-label job counts.
-// Uses hourly buckets for periods <= 1 day, daily buckets otherwise.
-func (db *DBWrapper) GetLabelDemandTrend(ctx context.Context, since time.Duration, repo string) ([]models.LabelDemandTrendPoint, error) {
-	cutoff := time.Now().Add(-since).Format(time.RFC3339)
+// Uses hourly buckets for periods <= 1 day, daily buckets read from the job
+// counters otherwise.
+func (db *DBWrapper) GetLabelDemandTrend(ctx context.Context, since time.Duration, repos []string) ([]models.LabelDemandTrendPoint, error) {
+	cutoffTime := db.clock.Now().Add(-since)
+	cutoff := cutoffTime.Format(time.RFC3339)
 
-	bucketFormat := "%Y-%m-%dT%H:00:00Z"
 	if since > 24*time.Hour {
-		bucketFormat = "%Y-%m-%dT00:00:00Z"
+		return db.counterLabelDemandTrend(ctx, cutoffTime, repos)
 	}
+	bucketFormat := "%Y-%m-%dT%H:00:00Z"
 
-	repoJoin, repoArgs := jobRepoFilter(repo)
+	repoJoin, repoArgs := jobRepoFilter(repos)
 	args := append([]interface{}{cutoff}, repoArgs...)
 
 	rows, err := db.db.QueryContext(ctx, fmt.Sprintf(`
@@ -74,7 +76,7 @@ func (db *DBWrapper) GetLabelDemandTrend(ctx context.Context, since time.Duratio
 			json_extract(j.labels, '$[0]') AS label,
 			COUNT(*) AS count
 		FROM workflow_jobs j`+repoJoin+`
-		WHERE j.created_at >= ? AND json_extract(j.labels, '$[0]') IS NOT NULL`+repoWhere(repo)+`
+		WHERE j.created_at >= ? AND json_extract(j.labels, '$[0]') IS NOT NULL`+repoWhere(repos)+`
 		GROUP BY bucket, label
 		ORDER BY bucket ASC, label ASC`, bucketFormat), args...)
 	if err != nil {
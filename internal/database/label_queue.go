@@ -0,0 +1,105 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/utils"
+	"github.com/gateixeira/live-actions/models"
+)
+
+// GetLabelQueueStats returns queue time percentiles per first label for the
+// jobs queued within [from, to) that have started. If repos is non-empty,
+// filters to those repositories.
+func (db *DBWrapper) GetLabelQueueStats(ctx context.Context, from, to time.Time, repos []string) ([]models.LabelQueueStats, error) {
+	repoJoin, repoArgs := jobRepoFilter(repos)
+	args := append([]interface{}{from.UTC().Format(time.RFC3339), to.UTC().Format(time.RFC3339)}, repoArgs...)
+
+	rows, err := db.db.QueryContext(ctx, `
+		SELECT label, queue_seconds FROM (
+			SELECT
+				json_extract(j.labels, '$[0]') AS label,
+				MAX(0, (julianday(j.started_at) - julianday(j.created_at)) * 86400) AS queue_seconds
+			FROM workflow_jobs j`+repoJoin+`
+			WHERE j.created_at >= ? AND j.created_at < ?
+				AND j.started_at IS NOT NULL AND j.started_at != ''
+				AND j.status IN ('in_progress', 'completed')`+repoWhere(repos)+`
+		)
+		WHERE label IS NOT NULL
+		ORDER BY label, queue_seconds`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get label queue times: %w", err)
+	}
+	defer rows.Close()
+
+	results := []models.LabelQueueStats{}
+	var label string
+	var waits []float64
+	flush := func() {
+		if len(waits) == 0 {
+			return
+		}
+		results = append(results, models.LabelQueueStats{
+			Label:           label,
+			Jobs:            len(waits),
+			P50QueueSeconds: utils.Percentile(waits, 50),
+			P90QueueSeconds: utils.Percentile(waits, 90),
+			MaxQueueSeconds: waits[len(waits)-1],
+		})
+	}
+	for rows.Next() {
+		var l string
+		var wait float64
+		if err := rows.Scan(&l, &wait); err != nil {
+			return nil, fmt.Errorf("failed to scan label queue time: %w", err)
+		}
+		if l != label {
+			flush()
+			label, waits = l, nil
+		}
+		waits = append(waits, wait)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+
+	return results, nil
+}
+
+// GetJobQueueWaits returns the jobs queued within [from, to) that have
+// started, with the labels they requested, oldest first. If repos is
+// non-empty, filters to those repositories.
+func (db *DBWrapper) GetJobQueueWaits(ctx context.Context, from, to time.Time, repos []string) ([]models.JobQueueWait, error) {
+	repoJoin, repoArgs := jobRepoFilter(repos)
+	args := append([]interface{}{from.UTC().Format(time.RFC3339), to.UTC().Format(time.RFC3339)}, repoArgs...)
+
+	rows, err := db.db.QueryContext(ctx, `
+		SELECT j.id, j.labels, j.created_at, j.started_at
+		FROM workflow_jobs j`+repoJoin+`
+		WHERE j.created_at >= ? AND j.created_at < ?
+			AND j.started_at IS NOT NULL AND j.started_at != ''
+			AND j.status IN ('in_progress', 'completed')`+repoWhere(repos)+`
+		ORDER BY j.created_at, j.id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job queue waits: %w", err)
+	}
+	defer rows.Close()
+
+	waits := []models.JobQueueWait{}
+	for rows.Next() {
+		var w models.JobQueueWait
+		var labels sql.NullString
+		var createdAt, startedAt string
+		if err := rows.Scan(&w.JobID, &labels, &createdAt, &startedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan job queue wait: %w", err)
+		}
+		w.Labels = labelsFromJSON(labels.String)
+		w.QueuedAt = parseTime(createdAt)
+		w.StartedAt = parseTime(startedAt)
+		waits = append(waits, w)
+	}
+	return waits, rows.Err()
+}
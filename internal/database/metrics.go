@@ -19,7 +19,7 @@ func (d *DBWrapper) InsertMetricsSnapshot(ctx context.Context, running, queued i
 
 // GetMetricsHistory returns time-series snapshots within the given duration.
 func (d *DBWrapper) GetMetricsHistory(ctx context.Context, since time.Duration) ([]models.MetricsSnapshot, error) {
-	cutoff := time.Now().UTC().Add(-since).Format("2006-01-02 15:04:05")
+	cutoff := d.clock.Now().UTC().Add(-since).Format("2006-01-02 15:04:05")
 	rows, err := d.db.QueryContext(ctx,
 		`SELECT timestamp, running_jobs, queued_jobs
 		 FROM metrics_snapshots
@@ -72,7 +72,7 @@ func (d *DBWrapper) GetMetricsSummary(ctx context.Context, since time.Duration)
 	result["queued_jobs"] = queued
 
 	// workflow_jobs stores timestamps as RFC3339
-	jobsCutoff := time.Now().UTC().Add(-since).Format(time.RFC3339)
+	jobsCutoff := d.clock.Now().UTC().Add(-since).Format(time.RFC3339)
 
 	// Average queue time: average seconds between created_at and started_at for
 	// jobs that started within the period.
@@ -97,7 +97,7 @@ func (d *DBWrapper) GetMetricsSummary(ctx context.Context, since time.Duration)
 	}
 
 	// metrics_snapshots stores timestamps as datetime (no T, no Z)
-	snapshotsCutoff := time.Now().UTC().Add(-since).Format("2006-01-02 15:04:05")
+	snapshotsCutoff := d.clock.Now().UTC().Add(-since).Format("2006-01-02 15:04:05")
 
 	// Peak demand from snapshots (max of running + queued in the period)
 	var peak float64
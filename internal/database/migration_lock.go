@@ -0,0 +1,193 @@
+package database
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Migration states reported by CurrentMigrationState.
+const (
+	MigrationPending        = "pending"
+	MigrationWaitingForLock = "waiting_for_lock"
+	MigrationRunning        = "migrating"
+	MigrationWaiting        = "waiting_for_migrations"
+	MigrationComplete       = "complete"
+	MigrationFailed         = "failed"
+)
+
+const (
+	// defaultMigrationTimeout bounds how long startup waits for the migration
+	// lock, or for another instance to migrate, when no timeout is given.
+	defaultMigrationTimeout = 10 * time.Minute
+	// migrationLockTTL is how long a lock may go unrefreshed before it is
+	// taken to be left behind by a crashed instance. The holder refreshes it
+	// with every migration it applies.
+	migrationLockTTL = 5 * time.Minute
+)
+
+// migrationPollInterval is how often a waiting instance checks the lock or the
+// schema version again.
+var migrationPollInterval = time.Second
+
+// MigrationOptions controls how an instance takes part in schema migrations
+// when several start against the same database.
+type MigrationOptions struct {
+	// Wait leaves migrating to another instance: startup waits until the
+	// schema reaches the version this build expects instead of applying
+	// migrations itself.
+	Wait bool
+	// Timeout bounds the wait for the migration lock or, with Wait, for the
+	// schema; zero uses the default of 10 minutes.
+	Timeout time.Duration
+}
+
+func (o MigrationOptions) timeout() time.Duration {
+	if o.Timeout <= 0 {
+		return defaultMigrationTimeout
+	}
+	return o.Timeout
+}
+
+// MigrationState describes the progress of this instance's migrations.
+type MigrationState struct {
+	State string `json:"state"`
+	// Version is the schema version last seen in the database and Latest the
+	// one this build expects.
+	Version int    `json:"version"`
+	Latest  int    `json:"latest"`
+	Error   string `json:"error,omitempty"`
+}
+
+var (
+	migrationStateMutex sync.RWMutex
+	migrationState      = MigrationState{State: MigrationPending}
+)
+
+// CurrentMigrationState returns the progress of this instance's migrations,
+// for readiness checks.
+func CurrentMigrationState() MigrationState {
+	migrationStateMutex.RLock()
+	defer migrationStateMutex.RUnlock()
+	return migrationState
+}
+
+func setMigrationState(state string, version, latest int, err error) {
+	migrationStateMutex.Lock()
+	defer migrationStateMutex.Unlock()
+	migrationState = MigrationState{State: state, Version: version, Latest: latest}
+	if err != nil {
+		migrationState.Error = err.Error()
+	}
+}
+
+// newMigrationLockOwner returns an identifier for this process as the holder
+// of the migration lock.
+func newMigrationLockOwner() string {
+	host, _ := os.Hostname()
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return fmt.Sprintf("%s:%d:%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
+
+// tryMigrationLock takes the migration lock for owner if it is free or was
+// left behind, reporting whether it did.
+func tryMigrationLock(db *sql.DB, owner string) (bool, error) {
+	now := time.Now().UTC()
+	result, err := db.Exec(`
+		INSERT INTO schema_migration_lock (id, owner, acquired_at) VALUES (1, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET owner = excluded.owner, acquired_at = excluded.acquired_at
+		WHERE schema_migration_lock.acquired_at < ?`,
+		owner, now.Format(time.RFC3339), now.Add(-migrationLockTTL).Format(time.RFC3339))
+	if err != nil {
+		return false, err
+	}
+	taken, err := result.RowsAffected()
+	return taken == 1, err
+}
+
+// acquireMigrationLock waits until owner holds the migration lock or the
+// deadline passes.
+func acquireMigrationLock(db *sql.DB, owner string, deadline time.Time) error {
+	logged := false
+	for {
+		taken, err := tryMigrationLock(db, owner)
+		if taken {
+			return nil
+		}
+		if err != nil {
+			// Another instance migrating holds the write lock on the file
+			logger.Logger.Debug("Failed to take migration lock, retrying", zap.Error(err))
+		} else if !logged {
+			var holder string
+			_ = db.QueryRow("SELECT owner FROM schema_migration_lock WHERE id = 1").Scan(&holder)
+			logger.Logger.Info("Waiting for another instance to finish migrating", zap.String("holder", holder))
+			logged = true
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for the migration lock")
+		}
+		time.Sleep(migrationPollInterval)
+	}
+}
+
+// refreshMigrationLock extends owner's hold on the migration lock within tx,
+// failing when the lock was taken over.
+func refreshMigrationLock(tx *sql.Tx, owner string) error {
+	result, err := tx.Exec("UPDATE schema_migration_lock SET acquired_at = ? WHERE id = 1 AND owner = ?",
+		time.Now().UTC().Format(time.RFC3339), owner)
+	if err != nil {
+		return fmt.Errorf("failed to refresh migration lock: %w", err)
+	}
+	if held, err := result.RowsAffected(); err != nil || held != 1 {
+		return fmt.Errorf("migration lock was taken over by another instance")
+	}
+	return nil
+}
+
+func releaseMigrationLock(db *sql.DB, owner string) {
+	if _, err := db.Exec("DELETE FROM schema_migration_lock WHERE id = 1 AND owner = ?", owner); err != nil {
+		logger.Logger.Warn("Failed to release migration lock", zap.Error(err))
+	}
+}
+
+// waitForSchema waits until another instance has migrated the schema to at
+// least latest or the deadline passes.
+func waitForSchema(db *sql.DB, latest int, deadline time.Time) error {
+	logged := false
+	for {
+		version, err := schemaVersion(db)
+		if err == nil && version >= latest {
+			setMigrationState(MigrationComplete, version, latest, nil)
+			logger.Logger.Info("Database migrations applied by another instance", zap.Int("version", version))
+			return nil
+		}
+		if err != nil {
+			logger.Logger.Debug("Failed to check schema version, retrying", zap.Error(err))
+		} else {
+			setMigrationState(MigrationWaiting, version, latest, nil)
+			if !logged {
+				logger.Logger.Info("Waiting for another instance to apply migrations",
+					zap.Int("version", version), zap.Int("latest", latest))
+				logged = true
+			}
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for schema version %d", latest)
+		}
+		time.Sleep(migrationPollInterval)
+	}
+}
+
+func schemaVersion(db *sql.DB) (int, error) {
+	var version int
+	err := db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+	return version, err
+}
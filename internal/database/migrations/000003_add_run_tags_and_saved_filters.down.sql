@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS saved_filters;
+DROP TABLE IF EXISTS run_tags;
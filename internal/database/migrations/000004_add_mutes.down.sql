@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_mutes_entity;
+DROP TABLE IF EXISTS mutes;
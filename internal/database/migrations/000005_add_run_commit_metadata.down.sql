@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_workflow_runs_head_sha;
+ALTER TABLE workflow_runs DROP COLUMN head_commit_author_email;
+ALTER TABLE workflow_runs DROP COLUMN head_commit_author;
+ALTER TABLE workflow_runs DROP COLUMN head_commit_message;
+ALTER TABLE workflow_runs DROP COLUMN head_sha;
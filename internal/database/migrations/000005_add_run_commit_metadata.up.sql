@@ -0,0 +1,7 @@
+ALTER TABLE workflow_runs ADD COLUMN head_sha TEXT NOT NULL DEFAULT '';
+ALTER TABLE workflow_runs ADD COLUMN head_commit_message TEXT NOT NULL DEFAULT '';
+ALTER TABLE workflow_runs ADD COLUMN head_commit_author TEXT NOT NULL DEFAULT '';
+ALTER TABLE workflow_runs ADD COLUMN head_commit_author_email TEXT NOT NULL DEFAULT '';
+
+-- Commit correlation: runs filtered by head_sha (exact or prefix)
+CREATE INDEX IF NOT EXISTS idx_workflow_runs_head_sha ON workflow_runs (head_sha);
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS repo_groups (
+    name TEXT PRIMARY KEY,
+    repositories TEXT NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS storage_samples (
+    table_name TEXT NOT NULL,
+    day TEXT NOT NULL,
+    row_count INTEGER NOT NULL,
+    table_bytes INTEGER NOT NULL,
+    index_bytes INTEGER NOT NULL,
+    PRIMARY KEY (table_name, day)
+);
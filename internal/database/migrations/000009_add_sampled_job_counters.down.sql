@@ -0,0 +1 @@
+DROP TABLE IF EXISTS sampled_job_counters;
@@ -0,0 +1,8 @@
+-- Successful jobs removed by sampling, counted per hour so analytics totals stay accurate
+CREATE TABLE IF NOT EXISTS sampled_job_counters (
+    hour TEXT NOT NULL,
+    repository TEXT NOT NULL,
+    name TEXT NOT NULL,
+    jobs INTEGER NOT NULL,
+    PRIMARY KEY (hour, repository, name)
+);
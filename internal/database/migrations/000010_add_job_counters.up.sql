@@ -0,0 +1,45 @@
+-- Per-day, per-repository, per-label job counters maintained on ingest, so
+-- analytics over long ranges do not scan workflow_jobs
+CREATE TABLE IF NOT EXISTS job_counters (
+    day TEXT NOT NULL,
+    repository TEXT NOT NULL,
+    label TEXT NOT NULL,
+    queued INTEGER NOT NULL DEFAULT 0,
+    started INTEGER NOT NULL DEFAULT 0,
+    completed INTEGER NOT NULL DEFAULT 0,
+    succeeded INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    cancelled INTEGER NOT NULL DEFAULT 0,
+    minutes REAL NOT NULL DEFAULT 0,
+    PRIMARY KEY (day, repository, label)
+);
+
+-- Backfill from the jobs already stored and the successes removed by sampling
+INSERT INTO job_counters (day, repository, label, queued, started, completed, succeeded, failed, cancelled, minutes)
+SELECT day, repository, label, SUM(queued), SUM(started), SUM(completed), SUM(succeeded), SUM(failed), SUM(cancelled), SUM(minutes)
+FROM (
+    SELECT date(j.created_at) AS day, COALESCE(r.repository, '') AS repository, COALESCE(json_extract(j.labels, '$[0]'), '') AS label,
+        1 AS queued, 0 AS started, 0 AS completed, 0 AS succeeded, 0 AS failed, 0 AS cancelled, 0 AS minutes
+    FROM workflow_jobs j LEFT JOIN workflow_runs r ON r.id = j.run_id
+    WHERE j.created_at IS NOT NULL
+    UNION ALL
+    SELECT date(j.started_at), COALESCE(r.repository, ''), COALESCE(json_extract(j.labels, '$[0]'), ''),
+        0, 1, 0, 0, 0, 0, 0
+    FROM workflow_jobs j LEFT JOIN workflow_runs r ON r.id = j.run_id
+    WHERE j.started_at IS NOT NULL AND j.status IN ('in_progress', 'completed')
+    UNION ALL
+    SELECT date(j.completed_at), COALESCE(r.repository, ''), COALESCE(json_extract(j.labels, '$[0]'), ''),
+        0, 0, 1,
+        CASE WHEN j.conclusion = 'success' THEN 1 ELSE 0 END,
+        CASE WHEN j.conclusion IN ('failure', 'timed_out') THEN 1 ELSE 0 END,
+        CASE WHEN j.conclusion = 'cancelled' THEN 1 ELSE 0 END,
+        CASE WHEN j.started_at IS NOT NULL AND j.completed_at > j.started_at
+            THEN (julianday(j.completed_at) - julianday(j.started_at)) * 1440 ELSE 0 END
+    FROM workflow_jobs j LEFT JOIN workflow_runs r ON r.id = j.run_id
+    WHERE j.status = 'completed' AND j.completed_at IS NOT NULL
+    UNION ALL
+    SELECT date(hour), repository, '', 0, 0, jobs, jobs, 0, 0, 0
+    FROM sampled_job_counters
+)
+WHERE day IS NOT NULL
+GROUP BY day, repository, label;
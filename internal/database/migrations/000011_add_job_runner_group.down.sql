@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_workflow_jobs_runner_group;
+ALTER TABLE workflow_jobs DROP COLUMN runner_group_name;
+ALTER TABLE workflow_jobs DROP COLUMN runner_group_id;
@@ -0,0 +1,5 @@
+-- Runner group that picked up the job; queued jobs have none yet
+ALTER TABLE workflow_jobs ADD COLUMN runner_group_id INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE workflow_jobs ADD COLUMN runner_group_name TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_workflow_jobs_runner_group ON workflow_jobs (runner_group_id, started_at);
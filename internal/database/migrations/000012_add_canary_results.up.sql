@@ -0,0 +1,13 @@
+-- Results of dispatching the synthetic canary workflow
+CREATE TABLE IF NOT EXISTS canary_results (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    dispatched_at TEXT NOT NULL,
+    outcome TEXT NOT NULL,
+    run_id INTEGER NOT NULL DEFAULT 0,
+    html_url TEXT NOT NULL DEFAULT '',
+    conclusion TEXT NOT NULL DEFAULT '',
+    duration_seconds REAL NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_canary_results_dispatched_at ON canary_results (dispatched_at);
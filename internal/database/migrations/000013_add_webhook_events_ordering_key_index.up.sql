@@ -0,0 +1,2 @@
+-- Lets sequence checks look up all events for one entity
+CREATE INDEX IF NOT EXISTS idx_webhook_events_ordering_key ON webhook_events (ordering_key, status_priority);
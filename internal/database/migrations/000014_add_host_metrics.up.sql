@@ -0,0 +1,11 @@
+-- Runner host metrics pushed via Prometheus remote write
+CREATE TABLE IF NOT EXISTS host_metrics (
+    host TEXT NOT NULL,
+    metric TEXT NOT NULL,
+    labels TEXT NOT NULL DEFAULT '',
+    timestamp TEXT NOT NULL,
+    value REAL NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_host_metrics_metric_timestamp ON host_metrics (metric, timestamp);
+CREATE INDEX IF NOT EXISTS idx_host_metrics_timestamp ON host_metrics (timestamp);
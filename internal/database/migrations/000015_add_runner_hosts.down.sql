@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS runner_hosts;
+DROP INDEX IF EXISTS idx_workflow_jobs_runner_name;
+ALTER TABLE workflow_jobs DROP COLUMN runner_name;
@@ -0,0 +1,14 @@
+-- Runner that picked up the job; queued jobs have none yet
+ALTER TABLE workflow_jobs ADD COLUMN runner_name TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_workflow_jobs_runner_name ON workflow_jobs (runner_name, started_at);
+
+-- Self-hosted runner hosts, registered manually or by heartbeat
+CREATE TABLE IF NOT EXISTS runner_hosts (
+    name TEXT PRIMARY KEY,
+    labels TEXT NOT NULL DEFAULT '[]',
+    zone TEXT NOT NULL DEFAULT '',
+    instance_type TEXT NOT NULL DEFAULT '',
+    last_seen_at TEXT,
+    updated_at TEXT NOT NULL
+);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_workflow_runs_triggering_actor;
+ALTER TABLE workflow_runs DROP COLUMN run_attempt;
+ALTER TABLE workflow_runs DROP COLUMN triggering_actor;
+ALTER TABLE workflow_runs DROP COLUMN actor;
@@ -0,0 +1,7 @@
+-- Who the run is attributed to and who started this attempt of it; they
+-- differ for re-runs and scheduled runs
+ALTER TABLE workflow_runs ADD COLUMN actor TEXT NOT NULL DEFAULT '';
+ALTER TABLE workflow_runs ADD COLUMN triggering_actor TEXT NOT NULL DEFAULT '';
+ALTER TABLE workflow_runs ADD COLUMN run_attempt INTEGER NOT NULL DEFAULT 1;
+
+CREATE INDEX IF NOT EXISTS idx_workflow_runs_triggering_actor ON workflow_runs (triggering_actor, created_at);
@@ -0,0 +1,13 @@
+-- Jobs that waited for an environment approval. waiting_at and queued_at are
+-- when the job's waiting and following queued events were received; the
+-- environment comes from deployment_review events
+CREATE TABLE IF NOT EXISTS job_approvals (
+    job_id INTEGER PRIMARY KEY,
+    run_id INTEGER NOT NULL,
+    environment TEXT NOT NULL DEFAULT '',
+    waiting_at TEXT,
+    queued_at TEXT,
+    updated_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_approvals_waiting_at ON job_approvals (waiting_at);
@@ -0,0 +1,11 @@
+-- Per-label demand and runner capacity, sampled with the metrics snapshots
+CREATE TABLE IF NOT EXISTS label_capacity_samples (
+    label TEXT NOT NULL,
+    timestamp TEXT NOT NULL,
+    running INTEGER NOT NULL DEFAULT 0,
+    queued INTEGER NOT NULL DEFAULT 0,
+    capacity INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (label, timestamp)
+);
+
+CREATE INDEX IF NOT EXISTS idx_label_capacity_samples_timestamp ON label_capacity_samples (timestamp);
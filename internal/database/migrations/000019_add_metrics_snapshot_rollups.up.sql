@@ -0,0 +1,13 @@
+-- Metrics snapshots rolled up into fixed buckets by compact-snapshots, whose
+-- raw snapshots have been deleted
+CREATE TABLE IF NOT EXISTS metrics_snapshot_rollups (
+    bucket_start TEXT NOT NULL,
+    bucket_seconds INTEGER NOT NULL,
+    samples INTEGER NOT NULL,
+    avg_running REAL NOT NULL DEFAULT 0,
+    avg_queued REAL NOT NULL DEFAULT 0,
+    max_running INTEGER NOT NULL DEFAULT 0,
+    max_queued INTEGER NOT NULL DEFAULT 0,
+    max_demand INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (bucket_start, bucket_seconds)
+);
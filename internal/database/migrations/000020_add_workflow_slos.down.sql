@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_workflow_runs_repository_name;
+DROP TABLE IF EXISTS workflow_slos;
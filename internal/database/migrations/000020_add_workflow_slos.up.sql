@@ -0,0 +1,13 @@
+-- Workflow SLOs managed through the API; those in WORKFLOW_SLOS stay in config
+CREATE TABLE IF NOT EXISTS workflow_slos (
+    name TEXT PRIMARY KEY,
+    repository TEXT NOT NULL,
+    workflow TEXT NOT NULL,
+    success_target REAL NOT NULL DEFAULT 0,
+    duration_percentile INTEGER NOT NULL DEFAULT 0,
+    duration_target_seconds INTEGER NOT NULL DEFAULT 0,
+    window_days INTEGER NOT NULL
+);
+
+-- Lets SLO checks find one workflow's recent runs
+CREATE INDEX IF NOT EXISTS idx_workflow_runs_repository_name ON workflow_runs (repository, name, updated_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_workflow_file_changes_committed_at;
+DROP TABLE IF EXISTS workflow_file_changes;
+ALTER TABLE workflow_runs DROP COLUMN workflow_path;
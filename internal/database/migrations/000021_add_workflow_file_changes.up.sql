@@ -0,0 +1,16 @@
+ALTER TABLE workflow_runs ADD COLUMN workflow_path TEXT NOT NULL DEFAULT '';
+
+-- Commits that changed a workflow file, fetched from the GitHub API to
+-- correlate failure rate shifts with workflow changes
+CREATE TABLE IF NOT EXISTS workflow_file_changes (
+    repository TEXT NOT NULL,
+    path TEXT NOT NULL,
+    sha TEXT NOT NULL,
+    message TEXT NOT NULL DEFAULT '',
+    author TEXT NOT NULL DEFAULT '',
+    html_url TEXT NOT NULL DEFAULT '',
+    committed_at TEXT NOT NULL,
+    PRIMARY KEY (repository, path, sha)
+);
+
+CREATE INDEX IF NOT EXISTS idx_workflow_file_changes_committed_at ON workflow_file_changes (committed_at);
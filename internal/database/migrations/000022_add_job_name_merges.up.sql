@@ -0,0 +1,9 @@
+-- Reviewed decisions on merging renamed jobs in analytics: a confirmed row
+-- groups alias under canonical, a rejected one stops the pair being proposed
+CREATE TABLE IF NOT EXISTS job_name_merges (
+    alias TEXT NOT NULL,
+    canonical TEXT NOT NULL,
+    status TEXT NOT NULL,
+    decided_at TEXT NOT NULL,
+    PRIMARY KEY (alias, canonical)
+);
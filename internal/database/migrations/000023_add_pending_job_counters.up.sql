@@ -0,0 +1,16 @@
+-- Counters of jobs seen before their run, keyed by run until the run and its
+-- repository are known. They are also counted in job_counters without a
+-- repository, and moved to the run's repository when it arrives
+CREATE TABLE IF NOT EXISTS pending_job_counters (
+    run_id INTEGER NOT NULL,
+    day TEXT NOT NULL,
+    label TEXT NOT NULL,
+    queued INTEGER NOT NULL DEFAULT 0,
+    started INTEGER NOT NULL DEFAULT 0,
+    completed INTEGER NOT NULL DEFAULT 0,
+    succeeded INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    cancelled INTEGER NOT NULL DEFAULT 0,
+    minutes REAL NOT NULL DEFAULT 0,
+    PRIMARY KEY (run_id, day, label)
+);
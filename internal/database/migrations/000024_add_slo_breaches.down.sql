@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_slo_breaches_started_at;
+DROP TABLE IF EXISTS slo_breaches;
@@ -0,0 +1,13 @@
+-- Breaches of a workflow SLO's error budget or of the processing lag SLO,
+-- recorded when their alert is raised so the activity feed can list them
+CREATE TABLE IF NOT EXISTS slo_breaches (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    kind TEXT NOT NULL,
+    title TEXT NOT NULL,
+    message TEXT NOT NULL,
+    repository TEXT NOT NULL DEFAULT '',
+    workflow TEXT NOT NULL DEFAULT '',
+    started_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_slo_breaches_started_at ON slo_breaches (started_at);
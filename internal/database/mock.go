@@ -12,8 +12,13 @@ type MockDatabase struct {
 	mock.Mock
 }
 
-func (m *MockDatabase) GetWorkflowRunsPaginated(ctx context.Context, page int, limit int, repo string, status string) ([]models.WorkflowRun, int, error) {
-	args := m.Called(ctx, page, limit, repo)
+// ReadSnapshot runs fn against the mock itself.
+func (m *MockDatabase) ReadSnapshot(ctx context.Context, fn func(DatabaseInterface) error) error {
+	return fn(m)
+}
+
+func (m *MockDatabase) GetWorkflowRunsPaginated(ctx context.Context, page int, limit int, repos []string, status string, sha string) ([]models.WorkflowRun, int, error) {
+	args := m.Called(ctx, page, limit, repos, status, sha)
 	return args.Get(0).([]models.WorkflowRun), args.Int(1), args.Error(2)
 }
 
@@ -32,6 +37,11 @@ func (m *MockDatabase) GetWorkflowJobsByRunID(ctx context.Context, runID int64)
 	return args.Get(0).([]models.WorkflowJob), args.Error(1)
 }
 
+func (m *MockDatabase) GetWorkflowJobsByRunIDs(ctx context.Context, runIDs []int64) (map[int64][]models.WorkflowJob, error) {
+	args := m.Called(ctx, runIDs)
+	return args.Get(0).(map[int64][]models.WorkflowJob), args.Error(1)
+}
+
 func (m *MockDatabase) CleanupOldData(ctx context.Context, retentionPeriod time.Duration) (int64, int64, int64, error) {
 	args := m.Called(ctx, retentionPeriod)
 	return args.Get(0).(int64), args.Get(1).(int64), args.Get(2).(int64), args.Error(3)
@@ -62,11 +72,47 @@ func (m *MockDatabase) MarkEventProcessed(ctx context.Context, deliveryID string
 	return args.Error(0)
 }
 
+func (m *MockDatabase) GetProcessingLagStats(ctx context.Context, since time.Duration) (*models.ProcessingLagStats, error) {
+	args := m.Called(ctx, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ProcessingLagStats), args.Error(1)
+}
+
+func (m *MockDatabase) PruneSampledJobs(ctx context.Context, keepPercent int, olderThan time.Duration) (int64, error) {
+	args := m.Called(ctx, keepPercent, olderThan)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockDatabase) RecordStorageSample(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockDatabase) GetStorageReport(ctx context.Context) (*models.StorageReport, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.StorageReport), args.Error(1)
+}
+
 func (m *MockDatabase) MarkEventFailed(ctx context.Context, deliveryID string) error {
 	args := m.Called(ctx, deliveryID)
 	return args.Error(0)
 }
 
+func (m *MockDatabase) PurgeWebhookEvents(ctx context.Context, status string, before time.Time, dryRun bool) (int64, error) {
+	args := m.Called(ctx, status, before, dryRun)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockDatabase) GetEventKeyCounts(ctx context.Context, since, bucket time.Duration) ([]models.EventKeyCount, error) {
+	args := m.Called(ctx, since, bucket)
+	return args.Get(0).([]models.EventKeyCount), args.Error(1)
+}
+
 func (m *MockDatabase) GetCurrentJobCounts(ctx context.Context) (int, int, error) {
 	args := m.Called(ctx)
 	return args.Int(0), args.Int(1), args.Error(2)
@@ -87,26 +133,51 @@ func (m *MockDatabase) GetMetricsSummary(ctx context.Context, since time.Duratio
 	return args.Get(0).(map[string]float64), args.Error(1)
 }
 
-func (m *MockDatabase) GetFailureAnalytics(ctx context.Context, since time.Duration, repo string) (*models.FailureAnalytics, error) {
-	args := m.Called(ctx, since, repo)
+func (m *MockDatabase) GetFailureAnalytics(ctx context.Context, since time.Duration, repos []string) (*models.FailureAnalytics, error) {
+	args := m.Called(ctx, since, repos)
+	return args.Get(0).(*models.FailureAnalytics), args.Error(1)
+}
+
+func (m *MockDatabase) GetFailureSummary(ctx context.Context, since time.Duration, repos []string) (*models.FailureAnalytics, error) {
+	args := m.Called(ctx, since, repos)
 	return args.Get(0).(*models.FailureAnalytics), args.Error(1)
 }
 
-func (m *MockDatabase) GetFailureTrend(ctx context.Context, since time.Duration, repo string) ([]models.FailureTrendPoint, error) {
-	args := m.Called(ctx, since, repo)
+func (m *MockDatabase) GetFailureTrend(ctx context.Context, since time.Duration, repos []string) ([]models.FailureTrendPoint, error) {
+	args := m.Called(ctx, since, repos)
 	return args.Get(0).([]models.FailureTrendPoint), args.Error(1)
 }
 
-func (m *MockDatabase) GetLabelDemandSummary(ctx context.Context, since time.Duration, repo string) ([]models.LabelDemandSummary, error) {
-	args := m.Called(ctx, since, repo)
+func (m *MockDatabase) GetLabelDemandSummary(ctx context.Context, since time.Duration, repos []string) ([]models.LabelDemandSummary, error) {
+	args := m.Called(ctx, since, repos)
 	return args.Get(0).([]models.LabelDemandSummary), args.Error(1)
 }
 
-func (m *MockDatabase) GetLabelDemandTrend(ctx context.Context, since time.Duration, repo string) ([]models.LabelDemandTrendPoint, error) {
-	args := m.Called(ctx, since, repo)
+func (m *MockDatabase) GetLabelDemandTrend(ctx context.Context, since time.Duration, repos []string) ([]models.LabelDemandTrendPoint, error) {
+	args := m.Called(ctx, since, repos)
 	return args.Get(0).([]models.LabelDemandTrendPoint), args.Error(1)
 }
 
+func (m *MockDatabase) GetLabelQueueStats(ctx context.Context, from, to time.Time, repos []string) ([]models.LabelQueueStats, error) {
+	args := m.Called(ctx, from, to, repos)
+	return args.Get(0).([]models.LabelQueueStats), args.Error(1)
+}
+
+func (m *MockDatabase) GetJobQueueWaits(ctx context.Context, from, to time.Time, repos []string) ([]models.JobQueueWait, error) {
+	args := m.Called(ctx, from, to, repos)
+	return args.Get(0).([]models.JobQueueWait), args.Error(1)
+}
+
+func (m *MockDatabase) GetLabelThroughput(ctx context.Context, from, to time.Time, repos []string) ([]models.LabelThroughput, error) {
+	args := m.Called(ctx, from, to, repos)
+	return args.Get(0).([]models.LabelThroughput), args.Error(1)
+}
+
+func (m *MockDatabase) GetJobThroughput(ctx context.Context, from, to time.Time, repos []string) (models.LabelThroughput, error) {
+	args := m.Called(ctx, from, to, repos)
+	return args.Get(0).(models.LabelThroughput), args.Error(1)
+}
+
 func (m *MockDatabase) GetCurrentJobCountsByLabel(ctx context.Context) ([]LabelJobCount, error) {
 	args := m.Called(ctx)
 	return args.Get(0).([]LabelJobCount), args.Error(1)
@@ -117,7 +188,340 @@ func (m *MockDatabase) GetRepositories(ctx context.Context) ([]string, error) {
 	return args.Get(0).([]string), args.Error(1)
 }
 
+func (m *MockDatabase) PruneData(ctx context.Context, filter models.PruneFilter, dryRun bool) (models.PruneResult, error) {
+	args := m.Called(ctx, filter, dryRun)
+	return args.Get(0).(models.PruneResult), args.Error(1)
+}
+
+func (m *MockDatabase) CompactMetricsSnapshots(ctx context.Context, before time.Time, bucket time.Duration, dryRun bool) (models.SnapshotCompaction, error) {
+	args := m.Called(ctx, before, bucket, dryRun)
+	return args.Get(0).(models.SnapshotCompaction), args.Error(1)
+}
+
 func (m *MockDatabase) CleanupStaleJobs(ctx context.Context, threshold time.Duration) (int64, error) {
 	args := m.Called(ctx, threshold)
 	return args.Get(0).(int64), args.Error(1)
 }
+
+func (m *MockDatabase) GetLatestWorkflowRun(ctx context.Context, repo string, workflow string) (*models.WorkflowRun, error) {
+	args := m.Called(ctx, repo, workflow)
+	return args.Get(0).(*models.WorkflowRun), args.Error(1)
+}
+
+func (m *MockDatabase) GetNotableRuns(ctx context.Context, since time.Duration, longRunning time.Duration, workflowFilters []string, limit int) ([]models.NotableRun, error) {
+	args := m.Called(ctx, since, longRunning, workflowFilters, limit)
+	return args.Get(0).([]models.NotableRun), args.Error(1)
+}
+
+func (m *MockDatabase) AddRunTag(ctx context.Context, runID int64, tag string) (bool, error) {
+	args := m.Called(ctx, runID, tag)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockDatabase) RemoveRunTag(ctx context.Context, runID int64, tag string) (bool, error) {
+	args := m.Called(ctx, runID, tag)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockDatabase) GetRunTags(ctx context.Context, runID int64) ([]string, error) {
+	args := m.Called(ctx, runID)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockDatabase) GetSavedFilters(ctx context.Context) ([]models.SavedFilter, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.SavedFilter), args.Error(1)
+}
+
+func (m *MockDatabase) SaveFilter(ctx context.Context, filter models.SavedFilter) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockDatabase) DeleteSavedFilter(ctx context.Context, id int64) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockDatabase) GetRepoGroups(ctx context.Context) ([]models.RepoGroup, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.RepoGroup), args.Error(1)
+}
+
+func (m *MockDatabase) SaveRepoGroup(ctx context.Context, group models.RepoGroup) error {
+	args := m.Called(ctx, group)
+	return args.Error(0)
+}
+
+func (m *MockDatabase) DeleteRepoGroup(ctx context.Context, name string) (bool, error) {
+	args := m.Called(ctx, name)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockDatabase) GetWorkflowSLOs(ctx context.Context) ([]models.WorkflowSLO, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.WorkflowSLO), args.Error(1)
+}
+
+func (m *MockDatabase) SaveWorkflowSLO(ctx context.Context, slo models.WorkflowSLO) error {
+	args := m.Called(ctx, slo)
+	return args.Error(0)
+}
+
+func (m *MockDatabase) DeleteWorkflowSLO(ctx context.Context, name string) (bool, error) {
+	args := m.Called(ctx, name)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockDatabase) GetJobNameCounts(ctx context.Context, since time.Duration, repos []string, limit int) ([]models.JobNameCount, error) {
+	args := m.Called(ctx, since, repos, limit)
+	return args.Get(0).([]models.JobNameCount), args.Error(1)
+}
+
+func (m *MockDatabase) GetJobNameMerges(ctx context.Context) ([]models.JobNameMerge, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.JobNameMerge), args.Error(1)
+}
+
+func (m *MockDatabase) SaveJobNameMerge(ctx context.Context, merge models.JobNameMerge) error {
+	args := m.Called(ctx, merge)
+	return args.Error(0)
+}
+
+func (m *MockDatabase) DeleteJobNameMerge(ctx context.Context, alias, canonical string) (bool, error) {
+	args := m.Called(ctx, alias, canonical)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockDatabase) GetSLORuns(ctx context.Context, repository, workflow string, since time.Duration) ([]models.SLORun, error) {
+	args := m.Called(ctx, repository, workflow, since)
+	return args.Get(0).([]models.SLORun), args.Error(1)
+}
+
+func (m *MockDatabase) GetWorkflowFiles(ctx context.Context, since time.Duration) ([]models.WorkflowFile, error) {
+	args := m.Called(ctx, since)
+	return args.Get(0).([]models.WorkflowFile), args.Error(1)
+}
+
+func (m *MockDatabase) SaveWorkflowFileChanges(ctx context.Context, changes []models.WorkflowFileChange) error {
+	args := m.Called(ctx, changes)
+	return args.Error(0)
+}
+
+func (m *MockDatabase) GetWorkflowFileChanges(ctx context.Context, since time.Duration, repos []string) ([]models.WorkflowFileChange, error) {
+	args := m.Called(ctx, since, repos)
+	return args.Get(0).([]models.WorkflowFileChange), args.Error(1)
+}
+
+func (m *MockDatabase) GetRunOutcomes(ctx context.Context, since time.Duration, repos []string) ([]models.RunOutcome, error) {
+	args := m.Called(ctx, since, repos)
+	return args.Get(0).([]models.RunOutcome), args.Error(1)
+}
+
+func (m *MockDatabase) CreateMute(ctx context.Context, mute models.Mute) (int64, error) {
+	args := m.Called(ctx, mute)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockDatabase) GetActiveMutes(ctx context.Context) ([]models.Mute, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.Mute), args.Error(1)
+}
+
+func (m *MockDatabase) DeleteMute(ctx context.Context, id int64) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockDatabase) IsMuted(ctx context.Context, runID int64, jobID int64) (bool, error) {
+	args := m.Called(ctx, runID, jobID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockDatabase) GetWorkflowRunByID(ctx context.Context, runID int64) (*models.WorkflowRun, error) {
+	args := m.Called(ctx, runID)
+	return args.Get(0).(*models.WorkflowRun), args.Error(1)
+}
+
+func (m *MockDatabase) GetJobDurationStats(ctx context.Context, runID int64) (map[string]models.DurationStats, error) {
+	args := m.Called(ctx, runID)
+	return args.Get(0).(map[string]models.DurationStats), args.Error(1)
+}
+
+func (m *MockDatabase) GetRunDurations(ctx context.Context, since time.Duration, repos []string) ([]models.RunDuration, error) {
+	args := m.Called(ctx, since, repos)
+	return args.Get(0).([]models.RunDuration), args.Error(1)
+}
+
+func (m *MockDatabase) GetUnschedulableJobs(ctx context.Context, queuedFor time.Duration, repos []string) ([]models.UnschedulableJob, error) {
+	args := m.Called(ctx, queuedFor, repos)
+	return args.Get(0).([]models.UnschedulableJob), args.Error(1)
+}
+
+func (m *MockDatabase) GetRunnerPoolStatus(ctx context.Context) ([]models.RunnerPoolStatus, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.RunnerPoolStatus), args.Error(1)
+}
+
+func (m *MockDatabase) GetRunnerGroupStats(ctx context.Context, since time.Duration) ([]models.RunnerGroupStats, error) {
+	args := m.Called(ctx, since)
+	return args.Get(0).([]models.RunnerGroupStats), args.Error(1)
+}
+
+func (m *MockDatabase) GetRunnerHosts(ctx context.Context) ([]models.RunnerHost, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.RunnerHost), args.Error(1)
+}
+
+func (m *MockDatabase) SaveRunnerHost(ctx context.Context, host models.RunnerHost) error {
+	args := m.Called(ctx, host)
+	return args.Error(0)
+}
+
+func (m *MockDatabase) RecordRunnerHeartbeat(ctx context.Context, host models.RunnerHost) error {
+	args := m.Called(ctx, host)
+	return args.Error(0)
+}
+
+func (m *MockDatabase) DeleteRunnerHost(ctx context.Context, name string) (bool, error) {
+	args := m.Called(ctx, name)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockDatabase) GetRunnerHostStats(ctx context.Context, since time.Duration, by string) ([]models.RunnerHostStats, error) {
+	args := m.Called(ctx, since, by)
+	return args.Get(0).([]models.RunnerHostStats), args.Error(1)
+}
+
+func (m *MockDatabase) GetActorStats(ctx context.Context, since time.Duration, repos []string, limit int) ([]models.ActorStats, error) {
+	args := m.Called(ctx, since, repos, limit)
+	return args.Get(0).([]models.ActorStats), args.Error(1)
+}
+
+func (m *MockDatabase) RecordJobWaiting(ctx context.Context, jobID, runID int64, at time.Time) error {
+	args := m.Called(ctx, jobID, runID, at)
+	return args.Error(0)
+}
+
+func (m *MockDatabase) RecordJobApproved(ctx context.Context, jobID int64, at time.Time) error {
+	args := m.Called(ctx, jobID, at)
+	return args.Error(0)
+}
+
+func (m *MockDatabase) SetJobEnvironments(ctx context.Context, runID int64, jobs []models.EnvironmentJob) error {
+	args := m.Called(ctx, runID, jobs)
+	return args.Error(0)
+}
+
+func (m *MockDatabase) GetApprovalStats(ctx context.Context, since time.Duration, repos []string) ([]models.ApprovalStats, error) {
+	args := m.Called(ctx, since, repos)
+	return args.Get(0).([]models.ApprovalStats), args.Error(1)
+}
+
+func (m *MockDatabase) GetJobTimings(ctx context.Context, since time.Duration, label string) ([]models.JobTiming, error) {
+	args := m.Called(ctx, since, label)
+	return args.Get(0).([]models.JobTiming), args.Error(1)
+}
+
+func (m *MockDatabase) HasOtherPendingEvents(ctx context.Context, orderingKey string, deliveryID string) (bool, error) {
+	args := m.Called(ctx, orderingKey, deliveryID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockDatabase) ClaimPendingEvent(ctx context.Context, deliveryID string) (bool, error) {
+	args := m.Called(ctx, deliveryID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockDatabase) ReleaseClaimedEvents(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockDatabase) GetSchemaVersion(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockDatabase) CheckWritable(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *MockDatabase) GetEventSamples(ctx context.Context, limit int) ([]models.EventSample, error) {
+	args := m.Called(ctx, limit)
+	return args.Get(0).([]models.EventSample), args.Error(1)
+}
+
+func (m *MockDatabase) SaveCanaryResult(ctx context.Context, result models.CanaryResult) error {
+	args := m.Called(ctx, result)
+	return args.Error(0)
+}
+
+func (m *MockDatabase) GetCanaryResults(ctx context.Context, limit int) ([]models.CanaryResult, error) {
+	args := m.Called(ctx, limit)
+	return args.Get(0).([]models.CanaryResult), args.Error(1)
+}
+
+func (m *MockDatabase) RecordSLOBreach(ctx context.Context, breach models.SLOBreach) error {
+	args := m.Called(ctx, breach)
+	return args.Error(0)
+}
+
+func (m *MockDatabase) GetSLOBreaches(ctx context.Context, since time.Duration, limit int) ([]models.SLOBreach, error) {
+	args := m.Called(ctx, since, limit)
+	return args.Get(0).([]models.SLOBreach), args.Error(1)
+}
+
+func (m *MockDatabase) GetEventStatusSequences(ctx context.Context, since time.Duration) ([]models.EventStatusSequence, error) {
+	args := m.Called(ctx, since)
+	return args.Get(0).([]models.EventStatusSequence), args.Error(1)
+}
+
+func (m *MockDatabase) SaveHostMetricSamples(ctx context.Context, samples []models.HostMetricSample) error {
+	args := m.Called(ctx, samples)
+	return args.Error(0)
+}
+
+func (m *MockDatabase) GetHostMetricSeries(ctx context.Context, metric string, since time.Duration) ([]models.HostMetricSeries, error) {
+	args := m.Called(ctx, metric, since)
+	return args.Get(0).([]models.HostMetricSeries), args.Error(1)
+}
+
+func (m *MockDatabase) GetRunnerCapacityByLabel(ctx context.Context, seenWithin time.Duration) (map[string]int, error) {
+	args := m.Called(ctx, seenWithin)
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+func (m *MockDatabase) GetCurrentLabelDemand(ctx context.Context) ([]LabelJobCount, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]LabelJobCount), args.Error(1)
+}
+
+func (m *MockDatabase) SaveLabelCapacitySamples(ctx context.Context, samples []models.LabelCapacitySample) error {
+	args := m.Called(ctx, samples)
+	return args.Error(0)
+}
+
+func (m *MockDatabase) GetLabelCapacitySamples(ctx context.Context, since time.Duration, label string) ([]models.LabelCapacitySample, error) {
+	args := m.Called(ctx, since, label)
+	return args.Get(0).([]models.LabelCapacitySample), args.Error(1)
+}
+
+func (m *MockDatabase) GetSettings(ctx context.Context) (map[string]string, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(map[string]string), args.Error(1)
+}
+
+func (m *MockDatabase) SaveSettings(ctx context.Context, settings map[string]string) error {
+	args := m.Called(ctx, settings)
+	return args.Error(0)
+}
+
+func (m *MockDatabase) GetSparklines(ctx context.Context, start time.Time, bucket time.Duration, points int) (*models.Sparklines, error) {
+	args := m.Called(ctx, start, bucket, points)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Sparklines), args.Error(1)
+}
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+)
+
+// mutedJobCondition matches jobs in alias j that are muted directly or through
+// their run. Its placeholder takes the current time, see mutedAt.
+const mutedJobCondition = `EXISTS (SELECT 1 FROM mutes m
+	WHERE m.muted_until > ?
+	AND ((m.entity_type = 'job' AND m.entity_id = j.id) OR (m.entity_type = 'run' AND m.entity_id = j.run_id)))`
+
+// mutedAt returns the argument of mutedJobCondition: the current time in the
+// format muted_until is stored in.
+func (db *DBWrapper) mutedAt() string {
+	return db.clock.Now().UTC().Format(time.RFC3339)
+}
+
+// CreateMute stores a mute and returns its ID.
+func (db *DBWrapper) CreateMute(ctx context.Context, mute models.Mute) (int64, error) {
+	result, err := db.db.ExecContext(ctx,
+		"INSERT INTO mutes (entity_type, entity_id, reason, muted_until) VALUES (?, ?, ?, ?)",
+		mute.EntityType, mute.EntityID, mute.Reason, mute.MutedUntil.UTC().Format(time.RFC3339))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create mute: %w", err)
+	}
+	return result.LastInsertId()
+}
+
+// GetActiveMutes returns the mutes that have not expired yet.
+func (db *DBWrapper) GetActiveMutes(ctx context.Context) ([]models.Mute, error) {
+	rows, err := db.db.QueryContext(ctx, `
+		SELECT id, entity_type, entity_id, reason, muted_until, created_at
+		FROM mutes
+		WHERE muted_until > ?
+		ORDER BY muted_until ASC`, db.clock.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mutes: %w", err)
+	}
+	defer rows.Close()
+
+	mutes := []models.Mute{}
+	for rows.Next() {
+		var m models.Mute
+		var mutedUntil, createdAt string
+		if err := rows.Scan(&m.ID, &m.EntityType, &m.EntityID, &m.Reason, &mutedUntil, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan mute: %w", err)
+		}
+		m.MutedUntil = parseTime(mutedUntil)
+		m.CreatedAt = parseTime(createdAt)
+		mutes = append(mutes, m)
+	}
+	return mutes, rows.Err()
+}
+
+// DeleteMute lifts a mute before it expires. Returns false when it did not exist.
+func (db *DBWrapper) DeleteMute(ctx context.Context, id int64) (bool, error) {
+	result, err := db.db.ExecContext(ctx, "DELETE FROM mutes WHERE id = ?", id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete mute: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// IsMuted reports whether a run, or a job of that run, is currently muted.
+// Pass jobID 0 to check the run only.
+func (db *DBWrapper) IsMuted(ctx context.Context, runID int64, jobID int64) (bool, error) {
+	var muted bool
+	err := db.db.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM mutes
+			WHERE muted_until > ?
+			AND ((entity_type = 'run' AND entity_id = ?) OR (entity_type = 'job' AND entity_id = ?)))`,
+		db.clock.Now().UTC().Format(time.RFC3339), runID, jobID).Scan(&muted)
+	if err != nil {
+		return false, fmt.Errorf("failed to check mute: %w", err)
+	}
+	return muted, nil
+}
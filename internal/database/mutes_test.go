@@ -0,0 +1,48 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/clock"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetFailureAnalytics_MutesFollowClock(t *testing.T) {
+	db := newTestDB(t, "")
+	ctx := context.Background()
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(now)
+	db.clock = fake
+
+	job := models.WorkflowJob{
+		ID: 1, RunID: 7, Name: "build", Status: models.JobStatusCompleted, Conclusion: "failure",
+		CreatedAt: now.Add(-time.Hour), StartedAt: now.Add(-50 * time.Minute), CompletedAt: now.Add(-40 * time.Minute),
+	}
+	_, err := db.AddOrUpdateJob(ctx, job, now)
+	require.NoError(t, err)
+	_, err = db.CreateMute(ctx, models.Mute{EntityType: "job", EntityID: 1, Reason: "flaky", MutedUntil: now.Add(time.Hour)})
+	require.NoError(t, err)
+
+	analytics, err := db.GetFailureAnalytics(ctx, 24*time.Hour, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, analytics.TotalFailed)
+	assert.Equal(t, 1, analytics.TotalMuted, "the mute is active at the clock's time, not the wall clock's")
+	require.Len(t, analytics.TopFailingJobs, 1)
+	assert.Equal(t, 0, analytics.TopFailingJobs[0].Failures)
+
+	summary, err := db.GetFailureSummary(ctx, 24*time.Hour, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.TotalMuted)
+	assert.Equal(t, analytics.TotalCompleted, summary.TotalCompleted)
+	assert.Empty(t, summary.TopFailingJobs, "the summary skips the jobs query")
+
+	fake.Advance(2 * time.Hour)
+	analytics, err = db.GetFailureAnalytics(ctx, 24*time.Hour, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, analytics.TotalFailed, "the mute expired")
+	assert.Equal(t, 0, analytics.TotalMuted)
+}
@@ -0,0 +1,66 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/utils"
+	"github.com/gateixeira/live-actions/models"
+)
+
+// GetProcessingLagStats returns percentiles of the delay between receiving
+// and processing webhook events processed within the window, along with the
+// backlog of events still pending.
+func (db *DBWrapper) GetProcessingLagStats(ctx context.Context, since time.Duration) (*models.ProcessingLagStats, error) {
+	now := db.clock.Now()
+	cutoff := now.Add(-since).Format(time.RFC3339)
+
+	rows, err := db.db.QueryContext(ctx, `
+		SELECT (julianday(processed_at) - julianday(received_at)) * 86400
+		FROM webhook_events
+		WHERE status = 'processed' AND processed_at >= ?`, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get processing lag: %w", err)
+	}
+	defer rows.Close()
+
+	var lags []float64
+	for rows.Next() {
+		var lag sql.NullFloat64
+		if err := rows.Scan(&lag); err != nil {
+			return nil, fmt.Errorf("failed to scan processing lag: %w", err)
+		}
+		if lag.Valid {
+			lags = append(lags, max(lag.Float64, 0))
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sort.Float64s(lags)
+
+	stats := &models.ProcessingLagStats{
+		Samples: len(lags),
+		P50:     utils.Percentile(lags, 50),
+		P95:     utils.Percentile(lags, 95),
+		P99:     utils.Percentile(lags, 99),
+	}
+	if len(lags) > 0 {
+		stats.Max = lags[len(lags)-1]
+	}
+
+	var oldest sql.NullString
+	err = db.db.QueryRowContext(ctx,
+		"SELECT COUNT(*), MIN(received_at) FROM webhook_events WHERE status = 'pending'").Scan(&stats.Pending, &oldest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending events: %w", err)
+	}
+	if oldest.Valid {
+		stats.OldestPendingSeconds = max(now.Sub(parseTime(oldest.String)).Seconds(), 0)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,184 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/utils"
+	"github.com/gateixeira/live-actions/models"
+)
+
+// PruneData deletes the runs, jobs, webhook events and counters matched by
+// filter, for removing data such as one repository's load test without
+// waiting for the retention period. Runs match by repository and creation
+// time and their jobs, events and job approvals go with them; without a
+// repository, jobs and events match by time alone as in CleanupOldData. Daily
+// job counters are removed for whole days before filter.Before; counters of
+// jobs seen before their run go with the run, or by day without a
+// repository. A repository name shared by several owners is refused, as its
+// counters cannot be told apart. On a dry run the deletes are rolled back, so
+// the result counts what would be removed.
+func (db *DBWrapper) PruneData(ctx context.Context, filter models.PruneFilter, dryRun bool) (models.PruneResult, error) {
+	var result models.PruneResult
+	if filter.Repository == "" && filter.Before.IsZero() {
+		return result, errors.New("prune needs a repository or a cutoff time")
+	}
+
+	var runConditions []string
+	var runArgs []interface{}
+	var counterConditions []string
+	var counterArgs []interface{}
+	var sampledConditions []string
+	var sampledArgs []interface{}
+	if filter.Repository != "" {
+		runConditions = append(runConditions, "repository = ?")
+		runArgs = append(runArgs, filter.Repository)
+		if filter.Owner != "" {
+			runConditions = append(runConditions, `html_url LIKE ? ESCAPE '\'`)
+			runArgs = append(runArgs, "%/"+escapeLike(filter.Owner+"/"+filter.Repository)+"/actions/%")
+		}
+		counterConditions = append(counterConditions, "repository = ?")
+		counterArgs = append(counterArgs, filter.Repository)
+		sampledConditions = append(sampledConditions, "repository = ?")
+		sampledArgs = append(sampledArgs, filter.Repository)
+	}
+	var before string
+	if !filter.Before.IsZero() {
+		before = filter.Before.UTC().Format(time.RFC3339)
+		runConditions = append(runConditions, "created_at < ?")
+		runArgs = append(runArgs, before)
+		counterConditions = append(counterConditions, "day < ?")
+		counterArgs = append(counterArgs, counterDay(filter.Before))
+		sampledConditions = append(sampledConditions, "hour < ?")
+		sampledArgs = append(sampledArgs, before)
+	}
+	runWhere := strings.Join(runConditions, " AND ")
+
+	jobWhere, jobArgs := "run_id IN (SELECT id FROM workflow_runs WHERE "+runWhere+")", runArgs
+	eventWhere := `status = 'processed' AND ordering_key IN (
+		SELECT 'job_' || id FROM workflow_jobs WHERE ` + jobWhere + `
+		UNION ALL
+		SELECT 'run_' || id FROM workflow_runs WHERE ` + runWhere + `)`
+	eventArgs := append(append([]interface{}{}, jobArgs...), runArgs...)
+	// Counters of jobs seen before their run are kept by run until it
+	// arrives; they are also in job_counters, so they are not counted again
+	pendingWhere := "run_id IN (SELECT id FROM workflow_runs WHERE " + runWhere + ")"
+	pendingArgs := runArgs
+	if filter.Repository == "" {
+		jobWhere, jobArgs = "created_at < ?", []interface{}{before}
+		eventWhere, eventArgs = "processed_at < ?", []interface{}{before}
+		// Their unattributed job_counters days go too, so a run arriving
+		// later must not move counts out of them
+		pendingWhere += " OR day < ?"
+		pendingArgs = append(append([]interface{}{}, runArgs...), counterDay(filter.Before))
+	}
+
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return result, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if filter.Repository != "" {
+		if err := checkPruneOwners(ctx, tx, filter); err != nil {
+			return result, err
+		}
+	}
+
+	deletes := []struct {
+		what  string
+		query string
+		args  []interface{}
+		count *int64
+	}{
+		// Events, jobs, approvals and pending counters are matched through their runs, so they go first
+		{"webhook events", "DELETE FROM webhook_events WHERE " + eventWhere, eventArgs, &result.WebhookEvents},
+		{"workflow jobs", "DELETE FROM workflow_jobs WHERE " + jobWhere, jobArgs, &result.Jobs},
+		{"job approvals", "DELETE FROM job_approvals WHERE run_id IN (SELECT id FROM workflow_runs WHERE " + runWhere + ")", runArgs, nil},
+		{"pending job counters", "DELETE FROM pending_job_counters WHERE " + pendingWhere, pendingArgs, nil},
+		{"workflow runs", "DELETE FROM workflow_runs WHERE " + runWhere, runArgs, &result.Runs},
+		{"job counters", "DELETE FROM job_counters WHERE " + strings.Join(counterConditions, " AND "), counterArgs, &result.Counters},
+		{"sampled job counters", "DELETE FROM sampled_job_counters WHERE " + strings.Join(sampledConditions, " AND "), sampledArgs, &result.Counters},
+		{"orphaned run tags", "DELETE FROM run_tags WHERE run_id NOT IN (SELECT id FROM workflow_runs)", nil, nil},
+	}
+	for _, d := range deletes {
+		res, err := tx.ExecContext(ctx, d.query, d.args...)
+		if err != nil {
+			return models.PruneResult{}, fmt.Errorf("failed to delete %s: %w", d.what, err)
+		}
+		if d.count == nil {
+			continue
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return models.PruneResult{}, fmt.Errorf("failed to get affected %s count: %w", d.what, err)
+		}
+		*d.count += n
+	}
+
+	if dryRun {
+		return result, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return models.PruneResult{}, fmt.Errorf("failed to commit prune transaction: %w", err)
+	}
+	committed = true
+	return result, nil
+}
+
+// checkPruneOwners refuses to prune a repository name that runs of another
+// owner share: daily counters are kept per repository name, so pruning them
+// would remove the other owner's history too.
+func checkPruneOwners(ctx context.Context, tx *sql.Tx, filter models.PruneFilter) error {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT DISTINCT substr(html_url, 1, instr(html_url, '/' || repository || '/actions/'))
+		FROM workflow_runs WHERE repository = ?`, filter.Repository)
+	if err != nil {
+		return fmt.Errorf("failed to get repository owners: %w", err)
+	}
+	defer rows.Close()
+
+	seen := make(map[string]bool)
+	for rows.Next() {
+		var prefix string
+		if err := rows.Scan(&prefix); err != nil {
+			return fmt.Errorf("failed to scan repository owner: %w", err)
+		}
+		// prefix is the run URL up to the owner, e.g. https://github.com/octo/
+		if owner := utils.RepoFullName(prefix+filter.Repository, ""); owner != "" {
+			seen[owner] = true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to get repository owners: %w", err)
+	}
+
+	target := filter.Owner + "/" + filter.Repository
+	var others []string
+	for owner := range seen {
+		if filter.Owner == "" || owner != target {
+			others = append(others, owner)
+		}
+	}
+	sort.Strings(others)
+
+	if filter.Owner != "" && len(others) > 0 {
+		return fmt.Errorf("repository name %q is also used by %s; job counters are kept per repository name, so %s cannot be pruned without removing theirs",
+			filter.Repository, strings.Join(others, ", "), target)
+	}
+	if filter.Owner == "" && len(others) > 1 {
+		return fmt.Errorf("repository name %q is used by several owners (%s); job counters are kept per repository name, so it cannot be pruned without removing all of them",
+			filter.Repository, strings.Join(others, ", "))
+	}
+	return nil
+}
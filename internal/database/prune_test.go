@@ -0,0 +1,136 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPruneData_SharedRepositoryName(t *testing.T) {
+	db := newTestDB(t, "")
+	ctx := context.Background()
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	for id, owner := range map[int64]string{1: "acme", 2: "other", 3: "acme"} {
+		repo := "api"
+		if id == 3 {
+			repo = "web"
+		}
+		_, err := db.AddOrUpdateRun(ctx, models.WorkflowRun{
+			ID:             id,
+			Name:           "CI",
+			Status:         models.JobStatusCompleted,
+			RepositoryName: repo,
+			HtmlUrl:        fmt.Sprintf("https://github.com/%s/%s/actions/runs/%d", owner, repo, id),
+			CreatedAt:      created,
+		}, created)
+		require.NoError(t, err)
+	}
+
+	for _, filter := range []models.PruneFilter{
+		{Owner: "acme", Repository: "api"},
+		{Repository: "api"},
+	} {
+		_, err := db.PruneData(ctx, filter, false)
+		assert.ErrorContains(t, err, "used by", "%+v must not remove the other owner's data", filter)
+	}
+
+	result, err := db.PruneData(ctx, models.PruneFilter{Owner: "acme", Repository: "web"}, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), result.Runs)
+
+	result, err = db.PruneData(ctx, models.PruneFilter{Owner: "nobody", Repository: "web"}, true)
+	require.NoError(t, err)
+	assert.Zero(t, result.Runs)
+
+	var remaining int
+	require.NoError(t, db.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM workflow_runs WHERE repository = 'api'").Scan(&remaining))
+	assert.Equal(t, 2, remaining)
+}
+
+func TestPruneData_RemovesJobApprovals(t *testing.T) {
+	db := newTestDB(t, "")
+	ctx := context.Background()
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	for id, repo := range map[int64]string{1: "api", 2: "web"} {
+		_, err := db.AddOrUpdateRun(ctx, models.WorkflowRun{
+			ID:             id,
+			Name:           "Deploy",
+			Status:         models.JobStatusCompleted,
+			RepositoryName: repo,
+			HtmlUrl:        fmt.Sprintf("https://github.com/acme/%s/actions/runs/%d", repo, id),
+			CreatedAt:      created,
+		}, created)
+		require.NoError(t, err)
+		require.NoError(t, db.RecordJobWaiting(ctx, id*10, id, created))
+	}
+
+	_, err := db.PruneData(ctx, models.PruneFilter{Owner: "acme", Repository: "api"}, true)
+	require.NoError(t, err)
+	var approvals int
+	require.NoError(t, db.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM job_approvals").Scan(&approvals))
+	assert.Equal(t, 2, approvals, "a dry run keeps the approvals")
+
+	_, err = db.PruneData(ctx, models.PruneFilter{Owner: "acme", Repository: "api"}, false)
+	require.NoError(t, err)
+	var runIDs []int64
+	rows, err := db.db.QueryContext(ctx, "SELECT run_id FROM job_approvals")
+	require.NoError(t, err)
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		require.NoError(t, rows.Scan(&id))
+		runIDs = append(runIDs, id)
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []int64{2}, runIDs, "only the pruned run's approvals are removed")
+}
+
+func TestPruneData_RemovesPendingJobCounters(t *testing.T) {
+	db := newTestDB(t, "")
+	ctx := context.Background()
+	old := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	recent := old.Add(10 * 24 * time.Hour)
+	for _, job := range []models.WorkflowJob{
+		{ID: 1, RunID: 7, Labels: []string{"linux"}, CreatedAt: old.Add(2 * 24 * time.Hour)},
+		{ID: 2, RunID: 8, Labels: []string{"linux"}, CreatedAt: old},
+		{ID: 3, RunID: 9, Labels: []string{"linux"}, CreatedAt: recent},
+	} {
+		job.Name = "build"
+		job.Status = models.JobStatusQueued
+		_, err := db.AddOrUpdateJob(ctx, job, job.CreatedAt)
+		require.NoError(t, err)
+	}
+	// Run 7 arrives without a repository, so its counters stay pending; they
+	// are from a day after the cutoff, so only the run matches them
+	_, err := db.AddOrUpdateRun(ctx, models.WorkflowRun{ID: 7, Name: "CI", Status: models.JobStatusCompleted, CreatedAt: old}, old)
+	require.NoError(t, err)
+
+	pending := func() []int64 {
+		rows, err := db.db.QueryContext(ctx, "SELECT run_id FROM pending_job_counters ORDER BY run_id")
+		require.NoError(t, err)
+		defer rows.Close()
+		var runIDs []int64
+		for rows.Next() {
+			var id int64
+			require.NoError(t, rows.Scan(&id))
+			runIDs = append(runIDs, id)
+		}
+		require.NoError(t, rows.Err())
+		return runIDs
+	}
+	require.Equal(t, []int64{7, 8, 9}, pending())
+
+	_, err = db.PruneData(ctx, models.PruneFilter{Before: old.Add(24 * time.Hour)}, true)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{7, 8, 9}, pending(), "a dry run keeps the pending counters")
+
+	result, err := db.PruneData(ctx, models.PruneFilter{Before: old.Add(24 * time.Hour)}, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), result.Runs)
+	assert.Equal(t, []int64{9}, pending(), "pending counters of pruned runs and days are removed")
+}
@@ -0,0 +1,60 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+)
+
+// GetRunDurations returns the durations of successful runs completed within
+// the window, ordered by completion time. If repos is non-empty, filters to
+// those repositories.
+func (db *DBWrapper) GetRunDurations(ctx context.Context, since time.Duration, repos []string) ([]models.RunDuration, error) {
+	cutoff := db.clock.Now().Add(-since).Format(time.RFC3339)
+
+	where := repoIn("repository", repos)
+	args := append([]interface{}{cutoff}, repoArgs(repos)...)
+
+	rows, err := db.db.QueryContext(ctx, `
+		SELECT id, name, repository, html_url, display_title, updated_at,
+			(julianday(updated_at) - julianday(run_started_at)) * 86400 AS duration
+		FROM workflow_runs
+		WHERE status = 'completed' AND conclusion = 'success'
+			AND run_started_at IS NOT NULL AND updated_at >= ?`+where+`
+		ORDER BY updated_at ASC, id ASC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get run durations: %w", err)
+	}
+	defer rows.Close()
+
+	var durations []models.RunDuration
+	for rows.Next() {
+		var d models.RunDuration
+		var repository, htmlURL, displayTitle, completedAt sql.NullString
+		var seconds sql.NullFloat64
+		if err := rows.Scan(&d.RunID, &d.Name, &repository, &htmlURL, &displayTitle, &completedAt, &seconds); err != nil {
+			return nil, fmt.Errorf("failed to scan run duration: %w", err)
+		}
+		if !seconds.Valid || seconds.Float64 < 0 {
+			continue
+		}
+		d.Repository = repository.String
+		d.HtmlUrl = htmlURL.String
+		d.DisplayTitle = displayTitle.String
+		d.CompletedAt = parseTime(completedAt.String)
+		d.Seconds = seconds.Float64
+		durations = append(durations, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if durations == nil {
+		durations = []models.RunDuration{}
+	}
+
+	return durations, nil
+}
@@ -1,18 +1,44 @@
 package database
 
+import "strings"
+
 // jobRepoFilter returns a JOIN clause and args for filtering workflow_jobs by repository.
-// When repo is empty, returns empty string and nil args (no filter).
-func jobRepoFilter(repo string) (string, []interface{}) {
-	if repo == "" {
+// When repos is empty, returns empty string and nil args (no filter).
+func jobRepoFilter(repos []string) (string, []interface{}) {
+	if len(repos) == 0 {
 		return "", nil
 	}
-	return " JOIN workflow_runs r ON j.run_id = r.id", []interface{}{repo}
+	return " JOIN workflow_runs r ON j.run_id = r.id", repoArgs(repos)
 }
 
 // repoWhere returns the AND clause for repo filtering.
-func repoWhere(repo string) string {
-	if repo == "" {
+func repoWhere(repos []string) string {
+	return repoIn("r.repository", repos)
+}
+
+// repoIn returns an AND clause matching column against any of repos, or an
+// empty string when repos is empty.
+func repoIn(column string, repos []string) string {
+	if len(repos) == 0 {
 		return ""
 	}
-	return " AND r.repository = ?"
+	return " AND " + column + " IN (?" + strings.Repeat(", ?", len(repos)-1) + ")"
+}
+
+// repoArgs returns repos as query args for repoIn.
+func repoArgs(repos []string) []interface{} {
+	args := make([]interface{}, len(repos))
+	for i, repo := range repos {
+		args[i] = repo
+	}
+	return args
+}
+
+// likeEscaper escapes the LIKE wildcards for patterns using ESCAPE '\'.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike returns s for use in a LIKE pattern with ESCAPE '\', matching
+// only itself: repository names such as my_app would otherwise match myXapp.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
 }
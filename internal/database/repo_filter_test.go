@@ -0,0 +1,26 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEscapeLike(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	matches := func(value, pattern string) bool {
+		var matched bool
+		require.NoError(t, db.QueryRow(`SELECT ? LIKE ? ESCAPE '\'`, value, pattern).Scan(&matched))
+		return matched
+	}
+
+	assert.Equal(t, `my\_app\%\\`, escapeLike(`my_app%\`))
+	assert.True(t, matches("https://github.com/octo/my_app/actions/runs/1", "%/"+escapeLike("octo/my_app")+"/actions/%"))
+	assert.False(t, matches("https://github.com/octo/myXapp/actions/runs/1", "%/"+escapeLike("octo/my_app")+"/actions/%"))
+	assert.False(t, matches("https://github.com/octo/app/actions/runs/1", "%/"+escapeLike("%")+"/actions/%"))
+}
@@ -0,0 +1,63 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/gateixeira/live-actions/models"
+)
+
+// GetRepoGroups returns the repository groups managed through the API, ordered by name.
+func (db *DBWrapper) GetRepoGroups(ctx context.Context) ([]models.RepoGroup, error) {
+	rows, err := db.db.QueryContext(ctx, "SELECT name, repositories FROM repo_groups ORDER BY name ASC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repo groups: %w", err)
+	}
+	defer rows.Close()
+
+	groups := []models.RepoGroup{}
+	for rows.Next() {
+		var g models.RepoGroup
+		var reposJSON string
+		if err := rows.Scan(&g.Name, &reposJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan repo group: %w", err)
+		}
+		if err := json.Unmarshal([]byte(reposJSON), &g.Repositories); err != nil {
+			return nil, fmt.Errorf("failed to decode repositories of group %s: %w", g.Name, err)
+		}
+		g.Source = "api"
+		groups = append(groups, g)
+	}
+	return groups, rows.Err()
+}
+
+// SaveRepoGroup creates or replaces the repository group with the given name.
+func (db *DBWrapper) SaveRepoGroup(ctx context.Context, group models.RepoGroup) error {
+	reposJSON, err := json.Marshal(group.Repositories)
+	if err != nil {
+		return fmt.Errorf("failed to encode repositories: %w", err)
+	}
+
+	_, err = db.db.ExecContext(ctx,
+		`INSERT INTO repo_groups (name, repositories) VALUES (?, ?)
+		ON CONFLICT (name) DO UPDATE SET repositories = excluded.repositories`,
+		group.Name, string(reposJSON))
+	if err != nil {
+		return fmt.Errorf("failed to save repo group: %w", err)
+	}
+	return nil
+}
+
+// DeleteRepoGroup removes a repository group. Returns false when it did not exist.
+func (db *DBWrapper) DeleteRepoGroup(ctx context.Context, name string) (bool, error) {
+	result, err := db.db.ExecContext(ctx, "DELETE FROM repo_groups WHERE name = ?", name)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete repo group: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
@@ -0,0 +1,63 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+)
+
+// GetRunnerGroupStats returns utilization and queue statistics for every
+// runner group that ran a job within the given window, busiest first. Jobs
+// are assigned to a group once a runner picks them up, so queued jobs are
+// not included.
+func (db *DBWrapper) GetRunnerGroupStats(ctx context.Context, since time.Duration) ([]models.RunnerGroupStats, error) {
+	now := db.clock.Now().UTC()
+	start := now.Add(-since)
+	nowStr := now.Format(time.RFC3339)
+	startStr := start.Format(time.RFC3339)
+
+	// Busy time is clipped to the window; in-progress jobs run until now
+	rows, err := db.db.QueryContext(ctx, `
+		SELECT
+			runner_group_id,
+			MAX(runner_group_name),
+			SUM(CASE WHEN status = 'in_progress' THEN 1 ELSE 0 END),
+			SUM(CASE WHEN julianday(started_at) >= julianday(?) THEN 1 ELSE 0 END),
+			COALESCE(SUM(MAX(0,
+				MIN(julianday(COALESCE(completed_at, ?)), julianday(?)) - MAX(julianday(started_at), julianday(?))
+			) * 1440), 0),
+			COALESCE(AVG(CASE WHEN julianday(started_at) >= julianday(?)
+				THEN MAX(0, julianday(started_at) - julianday(created_at)) * 86400 END), 0),
+			COALESCE(MAX(CASE WHEN julianday(started_at) >= julianday(?)
+				THEN MAX(0, julianday(started_at) - julianday(created_at)) * 86400 END), 0)
+		FROM workflow_jobs
+		WHERE runner_group_id != 0 AND started_at IS NOT NULL
+			AND (completed_at IS NULL OR julianday(completed_at) >= julianday(?))
+		GROUP BY runner_group_id
+		ORDER BY 5 DESC`,
+		startStr, nowStr, nowStr, startStr, startStr, startStr, startStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get runner group stats: %w", err)
+	}
+	defer rows.Close()
+
+	minutes := since.Minutes()
+	groups := []models.RunnerGroupStats{}
+	for rows.Next() {
+		var g models.RunnerGroupStats
+		if err := rows.Scan(&g.ID, &g.Name, &g.Running, &g.Started, &g.BusyMinutes, &g.AvgQueueSeconds, &g.MaxQueueSeconds); err != nil {
+			return nil, fmt.Errorf("failed to scan runner group stats: %w", err)
+		}
+		if minutes > 0 {
+			g.AvgBusyRunners = g.BusyMinutes / minutes
+		}
+		groups = append(groups, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
@@ -0,0 +1,157 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+)
+
+// runnerHostAttributes maps the attributes runner utilization can be sliced
+// by to their runner_hosts column.
+var runnerHostAttributes = map[string]string{
+	"zone":          "zone",
+	"instance_type": "instance_type",
+}
+
+// GetRunnerHosts returns the registered runner hosts, ordered by name.
+func (db *DBWrapper) GetRunnerHosts(ctx context.Context) ([]models.RunnerHost, error) {
+	rows, err := db.db.QueryContext(ctx,
+		"SELECT name, labels, zone, instance_type, last_seen_at, updated_at FROM runner_hosts ORDER BY name ASC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get runner hosts: %w", err)
+	}
+	defer rows.Close()
+
+	hosts := []models.RunnerHost{}
+	for rows.Next() {
+		var h models.RunnerHost
+		var labelsJSON, updatedAt string
+		var lastSeenAt sql.NullString
+		if err := rows.Scan(&h.Name, &labelsJSON, &h.Zone, &h.InstanceType, &lastSeenAt, &updatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan runner host: %w", err)
+		}
+		h.Labels = labelsFromJSON(labelsJSON)
+		if lastSeenAt.Valid {
+			t := parseTime(lastSeenAt.String)
+			h.LastSeenAt = &t
+		}
+		h.UpdatedAt = parseTime(updatedAt)
+		hosts = append(hosts, h)
+	}
+	return hosts, rows.Err()
+}
+
+// SaveRunnerHost creates a runner host or replaces its labels, zone and
+// instance type. When it last sent a heartbeat is kept.
+func (db *DBWrapper) SaveRunnerHost(ctx context.Context, host models.RunnerHost) error {
+	_, err := db.db.ExecContext(ctx, `
+		INSERT INTO runner_hosts (name, labels, zone, instance_type, updated_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET
+			labels = excluded.labels,
+			zone = excluded.zone,
+			instance_type = excluded.instance_type,
+			updated_at = excluded.updated_at`,
+		host.Name, labelsToJSON(host.Labels), host.Zone, host.InstanceType, db.clock.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("failed to save runner host: %w", err)
+	}
+	return nil
+}
+
+// RecordRunnerHeartbeat marks a runner host as seen now, registering it if
+// needed. Attributes the heartbeat leaves empty keep their stored value, so
+// hosts can report just their name once registered.
+func (db *DBWrapper) RecordRunnerHeartbeat(ctx context.Context, host models.RunnerHost) error {
+	now := db.clock.Now().UTC().Format(time.RFC3339)
+	_, err := db.db.ExecContext(ctx, `
+		INSERT INTO runner_hosts (name, labels, zone, instance_type, last_seen_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET
+			labels = CASE WHEN excluded.labels != '[]' THEN excluded.labels ELSE labels END,
+			zone = COALESCE(NULLIF(excluded.zone, ''), zone),
+			instance_type = COALESCE(NULLIF(excluded.instance_type, ''), instance_type),
+			last_seen_at = excluded.last_seen_at,
+			updated_at = excluded.updated_at`,
+		host.Name, labelsToJSON(host.Labels), host.Zone, host.InstanceType, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to record runner heartbeat: %w", err)
+	}
+	return nil
+}
+
+// DeleteRunnerHost removes a runner host. Returns false when it did not exist.
+func (db *DBWrapper) DeleteRunnerHost(ctx context.Context, name string) (bool, error) {
+	result, err := db.db.ExecContext(ctx, "DELETE FROM runner_hosts WHERE name = ?", name)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete runner host: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// GetRunnerHostStats returns runner utilization within the given window
+// grouped by a runner host attribute, "zone" or "instance_type", busiest
+// first. Jobs are matched to hosts by runner name; those on unregistered
+// runners are grouped under an empty key.
+func (db *DBWrapper) GetRunnerHostStats(ctx context.Context, since time.Duration, by string) ([]models.RunnerHostStats, error) {
+	column, ok := runnerHostAttributes[by]
+	if !ok {
+		return nil, fmt.Errorf("unknown runner host attribute %q", by)
+	}
+
+	now := db.clock.Now().UTC()
+	nowStr := now.Format(time.RFC3339)
+	startStr := now.Add(-since).Format(time.RFC3339)
+
+	// Busy time is clipped to the window as in GetRunnerGroupStats
+	rows, err := db.db.QueryContext(ctx, `
+		WITH registered AS (
+			SELECT `+column+` AS key, COUNT(*) AS hosts FROM runner_hosts GROUP BY `+column+`
+		), busy AS (
+			SELECT
+				COALESCE(h.`+column+`, '') AS key,
+				COUNT(DISTINCT j.runner_name) AS active,
+				SUM(CASE WHEN j.status = 'in_progress' THEN 1 ELSE 0 END) AS running,
+				SUM(CASE WHEN julianday(j.started_at) >= julianday(?) THEN 1 ELSE 0 END) AS started,
+				COALESCE(SUM(MAX(0,
+					MIN(julianday(COALESCE(j.completed_at, ?)), julianday(?)) - MAX(julianday(j.started_at), julianday(?))
+				) * 1440), 0) AS busy_minutes
+			FROM workflow_jobs j
+			LEFT JOIN runner_hosts h ON h.name = j.runner_name
+			WHERE j.runner_name != '' AND j.started_at IS NOT NULL
+				AND (j.completed_at IS NULL OR julianday(j.completed_at) >= julianday(?))
+			GROUP BY 1
+		)
+		SELECT key, COALESCE(r.hosts, 0), b.active, b.running, b.started, b.busy_minutes
+		FROM busy b LEFT JOIN registered r USING (key)
+		UNION ALL
+		SELECT key, hosts, 0, 0, 0, 0 FROM registered WHERE key NOT IN (SELECT key FROM busy)
+		ORDER BY 6 DESC, 1 ASC`,
+		startStr, nowStr, nowStr, startStr, startStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get runner host stats: %w", err)
+	}
+	defer rows.Close()
+
+	minutes := since.Minutes()
+	stats := []models.RunnerHostStats{}
+	for rows.Next() {
+		var s models.RunnerHostStats
+		if err := rows.Scan(&s.Key, &s.RegisteredHosts, &s.ActiveHosts, &s.Running, &s.Started, &s.BusyMinutes); err != nil {
+			return nil, fmt.Errorf("failed to scan runner host stats: %w", err)
+		}
+		if minutes > 0 {
+			s.AvgBusyRunners = s.BusyMinutes / minutes
+			if s.RegisteredHosts > 0 {
+				s.Utilization = s.AvgBusyRunners / float64(s.RegisteredHosts) * 100
+			}
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
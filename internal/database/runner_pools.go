@@ -0,0 +1,53 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/gateixeira/live-actions/models"
+)
+
+// GetRunnerPoolStatus returns queue state for every self-hosted runner label
+// set that currently has queued jobs, along with when a job last started on it.
+func (db *DBWrapper) GetRunnerPoolStatus(ctx context.Context) ([]models.RunnerPoolStatus, error) {
+	rows, err := db.db.QueryContext(ctx, `
+		SELECT
+			j.labels,
+			SUM(CASE WHEN j.status = 'queued' THEN 1 ELSE 0 END) AS queued,
+			SUM(CASE WHEN j.status = 'in_progress' THEN 1 ELSE 0 END) AS running,
+			MIN(CASE WHEN j.status = 'queued' THEN j.created_at END) AS oldest_queued,
+			MAX(CASE WHEN j.started_at != '' THEN j.started_at END) AS last_started
+		FROM workflow_jobs j
+		WHERE EXISTS (SELECT 1 FROM json_each(j.labels) WHERE value = 'self-hosted')
+		GROUP BY j.labels
+		HAVING queued > 0
+		ORDER BY queued DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get runner pool status: %w", err)
+	}
+	defer rows.Close()
+
+	var pools []models.RunnerPoolStatus
+	for rows.Next() {
+		var p models.RunnerPoolStatus
+		var labelsJSON string
+		var oldestQueued, lastStarted sql.NullString
+		if err := rows.Scan(&labelsJSON, &p.Queued, &p.Running, &oldestQueued, &lastStarted); err != nil {
+			return nil, fmt.Errorf("failed to scan runner pool status: %w", err)
+		}
+		p.Labels = labelsFromJSON(labelsJSON)
+		p.OldestQueuedAt = parseTime(oldestQueued.String)
+		p.LastStartedAt = parseTime(lastStarted.String)
+		pools = append(pools, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if pools == nil {
+		pools = []models.RunnerPoolStatus{}
+	}
+
+	return pools, nil
+}
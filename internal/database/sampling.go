@@ -0,0 +1,93 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// sampledOutCondition matches completed successful jobs outside the kept
+// sample. Sampling by job ID keeps the decision stable across events.
+const sampledOutCondition = `status = 'completed' AND conclusion = 'success' AND completed_at < ? AND (id % 100) >= ?`
+
+// PruneSampledJobs deletes successful jobs completed before olderThan that
+// fall outside the keepPercent sample, along with their webhook events. Each
+// deleted job is first added to sampled_job_counters so failure analytics
+// totals remain exact. Returns the number of jobs deleted.
+func (db *DBWrapper) PruneSampledJobs(ctx context.Context, keepPercent int, olderThan time.Duration) (int64, error) {
+	cutoff := db.clock.Now().Add(-olderThan).UTC().Format(time.RFC3339)
+
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO sampled_job_counters (hour, repository, name, jobs)
+		SELECT strftime('%Y-%m-%dT%H:00:00Z', j.completed_at), COALESCE(r.repository, ''), j.name, COUNT(*)
+		FROM (SELECT name, run_id, completed_at FROM workflow_jobs WHERE `+sampledOutCondition+`) j
+		LEFT JOIN workflow_runs r ON r.id = j.run_id
+		WHERE true
+		GROUP BY 1, 2, 3
+		ON CONFLICT (hour, repository, name) DO UPDATE SET jobs = jobs + excluded.jobs`,
+		cutoff, keepPercent)
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, fmt.Errorf("failed to count sampled jobs: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM webhook_events
+		WHERE status = 'processed' AND ordering_key IN (
+			SELECT 'job_' || id FROM workflow_jobs WHERE `+sampledOutCondition+`)`,
+		cutoff, keepPercent)
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, fmt.Errorf("failed to delete sampled job events: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM workflow_jobs WHERE "+sampledOutCondition, cutoff, keepPercent)
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, fmt.Errorf("failed to delete sampled jobs: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit sampling transaction: %w", err)
+	}
+	return deleted, nil
+}
+
+// sampledSuccesses returns the successful jobs removed by sampling since
+// cutoff, in total and per job name. Counters are hourly, so the hour
+// containing cutoff is included in full.
+func (db *DBWrapper) sampledSuccesses(ctx context.Context, cutoff time.Time, repos []string) (int, map[string]int, error) {
+	args := append([]interface{}{cutoff.UTC().Truncate(time.Hour).Format(time.RFC3339)}, repoArgs(repos)...)
+	rows, err := db.db.QueryContext(ctx, `
+		SELECT name, SUM(jobs)
+		FROM sampled_job_counters
+		WHERE hour >= ?`+repoIn("repository", repos)+`
+		GROUP BY name`, args...)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to get sampled job counters: %w", err)
+	}
+	defer rows.Close()
+
+	total := 0
+	byName := make(map[string]int)
+	for rows.Next() {
+		var name string
+		var jobs int
+		if err := rows.Scan(&name, &jobs); err != nil {
+			return 0, nil, fmt.Errorf("failed to scan sampled job counter: %w", err)
+		}
+		byName[name] = jobs
+		total += jobs
+	}
+	return total, byName, rows.Err()
+}
@@ -0,0 +1,50 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// GetSettings returns all persisted runtime settings keyed by name.
+func (db *DBWrapper) GetSettings(ctx context.Context) (map[string]string, error) {
+	rows, err := db.db.QueryContext(ctx, "SELECT key, value FROM settings")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get settings: %w", err)
+	}
+	defer rows.Close()
+
+	settings := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan setting: %w", err)
+		}
+		settings[key] = value
+	}
+	return settings, rows.Err()
+}
+
+// SaveSettings creates or replaces the given settings in a single transaction.
+func (db *DBWrapper) SaveSettings(ctx context.Context, settings map[string]string) error {
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	now := db.clock.Now().UTC().Format(time.RFC3339)
+	for key, value := range settings {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO settings (key, value, updated_at) VALUES (?, ?, ?)
+			ON CONFLICT (key) DO UPDATE SET
+				value = excluded.value,
+				updated_at = excluded.updated_at`,
+			key, value, now)
+		if err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to save setting %s: %w", key, err)
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,50 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+)
+
+// RecordSLOBreach stores a breached SLO for the activity feed.
+func (db *DBWrapper) RecordSLOBreach(ctx context.Context, breach models.SLOBreach) error {
+	_, err := db.db.ExecContext(ctx, `
+		INSERT INTO slo_breaches (kind, title, message, repository, workflow, started_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		breach.Kind, breach.Title, breach.Message, breach.Repository, breach.Workflow,
+		breach.StartedAt.UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("failed to record SLO breach: %w", err)
+	}
+	return nil
+}
+
+// GetSLOBreaches returns the SLO breaches that started within the window,
+// newest first.
+func (db *DBWrapper) GetSLOBreaches(ctx context.Context, since time.Duration, limit int) ([]models.SLOBreach, error) {
+	cutoff := db.clock.Now().Add(-since).Format(time.RFC3339)
+	rows, err := db.db.QueryContext(ctx, `
+		SELECT id, kind, title, message, repository, workflow, started_at
+		FROM slo_breaches
+		WHERE started_at >= ?
+		ORDER BY started_at DESC, id DESC
+		LIMIT ?`, cutoff, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SLO breaches: %w", err)
+	}
+	defer rows.Close()
+
+	breaches := []models.SLOBreach{}
+	for rows.Next() {
+		var b models.SLOBreach
+		var startedAt string
+		if err := rows.Scan(&b.ID, &b.Kind, &b.Title, &b.Message, &b.Repository, &b.Workflow, &startedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan SLO breach: %w", err)
+		}
+		b.StartedAt = parseTime(startedAt)
+		breaches = append(breaches, b)
+	}
+	return breaches, rows.Err()
+}
@@ -0,0 +1,42 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/clock"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSLOBreaches(t *testing.T) {
+	db := newTestDB(t, "")
+	ctx := context.Background()
+	now := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+	db.clock = clock.NewFake(now)
+
+	for _, b := range []models.SLOBreach{
+		{Kind: "slo_burn_rate", Title: "old", StartedAt: now.Add(-48 * time.Hour)},
+		{Kind: "slo_burn_rate", Title: "burn", Message: "burning", Repository: "octo/app", Workflow: "Deploy", StartedAt: now.Add(-3 * time.Hour)},
+		{Kind: "processing_lag", Title: "lag", StartedAt: now.Add(-time.Hour)},
+	} {
+		require.NoError(t, db.RecordSLOBreach(ctx, b))
+	}
+
+	breaches, err := db.GetSLOBreaches(ctx, 24*time.Hour, 10)
+	require.NoError(t, err)
+	require.Len(t, breaches, 2)
+	assert.Equal(t, "lag", breaches[0].Title)
+	assert.Equal(t, "burn", breaches[1].Title)
+	assert.Equal(t, "burning", breaches[1].Message)
+	assert.Equal(t, "octo/app", breaches[1].Repository)
+	assert.Equal(t, "Deploy", breaches[1].Workflow)
+	assert.True(t, now.Add(-3*time.Hour).Equal(breaches[1].StartedAt))
+
+	breaches, err = db.GetSLOBreaches(ctx, 24*time.Hour, 1)
+	require.NoError(t, err)
+	require.Len(t, breaches, 1)
+	assert.Equal(t, "lag", breaches[0].Title)
+}
@@ -0,0 +1,98 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+)
+
+// GetWorkflowSLOs returns the workflow SLOs managed through the API, ordered by name.
+func (db *DBWrapper) GetWorkflowSLOs(ctx context.Context) ([]models.WorkflowSLO, error) {
+	rows, err := db.db.QueryContext(ctx, `
+		SELECT name, repository, workflow, success_target, duration_percentile, duration_target_seconds, window_days
+		FROM workflow_slos ORDER BY name ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow SLOs: %w", err)
+	}
+	defer rows.Close()
+
+	slos := []models.WorkflowSLO{}
+	for rows.Next() {
+		var s models.WorkflowSLO
+		if err := rows.Scan(&s.Name, &s.Repository, &s.Workflow, &s.SuccessTarget,
+			&s.DurationPercentile, &s.DurationTargetSeconds, &s.WindowDays); err != nil {
+			return nil, fmt.Errorf("failed to scan workflow SLO: %w", err)
+		}
+		s.Source = "api"
+		slos = append(slos, s)
+	}
+	return slos, rows.Err()
+}
+
+// SaveWorkflowSLO creates or replaces the workflow SLO with the given name.
+func (db *DBWrapper) SaveWorkflowSLO(ctx context.Context, slo models.WorkflowSLO) error {
+	_, err := db.db.ExecContext(ctx,
+		`INSERT INTO workflow_slos (name, repository, workflow, success_target, duration_percentile, duration_target_seconds, window_days)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET
+			repository = excluded.repository,
+			workflow = excluded.workflow,
+			success_target = excluded.success_target,
+			duration_percentile = excluded.duration_percentile,
+			duration_target_seconds = excluded.duration_target_seconds,
+			window_days = excluded.window_days`,
+		slo.Name, slo.Repository, slo.Workflow, slo.SuccessTarget,
+		slo.DurationPercentile, slo.DurationTargetSeconds, slo.WindowDays)
+	if err != nil {
+		return fmt.Errorf("failed to save workflow SLO: %w", err)
+	}
+	return nil
+}
+
+// DeleteWorkflowSLO removes a workflow SLO. Returns false when it did not exist.
+func (db *DBWrapper) DeleteWorkflowSLO(ctx context.Context, name string) (bool, error) {
+	result, err := db.db.ExecContext(ctx, "DELETE FROM workflow_slos WHERE name = ?", name)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete workflow SLO: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// GetSLORuns returns the runs of a workflow in a repository completed within
+// the window, oldest first.
+func (db *DBWrapper) GetSLORuns(ctx context.Context, repository, workflow string, since time.Duration) ([]models.SLORun, error) {
+	cutoff := db.clock.Now().Add(-since).Format(time.RFC3339)
+
+	rows, err := db.db.QueryContext(ctx, `
+		SELECT conclusion, updated_at,
+			(julianday(updated_at) - julianday(run_started_at)) * 86400 AS duration
+		FROM workflow_runs
+		WHERE repository = ? AND name = ? AND status = 'completed' AND updated_at >= ?
+		ORDER BY updated_at ASC, id ASC`, repository, workflow, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SLO runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []models.SLORun{}
+	for rows.Next() {
+		var conclusion, completedAt sql.NullString
+		var seconds sql.NullFloat64
+		if err := rows.Scan(&conclusion, &completedAt, &seconds); err != nil {
+			return nil, fmt.Errorf("failed to scan SLO run: %w", err)
+		}
+		run := models.SLORun{Conclusion: conclusion.String, CompletedAt: parseTime(completedAt.String), Seconds: -1}
+		if seconds.Valid && seconds.Float64 >= 0 {
+			run.Seconds = seconds.Float64
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
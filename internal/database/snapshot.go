@@ -0,0 +1,40 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// errWriteInSnapshot is returned by methods that write in a transaction of
+// their own when they are called inside a read snapshot.
+var errWriteInSnapshot = errors.New("cannot start a write transaction inside a read snapshot")
+
+// snapshotTx runs a DBWrapper's queries in a read snapshot's transaction.
+type snapshotTx struct {
+	*sql.Tx
+}
+
+func (snapshotTx) BeginTx(context.Context, *sql.TxOptions) (*sql.Tx, error) {
+	return nil, errWriteInSnapshot
+}
+
+// ReadSnapshot runs fn with a DatabaseInterface whose queries all run in one
+// transaction. SQLite reads in a transaction see the database as of its first
+// read, so events processed meanwhile do not make counts from different
+// queries disagree. The transaction holds the only connection until fn
+// returns, so fn should only query. Nested calls reuse the outer snapshot.
+func (db *DBWrapper) ReadSnapshot(ctx context.Context, fn func(DatabaseInterface) error) error {
+	if _, nested := db.db.(snapshotTx); nested {
+		return fn(db)
+	}
+
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start read snapshot: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	return fn(&DBWrapper{db: snapshotTx{tx}, clock: db.clock})
+}
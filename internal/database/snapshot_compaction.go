@@ -0,0 +1,78 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+)
+
+// CompactMetricsSnapshots rolls the metrics snapshots taken before the cutoff
+// up into buckets of the given width, keeping each bucket's sample count,
+// average and peak running and queued jobs, and deletes the raw snapshots.
+// Buckets that were already rolled up are merged with the new samples. On a
+// dry run the changes are rolled back, so the result counts what would be
+// compacted.
+func (db *DBWrapper) CompactMetricsSnapshots(ctx context.Context, before time.Time, bucket time.Duration, dryRun bool) (models.SnapshotCompaction, error) {
+	var result models.SnapshotCompaction
+	seconds := int64(bucket.Seconds())
+	if seconds < 1 {
+		return result, errors.New("compaction bucket must be at least one second")
+	}
+	// metrics_snapshots stores timestamps as datetime (no T, no Z)
+	cutoff := before.UTC().Format("2006-01-02 15:04:05")
+
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return result, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback()
+		}
+	}()
+
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO metrics_snapshot_rollups
+			(bucket_start, bucket_seconds, samples, avg_running, avg_queued, max_running, max_queued, max_demand)
+		SELECT datetime(CAST(strftime('%s', timestamp) AS INTEGER) / ? * ?, 'unixepoch') AS bucket, ?,
+			COUNT(*), AVG(running_jobs), AVG(queued_jobs),
+			MAX(running_jobs), MAX(queued_jobs), MAX(running_jobs + queued_jobs)
+		FROM metrics_snapshots
+		WHERE timestamp < ?
+		GROUP BY bucket
+		ON CONFLICT (bucket_start, bucket_seconds) DO UPDATE SET
+			samples = samples + excluded.samples,
+			avg_running = (avg_running * samples + excluded.avg_running * excluded.samples) / (samples + excluded.samples),
+			avg_queued = (avg_queued * samples + excluded.avg_queued * excluded.samples) / (samples + excluded.samples),
+			max_running = MAX(max_running, excluded.max_running),
+			max_queued = MAX(max_queued, excluded.max_queued),
+			max_demand = MAX(max_demand, excluded.max_demand)`,
+		seconds, seconds, seconds, cutoff)
+	if err != nil {
+		return result, fmt.Errorf("failed to roll up metrics snapshots: %w", err)
+	}
+	if result.Buckets, err = res.RowsAffected(); err != nil {
+		return result, fmt.Errorf("failed to get rolled up bucket count: %w", err)
+	}
+
+	res, err = tx.ExecContext(ctx, "DELETE FROM metrics_snapshots WHERE timestamp < ?", cutoff)
+	if err != nil {
+		return result, fmt.Errorf("failed to delete compacted metrics snapshots: %w", err)
+	}
+	if result.Snapshots, err = res.RowsAffected(); err != nil {
+		return result, fmt.Errorf("failed to get compacted snapshot count: %w", err)
+	}
+
+	if dryRun {
+		return result, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return models.SnapshotCompaction{}, fmt.Errorf("failed to commit snapshot compaction: %w", err)
+	}
+	committed = true
+	return result, nil
+}
@@ -0,0 +1,84 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+)
+
+// GetSparklines buckets running/queued snapshots and job failures into a fixed
+// number of points starting at start. Running and queued hold the peak of each
+// bucket; empty buckets are zero.
+func (d *DBWrapper) GetSparklines(ctx context.Context, start time.Time, bucket time.Duration, points int) (*models.Sparklines, error) {
+	bucketSeconds := int64(bucket / time.Second)
+	if bucketSeconds <= 0 || points <= 0 {
+		return nil, fmt.Errorf("invalid sparkline bucketing: %d points of %s", points, bucket)
+	}
+
+	result := &models.Sparklines{
+		Start:           start.Unix(),
+		BucketSeconds:   bucketSeconds,
+		Running:         make([]float64, points),
+		Queued:          make([]float64, points),
+		FailuresPerHour: make([]float64, points),
+	}
+
+	// metrics_snapshots stores timestamps as datetime (no T, no Z)
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT
+			(CAST(strftime('%s', timestamp) AS INTEGER) - ?) / ? AS bucket,
+			MAX(running_jobs),
+			MAX(queued_jobs)
+		FROM metrics_snapshots
+		WHERE timestamp >= ?
+		GROUP BY bucket`,
+		start.Unix(), bucketSeconds, start.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get snapshot sparklines: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var i int
+		var running, queued float64
+		if err := rows.Scan(&i, &running, &queued); err != nil {
+			return nil, fmt.Errorf("failed to scan snapshot bucket: %w", err)
+		}
+		if i >= 0 && i < points {
+			result.Running[i] = running
+			result.Queued[i] = queued
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// workflow_jobs stores timestamps as RFC3339
+	failureRows, err := d.db.QueryContext(ctx, `
+		SELECT
+			(CAST(strftime('%s', completed_at) AS INTEGER) - ?) / ? AS bucket,
+			COUNT(*)
+		FROM workflow_jobs
+		WHERE status = 'completed' AND conclusion IN ('failure', 'timed_out') AND completed_at >= ?
+		GROUP BY bucket`,
+		start.Unix(), bucketSeconds, start.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get failure sparkline: %w", err)
+	}
+	defer failureRows.Close()
+
+	perHour := float64(time.Hour/time.Second) / float64(bucketSeconds)
+	for failureRows.Next() {
+		var i, failures int
+		if err := failureRows.Scan(&i, &failures); err != nil {
+			return nil, fmt.Errorf("failed to scan failure bucket: %w", err)
+		}
+		if i >= 0 && i < points {
+			result.FailuresPerHour[i] = float64(failures) * perHour
+		}
+	}
+
+	return result, failureRows.Err()
+}
@@ -0,0 +1,13 @@
+//go:build sqlcipher
+
+package database
+
+import (
+	// Registers "sqlite3", SQLite with SQLCipher encryption. It needs cgo, and
+	// the sqlite_json tag for the JSON functions queries rely on.
+	_ "github.com/mutecomm/go-sqlcipher/v4"
+)
+
+func init() {
+	encryptedDriver = "sqlite3"
+}
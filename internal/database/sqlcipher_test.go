@@ -0,0 +1,49 @@
+//go:build sqlcipher
+
+package database
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	testEncryptionKey = "test-encryption-key"
+}
+
+func TestSQLCipher_EncryptedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "live-actions.db")
+	sqlDB, err := InitDB(path, "test-encryption-key", MigrationOptions{})
+	require.NoError(t, err, "migrations run on the encrypted database")
+	require.NoError(t, sqlDB.Close())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.False(t, bytes.HasPrefix(content, []byte("SQLite format 3")), "the file is encrypted")
+
+	_, err = InitDB(path, "wrong-key", MigrationOptions{})
+	assert.Error(t, err)
+}
+
+func TestSQLCipher_StorageReport(t *testing.T) {
+	db := newTestDB(t, "")
+	ctx := context.Background()
+
+	require.NoError(t, db.RecordStorageSample(ctx))
+	report, err := db.GetStorageReport(ctx)
+	require.NoError(t, err)
+
+	assert.True(t, report.SizesUnavailable, "the bundled SQLite has no dbstat table")
+	assert.Positive(t, report.DatabaseBytes)
+	names := make([]string, len(report.Tables))
+	for i, table := range report.Tables {
+		names[i] = table.Name
+	}
+	assert.Contains(t, names, "workflow_runs")
+}
@@ -0,0 +1,168 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+)
+
+const (
+	// storageGrowthWindow is how far back the baseline sample for growth rates is taken from.
+	storageGrowthWindow = 7 * 24 * time.Hour
+	// storageSampleRetention is how long daily storage samples are kept.
+	storageSampleRetention = 90 * 24 * time.Hour
+)
+
+// tableStorage measures every table and its indexes using the dbstat virtual
+// table. SQLite builds without dbstat, such as the one bundled with the
+// SQLCipher driver, only get row counts: sized is then false.
+func (db *DBWrapper) tableStorage(ctx context.Context) (tables []models.TableStorage, sized bool, err error) {
+	rows, err := db.db.QueryContext(ctx, `
+		SELECT m.tbl_name,
+			SUM(CASE WHEN m.type = 'table' THEN s.pgsize ELSE 0 END),
+			SUM(CASE WHEN m.type = 'index' THEN s.pgsize ELSE 0 END)
+		FROM dbstat s
+		JOIN sqlite_master m ON m.name = s.name
+		WHERE m.tbl_name NOT LIKE 'sqlite_%'
+		GROUP BY m.tbl_name
+		ORDER BY m.tbl_name`)
+	sized = true
+	if err != nil && strings.Contains(err.Error(), "no such table: dbstat") {
+		sized = false
+		rows, err = db.db.QueryContext(ctx, `
+			SELECT name, 0, 0 FROM sqlite_master
+			WHERE type = 'table' AND name NOT LIKE 'sqlite_%'
+			ORDER BY name`)
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get table sizes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t models.TableStorage
+		if err := rows.Scan(&t.Name, &t.TableBytes, &t.IndexBytes); err != nil {
+			return nil, false, fmt.Errorf("failed to scan table size: %w", err)
+		}
+		tables = append(tables, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+
+	for i := range tables {
+		quoted := `"` + strings.ReplaceAll(tables[i].Name, `"`, `""`) + `"`
+		if err := db.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+quoted).Scan(&tables[i].Rows); err != nil {
+			return nil, false, fmt.Errorf("failed to count rows of %s: %w", tables[i].Name, err)
+		}
+	}
+	return tables, sized, nil
+}
+
+// RecordStorageSample stores today's table sizes, used to compute growth
+// rates, and prunes samples past their retention.
+func (db *DBWrapper) RecordStorageSample(ctx context.Context) error {
+	tables, _, err := db.tableStorage(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := db.clock.Now().UTC()
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	for _, t := range tables {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO storage_samples (table_name, day, row_count, table_bytes, index_bytes) VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (table_name, day) DO UPDATE SET
+				row_count = excluded.row_count,
+				table_bytes = excluded.table_bytes,
+				index_bytes = excluded.index_bytes`,
+			t.Name, now.Format(time.DateOnly), t.Rows, t.TableBytes, t.IndexBytes)
+		if err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to record storage sample for %s: %w", t.Name, err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM storage_samples WHERE day < ?",
+		now.Add(-storageSampleRetention).Format(time.DateOnly)); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to prune storage samples: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetStorageReport returns the size of the database file and of each table,
+// with daily growth rates relative to the oldest sample in the growth window.
+func (db *DBWrapper) GetStorageReport(ctx context.Context) (*models.StorageReport, error) {
+	report := &models.StorageReport{}
+	err := db.db.QueryRowContext(ctx, `
+		SELECT p.page_count * s.page_size, f.freelist_count * s.page_size
+		FROM pragma_page_count() p, pragma_freelist_count() f, pragma_page_size() s`).Scan(&report.DatabaseBytes, &report.FreeBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database size: %w", err)
+	}
+
+	var sized bool
+	if report.Tables, sized, err = db.tableStorage(ctx); err != nil {
+		return nil, err
+	}
+	report.SizesUnavailable = !sized
+
+	now := db.clock.Now().UTC()
+	rows, err := db.db.QueryContext(ctx, `
+		SELECT table_name, day, row_count, table_bytes + index_bytes
+		FROM storage_samples
+		WHERE day = (SELECT MIN(day) FROM storage_samples WHERE day >= ? AND day < ?)`,
+		now.Add(-storageGrowthWindow).Format(time.DateOnly), now.Format(time.DateOnly))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storage samples: %w", err)
+	}
+	defer rows.Close()
+
+	type sample struct {
+		days  float64
+		rows  int64
+		bytes int64
+	}
+	baseline := make(map[string]sample)
+	for rows.Next() {
+		var name, day string
+		var s sample
+		if err := rows.Scan(&name, &day, &s.rows, &s.bytes); err != nil {
+			return nil, fmt.Errorf("failed to scan storage sample: %w", err)
+		}
+		sampledAt, err := time.Parse(time.DateOnly, day)
+		if err != nil {
+			continue
+		}
+		s.days = now.Sub(sampledAt).Hours() / 24
+		baseline[name] = s
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range report.Tables {
+		t := &report.Tables[i]
+		base, ok := baseline[t.Name]
+		if !ok || base.days <= 0 {
+			continue
+		}
+		rowsPerDay := float64(t.Rows-base.rows) / base.days
+		t.GrowthRowsPerDay = &rowsPerDay
+		if sized {
+			bytesPerDay := float64(t.TableBytes+t.IndexBytes-base.bytes) / base.days
+			t.GrowthBytesPerDay = &bytesPerDay
+		}
+	}
+
+	return report, nil
+}
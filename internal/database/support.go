@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/gateixeira/live-actions/models"
+)
+
+// GetSchemaVersion returns the version of the latest applied migration.
+func (db *DBWrapper) GetSchemaVersion(ctx context.Context) (int, error) {
+	var version int
+	if err := db.db.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to get schema version: %w", err)
+	}
+	return version, nil
+}
+
+// GetEventSamples returns up to limit recently received webhook events,
+// failed ones first since they are the most useful in a bug report.
+func (db *DBWrapper) GetEventSamples(ctx context.Context, limit int) ([]models.EventSample, error) {
+	rows, err := db.db.QueryContext(ctx, `
+		SELECT event_type, status, github_timestamp, received_at, processed_at,
+			COALESCE(raw_payload, ''), ordering_key, status_priority
+		FROM webhook_events
+		ORDER BY status = 'failed' DESC, received_at DESC
+		LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event samples: %w", err)
+	}
+	defer rows.Close()
+
+	samples := []models.EventSample{}
+	for rows.Next() {
+		var s models.EventSample
+		var githubTimestamp, receivedAt, payload string
+		var processedAt sql.NullString
+		if err := rows.Scan(&s.EventType, &s.Status, &githubTimestamp, &receivedAt, &processedAt, &payload, &s.OrderingKey, &s.StatusPriority); err != nil {
+			return nil, fmt.Errorf("failed to scan event sample: %w", err)
+		}
+		s.GitHubTimestamp = parseTime(githubTimestamp)
+		s.ReceivedAt = parseTime(receivedAt)
+		if processedAt.Valid {
+			t := parseTime(processedAt.String)
+			s.ProcessedAt = &t
+		}
+		if json.Valid([]byte(payload)) {
+			s.Payload = json.RawMessage(payload)
+		}
+		samples = append(samples, s)
+	}
+	return samples, rows.Err()
+}
@@ -0,0 +1,151 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gateixeira/live-actions/models"
+)
+
+// AddRunTag attaches a tag to a workflow run. Returns false when the run does not exist.
+func (db *DBWrapper) AddRunTag(ctx context.Context, runID int64, tag string) (bool, error) {
+	var exists bool
+	err := db.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM workflow_runs WHERE id = ?)", runID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check workflow run: %w", err)
+	}
+	if !exists {
+		return false, nil
+	}
+
+	_, err = db.db.ExecContext(ctx,
+		"INSERT INTO run_tags (run_id, tag) VALUES (?, ?) ON CONFLICT (run_id, tag) DO NOTHING",
+		runID, tag)
+	if err != nil {
+		return false, fmt.Errorf("failed to add run tag: %w", err)
+	}
+	return true, nil
+}
+
+// RemoveRunTag detaches a tag from a workflow run. Returns false when the tag was not set.
+func (db *DBWrapper) RemoveRunTag(ctx context.Context, runID int64, tag string) (bool, error) {
+	result, err := db.db.ExecContext(ctx, "DELETE FROM run_tags WHERE run_id = ? AND tag = ?", runID, tag)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove run tag: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// GetRunTags returns the tags attached to a workflow run.
+func (db *DBWrapper) GetRunTags(ctx context.Context, runID int64) ([]string, error) {
+	tags, err := db.loadRunTags(ctx, []int64{runID})
+	if err != nil {
+		return nil, err
+	}
+	if tags[runID] == nil {
+		return []string{}, nil
+	}
+	return tags[runID], nil
+}
+
+// loadRunTags returns the tags for the given runs keyed by run ID.
+func (db *DBWrapper) loadRunTags(ctx context.Context, runIDs []int64) (map[int64][]string, error) {
+	tags := make(map[int64][]string)
+	if len(runIDs) == 0 {
+		return tags, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(runIDs)), ",")
+	args := make([]interface{}, len(runIDs))
+	for i, id := range runIDs {
+		args[i] = id
+	}
+
+	rows, err := db.db.QueryContext(ctx,
+		"SELECT run_id, tag FROM run_tags WHERE run_id IN ("+placeholders+") ORDER BY created_at ASC, tag ASC",
+		args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get run tags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var runID int64
+		var tag string
+		if err := rows.Scan(&runID, &tag); err != nil {
+			return nil, fmt.Errorf("failed to scan run tag: %w", err)
+		}
+		tags[runID] = append(tags[runID], tag)
+	}
+	return tags, rows.Err()
+}
+
+// GetSavedFilters returns all saved filter definitions ordered by name.
+func (db *DBWrapper) GetSavedFilters(ctx context.Context) ([]models.SavedFilter, error) {
+	rows, err := db.db.QueryContext(ctx,
+		"SELECT id, name, repository, status, created_at FROM saved_filters ORDER BY name ASC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saved filters: %w", err)
+	}
+	defer rows.Close()
+
+	filters := []models.SavedFilter{}
+	for rows.Next() {
+		var f models.SavedFilter
+		var createdAt string
+		if err := rows.Scan(&f.ID, &f.Name, &f.Repo, &f.Status, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan saved filter: %w", err)
+		}
+		f.CreatedAt = parseTime(createdAt)
+		filters = append(filters, f)
+	}
+	return filters, rows.Err()
+}
+
+// SaveFilter creates or replaces the saved filter with the given name and returns its ID.
+func (db *DBWrapper) SaveFilter(ctx context.Context, filter models.SavedFilter) (int64, error) {
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// The ID is read back rather than returned by the upsert: RETURNING needs
+	// SQLite 3.35, newer than the one bundled with the SQLCipher driver
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO saved_filters (name, repository, status) VALUES (?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET
+			repository = excluded.repository,
+			status = excluded.status`,
+		filter.Name, filter.Repo, filter.Status)
+	if err != nil {
+		return 0, fmt.Errorf("failed to save filter: %w", err)
+	}
+
+	var id int64
+	if err := tx.QueryRowContext(ctx, "SELECT id FROM saved_filters WHERE name = ?", filter.Name).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to get saved filter ID: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit saved filter: %w", err)
+	}
+	return id, nil
+}
+
+// DeleteSavedFilter removes a saved filter. Returns false when it did not exist.
+func (db *DBWrapper) DeleteSavedFilter(ctx context.Context, id int64) (bool, error) {
+	result, err := db.db.ExecContext(ctx, "DELETE FROM saved_filters WHERE id = ?", id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete saved filter: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
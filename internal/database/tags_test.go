@@ -0,0 +1,26 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveFilter(t *testing.T) {
+	db := newTestDB(t, "")
+	ctx := context.Background()
+
+	id, err := db.SaveFilter(ctx, models.SavedFilter{Name: "failing", Repo: "api", Status: "failure"})
+	require.NoError(t, err)
+	replaced, err := db.SaveFilter(ctx, models.SavedFilter{Name: "failing", Repo: "web", Status: "failure"})
+	require.NoError(t, err)
+	assert.Equal(t, id, replaced, "saving an existing name replaces the filter")
+
+	filters, err := db.GetSavedFilters(ctx)
+	require.NoError(t, err)
+	require.Len(t, filters, 1)
+	assert.Equal(t, "web", filters[0].Repo)
+}
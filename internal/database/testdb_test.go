@@ -0,0 +1,29 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+// testEncryptionKey encrypts the databases of tests that do not pass a key.
+// Builds with the sqlcipher tag set it, so the whole suite runs against
+// encrypted files.
+var testEncryptionKey string
+
+// newTestDB returns a migrated database in a temporary file, encrypted with
+// key, or testEncryptionKey when it is empty.
+func newTestDB(t *testing.T, key string) *DBWrapper {
+	t.Helper()
+	logger.InitLogger("error")
+	if key == "" {
+		key = testEncryptionKey
+	}
+
+	sqlDB, err := InitDB(filepath.Join(t.TempDir(), "live-actions.db"), key, MigrationOptions{})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	return NewDBWrapper(sqlDB).(*DBWrapper)
+}
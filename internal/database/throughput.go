@@ -0,0 +1,86 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+)
+
+// throughputColumns count the jobs that started and the jobs that completed
+// within [from, to). Jobs only count as started once in progress or
+// completed, whatever their started_at.
+const throughputColumns = `
+			SUM(CASE WHEN j.status IN ('in_progress', 'completed') AND j.started_at >= ? AND j.started_at < ? THEN 1 ELSE 0 END) AS started,
+			SUM(CASE WHEN j.status = 'completed' AND j.completed_at >= ? AND j.completed_at < ? THEN 1 ELSE 0 END) AS completed`
+
+// throughputWindow matches the jobs that may have started or completed
+// within [from, to).
+const throughputWindow = `((j.started_at >= ? AND j.started_at < ?) OR (j.completed_at >= ? AND j.completed_at < ?))`
+
+// throughputArgs returns the arguments of throughputColumns followed by those
+// of throughputWindow and repoArgs.
+func throughputArgs(from, to time.Time, repoArgs []interface{}) []interface{} {
+	start, end := from.UTC().Format(time.RFC3339), to.UTC().Format(time.RFC3339)
+	return append([]interface{}{start, end, start, end, start, end, start, end}, repoArgs...)
+}
+
+// GetLabelThroughput counts per label the jobs that started and the jobs that
+// completed within [from, to), and their rates per minute over it, ordered by
+// label. A job counts under every one of its labels, so the labels add up to
+// more than GetJobThroughput. If repos is non-empty, filters to those
+// repositories.
+func (db *DBWrapper) GetLabelThroughput(ctx context.Context, from, to time.Time, repos []string) ([]models.LabelThroughput, error) {
+	repoJoin, repoArgs := jobRepoFilter(repos)
+
+	rows, err := db.db.QueryContext(ctx, `
+		SELECT
+			l.value AS label,`+throughputColumns+`
+		FROM workflow_jobs j, json_each(j.labels) l`+repoJoin+`
+		WHERE `+throughputWindow+repoWhere(repos)+`
+		GROUP BY l.value
+		ORDER BY l.value`, throughputArgs(from, to, repoArgs)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get label throughput: %w", err)
+	}
+	defer rows.Close()
+
+	results := []models.LabelThroughput{}
+	for rows.Next() {
+		var t models.LabelThroughput
+		if err := rows.Scan(&t.Label, &t.Started, &t.Completed); err != nil {
+			return nil, fmt.Errorf("failed to scan label throughput: %w", err)
+		}
+		setThroughputRates(&t, from, to)
+		results = append(results, t)
+	}
+	return results, rows.Err()
+}
+
+// GetJobThroughput counts the jobs that started and the jobs that completed
+// within [from, to), each once whatever its labels, and their rates per
+// minute over it. If repos is non-empty, filters to those repositories.
+func (db *DBWrapper) GetJobThroughput(ctx context.Context, from, to time.Time, repos []string) (models.LabelThroughput, error) {
+	repoJoin, repoArgs := jobRepoFilter(repos)
+
+	var t models.LabelThroughput
+	err := db.db.QueryRowContext(ctx, `
+		SELECT
+			COALESCE(started, 0), COALESCE(completed, 0)
+		FROM (SELECT`+throughputColumns+`
+			FROM workflow_jobs j`+repoJoin+`
+			WHERE `+throughputWindow+repoWhere(repos)+`)`, throughputArgs(from, to, repoArgs)...).Scan(&t.Started, &t.Completed)
+	if err != nil {
+		return t, fmt.Errorf("failed to get job throughput: %w", err)
+	}
+	setThroughputRates(&t, from, to)
+	return t, nil
+}
+
+func setThroughputRates(t *models.LabelThroughput, from, to time.Time) {
+	if minutes := to.Sub(from).Minutes(); minutes > 0 {
+		t.StartedPerMinute = float64(t.Started) / minutes
+		t.CompletedPerMinute = float64(t.Completed) / minutes
+	}
+}
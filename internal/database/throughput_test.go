@@ -0,0 +1,52 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetLabelThroughput(t *testing.T) {
+	db := newTestDB(t, "")
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	jobs := []models.WorkflowJob{
+		{ID: 1, Status: models.JobStatusInProgress, Labels: []string{"self-hosted", "gpu"}, StartedAt: now.Add(-2 * time.Minute)},
+		{ID: 2, Status: models.JobStatusCompleted, Labels: []string{"self-hosted", "linux"}, StartedAt: now.Add(-8 * time.Minute), CompletedAt: now.Add(-time.Minute)},
+		{ID: 3, Status: models.JobStatusQueued, Labels: []string{"self-hosted", "linux"}, StartedAt: now.Add(-3 * time.Minute)},
+		{ID: 4, Status: models.JobStatusCompleted, Labels: []string{"ubuntu-latest"}, StartedAt: now.Add(-time.Hour), CompletedAt: now.Add(-50 * time.Minute)},
+	}
+	for _, job := range jobs {
+		job.RunID, job.Name, job.CreatedAt = 1, "build", now.Add(-time.Hour)
+		_, err := db.AddOrUpdateJob(ctx, job, now)
+		require.NoError(t, err)
+	}
+
+	from := now.Add(-10 * time.Minute)
+	labels, err := db.GetLabelThroughput(ctx, from, now, nil)
+	require.NoError(t, err)
+	byLabel := make(map[string]models.LabelThroughput)
+	for _, l := range labels {
+		byLabel[l.Label] = l
+	}
+	assert.Len(t, byLabel, 3, "jobs outside the window are left out")
+	assert.Equal(t, 2, byLabel["self-hosted"].Started, "jobs count under every label")
+	assert.Equal(t, 1, byLabel["self-hosted"].Completed)
+	assert.Equal(t, 1, byLabel["linux"].Started, "queued jobs have not started")
+	assert.Equal(t, 1, byLabel["gpu"].Started)
+	assert.Equal(t, 0.2, byLabel["self-hosted"].StartedPerMinute)
+
+	total, err := db.GetJobThroughput(ctx, from, now, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total.Started, "jobs count once in the total")
+	assert.Equal(t, 1, total.Completed)
+	assert.Equal(t, 0.1, total.CompletedPerMinute)
+
+	total, err = db.GetJobThroughput(ctx, now.Add(time.Hour), now.Add(2*time.Hour), nil)
+	require.NoError(t, err)
+	assert.Zero(t, total.Started)
+}
@@ -0,0 +1,73 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+)
+
+// GetUnschedulableJobs returns jobs queued for longer than queuedFor that
+// request a runner label no job has ever run on, which usually means a typo in
+// runs-on. Returns nothing until at least one job has run, so a fresh install
+// does not flag every queued job. If repos is non-empty, filters to those
+// repositories.
+func (db *DBWrapper) GetUnschedulableJobs(ctx context.Context, queuedFor time.Duration, repos []string) ([]models.UnschedulableJob, error) {
+	cutoff := db.clock.Now().Add(-queuedFor).UTC().Format(time.RFC3339)
+
+	args := append([]interface{}{cutoff}, repoArgs(repos)...)
+
+	// Jobs cancelled while still queued never reached a runner, so their
+	// labels do not count as known.
+	rows, err := db.db.QueryContext(ctx, `
+		WITH known AS (
+			SELECT DISTINCT l.value AS label
+			FROM workflow_jobs c, json_each(c.labels) l
+			WHERE c.status = 'completed' AND c.conclusion NOT IN ('cancelled', 'skipped', '')
+		)
+		SELECT j.id, j.name, j.run_id, j.labels, j.html_url, j.created_at, r.repository, r.html_url, l.value
+		FROM workflow_jobs j
+		JOIN json_each(j.labels) l
+		LEFT JOIN workflow_runs r ON r.id = j.run_id
+		WHERE j.status = 'queued' AND j.created_at < ?`+repoWhere(repos)+`
+			AND EXISTS (SELECT 1 FROM known)
+			AND l.value NOT IN (SELECT label FROM known)
+		ORDER BY j.created_at ASC, j.id ASC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unschedulable jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []models.UnschedulableJob
+	for rows.Next() {
+		var job models.UnschedulableJob
+		var labelsJSON, unknownLabel, createdAt string
+		var htmlURL, repository, runURL sql.NullString
+		if err := rows.Scan(&job.ID, &job.Name, &job.RunID, &labelsJSON, &htmlURL, &createdAt, &repository, &runURL, &unknownLabel); err != nil {
+			return nil, fmt.Errorf("failed to scan unschedulable job: %w", err)
+		}
+		// A job appears once per unknown label
+		if n := len(jobs); n > 0 && jobs[n-1].ID == job.ID {
+			jobs[n-1].UnknownLabels = append(jobs[n-1].UnknownLabels, unknownLabel)
+			continue
+		}
+		job.Labels = labelsFromJSON(labelsJSON)
+		job.UnknownLabels = []string{unknownLabel}
+		job.HtmlUrl = htmlURL.String
+		job.CreatedAt = parseTime(createdAt)
+		job.Repository = repository.String
+		job.RunHtmlUrl = runURL.String
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if jobs == nil {
+		jobs = []models.UnschedulableJob{}
+	}
+
+	return jobs, nil
+}
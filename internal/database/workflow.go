@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/gateixeira/live-actions/models"
@@ -47,25 +48,28 @@ func (db *DBWrapper) AddOrUpdateJob(ctx context.Context, workflowJob models.Work
 		return false, fmt.Errorf("failed to start transaction: %w", err)
 	}
 
-	var isTerminal bool
+	var isTerminal, wasStarted bool
 	err = tx.QueryRow(`
-		SELECT CASE WHEN status IN ('completed', 'cancelled', 'stale') THEN 1 ELSE 0 END
+		SELECT CASE WHEN status IN ('completed', 'cancelled', 'stale') THEN 1 ELSE 0 END,
+			CASE WHEN started_at IS NOT NULL AND status IN ('in_progress', 'completed') THEN 1 ELSE 0 END
 		FROM workflow_jobs 
-		WHERE id = ?`, workflowJob.ID).Scan(&isTerminal)
+		WHERE id = ?`, workflowJob.ID).Scan(&isTerminal, &wasStarted)
 
 	if err != nil && err != sql.ErrNoRows {
 		_ = tx.Rollback()
 		return false, fmt.Errorf("failed to check terminal state: %w", err)
 	}
+	seen := err == nil
 
-	if err == nil && isTerminal {
+	if seen && isTerminal {
 		_ = tx.Rollback()
 		return false, nil
 	}
 
 	_, err = tx.Exec(
-		`INSERT INTO workflow_jobs (id, name, status, labels, html_url, conclusion, created_at, started_at, completed_at, updated_at, run_id) 
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'), ?)
+		`INSERT INTO workflow_jobs (id, name, status, labels, html_url, conclusion, created_at, started_at, completed_at, updated_at, run_id,
+		runner_name, runner_group_id, runner_group_name) 
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'), ?, ?, ?, ?)
 		ON CONFLICT (id) DO UPDATE SET
 			name = excluded.name,
 			status = excluded.status,
@@ -76,9 +80,13 @@ func (db *DBWrapper) AddOrUpdateJob(ctx context.Context, workflowJob models.Work
 			started_at = excluded.started_at,
 			completed_at = excluded.completed_at,
 			updated_at = datetime('now'),
-			run_id = excluded.run_id`,
+			run_id = excluded.run_id,
+			runner_name = excluded.runner_name,
+			runner_group_id = excluded.runner_group_id,
+			runner_group_name = excluded.runner_group_name`,
 		workflowJob.ID, string(workflowJob.Name), string(workflowJob.Status), labelsToJSON(workflowJob.Labels),
 		workflowJob.HtmlUrl, string(workflowJob.Conclusion), workflowJob.CreatedAt.Format(time.RFC3339), formatNullableTime(workflowJob.StartedAt), formatNullableTime(workflowJob.CompletedAt), workflowJob.RunID,
+		workflowJob.RunnerName, workflowJob.RunnerGroupID, workflowJob.RunnerGroupName,
 	)
 
 	if err != nil {
@@ -86,6 +94,11 @@ func (db *DBWrapper) AddOrUpdateJob(ctx context.Context, workflowJob models.Work
 		return false, fmt.Errorf("failed to execute upsert: %w", err)
 	}
 
+	if err = recordJobCounters(ctx, tx, seen, wasStarted, workflowJob, db.clock.Now()); err != nil {
+		_ = tx.Rollback()
+		return false, err
+	}
+
 	if err = tx.Commit(); err != nil {
 		return false, fmt.Errorf("failed to commit transaction: %w", err)
 	}
@@ -99,11 +112,14 @@ func (db *DBWrapper) AddOrUpdateRun(ctx context.Context, workflowRun models.Work
 		return false, fmt.Errorf("failed to start transaction: %w", err)
 	}
 
+	// A re-run starts a new attempt of a finished run, which reopens it;
+	// events of earlier attempts are then stale
+	runAttempt := max(workflowRun.RunAttempt, 1)
 	var isTerminal bool
 	err = tx.QueryRow(`
-		SELECT CASE WHEN status IN ('completed', 'cancelled') THEN 1 ELSE 0 END
+		SELECT CASE WHEN run_attempt > ? OR (status IN ('completed', 'cancelled') AND run_attempt = ?) THEN 1 ELSE 0 END
 		FROM workflow_runs 
-		WHERE id = ?`, workflowRun.ID).Scan(&isTerminal)
+		WHERE id = ?`, runAttempt, runAttempt, workflowRun.ID).Scan(&isTerminal)
 
 	if err != nil && err != sql.ErrNoRows {
 		_ = tx.Rollback()
@@ -115,10 +131,17 @@ func (db *DBWrapper) AddOrUpdateRun(ctx context.Context, workflowRun models.Work
 		return false, nil
 	}
 
+	var commit models.HeadCommit
+	if workflowRun.HeadCommit != nil {
+		commit = *workflowRun.HeadCommit
+	}
+
 	_, err = tx.Exec(
 		`INSERT INTO workflow_runs (id, name, status, repository,
-		html_url, display_title, conclusion, created_at, run_started_at, updated_at) 
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		html_url, display_title, conclusion, created_at, run_started_at, updated_at,
+		head_sha, head_commit_message, head_commit_author, head_commit_author_email,
+		actor, triggering_actor, run_attempt, workflow_path) 
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT (id) DO UPDATE SET
 			name = excluded.name,
 			status = excluded.status,
@@ -128,10 +151,20 @@ func (db *DBWrapper) AddOrUpdateRun(ctx context.Context, workflowRun models.Work
 			conclusion = excluded.conclusion,
 			created_at = excluded.created_at,
 			run_started_at = excluded.run_started_at,
-			updated_at = excluded.updated_at`,
+			updated_at = excluded.updated_at,
+			head_sha = excluded.head_sha,
+			head_commit_message = excluded.head_commit_message,
+			head_commit_author = excluded.head_commit_author,
+			head_commit_author_email = excluded.head_commit_author_email,
+			actor = CASE WHEN excluded.actor != '' THEN excluded.actor ELSE actor END,
+			triggering_actor = CASE WHEN excluded.triggering_actor != '' THEN excluded.triggering_actor ELSE triggering_actor END,
+			run_attempt = excluded.run_attempt,
+			workflow_path = CASE WHEN excluded.workflow_path != '' THEN excluded.workflow_path ELSE workflow_path END`,
 		workflowRun.ID, string(workflowRun.Name), string(workflowRun.Status), string(workflowRun.RepositoryName),
 		string(workflowRun.HtmlUrl), string(workflowRun.DisplayTitle), string(workflowRun.Conclusion),
 		workflowRun.CreatedAt.Format(time.RFC3339), formatNullableTime(workflowRun.RunStartedAt), formatNullableTime(workflowRun.UpdatedAt),
+		workflowRun.HeadSha, commit.Message, commit.Author.Name, commit.Author.Email,
+		workflowRun.Actor, workflowRun.TriggeringActor, runAttempt, workflowRun.WorkflowPath,
 	)
 
 	if err != nil {
@@ -139,6 +172,11 @@ func (db *DBWrapper) AddOrUpdateRun(ctx context.Context, workflowRun models.Work
 		return false, fmt.Errorf("failed to execute upsert: %w", err)
 	}
 
+	if err := attributePendingJobCounters(ctx, tx, workflowRun.ID, workflowRun.RepositoryName); err != nil {
+		_ = tx.Rollback()
+		return false, err
+	}
+
 	if err = tx.Commit(); err != nil {
 		return false, fmt.Errorf("failed to commit transaction: %w", err)
 	}
@@ -146,17 +184,28 @@ func (db *DBWrapper) AddOrUpdateRun(ctx context.Context, workflowRun models.Work
 	return true, nil
 }
 
+// prefixEnd returns the smallest string greater than every string starting
+// with prefix, which must not end in byte 0xff.
+func prefixEnd(prefix string) string {
+	end := []byte(prefix)
+	end[len(end)-1]++
+	return string(end)
+}
+
 // GetWorkflowRunsPaginated retrieves workflow runs with pagination support.
-// If repo is non-empty, results are filtered to that repository.
+// If repos is non-empty, results are filtered to those repositories.
 // If status is non-empty, results are filtered to that status/conclusion.
-func (db *DBWrapper) GetWorkflowRunsPaginated(ctx context.Context, page int, limit int, repo string, status string) ([]models.WorkflowRun, int, error) {
+// If sha is non-empty, results are filtered to runs whose head commit starts with it.
+func (db *DBWrapper) GetWorkflowRunsPaginated(ctx context.Context, page int, limit int, repos []string, status string, sha string) ([]models.WorkflowRun, int, error) {
 	offset := (page - 1) * limit
 
-	where := "WHERE 1=1"
-	var args []interface{}
-	if repo != "" {
-		where += " AND repository = ?"
-		args = append(args, repo)
+	where := "WHERE 1=1" + repoIn("repository", repos)
+	args := repoArgs(repos)
+	if sha != "" {
+		// A range rather than LIKE, which is case-insensitive and so cannot use the index
+		prefix := strings.ToLower(sha)
+		where += " AND head_sha >= ? AND head_sha < ?"
+		args = append(args, prefix, prefixEnd(prefix))
 	}
 	if status != "" {
 		switch status {
@@ -184,7 +233,7 @@ func (db *DBWrapper) GetWorkflowRunsPaginated(ctx context.Context, page int, lim
 
 	queryArgs := append(args, limit, offset)
 	rows, err := db.db.QueryContext(ctx,
-		"SELECT id, name, status, repository, html_url, display_title, conclusion, created_at, run_started_at, updated_at FROM workflow_runs "+where+" ORDER BY created_at DESC LIMIT ? OFFSET ?",
+		"SELECT id, name, status, repository, html_url, display_title, conclusion, created_at, run_started_at, updated_at, head_sha, head_commit_message, head_commit_author, head_commit_author_email, actor, triggering_actor, run_attempt, workflow_path FROM workflow_runs "+where+" ORDER BY created_at DESC LIMIT ? OFFSET ?",
 		queryArgs...)
 	if err != nil {
 		return nil, 0, err
@@ -195,12 +244,18 @@ func (db *DBWrapper) GetWorkflowRunsPaginated(ctx context.Context, page int, lim
 	for rows.Next() {
 		var run models.WorkflowRun
 		var createdAt, startedAt, updatedAt sql.NullString
-		if err := rows.Scan(&run.ID, &run.Name, &run.Status, &run.RepositoryName, &run.HtmlUrl, &run.DisplayTitle, &run.Conclusion, &createdAt, &startedAt, &updatedAt); err != nil {
+		var commit models.HeadCommit
+		if err := rows.Scan(&run.ID, &run.Name, &run.Status, &run.RepositoryName, &run.HtmlUrl, &run.DisplayTitle, &run.Conclusion, &createdAt, &startedAt, &updatedAt,
+			&run.HeadSha, &commit.Message, &commit.Author.Name, &commit.Author.Email, &run.Actor, &run.TriggeringActor, &run.RunAttempt, &run.WorkflowPath); err != nil {
 			return nil, 0, err
 		}
 		run.CreatedAt = parseTime(createdAt.String)
 		run.RunStartedAt = parseTime(startedAt.String)
 		run.UpdatedAt = parseTime(updatedAt.String)
+		if run.HeadSha != "" {
+			commit.ID = run.HeadSha
+			run.HeadCommit = &commit
+		}
 		runs = append(runs, run)
 	}
 
@@ -208,6 +263,18 @@ func (db *DBWrapper) GetWorkflowRunsPaginated(ctx context.Context, page int, lim
 		return nil, 0, err
 	}
 
+	runIDs := make([]int64, len(runs))
+	for i, run := range runs {
+		runIDs[i] = run.ID
+	}
+	tags, err := db.loadRunTags(ctx, runIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range runs {
+		runs[i].Tags = tags[runs[i].ID]
+	}
+
 	return runs, totalCount, nil
 }
 
@@ -237,13 +304,81 @@ func (db *DBWrapper) GetRepositories(ctx context.Context) ([]string, error) {
 	return repos, nil
 }
 
+// GetWorkflowRunByID returns a single workflow run, or nil when it does not exist.
+func (db *DBWrapper) GetWorkflowRunByID(ctx context.Context, runID int64) (*models.WorkflowRun, error) {
+	var run models.WorkflowRun
+	var createdAt, startedAt, updatedAt sql.NullString
+	var repository, htmlURL, displayTitle, conclusion sql.NullString
+	var commit models.HeadCommit
+	err := db.db.QueryRowContext(ctx,
+		"SELECT id, name, status, repository, html_url, display_title, conclusion, created_at, run_started_at, updated_at, head_sha, head_commit_message, head_commit_author, head_commit_author_email, actor, triggering_actor, run_attempt, workflow_path FROM workflow_runs WHERE id = ?",
+		runID).Scan(&run.ID, &run.Name, &run.Status, &repository, &htmlURL, &displayTitle, &conclusion, &createdAt, &startedAt, &updatedAt,
+		&run.HeadSha, &commit.Message, &commit.Author.Name, &commit.Author.Email, &run.Actor, &run.TriggeringActor, &run.RunAttempt, &run.WorkflowPath)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get workflow run: %w", err)
+	}
+	run.RepositoryName = repository.String
+	run.HtmlUrl = htmlURL.String
+	run.DisplayTitle = displayTitle.String
+	run.Conclusion = conclusion.String
+	run.CreatedAt = parseTime(createdAt.String)
+	run.RunStartedAt = parseTime(startedAt.String)
+	run.UpdatedAt = parseTime(updatedAt.String)
+	if run.HeadSha != "" {
+		commit.ID = run.HeadSha
+		run.HeadCommit = &commit
+	}
+	return &run, nil
+}
+
+// workflowJobColumns are the workflow_jobs columns read by scanWorkflowJobs.
+const workflowJobColumns = "id, name, run_id, status, labels, html_url, conclusion, created_at, started_at, completed_at, runner_name, runner_group_id, runner_group_name"
+
 func (db *DBWrapper) GetWorkflowJobsByRunID(ctx context.Context, runID int64) ([]models.WorkflowJob, error) {
-	rows, err := db.db.QueryContext(ctx, "SELECT id, name, run_id, status, labels, html_url, conclusion, created_at, started_at, completed_at FROM workflow_jobs WHERE run_id = ? ORDER BY created_at DESC", runID)
+	rows, err := db.db.QueryContext(ctx, "SELECT "+workflowJobColumns+" FROM workflow_jobs WHERE run_id = ? ORDER BY created_at DESC", runID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	return scanWorkflowJobs(rows)
+}
+
+// GetWorkflowJobsByRunIDs returns the jobs of several runs in one query,
+// grouped by run ID, newest first. Runs without jobs are left out.
+func (db *DBWrapper) GetWorkflowJobsByRunIDs(ctx context.Context, runIDs []int64) (map[int64][]models.WorkflowJob, error) {
+	byRun := make(map[int64][]models.WorkflowJob)
+	if len(runIDs) == 0 {
+		return byRun, nil
+	}
+
+	args := make([]interface{}, len(runIDs))
+	for i, id := range runIDs {
+		args[i] = id
+	}
+	rows, err := db.db.QueryContext(ctx,
+		"SELECT "+workflowJobColumns+" FROM workflow_jobs WHERE run_id IN (?"+strings.Repeat(", ?", len(runIDs)-1)+") ORDER BY created_at DESC",
+		args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs, err := scanWorkflowJobs(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan workflow jobs: %w", err)
+	}
+	for _, job := range jobs {
+		byRun[job.RunID] = append(byRun[job.RunID], job)
+	}
+	return byRun, nil
+}
+
+// scanWorkflowJobs reads rows selecting workflowJobColumns.
+func scanWorkflowJobs(rows *sql.Rows) ([]models.WorkflowJob, error) {
 	var jobs []models.WorkflowJob
 	for rows.Next() {
 		var job models.WorkflowJob
@@ -251,7 +386,7 @@ func (db *DBWrapper) GetWorkflowJobsByRunID(ctx context.Context, runID int64) ([
 		var createdAt string
 		var htmlUrl sql.NullString
 		var startedAt, completedAt sql.NullString
-		if err := rows.Scan(&job.ID, &job.Name, &job.RunID, &job.Status, &labelsJSON, &htmlUrl, &job.Conclusion, &createdAt, &startedAt, &completedAt); err != nil {
+		if err := rows.Scan(&job.ID, &job.Name, &job.RunID, &job.Status, &labelsJSON, &htmlUrl, &job.Conclusion, &createdAt, &startedAt, &completedAt, &job.RunnerName, &job.RunnerGroupID, &job.RunnerGroupName); err != nil {
 			return nil, err
 		}
 		job.Labels = labelsFromJSON(labelsJSON)
@@ -279,12 +414,12 @@ func (db *DBWrapper) GetWorkflowJobByID(ctx context.Context, jobID int64) (model
 
 	err := db.db.QueryRowContext(ctx, `
 		SELECT id, name, run_id, status, labels, html_url, conclusion, 
-			   created_at, started_at, completed_at 
+			   created_at, started_at, completed_at, runner_name, runner_group_id, runner_group_name 
 		FROM workflow_jobs 
 		WHERE id = ?`, jobID).Scan(
 		&job.ID, &job.Name, &job.RunID, &job.Status,
 		&labelsJSON, &htmlUrl, &job.Conclusion, &createdAt,
-		&startedAt, &completedAt)
+		&startedAt, &completedAt, &job.RunnerName, &job.RunnerGroupID, &job.RunnerGroupName)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -304,7 +439,7 @@ func (db *DBWrapper) GetWorkflowJobByID(ctx context.Context, jobID int64) (model
 
 // CleanupOldData removes workflow runs and jobs older than the retention period
 func (db *DBWrapper) CleanupOldData(ctx context.Context, retentionPeriod time.Duration) (int64, int64, int64, error) {
-	cutoffTime := time.Now().Add(-retentionPeriod).Format(time.RFC3339)
+	cutoffTime := db.clock.Now().Add(-retentionPeriod).Format(time.RFC3339)
 
 	tx, err := db.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -347,11 +482,61 @@ func (db *DBWrapper) CleanupOldData(ctx context.Context, retentionPeriod time.Du
 		return 0, 0, 0, fmt.Errorf("failed to get affected events count: %w", err)
 	}
 
+	if _, err := tx.Exec("DELETE FROM mutes WHERE muted_until < ?", cutoffTime); err != nil {
+		return 0, 0, 0, fmt.Errorf("failed to delete expired mutes: %w", err)
+	}
+
+	if _, err := tx.Exec("DELETE FROM run_tags WHERE run_id NOT IN (SELECT id FROM workflow_runs)"); err != nil {
+		return 0, 0, 0, fmt.Errorf("failed to delete orphaned run tags: %w", err)
+	}
+
+	if _, err := tx.Exec("DELETE FROM sampled_job_counters WHERE hour < ?", cutoffTime); err != nil {
+		return 0, 0, 0, fmt.Errorf("failed to delete old sampled job counters: %w", err)
+	}
+
+	if _, err := tx.Exec("DELETE FROM job_counters WHERE day < ?", counterDay(db.clock.Now().Add(-retentionPeriod))); err != nil {
+		return 0, 0, 0, fmt.Errorf("failed to delete old job counters: %w", err)
+	}
+
+	if _, err := tx.Exec("DELETE FROM pending_job_counters WHERE day < ?", counterDay(db.clock.Now().Add(-retentionPeriod))); err != nil {
+		return 0, 0, 0, fmt.Errorf("failed to delete old pending job counters: %w", err)
+	}
+
+	if _, err := tx.Exec("DELETE FROM canary_results WHERE dispatched_at < ?", cutoffTime); err != nil {
+		return 0, 0, 0, fmt.Errorf("failed to delete old canary results: %w", err)
+	}
+
+	if _, err := tx.Exec("DELETE FROM slo_breaches WHERE started_at < ?", cutoffTime); err != nil {
+		return 0, 0, 0, fmt.Errorf("failed to delete old SLO breaches: %w", err)
+	}
+
+	if _, err := tx.Exec("DELETE FROM host_metrics WHERE timestamp < ?", cutoffTime); err != nil {
+		return 0, 0, 0, fmt.Errorf("failed to delete old host metrics: %w", err)
+	}
+
+	if _, err := tx.Exec("DELETE FROM job_approvals WHERE updated_at < ?", cutoffTime); err != nil {
+		return 0, 0, 0, fmt.Errorf("failed to delete old job approvals: %w", err)
+	}
+
+	if _, err := tx.Exec("DELETE FROM label_capacity_samples WHERE timestamp < ?", cutoffTime); err != nil {
+		return 0, 0, 0, fmt.Errorf("failed to delete old label capacity samples: %w", err)
+	}
+
+	// Commit times are stored in UTC
+	if _, err := tx.Exec("DELETE FROM workflow_file_changes WHERE committed_at < ?",
+		db.clock.Now().Add(-retentionPeriod).UTC().Format(time.RFC3339)); err != nil {
+		return 0, 0, 0, fmt.Errorf("failed to delete old workflow file changes: %w", err)
+	}
+
 	// Clean up old metrics snapshots
 	if _, err := tx.Exec("DELETE FROM metrics_snapshots WHERE timestamp < ?", cutoffTime); err != nil {
 		return 0, 0, 0, fmt.Errorf("failed to delete old metrics snapshots: %w", err)
 	}
 
+	if _, err := tx.Exec("DELETE FROM metrics_snapshot_rollups WHERE bucket_start < ?", cutoffTime); err != nil {
+		return 0, 0, 0, fmt.Errorf("failed to delete old metrics snapshot rollups: %w", err)
+	}
+
 	if err := tx.Commit(); err != nil {
 		return 0, 0, 0, fmt.Errorf("failed to commit cleanup transaction: %w", err)
 	}
@@ -364,13 +549,13 @@ func (db *DBWrapper) CleanupOldData(ctx context.Context, retentionPeriod time.Du
 // for longer than the given threshold as 'stale'. This handles cases
 // where webhook events were missed and jobs are left in a non-terminal state.
 func (db *DBWrapper) CleanupStaleJobs(ctx context.Context, threshold time.Duration) (int64, error) {
-	cutoffTime := time.Now().Add(-threshold).Format(time.RFC3339)
+	cutoffTime := db.clock.Now().Add(-threshold).Format(time.RFC3339)
 
 	result, err := db.db.ExecContext(ctx, `
 		UPDATE workflow_jobs
-		SET status = 'stale', completed_at = CURRENT_TIMESTAMP
+		SET status = 'stale', completed_at = ?
 		WHERE status IN ('queued', 'in_progress')
-		AND created_at < ?`, cutoffTime)
+		AND created_at < ?`, db.clock.Now().UTC().Format(time.RFC3339), cutoffTime)
 	if err != nil {
 		return 0, fmt.Errorf("failed to mark stale jobs: %w", err)
 	}
@@ -0,0 +1,131 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/utils"
+	"github.com/gateixeira/live-actions/models"
+)
+
+// GetWorkflowFiles returns the workflow files runs were created from within
+// the window, ordered by repository and path.
+func (db *DBWrapper) GetWorkflowFiles(ctx context.Context, since time.Duration) ([]models.WorkflowFile, error) {
+	cutoff := db.clock.Now().Add(-since).Format(time.RFC3339)
+
+	rows, err := db.db.QueryContext(ctx, `
+		SELECT repository, workflow_path, MAX(html_url)
+		FROM workflow_runs
+		WHERE workflow_path != '' AND repository != '' AND julianday(created_at) >= julianday(?)
+		GROUP BY repository, workflow_path
+		ORDER BY repository ASC, workflow_path ASC`, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow files: %w", err)
+	}
+	defer rows.Close()
+
+	files := []models.WorkflowFile{}
+	for rows.Next() {
+		var f models.WorkflowFile
+		var htmlURL sql.NullString
+		if err := rows.Scan(&f.Repository, &f.Path, &htmlURL); err != nil {
+			return nil, fmt.Errorf("failed to scan workflow file: %w", err)
+		}
+		f.FullName = utils.RepoFullName(htmlURL.String, f.Repository)
+		files = append(files, f)
+	}
+	return files, rows.Err()
+}
+
+// SaveWorkflowFileChanges stores commits that changed workflow files,
+// ignoring those already stored.
+func (db *DBWrapper) SaveWorkflowFileChanges(ctx context.Context, changes []models.WorkflowFileChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT OR IGNORE INTO workflow_file_changes (repository, path, sha, message, author, html_url, committed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare workflow file change insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, c := range changes {
+		if _, err := stmt.ExecContext(ctx, c.Repository, c.Path, c.SHA, c.Message, c.Author, c.HtmlUrl,
+			c.CommittedAt.UTC().Format(time.RFC3339)); err != nil {
+			return fmt.Errorf("failed to save workflow file change: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// GetWorkflowFileChanges returns the workflow file changes committed within
+// the window, oldest first, optionally filtered to repositories.
+func (db *DBWrapper) GetWorkflowFileChanges(ctx context.Context, since time.Duration, repos []string) ([]models.WorkflowFileChange, error) {
+	cutoff := db.clock.Now().Add(-since).Format(time.RFC3339)
+	args := append([]interface{}{cutoff}, repoArgs(repos)...)
+
+	rows, err := db.db.QueryContext(ctx, `
+		SELECT repository, path, sha, message, author, html_url, committed_at
+		FROM workflow_file_changes
+		WHERE julianday(committed_at) >= julianday(?)`+repoIn("repository", repos)+`
+		ORDER BY committed_at ASC, sha ASC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow file changes: %w", err)
+	}
+	defer rows.Close()
+
+	changes := []models.WorkflowFileChange{}
+	for rows.Next() {
+		var c models.WorkflowFileChange
+		var committedAt string
+		if err := rows.Scan(&c.Repository, &c.Path, &c.SHA, &c.Message, &c.Author, &c.HtmlUrl, &committedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan workflow file change: %w", err)
+		}
+		c.CommittedAt = parseTime(committedAt)
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
+// GetRunOutcomes returns the completed runs created within the window whose
+// workflow file is known, oldest first, optionally filtered to repositories.
+func (db *DBWrapper) GetRunOutcomes(ctx context.Context, since time.Duration, repos []string) ([]models.RunOutcome, error) {
+	cutoff := db.clock.Now().Add(-since).Format(time.RFC3339)
+	args := append([]interface{}{cutoff}, repoArgs(repos)...)
+
+	rows, err := db.db.QueryContext(ctx, `
+		SELECT id, repository, name, workflow_path, html_url, conclusion, created_at
+		FROM workflow_runs
+		WHERE status = 'completed' AND workflow_path != ''
+			AND julianday(created_at) >= julianday(?)`+repoIn("repository", repos)+`
+		ORDER BY julianday(created_at) ASC, id ASC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get run outcomes: %w", err)
+	}
+	defer rows.Close()
+
+	outcomes := []models.RunOutcome{}
+	for rows.Next() {
+		var o models.RunOutcome
+		var htmlURL, conclusion, createdAt sql.NullString
+		if err := rows.Scan(&o.RunID, &o.Repository, &o.Name, &o.WorkflowPath, &htmlURL, &conclusion, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan run outcome: %w", err)
+		}
+		o.HtmlUrl = htmlURL.String
+		o.Conclusion = conclusion.String
+		o.CreatedAt = parseTime(createdAt.String)
+		outcomes = append(outcomes, o)
+	}
+	return outcomes, rows.Err()
+}
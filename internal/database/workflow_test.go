@@ -0,0 +1,70 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrefixEnd(t *testing.T) {
+	assert.Equal(t, "abd", prefixEnd("abc"))
+	assert.Equal(t, "ab:", prefixEnd("ab9"))
+	assert.Equal(t, "abg", prefixEnd("abf"))
+}
+
+func TestHeadSHAPrefixUsesIndex(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec(`CREATE TABLE workflow_runs (id INTEGER PRIMARY KEY, head_sha TEXT NOT NULL DEFAULT '');
+		CREATE INDEX idx_workflow_runs_head_sha ON workflow_runs (head_sha);
+		INSERT INTO workflow_runs (id, head_sha) VALUES (1, 'abcf12'), (2, 'abd000'), (3, 'abc')`)
+	require.NoError(t, err)
+
+	var ids []int
+	rows, err := db.Query("SELECT id FROM workflow_runs WHERE head_sha >= ? AND head_sha < ? ORDER BY id", "abc", prefixEnd("abc"))
+	require.NoError(t, err)
+	for rows.Next() {
+		var id int
+		require.NoError(t, rows.Scan(&id))
+		ids = append(ids, id)
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []int{1, 3}, ids)
+
+	var plan strings.Builder
+	rows, err = db.Query("EXPLAIN QUERY PLAN SELECT id FROM workflow_runs WHERE head_sha >= ? AND head_sha < ?", "abc", "abd")
+	require.NoError(t, err)
+	defer rows.Close()
+	for rows.Next() {
+		var id, parent, notused int
+		var detail string
+		require.NoError(t, rows.Scan(&id, &parent, &notused, &detail))
+		plan.WriteString(detail)
+	}
+	assert.Contains(t, plan.String(), "idx_workflow_runs_head_sha")
+}
+
+func TestCleanupOldData_WorkflowFileChanges(t *testing.T) {
+	db := newTestDB(t, "")
+	ctx := context.Background()
+	now := time.Now()
+	require.NoError(t, db.SaveWorkflowFileChanges(ctx, []models.WorkflowFileChange{
+		{Repository: "app", Path: "ci.yml", SHA: "old", CommittedAt: now.Add(-40 * 24 * time.Hour)},
+		{Repository: "app", Path: "ci.yml", SHA: "new", CommittedAt: now.Add(-time.Hour)},
+	}))
+
+	_, _, _, err := db.CleanupOldData(ctx, 30*24*time.Hour)
+	require.NoError(t, err)
+
+	changes, err := db.GetWorkflowFileChanges(ctx, 365*24*time.Hour, nil)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "new", changes[0].SHA)
+}
@@ -0,0 +1,70 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CheckWritable reports whether the database accepts writes, by starting one
+// in a transaction that is rolled back.
+func (db *DBWrapper) CheckWritable(ctx context.Context) error {
+	return checkWritable(ctx, db.db)
+}
+
+// CheckDatabaseWritable reports whether the database at path can be opened
+// with key and written to, without applying migrations or changing it. A file
+// that does not exist yet passes if it could be created.
+func CheckDatabaseWritable(ctx context.Context, path, key string) error {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) && !strings.HasPrefix(path, "file:") {
+		return checkDirWritable(filepath.Dir(path))
+	}
+
+	db, err := open(path, key)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return checkWritable(ctx, db)
+}
+
+// checkWritable creates a table in a transaction it rolls back, which fails if
+// the file is read-only or another connection holds the write lock past the
+// busy timeout.
+func checkWritable(ctx context.Context, db sqlDB) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start write check: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, "CREATE TABLE live_actions_write_check (id INTEGER)"); err != nil {
+		return fmt.Errorf("database is not writable: %w", err)
+	}
+	return nil
+}
+
+// checkDirWritable reports whether a file can be created in dir or, as the
+// server creates missing data directories, in its nearest existing parent.
+func checkDirWritable(dir string) error {
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	f, err := os.CreateTemp(dir, ".live-actions-write-check-*")
+	if err != nil {
+		return fmt.Errorf("database directory is not writable: %w", err)
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
@@ -0,0 +1,108 @@
+package federation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+)
+
+const (
+	// peerTimeout bounds a single summary request so one slow peer cannot
+	// stall the combined overview.
+	peerTimeout = 5 * time.Second
+	// maxSummarySize bounds the body read from a peer; summaries are a few
+	// hundred bytes.
+	maxSummarySize = 1 << 20
+)
+
+// SummaryPath is where every instance serves its summary to peers.
+const SummaryPath = "/api/federation/summary"
+
+// Peer is another live-actions instance whose summary is aggregated.
+type Peer struct {
+	Name  string
+	URL   string
+	Token string
+}
+
+// Client fetches summaries from federated peers.
+type Client struct {
+	peers  []Peer
+	client *http.Client
+}
+
+// NewClient creates a client for the peers configured as name=URL pairs,
+// authenticating with the matching entries of tokens.
+func NewClient(peers map[string]string, tokens map[string]string) *Client {
+	c := &Client{client: &http.Client{Timeout: peerTimeout}}
+	for name, url := range peers {
+		c.peers = append(c.peers, Peer{Name: name, URL: strings.TrimSuffix(url, "/"), Token: tokens[name]})
+	}
+	sort.Slice(c.peers, func(i, j int) bool { return c.peers[i].Name < c.peers[j].Name })
+	return c
+}
+
+// Peers returns the configured peers ordered by name.
+func (c *Client) Peers() []Peer {
+	return c.peers
+}
+
+// FetchSummary retrieves the summary of a single peer. The result is labelled
+// with the peer's configured name and URL rather than what the peer reports.
+func (c *Client) FetchSummary(ctx context.Context, peer Peer) (*models.InstanceSummary, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, peer.URL+SummaryPath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if peer.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+peer.Token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach peer: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("peer responded with status %d", resp.StatusCode)
+	}
+
+	var summary models.InstanceSummary
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxSummarySize)).Decode(&summary); err != nil {
+		return nil, fmt.Errorf("failed to decode peer summary: %w", err)
+	}
+	summary.Name = peer.Name
+	summary.URL = peer.URL
+	summary.Error = ""
+	return &summary, nil
+}
+
+// FetchAll retrieves all peer summaries concurrently, in peer order. Peers
+// that fail are reported with Error set instead of failing the whole call.
+func (c *Client) FetchAll(ctx context.Context) []models.InstanceSummary {
+	summaries := make([]models.InstanceSummary, len(c.peers))
+	var wg sync.WaitGroup
+	for i, peer := range c.peers {
+		wg.Add(1)
+		go func(i int, peer Peer) {
+			defer wg.Done()
+			summary, err := c.FetchSummary(ctx, peer)
+			if err != nil {
+				summaries[i] = models.InstanceSummary{Name: peer.Name, URL: peer.URL, Error: err.Error()}
+				return
+			}
+			summaries[i] = *summary
+		}(i, peer)
+	}
+	wg.Wait()
+	return summaries
+}
@@ -0,0 +1,36 @@
+package federation
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchSummary(t *testing.T) {
+	peer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"name":"other","running_jobs":3}`))
+	}))
+	defer peer.Close()
+	c := NewClient(map[string]string{"eu": peer.URL}, nil)
+
+	summary, err := c.FetchSummary(context.Background(), c.Peers()[0])
+	require.NoError(t, err)
+	assert.Equal(t, "eu", summary.Name, "peers are named by the configuration")
+	assert.Equal(t, 3, summary.RunningJobs)
+}
+
+func TestFetchSummary_OversizedBody(t *testing.T) {
+	peer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"name":"` + strings.Repeat("x", maxSummarySize) + `"}`))
+	}))
+	defer peer.Close()
+	c := NewClient(map[string]string{"eu": peer.URL}, nil)
+
+	_, err := c.FetchSummary(context.Background(), c.Peers()[0])
+	assert.ErrorContains(t, err, "failed to decode peer summary")
+}
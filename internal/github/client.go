@@ -0,0 +1,204 @@
+// Package github talks to the GitHub REST API, to enrich what webhooks
+// deliver and to dispatch the canary workflow and follow the run it creates.
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// requestTimeout bounds a single GitHub API request.
+	requestTimeout = 15 * time.Second
+	// maxResponseSize bounds the body read from a single response.
+	maxResponseSize = 10 << 20
+	// maxCommitPages bounds the pages of commits followed for one file.
+	maxCommitPages = 10
+)
+
+// Commit is the subset of a commit needed to point at a change.
+type Commit struct {
+	SHA     string `json:"sha"`
+	HtmlUrl string `json:"html_url"`
+	Commit  struct {
+		Message string `json:"message"`
+		Author  struct {
+			Name string `json:"name"`
+		} `json:"author"`
+		Committer struct {
+			Date time.Time `json:"date"`
+		} `json:"committer"`
+	} `json:"commit"`
+}
+
+// Run is the subset of a workflow run the canary needs.
+type Run struct {
+	ID         int64     `json:"id"`
+	Status     string    `json:"status"`
+	Conclusion string    `json:"conclusion"`
+	HtmlUrl    string    `json:"html_url"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Client is a minimal GitHub REST API client authenticated with a token.
+type Client struct {
+	apiURL string
+	token  string
+	client *http.Client
+}
+
+// NewClient creates a client for the API at apiURL, e.g.
+// https://api.github.com or https://ghes.example.com/api/v3.
+func NewClient(apiURL, token string) *Client {
+	return &Client{
+		apiURL: strings.TrimSuffix(apiURL, "/"),
+		token:  token,
+		client: &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// do sends a request to target, a path under the API URL or an absolute
+// URL, and decodes a JSON response into out, when given. The body is read
+// through maxResponseSize. It returns the response headers.
+func (c *Client) do(ctx context.Context, method, target string, body interface{}, wantStatus int, out interface{}) (http.Header, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	if !strings.HasPrefix(target, "http://") && !strings.HasPrefix(target, "https://") {
+		target = c.apiURL + target
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach GitHub: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != wantStatus {
+		return nil, fmt.Errorf("GitHub responded to %s %s with status %d", method, req.URL.Path, resp.StatusCode)
+	}
+	if out != nil {
+		if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(out); err != nil {
+			return nil, fmt.Errorf("failed to decode GitHub response: %w", err)
+		}
+	}
+	return resp.Header, nil
+}
+
+// ListFileCommits returns the commits on the default branch of repo
+// ("owner/name") that changed path since the given time, newest first,
+// following pages up to maxCommitPages of 100.
+func (c *Client) ListFileCommits(ctx context.Context, repo, path string, since time.Time) ([]Commit, error) {
+	query := url.Values{
+		"path":     {path},
+		"since":    {since.UTC().Format(time.RFC3339)},
+		"per_page": {"100"},
+	}
+	next := fmt.Sprintf("%s/repos/%s/commits?%s", c.apiURL, repo, query.Encode())
+
+	var commits []Commit
+	for page := 0; next != "" && page < maxCommitPages; page++ {
+		var batch []Commit
+		var err error
+		if batch, next, err = c.getCommits(ctx, next); err != nil {
+			return nil, err
+		}
+		commits = append(commits, batch...)
+	}
+	return commits, nil
+}
+
+// getCommits requests one page of commits, returning the URL of the next
+// page from the Link header, or "" on the last page.
+func (c *Client) getCommits(ctx context.Context, pageURL string) ([]Commit, string, error) {
+	var commits []Commit
+	header, err := c.do(ctx, http.MethodGet, pageURL, nil, http.StatusOK, &commits)
+	if err != nil {
+		return nil, "", err
+	}
+	return commits, nextPage(header.Get("Link")), nil
+}
+
+// nextPage returns the rel="next" URL of a Link header, or "".
+func nextPage(link string) string {
+	for _, part := range strings.Split(link, ",") {
+		target, params, ok := strings.Cut(part, ";")
+		if !ok || !strings.Contains(params, `rel="next"`) {
+			continue
+		}
+		return strings.Trim(strings.TrimSpace(target), "<>")
+	}
+	return ""
+}
+
+// DispatchWorkflow triggers a workflow_dispatch event for workflow (a file
+// name or ID) of repo ("owner/name") on ref.
+func (c *Client) DispatchWorkflow(ctx context.Context, repo, workflow, ref string) error {
+	path := fmt.Sprintf("/repos/%s/actions/workflows/%s/dispatches", repo, url.PathEscape(workflow))
+	_, err := c.do(ctx, http.MethodPost, path, map[string]string{"ref": ref}, http.StatusNoContent, nil)
+	return err
+}
+
+// FindDispatchedRun returns the earliest workflow_dispatch run of workflow
+// created at or after since, or nil when GitHub has not created it yet.
+// The dispatch API does not return the run it creates, so the run is matched
+// by creation time.
+func (c *Client) FindDispatchedRun(ctx context.Context, repo, workflow string, since time.Time) (*Run, error) {
+	query := url.Values{
+		"event":    {"workflow_dispatch"},
+		"created":  {">=" + since.UTC().Format(time.RFC3339)},
+		"per_page": {"20"},
+	}
+	path := fmt.Sprintf("/repos/%s/actions/workflows/%s/runs?%s", repo, url.PathEscape(workflow), query.Encode())
+
+	var response struct {
+		WorkflowRuns []Run `json:"workflow_runs"`
+	}
+	if _, err := c.do(ctx, http.MethodGet, path, nil, http.StatusOK, &response); err != nil {
+		return nil, err
+	}
+
+	var earliest *Run
+	for i := range response.WorkflowRuns {
+		run := &response.WorkflowRuns[i]
+		if run.CreatedAt.Before(since) {
+			continue
+		}
+		if earliest == nil || run.CreatedAt.Before(earliest.CreatedAt) {
+			earliest = run
+		}
+	}
+	return earliest, nil
+}
+
+// GetRun returns the current state of a workflow run.
+func (c *Client) GetRun(ctx context.Context, repo string, runID int64) (*Run, error) {
+	var run Run
+	if _, err := c.do(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/actions/runs/%d", repo, runID), nil, http.StatusOK, &run); err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
@@ -0,0 +1,111 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListFileCommits_Pages(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/repos/octo/app/commits", r.URL.Path)
+		assert.Equal(t, ".github/workflows/ci.yml", r.URL.Query().Get("path"))
+		page := r.URL.Query().Get("page")
+		if page == "" {
+			page = "1"
+		}
+		if page == "1" {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/repos/octo/app/commits?path=.github%%2Fworkflows%%2Fci.yml&page=2>; rel="next", <%s/repos/octo/app/commits?page=2>; rel="last"`, server.URL, server.URL))
+		}
+		fmt.Fprintf(w, `[{"sha":"sha-%s"}]`, page)
+	}))
+	t.Cleanup(server.Close)
+
+	commits, err := NewClient(server.URL, "token").ListFileCommits(context.Background(), "octo/app", ".github/workflows/ci.yml", time.Now())
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+	assert.Equal(t, "sha-1", commits[0].SHA)
+	assert.Equal(t, "sha-2", commits[1].SHA)
+}
+
+func TestListFileCommits_PageLimit(t *testing.T) {
+	requests := 0
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Link", `<`+server.URL+r.URL.Path+`?page=next>; rel="next"`)
+		fmt.Fprint(w, `[]`)
+	}))
+	t.Cleanup(server.Close)
+
+	_, err := NewClient(server.URL, "token").ListFileCommits(context.Background(), "octo/app", "ci.yml", time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, maxCommitPages, requests)
+}
+
+func TestNextPage(t *testing.T) {
+	assert.Equal(t, "https://api.github.com/x?page=3",
+		nextPage(`<https://api.github.com/x?page=1>; rel="prev", <https://api.github.com/x?page=3>; rel="next"`))
+	assert.Equal(t, "", nextPage(`<https://api.github.com/x?page=1>; rel="first"`))
+	assert.Equal(t, "", nextPage(""))
+}
+
+func TestDispatchWorkflow(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/repos/octo/canary/actions/workflows/canary.yml/dispatches", r.URL.Path)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `{"ref":"main"}`, string(body))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	require.NoError(t, NewClient(server.URL, "token").DispatchWorkflow(context.Background(), "octo/canary", "canary.yml", "main"))
+}
+
+func TestFindDispatchedRun_Earliest(t *testing.T) {
+	since := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "workflow_dispatch", r.URL.Query().Get("event"))
+		fmt.Fprint(w, `{"workflow_runs":[
+			{"id":3,"created_at":"2024-05-02T10:02:00Z"},
+			{"id":2,"created_at":"2024-05-02T10:01:00Z"},
+			{"id":1,"created_at":"2024-05-02T09:59:00Z"}]}`)
+	}))
+	t.Cleanup(server.Close)
+
+	run, err := NewClient(server.URL, "token").FindDispatchedRun(context.Background(), "octo/canary", "canary.yml", since)
+	require.NoError(t, err)
+	require.NotNil(t, run)
+	assert.Equal(t, int64(2), run.ID)
+}
+
+func TestGetRun_ResponseTooLarge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"id":1,"html_url":"%s"}`, strings.Repeat("x", maxResponseSize))
+	}))
+	t.Cleanup(server.Close)
+
+	_, err := NewClient(server.URL, "token").GetRun(context.Background(), "octo/canary", 1)
+	assert.ErrorContains(t, err, "failed to decode GitHub response")
+}
+
+func TestGetRun_UnexpectedStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(server.Close)
+
+	_, err := NewClient(server.URL, "token").GetRun(context.Background(), "octo/canary", 1)
+	assert.EqualError(t, err, "GitHub responded to GET /repos/octo/canary/actions/runs/1 with status 404")
+}
@@ -0,0 +1,220 @@
+// Package jobnames merges the names a job has carried across runs, such as
+// "build (18, ubuntu)" and "🚀 build (ubuntu, 18)", so analytics keyed by job
+// name do not fragment when a workflow reorders its matrix or decorates its
+// job names.
+package jobnames
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Normalization rules, applied in the order they are configured.
+const (
+	// RuleEmoji drops emoji and other symbols, e.g. "🚀 deploy" -> "deploy".
+	RuleEmoji = "emoji"
+	// RuleMatrix sorts the comma-separated values inside parentheses, e.g.
+	// "test (ubuntu, 18)" -> "test (18, ubuntu)".
+	RuleMatrix = "matrix"
+	// RuleWhitespace collapses runs of whitespace and trims the name.
+	RuleWhitespace = "whitespace"
+	// RuleCase ignores letter case.
+	RuleCase = "case"
+)
+
+// DefaultRules are the rules applied unless configured otherwise.
+var DefaultRules = []string{RuleEmoji, RuleMatrix, RuleWhitespace}
+
+// ValidateRules returns an error naming the first unknown rule.
+func ValidateRules(rules []string) error {
+	for _, rule := range rules {
+		switch rule {
+		case RuleEmoji, RuleMatrix, RuleWhitespace, RuleCase:
+		default:
+			return fmt.Errorf("unknown job name rule %q", rule)
+		}
+	}
+	return nil
+}
+
+// Normalize applies rules to name. Names that normalize alike are merged.
+func Normalize(name string, rules []string) string {
+	for _, rule := range rules {
+		switch rule {
+		case RuleEmoji:
+			name = strings.Map(func(r rune) rune {
+				// Variation selectors, joiners and skin tones only make sense next to emoji
+				if unicode.Is(unicode.So, r) || unicode.Is(unicode.Variation_Selector, r) || r == '\u200d' || (r >= 0x1F3FB && r <= 0x1F3FF) {
+					return -1
+				}
+				return r
+			}, name)
+			name = strings.TrimSpace(name)
+		case RuleMatrix:
+			name = sortMatrixValues(name)
+		case RuleWhitespace:
+			name = strings.Join(strings.Fields(name), " ")
+		case RuleCase:
+			name = strings.ToLower(name)
+		}
+	}
+	return name
+}
+
+// sortMatrixValues sorts the comma-separated values of each parenthesized
+// group, which is how GitHub appends matrix values to job names.
+func sortMatrixValues(name string) string {
+	var b strings.Builder
+	for {
+		open := strings.IndexByte(name, '(')
+		if open < 0 {
+			break
+		}
+		end := strings.IndexByte(name[open:], ')')
+		if end < 0 {
+			break
+		}
+		end += open
+
+		values := strings.Split(name[open+1:end], ",")
+		for i := range values {
+			values[i] = strings.TrimSpace(values[i])
+		}
+		sort.Strings(values)
+		b.WriteString(name[:open+1])
+		b.WriteString(strings.Join(values, ", "))
+		b.WriteByte(')')
+		name = name[end+1:]
+	}
+	b.WriteString(name)
+	return b.String()
+}
+
+// Resolver maps job names to the key they are grouped under: their confirmed
+// merge target, if any, normalized by the rules. A nil Resolver keeps every
+// name apart.
+type Resolver struct {
+	rules  []string
+	merges map[string]string
+}
+
+// NewResolver returns a Resolver applying rules after merges, a map of
+// confirmed alias to canonical names.
+func NewResolver(rules []string, merges map[string]string) *Resolver {
+	return &Resolver{rules: rules, merges: merges}
+}
+
+// Key returns the key name is grouped under.
+func (r *Resolver) Key(name string) string {
+	if r == nil {
+		return name
+	}
+	// Follow chains of merges, bounded in case confirmed merges form a cycle
+	for i := 0; i < len(r.merges); i++ {
+		canonical, ok := r.merges[name]
+		if !ok || canonical == name {
+			break
+		}
+		name = canonical
+	}
+	return Normalize(name, r.rules)
+}
+
+// Similarity scores how alike two names are from 0 to 100, by their edit
+// distance relative to the longer name.
+func Similarity(a, b string) float64 {
+	longest := max(utf8.RuneCountInString(a), utf8.RuneCountInString(b))
+	if longest == 0 {
+		return 100
+	}
+	return (1 - float64(levenshtein([]rune(a), []rune(b)))/float64(longest)) * 100
+}
+
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// onlyDigitsDiffer reports whether a and b are the same once digits are
+// removed. Such names, like "shard 1" and "shard 2" or "node 18" and
+// "node 20", are usually distinct matrix jobs rather than renames.
+func onlyDigitsDiffer(a, b string) bool {
+	stripDigits := func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return -1
+		}
+		return r
+	}
+	return strings.Map(stripDigits, a) == strings.Map(stripDigits, b)
+}
+
+// Candidate is a job name seen in analytics with the number of its jobs.
+type Candidate struct {
+	Name string
+	Jobs int
+}
+
+// Match pairs a name with a similar one it may be a rename of.
+type Match struct {
+	Alias      string
+	Canonical  string
+	Similarity float64
+}
+
+// FuzzyMatches pairs names whose keys differ but are at least threshold
+// percent similar, proposing the name with more jobs as the canonical one.
+// Pairs for which skip returns true, such as already decided ones, are left
+// out. Matches are ordered by similarity, most similar first.
+func (r *Resolver) FuzzyMatches(candidates []Candidate, threshold float64, skip func(alias, canonical string) bool) []Match {
+	keys := make([]string, len(candidates))
+	for i, c := range candidates {
+		keys[i] = r.Key(c.Name)
+	}
+
+	var matches []Match
+	for i := range candidates {
+		for j := i + 1; j < len(candidates); j++ {
+			if keys[i] == keys[j] || onlyDigitsDiffer(keys[i], keys[j]) {
+				continue
+			}
+			similarity := Similarity(keys[i], keys[j])
+			if similarity < threshold {
+				continue
+			}
+			alias, canonical := candidates[i].Name, candidates[j].Name
+			if candidates[i].Jobs > candidates[j].Jobs || (candidates[i].Jobs == candidates[j].Jobs && alias < canonical) {
+				alias, canonical = canonical, alias
+			}
+			if skip != nil && skip(alias, canonical) {
+				continue
+			}
+			matches = append(matches, Match{Alias: alias, Canonical: canonical, Similarity: similarity})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Similarity != matches[j].Similarity {
+			return matches[i].Similarity > matches[j].Similarity
+		}
+		return matches[i].Alias < matches[j].Alias
+	})
+	return matches
+}
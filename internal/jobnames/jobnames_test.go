@@ -0,0 +1,85 @@
+package jobnames
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []string
+		want  string
+	}{
+		{"🚀 deploy", DefaultRules, "deploy"},
+		{"✅ test ✔️", DefaultRules, "test"},
+		{"test (ubuntu-latest, 18)", DefaultRules, "test (18, ubuntu-latest)"},
+		{"test (18,ubuntu-latest) / lint (b, a)", DefaultRules, "test (18, ubuntu-latest) / lint (a, b)"},
+		{"build  \t docs", DefaultRules, "build docs"},
+		{"Build Docs", DefaultRules, "Build Docs"},
+		{"Build Docs", []string{RuleCase}, "build docs"},
+		{"🚀 deploy", nil, "🚀 deploy"},
+		{"unclosed (b, a", DefaultRules, "unclosed (b, a"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Normalize(tt.name, tt.rules), tt.name)
+	}
+}
+
+func TestValidateRules(t *testing.T) {
+	assert.NoError(t, ValidateRules(append(DefaultRules, RuleCase)))
+	assert.Error(t, ValidateRules([]string{"emoji", "soundex"}))
+}
+
+func TestResolver_Key(t *testing.T) {
+	r := NewResolver(DefaultRules, map[string]string{
+		"compile":      "build",
+		"build":        "🔨 build",
+		"loop-a":       "loop-b",
+		"loop-b":       "loop-a",
+		"unit (b, a)":  "unit (b, a)",
+		"integration ": "integration",
+	})
+
+	assert.Equal(t, "build", r.Key("compile"), "merges are followed, then normalized")
+	assert.Equal(t, r.Key("🔨 build"), r.Key("compile"))
+	assert.Equal(t, "unit (a, b)", r.Key("unit (b, a)"))
+	assert.NotPanics(t, func() { r.Key("loop-a") })
+
+	var none *Resolver
+	assert.Equal(t, "🔨 build", none.Key("🔨 build"))
+}
+
+func TestSimilarity(t *testing.T) {
+	assert.Equal(t, 100.0, Similarity("build", "build"))
+	assert.Equal(t, 80.0, Similarity("build", "built"))
+	assert.Equal(t, 0.0, Similarity("abc", "xyz"))
+	assert.Equal(t, 100.0, Similarity("", ""))
+}
+
+func TestResolver_FuzzyMatches(t *testing.T) {
+	r := NewResolver(DefaultRules, nil)
+	candidates := []Candidate{
+		{Name: "integration-tests", Jobs: 40},
+		{Name: "integration-test", Jobs: 3},
+		{Name: "🚀 deploy", Jobs: 5},
+		{Name: "deploy", Jobs: 8},
+		{Name: "shard 1", Jobs: 10},
+		{Name: "shard 2", Jobs: 10},
+		{Name: "lint", Jobs: 12},
+	}
+
+	matches := r.FuzzyMatches(candidates, 85, nil)
+
+	require.Len(t, matches, 1, "names merged by rules, digit-only differences and dissimilar names are not proposed")
+	assert.Equal(t, "integration-test", matches[0].Alias)
+	assert.Equal(t, "integration-tests", matches[0].Canonical, "the name with more jobs is canonical")
+	assert.InDelta(t, 94.1, matches[0].Similarity, 0.1)
+
+	skipped := r.FuzzyMatches(candidates, 85, func(alias, canonical string) bool {
+		return alias == "integration-test"
+	})
+	assert.Empty(t, skipped)
+}
@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccessLogWriter is the destination for access log lines. When it targets a
+// file it can be reopened in place, so external rotation (logrotate moving the
+// file and sending SIGHUP) does not require a restart.
+type AccessLogWriter struct {
+	mutex sync.Mutex
+	path  string
+	out   io.Writer
+	file  *os.File
+}
+
+// NewAccessLogWriter opens the access log target. "stdout" writes to standard
+// output; any other value is treated as a file path opened in append mode.
+func NewAccessLogWriter(target string) (*AccessLogWriter, error) {
+	if target == "stdout" {
+		return &AccessLogWriter{out: os.Stdout}, nil
+	}
+
+	w := &AccessLogWriter{path: target}
+	if err := w.Reopen(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write writes a single access log line.
+func (w *AccessLogWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.out.Write(p)
+}
+
+// Reopen closes and reopens the underlying file. It is a no-op for stdout.
+func (w *AccessLogWriter) Reopen() error {
+	if w.path == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return fmt.Errorf("failed to open access log %s: %w", w.path, err)
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.file != nil {
+		_ = w.file.Close()
+	}
+	w.file = f
+	w.out = f
+	return nil
+}
+
+// Close closes the underlying file, if any.
+func (w *AccessLogWriter) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// accessLogEntry is the JSON representation of an access log line.
+type accessLogEntry struct {
+	Time       string  `json:"time"`
+	RemoteAddr string  `json:"remote_addr"`
+	Method     string  `json:"method"`
+	URI        string  `json:"uri"`
+	Proto      string  `json:"proto"`
+	Status     int     `json:"status"`
+	Bytes      int     `json:"bytes"`
+	Referer    string  `json:"referer"`
+	UserAgent  string  `json:"user_agent"`
+	LatencyMs  float64 `json:"latency_ms"`
+}
+
+// AccessLogger writes one line per request to w, separate from the
+// application log. Supported formats are "combined" (Apache/NGINX Combined
+// Log Format) and "json".
+func AccessLogger(w io.Writer, format string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		size := c.Writer.Size()
+		if size < 0 {
+			size = 0
+		}
+
+		var line []byte
+		if format == "json" {
+			line, _ = json.Marshal(accessLogEntry{
+				Time:       start.Format(time.RFC3339),
+				RemoteAddr: c.ClientIP(),
+				Method:     c.Request.Method,
+				URI:        c.Request.URL.RequestURI(),
+				Proto:      c.Request.Proto,
+				Status:     c.Writer.Status(),
+				Bytes:      size,
+				Referer:    c.Request.Referer(),
+				UserAgent:  c.Request.UserAgent(),
+				LatencyMs:  float64(time.Since(start).Microseconds()) / 1000,
+			})
+			line = append(line, '\n')
+		} else {
+			bytesField := "-"
+			if size > 0 {
+				bytesField = strconv.Itoa(size)
+			}
+			line = []byte(fmt.Sprintf("%s - - [%s] %q %d %s %q %q\n",
+				c.ClientIP(),
+				start.Format("02/Jan/2006:15:04:05 -0700"),
+				c.Request.Method+" "+c.Request.URL.RequestURI()+" "+c.Request.Proto,
+				c.Writer.Status(),
+				bytesField,
+				orDash(c.Request.Referer()),
+				orDash(c.Request.UserAgent()),
+			))
+		}
+
+		_, _ = w.Write(line)
+	}
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
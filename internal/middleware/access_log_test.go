@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessLogger_CombinedFormat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var buf bytes.Buffer
+	router := gin.New()
+	router.Use(AccessLogger(&buf, "combined"))
+	router.GET("/test", func(c *gin.Context) {
+		c.String(http.StatusOK, "hello")
+	})
+
+	req, _ := http.NewRequest("GET", "/test?page=2", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("User-Agent", "curl/8.0")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	pattern := regexp.MustCompile(`^10\.0\.0\.1 - - \[[^\]]+\] "GET /test\?page=2 HTTP/1\.1" 200 5 "-" "curl/8\.0"\n$`)
+	assert.Regexp(t, pattern, buf.String())
+}
+
+func TestAccessLogger_JSONFormat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var buf bytes.Buffer
+	router := gin.New()
+	router.Use(AccessLogger(&buf, "json"))
+	router.GET("/missing", func(c *gin.Context) {
+		c.Status(http.StatusNotFound)
+	})
+
+	req, _ := http.NewRequest("GET", "/missing", nil)
+	req.Header.Set("Referer", "http://localhost/")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var entry accessLogEntry
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "GET", entry.Method)
+	assert.Equal(t, "/missing", entry.URI)
+	assert.Equal(t, http.StatusNotFound, entry.Status)
+	assert.Equal(t, "http://localhost/", entry.Referer)
+}
+
+func TestAccessLogWriter_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "access.log")
+
+	w, err := NewAccessLogWriter(path)
+	require.NoError(t, err)
+	defer w.Close()
+
+	_, err = w.Write([]byte("first\n"))
+	require.NoError(t, err)
+
+	// Simulate logrotate moving the file away
+	rotated := filepath.Join(dir, "access.log.1")
+	require.NoError(t, os.Rename(path, rotated))
+	require.NoError(t, w.Reopen())
+
+	_, err = w.Write([]byte("second\n"))
+	require.NoError(t, err)
+
+	old, _ := os.ReadFile(rotated)
+	current, _ := os.ReadFile(path)
+	assert.Equal(t, "first\n", string(old))
+	assert.Equal(t, "second\n", string(current))
+}
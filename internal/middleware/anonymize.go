@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"github.com/gateixeira/live-actions/internal/anonymize"
+	"github.com/gin-gonic/gin"
+)
+
+// Anonymize passes JSON responses through the anonymizer, replacing
+// repository, owner and user names and run titles with pseudonyms. A
+// pseudonym in the repo query parameter is mapped back to its repository, so
+// filters picked from anonymized responses keep working. It must run before
+// anything reads the query, as gin caches it.
+func Anonymize(a *anonymize.Anonymizer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := c.Request.URL.Query()
+		if repo := query.Get("repo"); repo != "" {
+			query.Set("repo", a.Reveal(repo))
+			c.Request.URL.RawQuery = query.Encode()
+		}
+
+		w := bufferJSON(c)
+		c.Next()
+		w.finish(c, a.JSON)
+	}
+}
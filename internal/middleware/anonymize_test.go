@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gateixeira/live-actions/internal/anonymize"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnonymize(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	a := anonymize.New("secret")
+	router := gin.New()
+	router.Use(Anonymize(a))
+	router.GET("/api/workflow-runs", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"repo_filter": c.Query("repo"), "repository_name": "app", "running": 3})
+	})
+	router.GET("/api/text", func(c *gin.Context) {
+		c.String(http.StatusOK, "app")
+	})
+
+	alias := a.Repository("octo/app")
+	req, _ := http.NewRequest("GET", "/api/workflow-runs?repo="+alias, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"repo_filter":"octo/app","repository_name":"`+a.Repository("app")+`","running":3}`, w.Body.String())
+
+	req, _ = http.NewRequest("GET", "/api/text", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "app", w.Body.String())
+}
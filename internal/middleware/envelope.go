@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gin-gonic/gin"
+)
+
+// APIVersionPrefix is where the current version of the API is served.
+const APIVersionPrefix = "/api/v1"
+
+// envelope is the shape of every JSON response on versioned routes.
+type envelope struct {
+	Data       json.RawMessage  `json:"data"`
+	Pagination json.RawMessage  `json:"pagination,omitempty"`
+	Error      json.RawMessage  `json:"error,omitempty"`
+	Warnings   []models.Warning `json:"warnings"`
+	RequestID  string           `json:"request_id"`
+}
+
+// Envelope wraps JSON responses in {data, pagination, error, warnings,
+// request_id}. Handlers keep writing their unversioned bodies: "pagination"
+// and "error" fields of an object are lifted out of it, and the rest becomes
+// data. Other responses, including event streams, pass through untouched.
+func Envelope(warnings func() []models.Warning) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		w := bufferJSON(c)
+		c.Next()
+		w.finish(c, func(body []byte) []byte {
+			current := warnings()
+			if current == nil {
+				current = []models.Warning{}
+			}
+			wrapped, err := wrapEnvelope(body, current, c.GetString(RequestIDKey))
+			if err != nil {
+				return body
+			}
+			return wrapped
+		})
+	}
+}
+
+func wrapEnvelope(body []byte, warnings []models.Warning, requestID string) ([]byte, error) {
+	env := envelope{Warnings: warnings, RequestID: requestID}
+
+	trimmed := bytes.TrimSpace(body)
+	var fields map[string]json.RawMessage
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		if err := json.Unmarshal(trimmed, &fields); err != nil {
+			return nil, err
+		}
+		env.Pagination = fields["pagination"]
+		env.Error = fields["error"]
+		delete(fields, "pagination")
+		delete(fields, "error")
+		if len(fields) > 0 || env.Error == nil {
+			data, err := json.Marshal(fields)
+			if err != nil {
+				return nil, err
+			}
+			env.Data = data
+		}
+	} else if json.Valid(trimmed) {
+		env.Data = trimmed
+	} else {
+		return nil, errors.New("response body is not JSON")
+	}
+	if env.Data == nil {
+		env.Data = json.RawMessage("null")
+	}
+	return json.Marshal(env)
+}
+
+// Deprecated marks responses on unversioned API paths as deprecated, pointing
+// at their versioned successor with a Link header.
+func Deprecated() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if path := c.Request.URL.Path; strings.HasPrefix(path, "/api/") {
+			successor := APIVersionPrefix + strings.TrimPrefix(path, "/api")
+			c.Header("Deprecation", "true")
+			c.Header("Link", "<"+successor+`>; rel="successor-version"`)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testEnvelope struct {
+	Data       json.RawMessage  `json:"data"`
+	Pagination map[string]int   `json:"pagination"`
+	Error      string           `json:"error"`
+	Warnings   []models.Warning `json:"warnings"`
+	RequestID  string           `json:"request_id"`
+}
+
+func envelopeRouter(warnings []models.Warning) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestID())
+	v1 := router.Group(APIVersionPrefix, Envelope(func() []models.Warning { return warnings }))
+	v1.GET("/runs", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"workflow_runs": []int{1}, "pagination": gin.H{"page": 2}})
+	})
+	v1.GET("/list", func(c *gin.Context) {
+		c.JSON(http.StatusOK, []string{"app"})
+	})
+	v1.GET("/missing", func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Run not found"})
+	})
+	v1.GET("/text", func(c *gin.Context) {
+		c.String(http.StatusOK, "plain")
+	})
+	return router
+}
+
+func serveEnvelope(t *testing.T, router *gin.Engine, path string) (*httptest.ResponseRecorder, testEnvelope) {
+	req, _ := http.NewRequest("GET", path, nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var env testEnvelope
+	if w.Header().Get("Content-Type") == "application/json; charset=utf-8" {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env), w.Body.String())
+	}
+	return w, env
+}
+
+func TestEnvelope_WrapsObjects(t *testing.T) {
+	router := envelopeRouter([]models.Warning{{Code: "event_backlog", Message: "delayed"}})
+
+	w, env := serveEnvelope(t, router, "/api/v1/runs")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"workflow_runs":[1]}`, string(env.Data))
+	assert.Equal(t, map[string]int{"page": 2}, env.Pagination)
+	require.Len(t, env.Warnings, 1)
+	assert.Equal(t, "req-1", env.RequestID)
+	assert.Equal(t, "req-1", w.Header().Get(RequestIDHeader))
+}
+
+func TestEnvelope_ArraysErrorsAndOtherBodies(t *testing.T) {
+	router := envelopeRouter(nil)
+
+	_, env := serveEnvelope(t, router, "/api/v1/list")
+	assert.JSONEq(t, `["app"]`, string(env.Data))
+	assert.NotNil(t, env.Warnings, "warnings are always listed")
+	assert.Empty(t, env.Warnings)
+
+	w, env := serveEnvelope(t, router, "/api/v1/missing")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "Run not found", env.Error)
+	assert.Equal(t, "null", string(env.Data))
+
+	w, _ = serveEnvelope(t, router, "/api/v1/text")
+	assert.Equal(t, "plain", w.Body.String())
+}
+
+func TestDeprecated(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Group("/api", Deprecated()).GET("/workflow-runs", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{})
+	})
+
+	req, _ := http.NewRequest("GET", "/api/workflow-runs?page=2", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, "true", w.Header().Get("Deprecation"))
+	assert.Equal(t, `</api/v1/workflow-runs>; rel="successor-version"`, w.Header().Get("Link"))
+}
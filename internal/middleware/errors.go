@@ -75,7 +75,7 @@ func RequestLogger() gin.HandlerFunc {
 		Formatter: func(param gin.LogFormatterParams) string {
 			// Log security-relevant information (if logger is available)
 			if logger.Logger != nil && param.Path != "/metrics" {
-				logger.Logger.Debug("HTTP Request",
+				logger.Module("http").Debug("HTTP Request",
 					zap.String("method", param.Method),
 					zap.String("path", param.Path),
 					zap.Int("status", param.StatusCode),
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// jsonBufferWriter holds back JSON bodies so middleware can amend them once
+// the handler is done. Whether to buffer is decided on the first write, when
+// the content type is known; other responses pass straight through.
+type jsonBufferWriter struct {
+	gin.ResponseWriter
+	decided   bool
+	buffering bool
+	body      bytes.Buffer
+}
+
+// bufferJSON swaps the context's writer for a jsonBufferWriter.
+func bufferJSON(c *gin.Context) *jsonBufferWriter {
+	w := &jsonBufferWriter{ResponseWriter: c.Writer}
+	c.Writer = w
+	return w
+}
+
+// finish restores the original writer and writes the buffered body, passed
+// through amend, if the response was buffered.
+func (w *jsonBufferWriter) finish(c *gin.Context, amend func([]byte) []byte) {
+	c.Writer = w.ResponseWriter
+	if !w.buffering {
+		return
+	}
+	body := amend(w.body.Bytes())
+	w.ResponseWriter.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	_, _ = w.ResponseWriter.Write(body)
+}
+
+func (w *jsonBufferWriter) decide() {
+	if w.decided {
+		return
+	}
+	w.decided = true
+	w.buffering = strings.HasPrefix(w.Header().Get("Content-Type"), "application/json")
+}
+
+func (w *jsonBufferWriter) Write(data []byte) (int, error) {
+	w.decide()
+	if w.buffering {
+		return w.body.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *jsonBufferWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// WriteHeaderNow is deferred while buffering, because the body written later
+// changes the Content-Length.
+func (w *jsonBufferWriter) WriteHeaderNow() {
+	w.decide()
+	if !w.buffering {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+// Flush sends buffered bodies as they are once the handler streams, since a
+// streamed response cannot be amended.
+func (w *jsonBufferWriter) Flush() {
+	if w.buffering {
+		w.buffering = false
+		_, _ = w.ResponseWriter.Write(w.body.Bytes())
+		w.body.Reset()
+	}
+	w.ResponseWriter.Flush()
+}
+
+var _ http.Flusher = (*jsonBufferWriter)(nil)
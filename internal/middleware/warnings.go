@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Warnings adds a top-level "warnings" array to JSON object responses under
+// /api/ while warnings reports degraded subsystems, so clients can show that
+// data may be delayed. Other responses, including event streams, pass through
+// untouched. Versioned routes carry warnings in their envelope instead.
+func Warnings(warnings func() []models.Warning) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if !strings.HasPrefix(path, "/api/") || strings.HasPrefix(path, APIVersionPrefix+"/") {
+			c.Next()
+			return
+		}
+		current := warnings()
+		if len(current) == 0 {
+			c.Next()
+			return
+		}
+
+		w := bufferJSON(c)
+		c.Next()
+		w.finish(c, func(body []byte) []byte {
+			if injected, err := withWarnings(body, current); err == nil {
+				return injected
+			}
+			return body
+		})
+	}
+}
+
+// withWarnings splices the warnings into a JSON object. Arrays and other
+// values are left as they are.
+func withWarnings(body []byte, warnings []models.Warning) ([]byte, error) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) < 2 || trimmed[0] != '{' || !json.Valid(trimmed) {
+		return body, nil
+	}
+	encoded, err := json.Marshal(warnings)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	out.WriteString(`{"warnings":`)
+	out.Write(encoded)
+	rest := bytes.TrimSpace(trimmed[1:])
+	if rest[0] != '}' {
+		out.WriteByte(',')
+	}
+	out.Write(rest)
+	return out.Bytes(), nil
+}
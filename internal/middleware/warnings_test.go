@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func warningsRouter(warnings []models.Warning) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Warnings(func() []models.Warning { return warnings }))
+	router.GET("/api/object", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"runs": []int{1, 2}})
+	})
+	router.GET("/api/empty", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{})
+	})
+	router.GET("/api/list", func(c *gin.Context) {
+		c.JSON(http.StatusOK, []int{1})
+	})
+	router.GET("/api/text", func(c *gin.Context) {
+		c.String(http.StatusOK, "plain")
+	})
+	router.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+	return router
+}
+
+func serveWarnings(router *gin.Engine, path string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", path, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestWarnings_AddedToJSONObjects(t *testing.T) {
+	since := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	router := warningsRouter([]models.Warning{{Code: "event_backlog", Message: "delayed", Since: since}})
+
+	for _, path := range []string{"/api/object", "/api/empty"} {
+		w := serveWarnings(router, path)
+		assert.Equal(t, http.StatusOK, w.Code, path)
+
+		var body struct {
+			Warnings []models.Warning `json:"warnings"`
+			Runs     []int            `json:"runs"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body), path)
+		require.Len(t, body.Warnings, 1, path)
+		assert.Equal(t, "event_backlog", body.Warnings[0].Code)
+		assert.True(t, since.Equal(body.Warnings[0].Since))
+		assert.Equal(t, strconv.Itoa(w.Body.Len()), w.Header().Get("Content-Length"), path)
+	}
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(serveWarnings(router, "/api/object").Body.Bytes(), &body))
+	assert.Equal(t, []any{float64(1), float64(2)}, body["runs"])
+}
+
+func TestWarnings_OtherResponsesUntouched(t *testing.T) {
+	router := warningsRouter([]models.Warning{{Code: "event_backlog", Message: "delayed"}})
+
+	assert.Equal(t, "[1]", serveWarnings(router, "/api/list").Body.String())
+	assert.Equal(t, "plain", serveWarnings(router, "/api/text").Body.String())
+	assert.Equal(t, `{"status":"ok"}`, serveWarnings(router, "/health").Body.String())
+}
+
+func TestWarnings_NoneRaised(t *testing.T) {
+	router := warningsRouter(nil)
+
+	assert.Equal(t, `{"runs":[1,2]}`, serveWarnings(router, "/api/object").Body.String())
+}
@@ -0,0 +1,122 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/clock"
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// webhookTimeout bounds a single alert webhook delivery.
+const webhookTimeout = 10 * time.Second
+
+// Notifier delivers alerts to connected dashboard clients and, when
+// configured, to an external webhook. Alerts for muted runs and jobs are
+// dropped. A nil Notifier discards all alerts.
+type Notifier struct {
+	webhookURL string
+	client     *http.Client
+	db         database.DatabaseInterface
+	broadcast  func(models.Alert)
+	// clock stamps alerts raised without a time; nil means the wall clock
+	clock clock.Clock
+}
+
+// NewNotifier creates a notifier. broadcast is called synchronously for every
+// alert that is not muted; webhookURL may be empty to disable webhook delivery.
+func NewNotifier(webhookURL string, db database.DatabaseInterface, broadcast func(models.Alert)) *Notifier {
+	return &Notifier{
+		webhookURL: webhookURL,
+		client:     &http.Client{Timeout: webhookTimeout},
+		db:         db,
+		broadcast:  broadcast,
+	}
+}
+
+// SetClock makes alerts raised without a time stamped with clk. Call it
+// before raising alerts.
+func (n *Notifier) SetClock(clk clock.Clock) {
+	n.clock = clk
+}
+
+func (n *Notifier) now() time.Time {
+	if n.clock == nil {
+		return time.Now()
+	}
+	return n.clock.Now()
+}
+
+// Notify sends an alert. Webhook delivery happens in the background so slow
+// receivers never hold up event processing.
+func (n *Notifier) Notify(ctx context.Context, alert models.Alert) {
+	if n == nil {
+		return
+	}
+
+	if alert.RunID != 0 || alert.JobID != 0 {
+		muted, err := n.db.IsMuted(ctx, alert.RunID, alert.JobID)
+		if err != nil {
+			logger.Logger.Warn("Failed to check mute for alert, sending anyway", zap.String("type", alert.Type), zap.Error(err))
+		} else if muted {
+			logger.Logger.Debug("Dropping alert for muted run or job",
+				zap.String("type", alert.Type),
+				zap.Int64("run_id", alert.RunID),
+				zap.Int64("job_id", alert.JobID))
+			return
+		}
+	}
+
+	if alert.CreatedAt.IsZero() {
+		alert.CreatedAt = n.now().UTC()
+	}
+
+	logger.Logger.Info("Alert raised",
+		zap.String("type", alert.Type),
+		zap.String("title", alert.Title),
+		zap.String("repository", alert.Repository))
+
+	if n.broadcast != nil {
+		n.broadcast(alert)
+	}
+
+	if n.webhookURL != "" {
+		go func() {
+			if err := n.post(alert); err != nil {
+				logger.Logger.Error("Failed to deliver alert webhook", zap.String("type", alert.Type), zap.Error(err))
+			}
+		}()
+	}
+}
+
+func (n *Notifier) post(alert models.Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, n.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "live-actions")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("alert webhook returned %s", resp.Status)
+	}
+	return nil
+}
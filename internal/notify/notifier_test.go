@@ -0,0 +1,107 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/clock"
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifier_BroadcastsAndPostsWebhook(t *testing.T) {
+	logger.InitLogger("error")
+
+	received := make(chan models.Alert, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert models.Alert
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&alert))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		received <- alert
+	}))
+	defer server.Close()
+
+	mockDB := &database.MockDatabase{}
+	mockDB.On("IsMuted", context.Background(), int64(42), int64(0)).Return(false, nil)
+
+	var broadcast []models.Alert
+	n := NewNotifier(server.URL, mockDB, func(a models.Alert) { broadcast = append(broadcast, a) })
+	n.Notify(context.Background(), models.Alert{Type: "duration_regression", Title: "CI is slower", RunID: 42})
+
+	require.Len(t, broadcast, 1)
+	assert.False(t, broadcast[0].CreatedAt.IsZero())
+
+	select {
+	case alert := <-received:
+		assert.Equal(t, "duration_regression", alert.Type)
+		assert.Equal(t, int64(42), alert.RunID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not called")
+	}
+	mockDB.AssertExpectations(t)
+}
+
+func TestNotifier_StampsAlertsWithClock(t *testing.T) {
+	logger.InitLogger("error")
+
+	now := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+	var broadcast []models.Alert
+	n := NewNotifier("", &database.MockDatabase{}, func(a models.Alert) { broadcast = append(broadcast, a) })
+	n.SetClock(clock.NewFake(now))
+	n.Notify(context.Background(), models.Alert{Type: "test"})
+
+	require.Len(t, broadcast, 1)
+	assert.Equal(t, now, broadcast[0].CreatedAt)
+}
+
+func TestNotifier_DropsMutedAlerts(t *testing.T) {
+	logger.InitLogger("error")
+
+	mockDB := &database.MockDatabase{}
+	mockDB.On("IsMuted", context.Background(), int64(42), int64(7)).Return(true, nil)
+
+	called := false
+	n := NewNotifier("", mockDB, func(models.Alert) { called = true })
+	n.Notify(context.Background(), models.Alert{Type: "test", RunID: 42, JobID: 7})
+
+	assert.False(t, called)
+}
+
+func TestNotifier_SendsWhenMuteCheckFails(t *testing.T) {
+	logger.InitLogger("error")
+
+	mockDB := &database.MockDatabase{}
+	mockDB.On("IsMuted", context.Background(), int64(42), int64(0)).Return(false, errors.New("db error"))
+
+	called := false
+	n := NewNotifier("", mockDB, func(models.Alert) { called = true })
+	n.Notify(context.Background(), models.Alert{Type: "test", RunID: 42})
+
+	assert.True(t, called)
+}
+
+func TestNotifier_SkipsMuteCheckWithoutEntity(t *testing.T) {
+	logger.InitLogger("error")
+
+	mockDB := &database.MockDatabase{}
+
+	called := false
+	n := NewNotifier("", mockDB, func(models.Alert) { called = true })
+	n.Notify(context.Background(), models.Alert{Type: "test"})
+
+	assert.True(t, called)
+	mockDB.AssertNotCalled(t, "IsMuted")
+}
+
+func TestNotifier_Nil(t *testing.T) {
+	var n *Notifier
+	assert.NotPanics(t, func() { n.Notify(context.Background(), models.Alert{Type: "test"}) })
+}
@@ -0,0 +1,321 @@
+// Package payload normalizes GitHub webhook payloads into the internal models.
+//
+// GitHub Enterprise Server lags behind github.com, so the same event can
+// arrive in several shapes. Each payload is parsed into a raw form that
+// records which fields were present, its schema version is detected, and the
+// compatibility shims registered for that version fill in what it lacks
+// before it is converted.
+package payload
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+)
+
+// Version identifies the payload schema an event was parsed as.
+type Version int
+
+const (
+	// VersionLegacy is the shape sent by older GHES releases (such as 3.8):
+	// workflow_job events without created_at and with a "started" action,
+	// and workflow_run events without display_title or run_started_at.
+	VersionLegacy Version = 1
+	// VersionCurrent is the shape sent by github.com and recent GHES releases.
+	VersionCurrent Version = 2
+)
+
+func (v Version) String() string {
+	switch v {
+	case VersionLegacy:
+		return "legacy"
+	case VersionCurrent:
+		return "current"
+	default:
+		return fmt.Sprintf("v%d", int(v))
+	}
+}
+
+type rawWorkflowJobEvent struct {
+	Action      string         `json:"action"`
+	WorkflowJob rawWorkflowJob `json:"workflow_job"`
+}
+
+type rawWorkflowJob struct {
+	ID          int64      `json:"id"`
+	RunID       int64      `json:"run_id"`
+	Name        string     `json:"name"`
+	Labels      []string   `json:"labels"`
+	HtmlUrl     string     `json:"html_url"`
+	Conclusion  *string    `json:"conclusion"`
+	CreatedAt   *time.Time `json:"created_at"`
+	StartedAt   *time.Time `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	// Only set once a runner picks the job up
+	RunnerName      *string `json:"runner_name"`
+	RunnerGroupID   *int64  `json:"runner_group_id"`
+	RunnerGroupName *string `json:"runner_group_name"`
+}
+
+type rawRepository struct {
+	Name string `json:"name"`
+	Url  string `json:"url"`
+}
+
+type rawActor struct {
+	Login string `json:"login"`
+}
+
+type rawWorkflowRunEvent struct {
+	Action      string         `json:"action"`
+	Repository  *rawRepository `json:"repository"`
+	WorkflowRun rawWorkflowRun `json:"workflow_run"`
+}
+
+type rawWorkflowRun struct {
+	ID           int64              `json:"id"`
+	Name         string             `json:"name"`
+	HtmlUrl      string             `json:"html_url"`
+	DisplayTitle *string            `json:"display_title"`
+	Conclusion   *string            `json:"conclusion"`
+	CreatedAt    *time.Time         `json:"created_at"`
+	RunStartedAt *time.Time         `json:"run_started_at"`
+	UpdatedAt    *time.Time         `json:"updated_at"`
+	HeadSha      string             `json:"head_sha"`
+	HeadCommit   *models.HeadCommit `json:"head_commit"`
+	Repository   *rawRepository     `json:"repository"`
+	Path         string             `json:"path"`
+
+	Actor           *rawActor `json:"actor"`
+	TriggeringActor *rawActor `json:"triggering_actor"`
+	RunAttempt      int       `json:"run_attempt"`
+}
+
+// jobShims and runShims hold the compatibility shims applied to payloads of
+// each version, in order.
+var (
+	jobShims = map[Version][]func(*rawWorkflowJobEvent){
+		VersionLegacy: {renameStartedAction, createdAtFromStartedAt},
+	}
+	runShims = map[Version][]func(*rawWorkflowRunEvent){
+		VersionLegacy: {displayTitleFromCommit, runStartedAtFromCreatedAt, triggeringActorFromActor},
+	}
+)
+
+// renameStartedAction maps the "started" action of legacy workflow_job
+// events to "in_progress".
+func renameStartedAction(e *rawWorkflowJobEvent) {
+	if e.Action == "started" {
+		e.Action = string(models.JobStatusInProgress)
+	}
+}
+
+// createdAtFromStartedAt uses started_at, which legacy payloads set when
+// the job is queued, as the creation time.
+func createdAtFromStartedAt(e *rawWorkflowJobEvent) {
+	if e.WorkflowJob.CreatedAt == nil {
+		e.WorkflowJob.CreatedAt = e.WorkflowJob.StartedAt
+	}
+}
+
+// displayTitleFromCommit derives the title GitHub shows for a run from the
+// first line of its head commit message, falling back to the workflow name.
+func displayTitleFromCommit(e *rawWorkflowRunEvent) {
+	run := &e.WorkflowRun
+	if run.DisplayTitle != nil {
+		return
+	}
+	title := run.Name
+	if run.HeadCommit != nil && run.HeadCommit.Message != "" {
+		title, _, _ = strings.Cut(run.HeadCommit.Message, "\n")
+	}
+	run.DisplayTitle = &title
+}
+
+// runStartedAtFromCreatedAt treats the run as started when it was created.
+func runStartedAtFromCreatedAt(e *rawWorkflowRunEvent) {
+	if e.WorkflowRun.RunStartedAt == nil {
+		e.WorkflowRun.RunStartedAt = e.WorkflowRun.CreatedAt
+	}
+}
+
+// triggeringActorFromActor attributes the run to its actor when the payload
+// predates triggering_actor.
+func triggeringActorFromActor(e *rawWorkflowRunEvent) {
+	if e.WorkflowRun.TriggeringActor == nil {
+		e.WorkflowRun.TriggeringActor = e.WorkflowRun.Actor
+	}
+}
+
+// ParseWorkflowJobEvent normalizes a workflow_job payload. The job status is
+// taken from the event action.
+func ParseWorkflowJobEvent(data []byte) (*models.WorkflowJobEvent, Version, error) {
+	var raw rawWorkflowJobEvent
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, 0, err
+	}
+
+	version := VersionCurrent
+	if raw.Action == "started" || raw.WorkflowJob.CreatedAt == nil {
+		version = VersionLegacy
+	}
+	for _, shim := range jobShims[version] {
+		shim(&raw)
+	}
+
+	job := raw.WorkflowJob
+	labels := job.Labels
+	if labels == nil {
+		labels = []string{}
+	}
+	return &models.WorkflowJobEvent{
+		Action: raw.Action,
+		WorkflowJob: models.WorkflowJob{
+			ID:              job.ID,
+			Name:            job.Name,
+			Status:          models.JobStatus(raw.Action),
+			Labels:          labels,
+			HtmlUrl:         job.HtmlUrl,
+			Conclusion:      stringValue(job.Conclusion),
+			CreatedAt:       timeValue(job.CreatedAt),
+			StartedAt:       timeValue(job.StartedAt),
+			CompletedAt:     timeValue(job.CompletedAt),
+			RunID:           job.RunID,
+			RunnerName:      stringValue(job.RunnerName),
+			RunnerGroupID:   int64Value(job.RunnerGroupID),
+			RunnerGroupName: stringValue(job.RunnerGroupName),
+		},
+	}, version, nil
+}
+
+// ParseWorkflowRunEvent normalizes a workflow_run payload. The run status is
+// taken from the event action and the repository name from the event's
+// repository, or the run's own when the event has none.
+func ParseWorkflowRunEvent(data []byte) (*models.WorkflowRunEvent, Version, error) {
+	var raw rawWorkflowRunEvent
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, 0, err
+	}
+
+	version := VersionCurrent
+	if raw.WorkflowRun.DisplayTitle == nil || raw.WorkflowRun.RunStartedAt == nil {
+		version = VersionLegacy
+	}
+	for _, shim := range runShims[version] {
+		shim(&raw)
+	}
+
+	repository := raw.Repository
+	if repository == nil {
+		repository = raw.WorkflowRun.Repository
+	}
+	if repository == nil {
+		repository = &rawRepository{}
+	}
+
+	run := raw.WorkflowRun
+	runAttempt := run.RunAttempt
+	if runAttempt < 1 {
+		runAttempt = 1
+	}
+	return &models.WorkflowRunEvent{
+		Action:     raw.Action,
+		Repository: models.Repository{Name: repository.Name, Url: repository.Url},
+		WorkflowRun: models.WorkflowRun{
+			ID:             run.ID,
+			Name:           run.Name,
+			Status:         models.JobStatus(raw.Action),
+			HtmlUrl:        run.HtmlUrl,
+			DisplayTitle:   stringValue(run.DisplayTitle),
+			Conclusion:     stringValue(run.Conclusion),
+			CreatedAt:      timeValue(run.CreatedAt),
+			RunStartedAt:   timeValue(run.RunStartedAt),
+			UpdatedAt:      timeValue(run.UpdatedAt),
+			RepositoryName: repository.Name,
+			HeadSha:        run.HeadSha,
+			HeadCommit:     run.HeadCommit,
+
+			Actor:           actorLogin(run.Actor),
+			TriggeringActor: actorLogin(run.TriggeringActor),
+			RunAttempt:      runAttempt,
+			WorkflowPath:    run.Path,
+		},
+	}, version, nil
+}
+
+type rawDeploymentReviewEvent struct {
+	Action          string           `json:"action"`
+	Environment     string           `json:"environment"`
+	WorkflowRun     *rawReviewedRun  `json:"workflow_run"`
+	WorkflowJobRun  *rawReviewedJob  `json:"workflow_job_run"`
+	WorkflowJobRuns []rawReviewedJob `json:"workflow_job_runs"`
+}
+
+type rawReviewedRun struct {
+	ID int64 `json:"id"`
+}
+
+type rawReviewedJob struct {
+	ID          int64  `json:"id"`
+	Environment string `json:"environment"`
+}
+
+// ParseDeploymentReviewEvent normalizes a deployment_review payload. Review
+// requests name a single job and reviews may name several; jobs without an
+// environment of their own take the event's.
+func ParseDeploymentReviewEvent(data []byte) (*models.DeploymentReviewEvent, error) {
+	var raw rawDeploymentReviewEvent
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	event := &models.DeploymentReviewEvent{Action: raw.Action}
+	if raw.WorkflowRun != nil {
+		event.RunID = raw.WorkflowRun.ID
+	}
+	jobs := raw.WorkflowJobRuns
+	if raw.WorkflowJobRun != nil {
+		jobs = append(jobs, *raw.WorkflowJobRun)
+	}
+	for _, job := range jobs {
+		environment := job.Environment
+		if environment == "" {
+			environment = raw.Environment
+		}
+		if job.ID != 0 && environment != "" {
+			event.Jobs = append(event.Jobs, models.EnvironmentJob{ID: job.ID, Environment: environment})
+		}
+	}
+	return event, nil
+}
+
+func actorLogin(a *rawActor) string {
+	if a == nil {
+		return ""
+	}
+	return a.Login
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func timeValue(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
+
+func int64Value(i *int64) int64 {
+	if i == nil {
+		return 0
+	}
+	return *i
+}
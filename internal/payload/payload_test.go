@@ -0,0 +1,132 @@
+package payload
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	return data
+}
+
+func TestParseWorkflowJobEvent(t *testing.T) {
+	tests := []struct {
+		fixture         string
+		version         Version
+		createdAt       time.Time
+		htmlURL         string
+		runnerName      string
+		runnerGroupID   int64
+		runnerGroupName string
+	}{
+		{"workflow_job_current.json", VersionCurrent, time.Date(2024, 5, 2, 10, 14, 58, 0, time.UTC), "https://github.com/octo-org/example-workflow/runs/29679449", "runner-1", 2, "production"},
+		{"workflow_job_legacy.json", VersionLegacy, time.Date(2024, 5, 2, 10, 14, 58, 0, time.UTC), "https://ghes.example.com/octo-org/example-workflow/runs/29679449", "runner-1", 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			event, version, err := ParseWorkflowJobEvent(readFixture(t, tt.fixture))
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.version, version)
+			assert.Equal(t, "in_progress", event.Action)
+			assert.Equal(t, int64(29679449), event.WorkflowJob.ID)
+			assert.Equal(t, int64(2832853555), event.WorkflowJob.RunID)
+			assert.Equal(t, "build", event.WorkflowJob.Name)
+			assert.Equal(t, models.JobStatusInProgress, event.WorkflowJob.Status)
+			assert.Equal(t, []string{"self-hosted", "linux"}, event.WorkflowJob.Labels)
+			assert.Equal(t, tt.htmlURL, event.WorkflowJob.HtmlUrl)
+			assert.Empty(t, event.WorkflowJob.Conclusion)
+			assert.True(t, tt.createdAt.Equal(event.WorkflowJob.CreatedAt), "created_at = %v", event.WorkflowJob.CreatedAt)
+			assert.False(t, event.WorkflowJob.StartedAt.IsZero())
+			assert.True(t, event.WorkflowJob.CompletedAt.IsZero())
+			assert.Equal(t, tt.runnerName, event.WorkflowJob.RunnerName)
+			assert.Equal(t, tt.runnerGroupID, event.WorkflowJob.RunnerGroupID)
+			assert.Equal(t, tt.runnerGroupName, event.WorkflowJob.RunnerGroupName)
+		})
+	}
+}
+
+func TestParseWorkflowJobEvent_MissingLabels(t *testing.T) {
+	event, _, err := ParseWorkflowJobEvent([]byte(`{"action":"queued","workflow_job":{"id":1,"created_at":"2024-05-02T10:14:58Z","labels":null}}`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, event.WorkflowJob.Labels)
+	assert.Equal(t, models.JobStatusQueued, event.WorkflowJob.Status)
+}
+
+func TestParseWorkflowRunEvent(t *testing.T) {
+	tests := []struct {
+		fixture         string
+		version         Version
+		runStartedAt    time.Time
+		triggeringActor string
+		runAttempt      int
+	}{
+		{"workflow_run_current.json", VersionCurrent, time.Date(2024, 5, 2, 10, 14, 55, 0, time.UTC), "hubot", 2},
+		{"workflow_run_legacy.json", VersionLegacy, time.Date(2024, 5, 2, 10, 14, 50, 0, time.UTC), "mona", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			event, version, err := ParseWorkflowRunEvent(readFixture(t, tt.fixture))
+			require.NoError(t, err)
+
+			run := event.WorkflowRun
+			assert.Equal(t, tt.version, version)
+			assert.Equal(t, int64(2832853555), run.ID)
+			assert.Equal(t, "CI", run.Name)
+			assert.Equal(t, models.JobStatusCompleted, run.Status)
+			assert.Equal(t, "success", run.Conclusion)
+			assert.Equal(t, "Fix flaky cache restore", run.DisplayTitle)
+			assert.Equal(t, "example-workflow", run.RepositoryName)
+			assert.Equal(t, "f6c3b2b7a9a7f16d4a3f6a8f5c58f6e1d2c3b4a5", run.HeadSha)
+			require.NotNil(t, run.HeadCommit)
+			assert.Equal(t, "Mona Octocat", run.HeadCommit.Author.Name)
+			assert.True(t, tt.runStartedAt.Equal(run.RunStartedAt), "run_started_at = %v", run.RunStartedAt)
+			assert.False(t, run.UpdatedAt.IsZero())
+			assert.Equal(t, "mona", run.Actor)
+			assert.Equal(t, tt.triggeringActor, run.TriggeringActor)
+			assert.Equal(t, tt.runAttempt, run.RunAttempt)
+		})
+	}
+}
+
+func TestParseWorkflowRunEvent_TitleFallsBackToName(t *testing.T) {
+	event, version, err := ParseWorkflowRunEvent([]byte(`{"action":"requested","workflow_run":{"id":1,"name":"Nightly","created_at":"2024-05-02T10:14:50Z"},"repository":{"name":"app"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, VersionLegacy, version)
+	assert.Equal(t, "Nightly", event.WorkflowRun.DisplayTitle)
+	assert.Nil(t, event.WorkflowRun.HeadCommit)
+	assert.Equal(t, "app", event.WorkflowRun.RepositoryName)
+}
+
+func TestParseInvalidPayload(t *testing.T) {
+	_, _, err := ParseWorkflowJobEvent([]byte(`{invalid`))
+	assert.Error(t, err)
+	_, _, err = ParseWorkflowRunEvent([]byte(`{invalid`))
+	assert.Error(t, err)
+}
+
+func TestParseDeploymentReviewEvent(t *testing.T) {
+	requested, err := ParseDeploymentReviewEvent([]byte(`{"action":"requested","environment":"production","workflow_run":{"id":7},"workflow_job_run":{"id":11,"environment":"production"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, "requested", requested.Action)
+	assert.Equal(t, int64(7), requested.RunID)
+	assert.Equal(t, []models.EnvironmentJob{{ID: 11, Environment: "production"}}, requested.Jobs)
+
+	approved, err := ParseDeploymentReviewEvent([]byte(`{"action":"approved","environment":"staging","workflow_run":{"id":7},"workflow_job_runs":[{"id":12},{"id":13,"environment":"qa"}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, []models.EnvironmentJob{{ID: 12, Environment: "staging"}, {ID: 13, Environment: "qa"}}, approved.Jobs)
+
+	_, err = ParseDeploymentReviewEvent([]byte(`{invalid`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,155 @@
+// Package remotewrite decodes Prometheus remote-write requests, so runner
+// exporters can push host metrics without a Prometheus server in between.
+package remotewrite
+
+import (
+	"fmt"
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// NameLabel is the label holding a series' metric name.
+const NameLabel = "__name__"
+
+// Sample is one value of a series, with its timestamp in milliseconds.
+type Sample struct {
+	Value     float64
+	Timestamp int64
+}
+
+// Series is a time series and the samples sent for it.
+type Series struct {
+	Labels  map[string]string
+	Samples []Sample
+}
+
+// Decode parses a snappy-compressed WriteRequest as sent by remote-write
+// clients. Exemplars, histograms and metadata are skipped.
+func Decode(body []byte) ([]Series, error) {
+	raw, err := decodeSnappy(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var series []Series
+	err = consumeFields(raw, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		if num != 1 || typ != protowire.BytesType {
+			return 0, nil
+		}
+		v, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			return 0, protowire.ParseError(n)
+		}
+		s, err := decodeSeries(v)
+		if err != nil {
+			return 0, err
+		}
+		series = append(series, s)
+		return n, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode write request: %w", err)
+	}
+	return series, nil
+}
+
+// decodeSeries parses a TimeSeries message: labels are field 1, samples field 2.
+func decodeSeries(b []byte) (Series, error) {
+	s := Series{Labels: map[string]string{}}
+	err := consumeFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		if typ != protowire.BytesType || (num != 1 && num != 2) {
+			return 0, nil
+		}
+		v, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			return 0, protowire.ParseError(n)
+		}
+		if num == 1 {
+			name, value, err := decodeLabel(v)
+			if err != nil {
+				return 0, err
+			}
+			s.Labels[name] = value
+		} else {
+			sample, err := decodeSample(v)
+			if err != nil {
+				return 0, err
+			}
+			s.Samples = append(s.Samples, sample)
+		}
+		return n, nil
+	})
+	return s, err
+}
+
+// decodeLabel parses a Label message: name is field 1, value field 2.
+func decodeLabel(b []byte) (name, value string, err error) {
+	err = consumeFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		if typ != protowire.BytesType || (num != 1 && num != 2) {
+			return 0, nil
+		}
+		v, n := protowire.ConsumeString(b)
+		if n < 0 {
+			return 0, protowire.ParseError(n)
+		}
+		if num == 1 {
+			name = v
+		} else {
+			value = v
+		}
+		return n, nil
+	})
+	return name, value, err
+}
+
+// decodeSample parses a Sample message: the value is a double in field 1 and
+// the timestamp an int64 in field 2.
+func decodeSample(b []byte) (Sample, error) {
+	var s Sample
+	err := consumeFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch {
+		case num == 1 && typ == protowire.Fixed64Type:
+			v, n := protowire.ConsumeFixed64(b)
+			if n < 0 {
+				return 0, protowire.ParseError(n)
+			}
+			s.Value = math.Float64frombits(v)
+			return n, nil
+		case num == 2 && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return 0, protowire.ParseError(n)
+			}
+			s.Timestamp = int64(v)
+			return n, nil
+		}
+		return 0, nil
+	})
+	return s, err
+}
+
+// consumeFields calls fn with the value bytes of each field in b. fn returns
+// how many bytes it consumed, or 0 to have the field skipped.
+func consumeFields(b []byte, fn func(protowire.Number, protowire.Type, []byte) (int, error)) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		n, err := fn(num, typ, b)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+		}
+		b = b[n:]
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package remotewrite
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// snappyLiteral encodes b as a snappy block made of a single literal.
+func snappyLiteral(b []byte) []byte {
+	out := binary.AppendUvarint(nil, uint64(len(b)))
+	if len(b) <= 60 {
+		out = append(out, byte(len(b)-1)<<2)
+	} else {
+		out = append(out, 61<<2, byte(len(b)-1), byte((len(b)-1)>>8))
+	}
+	return append(out, b...)
+}
+
+func writeRequest(labels map[string]string, samples ...Sample) []byte {
+	var ts []byte
+	for name, value := range labels {
+		var label []byte
+		label = protowire.AppendTag(label, 1, protowire.BytesType)
+		label = protowire.AppendString(label, name)
+		label = protowire.AppendTag(label, 2, protowire.BytesType)
+		label = protowire.AppendString(label, value)
+		ts = protowire.AppendTag(ts, 1, protowire.BytesType)
+		ts = protowire.AppendBytes(ts, label)
+	}
+	for _, s := range samples {
+		var sample []byte
+		sample = protowire.AppendTag(sample, 1, protowire.Fixed64Type)
+		sample = protowire.AppendFixed64(sample, math.Float64bits(s.Value))
+		sample = protowire.AppendTag(sample, 2, protowire.VarintType)
+		sample = protowire.AppendVarint(sample, uint64(s.Timestamp))
+		ts = protowire.AppendTag(ts, 2, protowire.BytesType)
+		ts = protowire.AppendBytes(ts, sample)
+	}
+
+	var req []byte
+	req = protowire.AppendTag(req, 1, protowire.BytesType)
+	req = protowire.AppendBytes(req, ts)
+	// Metadata (field 3) is skipped
+	req = protowire.AppendTag(req, 3, protowire.BytesType)
+	req = protowire.AppendBytes(req, []byte{0x08, 0x01})
+	return req
+}
+
+func TestDecode(t *testing.T) {
+	body := snappyLiteral(writeRequest(
+		map[string]string{NameLabel: "node_load1", "instance": "runner-1:9100"},
+		Sample{Value: 0.5, Timestamp: 1714644000000},
+		Sample{Value: 1.25, Timestamp: 1714644015000},
+	))
+
+	series, err := Decode(body)
+	require.NoError(t, err)
+	require.Len(t, series, 1)
+	assert.Equal(t, map[string]string{NameLabel: "node_load1", "instance": "runner-1:9100"}, series[0].Labels)
+	assert.Equal(t, []Sample{{0.5, 1714644000000}, {1.25, 1714644015000}}, series[0].Samples)
+}
+
+func TestDecode_Corrupt(t *testing.T) {
+	valid := snappyLiteral(writeRequest(map[string]string{NameLabel: "up"}, Sample{Value: 1, Timestamp: 1}))
+
+	for name, body := range map[string][]byte{
+		"empty":              {},
+		"truncated snappy":   valid[:len(valid)-3],
+		"not protobuf":       snappyLiteral([]byte{0xff, 0xff, 0xff}),
+		"length too large":   binary.AppendUvarint(nil, maxDecodedSize+1),
+		"copy before output": {0x04, 0x01 | 0<<2, 0x01},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Decode(body)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestDecodeSnappy_Copies(t *testing.T) {
+	// "abcd" as a literal, then a 1-byte-offset copy of 8 bytes at offset 4
+	// that overlaps its own output
+	src := []byte{12, 3 << 2, 'a', 'b', 'c', 'd', 0x01 | 4<<2, 4}
+	out, err := decodeSnappy(src)
+	require.NoError(t, err)
+	assert.Equal(t, "abcdabcdabcd", string(out))
+
+	// The same with a 2-byte offset
+	src = []byte{12, 3 << 2, 'a', 'b', 'c', 'd', 0x02 | 7<<2, 4, 0}
+	out, err = decodeSnappy(src)
+	require.NoError(t, err)
+	assert.Equal(t, "abcdabcdabcd", string(out))
+}
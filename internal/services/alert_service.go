@@ -0,0 +1,344 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/config"
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/internal/notify"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// UnschedulableQueuedFor is how long a job must wait on an unknown runner
+// label before it is reported as unschedulable.
+const UnschedulableQueuedFor = 10 * time.Minute
+
+// ProcessingLagWindow is how far back processed events count towards the
+// processing lag SLO.
+const ProcessingLagWindow = 5 * time.Minute
+
+// AlertService periodically checks for conditions that need attention and
+// raises alerts through the notifier. Each condition is alerted once until it
+// clears.
+type AlertService struct {
+	config      *config.Config
+	db          database.DatabaseInterface
+	notifier    *notify.Notifier
+	degradation *Degradation
+	interval    time.Duration
+	ctx         context.Context
+	cancel      context.CancelFunc
+	done        chan struct{}
+
+	// unschedulableRuns holds the runs already alerted for unschedulable jobs
+	unschedulableRuns map[int64]struct{}
+	// poolQueues holds the queue depth seen per self-hosted label set on the last check
+	poolQueues map[string]int
+	// offlinePools holds the label sets already alerted as offline
+	offlinePools map[string]struct{}
+	// lagBreached is set while the processing lag SLO is breached
+	lagBreached bool
+	// burningSLOs holds the workflow SLO objectives already alerted for burning
+	// their error budget, keyed by SLO name and objective
+	burningSLOs map[string]struct{}
+}
+
+// NewAlertService creates a new alert service instance. Processing lag and
+// event backlog are also reported to degradation, which may be nil.
+func NewAlertService(config *config.Config, db database.DatabaseInterface, notifier *notify.Notifier, degradation *Degradation, interval time.Duration, ctx context.Context) *AlertService {
+	ctx, cancel := context.WithCancel(ctx)
+
+	return &AlertService{
+		config:            config,
+		db:                db,
+		notifier:          notifier,
+		degradation:       degradation,
+		interval:          interval,
+		ctx:               ctx,
+		cancel:            cancel,
+		done:              make(chan struct{}),
+		unschedulableRuns: make(map[int64]struct{}),
+		poolQueues:        make(map[string]int),
+		offlinePools:      make(map[string]struct{}),
+		burningSLOs:       make(map[string]struct{}),
+	}
+}
+
+// Start runs the checks periodically until Stop is called
+func (s *AlertService) Start() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			logger.Logger.Debug("Alert service stopped")
+			return
+		case <-ticker.C:
+			s.runChecks()
+		}
+	}
+}
+
+// Stop gracefully stops the alert service
+func (s *AlertService) Stop() {
+	s.cancel()
+	<-s.done
+}
+
+func (s *AlertService) runChecks() {
+	s.checkUnschedulableJobs()
+	s.checkOfflinePools(s.config.Now())
+	s.checkProcessingLag()
+	s.checkSLOBurnRates(s.config.Now())
+}
+
+// checkUnschedulableJobs alerts once per run that has jobs stuck on runner
+// labels no job has ever run on.
+func (s *AlertService) checkUnschedulableJobs() {
+	jobs, err := s.db.GetUnschedulableJobs(s.ctx, UnschedulableQueuedFor, nil)
+	if err != nil {
+		logger.Logger.Error("Failed to check for unschedulable jobs", zap.Error(err))
+		return
+	}
+
+	byRun := make(map[int64][]models.UnschedulableJob)
+	var runIDs []int64
+	for _, job := range jobs {
+		if _, ok := byRun[job.RunID]; !ok {
+			runIDs = append(runIDs, job.RunID)
+		}
+		byRun[job.RunID] = append(byRun[job.RunID], job)
+	}
+
+	// Forget runs whose jobs were picked up or cancelled so they alert again if they recur
+	for runID := range s.unschedulableRuns {
+		if _, ok := byRun[runID]; !ok {
+			delete(s.unschedulableRuns, runID)
+		}
+	}
+
+	for _, runID := range runIDs {
+		if _, alerted := s.unschedulableRuns[runID]; alerted {
+			continue
+		}
+		s.unschedulableRuns[runID] = struct{}{}
+		s.notifier.Notify(s.ctx, unschedulableAlert(byRun[runID]))
+	}
+}
+
+func unschedulableAlert(jobs []models.UnschedulableJob) models.Alert {
+	seen := make(map[string]struct{})
+	var labels []string
+	for _, job := range jobs {
+		for _, label := range job.UnknownLabels {
+			if _, ok := seen[label]; !ok {
+				seen[label] = struct{}{}
+				labels = append(labels, label)
+			}
+		}
+	}
+	sort.Strings(labels)
+
+	first := jobs[0]
+	return models.Alert{
+		Type:  "unschedulable_jobs",
+		Title: fmt.Sprintf("%d job(s) waiting on unknown runner labels", len(jobs)),
+		Message: fmt.Sprintf("Jobs in run %d of %s have been queued for over %s requesting labels no runner has picked up before: %s. Check runs-on for typos.",
+			first.RunID, first.Repository, UnschedulableQueuedFor, strings.Join(labels, ", ")),
+		Repository: first.Repository,
+		RunID:      first.RunID,
+		HtmlUrl:    first.RunHtmlUrl,
+		Data:       jobs,
+	}
+}
+
+// checkOfflinePools alerts when a self-hosted label set's queue grows while no
+// job has started on it for the offline threshold. Busy pools keep starting
+// jobs, so a silent pool with a growing queue points at runners that are down
+// rather than undersized.
+func (s *AlertService) checkOfflinePools(now time.Time) {
+	pools, err := s.db.GetRunnerPoolStatus(s.ctx)
+	if err != nil {
+		logger.Logger.Error("Failed to check runner pools", zap.Error(err))
+		return
+	}
+
+	threshold := s.config.GetRunnerOfflineThreshold()
+	cutoff := now.Add(-threshold)
+
+	queues := make(map[string]int, len(pools))
+	for _, pool := range pools {
+		key := strings.Join(pool.Labels, ",")
+		queues[key] = pool.Queued
+
+		idle := pool.LastStartedAt.Before(cutoff) && pool.OldestQueuedAt.Before(cutoff)
+		if !idle {
+			delete(s.offlinePools, key)
+			continue
+		}
+		if _, alerted := s.offlinePools[key]; alerted || pool.Queued <= s.poolQueues[key] {
+			continue
+		}
+
+		s.offlinePools[key] = struct{}{}
+		s.notifier.Notify(s.ctx, offlinePoolAlert(pool, threshold))
+	}
+
+	// Pools that drained are no longer offline
+	for key := range s.offlinePools {
+		if _, ok := queues[key]; !ok {
+			delete(s.offlinePools, key)
+		}
+	}
+	s.poolQueues = queues
+}
+
+func offlinePoolAlert(pool models.RunnerPoolStatus, threshold time.Duration) models.Alert {
+	labels := strings.Join(pool.Labels, ", ")
+	return models.Alert{
+		Type:  "runner_pool_offline",
+		Title: fmt.Sprintf("Runner pool [%s] appears offline", labels),
+		Message: fmt.Sprintf("%d job(s) are queued for runners labeled [%s] and none has started in over %s. Check that the runners are online.",
+			pool.Queued, labels, threshold),
+		Data: pool,
+	}
+}
+
+// LagExceedsSLO reports whether recently processed events were slower than
+// slo at the 95th percentile, or an event has been pending for longer than
+// slo. The latter catches a stalled processor that no longer produces samples.
+func LagExceedsSLO(stats *models.ProcessingLagStats, slo time.Duration) bool {
+	limit := slo.Seconds()
+	return stats.P95 > limit || stats.OldestPendingSeconds > limit
+}
+
+// checkProcessingLag alerts when this instance falls behind on processing
+// webhook events, so a delay on the dashboard is not mistaken for slow runners.
+// While it is behind, or the backlog of pending events is high, API responses
+// carry a warning.
+func (s *AlertService) checkProcessingLag() {
+	stats, err := s.db.GetProcessingLagStats(s.ctx, ProcessingLagWindow)
+	if err != nil {
+		logger.Logger.Error("Failed to check processing lag", zap.Error(err))
+		return
+	}
+
+	now := s.config.Now()
+	if threshold := s.config.Vars.EventBacklogWarning; stats.Pending >= threshold {
+		s.degradation.Set(WarningEventBacklog,
+			fmt.Sprintf("%d webhook events are waiting to be processed; data may be delayed.", stats.Pending), now)
+	} else {
+		s.degradation.Clear(WarningEventBacklog)
+	}
+
+	slo := s.config.GetProcessingLagSLO()
+	if !LagExceedsSLO(stats, slo) {
+		s.degradation.Clear(WarningProcessingLag)
+		s.lagBreached = false
+		return
+	}
+	s.degradation.Set(WarningProcessingLag,
+		fmt.Sprintf("Webhook processing is behind the %s SLO; data may be delayed.", slo), now)
+	if s.lagBreached {
+		return
+	}
+
+	s.lagBreached = true
+	alert := models.Alert{
+		Type:  "processing_lag_slo",
+		Title: "Webhook processing is falling behind",
+		Message: fmt.Sprintf("p95 processing lag is %.0fs and the oldest of %d pending event(s) has waited %.0fs, over the %s SLO. The dashboard may be showing stale data.",
+			stats.P95, stats.Pending, stats.OldestPendingSeconds, slo),
+		Data: stats,
+	}
+	s.notifier.Notify(s.ctx, alert)
+	s.recordBreach(alert, "", now)
+}
+
+// checkSLOBurnRates alerts once per workflow SLO objective whose error budget
+// is burning at least SLO_BURN_RATE_ALERT times faster than sustainable over
+// both the fast and the slow burn rate windows.
+func (s *AlertService) checkSLOBurnRates(now time.Time) {
+	threshold := float64(s.config.Vars.SLOBurnRateAlert)
+	if threshold == 0 {
+		return
+	}
+
+	slos, err := WorkflowSLOs(s.ctx, s.config, s.db)
+	if err != nil {
+		logger.Logger.Error("Failed to get workflow SLOs", zap.Error(err))
+		return
+	}
+
+	burning := make(map[string]struct{})
+	for _, slo := range slos {
+		runs, err := s.db.GetSLORuns(s.ctx, slo.Repository, slo.Workflow, BurnRateSlowWindow)
+		if err != nil {
+			logger.Logger.Error("Failed to check workflow SLO", zap.String("slo", slo.Name), zap.Error(err))
+			// Keep its alerts until it can be checked again
+			for key := range s.burningSLOs {
+				if strings.HasPrefix(key, slo.Name+"/") {
+					burning[key] = struct{}{}
+				}
+			}
+			continue
+		}
+
+		for _, objective := range sloObjectives(slo) {
+			allowed := 1 - objectiveTarget(slo, objective)/100
+			fast := burnRate(slo, objective, runs, now.Add(-BurnRateFastWindow), allowed)
+			slow := burnRate(slo, objective, runs, now.Add(-BurnRateSlowWindow), allowed)
+			if fast < threshold || slow < threshold {
+				continue
+			}
+
+			key := slo.Name + "/" + objective
+			burning[key] = struct{}{}
+			if _, alerted := s.burningSLOs[key]; !alerted {
+				alert := sloBurnAlert(slo, objective, fast, slow)
+				s.notifier.Notify(s.ctx, alert)
+				s.recordBreach(alert, slo.Workflow, now)
+			}
+		}
+	}
+	s.burningSLOs = burning
+}
+
+// recordBreach stores the alert of a breached SLO for the activity feed.
+func (s *AlertService) recordBreach(alert models.Alert, workflow string, now time.Time) {
+	breach := models.SLOBreach{
+		Kind:       alert.Type,
+		Title:      alert.Title,
+		Message:    alert.Message,
+		Repository: alert.Repository,
+		Workflow:   workflow,
+		StartedAt:  now,
+	}
+	if err := s.db.RecordSLOBreach(s.ctx, breach); err != nil {
+		logger.Logger.Error("Failed to record SLO breach", zap.String("type", alert.Type), zap.Error(err))
+	}
+}
+
+func sloBurnAlert(slo models.WorkflowSLO, objective string, fast, slow float64) models.Alert {
+	goal := fmt.Sprintf("%g%% of runs succeeding", slo.SuccessTarget)
+	if objective == ObjectiveDuration {
+		goal = fmt.Sprintf("p%d duration under %s", slo.DurationPercentile, time.Duration(slo.DurationTargetSeconds)*time.Second)
+	}
+	return models.Alert{
+		Type:  "slo_burn_rate",
+		Title: fmt.Sprintf("SLO %s is burning its error budget", slo.Name),
+		Message: fmt.Sprintf("%s in %s is spending the error budget of its %s objective (%s over %d days) %.1fx too fast over the last hour and %.1fx over the last 6 hours.",
+			slo.Workflow, slo.Repository, objective, goal, slo.WindowDays, fast, slow),
+		Repository: slo.Repository,
+		Data:       slo,
+	}
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/config"
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/internal/notify"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAlertService(mockDB *database.MockDatabase) (*AlertService, *[]models.Alert) {
+	setupTestLogger()
+	var alerts []models.Alert
+	notifier := notify.NewNotifier("", mockDB, func(a models.Alert) { alerts = append(alerts, a) })
+	mockDB.On("IsMuted", mock.Anything, mock.Anything, mock.Anything).Return(false, nil).Maybe()
+	cfg := &config.Config{Vars: config.Vars{RunnerOfflineMinutes: 15, ProcessingLagSLOSeconds: 60, EventBacklogWarning: 30}}
+	return NewAlertService(cfg, mockDB, notifier, NewDegradation(nil), time.Minute, context.Background()), &alerts
+}
+
+func TestAlertService_UnschedulableJobs(t *testing.T) {
+	mockDB := new(database.MockDatabase)
+	service, alerts := newTestAlertService(mockDB)
+
+	stuck := []models.UnschedulableJob{
+		{ID: 1, RunID: 10, Repository: "app", UnknownLabels: []string{"ubunut"}},
+		{ID: 2, RunID: 10, Repository: "app", UnknownLabels: []string{"gpu", "ubunut"}},
+	}
+	mockDB.On("GetUnschedulableJobs", mock.Anything, UnschedulableQueuedFor, []string(nil)).Return(stuck, nil).Twice()
+
+	service.checkUnschedulableJobs()
+	service.checkUnschedulableJobs()
+
+	require.Len(t, *alerts, 1, "a run is alerted once while its jobs stay stuck")
+	alert := (*alerts)[0]
+	assert.Equal(t, "unschedulable_jobs", alert.Type)
+	assert.Equal(t, int64(10), alert.RunID)
+	assert.Contains(t, alert.Message, "gpu, ubunut")
+
+	// Once the jobs clear, a recurrence alerts again
+	mockDB.On("GetUnschedulableJobs", mock.Anything, UnschedulableQueuedFor, []string(nil)).Return([]models.UnschedulableJob{}, nil).Once()
+	service.checkUnschedulableJobs()
+	mockDB.On("GetUnschedulableJobs", mock.Anything, UnschedulableQueuedFor, []string(nil)).Return(stuck, nil).Once()
+	service.checkUnschedulableJobs()
+
+	assert.Len(t, *alerts, 2)
+}
+
+func TestAlertService_UnschedulableJobsError(t *testing.T) {
+	mockDB := new(database.MockDatabase)
+	service, alerts := newTestAlertService(mockDB)
+
+	mockDB.On("GetUnschedulableJobs", mock.Anything, UnschedulableQueuedFor, []string(nil)).Return([]models.UnschedulableJob{}, errors.New("db error"))
+
+	service.checkUnschedulableJobs()
+
+	assert.Empty(t, *alerts)
+}
+
+func TestAlertService_OfflinePools(t *testing.T) {
+	mockDB := new(database.MockDatabase)
+	service, alerts := newTestAlertService(mockDB)
+	now := time.Now()
+
+	pool := func(queued int, lastStarted time.Time) []models.RunnerPoolStatus {
+		return []models.RunnerPoolStatus{{
+			Labels:         []string{"self-hosted", "gpu"},
+			Queued:         queued,
+			OldestQueuedAt: now.Add(-time.Hour),
+			LastStartedAt:  lastStarted,
+		}}
+	}
+
+	// Queue grows while nothing has started for an hour
+	mockDB.On("GetRunnerPoolStatus", mock.Anything).Return(pool(3, now.Add(-time.Hour)), nil).Once()
+	service.checkOfflinePools(now)
+	require.Len(t, *alerts, 1)
+	assert.Equal(t, "runner_pool_offline", (*alerts)[0].Type)
+	assert.Contains(t, (*alerts)[0].Message, "3 job(s) are queued for runners labeled [self-hosted, gpu]")
+
+	// Still offline and growing: no repeat
+	mockDB.On("GetRunnerPoolStatus", mock.Anything).Return(pool(5, now.Add(-time.Hour)), nil).Once()
+	service.checkOfflinePools(now)
+	assert.Len(t, *alerts, 1)
+
+	// A job starts, so the pool recovers
+	mockDB.On("GetRunnerPoolStatus", mock.Anything).Return(pool(4, now), nil).Once()
+	service.checkOfflinePools(now)
+
+	// A stable queue after recovery is not a new outage
+	mockDB.On("GetRunnerPoolStatus", mock.Anything).Return(pool(4, now.Add(-time.Hour)), nil).Once()
+	service.checkOfflinePools(now)
+	assert.Len(t, *alerts, 1)
+
+	// It grows again
+	mockDB.On("GetRunnerPoolStatus", mock.Anything).Return(pool(6, now.Add(-time.Hour)), nil).Once()
+	service.checkOfflinePools(now)
+	assert.Len(t, *alerts, 2)
+}
+
+func TestAlertService_PoolWithRecentStartsIsOnline(t *testing.T) {
+	mockDB := new(database.MockDatabase)
+	service, alerts := newTestAlertService(mockDB)
+	now := time.Now()
+
+	mockDB.On("GetRunnerPoolStatus", mock.Anything).Return([]models.RunnerPoolStatus{{
+		Labels:         []string{"self-hosted"},
+		Queued:         20,
+		OldestQueuedAt: now.Add(-time.Hour),
+		LastStartedAt:  now.Add(-time.Minute),
+	}}, nil)
+
+	service.checkOfflinePools(now)
+
+	assert.Empty(t, *alerts)
+}
+
+func TestAlertService_StartStop(t *testing.T) {
+	mockDB := new(database.MockDatabase)
+	service, _ := newTestAlertService(mockDB)
+
+	go service.Start()
+	service.Stop()
+}
+
+func TestAlertService_ProcessingLag(t *testing.T) {
+	mockDB := new(database.MockDatabase)
+	service, alerts := newTestAlertService(mockDB)
+
+	healthy := &models.ProcessingLagStats{Samples: 50, P95: 14, Pending: 2, OldestPendingSeconds: 8}
+	slow := &models.ProcessingLagStats{Samples: 50, P95: 95, Pending: 2, OldestPendingSeconds: 8}
+	stalled := &models.ProcessingLagStats{Samples: 0, Pending: 40, OldestPendingSeconds: 300}
+	mockDB.On("RecordSLOBreach", mock.Anything, mock.MatchedBy(func(b models.SLOBreach) bool {
+		return b.Kind == "processing_lag_slo" && b.Workflow == ""
+	})).Return(nil)
+
+	mockDB.On("GetProcessingLagStats", mock.Anything, ProcessingLagWindow).Return(healthy, nil).Once()
+	service.checkProcessingLag()
+	assert.Empty(t, *alerts)
+	assert.Empty(t, service.degradation.Warnings())
+
+	mockDB.On("GetProcessingLagStats", mock.Anything, ProcessingLagWindow).Return(slow, nil).Once()
+	mockDB.On("GetProcessingLagStats", mock.Anything, ProcessingLagWindow).Return(stalled, nil).Once()
+	service.checkProcessingLag()
+	service.checkProcessingLag()
+	require.Len(t, *alerts, 1, "a breach is alerted once until it recovers")
+	assert.Equal(t, "processing_lag_slo", (*alerts)[0].Type)
+	warnings := service.degradation.Warnings()
+	require.Len(t, warnings, 2, "the stalled backlog is over the warning threshold")
+	assert.Equal(t, WarningEventBacklog, warnings[0].Code)
+	assert.Contains(t, warnings[0].Message, "40 webhook events", "the message reports the backlog, not the threshold")
+	assert.Equal(t, WarningProcessingLag, warnings[1].Code)
+
+	mockDB.On("GetProcessingLagStats", mock.Anything, ProcessingLagWindow).Return(healthy, nil).Once()
+	service.checkProcessingLag()
+	assert.Empty(t, service.degradation.Warnings(), "warnings clear once processing recovers")
+
+	mockDB.On("GetProcessingLagStats", mock.Anything, ProcessingLagWindow).Return(stalled, nil).Once()
+	service.checkProcessingLag()
+	assert.Len(t, *alerts, 2)
+	mockDB.AssertNumberOfCalls(t, "RecordSLOBreach", 2)
+}
+
+func TestAlertService_ProcessingLagError(t *testing.T) {
+	mockDB := new(database.MockDatabase)
+	service, alerts := newTestAlertService(mockDB)
+
+	mockDB.On("GetProcessingLagStats", mock.Anything, ProcessingLagWindow).Return(nil, errors.New("db error"))
+
+	service.checkProcessingLag()
+
+	assert.Empty(t, *alerts)
+}
+
+func TestAlertService_SLOBurnRates(t *testing.T) {
+	mockDB := new(database.MockDatabase)
+	service, alerts := newTestAlertService(mockDB)
+	service.config.Vars.SLOBurnRateAlert = 10
+	now := time.Now()
+
+	mockDB.On("GetWorkflowSLOs", mock.Anything).Return([]models.WorkflowSLO{
+		{Name: "deploy", Repository: "app", Workflow: "Deploy", SuccessTarget: 99, WindowDays: 28, Source: "api"},
+	}, nil)
+	failing := []models.SLORun{
+		{Conclusion: "success", CompletedAt: now.Add(-5 * time.Hour), Seconds: 60},
+		{Conclusion: "failure", CompletedAt: now.Add(-30 * time.Minute), Seconds: 60},
+	}
+	recovered := []models.SLORun{
+		{Conclusion: "failure", CompletedAt: now.Add(-5 * time.Hour), Seconds: 60},
+		{Conclusion: "success", CompletedAt: now.Add(-30 * time.Minute), Seconds: 60},
+	}
+
+	mockDB.On("RecordSLOBreach", mock.Anything, mock.MatchedBy(func(b models.SLOBreach) bool {
+		return b.Kind == "slo_burn_rate" && b.Repository == "app" && b.Workflow == "Deploy" && b.StartedAt.Equal(now)
+	})).Return(nil)
+	mockDB.On("GetSLORuns", mock.Anything, "app", "Deploy", BurnRateSlowWindow).Return(failing, nil).Twice()
+	service.checkSLOBurnRates(now)
+	service.checkSLOBurnRates(now)
+
+	require.Len(t, *alerts, 1, "a burning objective is alerted once until it recovers")
+	alert := (*alerts)[0]
+	assert.Equal(t, "slo_burn_rate", alert.Type)
+	assert.Equal(t, "app", alert.Repository)
+	assert.Contains(t, alert.Message, "100.0x too fast over the last hour")
+
+	// A failure that is no longer in the fast window does not alert
+	mockDB.On("GetSLORuns", mock.Anything, "app", "Deploy", BurnRateSlowWindow).Return(recovered, nil).Once()
+	service.checkSLOBurnRates(now)
+	mockDB.On("GetSLORuns", mock.Anything, "app", "Deploy", BurnRateSlowWindow).Return(failing, nil).Once()
+	service.checkSLOBurnRates(now)
+
+	assert.Len(t, *alerts, 2)
+	mockDB.AssertNumberOfCalls(t, "RecordSLOBreach", 2)
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/config"
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/internal/github"
+	"github.com/gateixeira/live-actions/internal/notify"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// canaryPollInterval is how often GitHub is polled for the dispatched run.
+	canaryPollInterval = 10 * time.Second
+	// canaryClockSkew widens the search for the dispatched run to allow for
+	// clock differences between this server and GitHub.
+	canaryClockSkew = 5 * time.Second
+)
+
+// CanaryService periodically dispatches a canary workflow and measures how
+// long it takes from dispatch to completion, catching outages that would
+// otherwise only show up as missing webhooks. Failures and slow runs are
+// alerted once until a probe succeeds again.
+type CanaryService struct {
+	config       *config.Config
+	db           database.DatabaseInterface
+	notifier     *notify.Notifier
+	client       *github.Client
+	pollInterval time.Duration
+	ctx          context.Context
+	cancel       context.CancelFunc
+	done         chan struct{}
+
+	// alerting is set while the canary is failing or slow
+	alerting bool
+}
+
+// NewCanaryService creates a new canary service instance
+func NewCanaryService(config *config.Config, db database.DatabaseInterface, notifier *notify.Notifier, ctx context.Context) *CanaryService {
+	ctx, cancel := context.WithCancel(ctx)
+
+	return &CanaryService{
+		config:       config,
+		db:           db,
+		notifier:     notifier,
+		client:       github.NewClient(config.Vars.GitHubAPIURL, config.Vars.CanaryToken),
+		pollInterval: canaryPollInterval,
+		ctx:          ctx,
+		cancel:       cancel,
+		done:         make(chan struct{}),
+	}
+}
+
+// Start dispatches the canary periodically until Stop is called
+func (s *CanaryService) Start() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.config.GetCanaryInterval())
+	defer ticker.Stop()
+
+	logger.Logger.Info("Canary service started",
+		zap.String("repository", s.config.Vars.CanaryRepository),
+		zap.String("workflow", s.config.Vars.CanaryWorkflow),
+		zap.Duration("interval", s.config.GetCanaryInterval()))
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			logger.Logger.Debug("Canary service stopped")
+			return
+		case <-ticker.C:
+			s.runProbe()
+		}
+	}
+}
+
+// Stop gracefully stops the canary service
+func (s *CanaryService) Stop() {
+	s.cancel()
+	<-s.done
+}
+
+func (s *CanaryService) runProbe() {
+	result := s.probe()
+	if s.ctx.Err() != nil {
+		// Shutting down mid-probe says nothing about GitHub
+		return
+	}
+
+	logger.Logger.Info("Canary probe finished",
+		zap.String("outcome", result.Outcome),
+		zap.Int64("run_id", result.RunID),
+		zap.Float64("duration_seconds", result.DurationSeconds),
+		zap.String("error", result.Error))
+
+	if err := s.db.SaveCanaryResult(s.ctx, result); err != nil {
+		logger.Logger.Error("Failed to save canary result", zap.Error(err))
+	}
+	s.alert(result)
+}
+
+// probe dispatches the canary workflow and follows the run it creates until
+// it completes or the timeout passes.
+func (s *CanaryService) probe() models.CanaryResult {
+	vars := s.config.Vars
+	dispatchedAt := time.Now().UTC()
+	result := models.CanaryResult{DispatchedAt: dispatchedAt}
+
+	ctx, cancel := context.WithDeadline(s.ctx, dispatchedAt.Add(s.config.GetCanaryTimeout()))
+	defer cancel()
+
+	if err := s.client.DispatchWorkflow(ctx, vars.CanaryRepository, vars.CanaryWorkflow, vars.CanaryRef); err != nil {
+		result.Outcome = models.CanaryOutcomeError
+		result.Error = err.Error()
+		return result
+	}
+
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	var run *github.Run
+	var lastErr error
+	for {
+		select {
+		case <-ctx.Done():
+			result.DurationSeconds = time.Since(dispatchedAt).Seconds()
+			if run == nil && lastErr != nil {
+				result.Outcome = models.CanaryOutcomeError
+				result.Error = lastErr.Error()
+			} else {
+				result.Outcome = models.CanaryOutcomeTimeout
+			}
+			return result
+		case <-ticker.C:
+		}
+
+		// API errors are retried until the timeout, as GitHub may only be
+		// briefly unavailable
+		var err error
+		if run == nil {
+			run, err = s.client.FindDispatchedRun(ctx, vars.CanaryRepository, vars.CanaryWorkflow, dispatchedAt.Add(-canaryClockSkew))
+		} else {
+			var latest *github.Run
+			if latest, err = s.client.GetRun(ctx, vars.CanaryRepository, run.ID); err == nil {
+				run = latest
+			}
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if run == nil {
+			continue
+		}
+
+		result.RunID = run.ID
+		result.HtmlUrl = run.HtmlUrl
+		if run.Status != "completed" {
+			continue
+		}
+
+		result.Conclusion = run.Conclusion
+		result.DurationSeconds = canaryDuration(dispatchedAt, run.UpdatedAt, time.Now())
+		switch {
+		case run.Conclusion != "success":
+			result.Outcome = models.CanaryOutcomeFailure
+		case result.DurationSeconds > float64(vars.CanarySlowSeconds):
+			result.Outcome = models.CanaryOutcomeSlow
+		default:
+			result.Outcome = models.CanaryOutcomeSuccess
+		}
+		return result
+	}
+}
+
+// canaryDuration measures a run from dispatch to its completion as reported
+// by GitHub, falling back to when completion was observed if GitHub's clock
+// places it before the dispatch.
+func canaryDuration(dispatchedAt, completedAt, observedAt time.Time) float64 {
+	if completedAt.After(dispatchedAt) && completedAt.Before(observedAt) {
+		return completedAt.Sub(dispatchedAt).Seconds()
+	}
+	return observedAt.Sub(dispatchedAt).Seconds()
+}
+
+// alert raises an alert when the canary starts failing or slows down, and
+// rearms once a probe succeeds.
+func (s *CanaryService) alert(result models.CanaryResult) {
+	if result.Outcome == models.CanaryOutcomeSuccess {
+		s.alerting = false
+		return
+	}
+	if s.alerting {
+		return
+	}
+	s.alerting = true
+
+	vars := s.config.Vars
+	alert := models.Alert{
+		Type:    "canary_failed",
+		Title:   "Canary workflow failed",
+		HtmlUrl: result.HtmlUrl,
+		Data:    result,
+	}
+	switch result.Outcome {
+	case models.CanaryOutcomeSlow:
+		alert.Type = "canary_slow"
+		alert.Title = "Canary workflow is slow"
+		alert.Message = fmt.Sprintf("%s in %s took %.0fs from dispatch to completion, over the %ds threshold.",
+			vars.CanaryWorkflow, vars.CanaryRepository, result.DurationSeconds, vars.CanarySlowSeconds)
+	case models.CanaryOutcomeFailure:
+		alert.Message = fmt.Sprintf("%s in %s completed with conclusion %q.",
+			vars.CanaryWorkflow, vars.CanaryRepository, result.Conclusion)
+	case models.CanaryOutcomeTimeout:
+		alert.Message = fmt.Sprintf("%s in %s did not complete within %s of being dispatched.",
+			vars.CanaryWorkflow, vars.CanaryRepository, s.config.GetCanaryTimeout())
+	default:
+		alert.Message = fmt.Sprintf("%s in %s could not be dispatched or followed: %s",
+			vars.CanaryWorkflow, vars.CanaryRepository, result.Error)
+	}
+	s.notifier.Notify(s.ctx, alert)
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/config"
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/internal/notify"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGitHub serves the dispatch, list and get run endpoints for a canary
+// run that completes with conclusion after pending polls.
+func fakeGitHub(t *testing.T, dispatchStatus int, conclusion string, pending int32) *httptest.Server {
+	var polls int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/octo-org/canary/actions/workflows/canary.yml/dispatches", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		w.WriteHeader(dispatchStatus)
+	})
+	mux.HandleFunc("/repos/octo-org/canary/actions/workflows/canary.yml/runs", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "workflow_dispatch", r.URL.Query().Get("event"))
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"workflow_runs": []map[string]interface{}{
+				{"id": 7, "status": "queued", "html_url": "https://github.com/octo-org/canary/actions/runs/7", "created_at": time.Now().UTC()},
+			},
+		})
+	})
+	mux.HandleFunc("/repos/octo-org/canary/actions/runs/7", func(w http.ResponseWriter, r *http.Request) {
+		run := map[string]interface{}{"id": 7, "status": "in_progress", "html_url": "https://github.com/octo-org/canary/actions/runs/7"}
+		if atomic.AddInt32(&polls, 1) > pending {
+			run["status"] = "completed"
+			run["conclusion"] = conclusion
+			run["updated_at"] = time.Now().UTC()
+		}
+		json.NewEncoder(w).Encode(run)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestCanaryService(t *testing.T, mockDB *database.MockDatabase, apiURL string) (*CanaryService, *[]models.Alert) {
+	setupTestLogger()
+	var alerts []models.Alert
+	notifier := notify.NewNotifier("", mockDB, func(a models.Alert) { alerts = append(alerts, a) })
+	cfg := &config.Config{Vars: config.Vars{
+		GitHubAPIURL:          apiURL,
+		CanaryToken:           "token",
+		CanaryRepository:      "octo-org/canary",
+		CanaryWorkflow:        "canary.yml",
+		CanaryRef:             "main",
+		CanaryIntervalMinutes: 30,
+		CanaryTimeoutMinutes:  1,
+		CanarySlowSeconds:     300,
+	}}
+	service := NewCanaryService(cfg, mockDB, notifier, context.Background())
+	service.pollInterval = 10 * time.Millisecond
+	return service, &alerts
+}
+
+func TestCanaryService_Success(t *testing.T) {
+	mockDB := new(database.MockDatabase)
+	service, alerts := newTestCanaryService(t, mockDB, fakeGitHub(t, http.StatusNoContent, "success", 2).URL)
+
+	var saved models.CanaryResult
+	mockDB.On("SaveCanaryResult", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).(models.CanaryResult)
+	}).Return(nil).Once()
+
+	service.runProbe()
+
+	mockDB.AssertExpectations(t)
+	assert.Equal(t, models.CanaryOutcomeSuccess, saved.Outcome)
+	assert.Equal(t, int64(7), saved.RunID)
+	assert.Equal(t, "success", saved.Conclusion)
+	assert.Equal(t, "https://github.com/octo-org/canary/actions/runs/7", saved.HtmlUrl)
+	assert.Greater(t, saved.DurationSeconds, 0.0)
+	assert.Empty(t, *alerts)
+}
+
+func TestCanaryService_SlowRun(t *testing.T) {
+	mockDB := new(database.MockDatabase)
+	service, alerts := newTestCanaryService(t, mockDB, fakeGitHub(t, http.StatusNoContent, "success", 0).URL)
+	service.config.Vars.CanarySlowSeconds = 0
+
+	mockDB.On("SaveCanaryResult", mock.Anything, mock.MatchedBy(func(r models.CanaryResult) bool {
+		return r.Outcome == models.CanaryOutcomeSlow
+	})).Return(nil).Once()
+
+	service.runProbe()
+
+	mockDB.AssertExpectations(t)
+	require.Len(t, *alerts, 1)
+	assert.Equal(t, "canary_slow", (*alerts)[0].Type)
+}
+
+func TestCanaryService_FailureAlertsOnceUntilRecovered(t *testing.T) {
+	mockDB := new(database.MockDatabase)
+	service, alerts := newTestCanaryService(t, mockDB, fakeGitHub(t, http.StatusNoContent, "failure", 0).URL)
+
+	mockDB.On("SaveCanaryResult", mock.Anything, mock.MatchedBy(func(r models.CanaryResult) bool {
+		return r.Outcome == models.CanaryOutcomeFailure && r.Conclusion == "failure"
+	})).Return(nil).Twice()
+
+	service.runProbe()
+	service.runProbe()
+
+	mockDB.AssertExpectations(t)
+	require.Len(t, *alerts, 1, "a failing canary is alerted once")
+	assert.Equal(t, "canary_failed", (*alerts)[0].Type)
+	assert.Contains(t, (*alerts)[0].Message, `"failure"`)
+
+	// A success rearms the alert
+	service.alert(models.CanaryResult{Outcome: models.CanaryOutcomeSuccess})
+	service.alert(models.CanaryResult{Outcome: models.CanaryOutcomeTimeout})
+	assert.Len(t, *alerts, 2)
+}
+
+func TestCanaryService_DispatchError(t *testing.T) {
+	mockDB := new(database.MockDatabase)
+	service, alerts := newTestCanaryService(t, mockDB, fakeGitHub(t, http.StatusNotFound, "", 0).URL)
+
+	mockDB.On("SaveCanaryResult", mock.Anything, mock.MatchedBy(func(r models.CanaryResult) bool {
+		return r.Outcome == models.CanaryOutcomeError && r.RunID == 0
+	})).Return(nil).Once()
+
+	service.runProbe()
+
+	mockDB.AssertExpectations(t)
+	require.Len(t, *alerts, 1)
+	assert.Equal(t, "canary_failed", (*alerts)[0].Type)
+	assert.Contains(t, (*alerts)[0].Message, "status 404")
+}
+
+func TestCanaryDuration(t *testing.T) {
+	dispatched := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+	observed := dispatched.Add(2 * time.Minute)
+
+	assert.Equal(t, 90.0, canaryDuration(dispatched, dispatched.Add(90*time.Second), observed))
+	assert.Equal(t, 120.0, canaryDuration(dispatched, dispatched.Add(-time.Second), observed), "completion before dispatch falls back to observation")
+	assert.Equal(t, 120.0, canaryDuration(dispatched, time.Time{}, observed))
+}
@@ -10,6 +10,11 @@ import (
 	"go.uber.org/zap"
 )
 
+// SampledJobGracePeriod is how long successful jobs outside the sample are
+// kept after completing, so late duplicate events still find the terminal
+// state instead of recreating the job.
+const SampledJobGracePeriod = time.Hour
+
 // CleanupService handles automatic cleanup of old data
 type CleanupService struct {
 	config *config.Config
@@ -75,7 +80,7 @@ func (cs *CleanupService) performCleanup() error {
 
 	logger.Logger.Debug("Starting data cleanup",
 		zap.Duration("retention_period", retentionPeriod),
-		zap.Time("cutoff_time", time.Now().Add(-retentionPeriod)),
+		zap.Time("cutoff_time", cs.config.Now().Add(-retentionPeriod)),
 		zap.Duration("stale_job_threshold", staleThreshold),
 	)
 
@@ -96,6 +101,23 @@ func (cs *CleanupService) performCleanup() error {
 		return err
 	}
 
+	if percent := cs.config.Vars.JobSuccessSamplePercent; percent > 0 && percent < 100 {
+		sampledJobs, err := cs.db.PruneSampledJobs(cs.ctx, percent, SampledJobGracePeriod)
+		if err != nil {
+			logger.Logger.Error("Sampled job pruning failed", zap.Error(err))
+		} else if sampledJobs > 0 {
+			logger.Logger.Info("Sampled out successful jobs",
+				zap.Int64("deleted_workflow_jobs", sampledJobs),
+				zap.Int("sample_percent", percent),
+			)
+		}
+	}
+
+	// Sample table sizes after cleanup so growth rates reflect retained data
+	if err := cs.db.RecordStorageSample(cs.ctx); err != nil {
+		logger.Logger.Error("Failed to record storage sample", zap.Error(err))
+	}
+
 	if deletedRuns > 0 || deletedJobs > 0 {
 		logger.Logger.Info("Data cleanup completed",
 			zap.Int64("deleted_workflow_runs", deletedRuns),
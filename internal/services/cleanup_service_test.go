@@ -41,6 +41,7 @@ func TestCleanupService_StartStop(t *testing.T) {
 	expectedStaleThreshold := config.GetStaleJobThreshold()
 	mockDB.On("CleanupStaleJobs", mock.Anything, expectedStaleThreshold).Return(int64(0), nil)
 	mockDB.On("CleanupOldData", mock.Anything, expectedRetention).Return(int64(0), int64(0), int64(0), nil)
+	mockDB.On("RecordStorageSample", mock.Anything).Return(nil)
 
 	// Start the service in a goroutine since it blocks
 	done := make(chan struct{})
@@ -84,6 +85,7 @@ func TestCleanupService_ContextCancellation(t *testing.T) {
 	expectedStaleThreshold := config.GetStaleJobThreshold()
 	mockDB.On("CleanupStaleJobs", mock.Anything, expectedStaleThreshold).Return(int64(0), nil)
 	mockDB.On("CleanupOldData", mock.Anything, expectedRetention).Return(int64(0), int64(0), int64(0), nil)
+	mockDB.On("RecordStorageSample", mock.Anything).Return(nil)
 
 	// Start the service in a goroutine since it blocks
 	done := make(chan struct{})
@@ -104,3 +106,21 @@ func TestCleanupService_ContextCancellation(t *testing.T) {
 	// Verify expectations
 	mockDB.AssertExpectations(t)
 }
+
+func TestCleanupService_PrunesSampledJobs(t *testing.T) {
+	setupTestLogger()
+
+	mockDB := new(database.MockDatabase)
+	cfg := &config.Config{Vars: config.Vars{DataRetentionDays: 30, StaleJobThresholdHours: 24, JobSuccessSamplePercent: 10}}
+	cleanupService := NewCleanupService(cfg, mockDB, context.Background())
+
+	mockDB.On("CleanupStaleJobs", mock.Anything, cfg.GetStaleJobThreshold()).Return(int64(0), nil)
+	mockDB.On("CleanupOldData", mock.Anything, cfg.GetDataRetentionDuration()).Return(int64(0), int64(0), int64(0), nil)
+	mockDB.On("PruneSampledJobs", mock.Anything, 10, SampledJobGracePeriod).Return(int64(90), nil)
+	mockDB.On("RecordStorageSample", mock.Anything).Return(nil)
+
+	if err := cleanupService.performCleanup(); err != nil {
+		t.Fatalf("performCleanup() error = %v", err)
+	}
+	mockDB.AssertExpectations(t)
+}
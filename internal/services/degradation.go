@@ -0,0 +1,106 @@
+package services
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+)
+
+// Codes of the warnings raised while a subsystem is degraded.
+const (
+	WarningProcessingLag = "processing_lag"
+	WarningEventBacklog  = "event_backlog"
+)
+
+// Degradation tracks the subsystems that are currently degraded, so API
+// responses and dashboards can say data may be delayed instead of silently
+// presenting stale numbers. A nil Degradation tracks nothing.
+type Degradation struct {
+	mu       sync.RWMutex
+	warnings map[string]models.Warning
+	onChange func([]models.Warning)
+}
+
+// NewDegradation creates a tracker. onChange is called with the current
+// warnings whenever one is raised, changes or clears; it may be nil.
+func NewDegradation(onChange func([]models.Warning)) *Degradation {
+	return &Degradation{
+		warnings: make(map[string]models.Warning),
+		onChange: onChange,
+	}
+}
+
+// Set raises the warning with the given code, or updates its message. Since
+// is kept from when the warning was first raised.
+func (d *Degradation) Set(code, message string, now time.Time) {
+	if d == nil {
+		return
+	}
+
+	d.mu.Lock()
+	existing, ok := d.warnings[code]
+	if ok && existing.Message == message {
+		d.mu.Unlock()
+		return
+	}
+	since := now
+	if ok {
+		since = existing.Since
+	}
+	d.warnings[code] = models.Warning{Code: code, Message: message, Since: since}
+	warnings := d.sorted()
+	d.mu.Unlock()
+
+	d.notify(warnings)
+}
+
+// Clear removes the warning with the given code, if raised.
+func (d *Degradation) Clear(code string) {
+	if d == nil {
+		return
+	}
+
+	d.mu.Lock()
+	if _, ok := d.warnings[code]; !ok {
+		d.mu.Unlock()
+		return
+	}
+	delete(d.warnings, code)
+	warnings := d.sorted()
+	d.mu.Unlock()
+
+	d.notify(warnings)
+}
+
+// Warnings returns the current warnings ordered by code, or nil when nothing
+// is degraded.
+func (d *Degradation) Warnings() []models.Warning {
+	if d == nil {
+		return nil
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.sorted()
+}
+
+// sorted must be called with mu held.
+func (d *Degradation) sorted() []models.Warning {
+	if len(d.warnings) == 0 {
+		return nil
+	}
+	warnings := make([]models.Warning, 0, len(d.warnings))
+	for _, w := range d.warnings {
+		warnings = append(warnings, w)
+	}
+	sort.Slice(warnings, func(i, j int) bool { return warnings[i].Code < warnings[j].Code })
+	return warnings
+}
+
+func (d *Degradation) notify(warnings []models.Warning) {
+	if d.onChange != nil {
+		d.onChange(warnings)
+	}
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDegradation_NotifiesOnChange(t *testing.T) {
+	var updates [][]models.Warning
+	d := NewDegradation(func(w []models.Warning) { updates = append(updates, w) })
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	d.Set(WarningProcessingLag, "behind", start)
+	d.Set(WarningProcessingLag, "behind", start.Add(time.Minute))
+	require.Len(t, updates, 1, "an unchanged warning is not announced again")
+
+	d.Set(WarningProcessingLag, "further behind", start.Add(2*time.Minute))
+	d.Set(WarningEventBacklog, "backlog", start.Add(2*time.Minute))
+	require.Len(t, updates, 3)
+
+	warnings := d.Warnings()
+	require.Len(t, warnings, 2)
+	assert.Equal(t, WarningEventBacklog, warnings[0].Code)
+	assert.Equal(t, "further behind", warnings[1].Message)
+	assert.Equal(t, start, warnings[1].Since, "since is kept from when the warning was raised")
+
+	d.Clear(WarningProcessingLag)
+	d.Clear(WarningEventBacklog)
+	d.Clear(WarningEventBacklog)
+	require.Len(t, updates, 5)
+	assert.Nil(t, updates[4])
+	assert.Nil(t, d.Warnings())
+}
+
+func TestDegradation_Nil(t *testing.T) {
+	var d *Degradation
+	d.Set(WarningEventBacklog, "backlog", time.Now())
+	d.Clear(WarningEventBacklog)
+	assert.Nil(t, d.Warnings())
+}
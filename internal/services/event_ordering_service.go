@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"time"
 
@@ -11,6 +12,9 @@ import (
 	"go.uber.org/zap"
 )
 
+// ErrOrderingStopped is returned for events added once the service is stopping.
+var ErrOrderingStopped = errors.New("event ordering service is stopped")
+
 type EventOrderingService struct {
 	db            database.DatabaseInterface
 	processFunc   func(*models.OrderedEvent) error
@@ -18,9 +22,12 @@ type EventOrderingService struct {
 	maxAge        time.Duration
 	batchSize     int
 	mutex         sync.Mutex
-	wg            sync.WaitGroup
-	ctx           context.Context
-	cancel        context.CancelFunc
+	// lifecycle orders AddEvent against Stop, so no goroutine is added to wg
+	// once Stop waits for it
+	lifecycle sync.RWMutex
+	wg        sync.WaitGroup
+	ctx       context.Context
+	cancel    context.CancelFunc
 }
 
 func NewEventOrderingService(db database.DatabaseInterface, processFunc func(*models.OrderedEvent) error) *EventOrderingService {
@@ -37,17 +44,76 @@ func NewEventOrderingService(db database.DatabaseInterface, processFunc func(*mo
 }
 
 func (s *EventOrderingService) Start() {
+	// Events claimed by a process that stopped mid-way are pending again
+	if err := s.db.ReleaseClaimedEvents(s.ctx); err != nil {
+		logger.Logger.Error("Failed to release claimed events", zap.Error(err))
+	}
 	s.wg.Add(1)
 	go s.flushWorker()
 }
 
 func (s *EventOrderingService) Stop() {
+	s.lifecycle.Lock()
 	s.cancel()
+	s.lifecycle.Unlock()
 	s.wg.Wait()
 }
 
+// AddEvent stores an event for ordered processing. Terminal events are
+// processed right away when nothing else is pending for their entity, since
+// no buffered event can need to go before them; this keeps completions from
+// waiting out maxAge before reaching the dashboard.
 func (s *EventOrderingService) AddEvent(event *models.OrderedEvent) error {
-	return s.db.StoreWebhookEvent(s.ctx, event)
+	s.lifecycle.RLock()
+	defer s.lifecycle.RUnlock()
+	if s.ctx.Err() != nil {
+		return ErrOrderingStopped
+	}
+
+	if err := s.db.StoreWebhookEvent(s.ctx, event); err != nil {
+		return err
+	}
+	if event.Terminal {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.processTerminalEvent(event)
+		}()
+	}
+	return nil
+}
+
+// processTerminalEvent processes event unless other events are pending for
+// its ordering key, in which case it is left for the flush to order. The
+// flush may also have processed it already, once it was older than maxAge,
+// so the event is only processed if it can still be claimed.
+func (s *EventOrderingService) processTerminalEvent(event *models.OrderedEvent) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	pending, err := s.db.HasOtherPendingEvents(s.ctx, event.OrderingKey, event.Sequence.DeliveryID)
+	if err != nil {
+		logger.Logger.Error("Failed to check pending events, leaving event for ordered processing",
+			zap.String("ordering_key", event.OrderingKey), zap.Error(err))
+		return
+	}
+	if pending {
+		return
+	}
+	claimed, err := s.db.ClaimPendingEvent(s.ctx, event.Sequence.DeliveryID)
+	if err != nil {
+		logger.Logger.Error("Failed to claim event, leaving it for ordered processing",
+			zap.String("delivery_id", event.Sequence.DeliveryID), zap.Error(err))
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	logger.Logger.Debug("Processing terminal event without buffering",
+		zap.String("delivery_id", event.Sequence.DeliveryID),
+		zap.String("ordering_key", event.OrderingKey))
+	s.processEvents([]*models.OrderedEvent{event})
 }
 
 func (s *EventOrderingService) flushWorker() {
@@ -87,7 +153,8 @@ func (s *EventOrderingService) flushAll() {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	events, err := s.db.GetPendingEventsGrouped(s.ctx, 1000)
+	// s.ctx is already cancelled when flushing on stop
+	events, err := s.db.GetPendingEventsGrouped(context.Background(), 1000)
 	if err != nil {
 		logger.Logger.Error("Failed to fetch all pending events", zap.Error(err))
 		return
@@ -106,6 +106,90 @@ func TestEventOrderingService_AddEvent(t *testing.T) {
 	}
 }
 
+func TestEventOrderingService_AddEvent_TerminalFastPath(t *testing.T) {
+	setupTestLoggerForEventOrdering()
+	defer logger.SyncLogger()
+
+	tests := []struct {
+		name          string
+		otherPending  bool
+		pendingErr    error
+		wantProcessed bool
+	}{
+		{"processed immediately when nothing else is pending", false, nil, true},
+		{"left for ordering when earlier events are pending", true, nil, false},
+		{"left for ordering when the check fails", false, errors.New("database error"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(database.MockDatabase)
+			event := createTestEvent("delivery-1", "workflow_job", "job_123", 5)
+			event.Terminal = true
+			mockDB.On("StoreWebhookEvent", mock.Anything, event).Return(nil)
+			mockDB.On("HasOtherPendingEvents", mock.Anything, "job_123", "delivery-1").Return(tt.otherPending, tt.pendingErr)
+			if tt.wantProcessed {
+				mockDB.On("ClaimPendingEvent", mock.Anything, "delivery-1").Return(true, nil)
+			}
+
+			var processed []*models.OrderedEvent
+			service := NewEventOrderingService(mockDB, func(e *models.OrderedEvent) error {
+				processed = append(processed, e)
+				return nil
+			})
+
+			assert.NoError(t, service.AddEvent(event))
+			service.wg.Wait()
+
+			if tt.wantProcessed {
+				assert.Equal(t, []*models.OrderedEvent{event}, processed)
+			} else {
+				assert.Empty(t, processed)
+			}
+			mockDB.AssertExpectations(t)
+		})
+	}
+}
+
+func TestEventOrderingService_TerminalEventFlushedFirst(t *testing.T) {
+	setupTestLoggerForEventOrdering()
+	defer logger.SyncLogger()
+
+	mockDB := new(database.MockDatabase)
+	event := createTestEvent("delivery-1", "workflow_job", "job_123", 5)
+	event.Terminal = true
+	mockDB.On("GetPendingEventsByAge", mock.Anything, 10*time.Second, 100).Return([]*models.OrderedEvent{event}, nil)
+	mockDB.On("HasOtherPendingEvents", mock.Anything, "job_123", "delivery-1").Return(false, nil)
+	mockDB.On("ClaimPendingEvent", mock.Anything, "delivery-1").Return(false, nil)
+
+	processed := 0
+	service := NewEventOrderingService(mockDB, func(e *models.OrderedEvent) error {
+		processed++
+		return nil
+	})
+
+	// The flush got the lock first, once the event was older than maxAge
+	service.flushReadyEvents()
+	service.processTerminalEvent(event)
+
+	assert.Equal(t, 1, processed, "the event is only processed by the flush")
+	mockDB.AssertExpectations(t)
+}
+
+func TestEventOrderingService_AddEventAfterStop(t *testing.T) {
+	setupTestLoggerForEventOrdering()
+	defer logger.SyncLogger()
+
+	mockDB := new(database.MockDatabase)
+	service := NewEventOrderingService(mockDB, func(e *models.OrderedEvent) error { return nil })
+	service.Stop()
+
+	event := createTestEvent("delivery-1", "workflow_job", "job_123", 5)
+	event.Terminal = true
+	assert.ErrorIs(t, service.AddEvent(event), ErrOrderingStopped)
+	mockDB.AssertNotCalled(t, "StoreWebhookEvent", mock.Anything, mock.Anything)
+}
+
 func TestEventOrderingService_StartStop(t *testing.T) {
 	setupTestLoggerForEventOrdering()
 	defer logger.SyncLogger()
@@ -120,7 +204,7 @@ func TestEventOrderingService_StartStop(t *testing.T) {
 	// Mock expectations for the initial flush on stop
 	mockDB.On("GetPendingEventsGrouped", mock.Anything, 1000).Return([]*models.OrderedEvent{}, nil)
 
-	// Start the service
+	mockDB.On("ReleaseClaimedEvents", mock.Anything).Return(nil)
 	service.Start()
 
 	// Verify the service is running by checking if context is not done
@@ -385,6 +469,7 @@ func TestEventOrderingService_flushWorker(t *testing.T) {
 	service.flushInterval = 50 * time.Millisecond
 
 	// Start the flush worker
+	mockDB.On("ReleaseClaimedEvents", mock.Anything).Return(nil)
 	service.Start()
 
 	// Let it run for a short time
@@ -420,7 +505,7 @@ func TestEventOrderingService_ConcurrentAccess(t *testing.T) {
 	service := NewEventOrderingService(mockDB, processFunc)
 	service.flushInterval = 50 * time.Millisecond
 
-	// Start the service
+	mockDB.On("ReleaseClaimedEvents", mock.Anything).Return(nil)
 	service.Start()
 
 	// Add events concurrently
@@ -475,6 +560,7 @@ func TestEventOrderingService_ContextCancellation(t *testing.T) {
 	service.flushInterval = 1 * time.Second // Longer interval to test cancellation
 
 	// Start and immediately stop the service
+	mockDB.On("ReleaseClaimedEvents", mock.Anything).Return(nil)
 	service.Start()
 	service.Stop()
 
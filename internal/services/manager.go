@@ -0,0 +1,222 @@
+package services
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Service states reported by Manager.Health.
+const (
+	ServiceRegistered = "registered"
+	ServiceRunning    = "running"
+	ServiceStopping   = "stopping"
+	ServiceStopped    = "stopped"
+	// ServiceFailed is a service that panicked or whose Start returned
+	// before it was asked to stop.
+	ServiceFailed = "failed"
+)
+
+// Service is a background service run by a Manager. Start runs the service
+// until Stop is called, blocking while it runs; Stop makes Start return and
+// waits for it.
+type Service interface {
+	Start()
+	Stop()
+}
+
+// background adapts a service whose Start returns once its goroutines are
+// running to the blocking Start of Service.
+type background struct {
+	service Service
+	stopped chan struct{}
+}
+
+// Background wraps a service whose Start returns right away, such as
+// EventOrderingService, so a Manager can run it.
+func Background(service Service) Service {
+	return &background{service: service, stopped: make(chan struct{})}
+}
+
+func (b *background) Start() {
+	b.service.Start()
+	<-b.stopped
+}
+
+func (b *background) Stop() {
+	b.service.Stop()
+	close(b.stopped)
+}
+
+// ServiceHealth describes the state of one managed service.
+type ServiceHealth struct {
+	Name      string     `json:"name"`
+	State     string     `json:"state"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+type managedService struct {
+	name      string
+	service   Service
+	state     string
+	startedAt time.Time
+	err       string
+	exited    chan struct{}
+}
+
+// Manager starts background services in the order they were registered and
+// stops them in reverse, so a service can rely on those registered before it
+// while it runs. It recovers services that panic and reports each service's
+// state.
+type Manager struct {
+	mutex       sync.RWMutex
+	services    []*managedService
+	stopTimeout time.Duration
+	started     bool
+}
+
+// NewManager creates a manager that waits up to stopTimeout for each service
+// to stop.
+func NewManager(stopTimeout time.Duration) *Manager {
+	return &Manager{stopTimeout: stopTimeout}
+}
+
+// Register adds a service to be started by Start. It panics when called after
+// Start or with a name already registered.
+func (m *Manager) Register(name string, service Service) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.started {
+		panic(fmt.Sprintf("service %q registered after the manager started", name))
+	}
+	for _, s := range m.services {
+		if s.name == name {
+			panic(fmt.Sprintf("service %q registered twice", name))
+		}
+	}
+	m.services = append(m.services, &managedService{
+		name:    name,
+		service: service,
+		state:   ServiceRegistered,
+		exited:  make(chan struct{}),
+	})
+}
+
+// Start starts every registered service in registration order.
+func (m *Manager) Start() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.started {
+		return
+	}
+	m.started = true
+	for _, s := range m.services {
+		s.state = ServiceRunning
+		s.startedAt = time.Now()
+		go m.run(s)
+		logger.Logger.Debug("Service started", zap.String("service", s.name))
+	}
+}
+
+// run runs s until its Start returns, recording why it did.
+func (m *Manager) run(s *managedService) {
+	defer close(s.exited)
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Logger.Error("Service panicked",
+				zap.String("service", s.name),
+				zap.Any("panic", r),
+				zap.ByteString("stack", debug.Stack()))
+			m.setFailed(s, fmt.Sprintf("panic: %v", r))
+		}
+	}()
+
+	s.service.Start()
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if s.state == ServiceRunning {
+		logger.Logger.Error("Service exited before it was stopped", zap.String("service", s.name))
+		s.state = ServiceFailed
+		s.err = "exited unexpectedly"
+	}
+}
+
+func (m *Manager) setFailed(s *managedService, err string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	s.state = ServiceFailed
+	s.err = err
+}
+
+// Stop stops the running services in reverse registration order, giving each
+// up to the stop timeout. Services that failed are not stopped again.
+func (m *Manager) Stop() {
+	m.mutex.RLock()
+	services := append([]*managedService(nil), m.services...)
+	m.mutex.RUnlock()
+
+	for i := len(services) - 1; i >= 0; i-- {
+		s := services[i]
+		m.mutex.Lock()
+		running := s.state == ServiceRunning
+		if running {
+			s.state = ServiceStopping
+		}
+		m.mutex.Unlock()
+		if !running {
+			continue
+		}
+
+		stopped := make(chan struct{})
+		go func() {
+			defer close(stopped)
+			defer func() {
+				if r := recover(); r != nil {
+					logger.Logger.Error("Service panicked while stopping", zap.String("service", s.name), zap.Any("panic", r))
+				}
+			}()
+			s.service.Stop()
+			<-s.exited
+		}()
+
+		select {
+		case <-stopped:
+			m.mutex.Lock()
+			if s.state == ServiceStopping {
+				s.state = ServiceStopped
+			}
+			m.mutex.Unlock()
+			logger.Logger.Debug("Service stopped", zap.String("service", s.name))
+		case <-time.After(m.stopTimeout):
+			logger.Logger.Error("Service did not stop in time, continuing shutdown",
+				zap.String("service", s.name), zap.Duration("timeout", m.stopTimeout))
+			m.setFailed(s, "did not stop in time")
+		}
+	}
+}
+
+// Health returns the state of every registered service in registration
+// order.
+func (m *Manager) Health() []ServiceHealth {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	health := make([]ServiceHealth, 0, len(m.services))
+	for _, s := range m.services {
+		h := ServiceHealth{Name: s.name, State: s.state, Error: s.err}
+		if !s.startedAt.IsZero() {
+			startedAt := s.startedAt
+			h.StartedAt = &startedAt
+		}
+		health = append(health, h)
+	}
+	return health
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testService records its lifecycle in a shared log. Start blocks until Stop
+// unless run says otherwise.
+type testService struct {
+	name string
+	log  *eventLog
+	run  func()
+	stop chan struct{}
+	done chan struct{}
+}
+
+type eventLog struct {
+	mutex  sync.Mutex
+	events []string
+}
+
+func (l *eventLog) add(event string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.events = append(l.events, event)
+}
+
+func (l *eventLog) list() []string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return append([]string(nil), l.events...)
+}
+
+func newTestService(name string, log *eventLog) *testService {
+	return &testService{name: name, log: log, stop: make(chan struct{}), done: make(chan struct{})}
+}
+
+func (s *testService) Start() {
+	defer close(s.done)
+	s.log.add("start " + s.name)
+	if s.run != nil {
+		s.run()
+		return
+	}
+	<-s.stop
+}
+
+func (s *testService) Stop() {
+	s.log.add("stop " + s.name)
+	close(s.stop)
+	<-s.done
+}
+
+func waitForState(t *testing.T, m *Manager, name, state string) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		for _, h := range m.Health() {
+			if h.Name == name {
+				return h.State == state
+			}
+		}
+		return false
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestManager_StartStopOrder(t *testing.T) {
+	setupTestLogger()
+	log := &eventLog{}
+	m := NewManager(time.Second)
+	m.Register("first", newTestService("first", log))
+	m.Register("second", newTestService("second", log))
+
+	for _, h := range m.Health() {
+		assert.Equal(t, ServiceRegistered, h.State)
+		assert.Nil(t, h.StartedAt)
+	}
+
+	m.Start()
+	waitForState(t, m, "second", ServiceRunning)
+	require.Eventually(t, func() bool { return len(log.list()) == 2 }, time.Second, 5*time.Millisecond)
+
+	m.Stop()
+	assert.Equal(t, []string{"stop second", "stop first"}, log.list()[2:])
+	for _, h := range m.Health() {
+		assert.Equal(t, ServiceStopped, h.State)
+		assert.NotNil(t, h.StartedAt)
+	}
+}
+
+func TestManager_Failures(t *testing.T) {
+	setupTestLogger()
+	log := &eventLog{}
+	m := NewManager(50 * time.Millisecond)
+
+	panics := newTestService("panics", log)
+	panics.run = func() { panic("boom") }
+	exits := newTestService("exits", log)
+	exits.run = func() {}
+	hangs := newTestService("hangs", log)
+	healthy := newTestService("healthy", log)
+	m.Register("panics", panics)
+	m.Register("exits", exits)
+	m.Register("hangs", &stuckService{hangs})
+	m.Register("healthy", healthy)
+
+	m.Start()
+	waitForState(t, m, "panics", ServiceFailed)
+	waitForState(t, m, "exits", ServiceFailed)
+
+	m.Stop()
+	health := m.Health()
+	assert.Equal(t, ServiceHealth{Name: "panics", State: ServiceFailed, StartedAt: health[0].StartedAt, Error: "panic: boom"}, health[0])
+	assert.Equal(t, "exited unexpectedly", health[1].Error)
+	assert.Equal(t, ServiceFailed, health[2].State)
+	assert.Equal(t, "did not stop in time", health[2].Error)
+	assert.Equal(t, ServiceStopped, health[3].State, "a hung service does not block the others")
+	assert.NotContains(t, log.list(), "stop panics")
+}
+
+// stuckService never returns from Stop.
+type stuckService struct{ *testService }
+
+func (s *stuckService) Stop() { select {} }
+
+func TestManager_Background(t *testing.T) {
+	setupTestLogger()
+	started := make(chan struct{})
+	svc := &nonBlockingService{started: started}
+	m := NewManager(time.Second)
+	m.Register("ordering", Background(svc))
+
+	m.Start()
+	<-started
+	waitForState(t, m, "ordering", ServiceRunning)
+
+	m.Stop()
+	assert.True(t, svc.stopped)
+	assert.Equal(t, ServiceStopped, m.Health()[0].State)
+}
+
+type nonBlockingService struct {
+	started chan struct{}
+	stopped bool
+}
+
+func (s *nonBlockingService) Start() { close(s.started) }
+func (s *nonBlockingService) Stop()  { s.stopped = true }
+
+func TestManager_RegisterTwice(t *testing.T) {
+	m := NewManager(time.Second)
+	m.Register("cleanup", Background(&nonBlockingService{}))
+	assert.Panics(t, func() { m.Register("cleanup", Background(&nonBlockingService{})) })
+}
@@ -5,29 +5,35 @@ import (
 	"sync"
 	"time"
 
+	"github.com/gateixeira/live-actions/internal/config"
 	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/models"
 	"github.com/gateixeira/live-actions/pkg/logger"
 	"github.com/gateixeira/live-actions/pkg/metrics"
 	"go.uber.org/zap"
 )
 
 type MetricsUpdateService struct {
+	config   *config.Config
 	db       database.DatabaseInterface
 	registry *metrics.Registry
 	interval time.Duration
+	reset    chan time.Duration
 	ctx      context.Context
 	cancel   context.CancelFunc
 	done     chan struct{}
 	mutex    sync.RWMutex
 }
 
-func NewMetricsUpdateService(db database.DatabaseInterface, interval time.Duration, ctx context.Context) *MetricsUpdateService {
+func NewMetricsUpdateService(config *config.Config, db database.DatabaseInterface, interval time.Duration, ctx context.Context) *MetricsUpdateService {
 	ctx, cancel := context.WithCancel(ctx)
 
 	return &MetricsUpdateService{
+		config:   config,
 		db:       db,
 		registry: metrics.GetRegistry(),
 		interval: interval,
+		reset:    make(chan time.Duration, 1),
 		ctx:      ctx,
 		cancel:   cancel,
 		done:     make(chan struct{}),
@@ -37,7 +43,7 @@ func NewMetricsUpdateService(db database.DatabaseInterface, interval time.Durati
 func (s *MetricsUpdateService) Start() {
 	defer close(s.done)
 
-	ticker := time.NewTicker(s.interval)
+	ticker := time.NewTicker(s.Interval())
 	defer ticker.Stop()
 
 	// Update immediately on start
@@ -48,12 +54,35 @@ func (s *MetricsUpdateService) Start() {
 		case <-s.ctx.Done():
 			logger.Logger.Info("Metrics update service stopped")
 			return
+		case interval := <-s.reset:
+			ticker.Reset(interval)
 		case <-ticker.C:
 			s.updateMetrics()
 		}
 	}
 }
 
+// Interval returns how often metrics are refreshed and snapshotted
+func (s *MetricsUpdateService) Interval() time.Duration {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.interval
+}
+
+// SetInterval changes the refresh interval, taking effect on the next tick
+func (s *MetricsUpdateService) SetInterval(interval time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.interval = interval
+
+	// Keep only the latest pending change
+	select {
+	case <-s.reset:
+	default:
+	}
+	s.reset <- interval
+}
+
 func (s *MetricsUpdateService) Stop() {
 	s.cancel()
 	<-s.done // Wait for completion
@@ -77,14 +106,96 @@ func (s *MetricsUpdateService) updateMetrics() {
 	if err != nil {
 		logger.Logger.Error("Failed to get job counts by label", zap.Error(err))
 	} else {
-		s.registry.ResetJobsByLabel()
+		// Labels past the cardinality limit share one series, so sum them
+		byLabel := make(map[string]*database.LabelJobCount)
 		for _, lc := range labelCounts {
+			label := s.registry.TrackedLabel(lc.Label)
+			if byLabel[label] == nil {
+				byLabel[label] = &database.LabelJobCount{Label: label}
+			}
+			byLabel[label].Running += lc.Running
+			byLabel[label].Queued += lc.Queued
+		}
+		s.registry.ResetJobsByLabel()
+		for _, lc := range byLabel {
 			s.registry.UpdateJobsByLabel(lc.Label, lc.Running, lc.Queued)
 		}
 	}
+	s.recordLabelCapacity()
+	s.updateThroughput()
 
 	// Store a snapshot for historical charts
 	if err := s.db.InsertMetricsSnapshot(s.ctx, running, queued); err != nil {
 		logger.Logger.Error("Failed to insert metrics snapshot", zap.Error(err))
 	}
 }
+
+// updateThroughput refreshes the per-label rates of jobs started and completed
+// over the sliding throughput window.
+func (s *MetricsUpdateService) updateThroughput() {
+	now := s.config.Now()
+	throughput, err := s.db.GetLabelThroughput(s.ctx, now.Add(-s.config.GetThroughputWindow()), now, nil)
+	if err != nil {
+		logger.Logger.Error("Failed to get job throughput by label", zap.Error(err))
+		return
+	}
+
+	// Labels past the cardinality limit share one series, so sum them
+	byLabel := make(map[string]*models.LabelThroughput)
+	for _, t := range throughput {
+		label := s.registry.TrackedLabel(t.Label)
+		if byLabel[label] == nil {
+			byLabel[label] = &models.LabelThroughput{Label: label}
+		}
+		byLabel[label].StartedPerMinute += t.StartedPerMinute
+		byLabel[label].CompletedPerMinute += t.CompletedPerMinute
+	}
+	s.registry.ResetJobThroughput()
+	for _, t := range byLabel {
+		s.registry.UpdateJobThroughput(t.Label, t.StartedPerMinute, t.CompletedPerMinute)
+	}
+}
+
+// recordLabelCapacity stores each label's demand next to its capacity, so
+// saturation can be charted over time. Demand counts a job under each of its
+// labels. Capacity comes from RUNNER_CAPACITY when configured for the label,
+// and otherwise from the runner hosts carrying the label that are sending
+// heartbeats.
+func (s *MetricsUpdateService) recordLabelCapacity() {
+	labelCounts, err := s.db.GetCurrentLabelDemand(s.ctx)
+	if err != nil {
+		logger.Logger.Error("Failed to get label demand", zap.Error(err))
+		return
+	}
+	capacity, err := s.db.GetRunnerCapacityByLabel(s.ctx, s.config.GetRunnerOfflineThreshold())
+	if err != nil {
+		logger.Logger.Error("Failed to get runner capacity by label", zap.Error(err))
+		return
+	}
+	for label, runners := range s.config.Vars.RunnerCapacity {
+		capacity[label] = runners
+	}
+
+	now := s.config.Now()
+	samples := make([]models.LabelCapacitySample, 0, len(labelCounts)+len(capacity))
+	seen := make(map[string]bool, len(labelCounts))
+	for _, lc := range labelCounts {
+		seen[lc.Label] = true
+		samples = append(samples, models.LabelCapacitySample{
+			Label: lc.Label, Timestamp: now, Running: lc.Running, Queued: lc.Queued, Capacity: capacity[lc.Label],
+		})
+	}
+	// Idle labels are sampled too, so their saturation reads zero rather than missing
+	for label, runners := range capacity {
+		if !seen[label] {
+			samples = append(samples, models.LabelCapacitySample{Label: label, Timestamp: now, Capacity: runners})
+		}
+	}
+	if len(samples) == 0 {
+		return
+	}
+
+	if err := s.db.SaveLabelCapacitySamples(s.ctx, samples); err != nil {
+		logger.Logger.Error("Failed to save label capacity samples", zap.Error(err))
+	}
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/clock"
+	"github.com/gateixeira/live-actions/internal/config"
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gateixeira/live-actions/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsUpdateService_RecordsLabelCapacity(t *testing.T) {
+	setupTestLogger()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	cfg := &config.Config{
+		Vars:  config.Vars{RunnerOfflineMinutes: 15, RunnerCapacity: map[string]int{"gpu": 4}},
+		Clock: clock.NewFake(now),
+	}
+	mockDB := new(database.MockDatabase)
+	service := NewMetricsUpdateService(cfg, mockDB, time.Minute, context.Background())
+
+	mockDB.On("GetCurrentJobCounts", mock.Anything).Return(5, 3, nil)
+	mockDB.On("GetCurrentJobCountsByLabel", mock.Anything).Return([]database.LabelJobCount{
+		{Label: "linux", Running: 4, Queued: 2},
+		{Label: "windows", Running: 0, Queued: 0},
+	}, nil)
+	// gpu jobs also need linux, so they are missing from the first-label counts
+	mockDB.On("GetCurrentLabelDemand", mock.Anything).Return([]database.LabelJobCount{
+		{Label: "linux", Running: 5, Queued: 3},
+		{Label: "gpu", Running: 1, Queued: 1},
+		{Label: "windows", Running: 0, Queued: 0},
+	}, nil)
+	mockDB.On("GetRunnerCapacityByLabel", mock.Anything, 15*time.Minute).Return(map[string]int{"linux": 5, "gpu": 2, "arm": 3}, nil)
+	mockDB.On("GetLabelThroughput", mock.Anything, mock.Anything, mock.Anything, []string(nil)).Return([]models.LabelThroughput{}, nil)
+	mockDB.On("InsertMetricsSnapshot", mock.Anything, 5, 3).Return(nil)
+
+	var saved []models.LabelCapacitySample
+	mockDB.On("SaveLabelCapacitySamples", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).([]models.LabelCapacitySample)
+	}).Return(nil)
+
+	service.updateMetrics()
+
+	require.Len(t, saved, 4)
+	byLabel := make(map[string]models.LabelCapacitySample)
+	for _, s := range saved {
+		assert.Equal(t, now, s.Timestamp)
+		byLabel[s.Label] = s
+	}
+	assert.Equal(t, models.LabelCapacitySample{Label: "linux", Timestamp: now, Running: 5, Queued: 3, Capacity: 5}, byLabel["linux"])
+	assert.Equal(t, models.LabelCapacitySample{Label: "gpu", Timestamp: now, Running: 1, Queued: 1, Capacity: 4}, byLabel["gpu"], "configured capacity overrides heartbeats")
+	assert.Equal(t, 0, byLabel["windows"].Capacity, "labels without runners have unknown capacity")
+	assert.Equal(t, models.LabelCapacitySample{Label: "arm", Timestamp: now, Capacity: 3}, byLabel["arm"])
+}
+
+func TestMetricsUpdateService_GroupsLabelsPastLimit(t *testing.T) {
+	setupTestLogger()
+	cfg := &config.Config{Vars: config.Vars{RunnerOfflineMinutes: 15}}
+	mockDB := new(database.MockDatabase)
+	service := NewMetricsUpdateService(cfg, mockDB, time.Minute, context.Background())
+	service.registry.SetLabelLimits(0, 1)
+	defer service.registry.SetLabelLimits(0, 0)
+
+	mockDB.On("GetCurrentJobCounts", mock.Anything).Return(6, 3, nil)
+	mockDB.On("GetCurrentJobCountsByLabel", mock.Anything).Return([]database.LabelJobCount{
+		{Label: "tracked-linux", Running: 4, Queued: 1},
+		{Label: "gpu", Running: 1, Queued: 1},
+		{Label: "arm", Running: 1, Queued: 1},
+	}, nil)
+	mockDB.On("GetCurrentLabelDemand", mock.Anything).Return([]database.LabelJobCount{}, nil)
+	mockDB.On("GetRunnerCapacityByLabel", mock.Anything, 15*time.Minute).Return(map[string]int{}, nil)
+	mockDB.On("SaveLabelCapacitySamples", mock.Anything, mock.Anything).Return(nil)
+	mockDB.On("GetLabelThroughput", mock.Anything, mock.Anything, mock.Anything, []string(nil)).Return([]models.LabelThroughput{
+		{Label: "tracked-linux", StartedPerMinute: 2, CompletedPerMinute: 1},
+		{Label: "gpu", StartedPerMinute: 0.5, CompletedPerMinute: 0.25},
+		{Label: "arm", StartedPerMinute: 0.25, CompletedPerMinute: 0.5},
+	}, nil)
+	mockDB.On("InsertMetricsSnapshot", mock.Anything, 6, 3).Return(nil)
+
+	service.updateMetrics()
+
+	gauge := func(label, status string) float64 {
+		var m dto.Metric
+		require.NoError(t, service.registry.JobsByLabel.WithLabelValues(label, status).Write(&m))
+		return m.GetGauge().GetValue()
+	}
+	assert.Equal(t, 4.0, gauge("tracked-linux", "in_progress"))
+	assert.Equal(t, 2.0, gauge(metrics.OtherLabel, "in_progress"))
+	assert.Equal(t, 2.0, gauge(metrics.OtherLabel, "queued"))
+
+	var m dto.Metric
+	require.NoError(t, service.registry.JobsStartedPerMinute.WithLabelValues(metrics.OtherLabel).Write(&m))
+	assert.Equal(t, 0.75, m.GetGauge().GetValue())
+	require.NoError(t, service.registry.JobsCompletedPerMinute.WithLabelValues(metrics.OtherLabel).Write(&m))
+	assert.Equal(t, 0.75, m.GetGauge().GetValue())
+}
+
+func TestMetricsUpdateService_UpdatesThroughput(t *testing.T) {
+	setupTestLogger()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	cfg := &config.Config{
+		Vars:  config.Vars{RunnerOfflineMinutes: 15, ThroughputWindowMinutes: 5},
+		Clock: clock.NewFake(now),
+	}
+	mockDB := new(database.MockDatabase)
+	service := NewMetricsUpdateService(cfg, mockDB, time.Minute, context.Background())
+
+	mockDB.On("GetCurrentJobCounts", mock.Anything).Return(0, 0, nil)
+	mockDB.On("GetCurrentJobCountsByLabel", mock.Anything).Return([]database.LabelJobCount{}, nil)
+	mockDB.On("GetCurrentLabelDemand", mock.Anything).Return([]database.LabelJobCount{}, nil)
+	mockDB.On("GetRunnerCapacityByLabel", mock.Anything, 15*time.Minute).Return(map[string]int{}, nil)
+	mockDB.On("InsertMetricsSnapshot", mock.Anything, 0, 0).Return(nil)
+	mockDB.On("GetLabelThroughput", mock.Anything, now.Add(-5*time.Minute), now, []string(nil)).Return([]models.LabelThroughput{
+		{Label: "throughput-linux", Started: 6, Completed: 4, StartedPerMinute: 1.2, CompletedPerMinute: 0.8},
+	}, nil).Once()
+	mockDB.On("GetLabelThroughput", mock.Anything, now.Add(-5*time.Minute), now, []string(nil)).Return([]models.LabelThroughput{}, nil)
+
+	rate := func(gauge *prometheus.GaugeVec) float64 {
+		var m dto.Metric
+		require.NoError(t, gauge.WithLabelValues("throughput-linux").Write(&m))
+		return m.GetGauge().GetValue()
+	}
+
+	service.updateMetrics()
+	assert.Equal(t, 1.2, rate(service.registry.JobsStartedPerMinute))
+	assert.Equal(t, 0.8, rate(service.registry.JobsCompletedPerMinute))
+
+	// Labels without recent jobs drop out rather than keep their last rate
+	service.updateMetrics()
+	series := make(chan prometheus.Metric, 10)
+	service.registry.JobsStartedPerMinute.Collect(series)
+	assert.Empty(t, series)
+	mockDB.AssertExpectations(t)
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/config"
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/internal/utils"
+	"github.com/gateixeira/live-actions/models"
+)
+
+// SLO objectives.
+const (
+	ObjectiveSuccess  = "success"
+	ObjectiveDuration = "duration"
+)
+
+// Burn rates are measured over a fast and a slow window. Alerting only when
+// both burn fast catches sudden breakage without paging on a single bad run
+// that has already recovered.
+const (
+	BurnRateFastWindow = time.Hour
+	BurnRateSlowWindow = 6 * time.Hour
+)
+
+// failedConclusions are the run conclusions that spend a success objective's
+// error budget. Cancelled and skipped runs are not counted either way.
+var failedConclusions = map[string]bool{
+	"failure":         true,
+	"timed_out":       true,
+	"startup_failure": true,
+}
+
+// FailedConclusion reports whether a run conclusion counts as a failure.
+// Cancelled and skipped runs count as neither a failure nor a success.
+func FailedConclusion(conclusion string) bool {
+	return failedConclusions[conclusion]
+}
+
+// WorkflowSLOs returns the SLOs defined in WORKFLOW_SLOS followed by those
+// managed through the API. A configured SLO hides an API SLO of the same name.
+func WorkflowSLOs(ctx context.Context, cfg *config.Config, db database.DatabaseInterface) ([]models.WorkflowSLO, error) {
+	slos := []models.WorkflowSLO{}
+	for _, slo := range cfg.Vars.WorkflowSLOs {
+		slos = append(slos, slo)
+	}
+	sort.Slice(slos, func(i, j int) bool { return slos[i].Name < slos[j].Name })
+
+	stored, err := db.GetWorkflowSLOs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, slo := range stored {
+		if _, ok := cfg.Vars.WorkflowSLOs[slo.Name]; !ok {
+			slos = append(slos, slo)
+		}
+	}
+	return slos, nil
+}
+
+// EvaluateSLO reports compliance with each objective of slo over its window
+// ending at now, given the workflow's runs completed in that window.
+func EvaluateSLO(slo models.WorkflowSLO, runs []models.SLORun, now time.Time) models.SLOStatus {
+	status := models.SLOStatus{SLO: slo, Objectives: []models.SLOObjective{}}
+	for _, objective := range sloObjectives(slo) {
+		status.Objectives = append(status.Objectives, evaluateObjective(slo, objective, runs, now))
+	}
+	return status
+}
+
+func sloObjectives(slo models.WorkflowSLO) []string {
+	var objectives []string
+	if slo.SuccessTarget > 0 {
+		objectives = append(objectives, ObjectiveSuccess)
+	}
+	if slo.DurationTargetSeconds > 0 {
+		objectives = append(objectives, ObjectiveDuration)
+	}
+	return objectives
+}
+
+func evaluateObjective(slo models.WorkflowSLO, objective string, runs []models.SLORun, now time.Time) models.SLOObjective {
+	result := models.SLOObjective{Objective: objective, Target: objectiveTarget(slo, objective), Met: true, ErrorBudgetRemaining: 1}
+	result.Good, result.Total = countGood(slo, objective, runs, time.Time{})
+
+	allowed := 1 - result.Target/100
+	if result.Total > 0 {
+		compliance := float64(result.Good) / float64(result.Total) * 100
+		result.Compliance = &compliance
+		result.Met = compliance >= result.Target
+		result.ErrorBudgetRemaining = 1 - (1-compliance/100)/allowed
+	}
+	result.BurnRate1h = burnRate(slo, objective, runs, now.Add(-BurnRateFastWindow), allowed)
+	result.BurnRate6h = burnRate(slo, objective, runs, now.Add(-BurnRateSlowWindow), allowed)
+
+	if objective == ObjectiveDuration {
+		var durations []float64
+		for _, run := range runs {
+			if run.Conclusion == "success" && run.Seconds >= 0 {
+				durations = append(durations, run.Seconds)
+			}
+		}
+		if len(durations) > 0 {
+			sort.Float64s(durations)
+			observed := utils.Percentile(durations, float64(slo.DurationPercentile))
+			result.ObservedSeconds = &observed
+		}
+	}
+	return result
+}
+
+// objectiveTarget returns the percentage of runs that should be good. A
+// duration objective of p90 under 15m wants 90% of runs under 15m.
+func objectiveTarget(slo models.WorkflowSLO, objective string) float64 {
+	if objective == ObjectiveDuration {
+		return float64(slo.DurationPercentile)
+	}
+	return slo.SuccessTarget
+}
+
+// countGood counts the runs completed after since that an objective applies
+// to and those of them that met it. Duration objectives only count
+// successful runs, as failures spend the success objective's budget.
+func countGood(slo models.WorkflowSLO, objective string, runs []models.SLORun, since time.Time) (good, total int) {
+	for _, run := range runs {
+		if run.CompletedAt.Before(since) {
+			continue
+		}
+		switch objective {
+		case ObjectiveSuccess:
+			if run.Conclusion == "success" {
+				good++
+				total++
+			} else if failedConclusions[run.Conclusion] {
+				total++
+			}
+		case ObjectiveDuration:
+			if run.Conclusion != "success" || run.Seconds < 0 {
+				continue
+			}
+			total++
+			if run.Seconds <= float64(slo.DurationTargetSeconds) {
+				good++
+			}
+		}
+	}
+	return good, total
+}
+
+// burnRate returns how many times faster than sustainable the runs completed
+// after since spent the error budget; 0 without runs.
+func burnRate(slo models.WorkflowSLO, objective string, runs []models.SLORun, since time.Time, allowed float64) float64 {
+	good, total := countGood(slo, objective, runs, since)
+	if total == 0 {
+		return 0
+	}
+	return float64(total-good) / float64(total) / allowed
+}
+
+// SLOStatuses evaluates every workflow SLO over its window.
+func SLOStatuses(ctx context.Context, cfg *config.Config, db database.DatabaseInterface) ([]models.SLOStatus, error) {
+	slos, err := WorkflowSLOs(ctx, cfg, db)
+	if err != nil {
+		return nil, err
+	}
+
+	now := cfg.Now()
+	statuses := make([]models.SLOStatus, 0, len(slos))
+	for _, slo := range slos {
+		window := time.Duration(slo.WindowDays) * 24 * time.Hour
+		runs, err := db.GetSLORuns(ctx, slo.Repository, slo.Workflow, window)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, EvaluateSLO(slo, runs, now))
+	}
+	return statuses, nil
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/config"
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateSLO(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	slo := models.WorkflowSLO{Name: "deploy", SuccessTarget: 90, DurationPercentile: 50, DurationTargetSeconds: 600, WindowDays: 7}
+
+	var runs []models.SLORun
+	// Eight good runs two days ago, then a failure, a timeout and a slow run in the last hour
+	for i := 0; i < 8; i++ {
+		runs = append(runs, models.SLORun{Conclusion: "success", CompletedAt: now.Add(-48 * time.Hour), Seconds: 300})
+	}
+	runs = append(runs,
+		models.SLORun{Conclusion: "cancelled", CompletedAt: now.Add(-30 * time.Minute), Seconds: 10},
+		models.SLORun{Conclusion: "failure", CompletedAt: now.Add(-20 * time.Minute), Seconds: 100},
+		models.SLORun{Conclusion: "timed_out", CompletedAt: now.Add(-10 * time.Minute), Seconds: 3600},
+		models.SLORun{Conclusion: "success", CompletedAt: now.Add(-5 * time.Minute), Seconds: 900},
+	)
+
+	status := EvaluateSLO(slo, runs, now)
+	require.Len(t, status.Objectives, 2)
+
+	success := status.Objectives[0]
+	assert.Equal(t, ObjectiveSuccess, success.Objective)
+	assert.Equal(t, 11, success.Total, "cancelled runs are not counted")
+	assert.Equal(t, 9, success.Good)
+	require.NotNil(t, success.Compliance)
+	assert.InDelta(t, 81.8, *success.Compliance, 0.1)
+	assert.False(t, success.Met)
+	assert.InDelta(t, 1-(2.0/11)/0.1, success.ErrorBudgetRemaining, 0.001, "the budget is overspent")
+	assert.InDelta(t, (2.0/3)/0.1, success.BurnRate1h, 0.001)
+
+	duration := status.Objectives[1]
+	assert.Equal(t, ObjectiveDuration, duration.Objective)
+	assert.Equal(t, 50.0, duration.Target)
+	assert.Equal(t, 9, duration.Total, "only successful runs are counted")
+	assert.Equal(t, 8, duration.Good)
+	assert.True(t, duration.Met)
+	assert.InDelta(t, 2.0, duration.BurnRate6h, 0.001, "the one run in the last 6 hours was slow")
+	require.NotNil(t, duration.ObservedSeconds)
+	assert.Equal(t, 300.0, *duration.ObservedSeconds)
+}
+
+func TestEvaluateSLO_NoRuns(t *testing.T) {
+	status := EvaluateSLO(models.WorkflowSLO{SuccessTarget: 99, WindowDays: 28}, nil, time.Now())
+
+	require.Len(t, status.Objectives, 1)
+	assert.Nil(t, status.Objectives[0].Compliance)
+	assert.True(t, status.Objectives[0].Met)
+	assert.Equal(t, 1.0, status.Objectives[0].ErrorBudgetRemaining)
+	assert.Zero(t, status.Objectives[0].BurnRate1h)
+}
+
+func TestWorkflowSLOs(t *testing.T) {
+	mockDB := new(database.MockDatabase)
+	cfg := &config.Config{Vars: config.Vars{WorkflowSLOs: map[string]models.WorkflowSLO{
+		"deploy": {Name: "deploy", Repository: "app", Workflow: "Deploy", SuccessTarget: 99, WindowDays: 28, Source: "config"},
+	}}}
+	mockDB.On("GetWorkflowSLOs", mock.Anything).Return([]models.WorkflowSLO{
+		{Name: "ci", Repository: "app", Workflow: "CI", SuccessTarget: 95, WindowDays: 7, Source: "api"},
+		{Name: "deploy", Repository: "shadowed", Workflow: "Deploy", SuccessTarget: 90, WindowDays: 7, Source: "api"},
+	}, nil)
+
+	slos, err := WorkflowSLOs(context.Background(), cfg, mockDB)
+
+	require.NoError(t, err)
+	require.Len(t, slos, 2)
+	assert.Equal(t, "deploy", slos[0].Name)
+	assert.Equal(t, "config", slos[0].Source)
+	assert.Equal(t, "ci", slos[1].Name)
+}
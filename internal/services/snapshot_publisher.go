@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/config"
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/internal/s3"
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// SnapshotFileName is the file written to SNAPSHOT_DIR, and the name of the
+// object uploaded under SNAPSHOT_S3_PREFIX.
+const SnapshotFileName = "live-actions.json"
+
+// SnapshotFields lists the fields that may be published. None of them carry
+// repository, workflow or job names.
+var SnapshotFields = []string{"running_jobs", "queued_jobs", "total_completed", "total_failed", "failure_rate", "labels"}
+
+// snapshotLabel is the per-runner-label entry of the "labels" field.
+type snapshotLabel struct {
+	Label   string `json:"label"`
+	Running int    `json:"running"`
+	Queued  int    `json:"queued"`
+}
+
+// SnapshotPublisher periodically writes a sanitized JSON summary to a local
+// directory, where a static site can pick it up, and uploads it to an S3
+// bucket, without exposing the API.
+type SnapshotPublisher struct {
+	config *config.Config
+	db     database.DatabaseInterface
+	fields map[string]bool
+	// bucket uploads snapshots; nil when no bucket is configured
+	bucket *s3.Client
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewSnapshotPublisher creates a publisher for the configured fields. It
+// fails on field names not in SnapshotFields.
+func NewSnapshotPublisher(config *config.Config, db database.DatabaseInterface, ctx context.Context) (*SnapshotPublisher, error) {
+	known := make(map[string]bool)
+	for _, field := range SnapshotFields {
+		known[field] = true
+	}
+
+	fields := known
+	if len(config.Vars.SnapshotFields) > 0 {
+		fields = make(map[string]bool)
+		for _, field := range config.Vars.SnapshotFields {
+			if !known[field] {
+				return nil, fmt.Errorf("unknown snapshot field %q", field)
+			}
+			fields[field] = true
+		}
+	}
+
+	var bucket *s3.Client
+	if v := config.Vars; v.SnapshotS3Bucket != "" {
+		bucket = s3.NewClient(v.SnapshotS3Endpoint, v.SnapshotS3Region, v.SnapshotS3Bucket, s3.Credentials{
+			AccessKeyID:     v.SnapshotS3AccessKey,
+			SecretAccessKey: v.SnapshotS3SecretKey,
+			SessionToken:    v.SnapshotS3SessionToken,
+		})
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	return &SnapshotPublisher{
+		config: config,
+		db:     db,
+		fields: fields,
+		bucket: bucket,
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}, nil
+}
+
+// Start writes a snapshot immediately and then on every interval
+func (p *SnapshotPublisher) Start() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.config.GetSnapshotInterval())
+	defer ticker.Stop()
+
+	p.publishAndLog()
+
+	for {
+		select {
+		case <-p.ctx.Done():
+			logger.Logger.Debug("Snapshot publisher stopped")
+			return
+		case <-ticker.C:
+			p.publishAndLog()
+		}
+	}
+}
+
+// Stop gracefully stops the snapshot publisher
+func (p *SnapshotPublisher) Stop() {
+	p.cancel()
+	<-p.done
+}
+
+func (p *SnapshotPublisher) publishAndLog() {
+	if err := p.publish(p.config.Now()); err != nil {
+		logger.Logger.Error("Failed to publish snapshot",
+			zap.String("dir", p.config.Vars.SnapshotDir),
+			zap.String("bucket", p.config.Vars.SnapshotS3Bucket),
+			zap.Error(err))
+	}
+}
+
+// buildSnapshot collects the configured fields.
+func (p *SnapshotPublisher) buildSnapshot(now time.Time) (map[string]interface{}, error) {
+	snapshot := map[string]interface{}{
+		"generated_at": now.UTC().Format(time.RFC3339),
+	}
+
+	if p.fields["running_jobs"] || p.fields["queued_jobs"] {
+		running, queued, err := p.db.GetCurrentJobCounts(p.ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get job counts: %w", err)
+		}
+		if p.fields["running_jobs"] {
+			snapshot["running_jobs"] = running
+		}
+		if p.fields["queued_jobs"] {
+			snapshot["queued_jobs"] = queued
+		}
+	}
+
+	if p.fields["total_completed"] || p.fields["total_failed"] || p.fields["failure_rate"] {
+		failures, err := p.db.GetFailureSummary(p.ctx, 24*time.Hour, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get failure analytics: %w", err)
+		}
+		if p.fields["total_completed"] {
+			snapshot["total_completed"] = failures.TotalCompleted
+		}
+		if p.fields["total_failed"] {
+			snapshot["total_failed"] = failures.TotalFailed
+		}
+		if p.fields["failure_rate"] {
+			snapshot["failure_rate"] = failures.FailureRate
+		}
+	}
+
+	if p.fields["labels"] {
+		counts, err := p.db.GetCurrentJobCountsByLabel(p.ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get job counts by label: %w", err)
+		}
+		labels := make([]snapshotLabel, 0, len(counts))
+		for _, lc := range counts {
+			labels = append(labels, snapshotLabel{Label: lc.Label, Running: lc.Running, Queued: lc.Queued})
+		}
+		snapshot["labels"] = labels
+	}
+
+	return snapshot, nil
+}
+
+// publish writes the snapshot to the directory and uploads it to the bucket,
+// whichever are configured.
+func (p *SnapshotPublisher) publish(now time.Time) error {
+	snapshot, err := p.buildSnapshot(now)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	if p.config.Vars.SnapshotDir != "" {
+		if err := p.writeFile(data); err != nil {
+			return err
+		}
+	}
+	if p.bucket != nil {
+		key := strings.TrimPrefix(path.Join(p.config.Vars.SnapshotS3Prefix, SnapshotFileName), "/")
+		if err := p.bucket.PutObject(p.ctx, key, data, "application/json"); err != nil {
+			return fmt.Errorf("failed to upload snapshot: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeFile writes the snapshot through a temporary file so readers never
+// see a partially written file.
+func (p *SnapshotPublisher) writeFile(data []byte) error {
+	dir := p.config.Vars.SnapshotDir
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, SnapshotFileName+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to set snapshot permissions: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, SnapshotFileName)); err != nil {
+		return fmt.Errorf("failed to replace snapshot: %w", err)
+	}
+	return nil
+}
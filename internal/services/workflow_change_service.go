@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/config"
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/internal/github"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// workflowChangeInterval is how often workflow files are checked for new
+	// commits.
+	workflowChangeInterval = 15 * time.Minute
+	// WorkflowChangeLookback is how far back workflow files and their changes
+	// are looked up.
+	WorkflowChangeLookback = 14 * 24 * time.Hour
+	// workflowChangeOverlap re-reads a little of the previous check to allow
+	// for commits pushed with an earlier committer date.
+	workflowChangeOverlap = time.Hour
+)
+
+// WorkflowChangeService looks up the commits that changed the workflow files
+// runs were started from, so failure rate shifts can be pinned on them.
+// Webhooks only carry a run's head commit, which usually did not touch its
+// workflow file.
+type WorkflowChangeService struct {
+	config   *config.Config
+	db       database.DatabaseInterface
+	client   *github.Client
+	interval time.Duration
+	ctx      context.Context
+	cancel   context.CancelFunc
+	done     chan struct{}
+
+	// checked is when each repository's workflow file was last looked up
+	checked map[string]time.Time
+}
+
+// NewWorkflowChangeService creates a new workflow change service instance
+func NewWorkflowChangeService(config *config.Config, db database.DatabaseInterface, ctx context.Context) *WorkflowChangeService {
+	ctx, cancel := context.WithCancel(ctx)
+
+	return &WorkflowChangeService{
+		config:   config,
+		db:       db,
+		client:   github.NewClient(config.Vars.GitHubAPIURL, config.Vars.GitHubAPIToken),
+		interval: workflowChangeInterval,
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+		checked:  make(map[string]time.Time),
+	}
+}
+
+// Start looks up workflow file changes right away and then periodically
+// until Stop is called
+func (s *WorkflowChangeService) Start() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	logger.Logger.Info("Workflow change service started", zap.Duration("interval", s.interval))
+	s.sync()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			logger.Logger.Debug("Workflow change service stopped")
+			return
+		case <-ticker.C:
+			s.sync()
+		}
+	}
+}
+
+// Stop gracefully stops the workflow change service
+func (s *WorkflowChangeService) Stop() {
+	s.cancel()
+	<-s.done
+}
+
+// sync stores the commits that changed each recently used workflow file
+// since it was last checked. A file that cannot be looked up is retried on
+// the next check.
+func (s *WorkflowChangeService) sync() {
+	files, err := s.db.GetWorkflowFiles(s.ctx, WorkflowChangeLookback)
+	if err != nil {
+		logger.Logger.Error("Failed to get workflow files", zap.Error(err))
+		return
+	}
+
+	now := s.config.Now()
+	for _, file := range files {
+		if s.ctx.Err() != nil {
+			return
+		}
+		key := file.Repository + "\x00" + file.Path
+		since := now.Add(-WorkflowChangeLookback)
+		if checked, ok := s.checked[key]; ok {
+			since = checked.Add(-workflowChangeOverlap)
+		}
+
+		commits, err := s.client.ListFileCommits(s.ctx, file.FullName, file.Path, since)
+		if err != nil {
+			logger.Logger.Warn("Failed to look up workflow file changes",
+				zap.String("repository", file.FullName), zap.String("path", file.Path), zap.Error(err))
+			continue
+		}
+
+		changes := make([]models.WorkflowFileChange, 0, len(commits))
+		for _, commit := range commits {
+			message, _, _ := strings.Cut(commit.Commit.Message, "\n")
+			changes = append(changes, models.WorkflowFileChange{
+				Repository:  file.Repository,
+				Path:        file.Path,
+				SHA:         commit.SHA,
+				Message:     message,
+				Author:      commit.Commit.Author.Name,
+				HtmlUrl:     commit.HtmlUrl,
+				CommittedAt: commit.Commit.Committer.Date,
+			})
+		}
+		if err := s.db.SaveWorkflowFileChanges(s.ctx, changes); err != nil {
+			logger.Logger.Error("Failed to save workflow file changes", zap.String("path", file.Path), zap.Error(err))
+			continue
+		}
+		s.checked[key] = now
+	}
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/clock"
+	"github.com/gateixeira/live-actions/internal/config"
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflowChangeService_Sync(t *testing.T) {
+	setupTestLogger()
+	committedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var sinces []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/octo/app/commits", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.Equal(t, ".github/workflows/ci.yml", r.URL.Query().Get("path"))
+		sinces = append(sinces, r.URL.Query().Get("since"))
+		json.NewEncoder(w).Encode([]map[string]interface{}{{
+			"sha":      "abc123",
+			"html_url": "https://github.com/octo/app/commit/abc123",
+			"commit": map[string]interface{}{
+				"message":   "Bump node\n\nNode 20 is out of support.",
+				"author":    map[string]interface{}{"name": "Mona"},
+				"committer": map[string]interface{}{"date": committedAt},
+			},
+		}})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	now := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	cfg := &config.Config{
+		Vars:  config.Vars{GitHubAPIURL: server.URL, GitHubAPIToken: "token"},
+		Clock: clock.NewFake(now),
+	}
+	mockDB := new(database.MockDatabase)
+	mockDB.On("GetWorkflowFiles", mock.Anything, WorkflowChangeLookback).Return([]models.WorkflowFile{
+		{Repository: "app", FullName: "octo/app", Path: ".github/workflows/ci.yml"},
+	}, nil)
+	var saved []models.WorkflowFileChange
+	mockDB.On("SaveWorkflowFileChanges", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).([]models.WorkflowFileChange)
+	}).Return(nil)
+
+	service := NewWorkflowChangeService(cfg, mockDB, context.Background())
+	service.sync()
+	service.sync()
+
+	require.Len(t, saved, 1)
+	assert.Equal(t, models.WorkflowFileChange{
+		Repository:  "app",
+		Path:        ".github/workflows/ci.yml",
+		SHA:         "abc123",
+		Message:     "Bump node",
+		Author:      "Mona",
+		HtmlUrl:     "https://github.com/octo/app/commit/abc123",
+		CommittedAt: committedAt,
+	}, saved[0])
+	// The first check looks back over the whole lookback, later ones only
+	// since the previous check
+	assert.Equal(t, []string{
+		now.Add(-WorkflowChangeLookback).Format(time.RFC3339),
+		now.Add(-workflowChangeOverlap).Format(time.RFC3339),
+	}, sinces)
+}
@@ -0,0 +1,203 @@
+// Package support builds support bundles: an archive of the context needed to
+// reproduce a bug report, with secrets and identifying data removed.
+package support
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/config"
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gateixeira/live-actions/pkg/logger"
+)
+
+// eventSampleLimit is how many webhook events a bundle includes.
+const eventSampleLimit = 50
+
+// keptPayloadKeys are payload fields whose string values describe the event
+// rather than who sent it, so they are kept as is.
+var keptPayloadKeys = map[string]bool{
+	"action":         true,
+	"status":         true,
+	"conclusion":     true,
+	"event":          true,
+	"type":           true,
+	"created_at":     true,
+	"started_at":     true,
+	"completed_at":   true,
+	"updated_at":     true,
+	"run_started_at": true,
+}
+
+// Write writes a zip support bundle to w. Sections that cannot be collected
+// record the error instead, so a broken database still yields a bundle.
+func Write(ctx context.Context, w io.Writer, cfg *config.Config, db database.DatabaseInterface) error {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("failed to generate anonymization salt: %w", err)
+	}
+
+	archive := zip.NewWriter(w)
+
+	schemaVersion, err := db.GetSchemaVersion(ctx)
+	manifest := map[string]interface{}{
+		"generated_at":   cfg.Now().UTC(),
+		"build":          buildInfo(),
+		"go_version":     runtime.Version(),
+		"platform":       runtime.GOOS + "/" + runtime.GOARCH,
+		"schema_version": schemaVersion,
+	}
+	if err != nil {
+		manifest["schema_version"] = errorSection(err)
+	}
+	if err := writeJSON(archive, "manifest.json", manifest); err != nil {
+		return err
+	}
+
+	if err := writeJSON(archive, "config.json", cfg.Redacted()); err != nil {
+		return err
+	}
+
+	if err := writeJSON(archive, "topology.json", Topology(cfg)); err != nil {
+		return err
+	}
+
+	logs, err := archive.Create("logs.jsonl")
+	if err != nil {
+		return fmt.Errorf("failed to add logs.jsonl: %w", err)
+	}
+	for _, line := range logger.Recent() {
+		if _, err := io.WriteString(logs, line+"\n"); err != nil {
+			return fmt.Errorf("failed to write logs.jsonl: %w", err)
+		}
+	}
+
+	system := map[string]interface{}{}
+	if lag, err := db.GetProcessingLagStats(ctx, time.Hour); err != nil {
+		system["processing_lag"] = errorSection(err)
+	} else {
+		system["processing_lag"] = lag
+	}
+	if storage, err := db.GetStorageReport(ctx); err != nil {
+		system["storage"] = errorSection(err)
+	} else {
+		system["storage"] = storage
+	}
+	if err := writeJSON(archive, "system.json", system); err != nil {
+		return err
+	}
+
+	var events interface{}
+	if samples, err := db.GetEventSamples(ctx, eventSampleLimit); err != nil {
+		events = errorSection(err)
+	} else {
+		events = anonymizeEvents(samples, salt)
+	}
+	if err := writeJSON(archive, "events.json", events); err != nil {
+		return err
+	}
+
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to finish support bundle: %w", err)
+	}
+	return nil
+}
+
+// buildInfo returns the module version and VCS details embedded by the Go
+// toolchain.
+func buildInfo() map[string]string {
+	info := map[string]string{}
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info["module_version"] = build.Main.Version
+	for _, setting := range build.Settings {
+		if strings.HasPrefix(setting.Key, "vcs.") {
+			info[setting.Key] = setting.Value
+		}
+	}
+	return info
+}
+
+func errorSection(err error) map[string]string {
+	return map[string]string{"error": err.Error()}
+}
+
+func writeJSON(archive *zip.Writer, name string, v interface{}) error {
+	f, err := archive.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// anonymizeEvents replaces identifying strings in the event payloads.
+// Payloads that cannot be decoded are dropped.
+func anonymizeEvents(samples []models.EventSample, salt []byte) []models.EventSample {
+	for i := range samples {
+		if len(samples[i].Payload) == 0 {
+			continue
+		}
+		var payload interface{}
+		if err := json.Unmarshal(samples[i].Payload, &payload); err != nil {
+			samples[i].Payload = nil
+			continue
+		}
+		anonymized, err := json.Marshal(anonymize(payload, "", salt))
+		if err != nil {
+			samples[i].Payload = nil
+			continue
+		}
+		samples[i].Payload = anonymized
+	}
+	return samples
+}
+
+// anonymize replaces every string outside keptPayloadKeys with a salted
+// hash, so names, URLs and commit messages are removed while equal values
+// still match each other within the bundle. Numbers and booleans are kept.
+func anonymize(v interface{}, key string, salt []byte) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			v[k] = anonymize(child, k, salt)
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = anonymize(child, key, salt)
+		}
+		return v
+	case string:
+		if keptPayloadKeys[key] || v == "" {
+			return v
+		}
+		return pseudonym(v, salt)
+	default:
+		return v
+	}
+}
+
+func pseudonym(value string, salt []byte) string {
+	h := sha256.New()
+	h.Write(salt)
+	h.Write([]byte(value))
+	return "anon-" + hex.EncodeToString(h.Sum(nil))[:12]
+}
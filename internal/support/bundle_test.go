@@ -0,0 +1,101 @@
+package support
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/internal/config"
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func readBundle(t *testing.T, data []byte) map[string][]byte {
+	t.Helper()
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	files := map[string][]byte{}
+	for _, f := range archive.File {
+		r, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(r)
+		require.NoError(t, err)
+		r.Close()
+		files[f.Name] = content
+	}
+	return files
+}
+
+func TestWrite(t *testing.T) {
+	logger.Init(logger.Options{Level: "info", Output: io.Discard})
+	logger.Logger.Info("bundle test line")
+
+	mockDB := new(database.MockDatabase)
+	mockDB.On("GetSchemaVersion", mock.Anything).Return(12, nil)
+	mockDB.On("GetProcessingLagStats", mock.Anything, time.Hour).Return(&models.ProcessingLagStats{Samples: 3, P95: 1.5}, nil)
+	mockDB.On("GetStorageReport", mock.Anything).Return((*models.StorageReport)(nil), errors.New("dbstat unavailable"))
+	mockDB.On("GetEventSamples", mock.Anything, eventSampleLimit).Return([]models.EventSample{
+		{EventType: "workflow_job", Status: "failed", OrderingKey: "job_1",
+			Payload: json.RawMessage(`{"action":"completed","workflow_job":{"id":1,"name":"deploy secrets","conclusion":"failure","labels":["corp-runner"]},"repository":{"name":"secret-project"},"sender":{"login":"mona"}}`)},
+		{EventType: "workflow_run", Status: "processed", OrderingKey: "run_2"},
+	}, nil)
+
+	cfg := &config.Config{Vars: config.Vars{WebhookSecret: "s3cret", Port: "8080"}}
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(context.Background(), &buf, cfg, mockDB))
+	files := readBundle(t, buf.Bytes())
+
+	var manifest map[string]interface{}
+	require.NoError(t, json.Unmarshal(files["manifest.json"], &manifest))
+	assert.Equal(t, 12.0, manifest["schema_version"])
+
+	assert.NotContains(t, string(files["config.json"]), "s3cret")
+	assert.Contains(t, string(files["config.json"]), `"Port": "8080"`)
+
+	assert.Contains(t, string(files["topology.json"]), `"webhook_signature": true`)
+
+	assert.Contains(t, string(files["logs.jsonl"]), "bundle test line")
+
+	var system map[string]map[string]interface{}
+	require.NoError(t, json.Unmarshal(files["system.json"], &system))
+	assert.Equal(t, 3.0, system["processing_lag"]["samples"])
+	assert.Equal(t, "dbstat unavailable", system["storage"]["error"], "a failing section is recorded, not fatal")
+
+	events := string(files["events.json"])
+	for _, secret := range []string{"secret-project", "mona", "deploy secrets", "corp-runner"} {
+		assert.NotContains(t, events, secret)
+	}
+	assert.Contains(t, events, `"conclusion": "failure"`)
+	assert.Contains(t, events, `"action": "completed"`)
+}
+
+func TestAnonymize_ConsistentWithinBundle(t *testing.T) {
+	salt := []byte("salt")
+	payload := map[string]interface{}{
+		"repository": map[string]interface{}{"name": "app"},
+		"workflow_run": map[string]interface{}{
+			"id":         float64(7),
+			"status":     "completed",
+			"repository": map[string]interface{}{"name": "app"},
+		},
+	}
+
+	out := anonymize(payload, "", salt).(map[string]interface{})
+	outer := out["repository"].(map[string]interface{})["name"]
+	run := out["workflow_run"].(map[string]interface{})
+	assert.NotEqual(t, "app", outer)
+	assert.Equal(t, outer, run["repository"].(map[string]interface{})["name"])
+	assert.Equal(t, "completed", run["status"])
+	assert.Equal(t, float64(7), run["id"])
+	assert.NotEqual(t, pseudonym("app", salt), pseudonym("app", []byte("other")))
+}
@@ -0,0 +1,79 @@
+package support
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/gateixeira/live-actions/internal/config"
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/models"
+)
+
+// Topology describes how cfg sets up the instance: event backend, database,
+// authentication, integrations and retention. Secrets and URLs that may embed
+// them are left out.
+func Topology(cfg *config.Config) models.SystemTopology {
+	v := cfg.Vars
+	driver := "sqlite"
+	if v.DatabaseEncryptionKey != "" {
+		driver = "sqlcipher"
+	}
+	proxies := append([]string{}, v.TrustedProxies...)
+
+	peers := make([]string, 0, len(v.FederationPeers))
+	for name := range v.FederationPeers {
+		peers = append(peers, name)
+	}
+	sort.Strings(peers)
+
+	integrations := []models.IntegrationStatus{
+		// The alert webhook URL is left out, chat webhooks embed their token in it
+		{Name: "alert_webhook", Enabled: v.AlertWebhookURL != ""},
+		{Name: "federation", Enabled: len(peers) > 0, Detail: strings.Join(peers, ",")},
+		{Name: "canary", Enabled: cfg.CanaryEnabled(), Detail: v.CanaryRepository},
+		{Name: "workflow_changes", Enabled: cfg.WorkflowChangesEnabled(), Detail: v.GitHubAPIURL},
+		{Name: "snapshots", Enabled: v.SnapshotDir != "", Detail: v.SnapshotDir},
+		{Name: "access_log", Enabled: v.AccessLog != "", Detail: v.AccessLog},
+		{Name: "pprof", Enabled: v.PprofEnabled, Detail: v.PprofAddr},
+		{Name: "anonymize", Enabled: v.Anonymize},
+	}
+	for i := range integrations {
+		if !integrations[i].Enabled {
+			integrations[i].Detail = ""
+		}
+	}
+
+	return models.SystemTopology{
+		Version:     buildInfo()["module_version"],
+		Environment: v.Environment,
+		EventBackend: models.EventBackendTopology{
+			Type:                    "database",
+			DedupeWindowSeconds:     v.DedupeWindowSeconds,
+			JobSuccessSamplePercent: v.JobSuccessSamplePercent,
+			SSEMaxClients:           v.SSEMaxClients,
+			SSEOverflowMode:         v.SSEOverflowMode,
+		},
+		Database: models.DatabaseTopology{
+			Driver:              driver,
+			Path:                v.DatabasePath,
+			Encrypted:           v.DatabaseEncryptionKey != "",
+			EncryptionSupported: database.EncryptionSupported(),
+			WaitForMigrations:   v.WaitForMigrations,
+		},
+		Auth: models.AuthTopology{
+			WebhookSignature:  v.WebhookSecret != "",
+			AdminAPI:          v.AdminToken != "",
+			FederationServing: v.FederationToken != "",
+			RemoteWrite:       cfg.RemoteWriteEnabled(),
+			RunnerHeartbeats:  v.RunnerHeartbeatToken != "",
+			TLS:               cfg.IsHTTPS(),
+			TrustedProxies:    proxies,
+		},
+		Integrations: integrations,
+		Retention: models.RetentionTopology{
+			DataRetentionDays:      v.DataRetentionDays,
+			CleanupIntervalHours:   v.CleanupIntervalHours,
+			StaleJobThresholdHours: v.StaleJobThresholdHours,
+		},
+	}
+}
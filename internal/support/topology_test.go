@@ -0,0 +1,52 @@
+package support
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gateixeira/live-actions/internal/config"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopology(t *testing.T) {
+	cfg := &config.Config{Vars: config.Vars{
+		WebhookSecret:         "s3cret",
+		AdminToken:            "admin-token",
+		DatabasePath:          "/data/live-actions.db",
+		DatabaseEncryptionKey: "db-key",
+		FederationPeers:       map[string]string{"eu": "https://eu.example.com", "ghes": "https://ghes.example.com"},
+		FederationPeerTokens:  map[string]string{"eu": "peer-token"},
+		AlertWebhookURL:       "https://hooks.slack.com/services/T000/B000/XXXX",
+		CanaryToken:           "canary-token",
+		CanaryRepository:      "octo/canary",
+		PprofAddr:             "127.0.0.1:6060",
+		DataRetentionDays:     30,
+	}}
+
+	topology := Topology(cfg)
+
+	assert.Equal(t, "sqlcipher", topology.Database.Driver)
+	assert.True(t, topology.Database.Encrypted)
+	assert.True(t, topology.Auth.WebhookSignature)
+	assert.True(t, topology.Auth.AdminAPI)
+	assert.False(t, topology.Auth.RemoteWrite)
+	assert.Equal(t, 30, topology.Retention.DataRetentionDays)
+
+	integrations := map[string]models.IntegrationStatus{}
+	for _, integration := range topology.Integrations {
+		integrations[integration.Name] = integration
+	}
+	assert.Equal(t, models.IntegrationStatus{Name: "federation", Enabled: true, Detail: "eu,ghes"}, integrations["federation"])
+	assert.Equal(t, "octo/canary", integrations["canary"].Detail)
+	assert.True(t, integrations["alert_webhook"].Enabled)
+	assert.Equal(t, models.IntegrationStatus{Name: "pprof"}, integrations["pprof"], "disabled integrations carry no detail")
+
+	body, err := json.Marshal(topology)
+	require.NoError(t, err)
+	for _, secret := range []string{"s3cret", "admin-token", "db-key", "peer-token", "canary-token", "hooks.slack.com"} {
+		assert.NotContains(t, string(body), secret)
+	}
+	assert.Contains(t, string(body), `"trusted_proxies":[]`)
+}
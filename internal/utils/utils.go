@@ -3,6 +3,9 @@ package utils
 import (
 	"crypto/rand"
 	"encoding/base64"
+	"math"
+	"net/url"
+	"strings"
 	"time"
 )
 
@@ -40,3 +43,29 @@ func FormatDuration(seconds float64) string {
 	duration := time.Duration(seconds * float64(time.Second))
 	return duration.String()
 }
+
+// RepoFullName derives the owner/name of a repository from a GitHub html_url
+// such as https://github.com/owner/name/actions/runs/1. It falls back to the
+// given name when the URL does not contain both segments.
+func RepoFullName(htmlURL, fallback string) string {
+	u, err := url.Parse(htmlURL)
+	if err != nil {
+		return fallback
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return fallback
+	}
+	return parts[0] + "/" + parts[1]
+}
+
+// Percentile returns the p-th percentile of sorted values using linear interpolation.
+func Percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
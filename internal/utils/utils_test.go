@@ -69,3 +69,25 @@ func TestGenerateCSRFToken(t *testing.T) {
 		t.Error("GenerateCSRFToken() returned identical tokens")
 	}
 }
+
+func TestRepoFullName(t *testing.T) {
+	tests := []struct {
+		name     string
+		htmlURL  string
+		fallback string
+		expected string
+	}{
+		{"github.com run url", "https://github.com/octo/app/actions/runs/1", "app", "octo/app"},
+		{"ghes run url", "https://ghes.example.com/octo/app/actions/runs/1", "app", "octo/app"},
+		{"empty url", "", "app", "app"},
+		{"url without repo", "https://github.com/octo", "app", "app"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RepoFullName(tt.htmlURL, tt.fallback); got != tt.expected {
+				t.Errorf("RepoFullName() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -2,9 +2,13 @@ package main
 
 import (
 	"embed"
+	"errors"
+	"flag"
 	"fmt"
+	"os"
 	"runtime"
 
+	"github.com/gateixeira/live-actions/cmd/maintenance"
 	"github.com/gateixeira/live-actions/cmd/server"
 )
 
@@ -19,6 +23,27 @@ var (
 var staticFS embed.FS
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "prune":
+			os.Exit(maintenance.RunPrune(os.Args[2:], os.Stdout, os.Stderr))
+		case "compact-snapshots":
+			os.Exit(maintenance.RunCompactSnapshots(os.Args[2:], os.Stdout, os.Stderr))
+		case "serve":
+			opts, err := server.ParseServeArgs(os.Args[2:], os.Stderr)
+			if errors.Is(err, flag.ErrHelp) {
+				os.Exit(0)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(2)
+			}
+			if opts.CheckConfig {
+				os.Exit(server.RunCheckConfig(opts.Format, os.Stdout))
+			}
+		}
+	}
+
 	fmt.Printf("Live Actions %s (commit: %s, built: %s)\n", version, commit, date)
 	fmt.Printf("Go version: %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
 
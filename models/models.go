@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -30,29 +31,184 @@ type WorkflowRunEvent struct {
 }
 
 type WorkflowJob struct {
-	ID          int64     `json:"id" binding:"required"`
-	Name        string    `json:"name" binding:"required"`
-	Status      JobStatus `json:"status" binding:"required"`
-	Labels      []string  `json:"labels" binding:"required"`
-	HtmlUrl     string    `json:"html_url"`
-	Conclusion  string    `json:"conclusion"`
-	CreatedAt   time.Time `json:"created_at" binding:"required"`
-	StartedAt   time.Time `json:"started_at"`
-	CompletedAt time.Time `json:"completed_at"`
-	RunID       int64     `json:"run_id" binding:"required"`
+	ID              int64     `json:"id" binding:"required"`
+	Name            string    `json:"name" binding:"required"`
+	Status          JobStatus `json:"status" binding:"required"`
+	Labels          []string  `json:"labels" binding:"required"`
+	HtmlUrl         string    `json:"html_url"`
+	Conclusion      string    `json:"conclusion"`
+	CreatedAt       time.Time `json:"created_at" binding:"required"`
+	StartedAt       time.Time `json:"started_at"`
+	CompletedAt     time.Time `json:"completed_at"`
+	RunID           int64     `json:"run_id" binding:"required"`
+	RunnerName      string    `json:"runner_name,omitempty"` // Set once a runner picks the job up
+	RunnerGroupID   int64     `json:"runner_group_id,omitempty"`
+	RunnerGroupName string    `json:"runner_group_name,omitempty"`
+	ETA             *JobETA   `json:"eta,omitempty"`
+}
+
+// JobETA estimates when an in-progress job will finish based on the durations
+// of previous successful runs of the same job. Earliest and Latest bound the
+// 10th to 90th percentile range.
+type JobETA struct {
+	EstimatedCompletion time.Time `json:"estimated_completion"`
+	Earliest            time.Time `json:"earliest"`
+	Latest              time.Time `json:"latest"`
+	Progress            float64   `json:"progress"` // percent of the median duration elapsed, capped at 99
+	Samples             int       `json:"samples"`
+}
+
+// DurationStats holds duration percentiles in seconds for a job.
+type DurationStats struct {
+	Samples int     `json:"samples"`
+	P10     float64 `json:"p10"`
+	P50     float64 `json:"p50"`
+	P90     float64 `json:"p90"`
+}
+
+// ProcessingLagStats summarizes how long webhook events wait between being
+// received and being processed, in seconds.
+type ProcessingLagStats struct {
+	Samples              int     `json:"samples"`
+	P50                  float64 `json:"p50"`
+	P95                  float64 `json:"p95"`
+	P99                  float64 `json:"p99"`
+	Max                  float64 `json:"max"`
+	Pending              int     `json:"pending"`                // events received but not yet processed
+	OldestPendingSeconds float64 `json:"oldest_pending_seconds"` // age of the oldest pending event
+}
+
+// TableStorage is the size of one database table and its indexes. Growth is
+// averaged per day since the oldest sample in the growth window and is nil
+// until such a sample exists.
+type TableStorage struct {
+	Name              string   `json:"name"`
+	Rows              int64    `json:"rows"`
+	TableBytes        int64    `json:"table_bytes"`
+	IndexBytes        int64    `json:"index_bytes"`
+	GrowthRowsPerDay  *float64 `json:"growth_rows_per_day"`
+	GrowthBytesPerDay *float64 `json:"growth_bytes_per_day"`
+}
+
+// StorageReport describes the on-disk footprint of the database.
+type StorageReport struct {
+	DatabaseBytes int64          `json:"database_bytes"`
+	FreeBytes     int64          `json:"free_bytes"` // unused pages reclaimable with VACUUM
+	Tables        []TableStorage `json:"tables"`
+	// SizesUnavailable is set when SQLite was built without the dbstat table,
+	// as with the SQLCipher driver: tables then only report their row counts.
+	SizesUnavailable bool `json:"sizes_unavailable,omitempty"`
+}
+
+// PruneFilter selects the data removed by a targeted prune. An empty
+// Repository matches every repository and a zero Before matches any time.
+// Repositories are stored by name, so Owner, when set, narrows Repository to
+// the runs of that owner.
+type PruneFilter struct {
+	Owner      string
+	Repository string
+	Before     time.Time
+}
+
+// PruneResult counts the rows a prune removed, or would remove on a dry run.
+type PruneResult struct {
+	Runs          int64 `json:"runs"`
+	Jobs          int64 `json:"jobs"`
+	WebhookEvents int64 `json:"webhook_events"`
+	Counters      int64 `json:"counters"`
 }
 
 type WorkflowRun struct {
-	ID             int64     `json:"id" binding:"required"`
-	Name           string    `json:"name" binding:"required"`
-	Status         JobStatus `json:"status" binding:"required"`
-	HtmlUrl        string    `json:"html_url" binding:"required"`
-	DisplayTitle   string    `json:"display_title" binding:"required"`
-	Conclusion     string    `json:"conclusion"`
-	CreatedAt      time.Time `json:"created_at" binding:"required"`
-	RunStartedAt   time.Time `json:"run_started_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
-	RepositoryName string    `json:"repository_name"`
+	ID             int64       `json:"id" binding:"required"`
+	Name           string      `json:"name" binding:"required"`
+	Status         JobStatus   `json:"status" binding:"required"`
+	HtmlUrl        string      `json:"html_url" binding:"required"`
+	DisplayTitle   string      `json:"display_title" binding:"required"`
+	Conclusion     string      `json:"conclusion"`
+	CreatedAt      time.Time   `json:"created_at" binding:"required"`
+	RunStartedAt   time.Time   `json:"run_started_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+	RepositoryName string      `json:"repository_name"`
+	HeadSha        string      `json:"head_sha"`
+	HeadCommit     *HeadCommit `json:"head_commit,omitempty"`
+	Tags           []string    `json:"tags,omitempty"`
+	// Actor is who the run is attributed to; TriggeringActor is who started
+	// its latest attempt, e.g. the user who re-ran it.
+	Actor           string `json:"actor,omitempty"`
+	TriggeringActor string `json:"triggering_actor,omitempty"`
+	RunAttempt      int    `json:"run_attempt,omitempty"`
+	// WorkflowPath is the workflow file the run was started from, e.g.
+	// .github/workflows/ci.yml
+	WorkflowPath string `json:"workflow_path,omitempty"`
+}
+
+// HeadCommit is the commit a workflow run was triggered for.
+type HeadCommit struct {
+	ID      string       `json:"id"`
+	Message string       `json:"message"`
+	Author  CommitAuthor `json:"author"`
+}
+
+type CommitAuthor struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// Mute silences a run or job in alerting and failure analytics until MutedUntil.
+type Mute struct {
+	ID         int64     `json:"id"`
+	EntityType string    `json:"entity_type"` // "run" or "job"
+	EntityID   int64     `json:"entity_id"`
+	Reason     string    `json:"reason"`
+	MutedUntil time.Time `json:"muted_until"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// HandoffReport summarizes notable activity in a time window for on-call shift handoffs.
+type HandoffReport struct {
+	WindowStart     time.Time         `json:"window_start"`
+	WindowEnd       time.Time         `json:"window_end"`
+	FailedRuns      []WorkflowRun     `json:"failed_runs"`
+	LongRunningRuns []WorkflowRun     `json:"long_running_runs"`
+	Failures        *FailureAnalytics `json:"failures"`
+	PeakDemand      float64           `json:"peak_demand"`
+	AvgQueueSeconds float64           `json:"avg_queue_seconds"`
+	QueueAnomalies  []QueueAnomaly    `json:"queue_anomalies"`
+	ActiveMutes     []Mute            `json:"active_mutes"`
+}
+
+// QueueAnomaly flags a runner label whose queue time in the report window is well above its baseline.
+type QueueAnomaly struct {
+	Label                   string  `json:"label"`
+	TotalJobs               int     `json:"total_jobs"`
+	AvgQueueSeconds         float64 `json:"avg_queue_seconds"`
+	BaselineAvgQueueSeconds float64 `json:"baseline_avg_queue_seconds"`
+}
+
+// RuntimeSettings are settings adjustable at runtime through the admin API
+// and persisted across restarts.
+type RuntimeSettings struct {
+	MetricsIntervalSeconds int `json:"metrics_interval_seconds"` // how often metrics are refreshed and snapshotted
+	SSECoalesceMillis      int `json:"sse_coalesce_ms"`          // window for merging metrics updates per SSE client, 0 to disable
+}
+
+// SavedFilter is a named workflow runs filter shared by all dashboard users.
+type SavedFilter struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Repo      string    `json:"repo"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RepoGroup is a named set of repositories whose data is aggregated when
+// endpoints are queried with ?group=.
+type RepoGroup struct {
+	Name         string   `json:"name"`
+	Repositories []string `json:"repositories"`
+	// Source is "config" for groups defined in REPO_GROUPS, which cannot be
+	// changed through the API, and "api" otherwise.
+	Source string `json:"source"`
 }
 
 type Repository struct {
@@ -66,6 +222,21 @@ type MetricsUpdateEvent struct {
 	Timestamp   string `json:"timestamp"`
 }
 
+// Warning flags a degraded subsystem whose data may be delayed. It is
+// included in API responses and sent to dashboards while it lasts.
+type Warning struct {
+	Code    string    `json:"code"` // e.g. "processing_lag"
+	Message string    `json:"message"`
+	Since   time.Time `json:"since"`
+}
+
+// ConfigChangedEvent tells dashboards that reference data changed, so they
+// refetch it. Kind is "repo_groups", "mutes", "runner_hosts" or "settings".
+type ConfigChangedEvent struct {
+	Kind      string `json:"kind"`
+	Timestamp string `json:"timestamp"`
+}
+
 type WorkflowUpdateEvent struct {
 	Type        string      `json:"type"` // "run" or "job"
 	Action      string      `json:"action"`
@@ -91,6 +262,22 @@ type OrderedEvent struct {
 	ProcessedAt    *time.Time    `json:"processed_at,omitempty"`
 	OrderingKey    string        `json:"ordering_key"`
 	StatusPriority int           `json:"status_priority"`
+	// Terminal is set for completed and cancelled events, which may skip the
+	// ordering buffer; it is not stored
+	Terminal bool `json:"-"`
+}
+
+// EventSample is a stored webhook event as included in support bundles. The
+// payload is only kept for events that have not been processed.
+type EventSample struct {
+	EventType       string          `json:"event_type"`
+	Status          string          `json:"status"`
+	GitHubTimestamp time.Time       `json:"github_timestamp"`
+	ReceivedAt      time.Time       `json:"received_at"`
+	ProcessedAt     *time.Time      `json:"processed_at,omitempty"`
+	OrderingKey     string          `json:"ordering_key"`
+	StatusPriority  int             `json:"status_priority"`
+	Payload         json.RawMessage `json:"payload,omitempty"`
 }
 
 type EventBuffer struct {
@@ -133,6 +320,16 @@ type MetricsSnapshot struct {
 	Queued    int   `json:"queued"`
 }
 
+// Sparklines holds fixed-size series for compact trend charts. Index 0 is the
+// bucket starting at Start; each bucket spans BucketSeconds.
+type Sparklines struct {
+	Start           int64     `json:"start"`
+	BucketSeconds   int64     `json:"bucket_seconds"`
+	Running         []float64 `json:"running"`
+	Queued          []float64 `json:"queued"`
+	FailuresPerHour []float64 `json:"failures_per_hour"`
+}
+
 // FailingJob represents a job's failure statistics.
 type FailingJob struct {
 	Name        string  `json:"name"`
@@ -140,6 +337,8 @@ type FailingJob struct {
 	Failures    int     `json:"failures"`
 	Total       int     `json:"total"`
 	FailureRate float64 `json:"failure_rate"`
+	// MergedNames lists the other names the job was reported under
+	MergedNames []string `json:"merged_names,omitempty"`
 }
 
 // FailureAnalytics contains summary failure metrics.
@@ -147,6 +346,7 @@ type FailureAnalytics struct {
 	TotalCompleted  int          `json:"total_completed"`
 	TotalFailed     int          `json:"total_failed"`
 	TotalCancelled  int          `json:"total_cancelled"`
+	TotalMuted      int          `json:"total_muted"`
 	FailureRate     float64      `json:"failure_rate"`
 	TopFailingJobs  []FailingJob `json:"top_failing_jobs"`
 }
@@ -168,9 +368,688 @@ type LabelDemandSummary struct {
 	AvgQueueSeconds float64 `json:"avg_queue_seconds"`
 }
 
+// LabelQueueStats summarizes the queue times of the jobs started on a runner
+// label (the first label of each job) within a window.
+type LabelQueueStats struct {
+	Label           string  `json:"label"`
+	Jobs            int     `json:"jobs"`
+	P50QueueSeconds float64 `json:"p50_queue_seconds"`
+	P90QueueSeconds float64 `json:"p90_queue_seconds"`
+	MaxQueueSeconds float64 `json:"max_queue_seconds"`
+}
+
+// QueueLeaderboardEntry ranks a label by p90 queue time and compares it with
+// the previous period. Previous fields are nil when no job started on the
+// label in the previous period.
+type QueueLeaderboardEntry struct {
+	LabelQueueStats
+	Rank                    int      `json:"rank"`
+	PreviousRank            *int     `json:"previous_rank"`
+	PreviousJobs            int      `json:"previous_jobs"`
+	PreviousP90QueueSeconds *float64 `json:"previous_p90_queue_seconds"`
+	P90DeltaSeconds         *float64 `json:"p90_delta_seconds"`
+}
+
 // LabelDemandTrendPoint represents job volume for a single label at a point in time.
 type LabelDemandTrendPoint struct {
 	Timestamp int64  `json:"timestamp"`
 	Label     string `json:"label"`
 	Count     int    `json:"count"`
 }
+
+// RunDuration is the wall-clock duration of a successful workflow run.
+type RunDuration struct {
+	RunID        int64     `json:"run_id"`
+	Name         string    `json:"name"`
+	Repository   string    `json:"repository"`
+	HtmlUrl      string    `json:"html_url"`
+	DisplayTitle string    `json:"display_title"`
+	CompletedAt  time.Time `json:"completed_at"`
+	Seconds      float64   `json:"seconds"`
+}
+
+// DurationRegression flags a run that took significantly longer than the
+// trailing median of its workflow.
+type DurationRegression struct {
+	RunID           int64     `json:"run_id"`
+	Name            string    `json:"name"`
+	Repository      string    `json:"repository"`
+	HtmlUrl         string    `json:"html_url"`
+	DisplayTitle    string    `json:"display_title"`
+	CompletedAt     time.Time `json:"completed_at"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	BaselineSeconds float64   `json:"baseline_seconds"`
+	IncreasePercent float64   `json:"increase_percent"`
+	Samples         int       `json:"samples"`
+}
+
+// UnschedulableJob is a queued job requesting runner labels that no job has
+// ever run on, most likely because of a typo in runs-on.
+type UnschedulableJob struct {
+	ID            int64     `json:"id"`
+	Name          string    `json:"name"`
+	RunID         int64     `json:"run_id"`
+	HtmlUrl       string    `json:"html_url"`
+	Labels        []string  `json:"labels"`
+	UnknownLabels []string  `json:"unknown_labels"`
+	CreatedAt     time.Time `json:"created_at"`
+	Repository    string    `json:"repository"`
+	RunHtmlUrl    string    `json:"run_html_url"`
+}
+
+// RunnerGroupStats summarizes the jobs a runner group picked up over a
+// period. BusyMinutes counts the job minutes run within the period, and
+// AvgBusyRunners divides them by its length, giving the average number of the
+// group's runners in use. Queue times are those of the jobs that started
+// within the period.
+type RunnerGroupStats struct {
+	ID              int64   `json:"id"`
+	Name            string  `json:"name"`
+	Running         int     `json:"running"`
+	Started         int     `json:"started"`
+	BusyMinutes     float64 `json:"busy_minutes"`
+	AvgBusyRunners  float64 `json:"avg_busy_runners"`
+	AvgQueueSeconds float64 `json:"avg_queue_seconds"`
+	MaxQueueSeconds float64 `json:"max_queue_seconds"`
+}
+
+// JobTiming is when a job was queued and started and how long it ran, used
+// to replay a historical window.
+type JobTiming struct {
+	QueuedAt   time.Time
+	StartedAt  time.Time
+	RunSeconds float64
+}
+
+// QueueProjection summarizes the queue times of the jobs in a window, either
+// as observed or as projected for a given number of runners.
+type QueueProjection struct {
+	Runners         int     `json:"runners"`
+	Jobs            int     `json:"jobs"`
+	Waited          int     `json:"waited"` // jobs queued for more than a second
+	AvgQueueSeconds float64 `json:"avg_queue_seconds"`
+	P50QueueSeconds float64 `json:"p50_queue_seconds"`
+	P95QueueSeconds float64 `json:"p95_queue_seconds"`
+	MaxQueueSeconds float64 `json:"max_queue_seconds"`
+}
+
+// RunnerPoolStatus is the queue state of the self-hosted runners serving a
+// label set. LastStartedAt is zero when no job has started on it yet.
+type RunnerPoolStatus struct {
+	Labels         []string  `json:"labels"`
+	Queued         int       `json:"queued"`
+	Running        int       `json:"running"`
+	OldestQueuedAt time.Time `json:"oldest_queued_at"`
+	LastStartedAt  time.Time `json:"last_started_at"`
+}
+
+// Outcomes of a canary probe.
+const (
+	CanaryOutcomeSuccess = "success" // the run completed successfully within CANARY_SLOW_SECONDS
+	CanaryOutcomeSlow    = "slow"    // the run completed successfully but too slowly
+	CanaryOutcomeFailure = "failure" // the run completed with another conclusion
+	CanaryOutcomeTimeout = "timeout" // the run did not complete within CANARY_TIMEOUT_MINUTES
+	CanaryOutcomeError   = "error"   // the workflow could not be dispatched or followed
+)
+
+// CanaryResult is one dispatch of the synthetic canary workflow. Duration is
+// measured from dispatch to the run's completion.
+type CanaryResult struct {
+	ID              int64     `json:"id"`
+	DispatchedAt    time.Time `json:"dispatched_at"`
+	Outcome         string    `json:"outcome"`
+	RunID           int64     `json:"run_id,omitempty"`
+	HtmlUrl         string    `json:"html_url,omitempty"`
+	Conclusion      string    `json:"conclusion,omitempty"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	Error           string    `json:"error,omitempty"`
+}
+
+// Alert is a notification sent to dashboard clients and the alert webhook.
+type Alert struct {
+	Type       string      `json:"type"` // e.g. "duration_regression"
+	Title      string      `json:"title"`
+	Message    string      `json:"message"`
+	Repository string      `json:"repository,omitempty"`
+	RunID      int64       `json:"run_id,omitempty"`
+	JobID      int64       `json:"job_id,omitempty"`
+	HtmlUrl    string      `json:"html_url,omitempty"`
+	Data       interface{} `json:"data,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+// NotableRun is a workflow run surfaced in the activity feed, tagged with why
+// it is notable ("failure" or "long_running").
+type NotableRun struct {
+	Kind string      `json:"kind"`
+	Run  WorkflowRun `json:"workflow_run"`
+}
+
+// SLOBreach is a breached SLO surfaced in the activity feed: a workflow SLO
+// burning its error budget ("slo_burn_rate") or webhook processing falling
+// behind ("processing_lag_slo"), as of when its alert was raised.
+type SLOBreach struct {
+	ID         int64     `json:"id"`
+	Kind       string    `json:"kind"`
+	Title      string    `json:"title"`
+	Message    string    `json:"message"`
+	Repository string    `json:"repository,omitempty"`
+	Workflow   string    `json:"workflow,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+}
+
+// InstanceSummary is the headline state of one live-actions instance, shared
+// with federated peers. Failure figures cover the last 24 hours.
+type InstanceSummary struct {
+	Name           string  `json:"name"`
+	URL            string  `json:"url,omitempty"` // dashboard to drill down into; empty for the local instance
+	RunningJobs    int     `json:"running_jobs"`
+	QueuedJobs     int     `json:"queued_jobs"`
+	TotalCompleted int     `json:"total_completed"`
+	TotalFailed    int     `json:"total_failed"`
+	FailureRate    float64 `json:"failure_rate"`
+	Error          string  `json:"error,omitempty"` // set when the peer could not be reached
+}
+
+// EventStatusSequence is the set of statuses delivered by webhook for one
+// workflow job or run, identified by its ordering key.
+type EventStatusSequence struct {
+	EventType      string
+	OrderingKey    string
+	Priorities     []int // distinct status priorities received, ascending
+	NotStarted     bool  // the entity was cancelled or skipped before it started
+	LastReceivedAt time.Time
+}
+
+// SequenceGap is a job or run whose webhook deliveries skipped a status that
+// GitHub always sends before the latest one received.
+type SequenceGap struct {
+	EventType      string    `json:"event_type"`
+	OrderingKey    string    `json:"ordering_key"`
+	Received       []string  `json:"received"`
+	Missing        []string  `json:"missing"`
+	LastReceivedAt time.Time `json:"last_received_at"`
+}
+
+// DataQualityReport summarizes delivery gaps over a period. MissingByStatus
+// counts gaps per "<event_type>:<status>" so a status GitHub stopped
+// delivering stands out.
+type DataQualityReport struct {
+	Period           string         `json:"period"`
+	EntitiesChecked  int            `json:"entities_checked"`
+	EntitiesWithGaps int            `json:"entities_with_gaps"`
+	GapRate          float64        `json:"gap_rate"`
+	MissingByStatus  map[string]int `json:"missing_by_status"`
+	Gaps             []SequenceGap  `json:"gaps"`
+}
+
+// HostMetricSample is one value of a runner host metric pushed via remote
+// write. Labels holds the series' other labels as sorted name="value" pairs,
+// distinguishing e.g. filesystems on the same host.
+type HostMetricSample struct {
+	Host      string
+	Metric    string
+	Labels    string
+	Timestamp time.Time
+	Value     float64
+}
+
+// HostMetricPoint is one value of a host metric series.
+type HostMetricPoint struct {
+	Timestamp int64   `json:"timestamp"`
+	Value     float64 `json:"value"`
+}
+
+// HostMetricSeries is the values of one metric series on one runner host.
+type HostMetricSeries struct {
+	Host   string            `json:"host"`
+	Labels string            `json:"labels,omitempty"`
+	Points []HostMetricPoint `json:"points"`
+}
+
+// RunnerHost is a self-hosted runner machine and where it runs, matched to
+// jobs by runner name. LastSeenAt is nil for hosts that never sent a heartbeat.
+type RunnerHost struct {
+	Name         string     `json:"name"`
+	Labels       []string   `json:"labels"`
+	Zone         string     `json:"zone"`
+	InstanceType string     `json:"instance_type"`
+	LastSeenAt   *time.Time `json:"last_seen_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// RunnerHostStats is runner utilization over a window for the hosts sharing a
+// zone or instance type. Key is empty for jobs on unregistered runners or
+// hosts without the attribute. Utilization is the share of registered host
+// time spent running jobs, as a percentage.
+type RunnerHostStats struct {
+	Key             string  `json:"key"`
+	RegisteredHosts int     `json:"registered_hosts"`
+	ActiveHosts     int     `json:"active_hosts"`
+	Running         int     `json:"running"`
+	Started         int     `json:"started"`
+	BusyMinutes     float64 `json:"busy_minutes"`
+	AvgBusyRunners  float64 `json:"avg_busy_runners"`
+	Utilization     float64 `json:"utilization"`
+}
+
+// ActorStats summarizes the workflow runs of one GitHub user. Runs are those
+// attributed to them (actor); Triggered are those whose latest attempt they
+// started (triggering_actor), of which ReRuns were re-runs and ForOthers were
+// attributed to someone else.
+type ActorStats struct {
+	Actor     string `json:"actor"`
+	Runs      int    `json:"runs"`
+	Triggered int    `json:"triggered"`
+	ReRuns    int    `json:"re_runs"`
+	ForOthers int    `json:"for_others"`
+	Failed    int    `json:"failed"` // triggered runs that concluded failure
+}
+
+// DeploymentReviewEvent is a deployment_review webhook normalized to the jobs
+// an environment approval was requested or given for.
+type DeploymentReviewEvent struct {
+	Action string
+	RunID  int64
+	Jobs   []EnvironmentJob
+}
+
+// EnvironmentJob is a job deploying to an environment.
+type EnvironmentJob struct {
+	ID          int64
+	Environment string
+}
+
+// ApprovalStats summarizes how long jobs waited for an environment approval,
+// from entering waiting until being queued. Rejected counts jobs that finished
+// without being queued; Pending those still waiting. Environment is empty for
+// jobs no deployment_review event was received for.
+type ApprovalStats struct {
+	Environment string  `json:"environment"`
+	Approved    int     `json:"approved"`
+	Rejected    int     `json:"rejected"`
+	Pending     int     `json:"pending"`
+	AvgSeconds  float64 `json:"avg_seconds"`
+	P50Seconds  float64 `json:"p50_seconds"`
+	P90Seconds  float64 `json:"p90_seconds"`
+	MaxSeconds  float64 `json:"max_seconds"`
+}
+
+// LabelCapacitySample is the demand on a runner label and the number of
+// runners that could serve it at one point in time. Capacity is zero when
+// unknown.
+type LabelCapacitySample struct {
+	Label     string    `json:"label"`
+	Timestamp time.Time `json:"timestamp"`
+	Running   int       `json:"running"`
+	Queued    int       `json:"queued"`
+	Capacity  int       `json:"capacity"`
+}
+
+// SaturationPoint is a runner label's demand against its capacity at one
+// point in time. Saturation is running plus queued jobs as a percentage of
+// capacity, so it exceeds 100 while jobs wait for runners; it is nil when the
+// capacity is unknown.
+type SaturationPoint struct {
+	Timestamp  int64    `json:"timestamp"`
+	Running    int      `json:"running"`
+	Queued     int      `json:"queued"`
+	Capacity   int      `json:"capacity"`
+	Saturation *float64 `json:"saturation"`
+}
+
+// LabelSaturation summarizes a runner label's saturation over a window.
+// MinutesAboveThreshold is how long saturation stayed at or above the
+// requested threshold; the statistics only cover samples with a known
+// capacity and are nil when there are none.
+type LabelSaturation struct {
+	Label                 string            `json:"label"`
+	Capacity              int               `json:"capacity"`
+	AvgSaturation         *float64          `json:"avg_saturation"`
+	PeakSaturation        *float64          `json:"peak_saturation"`
+	MinutesAboveThreshold float64           `json:"minutes_above_threshold"`
+	Points                []SaturationPoint `json:"points"`
+}
+
+// SnapshotCompaction counts the metrics snapshots a compaction rolled up and
+// deleted, and the buckets they were rolled up into, or would be on a dry run.
+type SnapshotCompaction struct {
+	Snapshots int64 `json:"snapshots"`
+	Buckets   int64 `json:"buckets"`
+}
+
+// WorkflowSLO is a service level objective for the runs of one workflow,
+// evaluated over a rolling window of WindowDays. A zero SuccessTarget or
+// DurationTargetSeconds leaves that objective out.
+type WorkflowSLO struct {
+	Name       string `json:"name"`
+	Repository string `json:"repository"`
+	Workflow   string `json:"workflow"`
+	// SuccessTarget is the percentage of runs that should succeed
+	SuccessTarget float64 `json:"success_target,omitempty"`
+	// DurationPercentile percent of successful runs should finish within
+	// DurationTargetSeconds
+	DurationPercentile    int `json:"duration_percentile,omitempty"`
+	DurationTargetSeconds int `json:"duration_target_seconds,omitempty"`
+	WindowDays            int `json:"window_days"`
+	// Source is "config" for SLOs defined in WORKFLOW_SLOS, which cannot be
+	// changed through the API, and "api" otherwise.
+	Source string `json:"source"`
+}
+
+// SLORun is a completed workflow run counted towards an SLO. Seconds is
+// negative when the run's duration is unknown.
+type SLORun struct {
+	Conclusion  string
+	CompletedAt time.Time
+	Seconds     float64
+}
+
+// SLOObjective reports compliance with one objective of a workflow SLO.
+// ErrorBudgetRemaining is the share of the window's error budget left, and
+// drops below zero once the budget is spent. A burn rate of 1 spends the
+// budget exactly over the window.
+type SLOObjective struct {
+	Objective            string   `json:"objective"` // "success" or "duration"
+	Target               float64  `json:"target"`    // percentage of runs that should be good
+	Total                int      `json:"total"`
+	Good                 int      `json:"good"`
+	Compliance           *float64 `json:"compliance"` // nil without runs in the window
+	Met                  bool     `json:"met"`
+	ErrorBudgetRemaining float64  `json:"error_budget_remaining"`
+	BurnRate1h           float64  `json:"burn_rate_1h"`
+	BurnRate6h           float64  `json:"burn_rate_6h"`
+	// ObservedSeconds is the duration percentile of the window's runs, for
+	// duration objectives
+	ObservedSeconds *float64 `json:"observed_seconds,omitempty"`
+}
+
+// SLOStatus is a workflow SLO with its compliance over its window.
+type SLOStatus struct {
+	SLO        WorkflowSLO    `json:"slo"`
+	Objectives []SLOObjective `json:"objectives"`
+}
+
+// JobQueueWait is how long a started job waited in the queue.
+type JobQueueWait struct {
+	JobID     int64
+	Labels    []string
+	QueuedAt  time.Time
+	StartedAt time.Time
+}
+
+// QueueAttribution splits the time jobs spent queued between waiting on
+// GitHub to hand them to a runner and waiting for self-hosted runner capacity.
+// Capacity time is when every runner carrying one of the job's labels was
+// busy; GitHub time is queue time of GitHub-hosted jobs and of self-hosted
+// jobs while a runner was free. Unattributed time is self-hosted queue time
+// without a capacity sample to judge it by.
+type QueueAttribution struct {
+	Label               string  `json:"label,omitempty"`
+	RunnerType          string  `json:"runner_type,omitempty"` // "github-hosted" or "self-hosted"
+	Jobs                int     `json:"jobs"`
+	QueueSeconds        float64 `json:"queue_seconds"`
+	GitHubSeconds       float64 `json:"github_seconds"`
+	CapacitySeconds     float64 `json:"capacity_seconds"`
+	UnattributedSeconds float64 `json:"unattributed_seconds"`
+}
+
+// EventKeyCount is how many webhook events an ordering key received in one
+// time bucket. Repository is empty when it is unknown.
+type EventKeyCount struct {
+	BucketStart time.Time
+	OrderingKey string
+	Repository  string
+	Events      int
+}
+
+// EventDistribution reports how webhook events spread over ordering keys and
+// repositories in time, to judge how evenly they would partition.
+type EventDistribution struct {
+	Period          string                    `json:"period"`
+	BucketSeconds   int                       `json:"bucket_seconds"`
+	Events          int                       `json:"events"`
+	Keys            int                       `json:"keys"`
+	Repositories    int                       `json:"repositories"`
+	Buckets         []EventDistributionBucket `json:"buckets"`
+	HotKeys         []OrderingKeyLoad         `json:"hot_keys"`
+	TopRepositories []RepositoryEventLoad     `json:"top_repositories"`
+	Partitioning    []PartitionBalance        `json:"partitioning"`
+}
+
+// EventDistributionBucket summarizes the events received in one time bucket
+// and the key that received the most of them.
+type EventDistributionBucket struct {
+	Start            time.Time `json:"start"`
+	Events           int       `json:"events"`
+	Keys             int       `json:"keys"`
+	HottestKey       string    `json:"hottest_key"`
+	HottestKeyEvents int       `json:"hottest_key_events"`
+}
+
+// OrderingKeyLoad is the share of events one ordering key received and its
+// busiest bucket.
+type OrderingKeyLoad struct {
+	Key              string  `json:"key"`
+	Repository       string  `json:"repository"`
+	Events           int     `json:"events"`
+	Share            float64 `json:"share"` // percentage of all events
+	PeakBucketEvents int     `json:"peak_bucket_events"`
+}
+
+// RepositoryEventLoad is the share of events one repository's keys received,
+// with its events per bucket in the order of EventDistribution.Buckets.
+type RepositoryEventLoad struct {
+	Repository string  `json:"repository"`
+	Events     int     `json:"events"`
+	Share      float64 `json:"share"` // percentage of all events
+	Keys       int     `json:"keys"`
+	Series     []int   `json:"series"`
+}
+
+// PartitionBalance is how evenly events would have spread over a number of
+// partitions when hashed by ordering key or by repository. Imbalance is the
+// busiest partition's events over the mean, so 1 is perfectly even.
+type PartitionBalance struct {
+	Strategy   string  `json:"strategy"` // "ordering_key" or "repository"
+	Partitions int     `json:"partitions"`
+	MaxShare   float64 `json:"max_share"` // percentage of events on the busiest partition
+	Imbalance  float64 `json:"imbalance"`
+}
+
+// ServerRestartingEvent tells dashboards the server is shutting down and how
+// long it expects to be unavailable.
+type ServerRestartingEvent struct {
+	ExpectedDowntimeSeconds int    `json:"expected_downtime_seconds"`
+	Timestamp               string `json:"timestamp"`
+}
+
+// WorkflowFile is a workflow file runs were recently started from.
+type WorkflowFile struct {
+	Repository string // as stored on runs
+	FullName   string // owner/name, for the GitHub API
+	Path       string
+}
+
+// WorkflowFileChange is a commit that changed a workflow file.
+type WorkflowFileChange struct {
+	Repository  string    `json:"repository"`
+	Path        string    `json:"path"`
+	SHA         string    `json:"sha"`
+	Message     string    `json:"message"`
+	Author      string    `json:"author"`
+	HtmlUrl     string    `json:"html_url"`
+	CommittedAt time.Time `json:"committed_at"`
+}
+
+// RunOutcome is how a completed run of a workflow file concluded.
+type RunOutcome struct {
+	RunID        int64
+	Repository   string
+	Name         string
+	WorkflowPath string
+	HtmlUrl      string
+	Conclusion   string
+	CreatedAt    time.Time
+}
+
+// WorkflowChangeCorrelation flags a workflow file change after which the
+// workflow's failure rate rose, comparing the runs since the previous change
+// of the file with those until the next one.
+type WorkflowChangeCorrelation struct {
+	Repository        string             `json:"repository"`
+	Workflow          string             `json:"workflow"`
+	Path              string             `json:"path"`
+	Change            WorkflowFileChange `json:"change"`
+	RunsBefore        int                `json:"runs_before"`
+	RunsAfter         int                `json:"runs_after"`
+	FailureRateBefore float64            `json:"failure_rate_before"` // percentage
+	FailureRateAfter  float64            `json:"failure_rate_after"`  // percentage
+	// FirstFailedRunID and FirstFailedRunUrl point at the first failed run
+	// after the change
+	FirstFailedRunID  int64  `json:"first_failed_run_id"`
+	FirstFailedRunUrl string `json:"first_failed_run_url"`
+}
+
+// LabelThroughput counts the jobs of a runner label that started and completed
+// within a window, and their rates per minute over it.
+type LabelThroughput struct {
+	Label              string  `json:"label"`
+	Started            int     `json:"started"`
+	Completed          int     `json:"completed"`
+	StartedPerMinute   float64 `json:"started_per_minute"`
+	CompletedPerMinute float64 `json:"completed_per_minute"`
+}
+
+// SystemTopology describes how an instance is set up, so support can read a
+// reported installation at a glance. It holds no secrets: credentials are
+// only reported as configured or not.
+type SystemTopology struct {
+	Version      string               `json:"version"`
+	Environment  string               `json:"environment"`
+	EventBackend EventBackendTopology `json:"event_backend"`
+	Database     DatabaseTopology     `json:"database"`
+	Auth         AuthTopology         `json:"auth"`
+	Integrations []IntegrationStatus  `json:"integrations"`
+	Retention    RetentionTopology    `json:"retention"`
+}
+
+// EventBackendTopology describes how webhook events are queued, applied and
+// streamed to dashboards.
+type EventBackendTopology struct {
+	Type                    string `json:"type"` // "database": events are queued in the database and applied in order
+	DedupeWindowSeconds     int    `json:"dedupe_window_seconds"`
+	JobSuccessSamplePercent int    `json:"job_success_sample_percent"`
+	SSEMaxClients           int    `json:"sse_max_clients"`
+	SSEOverflowMode         string `json:"sse_overflow_mode"`
+}
+
+// DatabaseTopology describes the database backend.
+type DatabaseTopology struct {
+	Driver              string `json:"driver"` // "sqlite" or "sqlcipher"
+	Path                string `json:"path"`
+	Encrypted           bool   `json:"encrypted"`
+	EncryptionSupported bool   `json:"encryption_supported"` // whether this build can open encrypted databases
+	WaitForMigrations   bool   `json:"wait_for_migrations"`
+}
+
+// AuthTopology reports which endpoints are protected by which mechanism.
+type AuthTopology struct {
+	WebhookSignature  bool     `json:"webhook_signature"`
+	AdminAPI          bool     `json:"admin_api"`
+	FederationServing bool     `json:"federation_serving"`
+	RemoteWrite       bool     `json:"remote_write"`
+	RunnerHeartbeats  bool     `json:"runner_heartbeats"`
+	TLS               bool     `json:"tls"`
+	TrustedProxies    []string `json:"trusted_proxies"`
+}
+
+// IntegrationStatus reports whether an optional integration is enabled, with
+// a non-secret detail such as the repository or directory it uses.
+type IntegrationStatus struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	Detail  string `json:"detail,omitempty"`
+}
+
+// RetentionTopology describes how long data is kept.
+type RetentionTopology struct {
+	DataRetentionDays      int `json:"data_retention_days"`
+	CleanupIntervalHours   int `json:"cleanup_interval_hours"`
+	StaleJobThresholdHours int `json:"stale_job_threshold_hours"`
+}
+
+// Decisions on a proposed job name merge.
+const (
+	JobNameMergeConfirmed = "confirmed"
+	JobNameMergeRejected  = "rejected"
+)
+
+// JobNameMerge records a reviewed decision to merge a job name into another
+// in analytics, or to keep them apart.
+type JobNameMerge struct {
+	Alias     string    `json:"alias"`
+	Canonical string    `json:"canonical"`
+	Status    string    `json:"status"`
+	DecidedAt time.Time `json:"decided_at"`
+}
+
+// JobNameCount is the number of completed jobs carrying a name.
+type JobNameCount struct {
+	Name string `json:"name"`
+	Jobs int    `json:"jobs"`
+}
+
+// JobNameGroup lists the names analytics currently report as one job,
+// through the normalization rules or confirmed merges.
+type JobNameGroup struct {
+	Name  string   `json:"name"`
+	Names []string `json:"names"`
+	Jobs  int      `json:"jobs"`
+}
+
+// JobNameMergeSuggestion proposes merging a job name into a similar one with
+// more jobs, pending review.
+type JobNameMergeSuggestion struct {
+	Alias         string  `json:"alias"`
+	Canonical     string  `json:"canonical"`
+	Similarity    float64 `json:"similarity"` // percentage
+	AliasJobs     int     `json:"alias_jobs"`
+	CanonicalJobs int     `json:"canonical_jobs"`
+}
+
+// JobNameMergeReview shows how job names are merged in analytics and which
+// fuzzy matches await a decision.
+type JobNameMergeReview struct {
+	Rules        []string                 `json:"rules"`
+	MatchPercent int                      `json:"match_percent"`
+	Groups       []JobNameGroup           `json:"groups"`
+	Decisions    []JobNameMerge           `json:"decisions"`
+	Suggestions  []JobNameMergeSuggestion `json:"suggestions"`
+}
+
+// Outcomes of a configuration check.
+const (
+	ConfigCheckOK      = "ok"
+	ConfigCheckWarning = "warning"
+	ConfigCheckError   = "error"
+	ConfigCheckSkipped = "skipped"
+)
+
+// ConfigCheck is the outcome of validating one part of the configuration.
+type ConfigCheck struct {
+	Category string `json:"category"` // config, secrets, urls, labels or database
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Message  string `json:"message"`
+}
+
+// ConfigValidationReport lists the configuration checks; the configuration is
+// valid when none of them failed.
+type ConfigValidationReport struct {
+	Valid     bool          `json:"valid"`
+	Errors    int           `json:"errors"`
+	Warnings  int           `json:"warnings"`
+	CheckedAt time.Time     `json:"checked_at"`
+	Checks    []ConfigCheck `json:"checks"`
+}
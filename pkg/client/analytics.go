@@ -0,0 +1,268 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gateixeira/live-actions/models"
+)
+
+// AnalyticsFilter narrows an analytics report. Period is "hour", "day",
+// "week" or "month"; empty uses the report's default. Zero values are not
+// applied; Repo and Group cannot be combined.
+type AnalyticsFilter struct {
+	Period string
+	Repo   string
+	Group  string
+}
+
+func (f AnalyticsFilter) query() url.Values {
+	query := url.Values{}
+	setIfNotEmpty(query, "period", f.Period)
+	setIfNotEmpty(query, "repo", f.Repo)
+	setIfNotEmpty(query, "group", f.Group)
+	return query
+}
+
+// FailureReport is the failure analytics of a period.
+type FailureReport struct {
+	Summary models.FailureAnalytics    `json:"summary"`
+	Trend   []models.FailureTrendPoint `json:"trend"`
+}
+
+// GetFailureAnalytics returns job failure totals, top failing jobs and the
+// failure trend over the period (default: day).
+func (c *Client) GetFailureAnalytics(ctx context.Context, filter AnalyticsFilter) (*FailureReport, error) {
+	var report FailureReport
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/analytics/failures", filter.query(), nil, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// LabelDemandReport is the demand per runner label over a period.
+type LabelDemandReport struct {
+	Summary []models.LabelDemandSummary    `json:"summary"`
+	Trend   []models.LabelDemandTrendPoint `json:"trend"`
+}
+
+// GetLabelDemand returns the demand per runner label over the period
+// (default: day), with its daily trend.
+func (c *Client) GetLabelDemand(ctx context.Context, filter AnalyticsFilter) (*LabelDemandReport, error) {
+	var report LabelDemandReport
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/analytics/labels", filter.query(), nil, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// RegressionReport lists the workflow runs that got slower and the workflow
+// file changes that shifted failure rates.
+type RegressionReport struct {
+	Regressions            []models.DurationRegression        `json:"regressions"`
+	ThresholdPercent       int                                `json:"threshold_percent"`
+	WorkflowChanges        []models.WorkflowChangeCorrelation `json:"workflow_changes"`
+	WorkflowChangesEnabled bool                               `json:"workflow_changes_enabled"`
+	ChangeShiftPercent     int                                `json:"change_shift_percent"`
+}
+
+// GetDurationRegressions returns the duration regressions and workflow change
+// correlations of the period (default: week).
+func (c *Client) GetDurationRegressions(ctx context.Context, filter AnalyticsFilter) (*RegressionReport, error) {
+	var report RegressionReport
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/analytics/regressions", filter.query(), nil, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// CapacitySimulation is the observed queue of a label next to the queues
+// projected for other runner counts.
+type CapacitySimulation struct {
+	Label       string                   `json:"label"`
+	Period      string                   `json:"period"`
+	Observed    models.QueueProjection   `json:"observed"`
+	Projections []models.QueueProjection `json:"projections"`
+}
+
+// SimulateCapacity replays the jobs of label over period (default: week)
+// against each of 1 to 5 runner counts.
+func (c *Client) SimulateCapacity(ctx context.Context, label, period string, runners []int) (*CapacitySimulation, error) {
+	counts := make([]string, len(runners))
+	for i, n := range runners {
+		counts[i] = strconv.Itoa(n)
+	}
+	query := url.Values{"label": {label}, "runners": {strings.Join(counts, ",")}}
+	setIfNotEmpty(query, "period", period)
+
+	var simulation CapacitySimulation
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/analytics/capacity", query, nil, &simulation); err != nil {
+		return nil, err
+	}
+	return &simulation, nil
+}
+
+// SaturationReport is the runner saturation per label over a period.
+type SaturationReport struct {
+	Period    string                   `json:"period"`
+	Threshold int                      `json:"threshold"`
+	Labels    []models.LabelSaturation `json:"labels"`
+}
+
+// GetSaturation returns the saturation of label, or of every label when it is
+// empty, over period (default: day). Time at or above threshold percent,
+// default 90 when zero, is reported per label.
+func (c *Client) GetSaturation(ctx context.Context, label, period string, threshold int) (*SaturationReport, error) {
+	query := url.Values{}
+	setIfNotEmpty(query, "label", label)
+	setIfNotEmpty(query, "period", period)
+	if threshold > 0 {
+		query.Set("threshold", strconv.Itoa(threshold))
+	}
+
+	var report SaturationReport
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/analytics/saturation", query, nil, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// QueueLeaderboard ranks runner labels by queue time.
+type QueueLeaderboard struct {
+	Period string                         `json:"period"`
+	Labels []models.QueueLeaderboardEntry `json:"labels"`
+}
+
+// GetQueueLeaderboard ranks runner labels by p90 queue time over the period
+// (default: week), compared with the previous period.
+func (c *Client) GetQueueLeaderboard(ctx context.Context, filter AnalyticsFilter) (*QueueLeaderboard, error) {
+	var leaderboard QueueLeaderboard
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/analytics/queue-leaderboard", filter.query(), nil, &leaderboard); err != nil {
+		return nil, err
+	}
+	return &leaderboard, nil
+}
+
+// QueueAttributionReport splits queue time between GitHub and runner capacity.
+type QueueAttributionReport struct {
+	Period string                    `json:"period"`
+	Total  models.QueueAttribution   `json:"total"`
+	Labels []models.QueueAttribution `json:"labels"`
+}
+
+// GetQueueAttribution splits the queue time of the jobs queued over the period
+// (default: day) between GitHub and self-hosted runner capacity.
+func (c *Client) GetQueueAttribution(ctx context.Context, filter AnalyticsFilter) (*QueueAttributionReport, error) {
+	var report QueueAttributionReport
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/analytics/queue-attribution", filter.query(), nil, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// ThroughputReport is the rate of jobs started and completed per label.
+type ThroughputReport struct {
+	WindowMinutes int                      `json:"window_minutes"`
+	Total         models.LabelThroughput   `json:"total"`
+	Labels        []models.LabelThroughput `json:"labels"`
+}
+
+// GetThroughput returns the jobs started and completed per minute over the
+// last windowMinutes, or the server's throughput window when zero. The
+// filter's Period is not used.
+func (c *Client) GetThroughput(ctx context.Context, windowMinutes int, filter AnalyticsFilter) (*ThroughputReport, error) {
+	query := filter.query()
+	query.Del("period")
+	if windowMinutes > 0 {
+		query.Set("window", strconv.Itoa(windowMinutes))
+	}
+
+	var report ThroughputReport
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/analytics/throughput", query, nil, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// UnschedulableReport lists queued jobs that no runner seems able to pick up.
+type UnschedulableReport struct {
+	Jobs             []models.UnschedulableJob `json:"jobs"`
+	QueuedForMinutes int                       `json:"queued_for_minutes"`
+}
+
+// ListUnschedulableJobs returns the jobs queued for over QueuedForMinutes on
+// runner labels no job has ever run on, usually a typo in runs-on. The
+// filter's Period is not used.
+func (c *Client) ListUnschedulableJobs(ctx context.Context, filter AnalyticsFilter) (*UnschedulableReport, error) {
+	query := filter.query()
+	query.Del("period")
+
+	var report UnschedulableReport
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/analytics/unschedulable", query, nil, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// RunnerHostReport is the utilization of runner hosts per zone or instance
+// type.
+type RunnerHostReport struct {
+	Period string                   `json:"period"`
+	By     string                   `json:"by"`
+	Slices []models.RunnerHostStats `json:"slices"`
+}
+
+// GetRunnerHostAnalytics returns the utilization of the registered runner
+// hosts over period (default: day), grouped by "zone" (the default when by is
+// empty) or "instance_type".
+func (c *Client) GetRunnerHostAnalytics(ctx context.Context, period, by string) (*RunnerHostReport, error) {
+	query := url.Values{}
+	setIfNotEmpty(query, "period", period)
+	setIfNotEmpty(query, "by", by)
+
+	var report RunnerHostReport
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/analytics/runner-hosts", query, nil, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// ActorReport is the runs started per user.
+type ActorReport struct {
+	Period string              `json:"period"`
+	Actors []models.ActorStats `json:"actors"`
+}
+
+// GetActorAnalytics returns up to limit users, 50 when zero, behind the runs
+// started over the period (default: week), most triggered first.
+func (c *Client) GetActorAnalytics(ctx context.Context, filter AnalyticsFilter, limit int) (*ActorReport, error) {
+	query := filter.query()
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+
+	var report ActorReport
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/analytics/actors", query, nil, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// ApprovalReport is the approval latency per environment.
+type ApprovalReport struct {
+	Period       string                 `json:"period"`
+	Environments []models.ApprovalStats `json:"environments"`
+}
+
+// GetApprovalAnalytics returns how long jobs that started waiting over the
+// period (default: week) waited for approval, per environment.
+func (c *Client) GetApprovalAnalytics(ctx context.Context, filter AnalyticsFilter) (*ApprovalReport, error) {
+	var report ApprovalReport
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/analytics/approvals", filter.query(), nil, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
@@ -0,0 +1,463 @@
+// Package client is a Go client for the live-actions REST API and event
+// stream. It calls the versioned API under /api/v1 and unwraps its response
+// envelope. Responses are decoded into the types of the models package the
+// server itself uses, so tools built on it follow API changes at compile time.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gateixeira/live-actions/models"
+)
+
+// apiPrefix is the versioned API the client calls.
+const apiPrefix = "/api/v1"
+
+// CSRF cookie and header the dashboard API expects on every request.
+const (
+	csrfCookieName = "csrf_token"
+	csrfHeaderName = "X-CSRF-Token"
+	// csrfRejected is the error of requests whose CSRF token is no longer
+	// accepted, such as after its signing key was rotated.
+	csrfRejected = "Invalid CSRF token"
+)
+
+// Client calls a single live-actions instance. It is safe for concurrent use.
+type Client struct {
+	baseURL string
+	// HTTPClient sends the requests. It has no timeout by default, as event
+	// streams are long-lived; bound API calls through their context instead.
+	HTTPClient *http.Client
+	// AdminToken is sent as a bearer token to the admin API.
+	AdminToken string
+
+	mutex     sync.Mutex
+	csrfToken string
+}
+
+// New creates a client for the instance served at baseURL, such as
+// https://live-actions.example.com.
+func New(baseURL string) *Client {
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: &http.Client{},
+	}
+}
+
+// APIError is returned when the server answers with an error status.
+type APIError struct {
+	StatusCode int
+	// Message is the error reported by the server, or the status text when
+	// the response holds none.
+	Message string
+	// RequestID identifies the request in the server's logs, when reported.
+	RequestID string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("live-actions API responded with status %d: %s", e.StatusCode, e.Message)
+}
+
+// Pagination describes the page of a paginated listing.
+type Pagination struct {
+	CurrentPage int  `json:"current_page"`
+	TotalPages  int  `json:"total_pages"`
+	TotalCount  int  `json:"total_count"`
+	PageSize    int  `json:"page_size"`
+	HasNext     bool `json:"has_next"`
+	HasPrevious bool `json:"has_previous"`
+}
+
+// RunFilter narrows a workflow run listing. Zero values are not applied;
+// Repo and Group cannot be combined.
+type RunFilter struct {
+	Page   int
+	Limit  int
+	Repo   string
+	Group  string
+	Status string
+	SHA    string
+}
+
+// WorkflowRunPage is one page of workflow runs, newest first.
+type WorkflowRunPage struct {
+	WorkflowRuns []models.WorkflowRun `json:"workflow_runs"`
+	SavedFilters []models.SavedFilter `json:"saved_filters"`
+	Pagination   Pagination           `json:"pagination"`
+}
+
+// ListWorkflowRuns returns a page of workflow runs matching filter.
+func (c *Client) ListWorkflowRuns(ctx context.Context, filter RunFilter) (*WorkflowRunPage, error) {
+	query := url.Values{}
+	if filter.Page > 0 {
+		query.Set("page", strconv.Itoa(filter.Page))
+	}
+	if filter.Limit > 0 {
+		query.Set("limit", strconv.Itoa(filter.Limit))
+	}
+	setIfNotEmpty(query, "repo", filter.Repo)
+	setIfNotEmpty(query, "group", filter.Group)
+	setIfNotEmpty(query, "status", filter.Status)
+	setIfNotEmpty(query, "sha", filter.SHA)
+
+	var page WorkflowRunPage
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/workflow-runs", query, nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// GetWorkflowJobs returns the jobs of a workflow run. A run without jobs is
+// reported as an APIError with status 404.
+func (c *Client) GetWorkflowJobs(ctx context.Context, runID int64) ([]models.WorkflowJob, error) {
+	var resp struct {
+		WorkflowJobs []models.WorkflowJob `json:"workflow_jobs"`
+	}
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/workflow-jobs/"+strconv.FormatInt(runID, 10), nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.WorkflowJobs, nil
+}
+
+// GetWorkflowJobsBatch returns the jobs of up to 50 runs at once, keyed by run
+// ID. Every requested run is present, with no jobs when it has none.
+func (c *Client) GetWorkflowJobsBatch(ctx context.Context, runIDs []int64) (map[int64][]models.WorkflowJob, error) {
+	var resp struct {
+		WorkflowJobs map[int64][]models.WorkflowJob `json:"workflow_jobs"`
+	}
+	body := map[string][]int64{"run_ids": runIDs}
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/workflow-jobs/batch", nil, body, &resp); err != nil {
+		return nil, err
+	}
+	return resp.WorkflowJobs, nil
+}
+
+// ListRepositories returns the names of the repositories with recorded runs.
+func (c *Client) ListRepositories(ctx context.Context) ([]string, error) {
+	var resp struct {
+		Repositories []string `json:"repositories"`
+	}
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/repositories", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Repositories, nil
+}
+
+// AddRunTag tags a workflow run and returns all of its tags.
+func (c *Client) AddRunTag(ctx context.Context, runID int64, tag string) ([]string, error) {
+	return c.runTags(ctx, http.MethodPost, apiPrefix+"/workflow-runs/"+strconv.FormatInt(runID, 10)+"/tags", map[string]string{"tag": tag})
+}
+
+// RemoveRunTag removes a tag from a workflow run and returns its remaining tags.
+func (c *Client) RemoveRunTag(ctx context.Context, runID int64, tag string) ([]string, error) {
+	return c.runTags(ctx, http.MethodDelete, apiPrefix+"/workflow-runs/"+strconv.FormatInt(runID, 10)+"/tags/"+url.PathEscape(tag), nil)
+}
+
+func (c *Client) runTags(ctx context.Context, method, path string, body interface{}) ([]string, error) {
+	var resp struct {
+		Tags []string `json:"tags"`
+	}
+	if err := c.do(ctx, method, path, nil, body, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Tags, nil
+}
+
+// ListRepoGroups returns the repository groups, both configured and managed
+// through the API.
+func (c *Client) ListRepoGroups(ctx context.Context) ([]models.RepoGroup, error) {
+	var resp struct {
+		Groups []models.RepoGroup `json:"groups"`
+	}
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/repo-groups", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Groups, nil
+}
+
+// SaveRepoGroup creates or replaces a repository group and returns it as stored.
+func (c *Client) SaveRepoGroup(ctx context.Context, name string, repositories []string) (*models.RepoGroup, error) {
+	var group models.RepoGroup
+	body := map[string][]string{"repositories": repositories}
+	if err := c.do(ctx, http.MethodPut, apiPrefix+"/repo-groups/"+url.PathEscape(name), nil, body, &group); err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// DeleteRepoGroup removes a repository group managed through the API.
+func (c *Client) DeleteRepoGroup(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, apiPrefix+"/repo-groups/"+url.PathEscape(name), nil, nil, nil)
+}
+
+// ListSLOs returns every workflow SLO with its current compliance.
+func (c *Client) ListSLOs(ctx context.Context) ([]models.SLOStatus, error) {
+	var resp struct {
+		SLOs []models.SLOStatus `json:"slos"`
+	}
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/slo", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.SLOs, nil
+}
+
+// SaveSLO creates or replaces the workflow SLO named slo.Name and returns it
+// as stored.
+func (c *Client) SaveSLO(ctx context.Context, slo models.WorkflowSLO) (*models.WorkflowSLO, error) {
+	var saved models.WorkflowSLO
+	if err := c.do(ctx, http.MethodPut, apiPrefix+"/slo/"+url.PathEscape(slo.Name), nil, slo, &saved); err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
+
+// DeleteSLO removes a workflow SLO managed through the API.
+func (c *Client) DeleteSLO(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, apiPrefix+"/slo/"+url.PathEscape(name), nil, nil, nil)
+}
+
+// ListMutes returns the mutes that are currently active.
+func (c *Client) ListMutes(ctx context.Context) ([]models.Mute, error) {
+	var resp struct {
+		Mutes []models.Mute `json:"mutes"`
+	}
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/mutes", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Mutes, nil
+}
+
+// CreateMute silences a run or job, entityType "run" or "job", for duration,
+// such as "4h", and returns the mute.
+func (c *Client) CreateMute(ctx context.Context, entityType string, entityID int64, duration, reason string) (*models.Mute, error) {
+	body := map[string]interface{}{
+		"entity_type": entityType,
+		"entity_id":   entityID,
+		"duration":    duration,
+		"reason":      reason,
+	}
+	var mute models.Mute
+	if err := c.do(ctx, http.MethodPost, apiPrefix+"/mutes", nil, body, &mute); err != nil {
+		return nil, err
+	}
+	return &mute, nil
+}
+
+// DeleteMute lifts a mute before it expires.
+func (c *Client) DeleteMute(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, apiPrefix+"/mutes/"+strconv.FormatInt(id, 10), nil, nil, nil)
+}
+
+// GetEventDistribution returns the admin report of how webhook events over
+// period ("hour", "day", "week" or "month") spread over ordering keys and
+// repositories. It requires AdminToken.
+func (c *Client) GetEventDistribution(ctx context.Context, period string) (*models.EventDistribution, error) {
+	query := url.Values{}
+	setIfNotEmpty(query, "period", period)
+
+	var report models.EventDistribution
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/admin/events/distribution", query, nil, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// ValidateConfig runs the server's configuration checks: secrets, configured
+// URLs, runner label settings and database writes. A configuration with
+// failing checks is reported with Valid false rather than as an error. It
+// requires AdminToken.
+func (c *Client) ValidateConfig(ctx context.Context) (*models.ConfigValidationReport, error) {
+	var report models.ConfigValidationReport
+	if err := c.do(ctx, http.MethodGet, apiPrefix+"/admin/config/validate", nil, nil, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// RotateCSRFKey changes the key the server signs CSRF tokens with, so every
+// dashboard has to fetch a new token. It requires AdminToken.
+func (c *Client) RotateCSRFKey(ctx context.Context) error {
+	return c.do(ctx, http.MethodPost, apiPrefix+"/admin/security/rotate-csrf", nil, nil, nil)
+}
+
+func setIfNotEmpty(query url.Values, key, value string) {
+	if value != "" {
+		query.Set(key, value)
+	}
+}
+
+// do sends a JSON request to path and decodes the JSON response into out,
+// unless out is nil. A request whose CSRF token is rejected is retried once
+// with a new token.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	token, err := c.send(ctx, method, path, query, data, out)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusForbidden && apiErr.Message == csrfRejected {
+		c.mutex.Lock()
+		if c.csrfToken == token {
+			c.csrfToken = ""
+		}
+		c.mutex.Unlock()
+		_, err = c.send(ctx, method, path, query, data, out)
+	}
+	return err
+}
+
+// send makes a single attempt at a request for do, returning the CSRF token
+// it was sent with.
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, data []byte, out interface{}) (string, error) {
+	token, err := c.csrf(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var reader io.Reader
+	if data != nil {
+		reader = bytes.NewReader(data)
+	}
+	req, err := c.newRequest(ctx, method, path, query, reader)
+	if err != nil {
+		return token, err
+	}
+	if data != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set(csrfHeaderName, token)
+	req.AddCookie(&http.Cookie{Name: csrfCookieName, Value: token})
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return token, fmt.Errorf("failed to reach live-actions: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return token, responseError(resp)
+	}
+	if out == nil {
+		return token, nil
+	}
+	if err := decodeEnvelope(resp.Body, out); err != nil {
+		return token, err
+	}
+	return token, nil
+}
+
+// decodeEnvelope decodes the data of an /api/v1 response envelope into out.
+// The server lifts the pagination of listings out of their data, so it is
+// decoded back into out's pagination field, if it has one.
+func decodeEnvelope(body io.Reader, out interface{}) error {
+	var env struct {
+		Data       json.RawMessage `json:"data"`
+		Pagination json.RawMessage `json:"pagination"`
+	}
+	if err := json.NewDecoder(body).Decode(&env); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(env.Data) > 0 {
+		if err := json.Unmarshal(env.Data, out); err != nil {
+			return fmt.Errorf("failed to decode response data: %w", err)
+		}
+	}
+	if len(env.Pagination) > 0 {
+		wrapped := append(append([]byte(`{"pagination":`), env.Pagination...), '}')
+		if err := json.Unmarshal(wrapped, out); err != nil {
+			return fmt.Errorf("failed to decode pagination: %w", err)
+		}
+	}
+	return nil
+}
+
+// newRequest creates a request for path that passes the server's same-origin
+// checks, and carries the admin token when one is set.
+func (c *Client) newRequest(ctx context.Context, method, path string, query url.Values, body io.Reader) (*http.Request, error) {
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Referer", c.baseURL+"/")
+	req.Header.Set("Origin", c.baseURL)
+	if c.AdminToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.AdminToken)
+	}
+	return req, nil
+}
+
+// csrf returns the CSRF token sent with every API request, fetching one on
+// first use.
+func (c *Client) csrf(ctx context.Context) (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.csrfToken != "" {
+		return c.csrfToken, nil
+	}
+
+	req, err := c.newRequest(ctx, http.MethodGet, apiPrefix+"/csrf", nil, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to reach live-actions: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", responseError(resp)
+	}
+
+	var body struct {
+		Token string `json:"token"`
+	}
+	if err := decodeEnvelope(resp.Body, &body); err != nil {
+		return "", fmt.Errorf("failed to decode CSRF token: %w", err)
+	}
+	if body.Token == "" {
+		return "", errors.New("server returned an empty CSRF token")
+	}
+	c.csrfToken = body.Token
+	return c.csrfToken, nil
+}
+
+// responseError turns an error response into an APIError. Errors are
+// reported in the envelope's error field, usually as a message.
+func responseError(resp *http.Response) error {
+	apiErr := &APIError{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+	var body struct {
+		Error     json.RawMessage `json:"error"`
+		RequestID string          `json:"request_id"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&body); err != nil {
+		return apiErr
+	}
+	apiErr.RequestID = body.RequestID
+	var message string
+	if err := json.Unmarshal(body.Error, &message); err == nil && message != "" {
+		apiErr.Message = message
+	} else if len(body.Error) > 0 && string(body.Error) != "null" {
+		apiErr.Message = string(body.Error)
+	}
+	return apiErr
+}
@@ -0,0 +1,200 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gateixeira/live-actions/handlers"
+	"github.com/gateixeira/live-actions/internal/config"
+	"github.com/gateixeira/live-actions/internal/database"
+	"github.com/gateixeira/live-actions/internal/middleware"
+	"github.com/gateixeira/live-actions/models"
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// setupServer serves the real handlers under /api/v1, with their origin and
+// CSRF checks and the response envelope, backed by a mock database.
+func setupServer(t *testing.T) (*Client, *database.MockDatabase) {
+	logger.InitLogger("error")
+	gin.SetMode(gin.TestMode)
+
+	mockDB := &database.MockDatabase{}
+	cfg := &config.Config{Vars: config.Vars{AdminToken: "secret"}}
+	apiHandler := handlers.NewAPIHandler(cfg, mockDB)
+	adminHandler := handlers.NewAdminHandler(cfg, mockDB, nil, nil)
+
+	router := gin.New()
+	router.Use(middleware.RequestID())
+	router.GET("/events", handlers.ValidateSSEOrigin(), handlers.GetSSEHandler().HandleSSE())
+	api := router.Group(middleware.APIVersionPrefix, middleware.Envelope(func() []models.Warning { return nil }))
+	api.GET("/csrf", apiHandler.GetCSRFToken())
+	api.GET("/workflow-runs", handlers.ValidateOrigin(), apiHandler.GetWorkflowRuns())
+	api.GET("/repositories", handlers.ValidateOrigin(), apiHandler.GetRepositories())
+	api.GET("/analytics/queue-attribution", handlers.ValidateOrigin(), apiHandler.GetQueueAttribution())
+	api.POST("/workflow-runs/:run_id/tags", handlers.ValidateOrigin(), apiHandler.AddRunTag())
+	api.PUT("/slo/:name", handlers.ValidateOrigin(), apiHandler.SaveSLO())
+	api.GET("/admin/events/distribution", handlers.RequireAdminToken(cfg), adminHandler.GetEventDistribution())
+	api.POST("/admin/security/rotate-csrf", handlers.RequireAdminToken(cfg), adminHandler.RotateCSRFKey())
+	api.GET("/admin/config/validate", handlers.RequireAdminToken(cfg), apiHandler.ValidateConfig())
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return New(server.URL + "/"), mockDB
+}
+
+func TestClient_ListRepositories(t *testing.T) {
+	c, mockDB := setupServer(t)
+	mockDB.On("GetRepositories", mock.Anything).Return([]string{"octo/app", "octo/lib"}, nil)
+
+	repos, err := c.ListRepositories(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"octo/app", "octo/lib"}, repos)
+}
+
+func TestClient_AddRunTag(t *testing.T) {
+	c, mockDB := setupServer(t)
+	mockDB.On("AddRunTag", mock.Anything, int64(42), "flaky").Return(true, nil)
+	mockDB.On("GetRunTags", mock.Anything, int64(42)).Return([]string{"flaky"}, nil)
+
+	tags, err := c.AddRunTag(context.Background(), 42, "flaky")
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"flaky"}, tags)
+}
+
+func TestClient_APIError(t *testing.T) {
+	c, _ := setupServer(t)
+
+	_, err := c.SaveSLO(context.Background(), models.WorkflowSLO{Name: "deploy", Repository: "octo/app", Workflow: "Deploy"})
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	assert.Contains(t, apiErr.Message, "Invalid SLO")
+	assert.NotEmpty(t, apiErr.RequestID)
+}
+
+func TestClient_ListWorkflowRuns(t *testing.T) {
+	c, mockDB := setupServer(t)
+	mockDB.On("GetWorkflowRunsPaginated", mock.Anything, 2, 1, []string(nil), "", "").
+		Return([]models.WorkflowRun{{ID: 7, Name: "CI"}}, 3, nil)
+	mockDB.On("GetSavedFilters", mock.Anything).Return([]models.SavedFilter{}, nil)
+	mockDB.On("GetRunTags", mock.Anything, mock.Anything).Return([]string{}, nil).Maybe()
+
+	page, err := c.ListWorkflowRuns(context.Background(), RunFilter{Page: 2, Limit: 1})
+
+	require.NoError(t, err)
+	require.Len(t, page.WorkflowRuns, 1)
+	assert.Equal(t, int64(7), page.WorkflowRuns[0].ID)
+	assert.Equal(t, Pagination{CurrentPage: 2, TotalPages: 3, TotalCount: 3, PageSize: 1, HasNext: true, HasPrevious: true}, page.Pagination,
+		"pagination is lifted out of the envelope's data")
+}
+
+func TestClient_GetQueueAttribution(t *testing.T) {
+	c, mockDB := setupServer(t)
+	queued := time.Now().Add(-time.Hour)
+	mockDB.On("GetJobQueueWaits", mock.Anything, mock.Anything, mock.Anything, []string{"octo/app"}).Return([]models.JobQueueWait{
+		{JobID: 1, Labels: []string{"ubuntu-latest"}, QueuedAt: queued, StartedAt: queued.Add(time.Minute)},
+	}, nil)
+	mockDB.On("GetLabelCapacitySamples", mock.Anything, mock.Anything, "").Return([]models.LabelCapacitySample{}, nil)
+
+	report, err := c.GetQueueAttribution(context.Background(), AnalyticsFilter{Period: "day", Repo: "octo/app"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "day", report.Period)
+	assert.Equal(t, 60.0, report.Total.GitHubSeconds)
+	require.Len(t, report.Labels, 1)
+	assert.Equal(t, "ubuntu-latest", report.Labels[0].Label)
+}
+
+func TestClient_SaveSLO(t *testing.T) {
+	c, mockDB := setupServer(t)
+	mockDB.On("SaveWorkflowSLO", mock.Anything, mock.Anything).Return(nil)
+
+	slo, err := c.SaveSLO(context.Background(), models.WorkflowSLO{
+		Name: "deploy", Repository: "octo/app", Workflow: "Deploy", SuccessTarget: 95,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "api", slo.Source)
+	assert.Equal(t, config.DefaultSLOWindowDays, slo.WindowDays)
+}
+
+func TestClient_AdminToken(t *testing.T) {
+	c, mockDB := setupServer(t)
+	mockDB.On("GetEventKeyCounts", mock.Anything, mock.Anything, mock.Anything).Return([]models.EventKeyCount{}, nil)
+
+	_, err := c.GetEventDistribution(context.Background(), "hour")
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+
+	c.AdminToken = "secret"
+	report, err := c.GetEventDistribution(context.Background(), "hour")
+	require.NoError(t, err)
+	assert.Equal(t, "hour", report.Period)
+}
+
+func TestClient_ValidateConfig(t *testing.T) {
+	c, mockDB := setupServer(t)
+	c.AdminToken = "secret"
+	mockDB.On("CheckWritable", mock.Anything).Return(errors.New("database is not writable"))
+
+	report, err := c.ValidateConfig(context.Background())
+
+	require.NoError(t, err)
+	assert.False(t, report.Valid)
+	assert.Equal(t, 1, report.Errors)
+}
+
+func TestClient_RefreshesRotatedCSRFToken(t *testing.T) {
+	c, mockDB := setupServer(t)
+	c.AdminToken = "secret"
+	mockDB.On("GetRepositories", mock.Anything).Return([]string{"octo/app"}, nil)
+
+	_, err := c.ListRepositories(context.Background())
+	require.NoError(t, err)
+	stale := c.csrfToken
+
+	require.NoError(t, c.RotateCSRFKey(context.Background()))
+	repos, err := c.ListRepositories(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"octo/app"}, repos)
+	assert.NotEqual(t, stale, c.csrfToken)
+}
+
+func TestClient_StreamEvents(t *testing.T) {
+	c, _ := setupServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	errDone := errors.New("done")
+	connected := false
+	var update models.MetricsUpdateEvent
+	err := c.StreamEvents(ctx, "", func(event Event) error {
+		switch event.Type {
+		case EventConnected:
+			connected = true
+			handlers.SendMetricsUpdate(models.MetricsUpdateEvent{RunningJobs: 3})
+		case EventMetricsUpdate:
+			require.NoError(t, event.Decode(&update))
+			return errDone
+		}
+		// Other tests' updates may still be queued on the shared handler
+		return nil
+	})
+
+	assert.ErrorIs(t, err, errDone)
+	assert.True(t, connected)
+	assert.Equal(t, 3, update.RunningJobs)
+}
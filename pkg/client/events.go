@@ -0,0 +1,120 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Types of the events sent on event streams.
+const (
+	EventConnected      = "connected"
+	EventMetricsUpdate  = "metrics_update"
+	EventWorkflowUpdate = "workflow_update"
+	EventConfigChanged  = "config_changed"
+	EventDegraded       = "degraded"
+	EventAlert          = "alert"
+	// EventPoll is sent instead of updates when the server has reached its
+	// stream limit; reconnect after its interval_seconds.
+	EventPoll = "poll"
+	// EventServerRestarting precedes the server closing the stream on
+	// shutdown; decode it into models.ServerRestartingEvent.
+	EventServerRestarting = "server_restarting"
+	// EventRunSnapshot and EventEnd open and close the live tail of a run.
+	EventRunSnapshot = "run_snapshot"
+	EventEnd         = "end"
+)
+
+// maxEventSize bounds a single event on the stream.
+const maxEventSize = 4 << 20
+
+// Event is a single event from an event stream.
+type Event struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Decode decodes the event's data into v, such as a models.MetricsUpdateEvent
+// for a metrics_update event or a models.WorkflowUpdateEvent for a
+// workflow_update event.
+func (e Event) Decode(v interface{}) error {
+	return json.Unmarshal(e.Data, v)
+}
+
+// StreamEvents streams live updates to handle until ctx is done, handle
+// returns an error, or the server closes the stream, which it does after
+// EventPoll and EventServerRestarting. A non-empty repo, owner/name, limits
+// workflow updates to that repository. It returns nil when the server closed
+// the stream, and ctx's error once ctx is done; callers that want to stay
+// connected reconnect.
+func (c *Client) StreamEvents(ctx context.Context, repo string, handle func(Event) error) error {
+	query := url.Values{}
+	setIfNotEmpty(query, "repo", repo)
+	return c.stream(ctx, "/events", query, handle)
+}
+
+// StreamRun streams the live tail of a workflow run: an EventRunSnapshot with
+// the run and its jobs, their updates, and an EventEnd once the run has
+// finished, after which the stream is closed.
+func (c *Client) StreamRun(ctx context.Context, runID int64, handle func(Event) error) error {
+	return c.stream(ctx, apiPrefix+"/workflow-runs/"+strconv.FormatInt(runID, 10)+"/live", nil, handle)
+}
+
+func (c *Client) stream(ctx context.Context, path string, query url.Values, handle func(Event) error) error {
+	req, err := c.newRequest(ctx, http.MethodGet, path, query, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach live-actions: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return responseError(resp)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64<<10), maxEventSize)
+	var name string
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line != "" {
+			field, value, _ := strings.Cut(line, ":")
+			value = strings.TrimPrefix(value, " ")
+			switch field {
+			case "event":
+				name = value
+			case "data":
+				data = append(data, value)
+			}
+			continue
+		}
+
+		// A blank line ends an event. Only message events carry updates;
+		// the others are keepalive pings.
+		if (name == "" || name == "message") && len(data) > 0 {
+			var event Event
+			if err := json.Unmarshal([]byte(strings.Join(data, "\n")), &event); err != nil {
+				return fmt.Errorf("failed to decode event: %w", err)
+			}
+			if err := handle(event); err != nil {
+				return err
+			}
+		}
+		name, data = "", nil
+	}
+
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("failed to read event stream: %w", err)
+	}
+	return ctx.Err()
+}
@@ -1,7 +1,9 @@
 package logger
 
 import (
+	"io"
 	"os"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
@@ -17,8 +19,149 @@ var logLevels = map[string]zapcore.Level{
 	"error": zapcore.ErrorLevel,
 }
 
+// Options controls how the global logger is built.
+type Options struct {
+	// Level is the default minimum level (debug, info, warn, error).
+	Level string
+	// Format selects the encoder: "console" (colored, human readable) or "json".
+	Format string
+	// Sampling throttles repeated debug lines (e.g. one per SSE send) to the
+	// first sampleInitial occurrences per second and every sampleThereafter-th after that.
+	Sampling bool
+	// ModuleLevels overrides Level for loggers obtained through Module.
+	ModuleLevels map[string]string
+	// DisablePayloads drops every field built with Payload, so request bodies
+	// never reach the log sink regardless of level.
+	DisablePayloads bool
+	// Output is where log lines are written; defaults to os.Stdout.
+	Output io.Writer
+}
+
+const (
+	sampleInitial    = 10
+	sampleThereafter = 100
+
+	// maxPayloadBytes caps how much of a payload Payload will ever emit.
+	maxPayloadBytes = 100
+)
+
+var (
+	moduleMutex   sync.Mutex
+	moduleLoggers map[string]*zap.Logger
+	activeOptions Options
+)
+
+// InitLogger initializes the global logger with the console format and the given level.
 func InitLogger(level string) {
-	config := zapcore.EncoderConfig{
+	Init(Options{Level: level})
+}
+
+// Init initializes the global logger from the given options.
+func Init(opts Options) {
+	moduleMutex.Lock()
+	defer moduleMutex.Unlock()
+
+	activeOptions = opts
+	moduleLoggers = make(map[string]*zap.Logger)
+
+	// Only add caller for debug level
+	Logger = zap.New(newCore(opts, parseLevel(opts.Level)), zap.AddCallerSkip(1))
+}
+
+// Module returns a named logger for a subsystem (e.g. "sse", "webhook").
+// If a level override was configured for the module it is applied instead of
+// the global level, so a single noisy subsystem can be turned up or down alone.
+func Module(name string) *zap.Logger {
+	moduleMutex.Lock()
+	defer moduleMutex.Unlock()
+
+	if Logger == nil {
+		return zap.NewNop()
+	}
+
+	if l, ok := moduleLoggers[name]; ok {
+		return l
+	}
+
+	l := Logger.Named(name)
+	if level, ok := activeOptions.ModuleLevels[name]; ok {
+		l = zap.New(newCore(activeOptions, parseLevel(level)), zap.AddCallerSkip(1)).Named(name)
+	}
+	moduleLoggers[name] = l
+	return l
+}
+
+// Payload returns a field holding a truncated prefix of a request payload.
+// It must only be used on debug lines; when payload logging is disabled the
+// field is skipped entirely.
+func Payload(key string, data []byte) zap.Field {
+	moduleMutex.Lock()
+	disabled := activeOptions.DisablePayloads
+	moduleMutex.Unlock()
+
+	if disabled {
+		return zap.Skip()
+	}
+	return zap.ByteString(key, data[:min(len(data), maxPayloadBytes)])
+}
+
+func parseLevel(level string) zapcore.Level {
+	l, ok := logLevels[level]
+	if !ok {
+		l = zapcore.InfoLevel // Default to InfoLevel if invalid level provided
+	}
+	return l
+}
+
+// newCore builds the core writing to the configured output, teed into the
+// in-memory buffer of recent lines.
+func newCore(opts Options, level zapcore.Level) zapcore.Core {
+	return zapcore.NewTee(newOutputCore(opts, level), newRecentCore(level))
+}
+
+func newOutputCore(opts Options, level zapcore.Level) zapcore.Core {
+	var out io.Writer = os.Stdout
+	if opts.Output != nil {
+		out = opts.Output
+	}
+	sink := zapcore.AddSync(out)
+	encoder := newEncoder(opts.Format)
+
+	if !opts.Sampling || level > zapcore.DebugLevel {
+		return zapcore.NewCore(encoder, sink, level)
+	}
+
+	// Sample debug lines only; info and above are always written.
+	debugCore := zapcore.NewCore(encoder, sink, zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+		return l == zapcore.DebugLevel
+	}))
+	return zapcore.NewTee(
+		zapcore.NewSamplerWithOptions(debugCore, time.Second, sampleInitial, sampleThereafter),
+		zapcore.NewCore(encoder, sink, zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+			return l > zapcore.DebugLevel && l >= level
+		})),
+	)
+}
+
+func newEncoder(format string) zapcore.Encoder {
+	if format == "json" {
+		return zapcore.NewJSONEncoder(zapcore.EncoderConfig{
+			TimeKey:        "time",
+			LevelKey:       "level",
+			MessageKey:     "msg",
+			CallerKey:      "caller",
+			NameKey:        "logger",
+			StacktraceKey:  "stacktrace",
+			LineEnding:     zapcore.DefaultLineEnding,
+			EncodeLevel:    zapcore.LowercaseLevelEncoder,
+			EncodeTime:     zapcore.ISO8601TimeEncoder,
+			EncodeDuration: zapcore.StringDurationEncoder,
+			EncodeCaller:   zapcore.ShortCallerEncoder,
+			EncodeName:     zapcore.FullNameEncoder,
+		})
+	}
+
+	return zapcore.NewConsoleEncoder(zapcore.EncoderConfig{
 		TimeKey:        "time",
 		LevelKey:       "level",
 		MessageKey:     "msg",
@@ -31,21 +174,7 @@ func InitLogger(level string) {
 		EncodeDuration: zapcore.StringDurationEncoder,
 		EncodeCaller:   customCallerEncoder,
 		EncodeName:     zapcore.FullNameEncoder,
-	}
-
-	l, ok := logLevels[level]
-	if !ok {
-		l = zapcore.InfoLevel // Default to InfoLevel if invalid level provided
-	}
-
-	core := zapcore.NewCore(
-		zapcore.NewConsoleEncoder(config),
-		zapcore.AddSync(os.Stdout),
-		l,
-	)
-
-	// Only add caller for debug level
-	Logger = zap.New(core, zap.AddCallerSkip(1))
+	})
 }
 
 const (
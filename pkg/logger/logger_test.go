@@ -2,6 +2,8 @@ package logger
 
 import (
 	"bytes"
+	"encoding/json"
+	"io"
 	"strings"
 	"testing"
 	"time"
@@ -181,3 +183,137 @@ func TestLoggerLevels(t *testing.T) {
 		})
 	}
 }
+
+func TestInit_JSONFormat(t *testing.T) {
+	var buf bytes.Buffer
+	Init(Options{Level: "info", Format: "json", Output: &buf})
+
+	Logger.Info("json message", zap.String("key", "value"))
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected a JSON log line, got %q: %v", buf.String(), err)
+	}
+	if entry["msg"] != "json message" || entry["key"] != "value" || entry["level"] != "info" {
+		t.Errorf("unexpected JSON log entry: %v", entry)
+	}
+}
+
+func TestModule_LevelOverride(t *testing.T) {
+	var buf bytes.Buffer
+	Init(Options{
+		Level:        "warn",
+		Format:       "json",
+		ModuleLevels: map[string]string{"sse": "debug"},
+		Output:       &buf,
+	})
+
+	Module("sse").Debug("sse debug line")
+	Module("webhook").Debug("webhook debug line")
+	Logger.Info("global info line")
+
+	out := buf.String()
+	if !strings.Contains(out, "sse debug line") {
+		t.Errorf("expected module override to enable debug for sse, got %q", out)
+	}
+	if !strings.Contains(out, `"logger":"sse"`) {
+		t.Errorf("expected module name in log line, got %q", out)
+	}
+	if strings.Contains(out, "webhook debug line") || strings.Contains(out, "global info line") {
+		t.Errorf("expected global warn level to apply elsewhere, got %q", out)
+	}
+}
+
+func TestModule_CachedUntilReinit(t *testing.T) {
+	InitLogger("info")
+	first := Module("http")
+	if first != Module("http") {
+		t.Error("expected Module to return the cached logger")
+	}
+
+	InitLogger("info")
+	if first == Module("http") {
+		t.Error("expected Init to reset cached module loggers")
+	}
+}
+
+func TestInit_SamplingDebugOnly(t *testing.T) {
+	var buf bytes.Buffer
+	Init(Options{Level: "debug", Format: "json", Sampling: true, Output: &buf})
+
+	for i := 0; i < 500; i++ {
+		Logger.Debug("repeated debug line")
+		Logger.Info("repeated info line")
+	}
+
+	debugLines := strings.Count(buf.String(), "repeated debug line")
+	infoLines := strings.Count(buf.String(), "repeated info line")
+	if debugLines >= 500 {
+		t.Errorf("expected debug lines to be sampled, got %d", debugLines)
+	}
+	if infoLines != 500 {
+		t.Errorf("expected every info line to be written, got %d", infoLines)
+	}
+}
+
+func TestPayload(t *testing.T) {
+	var buf bytes.Buffer
+	Init(Options{Level: "debug", Format: "json", Output: &buf})
+
+	long := bytes.Repeat([]byte("a"), 500)
+	Logger.Debug("with payload", Payload("payload_start", long))
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected a JSON log line, got %q: %v", buf.String(), err)
+	}
+	if got := entry["payload_start"].(string); len(got) != maxPayloadBytes {
+		t.Errorf("expected payload to be truncated to %d bytes, got %d", maxPayloadBytes, len(got))
+	}
+}
+
+func TestPayload_Disabled(t *testing.T) {
+	var buf bytes.Buffer
+	Init(Options{Level: "debug", Format: "json", DisablePayloads: true, Output: &buf})
+
+	Logger.Debug("with payload", Payload("payload_start", []byte(`{"secret":"value"}`)))
+
+	if strings.Contains(buf.String(), "payload_start") || strings.Contains(buf.String(), "secret") {
+		t.Errorf("expected payload field to be dropped, got %q", buf.String())
+	}
+}
+
+func TestRecent(t *testing.T) {
+	Init(Options{Level: "debug", Output: io.Discard})
+
+	Logger.Debug("recent debug message")
+	Logger.Info("recent info message", zap.String("key", "value"))
+
+	lines := Recent()
+	if len(lines) == 0 {
+		t.Fatal("expected recent log lines")
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &entry); err != nil {
+		t.Fatalf("expected a JSON log line, got %q: %v", lines[len(lines)-1], err)
+	}
+	if entry["msg"] != "recent info message" || entry["key"] != "value" {
+		t.Errorf("unexpected recent log entry: %v", entry)
+	}
+	for _, line := range lines {
+		if strings.Contains(line, "recent debug message") {
+			t.Error("debug lines should not be kept")
+		}
+	}
+}
+
+func TestRingBuffer_KeepsLatestLines(t *testing.T) {
+	r := &ringBuffer{lines: make([]string, 3)}
+	for _, line := range []string{"a\n", "b\n", "c\n", "d\n"} {
+		r.Write([]byte(line))
+	}
+	got := r.snapshot()
+	if len(got) != 3 || got[0] != "b" || got[2] != "d" {
+		t.Errorf("snapshot() = %v, want [b c d]", got)
+	}
+}
@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// recentLogLines is how many of the latest log lines are kept in memory.
+const recentLogLines = 500
+
+// recentLogs keeps the latest log lines, JSON encoded, for support bundles.
+var recentLogs = &ringBuffer{lines: make([]string, recentLogLines)}
+
+// ringBuffer is a zapcore.WriteSyncer holding the last lines written to it.
+type ringBuffer struct {
+	mutex sync.Mutex
+	lines []string
+	next  int
+	full  bool
+}
+
+func (r *ringBuffer) Write(p []byte) (int, error) {
+	line := string(p)
+	if n := len(line); n > 0 && line[n-1] == '\n' {
+		line = line[:n-1]
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.lines[r.next] = line
+	r.next = (r.next + 1) % len(r.lines)
+	if r.next == 0 {
+		r.full = true
+	}
+	return len(p), nil
+}
+
+func (r *ringBuffer) Sync() error { return nil }
+
+// snapshot returns the buffered lines, oldest first.
+func (r *ringBuffer) snapshot() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if !r.full {
+		return append([]string{}, r.lines[:r.next]...)
+	}
+	return append(append([]string{}, r.lines[r.next:]...), r.lines[:r.next]...)
+}
+
+// newRecentCore records entries at info level or above, or at level when
+// that is higher, into recentLogs. Debug lines are left out so chatty
+// subsystems cannot push everything else out of the buffer.
+func newRecentCore(level zapcore.Level) zapcore.Core {
+	return zapcore.NewCore(newEncoder("json"), recentLogs, max(level, zapcore.InfoLevel))
+}
+
+// Recent returns the latest log lines as JSON objects, oldest first.
+func Recent() []string {
+	return recentLogs.snapshot()
+}
@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/gateixeira/live-actions/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Reasons a job's metrics are recorded under OtherLabel.
+const (
+	OverflowTooManyLabels = "too_many_labels"
+	OverflowTooManyValues = "too_many_values"
+)
+
+// labelGuard bounds the runner label values metrics are recorded under, so
+// a misconfigured workflow cannot explode the number of series. Label values
+// are admitted first come, first served until the limit is reached; series
+// are never removed, so neither are admitted values.
+type labelGuard struct {
+	mutex      sync.Mutex
+	maxPerJob  int
+	maxTracked int
+	tracked    map[string]struct{}
+	warned     bool
+}
+
+// SetLabelLimits bounds label metrics: jobs with more than maxPerJob labels,
+// and label values beyond the first maxTracked seen, are recorded under
+// OtherLabel. Zero removes a limit.
+func (r *Registry) SetLabelLimits(maxPerJob, maxTracked int) {
+	r.labels.mutex.Lock()
+	defer r.labels.mutex.Unlock()
+	r.labels.maxPerJob = maxPerJob
+	r.labels.maxTracked = maxTracked
+}
+
+// JobLabel returns the label value a job's metrics are recorded under: its
+// first runner label, or OtherLabel when that would exceed the limits, which
+// is counted in LabelOverflowTotal.
+func (r *Registry) JobLabel(labels []string) string {
+	if len(labels) == 0 {
+		return UnlabeledLabel
+	}
+	label, reason := r.labels.admit(labels[0], len(labels))
+	if reason != "" {
+		r.LabelOverflowTotal.WithLabelValues(reason).Inc()
+	}
+	return label
+}
+
+// TrackedLabel maps a label value to the one its metrics are recorded under,
+// without counting overflows, for metrics refreshed from stored state.
+func (r *Registry) TrackedLabel(label string) string {
+	label, _ = r.labels.admit(label, 1)
+	return label
+}
+
+func (g *labelGuard) admit(label string, count int) (string, string) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.maxPerJob > 0 && count > g.maxPerJob {
+		return OtherLabel, OverflowTooManyLabels
+	}
+	if _, ok := g.tracked[label]; ok || g.maxTracked <= 0 {
+		return label, ""
+	}
+	if len(g.tracked) >= g.maxTracked {
+		if !g.warned {
+			g.warned = true
+			logger.Module("metrics").Warn("Runner label limit reached, recording further labels as "+OtherLabel,
+				zap.Int("max_tracked_labels", g.maxTracked), zap.String("label", label))
+		}
+		return OtherLabel, OverflowTooManyValues
+	}
+	if g.tracked == nil {
+		g.tracked = make(map[string]struct{})
+	}
+	g.tracked[label] = struct{}{}
+	return label, ""
+}
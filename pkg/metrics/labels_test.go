@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_JobLabel(t *testing.T) {
+	r := GetRegistry()
+	r.SetLabelLimits(3, 2)
+	defer func() {
+		r.SetLabelLimits(0, 0)
+		r.labels.tracked = nil
+	}()
+	overflow := func(reason string) float64 {
+		var m dto.Metric
+		require.NoError(t, r.LabelOverflowTotal.WithLabelValues(reason).Write(&m))
+		return m.GetCounter().GetValue()
+	}
+	tooMany, tooManyValues := overflow(OverflowTooManyLabels), overflow(OverflowTooManyValues)
+
+	assert.Equal(t, UnlabeledLabel, r.JobLabel(nil))
+	assert.Equal(t, "linux", r.JobLabel([]string{"linux", "x64"}))
+	assert.Equal(t, OtherLabel, r.JobLabel([]string{"gpu", "a", "b", "c"}), "too many labels")
+	assert.Equal(t, "gpu", r.JobLabel([]string{"gpu"}))
+	assert.Equal(t, OtherLabel, r.JobLabel([]string{"arm"}), "too many distinct labels")
+	assert.Equal(t, "linux", r.JobLabel([]string{"linux"}), "admitted labels stay tracked")
+
+	assert.Equal(t, tooMany+1, overflow(OverflowTooManyLabels))
+	assert.Equal(t, tooManyValues+1, overflow(OverflowTooManyValues))
+	assert.Equal(t, OtherLabel, r.TrackedLabel("windows"))
+	assert.Equal(t, tooManyValues+1, overflow(OverflowTooManyValues), "stored state is not counted")
+}
+
+func TestRegistry_JobLabel_Unlimited(t *testing.T) {
+	r := GetRegistry()
+	labels := make([]string, 100)
+	for i := range labels {
+		labels[i] = string(rune('a' + i%26))
+	}
+	assert.Equal(t, "a", r.JobLabel(labels))
+	assert.Nil(t, r.labels.tracked, "nothing is tracked without a limit")
+}
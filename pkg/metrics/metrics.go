@@ -4,6 +4,13 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+// Runner label values metrics fall back to.
+const (
+	UnlabeledLabel = "(unlabeled)"
+	// OtherLabel groups jobs whose label would exceed the cardinality limits.
+	OtherLabel = "(other)"
+)
+
 // Registry holds all Prometheus metrics
 type Registry struct {
 	// Current state metrics (gauges)
@@ -12,11 +19,29 @@ type Registry struct {
 	// Per-label current state (gauges)
 	JobsByLabel *prometheus.GaugeVec
 
+	// Per-label throughput over a sliding window (gauges)
+	JobsStartedPerMinute   *prometheus.GaugeVec
+	JobsCompletedPerMinute *prometheus.GaugeVec
+
 	// Historical metrics
 	QueueDurationSeconds *prometheus.HistogramVec
 
 	// Job completion counters
 	JobConclusionsTotal *prometheus.CounterVec
+
+	// Self-monitoring: delay between receiving and processing webhook events
+	ProcessingLagSeconds *prometheus.HistogramVec
+
+	// Self-monitoring: repeated status updates dropped by the dedupe window
+	SuppressedEventsTotal *prometheus.CounterVec
+
+	// Self-monitoring: event stream clients turned away by the connection limit
+	SSEOverflowTotal *prometheus.CounterVec
+
+	// Self-monitoring: jobs recorded under OtherLabel by the label guard
+	LabelOverflowTotal *prometheus.CounterVec
+
+	labels labelGuard
 }
 
 // NewRegistry creates and registers all Prometheus metrics
@@ -32,6 +57,16 @@ func NewRegistry() *Registry {
 			Help: "Current number of jobs by runner label and status",
 		}, []string{"label", "job_status"}),
 
+		JobsStartedPerMinute: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "github_runners_jobs_started_per_minute",
+			Help: "Jobs started per minute by runner label, averaged over THROUGHPUT_WINDOW_MINUTES",
+		}, []string{"label"}),
+
+		JobsCompletedPerMinute: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "github_runners_jobs_completed_per_minute",
+			Help: "Jobs completed per minute by runner label, averaged over THROUGHPUT_WINDOW_MINUTES",
+		}, []string{"label"}),
+
 		QueueDurationSeconds: prometheus.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Name:    "github_runners_queue_duration_seconds",
@@ -45,13 +80,43 @@ func NewRegistry() *Registry {
 			Name: "github_runners_job_conclusions_total",
 			Help: "Total number of completed jobs by conclusion",
 		}, []string{"conclusion"}),
+
+		ProcessingLagSeconds: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "github_runners_webhook_processing_lag_seconds",
+				Help:    "Time between receiving a webhook event and finishing processing it",
+				Buckets: []float64{1, 5, 10, 15, 20, 30, 60, 120, 300, 600},
+			},
+			[]string{"event_type"},
+		),
+
+		SuppressedEventsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "github_runners_webhook_events_suppressed_total",
+			Help: "Total number of webhook events dropped as repeated status updates",
+		}, []string{"event_type"}),
+
+		SSEOverflowTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "github_runners_sse_overflow_total",
+			Help: "Total number of event stream connections turned away by SSE_MAX_CLIENTS",
+		}, []string{"mode"}),
+
+		LabelOverflowTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "github_runners_label_overflow_total",
+			Help: "Total number of job metrics recorded under the (other) runner label because of MAX_LABELS_PER_JOB or MAX_TRACKED_LABELS",
+		}, []string{"reason"}),
 	}
 
 	prometheus.MustRegister(
 		r.CurrentJobs,
 		r.JobsByLabel,
+		r.JobsStartedPerMinute,
+		r.JobsCompletedPerMinute,
 		r.QueueDurationSeconds,
 		r.JobConclusionsTotal,
+		r.ProcessingLagSeconds,
+		r.SuppressedEventsTotal,
+		r.SSEOverflowTotal,
+		r.LabelOverflowTotal,
 	)
 
 	return r
@@ -71,11 +136,35 @@ func (r *Registry) UpdateJobsByLabel(label string, running, queued int) {
 	r.JobsByLabel.WithLabelValues(label, "queued").Set(float64(queued))
 }
 
+func (r *Registry) UpdateJobThroughput(label string, startedPerMinute, completedPerMinute float64) {
+	r.JobsStartedPerMinute.WithLabelValues(label).Set(startedPerMinute)
+	r.JobsCompletedPerMinute.WithLabelValues(label).Set(completedPerMinute)
+}
+
 func (r *Registry) RecordJobConclusion(conclusion string) {
 	r.JobConclusionsTotal.WithLabelValues(conclusion).Inc()
 }
 
+func (r *Registry) RecordProcessingLag(eventType string, lagSeconds float64) {
+	r.ProcessingLagSeconds.WithLabelValues(eventType).Observe(lagSeconds)
+}
+
+func (r *Registry) RecordSuppressedEvent(eventType string) {
+	r.SuppressedEventsTotal.WithLabelValues(eventType).Inc()
+}
+
+func (r *Registry) RecordSSEOverflow(mode string) {
+	r.SSEOverflowTotal.WithLabelValues(mode).Inc()
+}
+
 // ResetJobsByLabel clears all label gauge values before re-setting them.
 func (r *Registry) ResetJobsByLabel() {
 	r.JobsByLabel.Reset()
 }
+
+// ResetJobThroughput clears all throughput gauge values before re-setting
+// them, so labels without recent jobs drop out.
+func (r *Registry) ResetJobThroughput() {
+	r.JobsStartedPerMinute.Reset()
+	r.JobsCompletedPerMinute.Reset()
+}